@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package anim implements helpers for animating values between frames.
+
+A Value interpolates from its current value towards a target over a
+duration. Retargeting a Value while it is animating starts the new
+animation from the current interpolated value, avoiding jumps.
+
+	var v anim.Value[float32]
+	...
+	v.Set(gtx.Now, target, 200*time.Millisecond, anim.EaseOut)
+	x := v.Get(gtx.Now)
+	if v.Animating(gtx.Now) {
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+*/
+package anim
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/internal/f32color"
+)
+
+// Interpolatable is the set of types supported by Value.
+type Interpolatable interface {
+	float32 | float64 | f32.Point | color.NRGBA
+}
+
+// Curve maps the linear progress of an animation in the range [0;1]
+// to the interpolation factor. A Curve must map 0 to 0 and 1 to 1.
+type Curve func(t float32) float32
+
+// Value animates a value towards a target. The zero value holds the
+// zero value of T and is not animating.
+type Value[T Interpolatable] struct {
+	from, to T
+	start    time.Time
+	duration time.Duration
+	curve    Curve
+}
+
+// Linear is the identity Curve.
+func Linear(t float32) float32 {
+	return t
+}
+
+// EaseOut is a Curve that starts fast and decelerates towards the end.
+func EaseOut(t float32) float32 {
+	t = 1 - t
+	return 1 - t*t*t
+}
+
+// EaseInOut is a Curve that accelerates from the start and decelerates
+// towards the end.
+func EaseInOut(t float32) float32 {
+	if t < .5 {
+		return 4 * t * t * t
+	}
+	t = -2*t + 2
+	return 1 - t*t*t/2
+}
+
+// Spring is a Curve that follows a critically damped spring: it starts
+// fast and settles without overshooting, like a released page.
+func Spring(t float32) float32 {
+	return float32(spring(float64(t)) / spring(1))
+}
+
+// spring is the response of a critically damped spring.
+func spring(t float64) float64 {
+	const omega = 8
+	return 1 - (1+omega*t)*math.Exp(-omega*t)
+}
+
+// Set starts an animation from the value at now towards target. A non-positive
+// duration sets the value immediately. A nil curve is equivalent to Linear.
+func (v *Value[T]) Set(now time.Time, target T, duration time.Duration, curve Curve) {
+	v.from = v.Get(now)
+	v.to = target
+	v.start = now
+	v.duration = duration
+	v.curve = curve
+}
+
+// Target returns the value the animation ends at.
+func (v *Value[T]) Target() T {
+	return v.to
+}
+
+// Get returns the value at now.
+func (v *Value[T]) Get(now time.Time) T {
+	if !v.Animating(now) {
+		return v.to
+	}
+	t := float32(now.Sub(v.start)) / float32(v.duration)
+	if t < 0 {
+		t = 0
+	}
+	if v.curve != nil {
+		t = v.curve(t)
+	}
+	return interpolate(v.from, v.to, t)
+}
+
+// Animating reports whether the value at now differs from the target.
+func (v *Value[T]) Animating(now time.Time) bool {
+	return v.duration > 0 && now.Sub(v.start) < v.duration
+}
+
+// interpolate returns the value a fraction t between from and to.
+// Colors are interpolated in linear space.
+func interpolate[T Interpolatable](from, to T, t float32) T {
+	var res interface{}
+	switch from := interface{}(from).(type) {
+	case float32:
+		to := interface{}(to).(float32)
+		res = from + (to-from)*t
+	case float64:
+		to := interface{}(to).(float64)
+		res = from + (to-from)*float64(t)
+	case f32.Point:
+		to := interface{}(to).(f32.Point)
+		res = from.Add(to.Sub(from).Mul(t))
+	case color.NRGBA:
+		c1 := f32color.LinearFromSRGB(from)
+		c2 := f32color.LinearFromSRGB(interface{}(to).(color.NRGBA))
+		res = f32color.RGBA{
+			R: c1.R + (c2.R-c1.R)*t,
+			G: c1.G + (c2.G-c1.G)*t,
+			B: c1.B + (c2.B-c1.B)*t,
+			A: c1.A + (c2.A-c1.A)*t,
+		}.SRGB()
+	}
+	return res.(T)
+}
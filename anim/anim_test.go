@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package anim
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+)
+
+func TestValueFloat(t *testing.T) {
+	start := time.Unix(0, 0)
+	var v Value[float32]
+	v.Set(start, 10, 100*time.Millisecond, Linear)
+	if got, exp := v.Get(start), float32(0); got != exp {
+		t.Errorf("start: got %v; expected %v", got, exp)
+	}
+	if got, exp := v.Get(start.Add(25*time.Millisecond)), float32(2.5); got != exp {
+		t.Errorf("quarter: got %v; expected %v", got, exp)
+	}
+	if !v.Animating(start.Add(99 * time.Millisecond)) {
+		t.Error("Value stopped animating early")
+	}
+	end := start.Add(100 * time.Millisecond)
+	if v.Animating(end) {
+		t.Error("Value still animating after its duration")
+	}
+	if got, exp := v.Get(end), float32(10); got != exp {
+		t.Errorf("end: got %v; expected %v", got, exp)
+	}
+}
+
+func TestValuePoint(t *testing.T) {
+	start := time.Unix(0, 0)
+	var v Value[f32.Point]
+	v.Set(start, f32.Pt(10, -20), time.Second, nil)
+	if got, exp := v.Get(start.Add(time.Second/2)), f32.Pt(5, -10); got != exp {
+		t.Errorf("got %v; expected %v", got, exp)
+	}
+}
+
+func TestValueColor(t *testing.T) {
+	start := time.Unix(0, 0)
+	var v Value[color.NRGBA]
+	v.Set(start, color.NRGBA{A: 0xff}, 0, nil)
+	v.Set(start, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, time.Second, Linear)
+	// Halfway between black and white in linear space is
+	// roughly 188 in sRGB, not 128.
+	exp := color.NRGBA{R: 188, G: 188, B: 188, A: 0xff}
+	if got := v.Get(start.Add(time.Second / 2)); got != exp {
+		t.Errorf("got %v; expected %v", got, exp)
+	}
+}
+
+func TestValueRetarget(t *testing.T) {
+	start := time.Unix(0, 0)
+	var v Value[float64]
+	v.Set(start, 100, time.Second, Linear)
+	mid := start.Add(time.Second / 2)
+	v.Set(mid, 0, time.Second, Linear)
+	if got, exp := v.Get(mid), 50.0; got != exp {
+		t.Errorf("retarget jumped: got %v; expected %v", got, exp)
+	}
+	if got, exp := v.Get(mid.Add(time.Second/2)), 25.0; got != exp {
+		t.Errorf("got %v; expected %v", got, exp)
+	}
+	if got, exp := v.Get(mid.Add(time.Second)), 0.0; got != exp {
+		t.Errorf("got %v; expected %v", got, exp)
+	}
+}
+
+func TestCurves(t *testing.T) {
+	for _, c := range []Curve{Linear, EaseOut, EaseInOut, Spring} {
+		if got := c(0); got != 0 {
+			t.Errorf("curve(0) = %v", got)
+		}
+		if got := c(1); got != 1 {
+			t.Errorf("curve(1) = %v", got)
+		}
+	}
+}
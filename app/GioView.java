@@ -55,6 +55,7 @@ import android.view.accessibility.AccessibilityEvent;
 import android.view.accessibility.AccessibilityManager;
 
 import java.io.UnsupportedEncodingException;
+import java.util.Locale;
 
 public final class GioView extends SurfaceView implements Choreographer.FrameCallback {
 	private static boolean jniLoaded;
@@ -191,6 +192,15 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 		this.setSystemUiVisibility(flags);
 	}
 
+	private void setSecure(boolean enabled) {
+		Window window = ((Activity) this.getContext()).getWindow();
+		if (enabled) {
+			window.addFlags(WindowManager.LayoutParams.FLAG_SECURE);
+		} else {
+			window.clearFlags(WindowManager.LayoutParams.FLAG_SECURE);
+		}
+	}
+
 	private enum Bar {
 		NAVIGATION,
 		STATUS,
@@ -400,6 +410,18 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 		return getResources().getConfiguration().fontScale;
 	}
 
+	String getLocale() {
+		Locale l = Locale.getDefault();
+		if (Build.VERSION.SDK_INT >= Build.VERSION_CODES.LOLLIPOP) {
+			return l.toLanguageTag();
+		}
+		String tag = l.getLanguage();
+		if (!l.getCountry().isEmpty()) {
+			tag += "-" + l.getCountry();
+		}
+		return tag;
+	}
+
 	public void start() {
 		if (nhandle != 0) {
 			onStartView(nhandle);
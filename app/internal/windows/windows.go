@@ -89,6 +89,8 @@ type MonitorInfo struct {
 const (
 	TRUE = 1
 
+	LOCALE_NAME_MAX_LENGTH = 85
+
 	CPS_CANCEL = 0x0004
 
 	CS_HREDRAW     = 0x0002
@@ -110,6 +112,7 @@ const (
 
 	CFS_POINT        = 0x0002
 	CFS_CANDIDATEPOS = 0x0040
+	CFS_EXCLUDE      = 0x0080
 
 	HWND_TOPMOST = ^(uint32(1) - 1) // -1
 
@@ -210,6 +213,10 @@ const (
 
 	UNICODE_NOCHAR = 65535
 
+	WDA_NONE               = 0x00
+	WDA_MONITOR            = 0x01
+	WDA_EXCLUDEFROMCAPTURE = 0x11
+
 	WM_CANCELMODE           = 0x001F
 	WM_CHAR                 = 0x0102
 	WM_CLOSE                = 0x0010
@@ -292,12 +299,13 @@ const (
 )
 
 var (
-	kernel32          = syscall.NewLazySystemDLL("kernel32.dll")
-	_GetModuleHandleW = kernel32.NewProc("GetModuleHandleW")
-	_GlobalAlloc      = kernel32.NewProc("GlobalAlloc")
-	_GlobalFree       = kernel32.NewProc("GlobalFree")
-	_GlobalLock       = kernel32.NewProc("GlobalLock")
-	_GlobalUnlock     = kernel32.NewProc("GlobalUnlock")
+	kernel32                  = syscall.NewLazySystemDLL("kernel32.dll")
+	_GetModuleHandleW         = kernel32.NewProc("GetModuleHandleW")
+	_GetUserDefaultLocaleName = kernel32.NewProc("GetUserDefaultLocaleName")
+	_GlobalAlloc              = kernel32.NewProc("GlobalAlloc")
+	_GlobalFree               = kernel32.NewProc("GlobalFree")
+	_GlobalLock               = kernel32.NewProc("GlobalLock")
+	_GlobalUnlock             = kernel32.NewProc("GlobalUnlock")
 
 	user32                       = syscall.NewLazySystemDLL("user32.dll")
 	_AdjustWindowRectEx          = user32.NewProc("AdjustWindowRectEx")
@@ -346,6 +354,7 @@ var (
 	_SetWindowLong32             = user32.NewProc("SetWindowLongW")
 	_SetWindowPlacement          = user32.NewProc("SetWindowPlacement")
 	_SetWindowPos                = user32.NewProc("SetWindowPos")
+	_SetWindowDisplayAffinity    = user32.NewProc("SetWindowDisplayAffinity")
 	_SetWindowText               = user32.NewProc("SetWindowTextW")
 	_TranslateMessage            = user32.NewProc("TranslateMessage")
 	_UnregisterClass             = user32.NewProc("UnregisterClassW")
@@ -453,6 +462,21 @@ func GetModuleHandle() (syscall.Handle, error) {
 	return syscall.Handle(h), nil
 }
 
+// GetUserDefaultLocaleName returns the user locale as a BCP 47 tag, or
+// the empty string if it is not available.
+func GetUserDefaultLocaleName() string {
+	if _GetUserDefaultLocaleName.Find() != nil {
+		// Introduced in Windows Vista.
+		return ""
+	}
+	var buf [LOCALE_NAME_MAX_LENGTH]uint16
+	n, _, _ := _GetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if n == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf[:])
+}
+
 func getDeviceCaps(hdc syscall.Handle, index int32) int {
 	c, _, _ := _GetDeviceCaps.Call(uintptr(hdc), uintptr(index))
 	return int(c)
@@ -572,12 +596,15 @@ func ImmSetCompositionWindow(imc syscall.Handle, x, y int) {
 	_ImmSetCompositionWindow.Call(uintptr(imc), uintptr(unsafe.Pointer(&f)))
 }
 
-func ImmSetCandidateWindow(imc syscall.Handle, x, y int) {
+// ImmSetCandidateWindow places the candidate window at (x, y), outside
+// the exclude rectangle.
+func ImmSetCandidateWindow(imc syscall.Handle, x, y int, exclude Rect) {
 	f := CandidateForm{
-		dwStyle: CFS_CANDIDATEPOS,
+		dwStyle: CFS_EXCLUDE,
 		ptCurrentPos: Point{
 			X: int32(x), Y: int32(y),
 		},
+		rcArea: exclude,
 	}
 	_ImmSetCandidateWindow.Call(uintptr(imc), uintptr(unsafe.Pointer(&f)))
 }
@@ -602,6 +629,14 @@ func SetWindowPos(hwnd syscall.Handle, hwndInsertAfter uint32, x, y, dx, dy int3
 	)
 }
 
+func SetWindowDisplayAffinity(hwnd syscall.Handle, affinity uint32) error {
+	r, _, err := _SetWindowDisplayAffinity.Call(uintptr(hwnd), uintptr(affinity))
+	if r == 0 {
+		return fmt.Errorf("SetWindowDisplayAffinity: %v", err)
+	}
+	return nil
+}
+
 func SetWindowText(hwnd syscall.Handle, title string) {
 	wname := syscall.StringToUTF16Ptr(title)
 	_SetWindowText.Call(uintptr(hwnd), uintptr(unsafe.Pointer(wname)))
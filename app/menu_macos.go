@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build darwin && !ios
+// +build darwin,!ios
+
+package app
+
+import (
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+
+	"gioui.org/io/key"
+	"gioui.org/io/menu"
+)
+
+/*
+#include <AppKit/AppKit.h>
+
+__attribute__ ((visibility ("hidden"))) CFTypeRef gio_newMainMenu(void);
+__attribute__ ((visibility ("hidden"))) void gio_setMainMenu(CFTypeRef menuRef);
+__attribute__ ((visibility ("hidden"))) CFTypeRef gio_menuItemAt(CFTypeRef menuRef, int idx);
+__attribute__ ((visibility ("hidden"))) CFTypeRef gio_submenu(CFTypeRef itemRef);
+__attribute__ ((visibility ("hidden"))) void gio_truncateMenu(CFTypeRef menuRef, int n);
+__attribute__ ((visibility ("hidden"))) CFTypeRef gio_addMenuItem(CFTypeRef menuRef, CFTypeRef viewRef, int hasSubmenu);
+__attribute__ ((visibility ("hidden"))) void gio_updateMenuItem(CFTypeRef itemRef, CFTypeRef titleRef, CFTypeRef keyRef, NSUInteger mods, int enabled, int checked);
+*/
+import "C"
+
+// nativeMenu is the main menu of a window, and the menu bar it displays.
+type nativeMenu struct {
+	menu C.CFTypeRef
+	bar  menu.Menu
+}
+
+// defaultMainMenu is the main menu of windows without menu bars.
+var defaultMainMenu C.CFTypeRef
+
+func (w *window) UpdateMenuBar(bar menu.Menu, changes []menu.Change) {
+	m := &w.mainMenu
+	if m.menu == 0 {
+		m.menu = C.gio_newMainMenu()
+	}
+	m.bar = bar
+	for _, c := range changes {
+		switch c.Type {
+		case menu.ChangeItems:
+			nm, n := m.menu, 0
+			if len(c.Path) > 0 {
+				nm = C.gio_submenu(m.itemAt(c.Path))
+			} else {
+				// Keep the application menu.
+				n = 1
+			}
+			C.gio_truncateMenu(nm, C.int(n))
+			w.addMenuItems(nm, c.Menu.Items)
+		case menu.ChangeItem:
+			updateMenuItem(m.itemAt(c.Path), c.Item)
+		}
+	}
+	if w.focused {
+		w.setMainMenu()
+	}
+}
+
+// releaseMainMenu releases the main menu of w.
+func (w *window) releaseMainMenu() {
+	if m := w.mainMenu.menu; m != 0 {
+		C.CFRelease(m)
+	}
+	w.mainMenu = nativeMenu{}
+}
+
+// setMainMenu makes the main menu of w the main menu of the application.
+func (w *window) setMainMenu() {
+	if defaultMainMenu == 0 {
+		defaultMainMenu = C.gio_newMainMenu()
+	}
+	if m := w.mainMenu.menu; m != 0 {
+		C.gio_setMainMenu(m)
+	} else {
+		C.gio_setMainMenu(defaultMainMenu)
+	}
+}
+
+func (w *window) addMenuItems(nm C.CFTypeRef, items []menu.Item) {
+	for _, it := range items {
+		var sub C.int
+		if it.Submenu != nil {
+			sub = 1
+		}
+		item := C.gio_addMenuItem(nm, w.view, sub)
+		updateMenuItem(item, it)
+		if it.Submenu != nil {
+			w.addMenuItems(C.gio_submenu(item), it.Submenu.Items)
+		}
+	}
+}
+
+// itemAt returns the native item at the path of menu bar items.
+func (m *nativeMenu) itemAt(path []int) C.CFTypeRef {
+	nm := m.menu
+	var item C.CFTypeRef
+	for i, idx := range path {
+		if i == 0 {
+			// Skip the application menu.
+			idx++
+		}
+		item = C.gio_menuItemAt(nm, C.int(idx))
+		nm = C.gio_submenu(item)
+	}
+	return item
+}
+
+// lookup returns the menu bar item at the path of native items.
+func (m *nativeMenu) lookup(path []int) (menu.Item, bool) {
+	if len(path) == 0 || path[0] == 0 {
+		return menu.Item{}, false
+	}
+	var it menu.Item
+	items := m.bar.Items
+	for i, idx := range path {
+		if i == 0 {
+			idx--
+		}
+		if idx >= len(items) {
+			return menu.Item{}, false
+		}
+		it = items[idx]
+		items = nil
+		if it.Submenu != nil {
+			items = it.Submenu.Items
+		}
+	}
+	return it, true
+}
+
+func updateMenuItem(item C.CFTypeRef, it menu.Item) {
+	title := stringToNSString(it.Title)
+	defer C.CFRelease(title)
+	keq := stringToNSString(keyEquivalent(it.Shortcut.Name))
+	defer C.CFRelease(keq)
+	var enabled, checked C.int
+	if !it.Disabled {
+		enabled = 1
+	}
+	if it.Checkable && it.Checked {
+		checked = 1
+	}
+	C.gio_updateMenuItem(item, title, keq, keyEquivalentMods(it.Shortcut.Modifiers), enabled, checked)
+}
+
+// keyEquivalent converts a key name to a menu item key equivalent.
+func keyEquivalent(name string) string {
+	switch name {
+	case key.NameEscape:
+		return "\x1b"
+	case key.NameReturn, key.NameEnter:
+		return "\r"
+	case key.NameTab:
+		return "\t"
+	case key.NameSpace:
+		return " "
+	case key.NameDeleteBackward:
+		return "\x08"
+	case key.NameDeleteForward:
+		return string(rune(C.NSDeleteFunctionKey))
+	case key.NameLeftArrow:
+		return string(rune(C.NSLeftArrowFunctionKey))
+	case key.NameRightArrow:
+		return string(rune(C.NSRightArrowFunctionKey))
+	case key.NameUpArrow:
+		return string(rune(C.NSUpArrowFunctionKey))
+	case key.NameDownArrow:
+		return string(rune(C.NSDownArrowFunctionKey))
+	case key.NameHome:
+		return string(rune(C.NSHomeFunctionKey))
+	case key.NameEnd:
+		return string(rune(C.NSEndFunctionKey))
+	case key.NamePageUp:
+		return string(rune(C.NSPageUpFunctionKey))
+	case key.NamePageDown:
+		return string(rune(C.NSPageDownFunctionKey))
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		// Upper case key equivalents imply the shift modifier.
+		return strings.ToLower(name)
+	}
+	return ""
+}
+
+func keyEquivalentMods(mods key.Modifiers) C.NSUInteger {
+	var nmods C.NSUInteger
+	if mods.Contain(key.ModAlt) {
+		nmods |= C.NSAlternateKeyMask
+	}
+	if mods.Contain(key.ModCtrl) {
+		nmods |= C.NSControlKeyMask
+	}
+	if mods.Contain(key.ModCommand) {
+		nmods |= C.NSCommandKeyMask
+	}
+	if mods.Contain(key.ModShift) {
+		nmods |= C.NSShiftKeyMask
+	}
+	return nmods
+}
+
+//export gio_onMenuItem
+func gio_onMenuItem(view C.CFTypeRef, cpath *C.int, n C.int) {
+	w := mustView(view)
+	path := make([]int, n)
+	for i, idx := range unsafe.Slice(cpath, n) {
+		path[i] = int(idx)
+	}
+	if it, ok := w.mainMenu.lookup(path); ok && it.Tag != nil && !it.Disabled {
+		w.w.Event(menu.Event{Tag: it.Tag})
+	}
+}
@@ -6,12 +6,16 @@ import (
 	"errors"
 	"image"
 	"image/color"
+	"strings"
+	"time"
 
 	"gioui.org/io/key"
+	"gioui.org/io/menu"
 
 	"gioui.org/gpu"
 	"gioui.org/io/pointer"
 	"gioui.org/io/system"
+	"gioui.org/layout"
 	"gioui.org/unit"
 )
 
@@ -41,6 +45,31 @@ type Config struct {
 	// CustomRenderer is true when the window content is rendered by the
 	// client.
 	CustomRenderer bool
+	// WarmUpGPU is true when the GPU programs are prepared during
+	// initialization.
+	WarmUpGPU bool
+	// TrimDelay is the time a paused window waits before trimming its
+	// caches. Zero or negative durations disable trimming.
+	TrimDelay time.Duration
+	// IdleInvalidates is the time after which content that invalidates
+	// every frame without changing is reported. Zero disables detection.
+	// See the DetectIdleInvalidates option.
+	IdleInvalidates time.Duration
+	// AutoSize is true when the window is resized to fit its content.
+	// See the AutoSize option.
+	AutoSize bool
+	// Inspector is true when the debug inspector of the window is
+	// enabled. See the Inspector option.
+	Inspector bool
+	// Clock is the source of the frame times of the window, or nil for
+	// the real time. See the Clock option.
+	Clock system.Clock
+	// SecureContent is true when the window content is excluded from
+	// screenshots and screen recordings. It remains false on platforms
+	// that don't support content protection.
+	//
+	// Supported platforms are Android, macOS and Windows.
+	SecureContent bool
 	// center is a flag used to center the window. Set by option.
 	center bool
 	// Decorated reports whether window decorations are provided automatically.
@@ -176,6 +205,13 @@ type driver interface {
 	EditorStateChanged(old, new editorState)
 }
 
+// menuDriver is implemented by drivers with native menu bars.
+type menuDriver interface {
+	// UpdateMenuBar applies the changes from the previous menu bar
+	// to the native menu bar, which results in bar.
+	UpdateMenuBar(bar menu.Menu, changes []menu.Change)
+}
+
 type windowRendezvous struct {
 	in   chan windowAndConfig
 	out  chan windowAndConfig
@@ -224,3 +260,15 @@ func walkActions(actions system.Action, do func(system.Action)) {
 		}
 	}
 }
+
+// posixLocaleTag converts a POSIX locale name such as "en_US.UTF-8" to a
+// BCP 47 language tag.
+func posixLocaleTag(l string) string {
+	if i := strings.IndexAny(l, ".@"); i != -1 {
+		l = l[:i]
+	}
+	if l == "C" || l == "POSIX" {
+		return ""
+	}
+	return layout.ParseLocale(l).String()
+}
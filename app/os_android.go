@@ -155,6 +155,7 @@ type window struct {
 	dpi       int
 	fontScale float32
 	insets    system.Insets
+	locale    string
 
 	stage     system.Stage
 	started   bool
@@ -186,6 +187,8 @@ var gioView struct {
 	setNavigationColor C.jmethodID
 	setStatusColor     C.jmethodID
 	setFullscreen      C.jmethodID
+	setSecure          C.jmethodID
+	getLocale          C.jmethodID
 	unregister         C.jmethodID
 	sendA11yEvent      C.jmethodID
 	sendA11yChange     C.jmethodID
@@ -274,6 +277,8 @@ var android struct {
 		androidWidgetRadioButton C.jstring
 		// "android.widget.Switch"
 		androidWidgetSwitch C.jstring
+		// "android.widget.ToggleButton"
+		androidWidgetToggleButton C.jstring
 	}
 }
 
@@ -432,6 +437,7 @@ func initJVM(env *C.JNIEnv, gio C.jclass, ctx C.jobject) {
 	android.strings.androidWidgetEditText = intern("android.widget.EditText")
 	android.strings.androidWidgetRadioButton = intern("android.widget.RadioButton")
 	android.strings.androidWidgetSwitch = intern("android.widget.Switch")
+	android.strings.androidWidgetToggleButton = intern("android.widget.ToggleButton")
 }
 
 // JavaVM returns the global JNI JavaVM.
@@ -469,6 +475,8 @@ func Java_org_gioui_GioView_onCreateView(env *C.JNIEnv, class C.jclass, view C.j
 		m.setNavigationColor = getMethodID(env, class, "setNavigationColor", "(II)V")
 		m.setStatusColor = getMethodID(env, class, "setStatusColor", "(II)V")
 		m.setFullscreen = getMethodID(env, class, "setFullscreen", "(Z)V")
+		m.setSecure = getMethodID(env, class, "setSecure", "(Z)V")
+		m.getLocale = getMethodID(env, class, "getLocale", "()Ljava/lang/String;")
 		m.unregister = getMethodID(env, class, "unregister", "()V")
 		m.sendA11yEvent = getMethodID(env, class, "sendA11yEvent", "(II)V")
 		m.sendA11yChange = getMethodID(env, class, "sendA11yChange", "(I)V")
@@ -720,6 +728,9 @@ func (w *window) initAccessibilityNodeInfo(env *C.JNIEnv, sem router.SemanticNod
 	case semantic.Switch:
 		checkable = true
 		clsName = android.strings.androidWidgetSwitch
+	case semantic.ToggleButton:
+		checkable = true
+		clsName = android.strings.androidWidgetToggleButton
 	}
 	if err := callVoidMethod(env, info, android.accessibilityNodeInfo.setClassName, jvalue(clsName)); err != nil {
 		panic(err)
@@ -801,6 +812,9 @@ func (w *window) nativeWindow() (*C.ANativeWindow, int, int) {
 func (w *window) loadConfig(env *C.JNIEnv, class C.jclass) {
 	dpi := int(C.jni_CallIntMethod(env, w.view, gioView.getDensity))
 	w.fontScale = float32(C.jni_CallFloatMethod(env, w.view, gioView.getFontScale))
+	if l, err := callObjectMethod(env, w.view, gioView.getLocale); err == nil {
+		w.locale = goString(env, C.jstring(l))
+	}
 	switch dpi {
 	case C.ACONFIGURATION_DENSITY_NONE,
 		C.ACONFIGURATION_DENSITY_DEFAULT,
@@ -837,6 +851,7 @@ func (w *window) draw(env *C.JNIEnv, sync bool) {
 			Now:    time.Now(),
 			Size:   w.config.Size,
 			Insets: w.insets,
+			Locale: w.locale,
 			Metric: unit.Metric{
 				PxPerDp: ppdp,
 				PxPerSp: w.fontScale * ppdp,
@@ -954,8 +969,10 @@ func Java_org_gioui_GioView_onTouchEvent(env *C.JNIEnv, class C.jclass, handle C
 		typ = pointer.Release
 	case C.AMOTION_EVENT_ACTION_CANCEL:
 		typ = pointer.Cancel
-	case C.AMOTION_EVENT_ACTION_MOVE:
+	case C.AMOTION_EVENT_ACTION_MOVE, C.AMOTION_EVENT_ACTION_HOVER_ENTER, C.AMOTION_EVENT_ACTION_HOVER_MOVE:
 		typ = pointer.Move
+	case C.AMOTION_EVENT_ACTION_HOVER_EXIT:
+		typ = pointer.Leave
 	case C.AMOTION_EVENT_ACTION_SCROLL:
 		typ = pointer.Scroll
 	default:
@@ -972,11 +989,25 @@ func Java_org_gioui_GioView_onTouchEvent(env *C.JNIEnv, class C.jclass, handle C
 	if jbtns&C.AMOTION_EVENT_BUTTON_TERTIARY != 0 {
 		btns |= pointer.ButtonTertiary
 	}
+	hover := action == C.AMOTION_EVENT_ACTION_HOVER_ENTER || action == C.AMOTION_EVENT_ACTION_HOVER_MOVE || action == C.AMOTION_EVENT_ACTION_HOVER_EXIT
 	switch tool {
 	case C.AMOTION_EVENT_TOOL_TYPE_FINGER:
 		src = pointer.Touch
-	case C.AMOTION_EVENT_TOOL_TYPE_STYLUS:
-		src = pointer.Touch
+	case C.AMOTION_EVENT_TOOL_TYPE_STYLUS, C.AMOTION_EVENT_TOOL_TYPE_ERASER:
+		src = pointer.Pen
+		// Android reports contact by the action, not the button
+		// state.
+		btns = 0
+		if jbtns&C.AMOTION_EVENT_BUTTON_STYLUS_PRIMARY != 0 {
+			btns |= pointer.ButtonBarrel
+		}
+		if !hover && typ != pointer.Release && typ != pointer.Cancel {
+			if tool == C.AMOTION_EVENT_TOOL_TYPE_ERASER {
+				btns |= pointer.ButtonEraser
+			} else {
+				btns |= pointer.ButtonPrimary
+			}
+		}
 	case C.AMOTION_EVENT_TOOL_TYPE_MOUSE:
 		src = pointer.Mouse
 	case C.AMOTION_EVENT_TOOL_TYPE_UNKNOWN:
@@ -986,6 +1017,9 @@ func Java_org_gioui_GioView_onTouchEvent(env *C.JNIEnv, class C.jclass, handle C
 	default:
 		return
 	}
+	if hover && src != pointer.Pen {
+		return
+	}
 	w.callbacks.Event(pointer.Event{
 		Type:      typ,
 		Source:    src,
@@ -1319,6 +1353,14 @@ func (w *window) Configure(options []Option) {
 		if cnf.Decorated != prev.Decorated {
 			w.config.Decorated = cnf.Decorated
 		}
+		if cnf.SecureContent != prev.SecureContent {
+			if cnf.SecureContent {
+				callVoidMethod(env, w.view, gioView.setSecure, C.JNI_TRUE)
+			} else {
+				callVoidMethod(env, w.view, gioView.setSecure, C.JNI_FALSE)
+			}
+			w.config.SecureContent = cnf.SecureContent
+		}
 		if w.config != prev {
 			w.callbacks.Event(ConfigEvent{Config: w.config})
 		}
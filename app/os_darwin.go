@@ -14,6 +14,7 @@ __attribute__ ((visibility ("hidden"))) void gio_setDisplayLinkDisplay(CFTypeRef
 __attribute__ ((visibility ("hidden"))) void gio_hideCursor();
 __attribute__ ((visibility ("hidden"))) void gio_showCursor();
 __attribute__ ((visibility ("hidden"))) void gio_setCursor(NSUInteger curID);
+__attribute__ ((visibility ("hidden"))) void gio_observeLocale(void);
 
 static bool isMainThread() {
 	return [NSThread isMainThread];
@@ -29,6 +30,12 @@ static void nsstringGetCharacters(CFTypeRef cstr, unichar *chars, NSUInteger loc
 	[str getCharacters:chars range:NSMakeRange(loc, length)];
 }
 
+static CFTypeRef currentLocale(void) {
+	@autoreleasepool {
+		return CFBridgingRetain([[NSLocale currentLocale] localeIdentifier]);
+	}
+}
+
 static CFTypeRef newNSString(unichar *chars, NSUInteger length) {
 	@autoreleasepool {
 		NSString *s = [NSString string];
@@ -111,6 +118,35 @@ func nsstringToString(str C.CFTypeRef) string {
 	return string(utf8)
 }
 
+// darwinLocale caches the current locale. It is only accessed from the
+// main thread.
+var darwinLocale struct {
+	tag       string
+	valid     bool
+	observing bool
+}
+
+// systemLocale returns the current locale as a BCP 47 tag. It must be
+// called from the main thread.
+func systemLocale() string {
+	if !darwinLocale.observing {
+		darwinLocale.observing = true
+		C.gio_observeLocale()
+	}
+	if !darwinLocale.valid {
+		l := C.currentLocale()
+		darwinLocale.tag = posixLocaleTag(nsstringToString(l))
+		darwinLocale.valid = true
+		C.CFRelease(l)
+	}
+	return darwinLocale.tag
+}
+
+//export gio_onLocaleChange
+func gio_onLocaleChange() {
+	darwinLocale.valid = false
+}
+
 // stringToNSString converts a Go string to a retained NSString.
 func stringToNSString(str string) C.CFTypeRef {
 	u16 := utf16.Encode([]rune(str))
@@ -10,3 +10,12 @@ void gio_wakeupMainThread(void) {
 		gio_dispatchMainFuncs();
 	});
 }
+
+void gio_observeLocale(void) {
+	[[NSNotificationCenter defaultCenter] addObserverForName:NSCurrentLocaleDidChangeNotification
+	                                                  object:nil
+	                                                   queue:[NSOperationQueue mainQueue]
+	                                              usingBlock:^(NSNotification *note) {
+		gio_onLocaleChange();
+	}];
+}
@@ -167,6 +167,7 @@ func (w *window) draw(sync bool) {
 				PxPerDp: float32(params.dpi) * inchPrDp,
 				PxPerSp: float32(params.sdpi) * inchPrDp,
 			},
+			Locale: systemLocale(),
 		},
 		Sync: sync,
 	})
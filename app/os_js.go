@@ -642,11 +642,21 @@ func (w *window) draw(sync bool) {
 			Size:   size,
 			Insets: insets,
 			Metric: metric,
+			Locale: w.locale(),
 		},
 		Sync: sync,
 	})
 }
 
+// locale returns the preferred language of the browser.
+func (w *window) locale() string {
+	l := w.window.Get("navigator").Get("language")
+	if l.Type() != js.TypeString {
+		return ""
+	}
+	return l.String()
+}
+
 func (w *window) getConfig() (image.Point, system.Insets, unit.Metric) {
 	return image.Pt(w.config.Size.X, w.config.Size.Y),
 		system.Insets{
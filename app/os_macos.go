@@ -145,6 +145,11 @@ static void setTitle(CFTypeRef windowRef, CFTypeRef titleRef) {
 	window.title = (__bridge NSString *)titleRef;
 }
 
+static void setSecure(CFTypeRef windowRef, int secure) {
+	NSWindow *window = (__bridge NSWindow *)windowRef;
+	window.sharingType = secure ? NSWindowSharingNone : NSWindowSharingReadOnly;
+}
+
 static CFTypeRef layerForView(CFTypeRef viewRef) {
 	NSView *view = (__bridge NSView *)viewRef;
 	return (__bridge CFTypeRef)view.layer;
@@ -212,6 +217,9 @@ type window struct {
 
 	scale  float32
 	config Config
+
+	focused  bool
+	mainMenu nativeMenu
 }
 
 // viewMap is the mapping from Cocoa NSViews to Go windows.
@@ -355,6 +363,14 @@ func (w *window) Configure(options []Option) {
 	if cnf.Decorated != prev.Decorated {
 		w.config.Decorated = cnf.Decorated
 	}
+	if cnf.SecureContent != prev.SecureContent {
+		w.config.SecureContent = cnf.SecureContent
+		secure := C.int(0)
+		if cnf.SecureContent {
+			secure = 1
+		}
+		C.setSecure(w.window, secure)
+	}
 	if w.config != prev {
 		w.w.Event(ConfigEvent{Config: w.config})
 	}
@@ -499,7 +515,13 @@ func gio_onDraw(view C.CFTypeRef) {
 //export gio_onFocus
 func gio_onFocus(view C.CFTypeRef, focus C.int) {
 	w := mustView(view)
-	w.w.Event(key.FocusEvent{Focus: focus == 1})
+	w.focused = focus == 1
+	if w.focused && (w.mainMenu.menu != 0 || defaultMainMenu != 0) {
+		// Show the menu bar of w, or the default menu bar in
+		// place of the menu bar of another window.
+		w.setMainMenu()
+	}
+	w.w.Event(key.FocusEvent{Focus: w.focused})
 	w.SetCursor(w.cursor)
 }
 
@@ -657,10 +679,10 @@ func gio_firstRectForCharacterRange(view C.CFTypeRef, crng C.NSRange, actual C.N
 	scale := 1. / float32(C.getViewBackingScale(w.view))
 	height := float32(C.viewHeight(w.view))
 	local := f32.Affine2D{}.Scale(f32.Pt(0, 0), f32.Pt(scale, -scale)).Offset(f32.Pt(0, height))
-	t := local.Mul(sel.Transform)
+	caret := state.CaretRect()
 	bounds := f32.Rectangle{
-		Min: t.Transform(sel.Pos.Sub(f32.Pt(0, sel.Ascent))),
-		Max: t.Transform(sel.Pos.Add(f32.Pt(0, sel.Descent))),
+		Min: local.Transform(caret.Min),
+		Max: local.Transform(caret.Max),
 	}.Canon()
 	sz := bounds.Size()
 	return C.NSMakeRect(
@@ -694,6 +716,7 @@ func (w *window) draw() {
 			Now:    time.Now(),
 			Size:   w.config.Size,
 			Metric: cfg,
+			Locale: systemLocale(),
 		},
 		Sync: true,
 	})
@@ -713,6 +736,7 @@ func gio_onClose(view C.CFTypeRef) {
 	deleteView(view)
 	w.w.Event(system.DestroyEvent{})
 	w.displayLink.Close()
+	w.releaseMainMenu()
 	C.CFRelease(w.view)
 	C.CFRelease(w.window)
 	w.view = 0
@@ -185,6 +185,21 @@ static void handleMouse(NSView *view, NSEvent *event, int typ, CGFloat dx, CGFlo
     r = [self convertRect:r toView:nil];
     return [[self window] convertRectToScreen:r];
 }
+- (void)chooseMenuItem:(NSMenuItem *)item {
+	// Find the indices of the items from the main menu to item.
+	NSMutableArray<NSNumber *> *path = [NSMutableArray array];
+	for (NSMenuItem *it = item; it != nil; ) {
+		NSMenu *menu = it.menu;
+		[path insertObject:@([menu indexOfItem:it]) atIndex:0];
+		NSMenu *parent = menu.supermenu;
+		it = parent != nil ? [parent itemAtIndex:[parent indexOfItemWithSubmenu:menu]] : nil;
+	}
+	int indices[path.count];
+	for (NSUInteger i = 0; i < path.count; i++) {
+		indices[i] = path[i].intValue;
+	}
+	gio_onMenuItem((__bridge CFTypeRef)self, indices, (int)path.count);
+}
 @end
 
 // Delegates are weakly referenced from their peers. Nothing
@@ -387,27 +402,88 @@ CFTypeRef gio_createView(void) {
 }
 @end
 
+// newAppMenuItem creates the item of the application menu.
+static NSMenuItem *newAppMenuItem(void) {
+	NSMenuItem *mainMenu = [NSMenuItem new];
+
+	NSMenu *menu = [NSMenu new];
+	NSMenuItem *hideMenuItem = [[NSMenuItem alloc] initWithTitle:@"Hide"
+														  action:@selector(hide:)
+												   keyEquivalent:@"h"];
+	[menu addItem:hideMenuItem];
+	NSMenuItem *quitMenuItem = [[NSMenuItem alloc] initWithTitle:@"Quit"
+														  action:@selector(terminate:)
+												   keyEquivalent:@"q"];
+	[menu addItem:quitMenuItem];
+	[mainMenu setSubmenu:menu];
+	return mainMenu;
+}
+
+CFTypeRef gio_newMainMenu(void) {
+	@autoreleasepool {
+		NSMenu *menuBar = [NSMenu new];
+		menuBar.autoenablesItems = NO;
+		[menuBar addItem:newAppMenuItem()];
+		return CFBridgingRetain(menuBar);
+	}
+}
+
+void gio_setMainMenu(CFTypeRef menuRef) {
+	NSApp.mainMenu = (__bridge NSMenu *)menuRef;
+}
+
+CFTypeRef gio_menuItemAt(CFTypeRef menuRef, int idx) {
+	NSMenu *menu = (__bridge NSMenu *)menuRef;
+	return (__bridge CFTypeRef)[menu itemAtIndex:idx];
+}
+
+CFTypeRef gio_submenu(CFTypeRef itemRef) {
+	NSMenuItem *item = (__bridge NSMenuItem *)itemRef;
+	return (__bridge CFTypeRef)item.submenu;
+}
+
+void gio_truncateMenu(CFTypeRef menuRef, int n) {
+	NSMenu *menu = (__bridge NSMenu *)menuRef;
+	while (menu.numberOfItems > n) {
+		[menu removeItemAtIndex:n];
+	}
+}
+
+CFTypeRef gio_addMenuItem(CFTypeRef menuRef, CFTypeRef viewRef, int hasSubmenu) {
+	@autoreleasepool {
+		NSMenu *menu = (__bridge NSMenu *)menuRef;
+		NSMenuItem *item = [NSMenuItem new];
+		if (hasSubmenu) {
+			NSMenu *sub = [NSMenu new];
+			sub.autoenablesItems = NO;
+			item.submenu = sub;
+		} else {
+			item.target = (__bridge NSView *)viewRef;
+			item.action = @selector(chooseMenuItem:);
+		}
+		[menu addItem:item];
+		return (__bridge CFTypeRef)item;
+	}
+}
+
+void gio_updateMenuItem(CFTypeRef itemRef, CFTypeRef titleRef, CFTypeRef keyRef, NSUInteger mods, int enabled, int checked) {
+	NSMenuItem *item = (__bridge NSMenuItem *)itemRef;
+	NSString *title = (__bridge NSString *)titleRef;
+	item.title = title;
+	item.submenu.title = title;
+	item.keyEquivalent = (__bridge NSString *)keyRef;
+	item.keyEquivalentModifierMask = mods;
+	item.enabled = enabled ? YES : NO;
+	item.state = checked ? NSControlStateValueOn : NSControlStateValueOff;
+}
+
 void gio_main() {
 	@autoreleasepool {
 		[NSApplication sharedApplication];
 		GioAppDelegate *del = [[GioAppDelegate alloc] init];
 		[NSApp setDelegate:del];
 
-		NSMenuItem *mainMenu = [NSMenuItem new];
-
-		NSMenu *menu = [NSMenu new];
-		NSMenuItem *hideMenuItem = [[NSMenuItem alloc] initWithTitle:@"Hide"
-															  action:@selector(hide:)
-													   keyEquivalent:@"h"];
-		[menu addItem:hideMenuItem];
-		NSMenuItem *quitMenuItem = [[NSMenuItem alloc] initWithTitle:@"Quit"
-															  action:@selector(terminate:)
-													   keyEquivalent:@"q"];
-		[menu addItem:quitMenuItem];
-		[mainMenu setSubmenu:menu];
-		NSMenu *menuBar = [NSMenu new];
-		[menuBar addItem:mainMenu];
-		[NSApp setMainMenu:menuBar];
+		[NSApp setMainMenu:(__bridge_transfer NSMenu *)gio_newMainMenu()];
 
 		globalWindowDel = [[GioWindowDelegate alloc] init];
 
@@ -7,6 +7,8 @@ package app
 
 import (
 	"errors"
+	"os"
+	"sync"
 	"unsafe"
 
 	"gioui.org/io/pointer"
@@ -98,3 +100,25 @@ var xCursor = [...]string{
 	pointer.CursorNorthEastSouthWestResize: "fd_double_arrow",
 	pointer.CursorNorthWestSouthEastResize: "bd_double_arrow",
 }
+
+// unixLocale caches the locale of the environment. There are no locale
+// change notifications, and changes to the environment don't affect a
+// running program.
+var unixLocale struct {
+	once sync.Once
+	tag  string
+}
+
+// systemLocale returns the locale of the environment as a BCP 47 tag,
+// from the POSIX locale variables.
+func systemLocale() string {
+	unixLocale.once.Do(func() {
+		for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if l := os.Getenv(v); l != "" {
+				unixLocale.tag = posixLocaleTag(l)
+				return
+			}
+		}
+	})
+	return unixLocale.tag
+}
@@ -1594,6 +1594,7 @@ func (w *window) draw() {
 			Now:    time.Now(),
 			Size:   w.config.Size,
 			Metric: cfg,
+			Locale: systemLocale(),
 		},
 		Sync: sync,
 	})
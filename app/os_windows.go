@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"math"
 	"runtime"
 	"sort"
 	"strings"
@@ -352,11 +353,15 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 			return windows.TRUE
 		}
 		defer windows.ImmReleaseContext(w.hwnd, imc)
-		sel := w.w.EditorState().Selection
-		caret := sel.Transform.Transform(sel.Caret.Pos.Add(f32.Pt(0, sel.Caret.Descent)))
-		icaret := image.Pt(int(caret.X+.5), int(caret.Y+.5))
-		windows.ImmSetCompositionWindow(imc, icaret.X, icaret.Y)
-		windows.ImmSetCandidateWindow(imc, icaret.X, icaret.Y)
+		caret := w.w.EditorState().CaretRect()
+		r := windows.Rect{
+			Left:   int32(math.Floor(float64(caret.Min.X))),
+			Top:    int32(math.Floor(float64(caret.Min.Y))),
+			Right:  int32(math.Ceil(float64(caret.Max.X))),
+			Bottom: int32(math.Ceil(float64(caret.Max.Y))),
+		}
+		windows.ImmSetCompositionWindow(imc, int(r.Left), int(r.Bottom))
+		windows.ImmSetCandidateWindow(imc, int(r.Left), int(r.Bottom), r)
 	case windows.WM_IME_COMPOSITION:
 		imc := windows.ImmGetContext(w.hwnd)
 		if imc == 0 {
@@ -469,12 +474,13 @@ func (w *window) scrollEvent(wParam, lParam uintptr, horizontal bool) {
 		sp.Y = -dist
 	}
 	w.w.Event(pointer.Event{
-		Type:     pointer.Scroll,
-		Source:   pointer.Mouse,
-		Position: p,
-		Buttons:  w.pointerBtns,
-		Scroll:   sp,
-		Time:     windows.GetMessageTime(),
+		Type:      pointer.Scroll,
+		Source:    pointer.Mouse,
+		Position:  p,
+		Buttons:   w.pointerBtns,
+		Scroll:    sp,
+		Time:      windows.GetMessageTime(),
+		Modifiers: getModifiers(),
 	})
 }
 
@@ -540,6 +546,7 @@ func (w *window) draw(sync bool) {
 			Now:    time.Now(),
 			Size:   w.config.Size,
 			Metric: cfg,
+			Locale: windows.GetUserDefaultLocaleName(),
 		},
 		Sync: sync,
 	})
@@ -655,12 +662,29 @@ func (w *window) Configure(options []Option) {
 		)
 		windows.ShowWindow(w.hwnd, windows.SW_SHOW)
 	}
+	if w.config.SecureContent != oldConfig.SecureContent {
+		w.config.SecureContent = w.setSecure(w.config.SecureContent)
+	}
 	// A config event is sent to the main event loop whenever the configuration is changed
 	if oldConfig != w.config {
 		w.w.Event(ConfigEvent{Config: w.config})
 	}
 }
 
+// setSecure sets the display affinity of the window and reports whether
+// its content is excluded from capture.
+func (w *window) setSecure(enable bool) bool {
+	if !enable {
+		return windows.SetWindowDisplayAffinity(w.hwnd, windows.WDA_NONE) != nil
+	}
+	if windows.SetWindowDisplayAffinity(w.hwnd, windows.WDA_EXCLUDEFROMCAPTURE) == nil {
+		return true
+	}
+	// WDA_EXCLUDEFROMCAPTURE requires Windows 10, version 2004. Fall back
+	// to blacking out the window content.
+	return windows.SetWindowDisplayAffinity(w.hwnd, windows.WDA_MONITOR) == nil
+}
+
 func (w *window) WriteClipboard(s string) {
 	w.writeClipboard(s)
 }
@@ -453,6 +453,7 @@ loop:
 					Now:    time.Now(),
 					Size:   w.config.Size,
 					Metric: w.metric,
+					Locale: systemLocale(),
 				},
 				Sync: syn,
 			})
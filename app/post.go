@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by Window.Post and Window.PostAndWait after the
+// window is destroyed.
+var ErrClosed = errors.New("app: window closed")
+
+// PostEvent carries functions posted by Window.Post, to be run by the
+// goroutine receiving the window events. Programs that post functions
+// must call Run for every PostEvent they receive.
+type PostEvent struct {
+	funcs []func()
+}
+
+// posts is the queue of posted functions of a Window.
+type posts struct {
+	mu     sync.Mutex
+	funcs  []func()
+	closed bool
+}
+
+// Run the posted functions, in the order they were posted.
+func (e PostEvent) Run() {
+	for _, f := range e.funcs {
+		f()
+	}
+}
+
+// Post queues f to run on the goroutine receiving the events of the
+// window, and invalidates the window. The functions posted between two
+// deliveries are coalesced into a single PostEvent, delivered when the
+// window wakes up and always before the next FrameEvent, so the state
+// changed by f is visible to the layout of that frame. Functions run in
+// the order they are posted; in particular, the functions posted by a
+// goroutine run in the order of posting.
+//
+// Post is safe for concurrent use. The functions pending when the window
+// is destroyed are dropped, and Post returns ErrClosed after the window
+// is destroyed.
+func (w *Window) Post(f func()) error {
+	p := &w.posts
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrClosed
+	}
+	p.funcs = append(p.funcs, f)
+	first := len(p.funcs) == 1
+	p.mu.Unlock()
+	if first {
+		w.Invalidate()
+	}
+	return nil
+}
+
+// PostAndWait is like Post, but waits for f to return. It returns
+// ErrClosed if the window is destroyed before f runs. PostAndWait must not
+// be called from the goroutine receiving the window events; it is
+// intended for tests and background goroutines that depend on the
+// result of f.
+func (w *Window) PostAndWait(f func()) error {
+	done := make(chan struct{})
+	err := w.Post(func() {
+		defer close(done)
+		f()
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case <-done:
+		return nil
+	case <-w.dead:
+		// The function may have run before the window closed.
+		select {
+		case <-done:
+			return nil
+		default:
+			return ErrClosed
+		}
+	}
+}
+
+// flushPosts delivers the pending posted functions and waits for the
+// client to run them. Functions posted while waiting are delivered as
+// well, because their wakeup may have been absorbed by waitAck.
+func (w *Window) flushPosts(d driver) {
+	p := &w.posts
+	for {
+		p.mu.Lock()
+		funcs := p.funcs
+		p.funcs = nil
+		p.mu.Unlock()
+		if len(funcs) == 0 {
+			return
+		}
+		w.out <- PostEvent{funcs: funcs}
+		w.waitAck(d)
+	}
+}
+
+// closePosts drops the pending posted functions, and rejects later
+// posts.
+func (w *Window) closePosts() {
+	p := &w.posts
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	p.funcs = nil
+}
+
+func (PostEvent) ImplementsEvent() {}
@@ -17,14 +17,18 @@ import (
 	"gioui.org/font/gofont"
 	"gioui.org/gpu"
 	"gioui.org/internal/ops"
+	"gioui.org/io/diag"
 	"gioui.org/io/event"
 	"gioui.org/io/key"
+	"gioui.org/io/menu"
 	"gioui.org/io/pointer"
 	"gioui.org/io/profile"
 	"gioui.org/io/router"
+	"gioui.org/io/size"
 	"gioui.org/io/system"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
@@ -67,6 +71,25 @@ type Window struct {
 	hasNextFrame bool
 	nextFrame    time.Time
 	delayedDraw  *time.Timer
+	// hasScheduled and nextScheduled track the scheduled redraw of the
+	// most recent frame. See op.InvalidateOp.
+	hasScheduled  bool
+	nextScheduled time.Time
+	// redrawRequested is set when a redraw is requested by Invalidate or
+	// by input since the most recent frame.
+	redrawRequested bool
+	trimDelay       time.Duration
+	trimTimer       *time.Timer
+	// clock stamps the frames and times the scheduled redraws.
+	clock system.Clock
+	// autoSize is set when the window fits the size of its content. fitted
+	// is set once the window was fitted to the first observed size.
+	autoSize bool
+	fitted   bool
+	// diag receives the diagnostics of the window, and lostDevice is
+	// set between the loss of the GPU device and its recovery.
+	diag       diag.Reporter
+	lostDevice bool
 
 	queue       queue
 	cursor      pointer.Cursor
@@ -77,10 +100,17 @@ type Window struct {
 		*widget.Decorations
 		size image.Point // decorations size
 	}
+	// inspector is the debug inspector, if enabled.
+	inspector struct {
+		op.Ops
+		enabled bool
+		widget.Inspector
+	}
 
 	callbacks callbacks
 
 	nocontext bool
+	warmUpGPU bool
 
 	// semantic data, lazily evaluated if requested by a backend to speed up
 	// the cases where semantic data is not needed.
@@ -94,6 +124,12 @@ type Window struct {
 	}
 
 	imeState editorState
+
+	// menuBar is the menu bar of the driver.
+	menuBar menu.Menu
+
+	// posts are the functions queued by Post.
+	posts posts
 }
 
 type editorState struct {
@@ -131,6 +167,7 @@ func NewWindow(options ...Option) *Window {
 	defaultOptions := []Option{
 		Size(unit.Dp(800), unit.Dp(600)),
 		Title("Gio"),
+		TrimDelay(10 * time.Second),
 	}
 	options = append(defaultOptions, options...)
 	var cnf Config
@@ -148,6 +185,18 @@ func NewWindow(options ...Option) *Window {
 		wakeupFuncs:      make(chan func()),
 		dead:             make(chan struct{}),
 		nocontext:        cnf.CustomRenderer,
+		warmUpGPU:        cnf.WarmUpGPU,
+		trimDelay:        cnf.TrimDelay,
+		autoSize:         cnf.AutoSize,
+	}
+	w.inspector.enabled = cnf.Inspector
+	w.clock = system.RealClock
+	if cnf.Clock != nil {
+		w.clock = cnf.Clock
+		w.queue.q.SetClock(cnf.Clock)
+	}
+	if cnf.IdleInvalidates > 0 {
+		w.queue.q.DetectIdleInvalidates(&w.diag, cnf.IdleInvalidates)
 	}
 	w.imeState.compose = key.Range{Start: -1, End: -1}
 	w.semantic.ids = make(map[router.SemanticID]router.SemanticNode)
@@ -189,7 +238,7 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 					return nil
 				}
 				w.destroyGPU()
-				if errors.Is(err, gpu.ErrDeviceLost) {
+				if errors.Is(w.reportDeviceLost(err), gpu.ErrDeviceLost) {
 					continue
 				}
 				return err
@@ -200,13 +249,29 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 				w.destroyGPU()
 				return err
 			}
-			gpu, err := gpu.New(w.ctx.API())
+			g, err := gpu.New(w.ctx.API())
+			if err == nil {
+				gpu.SetDiagnostics(g, &w.diag)
+				if w.lostDevice {
+					w.lostDevice = false
+					w.diag.Report(diag.Diagnostic{
+						Severity: diag.Info,
+						Source:   "gpu",
+						Message:  "device recovered",
+					})
+				}
+			}
+			if err == nil && w.warmUpGPU {
+				// Warm-up failures only mean that programs are
+				// compiled when first used.
+				gpu.WarmUp(g)
+			}
 			w.ctx.Unlock()
 			if err != nil {
 				w.destroyGPU()
 				return err
 			}
-			w.gpu = gpu
+			w.gpu = g
 		}
 		if w.gpu != nil {
 			if err := w.render(frame, size); err != nil {
@@ -217,6 +282,7 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 				}
 				w.destroyGPU()
 				if errors.Is(err, gpu.ErrDeviceLost) {
+					w.lostDevice = true
 					continue
 				}
 				return err
@@ -227,9 +293,23 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 	}
 }
 
+// reportDeviceLost records and reports the loss of the GPU device, if
+// err is ErrDeviceLost.
+func (w *Window) reportDeviceLost(err error) error {
+	if errors.Is(err, gpu.ErrDeviceLost) {
+		w.lostDevice = true
+		w.diag.Report(diag.Diagnostic{
+			Severity: diag.Error,
+			Source:   "gpu",
+			Message:  "device lost",
+		})
+	}
+	return err
+}
+
 func (w *Window) render(frame *op.Ops, viewport image.Point) error {
 	if err := w.ctx.Lock(); err != nil {
-		return err
+		return w.reportDeviceLost(err)
 	}
 	defer w.ctx.Unlock()
 	if runtime.GOOS == "js" {
@@ -241,12 +321,13 @@ func (w *Window) render(frame *op.Ops, viewport image.Point) error {
 	}
 	target, err := w.ctx.RenderTarget()
 	if err != nil {
-		return err
+		return w.reportDeviceLost(err)
 	}
+	// The GPU reports its own device losses.
 	if err := w.gpu.Frame(frame, target, viewport); err != nil {
 		return err
 	}
-	return w.ctx.Present()
+	return w.reportDeviceLost(w.ctx.Present())
 }
 
 func (w *Window) processFrame(d driver, frameStart time.Time) {
@@ -277,19 +358,71 @@ func (w *Window) processFrame(d driver, frameStart time.Time) {
 		w.imeState = newState
 		d.EditorStateChanged(oldState, newState)
 	}
+	// Read the wakeup of the frame before the profile event, which
+	// doesn't need a frame of its own.
+	if t, ok := q.WakeupTime(); ok {
+		w.setNextFrame(t)
+	}
 	if q.Profiling() && w.gpu != nil {
 		frameDur := time.Since(frameStart)
 		frameDur = frameDur.Truncate(100 * time.Microsecond)
 		quantum := 100 * time.Microsecond
 		timings := fmt.Sprintf("tot:%7s %s", frameDur.Round(quantum), w.gpu.Profile())
+		if causes := q.FrameCauses(); len(causes) > 0 {
+			timings += " cause:" + router.FormatCauses(causes)
+		}
 		q.Queue(profile.Event{Timings: timings})
 	}
-	if t, ok := q.WakeupTime(); ok {
-		w.setNextFrame(t)
-	}
+	w.nextScheduled, w.hasScheduled = q.ScheduledWakeupTime()
+	w.fitContent(d)
 	w.updateAnimation(d)
 }
 
+// fitContent resizes an auto-sized window to the size of its content,
+// as observed with the Window as tag.
+func (w *Window) fitContent(d driver) {
+	if !w.autoSize {
+		return
+	}
+	q := &w.queue.q
+	var (
+		sz image.Point
+		ok bool
+	)
+	if !w.fitted {
+		sz, ok = q.ObservedSize(w)
+	}
+	for _, e := range q.Events(w) {
+		if e, isSize := e.(size.Event); isSize {
+			sz, ok = e.New, true
+		}
+	}
+	if !ok {
+		return
+	}
+	w.fitted = true
+	cnf := w.decorations.Config
+	sz = sz.Add(w.decorations.size)
+	if m := cnf.MinSize; sz.X < m.X {
+		sz.X = m.X
+	}
+	if m := cnf.MinSize; sz.Y < m.Y {
+		sz.Y = m.Y
+	}
+	if m := cnf.MaxSize; m.X > 0 && sz.X > m.X {
+		sz.X = m.X
+	}
+	if m := cnf.MaxSize; m.Y > 0 && sz.Y > m.Y {
+		sz.Y = m.Y
+	}
+	if sz == cnf.Size {
+		return
+	}
+	d.Configure([]Option{func(_ unit.Metric, cnf *Config) {
+		cnf.Size = sz
+	}})
+}
+
 // Invalidate the window such that a FrameEvent will be generated immediately.
 // If the window is inactive, the event is sent when the window becomes active.
 //
@@ -311,6 +444,15 @@ func (w *Window) Invalidate() {
 	}
 }
 
+// Diagnostics returns the Reporter of the diagnostics of the window,
+// such as the loss and recovery of the GPU device. Use its SetSink
+// method to receive the diagnostics; they are logged to standard error
+// by default. Text shapers report to the window through
+// text.Cache.SetDiagnostics.
+func (w *Window) Diagnostics() *diag.Reporter {
+	return &w.diag
+}
+
 // Option applies the options to the window.
 func (w *Window) Option(opts ...Option) {
 	w.driverDefer(func(d driver) {
@@ -367,7 +509,7 @@ func (w *Window) driverDefer(f func(d driver)) {
 func (w *Window) updateAnimation(d driver) {
 	animate := false
 	if w.stage >= system.StageRunning && w.hasNextFrame {
-		if dt := time.Until(w.nextFrame); dt <= 0 {
+		if dt := w.nextFrame.Sub(w.clock.Now()); dt <= 0 {
 			animate = true
 		} else {
 			// Schedule redraw.
@@ -384,6 +526,40 @@ func (w *Window) updateAnimation(d driver) {
 	}
 }
 
+// pause drops the pending animation redraws, and starts the timer for
+// trimming the caches of the window.
+func (w *Window) pause() {
+	if !w.redrawRequested {
+		// Keep only the scheduled redraw, to be drawn after resuming.
+		w.hasNextFrame = w.hasScheduled
+		w.nextFrame = w.nextScheduled
+	}
+	if w.trimDelay <= 0 || w.trimTimer != nil {
+		return
+	}
+	w.trimTimer = time.AfterFunc(w.trimDelay, func() {
+		w.driverDefer(func(d driver) {
+			w.trim()
+		})
+	})
+}
+
+// trim releases the caches of a paused window. They are repopulated by
+// the frames after the window resumes. The GPU resources are already
+// released when the window pauses.
+func (w *Window) trim() {
+	if w.trimTimer == nil || w.stage >= system.StageRunning {
+		// Resumed before the trim ran.
+		return
+	}
+	w.trimTimer = nil
+	if th := w.decorations.Theme; th != nil {
+		if c, ok := th.Shaper.(*text.Cache); ok {
+			c.Trim()
+		}
+	}
+}
+
 func (w *Window) wakeup() {
 	select {
 	case w.wakeups <- struct{}{}:
@@ -705,6 +881,7 @@ func (w *Window) updateState(d driver) {
 		case f := <-w.driverFuncs:
 			f(d)
 		case <-w.redraws:
+			w.redrawRequested = true
 			w.setNextFrame(time.Time{})
 			w.updateAnimation(d)
 		default:
@@ -729,6 +906,12 @@ func (w *Window) processEvent(d driver, e event.Event) {
 				w.ctx.Unlock()
 			}
 		}
+		if e2.Stage < system.StageRunning && w.stage >= system.StageRunning {
+			w.pause()
+		} else if e2.Stage >= system.StageRunning && w.trimTimer != nil {
+			w.trimTimer.Stop()
+			w.trimTimer = nil
+		}
 		w.stage = e2.Stage
 		w.updateAnimation(d)
 		w.out <- e
@@ -737,6 +920,8 @@ func (w *Window) processEvent(d driver, e event.Event) {
 		if e2.Size == (image.Point{}) {
 			panic(errors.New("internal error: zero-sized Draw"))
 		}
+		// Run the posted functions before the layout of the frame.
+		w.flushPosts(d)
 		if w.stage < system.StageRunning {
 			// No drawing if not visible.
 			break
@@ -746,6 +931,10 @@ func (w *Window) processEvent(d driver, e event.Event) {
 			frameStart = time.Now()
 		}
 		w.hasNextFrame = false
+		w.redrawRequested = false
+		if w.clock != system.RealClock {
+			e2.Now = w.clock.Now()
+		}
 		e2.Frame = w.update
 		e2.Queue = &w.queue
 
@@ -753,10 +942,14 @@ func (w *Window) processEvent(d driver, e event.Event) {
 		wrapper := &w.decorations.Ops
 		wrapper.Reset()
 		size := e2.Size // save the initial window size as the decorations will change it.
+		w.queue.q.Resize(size)
 		e2.FrameEvent.Size = w.decorate(d, e2.FrameEvent, wrapper)
 		w.out <- e2.FrameEvent
 		frame, gotFrame := w.waitFrame(d)
 		ops.AddCall(&wrapper.Internal, &frame.Internal, ops.PC{}, ops.PCFor(&frame.Internal))
+		if w.inspector.enabled {
+			wrapper = w.inspect(e2.FrameEvent, size, wrapper)
+		}
 		err := w.validateAndProcess(d, size, e2.Sync, wrapper)
 		if gotFrame {
 			// We're done with frame, let the client continue.
@@ -764,6 +957,7 @@ func (w *Window) processEvent(d driver, e event.Event) {
 		}
 		if err != nil {
 			w.destroyGPU()
+			w.closePosts()
 			w.out <- system.DestroyEvent{Err: err}
 			close(w.dead)
 			close(w.out)
@@ -775,7 +969,11 @@ func (w *Window) processEvent(d driver, e event.Event) {
 		w.out <- e
 		w.waitAck(d)
 	case system.DestroyEvent:
+		if w.trimTimer != nil {
+			w.trimTimer.Stop()
+		}
 		w.destroyGPU()
+		w.closePosts()
 		w.out <- e2
 		close(w.dead)
 		close(w.out)
@@ -783,6 +981,7 @@ func (w *Window) processEvent(d driver, e event.Event) {
 		w.out <- e2
 		w.waitAck(d)
 	case wakeupEvent:
+		w.flushPosts(d)
 	case ConfigEvent:
 		w.decorations.Config = e2.Config
 		if !w.fallbackDecorate() {
@@ -793,7 +992,14 @@ func (w *Window) processEvent(d driver, e event.Event) {
 		e2.Config.Size = e2.Config.Size.Sub(w.decorations.size)
 		w.out <- e2
 	case event.Event:
+		if w.inspector.enabled && w.inspector.Intercept(e2) {
+			w.redrawRequested = true
+			w.setNextFrame(time.Time{})
+			w.updateAnimation(d)
+			break
+		}
 		if w.queue.q.Queue(e2) {
+			w.redrawRequested = true
 			w.setNextFrame(time.Time{})
 			w.updateAnimation(d)
 		}
@@ -804,13 +1010,15 @@ func (w *Window) processEvent(d driver, e event.Event) {
 
 func (w *Window) run(options []Option) {
 	if err := newWindow(&w.callbacks, options); err != nil {
+		w.closePosts()
 		w.out <- system.DestroyEvent{Err: err}
 		close(w.dead)
 		close(w.out)
 		return
 	}
 	var wakeup func()
-	var timer *time.Timer
+	// timer fires when the clock reaches the scheduled redraw.
+	var timer system.Timer
 	for {
 		var (
 			wakeups <-chan struct{}
@@ -819,7 +1027,7 @@ func (w *Window) run(options []Option) {
 		if wakeup != nil {
 			wakeups = w.wakeups
 			if timer != nil {
-				timeC = timer.C
+				timeC = timer.C()
 			}
 		}
 		select {
@@ -827,7 +1035,7 @@ func (w *Window) run(options []Option) {
 			if timer != nil {
 				timer.Stop()
 			}
-			timer = time.NewTimer(time.Until(t))
+			timer = w.clock.NewTimer(t)
 		case <-w.dead:
 			return
 		case <-timeC:
@@ -855,16 +1063,45 @@ func (w *Window) fallbackDecorate() bool {
 	return !cnf.Decorated && cnf.Mode != Fullscreen && !w.nocontext
 }
 
+// theme returns the theme of the decorations and the inspector.
+func (w *Window) theme() *material.Theme {
+	if w.decorations.Theme == nil {
+		theme := material.NewTheme(gofont.Collection())
+		if c, ok := theme.Shaper.(*text.Cache); ok {
+			c.SetDiagnostics(&w.diag)
+		}
+		w.decorations.Theme = theme
+	}
+	return w.decorations.Theme
+}
+
+// inspect lays out the inspector of the window over content, and
+// returns the result.
+func (w *Window) inspect(e system.FrameEvent, size image.Point, content *op.Ops) *op.Ops {
+	o := &w.inspector.Ops
+	o.Reset()
+	gtx := layout.Context{
+		Ops:         o,
+		Now:         e.Now,
+		Queue:       e.Queue,
+		Metric:      e.Metric,
+		Constraints: layout.Exact(size),
+	}
+	material.Inspector(w.theme(), &w.inspector.Inspector).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		// Contain the transformations of content.
+		defer op.Offset(f32.Point{}).Push(gtx.Ops).Pop()
+		ops.AddCall(&o.Internal, &content.Internal, ops.PC{}, ops.PCFor(&content.Internal))
+		return layout.Dimensions{Size: size}
+	})
+	return o
+}
+
 // decorate the window if enabled and returns the corresponding Insets.
 func (w *Window) decorate(d driver, e system.FrameEvent, o *op.Ops) image.Point {
 	if !w.fallbackDecorate() {
 		return e.Size
 	}
-	theme := w.decorations.Theme
-	if theme == nil {
-		theme = material.NewTheme(gofont.Collection())
-		w.decorations.Theme = theme
-	}
+	theme := w.theme()
 	deco := w.decorations.Decorations
 	if deco == nil {
 		deco = new(widget.Decorations)
@@ -915,6 +1152,33 @@ func (w *Window) decorate(d driver, e system.FrameEvent, o *op.Ops) image.Point
 	return appSize
 }
 
+// SetMenuBar sets the menu bar of the window. The items of bar are the
+// menus of the bar, and choosing an item delivers a menu.Event to its tag.
+// Native menus are updated in place with the changes from the previous
+// menu bar, so it is cheap to set the menu bar again to update the
+// enabled and checked states of its items. The window keeps a copy of bar.
+//
+// SetMenuBar does nothing on platforms without native menu bars, where
+// programs can lay out the menu bar in the window with material.MenuBar.
+// A widget.MenuBar reports the chosen items of either menu bar through
+// its Events.
+//
+// Supported platforms are macOS.
+func (w *Window) SetMenuBar(bar menu.Menu) {
+	bar = bar.Clone()
+	w.driverDefer(func(d driver) {
+		md, ok := d.(menuDriver)
+		if !ok {
+			return
+		}
+		changes := menu.Diff(w.menuBar, bar)
+		w.menuBar = bar
+		if len(changes) > 0 {
+			md.UpdateMenuBar(bar, changes)
+		}
+	})
+}
+
 // Perform the actions on the window.
 func (w *Window) Perform(actions system.Action) {
 	w.driverDefer(func(d driver) {
@@ -953,6 +1217,16 @@ func (q *queue) Events(k event.Tag) []event.Event {
 	return q.q.Events(k)
 }
 
+// HitTest implements widget.HitTester.
+func (q *queue) HitTest(pos f32.Point) []router.Hit {
+	return q.q.HitTest(pos)
+}
+
+// Focused reports the focused handler to widget.Inspector.
+func (q *queue) Focused() event.Tag {
+	return q.q.Focused()
+}
+
 // Title sets the title of the window.
 func Title(t string) Option {
 	return func(_ unit.Metric, cnf *Config) {
@@ -1035,6 +1309,80 @@ func CustomRenderer(custom bool) Option {
 	}
 }
 
+// WarmUpGPU controls whether the GPU programs are prepared when the GPU
+// context is created, to avoid compilation hitches when content such as
+// gradients, images and paths are first drawn. See gpu.WarmUp.
+func WarmUpGPU(enable bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.WarmUpGPU = enable
+	}
+}
+
+// TrimDelay sets the time a paused window waits before trimming its
+// caches, such as the text cache of the fallback decorations. Programs
+// trim their own caches, for example with text.Cache.Trim, when receiving
+// a system.StageEvent that pauses the window. A zero or negative duration
+// disables trimming. The default is 10 seconds.
+func TrimDelay(d time.Duration) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.TrimDelay = d
+	}
+}
+
+// DetectIdleInvalidates enables the detection of content that issues
+// op.InvalidateOps for longer than d without changing and without input,
+// keeping the window redrawing needlessly. Such content is reported as a
+// Warning to the Diagnostics of the window. Detection hashes the
+// operations of every frame, and is intended for debugging.
+func DetectIdleInvalidates(d time.Duration) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.IdleInvalidates = d
+	}
+}
+
+// AutoSize controls whether the window is resized to fit its content
+// after every frame. The program records the size of its content with
+// layout.Observe, using the Window as tag, and lays out the content with
+// constraints that admit its natural size. The fitted size is bounded by
+// the MinSize and MaxSize of the window.
+func AutoSize(enable bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.AutoSize = enable
+	}
+}
+
+// Clock sets the source of the frame times of the window, reported by
+// system.FrameEvent.Now, and the clock of its scheduled redraws. Use a
+// system.ManualClock to step animations deterministically, for example
+// in tests. The clock can only be set when creating the window. The
+// default is the real time.
+func Clock(c system.Clock) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.Clock = c
+	}
+}
+
+// Inspector enables a debug inspector of the window content, toggled by
+// pressing I with key.ModShortcut and key.ModShift. The inspector
+// highlights the widget under the pointer, and lists the pointer
+// handlers and semantic areas under it in a side panel. The up and down
+// arrows walk the list, and Esc ends the inspection. The window content
+// receives no input while inspected.
+func Inspector(enable bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.Inspector = enable
+	}
+}
+
+// SecureContent controls whether the window content is excluded from
+// screenshots and screen recordings. Use the SecureContent field of
+// Config to determine whether the platform honored the request.
+func SecureContent(enable bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.SecureContent = enable
+	}
+}
+
 // Decorated controls whether Gio and/or the platform are responsible
 // for drawing window decorations. Providing false indicates that
 // the application will either be undecorated or will draw its own decorations.
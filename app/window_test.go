@@ -0,0 +1,261 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"reflect"
+	"sync"
+	"testing"
+
+	"gioui.org/io/event"
+	"gioui.org/io/menu"
+	"gioui.org/io/size"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+// configDriver is a driver that records its configuration.
+type configDriver struct {
+	driver
+	// secure reports whether the platform supports SecureContent.
+	secure bool
+	config Config
+	events []ConfigEvent
+}
+
+func (d *configDriver) Configure(options []Option) {
+	prev := d.config
+	cnf := d.config
+	cnf.apply(unit.Metric{}, options)
+	d.config.Size = cnf.Size
+	if d.secure {
+		d.config.SecureContent = cnf.SecureContent
+	}
+	if d.config != prev {
+		d.events = append(d.events, ConfigEvent{Config: d.config})
+	}
+}
+
+func TestSecureContent(t *testing.T) {
+	for _, supported := range []bool{true, false} {
+		w := &Window{
+			driverFuncs: make(chan func(d driver), 1),
+			wakeups:     make(chan struct{}, 1),
+			dead:        make(chan struct{}),
+		}
+		d := &configDriver{secure: supported}
+		option := func(opts ...Option) {
+			w.Option(opts...)
+			f := <-w.driverFuncs
+			f(d)
+		}
+		option(SecureContent(true))
+		if got := d.config.SecureContent; got != supported {
+			t.Errorf("supported %v: SecureContent is %v after enabling", supported, got)
+		}
+		// Other options leave the flag alone.
+		option(Title("Vault"))
+		if got := d.config.SecureContent; got != supported {
+			t.Errorf("supported %v: SecureContent is %v after Title", supported, got)
+		}
+		option(SecureContent(false))
+		if d.config.SecureContent {
+			t.Errorf("supported %v: SecureContent is true after disabling", supported)
+		}
+		exp := 0
+		if supported {
+			exp = 2
+		}
+		if n := len(d.events); n != exp {
+			t.Errorf("supported %v: got %d ConfigEvents, expected %d", supported, n, exp)
+		}
+	}
+}
+
+// menuBarDriver is a driver with a native menu bar.
+type menuBarDriver struct {
+	driver
+	bar     menu.Menu
+	changes [][]menu.Change
+}
+
+func (d *menuBarDriver) UpdateMenuBar(bar menu.Menu, changes []menu.Change) {
+	d.bar = bar
+	d.changes = append(d.changes, changes)
+}
+
+func TestSetMenuBar(t *testing.T) {
+	w := &Window{
+		driverFuncs: make(chan func(d driver), 1),
+		wakeups:     make(chan struct{}, 1),
+		dead:        make(chan struct{}),
+	}
+	d := new(menuBarDriver)
+	setMenuBar := func(bar menu.Menu) {
+		w.SetMenuBar(bar)
+		f := <-w.driverFuncs
+		f(d)
+	}
+	var save int
+	file := &menu.Menu{Items: []menu.Item{{Title: "Save", Tag: &save, Disabled: true}}}
+	bar := menu.Menu{Items: []menu.Item{{Title: "File", Submenu: file}}}
+	setMenuBar(bar)
+	if n := len(d.changes); n != 1 || d.changes[0][0].Type != menu.ChangeItems {
+		t.Fatalf("got changes %+v; expected the initial menu bar", d.changes)
+	}
+	// Modifying the menu bar in place and setting it again updates the
+	// changed item.
+	file.Items[0].Disabled = false
+	setMenuBar(bar)
+	if n := len(d.changes); n != 2 {
+		t.Fatalf("got %d updates; expected 2", n)
+	}
+	want := []menu.Change{{Type: menu.ChangeItem, Path: []int{0, 0}, Item: file.Items[0]}}
+	if got := d.changes[1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got changes %+v; expected %+v", got, want)
+	}
+	// Unchanged menu bars don't update the driver.
+	setMenuBar(bar)
+	if n := len(d.changes); n != 2 {
+		t.Errorf("got %d updates after an unchanged menu bar; expected 2", n)
+	}
+	if d.bar.Items[0].Submenu.Items[0].Disabled {
+		t.Error("driver menu bar not updated")
+	}
+}
+
+func TestAutoSize(t *testing.T) {
+	w := &Window{autoSize: true}
+	w.decorations.Config.MaxSize = image.Pt(200, 200)
+	d := new(configDriver)
+	var ops op.Ops
+	frame := func(sz image.Point) {
+		ops.Reset()
+		size.Op{Tag: w, Size: sz}.Add(&ops)
+		w.queue.q.Frame(&ops)
+		w.fitContent(d)
+		w.decorations.Config.Size = d.config.Size
+	}
+	// The first observation fits the window.
+	frame(image.Pt(100, 50))
+	if got, want := d.config.Size, image.Pt(100, 50); got != want {
+		t.Errorf("initial size is %v, expected %v", got, want)
+	}
+	frame(image.Pt(150, 50))
+	if got, want := d.config.Size, image.Pt(150, 50); got != want {
+		t.Errorf("size is %v after growing content, expected %v", got, want)
+	}
+	frame(image.Pt(300, 50))
+	if got, want := d.config.Size, image.Pt(200, 50); got != want {
+		t.Errorf("size is %v after exceeding MaxSize, expected %v", got, want)
+	}
+	// Unchanged content issues no request.
+	n := len(d.events)
+	frame(image.Pt(300, 50))
+	if len(d.events) != n {
+		t.Errorf("unchanged content issued %v", d.events[n:])
+	}
+}
+
+func TestPost(t *testing.T) {
+	w := &Window{
+		out:              make(chan event.Event),
+		dead:             make(chan struct{}),
+		redraws:          make(chan struct{}, 1),
+		immediateRedraws: make(chan struct{}),
+		wakeups:          make(chan struct{}, 1),
+		driverFuncs:      make(chan func(d driver), 1),
+	}
+	// Deliver the posted functions on wakeup, like the driver loop.
+	stop := make(chan struct{})
+	driverDone := make(chan struct{})
+	go func() {
+		defer close(driverDone)
+		for {
+			select {
+			case <-w.wakeups:
+				select {
+				case <-w.redraws:
+				default:
+				}
+				w.flushPosts(nil)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	// Run the posted functions, like the event loop of a program.
+	uiDone := make(chan struct{})
+	go func() {
+		defer close(uiDone)
+		for e := range w.out {
+			if e, ok := e.(PostEvent); ok {
+				e.Run()
+			}
+		}
+	}()
+
+	const goroutines, posts = 4, 100
+	var ran [goroutines][]int
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < posts; i++ {
+				i := i
+				if err := w.Post(func() { ran[g] = append(ran[g], i) }); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.PostAndWait(func() {}); err != nil {
+		t.Fatal(err)
+	}
+	for g, ran := range ran {
+		if len(ran) != posts {
+			t.Fatalf("goroutine %d: ran %d functions, expected %d", g, len(ran), posts)
+		}
+		for i, v := range ran {
+			if v != i {
+				t.Fatalf("goroutine %d: function %d ran as number %d", g, v, i)
+			}
+		}
+	}
+
+	close(stop)
+	<-driverDone
+	// Destroy the window.
+	w.Post(func() { t.Error("pending function ran after destroy") })
+	w.closePosts()
+	close(w.dead)
+	close(w.out)
+	<-uiDone
+	if err := w.Post(func() {}); err != ErrClosed {
+		t.Errorf("Post after destroy returned %v, expected ErrClosed", err)
+	}
+	if err := w.PostAndWait(func() {}); err != ErrClosed {
+		t.Errorf("PostAndWait after destroy returned %v, expected ErrClosed", err)
+	}
+}
+
+func TestPosixLocaleTag(t *testing.T) {
+	for _, tc := range []struct {
+		locale, tag string
+	}{
+		{"en_US.UTF-8", "en-US"},
+		{"sr_RS@latin", "sr-RS"},
+		{"zh_hant_tw", "zh-Hant-TW"},
+		{"de", "de"},
+		{"C", ""},
+		{"POSIX", ""},
+	} {
+		if got := posixLocaleTag(tc.locale); got != tc.tag {
+			t.Errorf("%q: got %q, expected %q", tc.locale, got, tc.tag)
+		}
+	}
+}
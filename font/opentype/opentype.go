@@ -124,6 +124,13 @@ func (f *Font) Shape(ppem fixed.Int26_6, str text.Layout) clip.PathSpec {
 	return textPath(&buf, ppem, []*opentype{{Font: f.font, Hinting: font.HintingFull}}, str)
 }
 
+// HasGlyph reports whether the font has a glyph for r.
+func (f *Font) HasGlyph(r rune) bool {
+	var buf sfnt.Buffer
+	o := &opentype{Font: f.font}
+	return o.HasGlyph(&buf, r)
+}
+
 func (f *Font) Metrics(ppem fixed.Int26_6) font.Metrics {
 	o := &opentype{Font: f.font, Hinting: font.HintingFull}
 	var buf sfnt.Buffer
@@ -144,6 +151,17 @@ func (c *Collection) Shape(ppem fixed.Int26_6, str text.Layout) clip.PathSpec {
 	return textPath(&buf, ppem, c.fonts, str)
 }
 
+// HasGlyph reports whether a font of the collection has a glyph for r.
+func (c *Collection) HasGlyph(r rune) bool {
+	var buf sfnt.Buffer
+	for _, f := range c.fonts {
+		if f.HasGlyph(&buf, r) {
+			return true
+		}
+	}
+	return false
+}
+
 func fontForGlyph(buf *sfnt.Buffer, fonts []*opentype, r rune) *opentype {
 	if len(fonts) < 1 {
 		return nil
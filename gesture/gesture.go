@@ -21,12 +21,24 @@ import (
 	"gioui.org/io/key"
 	"gioui.org/io/pointer"
 	"gioui.org/op"
+	"gioui.org/op/clip"
 	"gioui.org/unit"
 )
 
 // The duration is somewhat arbitrary.
 const doubleClickDuration = 200 * time.Millisecond
 
+// Config tunes the thresholds of gestures. The zero value of a field
+// means its default.
+type Config struct {
+	// TouchSlop is the distance a pointer must move before a drag or
+	// a scroll starts. Zero means 3dp.
+	TouchSlop unit.Value
+	// DoubleClickDuration is the maximum duration between successive
+	// clicks counted by ClickEvent.NumClicks. Zero means 200ms.
+	DoubleClickDuration time.Duration
+}
+
 // Hover detects the hover gesture for a pointer area.
 type Hover struct {
 	// entered tracks whether the pointer is inside the gesture.
@@ -70,6 +82,9 @@ func (h *Hover) Hovered(q event.Queue) bool {
 // Click detects click gestures in the form
 // of ClickEvents.
 type Click struct {
+	// Config tunes the click detection.
+	Config Config
+
 	// clickedAt is the timestamp at which
 	// the last click occurred.
 	clickedAt time.Duration
@@ -93,7 +108,11 @@ type ClickEvent struct {
 	Source    pointer.Source
 	Modifiers key.Modifiers
 	// NumClicks records successive clicks occurring
-	// within a short duration of each other.
+	// within a short duration of each other. There is no
+	// upper bound, so triple clicks or quadruple taps are
+	// reported as 3 and 4. The count restarts at 1 when
+	// a click follows the previous by more than
+	// Config.DoubleClickDuration.
 	NumClicks int
 }
 
@@ -101,6 +120,12 @@ type ClickType uint8
 
 // Drag detects drag gestures in the form of pointer.Drag events.
 type Drag struct {
+	// Config tunes the drag detection.
+	Config Config
+	// Arena, if set, arbitrates the pointer between the Drag
+	// and other gestures.
+	Arena *Arena
+
 	dragging bool
 	pressed  bool
 	pid      pointer.ID
@@ -108,10 +133,163 @@ type Drag struct {
 	grab     bool
 }
 
+// EdgeSwipe detects drags that start near an edge of an area, such as
+// the swipes that open navigation drawers.
+type EdgeSwipe struct {
+	// Edge is the edge where swipes start.
+	Edge Edge
+	// Margin is the size of the strip along the edge where swipes
+	// start. Zero means 20dp.
+	Margin unit.Value
+	// Distance is the swipe distance from the start of the swipe that
+	// completes it, typically the size of the drawer. Zero means 280dp.
+	Distance unit.Value
+	// Config tunes the drag detection.
+	Config Config
+	// Arena, if set, arbitrates the pointer between the EdgeSwipe
+	// and other gestures.
+	Arena *Arena
+
+	drag      Drag
+	swiping   bool
+	start     float32
+	progress  float32
+	estimator fling.Extrapolation
+}
+
+// SwipeEvent describes the progress of an EdgeSwipe.
+type SwipeEvent struct {
+	Type SwipeType
+	// Progress is the fraction of the swipe distance covered, in the
+	// range [0, 1].
+	Progress float32
+	// Velocity is the speed of the swipe in pixels per second at
+	// release, positive in the direction away from the edge.
+	Velocity float32
+	// Open is the decision of a SwipeRelease: whether the swipe was
+	// far or fast enough for opening the drawer.
+	Open bool
+}
+
+// Edge is an edge of an area.
+type Edge uint8
+
+// SwipeType is the type of a SwipeEvent.
+type SwipeType uint8
+
+// SelectionHandles tracks the draggable handles at the ends of a text
+// selection, as used for selecting text on touch screens. The text widget
+// maps the positions of dragged handles to text offsets.
+type SelectionHandles struct {
+	// Config tunes the drag detection.
+	Config Config
+	// Arena, if set, arbitrates the pointer between the handles and
+	// other gestures.
+	Arena *Arena
+
+	handles [2]selectionHandle
+}
+
+type selectionHandle struct {
+	drag Drag
+	// anchor is the text position of the handle.
+	anchor f32.Point
+	// grab is the distance from the anchor to the pointer at the start
+	// of a drag.
+	grab f32.Point
+}
+
+// Handle identifies a handle of SelectionHandles.
+type Handle uint8
+
+// SelectionEvent reports the text offset of a dragged handle.
+type SelectionEvent struct {
+	Handle Handle
+	// Offset is the text offset at the anchor of the dragged handle.
+	Offset int
+}
+
+// Rotate detects two-finger gestures that rotate, scale and move content,
+// such as in photo and map viewers. To ignore jitter, a rotation starts
+// only after the fingers have turned past a threshold angle, and then
+// tracks the fingers without jumping.
+type Rotate struct {
+	// Threshold is the angle in radians the fingers must turn before
+	// the rotation starts. Zero means 0.1 radians.
+	Threshold float32
+
+	pointers [2]rotatePointer
+	// n is the number of pressed pointers.
+	n        int
+	rotating bool
+	// angle is the angle of the fingers at the start of the gesture,
+	// and of the most recent rotation after the rotation starts.
+	angle float32
+	// dist and center are the distance and midpoint of the fingers in
+	// the most recent event.
+	dist   float32
+	center f32.Point
+}
+
+type rotatePointer struct {
+	id  pointer.ID
+	pos f32.Point
+}
+
+// RotateEvent describes the movement of the fingers of a Rotate since
+// the previous event.
+type RotateEvent struct {
+	Type RotateType
+	// Angle is the rotation in radians, turning the positive x axis
+	// towards the positive y axis.
+	Angle float32
+	// Scale is the ratio of the distance between the fingers and the
+	// distance in the previous event.
+	Scale float32
+	// Center is the midpoint of the fingers.
+	Center f32.Point
+	// Offset is the movement of Center.
+	Offset f32.Point
+}
+
+// RotateType is the type of a RotateEvent.
+type RotateType uint8
+
+// Transform detects one and two-finger gestures that move, scale and
+// rotate content, and reports their effect as affine transformations
+// that a canvas can apply directly. One finger moves the content; two
+// fingers also scale and rotate it around their midpoint.
+type Transform struct {
+	pointers [2]rotatePointer
+	// n is the number of pressed pointers.
+	n int
+}
+
+// TransformEvent describes the movement of the fingers of a Transform
+// since the previous event.
+type TransformEvent struct {
+	Type TransformType
+	// Delta maps positions before the event to positions after the
+	// event. The content transformation t is updated by
+	// t = e.Delta.Mul(t).
+	Delta f32.Affine2D
+	// Pointers is the number of pressed fingers.
+	Pointers int
+}
+
+// TransformType is the type of a TransformEvent.
+type TransformType uint8
+
 // Scroll detects scroll gestures and reduces them to
 // scroll distances. Scroll recognizes mouse wheel
 // movements as well as drag and fling touch gestures.
 type Scroll struct {
+	// Config tunes the drag detection.
+	Config Config
+	// Arena, if set, arbitrates the pointer between the Scroll
+	// and other gestures.
+	Arena *Arena
+
 	dragging  bool
 	axis      Axis
 	estimator fling.Extrapolation
@@ -119,10 +297,28 @@ type Scroll struct {
 	pid       pointer.ID
 	grab      bool
 	last      int
+	// frameClock is set when the pointer events of the drag lack
+	// timestamps, and the frame times since clockStart time the
+	// samples instead.
+	frameClock bool
+	clockStart time.Time
 	// Leftover scroll.
 	scroll float32
 }
 
+// Arena arbitrates a pointer between competing gestures, such as a
+// vertical Scroll of a list and a horizontal Drag of its items. The first
+// gesture to move the pointer past its threshold wins; the other gestures
+// of the Arena are canceled until the winner releases the pointer, or is
+// pressed again. Claims are decided in the order the gestures process
+// their events, so gestures should process their events every frame.
+//
+// The zero Arena is ready to use, and a nil Arena never cancels gestures.
+type Arena struct {
+	// winner is the gesture holding the pointer.
+	winner interface{}
+}
+
 type ScrollState uint8
 
 type Axis uint8
@@ -145,6 +341,45 @@ const (
 	TypeCancel
 )
 
+const (
+	EdgeLeft Edge = iota
+	EdgeRight
+	EdgeTop
+	EdgeBottom
+)
+
+const (
+	// SwipeMove is reported when the swipe progress changes.
+	SwipeMove SwipeType = iota
+	// SwipeRelease is reported when the swipe ends.
+	SwipeRelease
+	// SwipeCancel is reported when the swipe is cancelled, for
+	// example by losing the pointer to another gesture.
+	SwipeCancel
+)
+
+const (
+	// RotateMove is reported when the fingers move.
+	RotateMove RotateType = iota
+	// RotateEnd is reported when a finger is released or cancelled.
+	RotateEnd
+)
+
+const (
+	// TransformMove is reported when the fingers move.
+	TransformMove TransformType = iota
+	// TransformEnd is reported when the last finger is released, or
+	// the fingers are cancelled.
+	TransformEnd
+)
+
+const (
+	// HandleStart is the handle at the start of the selection.
+	HandleStart Handle = iota
+	// HandleEnd is the handle at the end of the selection.
+	HandleEnd
+)
+
 const (
 	// StateIdle is the default scroll state.
 	StateIdle ScrollState = iota
@@ -157,6 +392,31 @@ const (
 
 var touchSlop = unit.Dp(3)
 
+// defaultRotateThreshold is the default Rotate.Threshold.
+const defaultRotateThreshold = 0.1
+
+var (
+	defaultSwipeMargin   = unit.Dp(20)
+	defaultSwipeDistance = unit.Dp(280)
+	// swipeFlingVelocity is the release velocity, in units per second,
+	// that decides a swipe regardless of its progress.
+	swipeFlingVelocity = unit.Dp(300)
+)
+
+func (c Config) touchSlop() unit.Value {
+	if c.TouchSlop.V != 0 {
+		return c.TouchSlop
+	}
+	return touchSlop
+}
+
+func (c Config) doubleClick() time.Duration {
+	if c.DoubleClickDuration != 0 {
+		return c.DoubleClickDuration
+	}
+	return doubleClickDuration
+}
+
 // Add the handler to the operation list to receive click events.
 func (c *Click) Add(ops *op.Ops) {
 	pointer.InputOp{
@@ -190,7 +450,7 @@ func (c *Click) Events(q event.Queue) []ClickEvent {
 			}
 			c.pressed = false
 			if c.entered {
-				if e.Time-c.clickedAt < doubleClickDuration {
+				if e.Time-c.clickedAt < c.Config.doubleClick() {
 					c.clicks++
 				} else {
 					c.clicks = 1
@@ -265,7 +525,10 @@ func (s *Scroll) Stop() {
 }
 
 // Scroll detects the scrolling distance from the available events and
-// ongoing fling gestures.
+// ongoing fling gestures. The fling velocity is estimated from the
+// timestamps of the pointer events, so it doesn't depend on the frame
+// rate. If the events of a drag lack timestamps, the frame time t is
+// used instead.
 func (s *Scroll) Scroll(cfg unit.Metric, q event.Queue, t time.Time, axis Axis) int {
 	if s.axis != axis {
 		s.axis = axis
@@ -287,23 +550,31 @@ func (s *Scroll) Scroll(cfg unit.Metric, q event.Queue, t time.Time, axis Axis)
 			if e.Source != pointer.Touch && runtime.GOOS != "android" {
 				break
 			}
+			s.Arena.press(s)
 			s.Stop()
 			s.estimator = fling.Extrapolation{}
 			v := s.val(e.Position)
 			s.last = int(math.Round(float64(v)))
-			s.estimator.Sample(e.Time, v)
+			s.frameClock = e.Time == 0
+			s.clockStart = t
+			s.estimator.Sample(s.sampleTime(e, t), v)
 			s.dragging = true
 			s.pid = e.PointerID
 		case pointer.Release:
-			if s.pid != e.PointerID {
+			// Only fling the drags of presses the scroll took; the
+			// estimator holds the samples of the last drag.
+			if !s.dragging || s.pid != e.PointerID {
 				break
 			}
 			fling := s.estimator.Estimate()
-			if slop, d := float32(cfg.Px(touchSlop)), fling.Distance; d < -slop || d > slop {
+			if slop, d := float32(cfg.Px(s.Config.touchSlop())), fling.Distance; d < -slop || d > slop {
 				s.flinger.Start(cfg, t, fling.Velocity)
 			}
 			fallthrough
 		case pointer.Cancel:
+			if s.dragging && s.pid == e.PointerID {
+				s.Arena.release(s)
+			}
 			s.dragging = false
 			s.grab = false
 		case pointer.Scroll:
@@ -320,13 +591,22 @@ func (s *Scroll) Scroll(cfg unit.Metric, q event.Queue, t time.Time, axis Axis)
 			if !s.dragging || s.pid != e.PointerID {
 				continue
 			}
+			if s.Arena.lost(s) {
+				s.dragging = false
+				s.grab = false
+				continue
+			}
 			val := s.val(e.Position)
-			s.estimator.Sample(e.Time, val)
+			s.estimator.Sample(s.sampleTime(e, t), val)
 			v := int(math.Round(float64(val)))
 			dist := s.last - v
 			if e.Priority < pointer.Grabbed {
-				slop := cfg.Px(touchSlop)
+				slop := cfg.Px(s.Config.touchSlop())
 				if dist := dist; dist >= slop || -slop >= dist {
+					if !s.Arena.claim(s) {
+						s.dragging = false
+						continue
+					}
 					s.grab = true
 				}
 			} else {
@@ -339,6 +619,20 @@ func (s *Scroll) Scroll(cfg unit.Metric, q event.Queue, t time.Time, axis Axis)
 	return total
 }
 
+// sampleTime returns the time of the velocity sample of e: the event
+// timestamp, or the frame time t relative to the start of the drag if
+// the events lack timestamps. A press at time zero samples zero on
+// both clocks, so the event clock takes over at the first timestamp.
+func (s *Scroll) sampleTime(e pointer.Event, t time.Time) time.Duration {
+	if e.Time != 0 {
+		s.frameClock = false
+	}
+	if s.frameClock {
+		return t.Sub(s.clockStart)
+	}
+	return e.Time
+}
+
 func (s *Scroll) val(p f32.Point) float32 {
 	if s.axis == Horizontal {
 		return p.X
@@ -379,13 +673,16 @@ func (d *Drag) Events(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event
 
 		switch e.Type {
 		case pointer.Press:
-			if !(e.Buttons == pointer.ButtonPrimary || e.Source == pointer.Touch) {
+			// Pens press on contact, including with the eraser or
+			// with the barrel button held.
+			if !(e.Buttons == pointer.ButtonPrimary || e.Source == pointer.Touch || e.Source == pointer.Pen) {
 				continue
 			}
 			d.pressed = true
 			if d.dragging {
 				continue
 			}
+			d.Arena.press(d)
 			d.dragging = true
 			d.pid = e.PointerID
 			d.start = e.Position
@@ -393,6 +690,11 @@ func (d *Drag) Events(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event
 			if !d.dragging || e.PointerID != d.pid {
 				continue
 			}
+			if d.Arena.lost(d) {
+				d.cancel()
+				e.Type = pointer.Cancel
+				break
+			}
 			switch axis {
 			case Horizontal:
 				e.Position.Y = d.start.Y
@@ -403,8 +705,13 @@ func (d *Drag) Events(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event
 			}
 			if e.Priority < pointer.Grabbed {
 				diff := e.Position.Sub(d.start)
-				slop := cfg.Px(touchSlop)
+				slop := cfg.Px(d.Config.touchSlop())
 				if diff.X*diff.X+diff.Y*diff.Y > float32(slop*slop) {
+					if !d.Arena.claim(d) {
+						d.cancel()
+						e.Type = pointer.Cancel
+						break
+					}
 					d.grab = true
 				}
 			}
@@ -413,6 +720,7 @@ func (d *Drag) Events(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event
 			if !d.dragging || e.PointerID != d.pid {
 				continue
 			}
+			d.Arena.release(d)
 			d.dragging = false
 			d.grab = false
 		}
@@ -423,12 +731,424 @@ func (d *Drag) Events(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event
 	return events
 }
 
+// cancel the drag after losing the pointer to another gesture.
+func (d *Drag) cancel() {
+	d.dragging = false
+	d.pressed = false
+	d.grab = false
+}
+
 // Dragging reports whether it is currently in use.
 func (d *Drag) Dragging() bool { return d.dragging }
 
 // Pressed returns whether a pointer is pressing.
 func (d *Drag) Pressed() bool { return d.pressed }
 
+// Add the handler to the operation list to receive swipes that start
+// near the edge of bounds. Only the strip along the edge is covered by
+// the handler, so presses elsewhere in bounds reach other handlers.
+func (s *EdgeSwipe) Add(ops *op.Ops, cfg unit.Metric, bounds image.Rectangle) {
+	m := cfg.Px(defaultSwipeMargin)
+	if s.Margin.V != 0 {
+		m = cfg.Px(s.Margin)
+	}
+	strip := bounds
+	switch s.Edge {
+	case EdgeLeft:
+		strip.Max.X = strip.Min.X + m
+	case EdgeRight:
+		strip.Min.X = strip.Max.X - m
+	case EdgeTop:
+		strip.Max.Y = strip.Min.Y + m
+	case EdgeBottom:
+		strip.Min.Y = strip.Max.Y - m
+	}
+	defer clip.Rect(strip.Intersect(bounds)).Push(ops).Pop()
+	s.drag.Add(ops)
+}
+
+// Events returns the next swipe events, if any.
+func (s *EdgeSwipe) Events(cfg unit.Metric, q event.Queue) []SwipeEvent {
+	s.drag.Arena = s.Arena
+	s.drag.Config = s.Config
+	axis := Horizontal
+	if s.Edge == EdgeTop || s.Edge == EdgeBottom {
+		axis = Vertical
+	}
+	dist := float32(cfg.Px(defaultSwipeDistance))
+	if s.Distance.V != 0 {
+		dist = float32(cfg.Px(s.Distance))
+	}
+	var events []SwipeEvent
+	for _, e := range s.drag.Events(cfg, q, axis) {
+		v := s.val(e.Position)
+		switch e.Type {
+		case pointer.Press:
+			if s.swiping {
+				break
+			}
+			s.swiping = true
+			s.start = v
+			s.progress = 0
+			s.estimator = fling.Extrapolation{}
+			s.estimator.Sample(e.Time, v)
+		case pointer.Drag:
+			if !s.swiping {
+				break
+			}
+			s.estimator.Sample(e.Time, v)
+			p := (v - s.start) / dist
+			if p < 0 {
+				p = 0
+			} else if p > 1 {
+				p = 1
+			}
+			if p != s.progress {
+				s.progress = p
+				events = append(events, SwipeEvent{Type: SwipeMove, Progress: p})
+			}
+		case pointer.Release:
+			if !s.swiping {
+				break
+			}
+			s.swiping = false
+			// The estimate is in the direction of scrolling, opposite
+			// to the pointer movement.
+			vel := -s.estimator.Estimate().Velocity
+			open := s.progress >= .5
+			if min := float32(cfg.Px(swipeFlingVelocity)); vel >= min {
+				open = true
+			} else if vel <= -min {
+				open = false
+			}
+			events = append(events, SwipeEvent{Type: SwipeRelease, Progress: s.progress, Velocity: vel, Open: open})
+		case pointer.Cancel:
+			if !s.swiping {
+				break
+			}
+			s.swiping = false
+			events = append(events, SwipeEvent{Type: SwipeCancel, Progress: s.progress})
+		}
+	}
+	return events
+}
+
+// val returns the position along the swipe, increasing away from the
+// edge.
+func (s *EdgeSwipe) val(p f32.Point) float32 {
+	switch s.Edge {
+	case EdgeRight:
+		return -p.X
+	case EdgeTop:
+		return p.Y
+	case EdgeBottom:
+		return -p.Y
+	default:
+		return p.X
+	}
+}
+
+// Swiping reports whether a swipe is in progress.
+func (s *EdgeSwipe) Swiping() bool { return s.swiping }
+
+// Progress returns the progress of the current or most recent swipe.
+func (s *EdgeSwipe) Progress() float32 { return s.progress }
+
+// Add the handle h to the operation list to receive drags in area. The
+// anchor is the text position the handle points to, typically an end of
+// the selection. Both anchor and area are in the coordinates of the text.
+func (s *SelectionHandles) Add(ops *op.Ops, h Handle, anchor f32.Point, area image.Rectangle) {
+	hd := &s.handles[h]
+	hd.anchor = anchor
+	defer clip.Rect(area).Push(ops).Pop()
+	hd.drag.Add(ops)
+}
+
+// Events returns the new offsets of the dragged handles. The offset
+// function maps a position in the coordinates of the text to a text
+// offset. The position of a dragged handle keeps the distance between
+// the pointer and the anchor at the start of the drag, so the handle
+// doesn't jump under the pointer.
+func (s *SelectionHandles) Events(cfg unit.Metric, q event.Queue, offset func(pos f32.Point) int) []SelectionEvent {
+	var events []SelectionEvent
+	for i := range s.handles {
+		hd := &s.handles[i]
+		hd.drag.Config = s.Config
+		hd.drag.Arena = s.Arena
+		for _, e := range hd.drag.Events(cfg, q, Both) {
+			switch e.Type {
+			case pointer.Press:
+				hd.grab = e.Position.Sub(hd.anchor)
+			case pointer.Drag:
+				events = append(events, SelectionEvent{
+					Handle: Handle(i),
+					Offset: offset(e.Position.Sub(hd.grab)),
+				})
+			}
+		}
+	}
+	return events
+}
+
+// Dragging reports whether a handle is being dragged.
+func (s *SelectionHandles) Dragging() bool {
+	return s.handles[HandleStart].drag.Dragging() || s.handles[HandleEnd].drag.Dragging()
+}
+
+// Add the gesture to detect two-finger gestures over the current pointer
+// area.
+func (r *Rotate) Add(ops *op.Ops) {
+	pointer.InputOp{
+		Tag:   r,
+		Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel,
+	}.Add(ops)
+}
+
+// Events returns the next events.
+func (r *Rotate) Events(q event.Queue) []RotateEvent {
+	var events []RotateEvent
+	for _, evt := range q.Events(r) {
+		e, ok := evt.(pointer.Event)
+		if !ok {
+			continue
+		}
+		idx := r.pointer(e.PointerID)
+		switch e.Type {
+		case pointer.Press:
+			if idx != -1 || r.n == len(r.pointers) {
+				break
+			}
+			r.pointers[r.n] = rotatePointer{id: e.PointerID, pos: e.Position}
+			r.n++
+			if r.n == len(r.pointers) {
+				r.rotating = false
+				r.angle, r.dist, r.center = r.measure()
+			}
+		case pointer.Drag:
+			if idx == -1 {
+				break
+			}
+			r.pointers[idx].pos = e.Position
+			if r.n < len(r.pointers) {
+				break
+			}
+			angle, dist, center := r.measure()
+			ev := RotateEvent{Type: RotateMove, Scale: 1, Center: center, Offset: center.Sub(r.center)}
+			if r.dist > 0 {
+				ev.Scale = dist / r.dist
+			}
+			da := normalizeAngle(angle - r.angle)
+			switch {
+			case r.rotating:
+				ev.Angle = da
+				r.angle = angle
+			case abs32(da) >= r.threshold():
+				// Rebase the rotation to the current angle, to avoid a
+				// jump by the threshold.
+				r.rotating = true
+				r.angle = angle
+			}
+			r.dist, r.center = dist, center
+			events = append(events, ev)
+		case pointer.Release:
+			if idx == -1 {
+				break
+			}
+			if r.n == len(r.pointers) {
+				events = append(events, RotateEvent{Type: RotateEnd, Scale: 1, Center: r.center})
+			}
+			r.rotating = false
+			r.n--
+			copy(r.pointers[idx:], r.pointers[idx+1:])
+		case pointer.Cancel:
+			// Cancel applies to every pointer.
+			if r.n == len(r.pointers) {
+				events = append(events, RotateEvent{Type: RotateEnd, Scale: 1, Center: r.center})
+			}
+			r.n = 0
+			r.rotating = false
+		}
+	}
+	return events
+}
+
+// Rotating reports whether a rotation is in progress.
+func (r *Rotate) Rotating() bool {
+	return r.rotating
+}
+
+// pointer returns the index of the pointer id, or -1.
+func (r *Rotate) pointer(id pointer.ID) int {
+	for i := 0; i < r.n; i++ {
+		if r.pointers[i].id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// measure returns the angle, distance and midpoint of the fingers.
+func (r *Rotate) measure() (angle, dist float32, center f32.Point) {
+	p0, p1 := r.pointers[0].pos, r.pointers[1].pos
+	v := p1.Sub(p0)
+	angle = float32(math.Atan2(float64(v.Y), float64(v.X)))
+	dist = float32(math.Hypot(float64(v.X), float64(v.Y)))
+	center = p0.Add(p1).Mul(.5)
+	return
+}
+
+func (r *Rotate) threshold() float32 {
+	if r.Threshold != 0 {
+		return r.Threshold
+	}
+	return defaultRotateThreshold
+}
+
+// normalizeAngle returns a in the range (-π, π].
+func normalizeAngle(a float32) float32 {
+	for a > math.Pi {
+		a -= 2 * math.Pi
+	}
+	for a <= -math.Pi {
+		a += 2 * math.Pi
+	}
+	return a
+}
+
+// Add the gesture to detect transform gestures over the current pointer
+// area.
+func (t *Transform) Add(ops *op.Ops) {
+	pointer.InputOp{
+		Tag:   t,
+		Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel,
+	}.Add(ops)
+}
+
+// Events returns the next events. The Delta of the events of a frame
+// compose into the transformation of the frame.
+func (t *Transform) Events(q event.Queue) []TransformEvent {
+	var events []TransformEvent
+	for _, evt := range q.Events(t) {
+		e, ok := evt.(pointer.Event)
+		if !ok {
+			continue
+		}
+		idx := t.pointer(e.PointerID)
+		switch e.Type {
+		case pointer.Press:
+			if idx != -1 || t.n == len(t.pointers) {
+				break
+			}
+			t.pointers[t.n] = rotatePointer{id: e.PointerID, pos: e.Position}
+			t.n++
+		case pointer.Drag:
+			if idx == -1 {
+				break
+			}
+			old := t.pointers
+			t.pointers[idx].pos = e.Position
+			ev := TransformEvent{Type: TransformMove, Pointers: t.n}
+			if t.n == 1 {
+				ev.Delta = f32.Affine2D{}.Offset(e.Position.Sub(old[0].pos))
+			} else {
+				ev.Delta = similarity(old[0].pos, old[1].pos, t.pointers[0].pos, t.pointers[1].pos)
+			}
+			events = append(events, ev)
+		case pointer.Release:
+			if idx == -1 {
+				break
+			}
+			t.n--
+			copy(t.pointers[idx:], t.pointers[idx+1:])
+			if t.n == 0 {
+				events = append(events, TransformEvent{Type: TransformEnd})
+			}
+		case pointer.Cancel:
+			// Cancel applies to every pointer.
+			if t.n > 0 {
+				events = append(events, TransformEvent{Type: TransformEnd})
+			}
+			t.n = 0
+		}
+	}
+	return events
+}
+
+// Active reports whether a finger is pressed.
+func (t *Transform) Active() bool {
+	return t.n > 0
+}
+
+// pointer returns the index of the pointer id, or -1.
+func (t *Transform) pointer(id pointer.ID) int {
+	for i := 0; i < t.n; i++ {
+		if t.pointers[i].id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// similarity returns the transformation without shear that maps p0 to
+// q0 and p1 to q1.
+func similarity(p0, p1, q0, q1 f32.Point) f32.Affine2D {
+	vp, vq := p1.Sub(p0), q1.Sub(q0)
+	dp := math.Hypot(float64(vp.X), float64(vp.Y))
+	dq := math.Hypot(float64(vq.X), float64(vq.Y))
+	c := p0.Add(p1).Mul(.5)
+	t := f32.Affine2D{}
+	if dp > 0 && dq > 0 {
+		s := float32(dq / dp)
+		angle := math.Atan2(float64(vq.Y), float64(vq.X)) - math.Atan2(float64(vp.Y), float64(vp.X))
+		t = t.Scale(c, f32.Pt(s, s)).Rotate(c, float32(angle))
+	}
+	return t.Offset(q0.Add(q1).Mul(.5).Sub(c))
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Winner returns the gesture holding the pointer, or nil.
+func (a *Arena) Winner() interface{} {
+	if a == nil {
+		return nil
+	}
+	return a.winner
+}
+
+// claim the pointer for g, and report whether g won.
+func (a *Arena) claim(g interface{}) bool {
+	if a == nil {
+		return true
+	}
+	if a.winner == nil {
+		a.winner = g
+	}
+	return a.winner == g
+}
+
+// lost reports whether another gesture holds the pointer.
+func (a *Arena) lost(g interface{}) bool {
+	return a != nil && a.winner != nil && a.winner != g
+}
+
+// press records a press of g. A winner pressed again starts a new
+// gesture, so it no longer holds the pointer.
+func (a *Arena) press(g interface{}) {
+	a.release(g)
+}
+
+// release the pointer held by g.
+func (a *Arena) release(g interface{}) {
+	if a != nil && a.winner == g {
+		a.winner = nil
+	}
+}
+
 func (a Axis) String() string {
 	switch a {
 	case Horizontal:
@@ -453,6 +1173,67 @@ func (ct ClickType) String() string {
 	}
 }
 
+func (e Edge) String() string {
+	switch e {
+	case EdgeLeft:
+		return "EdgeLeft"
+	case EdgeRight:
+		return "EdgeRight"
+	case EdgeTop:
+		return "EdgeTop"
+	case EdgeBottom:
+		return "EdgeBottom"
+	default:
+		panic("invalid Edge")
+	}
+}
+
+func (h Handle) String() string {
+	switch h {
+	case HandleStart:
+		return "HandleStart"
+	case HandleEnd:
+		return "HandleEnd"
+	default:
+		panic("invalid Handle")
+	}
+}
+
+func (r RotateType) String() string {
+	switch r {
+	case RotateMove:
+		return "RotateMove"
+	case RotateEnd:
+		return "RotateEnd"
+	default:
+		panic("invalid RotateType")
+	}
+}
+
+func (t TransformType) String() string {
+	switch t {
+	case TransformMove:
+		return "TransformMove"
+	case TransformEnd:
+		return "TransformEnd"
+	default:
+		panic("invalid TransformType")
+	}
+}
+
+func (s SwipeType) String() string {
+	switch s {
+	case SwipeMove:
+		return "SwipeMove"
+	case SwipeRelease:
+		return "SwipeRelease"
+	case SwipeCancel:
+		return "SwipeCancel"
+	default:
+		panic("invalid SwipeType")
+	}
+}
+
 func (s ScrollState) String() string {
 	switch s {
 	case StateIdle:
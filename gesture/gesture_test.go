@@ -4,6 +4,9 @@ package gesture
 
 import (
 	"image"
+	"math"
+	"reflect"
+	"runtime"
 	"testing"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	"gioui.org/io/router"
 	"gioui.org/op"
 	"gioui.org/op/clip"
+	"gioui.org/unit"
 )
 
 func TestHover(t *testing.T) {
@@ -90,6 +94,40 @@ func TestMouseClicks(t *testing.T) {
 	}
 }
 
+func TestMultipleTaps(t *testing.T) {
+	var (
+		click Click
+		ops   op.Ops
+		r     router.Router
+	)
+	click.Config.DoubleClickDuration = 300 * time.Millisecond
+	clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	click.Add(&ops)
+	r.Frame(&ops)
+
+	tap := func(t time.Duration) []event.Event {
+		press := pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: f32.Pt(50, 50), Time: t}
+		release := press
+		release.Type = pointer.Release
+		release.Time = t + 50*time.Millisecond
+		return []event.Event{press, release}
+	}
+	// Four rapid taps followed by a tap after a pause longer than the
+	// configured duration.
+	for _, start := range []time.Duration{0, 250, 500, 750, 1400} {
+		r.Queue(tap(start * time.Millisecond)...)
+	}
+	var got []int
+	for _, e := range click.Events(&r) {
+		if e.Type == TypeClick {
+			got = append(got, e.NumClicks)
+		}
+	}
+	if want := []int{1, 2, 3, 4, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got click counts %v, expected %v", got, want)
+	}
+}
+
 func mouseClickEvents(times ...time.Duration) []event.Event {
 	press := pointer.Event{
 		Type:    pointer.Press,
@@ -115,3 +153,616 @@ func filterMouseClicks(events []ClickEvent) []ClickEvent {
 	}
 	return clicks
 }
+
+func TestArenaScrollWins(t *testing.T) {
+	var arena Arena
+	scroll := Scroll{Arena: &arena}
+	drag := Drag{Arena: &arena}
+	var ops op.Ops
+	var r router.Router
+	frame := func() {
+		ops.Reset()
+		stack := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+		scroll.Add(&ops, image.Rect(0, -1000, 0, 1000))
+		drag.Add(&ops)
+		stack.Pop()
+		r.Frame(&ops)
+	}
+	cfg := unit.Metric{PxPerDp: 1, PxPerSp: 1}
+	// Set the scroll axis.
+	scroll.Scroll(cfg, &r, time.Time{}, Vertical)
+	frame()
+	// Move vertically past the touch slop before moving horizontally.
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: f32.Pt(50, 50), Time: 1},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(51, 60), Time: 2},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(80, 62), Time: 3},
+	)
+	scroll.Scroll(cfg, &r, time.Time{}, Vertical)
+	var types []pointer.Type
+	for _, e := range drag.Events(cfg, &r, Horizontal) {
+		types = append(types, e.Type)
+	}
+	if got := arena.Winner(); got != &scroll {
+		t.Errorf("got winner %v; expected the scroll", got)
+	}
+	if got, exp := types, []pointer.Type{pointer.Press, pointer.Cancel}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("got drag events %v; expected %v", got, exp)
+	}
+	if drag.Dragging() {
+		t.Error("drag still in progress")
+	}
+	if scroll.State() != StateDragging {
+		t.Errorf("got scroll state %v; expected %v", scroll.State(), StateDragging)
+	}
+
+	// The winner keeps the pointer until release.
+	frame()
+	r.Queue(
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(80, 80), Time: 4},
+	)
+	if dist := scroll.Scroll(cfg, &r, time.Time{}, Vertical); dist == 0 {
+		t.Error("scroll didn't scroll after winning")
+	}
+	if evts := drag.Events(cfg, &r, Horizontal); len(evts) != 0 {
+		t.Errorf("canceled drag got events: %v", evts)
+	}
+	r.Queue(
+		pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: f32.Pt(80, 80), Time: 5},
+	)
+	scroll.Scroll(cfg, &r, time.Time{}, Vertical)
+	drag.Events(cfg, &r, Horizontal)
+	if got := arena.Winner(); got != nil {
+		t.Errorf("got winner %v after release; expected none", got)
+	}
+}
+
+func TestArenaUntimed(t *testing.T) {
+	var arena Arena
+	scroll := Scroll{Arena: &arena}
+	drag := Drag{Arena: &arena}
+	var ops op.Ops
+	var r router.Router
+	cfg := unit.Metric{PxPerDp: 1, PxPerSp: 1}
+	// gesture delivers events without timestamps, and returns the
+	// scroll distance and the drag event types.
+	gesture := func(events ...pointer.Event) (int, []pointer.Type) {
+		ops.Reset()
+		stack := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+		scroll.Add(&ops, image.Rect(0, -1000, 0, 1000))
+		drag.Add(&ops)
+		stack.Pop()
+		r.Frame(&ops)
+		dist := 0
+		var types []pointer.Type
+		for _, e := range events {
+			r.Queue(e)
+			dist += scroll.Scroll(cfg, &r, time.Time{}, Vertical)
+			for _, e := range drag.Events(cfg, &r, Horizontal) {
+				types = append(types, e.Type)
+			}
+		}
+		return dist, types
+	}
+	gesture()
+	// A vertical gesture goes to the scroll.
+	gesture(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: f32.Pt(50, 50)},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(50, 70)},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(50, 90)},
+	)
+	if got := arena.Winner(); got != &scroll {
+		t.Errorf("got winner %v; expected the scroll", got)
+	}
+	gesture(pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: f32.Pt(50, 90)})
+	if got := arena.Winner(); got != nil {
+		t.Errorf("got winner %v after release; expected none", got)
+	}
+	// A separate horizontal gesture goes to the drag.
+	dist, types := gesture(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: f32.Pt(50, 50)},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(70, 50)},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(90, 52)},
+		pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: f32.Pt(90, 52)},
+	)
+	if exp := []pointer.Type{pointer.Press, pointer.Drag, pointer.Drag, pointer.Release}; !reflect.DeepEqual(types, exp) {
+		t.Errorf("got drag events %v; expected %v", types, exp)
+	}
+	if dist != 0 {
+		t.Errorf("scroll scrolled %d during the drag", dist)
+	}
+}
+
+func TestOccludedGestures(t *testing.T) {
+	// A list scrolls vertically, its item drags horizontally and
+	// clicks, and a floating action button is drawn over the item.
+	var arena Arena
+	list := Scroll{Arena: &arena}
+	drag := Drag{Arena: &arena}
+	var item, fab Click
+	var ops op.Ops
+	var r router.Router
+	cfg := unit.Metric{PxPerDp: 1, PxPerSp: 1}
+	// mouse is set for mouse events, and touch events otherwise.
+	mouse := false
+	frame := func() {
+		ops.Reset()
+		l := clip.Rect(image.Rect(0, 0, 100, 200)).Push(&ops)
+		list.Add(&ops, image.Rect(0, -1000, 0, 1000))
+		it := clip.Rect(image.Rect(0, 150, 100, 200)).Push(&ops)
+		drag.Add(&ops)
+		item.Add(&ops)
+		it.Pop()
+		l.Pop()
+		f := clip.Rect(image.Rect(60, 160, 90, 190)).Push(&ops)
+		fab.Add(&ops)
+		f.Pop()
+		r.Frame(&ops)
+	}
+	// gesture delivers the events in a frame each, and returns the
+	// scroll distance, the drag event types, and the click event types
+	// of the item and the button.
+	gesture := func(events ...pointer.Event) (int, []pointer.Type, []ClickType, []ClickType) {
+		dist := 0
+		var drags []pointer.Type
+		var items, fabs []ClickType
+		for _, e := range events {
+			if !mouse {
+				e.Source = pointer.Touch
+			}
+			r.Queue(e)
+			dist += list.Scroll(cfg, &r, time.Time{}, Vertical)
+			for _, e := range drag.Events(cfg, &r, Horizontal) {
+				drags = append(drags, e.Type)
+			}
+			for _, e := range item.Events(&r) {
+				items = append(items, e.Type)
+			}
+			for _, e := range fab.Events(&r) {
+				fabs = append(fabs, e.Type)
+			}
+			frame()
+		}
+		return dist, drags, items, fabs
+	}
+	// Set the scroll axis.
+	list.Scroll(cfg, &r, time.Time{}, Vertical)
+	frame()
+
+	// A press on the button goes to the button only, even when the
+	// pointer moves over the list past the touch slop.
+	dist, drags, items, fabs := gesture(
+		pointer.Event{Type: pointer.Press, Position: f32.Pt(70, 170)},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(70, 140)},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(40, 110)},
+		pointer.Event{Type: pointer.Release, Position: f32.Pt(40, 110)},
+	)
+	if dist != 0 || list.State() != StateIdle {
+		t.Errorf("list scrolled %d, state %v, under the button", dist, list.State())
+	}
+	if len(drags) != 0 || len(items) != 0 {
+		t.Errorf("item got drag events %v, click events %v under the button", drags, items)
+	}
+	if exp := []ClickType{TypePress, TypeCancel}; !reflect.DeepEqual(fabs, exp) {
+		t.Errorf("got button events %v; expected %v", fabs, exp)
+	}
+	if got := arena.Winner(); got != nil {
+		t.Errorf("got winner %v; expected none", got)
+	}
+	_, _, _, fabs = gesture(
+		pointer.Event{Type: pointer.Press, Position: f32.Pt(70, 170)},
+		pointer.Event{Type: pointer.Release, Position: f32.Pt(70, 170)},
+	)
+	if exp := []ClickType{TypePress, TypeClick}; !reflect.DeepEqual(fabs, exp) {
+		t.Errorf("got button events %v; expected %v", fabs, exp)
+	}
+
+	// Outside the button, the list and its item compete as usual.
+	dist, drags, items, fabs = gesture(
+		pointer.Event{Type: pointer.Press, Position: f32.Pt(20, 170)},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 140)},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 110)},
+		pointer.Event{Type: pointer.Release, Position: f32.Pt(20, 110)},
+	)
+	if dist == 0 {
+		t.Error("list didn't scroll beside the button")
+	}
+	if exp := []pointer.Type{pointer.Press, pointer.Cancel}; !reflect.DeepEqual(drags, exp) {
+		t.Errorf("got drag events %v; expected %v", drags, exp)
+	}
+	if exp := []ClickType{TypePress, TypeCancel}; !reflect.DeepEqual(items, exp) {
+		t.Errorf("got item events %v; expected %v", items, exp)
+	}
+	if len(fabs) != 0 {
+		t.Errorf("button got events %v beside it", fabs)
+	}
+	list.Stop()
+	// A horizontal drag of the item doesn't fling the list on release.
+	dist, drags, _, _ = gesture(
+		pointer.Event{Type: pointer.Press, Position: f32.Pt(20, 170), Time: 10 * time.Millisecond},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(40, 171), Time: 20 * time.Millisecond},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(60, 171), Time: 30 * time.Millisecond},
+		pointer.Event{Type: pointer.Release, Position: f32.Pt(60, 171), Time: 40 * time.Millisecond},
+	)
+	if exp := []pointer.Type{pointer.Press, pointer.Drag, pointer.Drag, pointer.Release}; !reflect.DeepEqual(drags, exp) {
+		t.Errorf("got drag events %v; expected %v", drags, exp)
+	}
+	if dist != 0 || list.State() != StateIdle {
+		t.Errorf("list scrolled %d, state %v, after the item drag", dist, list.State())
+	}
+
+	// A touch fling of the list, then a mouse click that the list
+	// ignores, doesn't fling the list again on release.
+	gesture(
+		pointer.Event{Type: pointer.Press, Position: f32.Pt(20, 170), Time: 50 * time.Millisecond},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 150), Time: 60 * time.Millisecond},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 130), Time: 70 * time.Millisecond},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 110), Time: 80 * time.Millisecond},
+		pointer.Event{Type: pointer.Release, Position: f32.Pt(20, 110), Time: 80 * time.Millisecond},
+	)
+	list.Stop()
+	mouse = true
+	_, _, items, _ = gesture(
+		pointer.Event{Type: pointer.Press, Position: f32.Pt(20, 170), Buttons: pointer.ButtonPrimary, Time: 100 * time.Millisecond},
+		pointer.Event{Type: pointer.Release, Position: f32.Pt(20, 170), Time: 110 * time.Millisecond},
+	)
+	if exp := []ClickType{TypePress, TypeClick}; !reflect.DeepEqual(items, exp) {
+		t.Errorf("got item events %v; expected %v", items, exp)
+	}
+	if runtime.GOOS != "android" && list.State() != StateIdle {
+		t.Errorf("got list state %v after a mouse click; expected %v", list.State(), StateIdle)
+	}
+}
+
+func TestScrollFrameRate(t *testing.T) {
+	cfg := unit.Metric{PxPerDp: 1, PxPerSp: 1}
+	epoch := time.Now()
+	// fling moves a pointer 12px every 8ms, delivering the events in
+	// frames of perFrame events, and returns the fling distance after
+	// the release. Events lack timestamps if stamped is false.
+	fling := func(perFrame int, stamped bool) int {
+		var s Scroll
+		var ops op.Ops
+		var r router.Router
+		frame := func() {
+			ops.Reset()
+			stack := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+			s.Add(&ops, image.Rect(0, -1000, 0, 1000))
+			stack.Pop()
+			r.Frame(&ops)
+		}
+		s.Scroll(cfg, &r, epoch, Vertical)
+		frame()
+		const n = 12
+		var now time.Time
+		for i := 0; i <= n; i++ {
+			et := time.Duration(i) * 8 * time.Millisecond
+			typ := pointer.Move
+			if i == 0 {
+				typ = pointer.Press
+			}
+			e := pointer.Event{Type: typ, Source: pointer.Touch, Position: f32.Pt(50, 90-float32(i)*12)}
+			if stamped {
+				e.Time = et
+			}
+			r.Queue(e)
+			if i == n {
+				e.Type = pointer.Release
+				r.Queue(e)
+			}
+			if i%perFrame == perFrame-1 || i == n {
+				now = epoch.Add(et)
+				s.Scroll(cfg, &r, now, Vertical)
+				frame()
+			}
+		}
+		if s.State() != StateFlinging {
+			t.Fatalf("got state %v; expected %v", s.State(), StateFlinging)
+		}
+		dist := 0
+		for i := 1; i <= 10; i++ {
+			dist += s.Scroll(cfg, &r, now.Add(time.Duration(i)*16*time.Millisecond), Vertical)
+		}
+		return dist
+	}
+
+	d60, d30 := fling(2, true), fling(4, true)
+	if d60 != d30 {
+		t.Errorf("fling distance %d at 60 fps differs from %d at 30 fps", d60, d30)
+	}
+	// Without timestamps, the frame times measure the velocity.
+	if d := fling(1, false); d == 0 {
+		t.Error("no fling without event timestamps")
+	}
+}
+
+func TestEdgeSwipe(t *testing.T) {
+	swipe := EdgeSwipe{Edge: EdgeLeft, Distance: unit.Dp(100)}
+	cfg := unit.Metric{PxPerDp: 1, PxPerSp: 1}
+	var ops op.Ops
+	var r router.Router
+	frame := func() {
+		ops.Reset()
+		swipe.Add(&ops, cfg, image.Rect(0, 0, 300, 200))
+		r.Frame(&ops)
+	}
+	// drag to each x at interval ms, and release.
+	drag := func(interval time.Duration, xs ...float32) []SwipeEvent {
+		frame()
+		now := time.Duration(0)
+		r.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: f32.Pt(xs[0], 50), Time: now})
+		for _, x := range xs[1:] {
+			now += interval * time.Millisecond
+			r.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(x, 50), Time: now})
+		}
+		r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: f32.Pt(xs[len(xs)-1], 50), Time: now})
+		return swipe.Events(cfg, &r)
+	}
+
+	// A slow swipe past half the distance opens.
+	evts := drag(50, 5, 25, 45, 75)
+	var progress []float32
+	for _, e := range evts[:len(evts)-1] {
+		if e.Type != SwipeMove {
+			t.Fatalf("got %v before release; expected SwipeMove", e.Type)
+		}
+		progress = append(progress, e.Progress)
+	}
+	if exp := []float32{.2, .4, .7}; !reflect.DeepEqual(progress, exp) {
+		t.Errorf("got progress %v; expected %v", progress, exp)
+	}
+	if e := evts[len(evts)-1]; e.Type != SwipeRelease || !e.Open {
+		t.Errorf("got %+v; expected an opening release", e)
+	}
+	if swipe.Swiping() {
+		t.Error("swipe in progress after release")
+	}
+
+	// A slow short swipe closes.
+	evts = drag(50, 5, 20, 35)
+	if e := evts[len(evts)-1]; e.Type != SwipeRelease || e.Open {
+		t.Errorf("got %+v; expected a closing release", e)
+	}
+
+	// A fast short swipe opens.
+	evts = drag(10, 5, 15, 25, 35)
+	if e := evts[len(evts)-1]; e.Type != SwipeRelease || !e.Open || e.Velocity <= 0 {
+		t.Errorf("got %+v; expected an opening fling", e)
+	}
+
+	// Presses away from the edge are ignored.
+	if evts := drag(50, 50, 150); len(evts) != 0 {
+		t.Errorf("swipe away from the edge reported %v", evts)
+	}
+}
+
+func TestSelectionHandles(t *testing.T) {
+	var handles SelectionHandles
+	cfg := unit.Metric{PxPerDp: 1, PxPerSp: 1}
+	var ops op.Ops
+	var r router.Router
+	// Handles hang below anchors on the text baseline at y=20.
+	handles.Add(&ops, HandleStart, f32.Pt(10, 20), image.Rect(0, 20, 20, 40))
+	handles.Add(&ops, HandleEnd, f32.Pt(60, 20), image.Rect(50, 20, 70, 40))
+	r.Frame(&ops)
+
+	// offset maps positions to offsets of 10 pixel wide characters,
+	// and records the positions.
+	var positions []f32.Point
+	offset := func(pos f32.Point) int {
+		positions = append(positions, pos)
+		return int(pos.X) / 10
+	}
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: f32.Pt(62, 35)},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(72, 36)},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(92, 34)},
+	)
+	evts := handles.Events(cfg, &r, offset)
+	exp := []SelectionEvent{
+		{Handle: HandleEnd, Offset: 7},
+		{Handle: HandleEnd, Offset: 9},
+	}
+	if !reflect.DeepEqual(evts, exp) {
+		t.Errorf("got events %+v; expected %+v", evts, exp)
+	}
+	// The text positions keep the distance from the anchor.
+	if exp := []f32.Point{f32.Pt(70, 21), f32.Pt(90, 19)}; !reflect.DeepEqual(positions, exp) {
+		t.Errorf("got positions %v; expected %v", positions, exp)
+	}
+	if !handles.Dragging() {
+		t.Error("handles not dragging during drag")
+	}
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: f32.Pt(92, 34)})
+	if evts := handles.Events(cfg, &r, offset); len(evts) != 0 {
+		t.Errorf("release reported %+v", evts)
+	}
+	if handles.Dragging() {
+		t.Error("handles dragging after release")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	var (
+		rot Rotate
+		ops op.Ops
+		r   router.Router
+	)
+	clip.Rect(image.Rect(0, 0, 200, 200)).Push(&ops)
+	rot.Add(&ops)
+	r.Frame(&ops)
+
+	// The second finger orbits the first at a distance of 50.
+	orbit := func(angle float64) f32.Point {
+		return f32.Pt(100+50*float32(math.Cos(angle)), 100+50*float32(math.Sin(angle)))
+	}
+	move := func(angle float64) RotateEvent {
+		t.Helper()
+		r.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Touch, PointerID: 1, Position: orbit(angle)})
+		evts := rot.Events(&r)
+		if len(evts) != 1 || evts[0].Type != RotateMove {
+			t.Fatalf("got events %+v at angle %v; expected one RotateMove", evts, angle)
+		}
+		return evts[0]
+	}
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 0, Position: f32.Pt(100, 100)},
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 1, Position: orbit(0)},
+	)
+	if evts := rot.Events(&r); len(evts) != 0 {
+		t.Errorf("presses reported %+v", evts)
+	}
+	// Below the threshold.
+	if e := move(.05); e.Angle != 0 || rot.Rotating() {
+		t.Errorf("rotated by %v below the threshold", e.Angle)
+	}
+	// Crossing the threshold rebases the rotation.
+	if e := move(.15); e.Angle != 0 || !rot.Rotating() {
+		t.Errorf("rotated by %v when crossing the threshold, rotating: %v", e.Angle, rot.Rotating())
+	}
+	// Past the threshold, the rotation tracks the fingers.
+	e := move(.25)
+	if math.Abs(float64(e.Angle)-.1) > 1e-4 {
+		t.Errorf("rotated by %v; expected 0.1", e.Angle)
+	}
+	if math.Abs(float64(e.Scale)-1) > 1e-4 {
+		t.Errorf("scaled by %v; expected 1", e.Scale)
+	}
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Touch, PointerID: 1, Position: orbit(.25)})
+	if evts := rot.Events(&r); len(evts) != 1 || evts[0].Type != RotateEnd {
+		t.Errorf("got events %+v at release; expected RotateEnd", evts)
+	}
+	if rot.Rotating() {
+		t.Error("rotating after release")
+	}
+}
+
+func TestRotateTwoPointers(t *testing.T) {
+	var (
+		rot = Rotate{Threshold: .08}
+		ops op.Ops
+		r   router.Router
+	)
+	clip.Rect(image.Rect(0, 0, 200, 200)).Push(&ops)
+	rot.Add(&ops)
+	r.Frame(&ops)
+
+	// Both fingers turn around the center, crossing the negative x
+	// axis where the angle wraps around.
+	finger := func(angle float64, id pointer.ID) f32.Point {
+		if id == 1 {
+			angle += math.Pi
+		}
+		return f32.Pt(100+40*float32(math.Cos(angle)), 100+40*float32(math.Sin(angle)))
+	}
+	const start = math.Pi - .35
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 0, Position: finger(start, 0)},
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 1, Position: finger(start, 1)},
+	)
+	rot.Events(&r)
+	var total float32
+	for i := 1; i <= 7; i++ {
+		angle := start + float64(i)*.1
+		r.Queue(
+			pointer.Event{Type: pointer.Move, Source: pointer.Touch, PointerID: 0, Position: finger(angle, 0)},
+			pointer.Event{Type: pointer.Move, Source: pointer.Touch, PointerID: 1, Position: finger(angle, 1)},
+		)
+		evts := rot.Events(&r)
+		if len(evts) != 2 {
+			t.Fatalf("got events %+v; expected one RotateMove per finger", evts)
+		}
+		for _, e := range evts {
+			if e.Type != RotateMove {
+				t.Fatalf("got %+v; expected RotateMove", e)
+			}
+			// Moving one finger turns the fingers by half a step.
+			if e.Angle < 0 || e.Angle > .05+1e-4 {
+				t.Errorf("got angle delta %v; expected at most 0.05", e.Angle)
+			}
+			total += e.Angle
+		}
+		if d := evts[1].Center.Sub(f32.Pt(100, 100)); math.Abs(float64(d.X)) > 1e-3 || math.Abs(float64(d.Y)) > 1e-3 {
+			t.Errorf("got center %v; expected (100,100)", evts[1].Center)
+		}
+	}
+	// The rotation starts past the threshold, after the first step.
+	if math.Abs(float64(total)-.6) > 1e-3 {
+		t.Errorf("rotated by %v; expected 0.6", total)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	var (
+		tr  Transform
+		ops op.Ops
+		r   router.Router
+	)
+	clip.Rect(image.Rect(0, 0, 300, 300)).Push(&ops)
+	tr.Add(&ops)
+	r.Frame(&ops)
+
+	var total f32.Affine2D
+	apply := func(events ...event.Event) []TransformEvent {
+		r.Queue(events...)
+		evts := tr.Events(&r)
+		for _, e := range evts {
+			total = e.Delta.Mul(total)
+		}
+		return evts
+	}
+	near := func(p, q f32.Point) bool {
+		d := p.Sub(q)
+		return math.Abs(float64(d.X)) < 1e-3 && math.Abs(float64(d.Y)) < 1e-3
+	}
+	p0, p1 := f32.Pt(100, 100), f32.Pt(200, 100)
+	apply(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 0, Position: p0},
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 1, Position: p1},
+	)
+	// Pinch the fingers to half their distance, turn them by 90 degrees
+	// and drag them.
+	q0, q1 := f32.Pt(150, 125), f32.Pt(150, 175)
+	evts := apply(
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, PointerID: 0, Position: f32.Pt(120, 80)},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, PointerID: 1, Position: q1},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, PointerID: 0, Position: q0},
+	)
+	if len(evts) != 3 {
+		t.Fatalf("got events %+v; expected 3 TransformMoves", evts)
+	}
+	for _, e := range evts {
+		if e.Type != TransformMove || e.Pointers != 2 {
+			t.Errorf("got %+v; expected a TransformMove of 2 pointers", e)
+		}
+	}
+	exp := f32.Affine2D{}.
+		Scale(f32.Pt(150, 100), f32.Pt(.5, .5)).
+		Rotate(f32.Pt(150, 100), math.Pi/2).
+		Offset(f32.Pt(0, 50))
+	for _, p := range []f32.Point{p0, p1, {}, f32.Pt(150, 100), f32.Pt(300, 200)} {
+		if got, want := total.Transform(p), exp.Transform(p); !near(got, want) {
+			t.Errorf("transform maps %v to %v; expected %v", p, got, want)
+		}
+	}
+	if !near(total.Transform(p0), q0) || !near(total.Transform(p1), q1) {
+		t.Errorf("transform doesn't follow the fingers: %v", total)
+	}
+	// With one finger left, the transform pans.
+	apply(pointer.Event{Type: pointer.Release, Source: pointer.Touch, PointerID: 0, Position: q0})
+	if !tr.Active() {
+		t.Error("inactive with one finger pressed")
+	}
+	before := total
+	evts = apply(pointer.Event{Type: pointer.Move, Source: pointer.Touch, PointerID: 1, Position: q1.Add(f32.Pt(10, -20))})
+	if len(evts) != 1 || evts[0].Pointers != 1 || evts[0].Delta != (f32.Affine2D{}).Offset(f32.Pt(10, -20)) {
+		t.Errorf("got events %+v; expected a pan by (10,-20)", evts)
+	}
+	if got, want := total.Transform(p1), before.Transform(p1).Add(f32.Pt(10, -20)); !near(got, want) {
+		t.Errorf("pan maps %v to %v; expected %v", p1, got, want)
+	}
+	evts = apply(pointer.Event{Type: pointer.Release, Source: pointer.Touch, PointerID: 1, Position: q1})
+	if len(evts) != 1 || evts[0].Type != TransformEnd || tr.Active() {
+		t.Errorf("got events %+v at the last release; expected TransformEnd", evts)
+	}
+}
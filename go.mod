@@ -1,6 +1,6 @@
 module gioui.org
 
-go 1.17
+go 1.18
 
 require (
 	golang.org/x/exp v0.0.0-20210722180016-6781d3edade3
@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package gpu
+
+import (
+	"math"
+	"sort"
+
+	"gioui.org/f32"
+	"gioui.org/internal/ops"
+	"gioui.org/internal/scene"
+	"gioui.org/internal/stroke"
+)
+
+// aliaser rasterizes aliased clip areas into rectangles aligned to the
+// pixel grid. The coverage of pixel-aligned rectangles is exactly 0 or
+// 1 in both renderers, so the rectangles draw the area without
+// antialiasing.
+type aliaser struct {
+	edges []aliasEdge
+	// active are the edges crossing the current row.
+	active []aliasEdge
+	// crossings of the active edges with the center of the current row.
+	crossings []aliasCrossing
+	// spans and prev are the covered pixel columns of the current and
+	// previous rows, as pairs of start and end.
+	spans, prev []int
+	bounds      f32.Rectangle
+	contour     uint32
+}
+
+// aliasEdge is a line segment of the outline, directed downwards.
+type aliasEdge struct {
+	from, to f32.Point
+	// winding is 1 for edges directed downwards in the outline, -1
+	// otherwise.
+	winding int
+}
+
+type aliasCrossing struct {
+	x       float32
+	winding int
+}
+
+// aliasTolerance is the maximum distance in pixels between curves and
+// the lines approximating them.
+const aliasTolerance = .1
+
+func (a *aliaser) reset() {
+	a.edges = a.edges[:0]
+	a.contour = 0
+}
+
+// addPath adds the outline of a path, or of the stroke of the path if
+// strWidth is positive, transformed by t to pixel coordinates.
+func (a *aliaser) addPath(pathData []byte, t f32.Affine2D, outline bool, strWidth float32) {
+	switch {
+	case strWidth > 0:
+		ss := stroke.StrokeStyle{
+			Width: strWidth,
+		}
+		for _, quad := range stroke.StrokePathCommands(ss, pathData) {
+			a.addQuad(quad.Quad.Transform(t))
+		}
+	case outline:
+		forEachOutlineQuad(t, pathData, func(_ uint32, q stroke.QuadSegment) {
+			a.addQuad(q)
+		})
+	}
+}
+
+// addRect adds the outline of r transformed by t to pixel coordinates.
+func (a *aliaser) addRect(r f32.Rectangle, t f32.Affine2D) {
+	corners := [4]f32.Point{
+		t.Transform(r.Min), t.Transform(f32.Pt(r.Max.X, r.Min.Y)),
+		t.Transform(r.Max), t.Transform(f32.Pt(r.Min.X, r.Max.Y)),
+	}
+	for i, c := range corners {
+		a.addLine(c, corners[(i+1)%len(corners)])
+	}
+}
+
+func (a *aliaser) addQuad(q stroke.QuadSegment) {
+	// The distance between a quadratic curve and n line segments is
+	// at most |from - 2*ctrl + to|/(8*n²).
+	dd := q.From.Sub(q.Ctrl.Mul(2)).Add(q.To)
+	d := math.Sqrt(float64(dd.X*dd.X + dd.Y*dd.Y))
+	n := int(math.Ceil(math.Sqrt(d / (8 * aliasTolerance))))
+	if n < 1 {
+		n = 1
+	}
+	from := q.From
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		u := 1 - t
+		to := q.From.Mul(u * u).Add(q.Ctrl.Mul(2 * u * t)).Add(q.To.Mul(t * t))
+		a.addLine(from, to)
+		from = to
+	}
+}
+
+func (a *aliaser) addLine(from, to f32.Point) {
+	switch {
+	case from.Y < to.Y:
+		a.edges = append(a.edges, aliasEdge{from: from, to: to, winding: 1})
+	case from.Y > to.Y:
+		a.edges = append(a.edges, aliasEdge{from: to, to: from, winding: -1})
+	}
+	// Horizontal edges don't cross rows.
+}
+
+// appendPixels rasterizes the outlines added since reset with the
+// non-zero winding rule, and appends path data for the rectangles of the
+// pixels whose centers are inside, transformed by t. It returns the
+// path data and the bounds of the transformed rectangles.
+func (a *aliaser) appendPixels(pathData []byte, t f32.Affine2D) ([]byte, f32.Rectangle) {
+	inf := float32(math.Inf(+1))
+	a.bounds = f32.Rectangle{
+		Min: f32.Point{X: inf, Y: inf},
+		Max: f32.Point{X: -inf, Y: -inf},
+	}
+	if len(a.edges) == 0 {
+		return pathData, f32.Rectangle{}
+	}
+	sort.Slice(a.edges, func(i, j int) bool {
+		return a.edges[i].from.Y < a.edges[j].from.Y
+	})
+	minY := a.edges[0].from.Y
+	maxY := minY
+	for _, e := range a.edges {
+		if e.to.Y > maxY {
+			maxY = e.to.Y
+		}
+	}
+	a.active = a.active[:0]
+	a.prev = a.prev[:0]
+	next := 0
+	start := int(math.Floor(float64(minY)))
+	end := int(math.Ceil(float64(maxY)))
+	// A row of pixels is inside the outline at its center, so the
+	// rectangles of the previous rows continue while the spans are the
+	// same.
+	runStart := start
+	for y := start; y < end; y++ {
+		cy := float32(y) + .5
+		for next < len(a.edges) && a.edges[next].from.Y <= cy {
+			a.active = append(a.active, a.edges[next])
+			next++
+		}
+		a.crossings = a.crossings[:0]
+		active := a.active[:0]
+		for _, e := range a.active {
+			if e.to.Y <= cy {
+				continue
+			}
+			active = append(active, e)
+			f := (cy - e.from.Y) / (e.to.Y - e.from.Y)
+			x := e.from.X + f*(e.to.X-e.from.X)
+			a.crossings = append(a.crossings, aliasCrossing{x: x, winding: e.winding})
+		}
+		a.active = active
+		sort.Slice(a.crossings, func(i, j int) bool {
+			return a.crossings[i].x < a.crossings[j].x
+		})
+		a.spans = a.spans[:0]
+		winding := 0
+		var x0 float32
+		for _, c := range a.crossings {
+			inside := winding != 0
+			winding += c.winding
+			switch {
+			case !inside && winding != 0:
+				x0 = c.x
+			case inside && winding == 0:
+				a.addSpan(x0, c.x)
+			}
+		}
+		if !equalSpans(a.spans, a.prev) {
+			pathData = a.appendRects(pathData, t, a.prev, runStart, y)
+			a.prev, a.spans = a.spans, a.prev
+			runStart = y
+		}
+	}
+	pathData = a.appendRects(pathData, t, a.prev, runStart, end)
+	if a.bounds.Min.X > a.bounds.Max.X {
+		return pathData, f32.Rectangle{}
+	}
+	return pathData, a.bounds
+}
+
+// addSpan adds the pixels of the current row whose centers are between
+// x0 and x1.
+func (a *aliaser) addSpan(x0, x1 float32) {
+	// Pixel x is inside if its center, x+.5, is.
+	p0 := int(math.Ceil(float64(x0 - .5)))
+	p1 := int(math.Ceil(float64(x1 - .5)))
+	if p0 >= p1 {
+		return
+	}
+	if n := len(a.spans); n > 0 && a.spans[n-1] >= p0 {
+		// Merge touching spans.
+		if p1 > a.spans[n-1] {
+			a.spans[n-1] = p1
+		}
+		return
+	}
+	a.spans = append(a.spans, p0, p1)
+}
+
+// appendRects appends the rectangles of spans from row y0 to row y1.
+func (a *aliaser) appendRects(pathData []byte, t f32.Affine2D, spans []int, y0, y1 int) []byte {
+	for i := 0; i < len(spans); i += 2 {
+		x0, x1 := float32(spans[i]), float32(spans[i+1])
+		corners := [4]f32.Point{
+			t.Transform(f32.Pt(x0, float32(y0))), t.Transform(f32.Pt(x1, float32(y0))),
+			t.Transform(f32.Pt(x1, float32(y1))), t.Transform(f32.Pt(x0, float32(y1))),
+		}
+		for j, c := range corners {
+			a.bounds.Min = min(a.bounds.Min, c)
+			a.bounds.Max = max(a.bounds.Max, c)
+			n := len(pathData)
+			pathData = append(pathData, make([]byte, 4+scene.CommandSize)...)
+			bo.PutUint32(pathData[n:], a.contour)
+			ops.EncodeCommand(pathData[n+4:], scene.Line(c, corners[(j+1)%len(corners)]))
+		}
+		a.contour++
+	}
+	return pathData
+}
+
+// appendRectPath appends path data for the outline of r.
+func appendRectPath(pathData []byte, r f32.Rectangle) []byte {
+	corners := [4]f32.Point{
+		r.Min, f32.Pt(r.Max.X, r.Min.Y), r.Max, f32.Pt(r.Min.X, r.Max.Y),
+	}
+	for i, c := range corners {
+		n := len(pathData)
+		pathData = append(pathData, make([]byte, 4+scene.CommandSize)...)
+		ops.EncodeCommand(pathData[n+4:], scene.Line(c, corners[(i+1)%len(corners)]))
+	}
+	return pathData
+}
+
+func equalSpans(s1, s2 []int) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+	for i, x := range s1 {
+		if x != s2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// pixelRect returns the rectangle of the pixels whose centers are inside
+// r.
+func pixelRect(r f32.Rectangle) f32.Rectangle {
+	pixel := func(v float32) float32 {
+		return float32(math.Ceil(float64(v - .5)))
+	}
+	r = f32.Rectangle{
+		Min: f32.Pt(pixel(r.Min.X), pixel(r.Min.Y)),
+		Max: f32.Pt(pixel(r.Max.X), pixel(r.Max.Y)),
+	}
+	if r.Empty() {
+		return f32.Rectangle{}
+	}
+	return r
+}
+
+// isScaleOffset reports whether t maps axis-aligned rectangles to
+// axis-aligned rectangles.
+func isScaleOffset(t f32.Affine2D) bool {
+	_, b, _, d, _, _ := t.Elems()
+	return b == 0 && d == 0
+}
+
+// roundPoint rounds p to the pixel grid.
+func roundPoint(p f32.Point) f32.Point {
+	return f32.Pt(float32(math.Round(float64(p.X))), float32(math.Round(float64(p.Y))))
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package gpu
+
+import (
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/internal/ops"
+	"gioui.org/internal/scene"
+)
+
+func TestAliaserPixels(t *testing.T) {
+	var a aliaser
+	a.reset()
+	// A triangle with fractional corners.
+	tri := [3]f32.Point{{X: .2, Y: .3}, {X: 6.8, Y: .3}, {X: .2, Y: 5.6}}
+	for i, p := range tri {
+		a.addLine(p, tri[(i+1)%len(tri)])
+	}
+	data, bounds := a.appendPixels(nil, f32.Affine2D{})
+	// cover counts the rectangles covering each pixel.
+	var cover [8][8]int
+	for len(data) > 0 {
+		r := f32.Rectangle{Min: f32.Pt(1e6, 1e6), Max: f32.Pt(-1e6, -1e6)}
+		for i := 0; i < 4; i++ {
+			from, to := scene.DecodeLine(ops.DecodeCommand(data[4:]))
+			r.Min, r.Max = min(r.Min, min(from, to)), max(r.Max, max(from, to))
+			data = data[4+scene.CommandSize:]
+		}
+		if bounds.Union(r) != bounds {
+			t.Errorf("rectangle %v outside bounds %v", r, bounds)
+		}
+		for y := int(r.Min.Y); y < int(r.Max.Y); y++ {
+			for x := int(r.Min.X); x < int(r.Max.X); x++ {
+				cover[y][x]++
+			}
+		}
+	}
+	for y := range cover {
+		for x, n := range cover[y] {
+			// The hypotenuse is the line through (6.8, .3) and (.2, 5.6).
+			cx, cy := float32(x)+.5, float32(y)+.5
+			in := cx > .2 && cy > .3 && (cx-.2)*5.3+(cy-.3)*6.6 < 6.6*5.3
+			want := 0
+			if in {
+				want = 1
+			}
+			if n != want {
+				t.Errorf("pixel (%d, %d) covered %d times, want %d", x, y, n, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package gpu
+
+import (
+	"image"
+
+	"gioui.org/gpu/internal/driver"
+	"gioui.org/internal/byteslice"
+	"gioui.org/internal/f32color"
+)
+
+// rectBatch is a run of consecutive color fills of the same color,
+// clipped by plain rectangles. A batch is drawn by a single draw call
+// of a triangle strip joined by degenerate triangles.
+type rectBatch struct {
+	// start and end are the indices of the fills of the batch.
+	start, end int
+	// off is the index of the first vertex of the batch in the batch
+	// vertex buffer.
+	off int
+}
+
+// minBatch is the smallest number of fills worth batching.
+const minBatch = 2
+
+// batchVertSize is the size of a batch vertex in float32s: a position
+// and an unused texture coordinate, matching the blit vertex layout.
+const batchVertSize = 4
+
+// isRectFill reports whether img is a color fill without a clip mask.
+func isRectFill(img imageOp) bool {
+	return img.clipType == clipTypeNone && img.material.material == materialColor
+}
+
+// prepareBatches finds the batches of ops and uploads their vertices.
+// It must be called after the clip types of ops are known, and before
+// the render pass.
+func (r *renderer) prepareBatches(ops []imageOp) {
+	r.batches = r.batches[:0]
+	r.batchVerts = r.batchVerts[:0]
+	if r.noBatch {
+		return
+	}
+	for i := 0; i < len(ops); {
+		j := i + 1
+		if isRectFill(ops[i]) {
+			col := ops[i].material.color
+			for j < len(ops) && isRectFill(ops[j]) && ops[j].material.color == col {
+				j++
+			}
+		}
+		if j-i >= minBatch {
+			r.batches = append(r.batches, rectBatch{start: i, end: j, off: len(r.batchVerts) / batchVertSize})
+			for k := i; k < j; k++ {
+				r.batchVerts = r.appendRectVerts(r.batchVerts, ops[k].clip, k > i)
+			}
+		}
+		i = j
+	}
+	if len(r.batchVerts) == 0 {
+		return
+	}
+	data := byteslice.Slice(r.batchVerts)
+	if err := r.batchBuf.ensureCapacity(false, r.ctx, driver.BufferBindingVertices, len(data)); err != nil {
+		panic(err)
+	}
+	r.batchBuf.upload(data)
+}
+
+// appendRectVerts appends the triangle strip vertices of the rectangle
+// to verts. If join is set, the strip is joined to the previous rectangle
+// with degenerate triangles.
+func (r *renderer) appendRectVerts(verts []float32, rect image.Rectangle, join bool) []float32 {
+	scale, off := clipSpaceTransform(rect, r.blitter.viewport)
+	x0, x1 := off.X-scale.X, off.X+scale.X
+	y0, y1 := off.Y-scale.Y, off.Y+scale.Y
+	if join {
+		n := len(verts)
+		verts = append(verts, verts[n-batchVertSize:n]...)
+		verts = append(verts, x0, y0, 0, 0)
+	}
+	return append(verts,
+		x0, y0, 0, 0,
+		x1, y0, 0, 0,
+		x0, y1, 0, 0,
+		x1, y1, 0, 0,
+	)
+}
+
+// drawBatch draws the fills of b in color col.
+func (r *renderer) drawBatch(b rectBatch, col f32color.RGBA) {
+	n := b.end - b.start
+	// Four vertices per rectangle, and two degenerate vertices between
+	// rectangles.
+	nverts := 4*n + 2*(n-1)
+	p := r.blitter.pipelines[materialColor]
+	r.ctx.BindPipeline(p.pipeline)
+	r.ctx.BindVertexBuffer(r.batchBuf.buffer, b.off*batchVertSize*4)
+	r.blitter.colUniforms.color = col
+	// The vertices are already in clip space.
+	r.blitter.colUniforms.transform = [4]float32{1, 1, 0, 0}
+	p.UploadUniforms(r.ctx)
+	r.ctx.DrawArrays(0, nverts)
+	r.used |= programBlitColor << materialColor
+}
@@ -6,11 +6,24 @@ import (
 	"fmt"
 
 	"gioui.org/f32"
+	"gioui.org/io/diag"
 )
 
 type resourceCache struct {
 	res    map[interface{}]resource
 	newRes map[interface{}]resource
+	// size is the total size of the resources, and budget its bound.
+	// When the size exceeds the budget, the resources not yet used in
+	// the current frame are evicted early. A zero budget is unbounded.
+	size   int
+	budget int
+	diag   *diag.Reporter
+}
+
+// sizedResource is implemented by resources that count towards the
+// budget of a resourceCache.
+type sizedResource interface {
+	size() int
 }
 
 // opCache is like a resourceCache but using concrete types and a
@@ -40,6 +53,20 @@ func newResourceCache() *resourceCache {
 	}
 }
 
+// SetTextureBudget bounds the size of the image textures cached by g to
+// budget bytes. Exceeding the budget evicts the textures not used by the
+// current frame before the frame ends. The default budget of zero means
+// no bound.
+func SetTextureBudget(g GPU, budget int) {
+	if b, ok := g.(interface{ setTextureBudget(budget int) }); ok {
+		b.setTextureBudget(budget)
+	}
+}
+
+func (g *gpu) setTextureBudget(budget int) {
+	g.cache.budget = budget
+}
+
 func (r *resourceCache) get(key interface{}) (resource, bool) {
 	v, exists := r.res[key]
 	if exists {
@@ -52,14 +79,63 @@ func (r *resourceCache) put(key interface{}, val resource) {
 	if _, exists := r.newRes[key]; exists {
 		panic(fmt.Errorf("key exists, %p", key))
 	}
+	if old, exists := r.res[key]; exists {
+		r.size -= resourceSize(old)
+	}
 	r.res[key] = val
 	r.newRes[key] = val
+	r.size += resourceSize(val)
+	if r.budget > 0 && r.size > r.budget {
+		r.evict()
+	}
+}
+
+// evict the resources not used in the current frame until the size of
+// the cache is within budget.
+func (r *resourceCache) evict() {
+	evicted := false
+	for k, v := range r.res {
+		if r.size <= r.budget {
+			break
+		}
+		if _, used := r.newRes[k]; used {
+			continue
+		}
+		delete(r.res, k)
+		r.size -= resourceSize(v)
+		v.release()
+		evicted = true
+	}
+	if evicted {
+		r.diag.Report(diag.Diagnostic{
+			Severity: diag.Info,
+			Source:   "gpu",
+			Message:  fmt.Sprintf("texture cache reached its budget of %d bytes, evicting textures", r.budget),
+			Once:     true,
+		})
+	}
+	if r.size > r.budget {
+		r.diag.Report(diag.Diagnostic{
+			Severity: diag.Warning,
+			Source:   "gpu",
+			Message:  fmt.Sprintf("textures of a frame exceed the texture budget of %d bytes", r.budget),
+			Once:     true,
+		})
+	}
+}
+
+func resourceSize(v resource) int {
+	if s, ok := v.(sizedResource); ok {
+		return s.size()
+	}
+	return 0
 }
 
 func (r *resourceCache) frame() {
 	for k, v := range r.res {
 		if _, exists := r.newRes[k]; !exists {
 			delete(r.res, k)
+			r.size -= resourceSize(v)
 			v.release()
 		}
 	}
@@ -76,6 +152,7 @@ func (r *resourceCache) release() {
 	}
 	r.newRes = nil
 	r.res = nil
+	r.size = 0
 }
 
 func newOpCache() *opCache {
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package gpu
+
+import (
+	"reflect"
+	"testing"
+
+	"gioui.org/io/diag"
+)
+
+// sizedRes is a resource of a fixed size.
+type sizedRes struct {
+	n        int
+	released bool
+}
+
+func (r *sizedRes) size() int { return r.n }
+func (r *sizedRes) release() { r.released = true }
+
+func TestResourceCacheUnbounded(t *testing.T) {
+	c := newResourceCache()
+	a, b := &sizedRes{n: 1 << 30}, &sizedRes{n: 1 << 30}
+	c.put("a", a)
+	c.frame()
+	c.put("b", b)
+	if a.released || b.released {
+		t.Error("the default cache evicted a resource")
+	}
+}
+
+func TestResourceCacheBudget(t *testing.T) {
+	var got []diag.Diagnostic
+	var rep diag.Reporter
+	rep.SetSink(func(d diag.Diagnostic) {
+		got = append(got, d)
+	})
+	c := newResourceCache()
+	c.budget = 100
+	c.diag = &rep
+	a, b := &sizedRes{n: 60}, &sizedRes{n: 30}
+	c.put("a", a)
+	c.put("b", b)
+	c.frame()
+	// Exceeding the budget evicts a before it is used by the frame.
+	d := &sizedRes{n: 50}
+	c.get("b")
+	c.put("d", d)
+	if !a.released || b.released || d.released {
+		t.Errorf("released a, b, d: %v, %v, %v; expected true, false, false", a.released, b.released, d.released)
+	}
+	if c.size != 80 {
+		t.Errorf("cache size is %d, expected 80", c.size)
+	}
+	evict := diag.Diagnostic{
+		Severity: diag.Info,
+		Source:   "gpu",
+		Message:  "texture cache reached its budget of 100 bytes, evicting textures",
+		Once:     true,
+	}
+	if want := []diag.Diagnostic{evict}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got diagnostics %v, expected %v", got, want)
+	}
+	c.frame()
+	// Repeated evictions are reported once.
+	e := &sizedRes{n: 60}
+	c.put("e", e)
+	if !b.released && !d.released {
+		t.Error("no resource was evicted")
+	}
+	if want := []diag.Diagnostic{evict}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got diagnostics %v after second eviction, expected %v", got, want)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"gioui.org/internal/f32color"
 	"gioui.org/internal/ops"
 	"gioui.org/internal/scene"
+	"gioui.org/io/diag"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/shader"
@@ -35,7 +36,8 @@ import (
 )
 
 type compute struct {
-	ctx driver.Device
+	ctx  driver.Device
+	diag *diag.Reporter
 
 	collector     collector
 	enc           encoder
@@ -186,6 +188,9 @@ type collector struct {
 	transStack []transEntry
 	prevFrame  opsCollector
 	frame      opsCollector
+	// alias rasterizes aliased clip areas into aliasPaths.
+	alias      aliaser
+	aliasPaths []byte
 }
 
 type transEntry struct {
@@ -580,7 +585,7 @@ func newShaders(ctx driver.Device, vsrc, fsrc shader.Sources) (vert driver.Verte
 func (g *compute) Frame(frameOps *op.Ops, target RenderTarget, viewport image.Point) error {
 	g.frameCount++
 	g.collect(viewport, frameOps)
-	return g.frame(target)
+	return reportFrame(g.diag, frameErr(g.ctx, g.frame(target)))
 }
 
 func (g *compute) collect(viewport image.Point, ops *op.Ops) {
@@ -1676,6 +1681,7 @@ func (c *collector) reset() {
 	c.profile = false
 	c.clipStates = c.clipStates[:0]
 	c.transStack = c.transStack[:0]
+	c.aliasPaths = c.aliasPaths[:0]
 	c.frame.reset()
 }
 
@@ -1725,6 +1731,70 @@ func (c *collector) addClip(state *encoderState, viewport, bounds f32.Rectangle,
 	state.relTrans = f32.Affine2D{}
 }
 
+// aliasedPath rasterizes an aliased clip area, the path or the bounds if
+// path is empty, in pixels. It returns the outline of the pixels and its
+// bounds, in the coordinates of t.
+func (c *collector) aliasedPath(t f32.Affine2D, path []byte, bounds f32.Rectangle, outline bool, strWidth float32) ([]byte, f32.Rectangle) {
+	c.alias.reset()
+	if len(path) == 0 {
+		c.alias.addRect(bounds, t)
+	} else {
+		c.alias.addPath(path, t, outline, strWidth)
+	}
+	start := len(c.aliasPaths)
+	c.aliasPaths, bounds = c.alias.appendPixels(c.aliasPaths, t.Invert())
+	return c.aliasPaths[start:len(c.aliasPaths):len(c.aliasPaths)], bounds
+}
+
+// paint adds the paint operation for filling the clip area of state with
+// its material.
+func (c *collector) paint(state encoderState, fview f32.Rectangle) {
+	paintState := state
+	if paintState.matType == materialTexture {
+		// Clip to the bounds of the image, to hide other images in the atlas.
+		sz := state.image.src.Rect.Size()
+		bounds := f32.Rectangle{Max: layout.FPt(sz)}
+		c.addClip(&paintState, fview, bounds, nil, ops.Key{}, 0, 0, false)
+	}
+	intersect := paintState.clip.intersect
+	if intersect.Empty() {
+		return
+	}
+
+	// If the paint is a uniform opaque color that takes up the whole
+	// screen, it covers all previous paints and we can discard all
+	// rendering commands recorded so far.
+	if paintState.clip == nil && paintState.matType == materialColor && paintState.color.A == 255 {
+		c.clearColor = f32color.LinearFromSRGB(paintState.color).Opaque()
+		c.clear = true
+		c.frame.reset()
+		return
+	}
+
+	// Flatten clip stack.
+	p := paintState.clip
+	startIdx := len(c.frame.clipCmds)
+	for p != nil {
+		idx := len(c.frame.paths)
+		c.frame.paths = append(c.frame.paths, make([]byte, len(p.path))...)
+		path := c.frame.paths[idx:]
+		copy(path, p.path)
+		c.frame.clipCmds = append(c.frame.clipCmds, clipCmd{
+			state:     p.clipKey,
+			path:      path,
+			pathKey:   p.pathKey,
+			absBounds: p.absBounds,
+		})
+		p = p.parent
+	}
+	clipStack := c.frame.clipCmds[startIdx:]
+	c.frame.ops = append(c.frame.ops, paintOp{
+		clipStack: clipStack,
+		state:     paintState.paintKey,
+		intersect: intersect,
+	})
+}
+
 func (c *collector) collect(root *op.Ops, viewport image.Point, texOps *[]textureOp) {
 	fview := f32.Rectangle{Max: layout.FPt(viewport)}
 	var intOps *ops.Ops
@@ -1783,7 +1853,12 @@ func (c *collector) collect(root *op.Ops, viewport image.Point, texOps *[]textur
 			var op ops.ClipOp
 			op.Decode(encOp.Data)
 			bounds := layout.FRect(op.Bounds)
-			c.addClip(&state, fview, bounds, pathData.data, pathData.key, pathData.hash, strWidth, true)
+			path, w := pathData.data, strWidth
+			if op.Aliased {
+				path, bounds = c.aliasedPath(state.t, path, bounds, op.Outline, strWidth)
+				w = 0
+			}
+			c.addClip(&state, fview, bounds, path, pathData.key, pathData.hash, w, true)
 			pathData.data = nil
 			strWidth = 0
 		case ops.TypePopClip:
@@ -1803,49 +1878,16 @@ func (c *collector) collect(root *op.Ops, viewport image.Point, texOps *[]textur
 			state.matType = materialTexture
 			state.image = decodeImageOp(encOp.Data, encOp.Refs)
 		case ops.TypePaint:
-			paintState := state
-			if paintState.matType == materialTexture {
-				// Clip to the bounds of the image, to hide other images in the atlas.
-				sz := state.image.src.Rect.Size()
-				bounds := f32.Rectangle{Max: layout.FPt(sz)}
-				c.addClip(&paintState, fview, bounds, nil, ops.Key{}, 0, 0, false)
-			}
-			intersect := paintState.clip.intersect
-			if intersect.Empty() {
-				break
-			}
-
-			// If the paint is a uniform opaque color that takes up the whole
-			// screen, it covers all previous paints and we can discard all
-			// rendering commands recorded so far.
-			if paintState.clip == nil && paintState.matType == materialColor && paintState.color.A == 255 {
-				c.clearColor = f32color.LinearFromSRGB(paintState.color).Opaque()
-				c.clear = true
-				c.frame.reset()
-				break
-			}
-
-			// Flatten clip stack.
-			p := paintState.clip
-			startIdx := len(c.frame.clipCmds)
-			for p != nil {
-				idx := len(c.frame.paths)
-				c.frame.paths = append(c.frame.paths, make([]byte, len(p.path))...)
-				path := c.frame.paths[idx:]
-				copy(path, p.path)
-				c.frame.clipCmds = append(c.frame.clipCmds, clipCmd{
-					state:     p.clipKey,
-					path:      path,
-					pathKey:   p.pathKey,
-					absBounds: p.absBounds,
-				})
-				p = p.parent
-			}
-			clipStack := c.frame.clipCmds[startIdx:]
-			c.frame.ops = append(c.frame.ops, paintOp{
-				clipStack: clipStack,
-				state:     paintState.paintKey,
-				intersect: intersect,
+			c.paint(state, fview)
+		case ops.TypeTiledImage:
+			op := decodeTiledImageOp(encOp.Data, encOp.Refs)
+			state.matType = materialTexture
+			state.image = op.image
+			op.forEachTile(state.t, state.clip.intersect, func(tile f32.Affine2D) {
+				tileState := state
+				tileState.t = state.t.Mul(tile)
+				tileState.relTrans = state.relTrans.Mul(tile)
+				c.paint(tileState, fview)
 			})
 		case ops.TypeSave:
 			id := ops.DecodeSave(encOp.Data)
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package gpu
+
+import (
+	"errors"
+
+	"gioui.org/gpu/internal/driver"
+	"gioui.org/io/diag"
+)
+
+// SetDiagnostics directs the diagnostics of g, such as a lost device or
+// the eviction of textures over budget, to r.
+func SetDiagnostics(g GPU, r *diag.Reporter) {
+	if d, ok := g.(interface{ setDiagnostics(r *diag.Reporter) }); ok {
+		d.setDiagnostics(r)
+	}
+}
+
+func (g *gpu) setDiagnostics(r *diag.Reporter) {
+	g.diag = r
+	g.cache.diag = r
+}
+
+func (g *compute) setDiagnostics(r *diag.Reporter) {
+	g.diag = r
+}
+
+// frameErr returns err, or ErrDeviceLost if dev detected the loss of
+// its device during the frame.
+func frameErr(dev driver.Device, err error) error {
+	if err != nil {
+		return err
+	}
+	if d, ok := dev.(driver.LossDetector); ok && d.DeviceLost() {
+		return ErrDeviceLost
+	}
+	return nil
+}
+
+// reportFrame reports the loss of the device if err is ErrDeviceLost.
+func reportFrame(r *diag.Reporter, err error) error {
+	if errors.Is(err, ErrDeviceLost) {
+		r.Report(diag.Diagnostic{
+			Severity: diag.Error,
+			Source:   "gpu",
+			Message:  "device lost",
+		})
+	}
+	return err
+}
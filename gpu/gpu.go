@@ -25,6 +25,7 @@ import (
 	"gioui.org/internal/ops"
 	"gioui.org/internal/scene"
 	"gioui.org/internal/stroke"
+	"gioui.org/io/diag"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/shader"
@@ -52,6 +53,7 @@ type GPU interface {
 
 type gpu struct {
 	cache *resourceCache
+	diag  *diag.Reporter
 
 	profile                                string
 	timers                                 *timers
@@ -60,6 +62,8 @@ type gpu struct {
 	drawOps                                drawOps
 	ctx                                    driver.Device
 	renderer                               *renderer
+	// warmed is the set of programs used by WarmUp.
+	warmed program
 }
 
 type renderer struct {
@@ -68,6 +72,14 @@ type renderer struct {
 	pather        *pather
 	packer        packer
 	intersections packer
+	// used is the set of programs used so far.
+	used program
+
+	// noBatch disables the batching of rectangle fills.
+	noBatch    bool
+	batches    []rectBatch
+	batchVerts []float32
+	batchBuf   sizedBuffer
 }
 
 type drawOps struct {
@@ -84,6 +96,11 @@ type drawOps struct {
 	pathOpCache []pathOp
 	qs          quadSplitter
 	pathCache   *opCache
+	// alias rasterizes aliased clip areas into aliasPath. aliasRect
+	// holds the outlines of transformed aliased rectangles.
+	alias     aliaser
+	aliasPath []byte
+	aliasRect []byte
 }
 
 type drawState struct {
@@ -145,6 +162,10 @@ type opKey struct {
 	outline        bool
 	strokeWidth    float32
 	sx, hx, sy, hy float32
+	// aliased is set for aliased clip areas, whose pixels depend on
+	// the fractional offset ox, oy as well.
+	aliased bool
+	ox, oy  float32
 	ops.Key
 }
 
@@ -311,11 +332,19 @@ func New(api API) (GPU, error) {
 func NewWithDevice(d driver.Device) (GPU, error) {
 	d.BeginFrame(nil, false, image.Point{})
 	defer d.EndFrame()
-	forceCompute := os.Getenv("GIORENDERER") == "forcecompute"
+	env := os.Getenv("GIORENDERER")
+	forceCompute := env == "forcecompute"
 	feats := d.Caps().Features
 	switch {
 	case !forceCompute && feats.Has(driver.FeatureFloatRenderTargets) && feats.Has(driver.FeatureSRGB):
-		return newGPU(d)
+		g, err := newGPU(d)
+		if err != nil {
+			return nil, err
+		}
+		// Disabling batching is useful for comparing against the
+		// unbatched renderer.
+		g.renderer.noBatch = env == "nobatch"
+		return g, nil
 	}
 	return newCompute(d)
 }
@@ -354,7 +383,7 @@ func (g *gpu) Release() {
 
 func (g *gpu) Frame(frameOps *op.Ops, target RenderTarget, viewport image.Point) error {
 	g.collect(viewport, frameOps)
-	return g.frame(target)
+	return reportFrame(g.diag, frameErr(g.ctx, g.frame(target)))
 }
 
 func (g *gpu) collect(viewport image.Point, frameOps *op.Ops) {
@@ -413,6 +442,9 @@ func (g *gpu) frame(target RenderTarget) error {
 		frameDur := time.Since(g.frameStart).Round(q)
 		ft = ft.Round(q)
 		g.profile = fmt.Sprintf("draw:%7s gpu:%7s st:%7s cov:%7s", frameDur, ft, st, covt)
+		if lazy := g.renderer.used &^ g.warmed; lazy != 0 {
+			g.profile += " lazy:" + lazy.String()
+		}
 	}
 	return nil
 }
@@ -443,6 +475,11 @@ func (r *renderer) texHandle(cache *resourceCache, data imageOpData) driver.Text
 	return tex.tex
 }
 
+func (t *texture) size() int {
+	b := t.src.Bounds()
+	return 4 * b.Dx() * b.Dy()
+}
+
 func (t *texture) release() {
 	if t.tex != nil {
 		t.tex.Release()
@@ -471,6 +508,7 @@ func newRenderer(ctx driver.Device) *renderer {
 func (r *renderer) release() {
 	r.pather.release()
 	r.blitter.release()
+	r.batchBuf.Release()
 }
 
 func newBlitter(ctx driver.Device) *blitter {
@@ -628,6 +666,7 @@ func (r *renderer) stencilClips(pathCache *opCache, ops []*pathOp) {
 			r.ctx.BeginRenderPass(f.tex, driver.LoadDesc{Action: driver.LoadActionClear})
 			r.ctx.BindPipeline(r.pather.stenciler.pipeline.pipeline.pipeline)
 			r.ctx.BindIndexBuffer(r.pather.stenciler.indexBuf)
+			r.used |= programStencil
 		}
 		v, _ := pathCache.get(p.pathKey)
 		r.pather.stencilPath(p.clip, p.off, p.place.Pos, v.data)
@@ -668,6 +707,7 @@ func (r *renderer) intersect(ops []imageOp) {
 			r.ctx.BeginRenderPass(f.tex, d)
 			r.ctx.BindPipeline(r.pather.stenciler.ipipeline.pipeline.pipeline)
 			r.ctx.BindVertexBuffer(r.blitter.quadVerts, 0)
+			r.used |= programIntersect
 		}
 		r.ctx.Viewport(img.place.Pos.X, img.place.Pos.Y, img.clip.Dx(), img.clip.Dy())
 		r.intersectPath(img.path, img.clip)
@@ -912,25 +952,56 @@ loop:
 			quads.key.outline = op.Outline
 			bounds := layout.FRect(op.Bounds)
 			trans, off := splitTransform(state.t)
+			if op.Aliased {
+				// Rasterize aliased areas in pixels relative to the
+				// integer part of their offset.
+				ioff := roundPoint(off)
+				trans, off = trans.Offset(off.Sub(ioff)), ioff
+				if len(quads.aux) == 0 && !isScaleOffset(trans) {
+					// Rasterize transformed rectangles as paths.
+					d.aliasRect = appendRectPath(d.aliasRect[:0], bounds)
+					quads.aux = d.aliasRect
+					quads.key = opKey{outline: true, Key: encOp.Key}
+				}
+			}
 			if len(quads.aux) > 0 {
 				// There is a clipping path, build the gpu data and update the
 				// cache key such that it will be equal only if the transform is the
 				// same also. Use cached data if we have it.
 				quads.key = quads.key.SetTransform(trans)
+				if op.Aliased {
+					quads.key.aliased = true
+					_, _, quads.key.ox, _, _, quads.key.oy = trans.Elems()
+				}
 				if v, ok := d.pathCache.get(quads.key); ok {
 					// Since the GPU data exists in the cache aux will not be used.
 					// Why is this not used for the offset shapes?
 					bounds = v.bounds
 				} else {
 					var pathData []byte
-					pathData, bounds = d.buildVerts(
-						quads.aux, trans, quads.key.outline, quads.key.strokeWidth,
-					)
+					if op.Aliased {
+						pathData, bounds = d.buildAliasedVerts(
+							quads.aux, trans, quads.key.outline, quads.key.strokeWidth,
+						)
+					} else {
+						pathData, bounds = d.buildVerts(
+							quads.aux, trans, quads.key.outline, quads.key.strokeWidth,
+						)
+					}
 					quads.aux = pathData
 					// add it to the cache, without GPU data, so the transform can be
-					// reused.
-					d.pathCache.put(quads.key, opCacheValue{bounds: bounds})
+					// reused. Aliased areas without pixels are clipped as empty
+					// rectangles instead.
+					if len(pathData) > 0 {
+						d.pathCache.put(quads.key, opCacheValue{bounds: bounds})
+					}
 				}
+			} else if op.Aliased {
+				bounds = pixelRect(f32.Rectangle{
+					Min: trans.Transform(bounds.Min),
+					Max: trans.Transform(bounds.Max),
+				}.Canon())
+				quads.key = opKey{Key: encOp.Key}
 			} else {
 				quads.aux, bounds, _ = d.boundsForTransformedRect(bounds, trans)
 				quads.key = opKey{Key: encOp.Key}
@@ -954,58 +1025,21 @@ loop:
 			state.matType = materialTexture
 			state.image = decodeImageOp(encOp.Data, encOp.Refs)
 		case ops.TypePaint:
-			// Transform (if needed) the painting rectangle and if so generate a clip path,
-			// for those cases also compute a partialTrans that maps texture coordinates between
-			// the new bounding rectangle and the transformed original paint rectangle.
-			t, off := splitTransform(state.t)
-			// Fill the clip area, unless the material is a (bounded) image.
-			// TODO: Find a tighter bound.
-			inf := float32(1e6)
-			dst := f32.Rect(-inf, -inf, inf, inf)
-			if state.matType == materialTexture {
-				sz := state.image.src.Rect.Size()
-				dst = f32.Rectangle{Max: layout.FPt(sz)}
-			}
-			clipData, bnd, partialTrans := d.boundsForTransformedRect(dst, t)
-			cl := viewport.Intersect(bnd.Add(off))
+			d.paint(&state, encOp.Key, viewport)
+		case ops.TypeTiledImage:
+			op := decodeTiledImageOp(encOp.Data, encOp.Refs)
+			state.matType = materialTexture
+			state.image = op.image
+			t := state.t
+			cl := viewport
 			if state.cpath != nil {
 				cl = state.cpath.intersect.Intersect(cl)
 			}
-			if cl.Empty() {
-				continue
-			}
-
-			if clipData != nil {
-				// The paint operation is sheared or rotated, add a clip path representing
-				// this transformed rectangle.
-				k := opKey{Key: encOp.Key}
-				k.SetTransform(t) // TODO: This call has no effect.
-				d.addClipPath(&state, clipData, k, bnd, off, false)
-			}
-
-			bounds := boundRectF(cl)
-			mat := state.materialFor(bnd, off, partialTrans, bounds)
-
-			rect := state.cpath == nil || state.cpath.rect
-			if bounds.Min == (image.Point{}) && bounds.Max == d.viewport && rect && mat.opaque && (mat.material == materialColor) {
-				// The image is a uniform opaque color and takes up the whole screen.
-				// Scrap images up to and including this image and set clear color.
-				d.imageOps = d.imageOps[:0]
-				d.clearColor = mat.color.Opaque()
-				d.clear = true
-				continue
-			}
-			img := imageOp{
-				path:     state.cpath,
-				clip:     bounds,
-				material: mat,
-			}
-
-			d.imageOps = append(d.imageOps, img)
-			if clipData != nil {
-				// we added a clip path that should not remain
-				state.cpath = state.cpath.parent
-			}
+			op.forEachTile(t, cl, func(tile f32.Affine2D) {
+				state.t = t.Mul(tile)
+				d.paint(&state, encOp.Key, viewport)
+			})
+			state.t = t
 		case ops.TypeSave:
 			id := ops.DecodeSave(encOp.Data)
 			d.save(id, state.t)
@@ -1017,6 +1051,63 @@ loop:
 	}
 }
 
+// paint adds the image operation for painting the current clip area of
+// state with its material.
+func (d *drawOps) paint(state *drawState, key ops.Key, viewport f32.Rectangle) {
+	// Transform (if needed) the painting rectangle and if so generate a clip path,
+	// for those cases also compute a partialTrans that maps texture coordinates between
+	// the new bounding rectangle and the transformed original paint rectangle.
+	t, off := splitTransform(state.t)
+	// Fill the clip area, unless the material is a (bounded) image.
+	// TODO: Find a tighter bound.
+	inf := float32(1e6)
+	dst := f32.Rect(-inf, -inf, inf, inf)
+	if state.matType == materialTexture {
+		sz := state.image.src.Rect.Size()
+		dst = f32.Rectangle{Max: layout.FPt(sz)}
+	}
+	clipData, bnd, partialTrans := d.boundsForTransformedRect(dst, t)
+	cl := viewport.Intersect(bnd.Add(off))
+	if state.cpath != nil {
+		cl = state.cpath.intersect.Intersect(cl)
+	}
+	if cl.Empty() {
+		return
+	}
+
+	if clipData != nil {
+		// The paint operation is sheared or rotated, add a clip path representing
+		// this transformed rectangle.
+		k := opKey{Key: key}
+		k.SetTransform(t) // TODO: This call has no effect.
+		d.addClipPath(state, clipData, k, bnd, off, false)
+	}
+
+	bounds := boundRectF(cl)
+	mat := state.materialFor(bnd, off, partialTrans, bounds)
+
+	rect := state.cpath == nil || state.cpath.rect
+	if bounds.Min == (image.Point{}) && bounds.Max == d.viewport && rect && mat.opaque && (mat.material == materialColor) {
+		// The image is a uniform opaque color and takes up the whole screen.
+		// Scrap images up to and including this image and set clear color.
+		d.imageOps = d.imageOps[:0]
+		d.clearColor = mat.color.Opaque()
+		d.clear = true
+		return
+	}
+	img := imageOp{
+		path:     state.cpath,
+		clip:     bounds,
+		material: mat,
+	}
+
+	d.imageOps = append(d.imageOps, img)
+	if clipData != nil {
+		// we added a clip path that should not remain
+		state.cpath = state.cpath.parent
+	}
+}
+
 func expandPathOp(p *pathOp, clip image.Rectangle) {
 	for p != nil {
 		pclip := p.clip
@@ -1078,6 +1169,7 @@ func (r *renderer) uploadImages(cache *resourceCache, ops []imageOp) {
 }
 
 func (r *renderer) prepareDrawOps(cache *resourceCache, ops []imageOp) {
+	r.prepareBatches(ops)
 	for _, img := range ops {
 		m := img.material
 		switch m.material {
@@ -1100,7 +1192,16 @@ func (r *renderer) prepareDrawOps(cache *resourceCache, ops []imageOp) {
 
 func (r *renderer) drawOps(cache *resourceCache, ops []imageOp) {
 	var coverTex driver.Texture
-	for _, img := range ops {
+	batches := r.batches
+	for i := 0; i < len(ops); i++ {
+		if len(batches) > 0 && batches[0].start == i {
+			b := batches[0]
+			batches = batches[1:]
+			r.drawBatch(b, ops[i].material.color)
+			i = b.end - 1
+			continue
+		}
+		img := ops[i]
 		m := img.material
 		switch m.material {
 		case materialTexture:
@@ -1116,6 +1217,7 @@ func (r *renderer) drawOps(cache *resourceCache, ops []imageOp) {
 			r.ctx.BindPipeline(p.pipeline)
 			r.ctx.BindVertexBuffer(r.blitter.quadVerts, 0)
 			r.blitter.blit(m.material, m.color, m.color1, m.color2, scale, off, m.uvTrans)
+			r.used |= programBlitColor << m.material
 			continue
 		case clipTypePath:
 			fbo = r.pather.stenciler.cover(img.place.Idx)
@@ -1135,6 +1237,7 @@ func (r *renderer) drawOps(cache *resourceCache, ops []imageOp) {
 		r.ctx.BindPipeline(p.pipeline)
 		r.ctx.BindVertexBuffer(r.blitter.quadVerts, 0)
 		r.pather.cover(m.material, m.color, m.color1, m.color2, scale, off, m.uvTrans, coverScale, coverOff)
+		r.used |= programCoverColor << m.material
 	}
 }
 
@@ -1331,34 +1434,52 @@ func (d *drawOps) buildVerts(pathData []byte, tr f32.Affine2D, outline bool, str
 	return d.vertCache[startLength:], d.qs.bounds
 }
 
+// buildAliasedVerts is like buildVerts for aliased clip areas. The
+// vertices outline the pixels inside the area transformed by tr.
+func (d *drawOps) buildAliasedVerts(pathData []byte, tr f32.Affine2D, outline bool, strWidth float32) (verts []byte, bounds f32.Rectangle) {
+	d.alias.reset()
+	d.alias.addPath(pathData, tr, outline, strWidth)
+	d.aliasPath, _ = d.alias.appendPixels(d.aliasPath[:0], f32.Affine2D{})
+	if len(d.aliasPath) == 0 {
+		// No pixels are inside.
+		return nil, f32.Rectangle{}
+	}
+	return d.buildVerts(d.aliasPath, f32.Affine2D{}, true, 0)
+}
+
 // decodeOutlineQuads decodes scene commands, splits them into quadratic béziers
 // as needed and feeds them to the supplied splitter.
 func decodeToOutlineQuads(qs *quadSplitter, tr f32.Affine2D, pathData []byte) {
+	forEachOutlineQuad(tr, pathData, func(contour uint32, q stroke.QuadSegment) {
+		qs.contour = contour
+		qs.splitAndEncode(q)
+	})
+}
+
+// forEachOutlineQuad decodes scene commands, splits them into quadratic
+// béziers as needed and calls f with each bézier transformed by tr.
+func forEachOutlineQuad(tr f32.Affine2D, pathData []byte, f func(contour uint32, q stroke.QuadSegment)) {
 	for len(pathData) >= scene.CommandSize+4 {
-		qs.contour = bo.Uint32(pathData)
+		contour := bo.Uint32(pathData)
 		cmd := ops.DecodeCommand(pathData[4:])
 		switch cmd.Op() {
 		case scene.OpLine:
 			var q stroke.QuadSegment
 			q.From, q.To = scene.DecodeLine(cmd)
 			q.Ctrl = q.From.Add(q.To).Mul(.5)
-			q = q.Transform(tr)
-			qs.splitAndEncode(q)
+			f(contour, q.Transform(tr))
 		case scene.OpGap:
 			var q stroke.QuadSegment
 			q.From, q.To = scene.DecodeGap(cmd)
 			q.Ctrl = q.From.Add(q.To).Mul(.5)
-			q = q.Transform(tr)
-			qs.splitAndEncode(q)
+			f(contour, q.Transform(tr))
 		case scene.OpQuad:
 			var q stroke.QuadSegment
 			q.From, q.Ctrl, q.To = scene.DecodeQuad(cmd)
-			q = q.Transform(tr)
-			qs.splitAndEncode(q)
+			f(contour, q.Transform(tr))
 		case scene.OpCubic:
 			for _, q := range stroke.SplitCubic(scene.DecodeCubic(cmd)) {
-				q = q.Transform(tr)
-				qs.splitAndEncode(q)
+				f(contour, q.Transform(tr))
 			}
 		default:
 			panic("unsupported scene command")
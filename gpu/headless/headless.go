@@ -11,6 +11,7 @@ import (
 
 	"gioui.org/gpu"
 	"gioui.org/gpu/internal/driver"
+	"gioui.org/io/diag"
 	"gioui.org/op"
 )
 
@@ -21,6 +22,7 @@ type Window struct {
 	dev    driver.Device
 	gpu    gpu.GPU
 	fboTex driver.Texture
+	diag   *diag.Reporter
 }
 
 type context interface {
@@ -33,8 +35,14 @@ type context interface {
 var (
 	newContextPrimary  func() (context, error)
 	newContextFallback func() (context, error)
+	// newDevice is replaced by tests to inject faults.
+	newDevice = driver.NewDevice
 )
 
+// errNoContext is returned by the methods of a window whose context
+// couldn't be recreated after its loss.
+var errNoContext = errors.New("headless: window has no context")
+
 func newContext() (context, error) {
 	funcs := []func() (context, error){newContextPrimary, newContextFallback}
 	var firstErr error
@@ -67,32 +75,7 @@ func NewWindow(width, height int) (*Window, error) {
 		size: image.Point{X: width, Y: height},
 		ctx:  ctx,
 	}
-	err = contextDo(ctx, func() error {
-		dev, err := driver.NewDevice(ctx.API())
-		if err != nil {
-			return err
-		}
-		fboTex, err := dev.NewTexture(
-			driver.TextureFormatSRGBA,
-			width, height,
-			driver.FilterNearest, driver.FilterNearest,
-			driver.BufferBindingFramebuffer,
-		)
-		if err != nil {
-			dev.Release()
-			return nil
-		}
-		// Note that the gpu takes ownership of dev.
-		gp, err := gpu.NewWithDevice(dev)
-		if err != nil {
-			fboTex.Release()
-			return err
-		}
-		w.fboTex = fboTex
-		w.gpu = gp
-		w.dev = dev
-		return err
-	})
+	err = contextDo(ctx, w.init)
 	if err != nil {
 		ctx.Release()
 		return nil, err
@@ -100,25 +83,62 @@ func NewWindow(width, height int) (*Window, error) {
 	return w, nil
 }
 
+// init creates the GPU resources of the window. It must be called with
+// the context current.
+func (w *Window) init() error {
+	dev, err := newDevice(w.ctx.API())
+	if err != nil {
+		return err
+	}
+	fboTex, err := dev.NewTexture(
+		driver.TextureFormatSRGBA,
+		w.size.X, w.size.Y,
+		driver.FilterNearest, driver.FilterNearest,
+		driver.BufferBindingFramebuffer,
+	)
+	if err != nil {
+		dev.Release()
+		return err
+	}
+	// Note that the gpu takes ownership of dev.
+	gp, err := gpu.NewWithDevice(dev)
+	if err != nil {
+		fboTex.Release()
+		return err
+	}
+	gpu.SetDiagnostics(gp, w.diag)
+	w.fboTex = fboTex
+	w.gpu = gp
+	w.dev = dev
+	return nil
+}
+
+// release the GPU resources of the window. It must be called with the
+// context current.
+func (w *Window) release() {
+	if w.fboTex != nil {
+		w.fboTex.Release()
+		w.fboTex = nil
+	}
+	if w.gpu != nil {
+		w.gpu.Release()
+		w.gpu = nil
+	}
+	// w.dev is owned and freed by w.gpu.
+	w.dev = nil
+}
+
 // Release resources associated with the window.
 func (w *Window) Release() {
+	if w.ctx == nil {
+		return
+	}
 	contextDo(w.ctx, func() error {
-		if w.fboTex != nil {
-			w.fboTex.Release()
-			w.fboTex = nil
-		}
-		if w.gpu != nil {
-			w.gpu.Release()
-			w.gpu = nil
-		}
-		// w.dev is owned and freed by w.gpu.
-		w.dev = nil
+		w.release()
 		return nil
 	})
-	if w.ctx != nil {
-		w.ctx.Release()
-		w.ctx = nil
-	}
+	w.ctx.Release()
+	w.ctx = nil
 }
 
 // Size returns the window size.
@@ -127,11 +147,75 @@ func (w *Window) Size() image.Point {
 }
 
 // Frame replaces the window content and state with the
-// operation list.
+// operation list. If the GPU device is lost, Frame recreates the context
+// and GPU resources of the window and draws the frame again. Images are
+// uploaded again from their sources.
 func (w *Window) Frame(frame *op.Ops) error {
-	return contextDo(w.ctx, func() error {
+	draw := func() error {
 		w.gpu.Clear(color.NRGBA{})
 		return w.gpu.Frame(frame, w.fboTex, w.size)
+	}
+	err := contextDo(w.ctx, draw)
+	if !errors.Is(err, gpu.ErrDeviceLost) {
+		return err
+	}
+	if err := w.recover(); err != nil {
+		return err
+	}
+	return contextDo(w.ctx, draw)
+}
+
+// recover replaces the lost context of the window and its GPU
+// resources. A lost context can't be made usable again, so it is
+// released along with the device.
+func (w *Window) recover() error {
+	contextDo(w.ctx, func() error {
+		w.release()
+		return nil
+	})
+	w.ctx.Release()
+	w.ctx = nil
+	ctx, err := newContext()
+	if err != nil {
+		return err
+	}
+	w.ctx = ctx
+	if err := contextDo(ctx, w.init); err != nil {
+		ctx.Release()
+		w.ctx = nil
+		return err
+	}
+	w.diag.Report(diag.Diagnostic{
+		Severity: diag.Info,
+		Source:   "gpu",
+		Message:  "device recovered",
+	})
+	return nil
+}
+
+// SetDiagnostics directs the diagnostics of the window, such as the loss
+// and recovery of the GPU device, to r.
+func (w *Window) SetDiagnostics(r *diag.Reporter) {
+	w.diag = r
+	contextDo(w.ctx, func() error {
+		gpu.SetDiagnostics(w.gpu, r)
+		return nil
+	})
+}
+
+// WarmUp prepares the built-in GPU programs to avoid compilation
+// hitches during the first frames. See gpu.WarmUp.
+func (w *Window) WarmUp() error {
+	return contextDo(w.ctx, func() error {
+		return gpu.WarmUp(w.gpu)
+	})
+}
+
+// Trim releases the cached GPU resources of the window. See gpu.Trim.
+func (w *Window) Trim() error {
+	return contextDo(w.ctx, func() error {
+		gpu.Trim(w.gpu)
+		return nil
 	})
 }
 
@@ -143,6 +227,9 @@ func (w *Window) Screenshot(img *image.RGBA) error {
 }
 
 func contextDo(ctx context, f func() error) error {
+	if ctx == nil {
+		return errNoContext
+	}
 	errCh := make(chan error)
 	go func() {
 		if err := ctx.MakeCurrent(); err != nil {
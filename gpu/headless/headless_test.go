@@ -3,12 +3,16 @@
 package headless
 
 import (
+	"bytes"
 	"image"
 	"image/color"
+	"image/draw"
 	"testing"
 
 	"gioui.org/f32"
+	"gioui.org/gpu/internal/driver"
 	"gioui.org/internal/f32color"
+	"gioui.org/io/diag"
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
@@ -143,6 +147,209 @@ func TestNoOps(t *testing.T) {
 	}
 }
 
+func TestWarmUp(t *testing.T) {
+	w, release := newTestWindow(t)
+	defer release()
+	if err := w.WarmUp(); err != nil {
+		t.Fatal(err)
+	}
+	// The warm-up frame must not leak into the window.
+	col := color.NRGBA{A: 0xff, R: 0xca, G: 0xfe}
+	var ops op.Ops
+	paint.FillShape(&ops, col, clip.Rect(image.Rect(0, 0, 10, 10)).Op())
+	if err := w.Frame(&ops); err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewRGBA(image.Rectangle{Max: w.Size()})
+	if err := w.Screenshot(img); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := img.RGBAAt(5, 5), f32color.NRGBAToRGBA(col); got != exp {
+		t.Errorf("got color %v, expected %v", got, exp)
+	}
+	if got := img.RGBAAt(20, 20); got != (color.RGBA{}) {
+		t.Errorf("got color %v outside the frame content, expected transparent", got)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	w, release := newTestWindow(t)
+	defer release()
+	var ops op.Ops
+	firstFrameOps(&ops)
+	frame := func() *image.RGBA {
+		t.Helper()
+		if err := w.Frame(&ops); err != nil {
+			t.Fatal(err)
+		}
+		img := image.NewRGBA(image.Rectangle{Max: w.Size()})
+		if err := w.Screenshot(img); err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+	before := frame()
+	if err := w.Trim(); err != nil {
+		t.Fatal(err)
+	}
+	// The trimmed resources must be recreated by the next frame.
+	after := frame()
+	if !bytes.Equal(before.Pix, after.Pix) {
+		t.Error("frame after Trim differs from the frame before")
+	}
+}
+
+// lossyDevice is a device that reports its loss when lost is set.
+type lossyDevice struct {
+	driver.Device
+	lost *bool
+}
+
+func (d lossyDevice) DeviceLost() bool {
+	lost := *d.lost
+	*d.lost = false
+	return lost
+}
+
+func TestDeviceLoss(t *testing.T) {
+	var lost bool
+	devices := 0
+	newDevice = func(api driver.API) (driver.Device, error) {
+		d, err := driver.NewDevice(api)
+		if err != nil {
+			return nil, err
+		}
+		devices++
+		return lossyDevice{Device: d, lost: &lost}, nil
+	}
+	defer func() { newDevice = driver.NewDevice }()
+	contexts := 0
+	countContexts := func(f *func() (context, error)) {
+		newCtx := *f
+		if newCtx == nil {
+			return
+		}
+		*f = func() (context, error) {
+			c, err := newCtx()
+			if err == nil {
+				contexts++
+			}
+			return c, err
+		}
+		t.Cleanup(func() { *f = newCtx })
+	}
+	countContexts(&newContextPrimary)
+	countContexts(&newContextFallback)
+	w, release := newTestWindow(t)
+	defer release()
+	var diags []diag.Diagnostic
+	r := new(diag.Reporter)
+	r.SetSink(func(d diag.Diagnostic) {
+		diags = append(diags, d)
+	})
+	w.SetDiagnostics(r)
+
+	// The image must be uploaded again after the loss.
+	col := color.NRGBA{A: 0xff, R: 0xca, G: 0xfe}
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: col}, image.Point{}, draw.Src)
+	var ops op.Ops
+	paint.NewImageOp(img).Add(&ops)
+	paint.PaintOp{}.Add(&ops)
+	frame := func() color.RGBA {
+		t.Helper()
+		if err := w.Frame(&ops); err != nil {
+			t.Fatal(err)
+		}
+		scr := image.NewRGBA(image.Rectangle{Max: w.Size()})
+		if err := w.Screenshot(scr); err != nil {
+			t.Fatal(err)
+		}
+		return scr.RGBAAt(5, 5)
+	}
+	exp := f32color.NRGBAToRGBA(col)
+	if got := frame(); got != exp {
+		t.Fatalf("got color %v, expected %v", got, exp)
+	}
+	// Lose the device during the next frame.
+	lost = true
+	if got := frame(); got != exp {
+		t.Errorf("got color %v after recovery, expected %v", got, exp)
+	}
+	if devices != 2 {
+		t.Errorf("got %d devices, expected a recreated device", devices)
+	}
+	if contexts != 2 {
+		t.Errorf("got %d contexts, expected a recreated context", contexts)
+	}
+	var msgs []string
+	for _, d := range diags {
+		msgs = append(msgs, d.Message)
+	}
+	if len(diags) != 2 || diags[0].Severity != diag.Error || diags[1].Severity != diag.Info {
+		t.Errorf("got diagnostics %q, expected the loss and recovery of the device", msgs)
+	}
+	// Frames after the recovery are drawn by the new device.
+	if got := frame(); got != exp {
+		t.Errorf("got color %v, expected %v", got, exp)
+	}
+}
+
+// BenchmarkFirstFrame measures the first frame of a new window that draws
+// every material with and without clipping, with and without warm-up.
+func BenchmarkFirstFrame(b *testing.B) {
+	for _, warm := range []bool{false, true} {
+		name := "lazy"
+		if warm {
+			name = "warm"
+		}
+		b.Run(name, func(b *testing.B) {
+			var ops op.Ops
+			firstFrameOps(&ops)
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				w, err := NewWindow(800, 600)
+				if err != nil {
+					b.Skipf("headless windows not supported: %v", err)
+				}
+				if warm {
+					if err := w.WarmUp(); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.StartTimer()
+				if err := w.Frame(&ops); err != nil {
+					b.Fatal(err)
+				}
+				// Wait for the GPU.
+				if err := w.Screenshot(image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+					b.Fatal(err)
+				}
+				b.StopTimer()
+				w.Release()
+			}
+		})
+	}
+}
+
+func firstFrameOps(ops *op.Ops) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	paint.NewImageOp(img).Add(ops)
+	paint.PaintOp{}.Add(ops)
+	paint.LinearGradientOp{
+		Stop2:  f32.Pt(100, 0),
+		Color1: color.NRGBA{R: 0xff, A: 0xff},
+		Color2: color.NRGBA{B: 0xff, A: 0xff},
+	}.Add(ops)
+	cl := clip.Ellipse(f32.Rect(10, 10, 300, 200)).Push(ops)
+	paint.PaintOp{}.Add(ops)
+	cl2 := clip.RRect{Rect: f32.Rect(50, 50, 400, 300), SE: 30}.Push(ops)
+	paint.ColorOp{Color: color.NRGBA{G: 0xff, A: 0xff}}.Add(ops)
+	paint.PaintOp{}.Add(ops)
+	cl2.Pop()
+	cl.Pop()
+}
+
 func newTestWindow(t *testing.T) (*Window, func()) {
 	t.Helper()
 	sz := image.Point{X: 800, Y: 600}
@@ -56,6 +56,15 @@ type Device interface {
 
 var ErrDeviceLost = errors.New("GPU device lost")
 
+// LossDetector is implemented by Devices that detect the loss of their
+// device without failing an operation, such as a reset of an OpenGL
+// context.
+type LossDetector interface {
+	// DeviceLost reports whether the device was lost since the last
+	// call to DeviceLost.
+	DeviceLost() bool
+}
+
 type LoadDesc struct {
 	Action     LoadAction
 	ClearColor f32color.RGBA
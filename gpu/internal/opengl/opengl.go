@@ -671,6 +671,13 @@ func (b *Backend) NewTimer() driver.Timer {
 	}
 }
 
+// DeviceLost implements driver.LossDetector. Context resets are
+// reported by glGetGraphicsResetStatus on contexts with reset
+// notifications.
+func (b *Backend) DeviceLost() bool {
+	return b.funcs.GetGraphicsResetStatus() != gl.NO_ERROR
+}
+
 func (b *Backend) IsTimeContinuous() bool {
 	return b.funcs.GetInteger(gl.GPU_DISJOINT_EXT) == gl.FALSE
 }
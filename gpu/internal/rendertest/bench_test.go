@@ -154,6 +154,34 @@ func Benchmark1000CirclesInstanced(b *testing.B) {
 	finishBenchmark(b, w)
 }
 
+// Benchmark5000Rects draws 5000 rectangle fills of one color, which the
+// renderer batches into a single draw call. Compare with
+// GIORENDERER=nobatch for the unbatched renderer.
+func Benchmark5000Rects(b *testing.B) {
+	gtx, w, _ := setupBenchmark(b)
+	defer w.Release()
+	draw5000Rects(gtx)
+	w.Frame(gtx.Ops)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resetOps(gtx)
+		draw5000Rects(gtx)
+		w.Frame(gtx.Ops)
+	}
+	finishBenchmark(b, w)
+}
+
+func draw5000Rects(gtx layout.Context) {
+	col := color.NRGBA{R: 100, G: 120, B: 140, A: 0xff}
+	for x := 0; x < 100; x++ {
+		for y := 0; y < 50; y++ {
+			r := image.Rect(x*10, y*20, x*10+8, y*20+18)
+			paint.FillShape(gtx.Ops, col, clip.Rect(r).Op())
+		}
+	}
+}
+
 func draw1000Circles(gtx layout.Context) {
 	ops := gtx.Ops
 	for x := 0; x < 100; x++ {
@@ -11,6 +11,7 @@ import (
 	"golang.org/x/image/colornames"
 
 	"gioui.org/f32"
+	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
@@ -291,3 +292,72 @@ func TestStrokedRect(t *testing.T) {
 	}, func(r result) {
 	})
 }
+
+func TestClipAliased(t *testing.T) {
+	ellipse := f32.Rect(10.3, 10.7, 60.1, 50.2)
+	// The rectangle is rotated by 30 degrees around its center.
+	rect := image.Rect(76, 76, 116, 116)
+	rot := f32.Affine2D{}.Rotate(f32.Pt(96, 96), math.Pi/6)
+	smooth := f32.Rect(10.3, 70.7, 60.1, 110.2)
+	ops := new(op.Ops)
+	img, err := drawImage(t, 128, ops, func(o *op.Ops) {
+		paint.FillShape(o, red, clip.Ellipse(ellipse).Op(o).Aliased())
+		// Only transform the clip area; the paint is not rotated.
+		stack := op.Affine(rot).Push(o)
+		cl := clip.Rect(rect).Op().Aliased().Push(o)
+		stack.Pop()
+		paint.ColorOp{Color: red}.Add(o)
+		paint.PaintOp{}.Add(o)
+		cl.Pop()
+		paint.FillShape(o, red, clip.Ellipse(smooth).Op(o))
+	})
+	if err != nil {
+		t.Fatal("error rendering:", err)
+	}
+	// inside reports whether p is inside the shape of the area
+	// containing it, and whether p is clearly inside or outside.
+	inside := func(p f32.Point, e f32.Rectangle) (bool, bool) {
+		c, r := e.Min.Add(e.Max).Mul(.5), e.Size().Mul(.5)
+		d := p.Sub(c)
+		dist := float32(math.Hypot(float64(d.X/r.X), float64(d.Y/r.Y))) - 1
+		return dist < 0, dist < -.01 || dist > .01
+	}
+	insideRect := func(p f32.Point) (bool, bool) {
+		p = rot.Invert().Transform(p)
+		r := layout.FRect(rect)
+		in := r.Min.X < p.X && p.X < r.Max.X && r.Min.Y < p.Y && p.Y < r.Max.Y
+		d := math.Min(
+			math.Min(math.Abs(float64(p.X-r.Min.X)), math.Abs(float64(p.X-r.Max.X))),
+			math.Min(math.Abs(float64(p.Y-r.Min.Y)), math.Abs(float64(p.Y-r.Max.Y))))
+		return in, d > .01
+	}
+	partial := 0
+	for y := 0; y < 128; y++ {
+		for x := 0; x < 128; x++ {
+			c := img.RGBAAt(x, y)
+			center := f32.Pt(float32(x)+.5, float32(y)+.5)
+			var in, clear bool
+			switch {
+			case x < 64 && y >= 64:
+				if c != transparent && c != colornames.Red {
+					partial++
+				}
+				continue
+			case x < 64:
+				in, clear = inside(center, ellipse)
+			default:
+				in, clear = insideRect(center)
+			}
+			if c != transparent && c != colornames.Red {
+				t.Errorf("got antialiased pixel %v at (%d, %d)", c, x, y)
+				continue
+			}
+			if clear && in != (c == colornames.Red) {
+				t.Errorf("got %v at (%d, %d) inside (%v) the aliased area", c, x, y, in)
+			}
+		}
+	}
+	if partial == 0 {
+		t.Error("no antialiased pixels in the antialiased area")
+	}
+}
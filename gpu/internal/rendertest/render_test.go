@@ -12,6 +12,7 @@ import (
 
 	"gioui.org/f32"
 	"gioui.org/internal/f32color"
+	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
@@ -420,3 +421,126 @@ func lerp(a, b f32color.RGBA, p float32) f32color.RGBA {
 		A: a.A*(1-p) + b.A*p,
 	}
 }
+
+func TestBatchedRects(t *testing.T) {
+	// Rectangle fills are batched by color, interleaved with a rounded
+	// rectangle and translucent overlapping fills that must keep their
+	// order.
+	draw := func(o *op.Ops) {
+		drawRects(o, 128, 8)
+		paint.FillShape(o, blue, clip.RRect{Rect: f32.Rect(20, 20, 80, 80), SE: 10, SW: 10, NW: 10, NE: 10}.Op(o))
+		translucent := color.NRGBA{R: 0xff, A: 0x80}
+		for i := 0; i < 4; i++ {
+			paint.FillShape(o, translucent, clip.Rect(image.Rect(40+i*10, 40, 90+i*10, 90)).Op())
+		}
+		paint.FillShape(o, green, clip.Rect(image.Rect(60, 60, 70, 70)).Op())
+	}
+	ops := new(op.Ops)
+	batched, err := drawImage(t, 128, ops, draw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIORENDERER", "nobatch")
+	ops.Reset()
+	unbatched, err := drawImage(t, 128, ops, draw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := batched.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c1, c2 := batched.RGBAAt(x, y), unbatched.RGBAAt(x, y); c1 != c2 {
+				saveImage(t, t.Name()+"-batched.png", batched)
+				saveImage(t, t.Name()+"-unbatched.png", unbatched)
+				t.Fatalf("(%d,%d): batched color %v, unbatched color %v", x, y, c1, c2)
+			}
+		}
+	}
+}
+
+func TestTiledImage(t *testing.T) {
+	// The 8x8 tile is red in its top left quadrant, and blue elsewhere.
+	tile := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			col := colornames.Blue
+			if x < 4 && y < 4 {
+				col = colornames.Red
+			}
+			tile.SetRGBA(x, y, col)
+		}
+	}
+	src := paint.NewImageOp(tile)
+	clipRect := image.Rect(10, 10, 100, 90)
+	off := image.Pt(3, 5)
+	for _, size := range []int{8, 16} {
+		tiled := func(o *op.Ops) {
+			defer clip.Rect(clipRect).Push(o).Pop()
+			paint.TiledImageOp{Src: src, TileSize: layout.FPt(image.Pt(size, size)), Offset: layout.FPt(off)}.Add(o)
+		}
+		// explicit paints every tile with a separate ImageOp.
+		explicit := func(o *op.Ops) {
+			defer clip.Rect(clipRect).Push(o).Pop()
+			scale := float32(size) / 8
+			for y := off.Y - 2*size; y < clipRect.Max.Y; y += size {
+				for x := off.X - 2*size; x < clipRect.Max.X; x += size {
+					a := f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(scale, scale)).Offset(layout.FPt(image.Pt(x, y)))
+					tr := op.Affine(a).Push(o)
+					src.Add(o)
+					paint.PaintOp{}.Add(o)
+					tr.Pop()
+				}
+			}
+		}
+		ops := new(op.Ops)
+		img, err := drawImage(t, 128, ops, tiled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := result{t: t, img: img}
+		mod := func(v int) int {
+			return ((v % size) + size) % size
+		}
+		// Images are filtered linearly, so the points are away from the
+		// edges of the quadrants of the scaled tiles.
+		for _, p := range []image.Point{{12, 10}, {20, 22}, {24, 27}, {33, 31}, {76, 62}, {97, 86}} {
+			col := colornames.Blue
+			if mod(p.X-off.X) < size/2 && mod(p.Y-off.Y) < size/2 {
+				col = colornames.Red
+			}
+			r.expect(p.X, p.Y, col)
+		}
+		// Outside the clip.
+		r.expect(5, 5, transparent)
+		r.expect(100, 90, transparent)
+		ops.Reset()
+		want, err := drawImage(t, 128, ops, explicit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if c1, c2 := img.RGBAAt(x, y), want.RGBAAt(x, y); c1 != c2 {
+					saveImage(t, t.Name()+"-tiled.png", img)
+					saveImage(t, t.Name()+"-explicit.png", want)
+					t.Fatalf("tile size %d: (%d,%d): tiled color %v, explicit color %v", size, x, y, c1, c2)
+				}
+			}
+		}
+	}
+}
+
+// drawRects fills a grid of size×size pixels with cells of cell×cell
+// pixels in alternating colors, one column at a time.
+func drawRects(o *op.Ops, size, cell int) {
+	for x := 0; x < size; x += cell {
+		for y := 0; y < size; y += cell {
+			col := red
+			if (x/cell+y/cell)%2 == 1 {
+				col = white
+			}
+			paint.FillShape(o, col, clip.Rect(image.Rect(x, y, x+cell, y+cell)).Op())
+		}
+	}
+}
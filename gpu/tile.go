@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package gpu
+
+import (
+	"encoding/binary"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/internal/ops"
+)
+
+type tiledImageOpData struct {
+	image        imageOpData
+	size, offset f32.Point
+}
+
+// maxTiles bounds the number of tiles drawn for a single TiledImageOp,
+// to bound the cost of tiles that are tiny compared to the clip area.
+const maxTiles = 10000
+
+func decodeTiledImageOp(data []byte, refs []interface{}) tiledImageOpData {
+	if ops.OpType(data[0]) != ops.TypeTiledImage {
+		panic("invalid op")
+	}
+	bo := binary.LittleEndian
+	return tiledImageOpData{
+		image: decodeImageOp(data, refs),
+		size: f32.Point{
+			X: math.Float32frombits(bo.Uint32(data[1:])),
+			Y: math.Float32frombits(bo.Uint32(data[5:])),
+		},
+		offset: f32.Point{
+			X: math.Float32frombits(bo.Uint32(data[9:])),
+			Y: math.Float32frombits(bo.Uint32(data[13:])),
+		},
+	}
+}
+
+// forEachTile calls f with the transformation of every tile that overlaps
+// clip. The transformation t maps the coordinates of the tiles to the
+// coordinates of clip, and the tile transformations map the image to its
+// tile in tile coordinates.
+//
+// The shaders don't support repeating textures, so tiles are drawn as
+// separate images.
+func (op tiledImageOpData) forEachTile(t f32.Affine2D, clip f32.Rectangle, f func(tile f32.Affine2D)) {
+	if op.image.src == nil || clip.Empty() {
+		return
+	}
+	sz := op.image.src.Rect.Size()
+	imgSize := f32.Pt(float32(sz.X), float32(sz.Y))
+	ts := op.size
+	if ts.X == 0 {
+		ts.X = imgSize.X
+	}
+	if ts.Y == 0 {
+		ts.Y = imgSize.Y
+	}
+	if ts.X <= 0 || ts.Y <= 0 {
+		return
+	}
+	// Find the bounds of clip in tile coordinates.
+	inv := t.Invert()
+	p := inv.Transform(clip.Min)
+	bounds := f32.Rectangle{Min: p, Max: p}
+	for _, c := range [...]f32.Point{{X: clip.Max.X, Y: clip.Min.Y}, {X: clip.Min.X, Y: clip.Max.Y}, clip.Max} {
+		p := inv.Transform(c)
+		bounds.Min.X = float32(math.Min(float64(bounds.Min.X), float64(p.X)))
+		bounds.Min.Y = float32(math.Min(float64(bounds.Min.Y), float64(p.Y)))
+		bounds.Max.X = float32(math.Max(float64(bounds.Max.X), float64(p.X)))
+		bounds.Max.Y = float32(math.Max(float64(bounds.Max.Y), float64(p.Y)))
+	}
+	x0 := math.Floor(float64((bounds.Min.X - op.offset.X) / ts.X))
+	x1 := math.Ceil(float64((bounds.Max.X - op.offset.X) / ts.X))
+	y0 := math.Floor(float64((bounds.Min.Y - op.offset.Y) / ts.Y))
+	y1 := math.Ceil(float64((bounds.Max.Y - op.offset.Y) / ts.Y))
+	scale := f32.Pt(ts.X/imgSize.X, ts.Y/imgSize.Y)
+	n := 0
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			if n == maxTiles {
+				return
+			}
+			n++
+			pos := op.offset.Add(f32.Pt(float32(x)*ts.X, float32(y)*ts.Y))
+			f(f32.Affine2D{}.Scale(f32.Point{}, scale).Offset(pos))
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package gpu
+
+// Trim releases the cached resources of g, such as image textures, path
+// vertex buffers and stencil buffers. The resources are recreated when
+// needed by later frames, so Trim is suitable for reducing the memory use
+// of an idle or hidden window without releasing g.
+//
+// Trim must be called between frames, with the GPU context current.
+func Trim(g GPU) {
+	if t, ok := g.(interface{ trim() }); ok {
+		t.trim()
+	}
+}
+
+func (g *gpu) trim() {
+	budget := g.cache.budget
+	g.cache.release()
+	g.cache = newResourceCache()
+	g.cache.diag = g.diag
+	g.cache.budget = budget
+	g.drawOps.pathCache.release()
+	g.drawOps.pathCache = newOpCache()
+	g.renderer.trim()
+	g.drawOps.vertCache = nil
+	g.drawOps.pathOpCache = nil
+}
+
+func (r *renderer) trim() {
+	s := r.pather.stenciler
+	s.fbos.delete(s.ctx, 0)
+	s.intersections.delete(s.ctx, 0)
+	r.batchBuf.Release()
+	r.batches = nil
+	r.batchVerts = nil
+}
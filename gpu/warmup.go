@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package gpu
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/f32"
+	"gioui.org/gpu/internal/driver"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+)
+
+// program is a set of built-in programs, tracked for diagnosing programs
+// that are first used during a session instead of during WarmUp.
+type program uint16
+
+const (
+	// The blit and cover programs are ordered by materialType.
+	programBlitColor program = 1 << iota
+	programBlitLinearGradient
+	programBlitTexture
+	programCoverColor
+	programCoverLinearGradient
+	programCoverTexture
+	programStencil
+	programIntersect
+)
+
+// warmUpSize is the size of the warm-up frame.
+const warmUpSize = 16
+
+// WarmUp renders an offscreen frame that exercises the built-in programs
+// of g. Many drivers defer shader compilation and pipeline creation to the
+// first draw that uses them, which shows as hitches the first time a
+// gradient, an image or a path is drawn. WarmUp moves that cost to
+// initialization.
+//
+// WarmUp must be called between frames, with the GPU context current. If
+// WarmUp fails, g remains usable and the remaining programs are compiled
+// when first used.
+//
+// When profiling is enabled, the default renderer reports the programs not
+// warmed up but used during the session as a "lazy" entry in Profile.
+func WarmUp(g GPU) error {
+	w, ok := g.(interface{ warmUp() error })
+	if !ok {
+		return nil
+	}
+	return w.warmUp()
+}
+
+func (g *gpu) warmUp() error {
+	clear, clearColor := g.drawOps.clear, g.drawOps.clearColor
+	defer func() {
+		g.drawOps.clear, g.drawOps.clearColor = clear, clearColor
+	}()
+	err := warmUpFrame(g.ctx, func(target RenderTarget, frame *op.Ops, viewport image.Point) error {
+		g.drawOps.clear = true
+		return g.Frame(frame, target, viewport)
+	})
+	g.warmed |= g.renderer.used
+	return err
+}
+
+func (g *compute) warmUp() error {
+	clear, clearColor := g.collector.clear, g.collector.clearColor
+	defer func() {
+		g.collector.clear, g.collector.clearColor = clear, clearColor
+	}()
+	return warmUpFrame(g.ctx, func(target RenderTarget, frame *op.Ops, viewport image.Point) error {
+		g.collector.clear = true
+		return g.Frame(frame, target, viewport)
+	})
+}
+
+// warmUpFrame renders the warm-up operations to an offscreen target with
+// frame. Panics from the renderer are converted to errors.
+func warmUpFrame(ctx driver.Device, frame func(target RenderTarget, ops *op.Ops, viewport image.Point) error) (err error) {
+	tex, err := ctx.NewTexture(
+		driver.TextureFormatSRGBA,
+		warmUpSize, warmUpSize,
+		driver.FilterNearest, driver.FilterNearest,
+		driver.BufferBindingFramebuffer,
+	)
+	if err != nil {
+		return fmt.Errorf("gpu: warm-up failed: %w", err)
+	}
+	defer tex.Release()
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("gpu: warm-up failed: %v", e)
+		}
+	}()
+	var ops op.Ops
+	warmUpOps(&ops)
+	if err := frame(tex, &ops, image.Pt(warmUpSize, warmUpSize)); err != nil {
+		return fmt.Errorf("gpu: warm-up failed: %w", err)
+	}
+	return nil
+}
+
+// warmUpOps draws every material, unclipped, clipped by a path and
+// clipped by an intersection of paths.
+func warmUpOps(o *op.Ops) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	imgOp := paint.NewImageOp(img)
+	materials := []func(){
+		func() {
+			paint.ColorOp{Color: color.NRGBA{A: 0xff}}.Add(o)
+		},
+		func() {
+			paint.LinearGradientOp{
+				Stop2:  f32.Pt(warmUpSize, 0),
+				Color1: color.NRGBA{A: 0xff},
+				Color2: color.NRGBA{R: 0xff, A: 0xff},
+			}.Add(o)
+		},
+		func() {
+			imgOp.Add(o)
+		},
+	}
+	// Don't cover the frame, to avoid the clear optimization.
+	r := image.Rect(1, 1, warmUpSize-1, warmUpSize-1)
+	for _, m := range materials {
+		func() {
+			defer clip.Rect(r).Push(o).Pop()
+			m()
+			paint.PaintOp{}.Add(o)
+		}()
+		func() {
+			defer clip.Ellipse(f32.Rect(0.5, 0.5, warmUpSize-0.5, warmUpSize-0.5)).Push(o).Pop()
+			m()
+			paint.PaintOp{}.Add(o)
+		}()
+		func() {
+			defer clip.Ellipse(f32.Rect(0.5, 0.5, warmUpSize-0.5, warmUpSize-0.5)).Push(o).Pop()
+			defer clip.Ellipse(f32.Rect(2.5, 0.5, warmUpSize-2.5, warmUpSize-0.5)).Push(o).Pop()
+			m()
+			paint.PaintOp{}.Add(o)
+		}()
+	}
+}
+
+func (p program) String() string {
+	names := [...]string{
+		"blit.color", "blit.gradient", "blit.texture",
+		"cover.color", "cover.gradient", "cover.texture",
+		"stencil", "intersect",
+	}
+	var s []string
+	for i, n := range names {
+		if p&(1<<i) != 0 {
+			s = append(s, n)
+		}
+	}
+	return strings.Join(s, ",")
+}
@@ -127,4 +127,9 @@ const (
 	// EXT_disjoint_timer_query
 	TIME_ELAPSED_EXT = 0x88BF
 	GPU_DISJOINT_EXT = 0x8FBB
+
+	// KHR_robustness
+	GUILTY_CONTEXT_RESET   = 0x8253
+	INNOCENT_CONTEXT_RESET = 0x8254
+	UNKNOWN_CONTEXT_RESET  = 0x8255
 )
@@ -88,6 +88,7 @@ type Functions struct {
 	_getVertexAttrib                   js.Value
 	_getVertexAttribOffset             js.Value
 	_invalidateFramebuffer             js.Value
+	_isContextLost                     js.Value
 	_isEnabled                         js.Value
 	_linkProgram                       js.Value
 	_pixelStorei                       js.Value
@@ -183,6 +184,7 @@ func NewFunctions(ctx Context, forceES bool) (*Functions, error) {
 		_getVertexAttrib:                   _bind(webgl, `getVertexAttrib`),
 		_getVertexAttribOffset:             _bind(webgl, `getVertexAttribOffset`),
 		_invalidateFramebuffer:             _bind(webgl, `invalidateFramebuffer`),
+		_isContextLost:                     _bind(webgl, `isContextLost`),
 		_isEnabled:                         _bind(webgl, `isEnabled`),
 		_linkProgram:                       _bind(webgl, `linkProgram`),
 		_pixelStorei:                       _bind(webgl, `pixelStorei`),
@@ -418,6 +420,12 @@ func (f *Functions) GetError() Enum {
 	// Avoid slow getError calls. See gio#179.
 	return 0
 }
+func (f *Functions) GetGraphicsResetStatus() Enum {
+	if f._isContextLost.Invoke().Bool() {
+		return UNKNOWN_CONTEXT_RESET
+	}
+	return NO_ERROR
+}
 func (f *Functions) GetRenderbufferParameteri(target, pname Enum) int {
 	return paramVal(f._getRenderbufferParameteri.Invoke(int(pname)))
 }
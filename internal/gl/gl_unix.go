@@ -114,6 +114,7 @@ typedef struct {
 	GLuint (*glGetUniformBlockIndex)(GLuint program, const GLchar *uniformBlockName);
 	void (*glUniformBlockBinding)(GLuint program, GLuint uniformBlockIndex, GLuint uniformBlockBinding);
 	void (*glInvalidateFramebuffer)(GLenum target, GLsizei numAttachments, const GLenum *attachments);
+	GLenum (*glGetGraphicsResetStatus)(void);
 	void (*glBeginQuery)(GLenum target, GLuint id);
 	void (*glDeleteQueries)(GLsizei n, const GLuint *ids);
 	void (*glDeleteVertexArrays)(GLsizei n, const GLuint *ids);
@@ -452,6 +453,14 @@ static void glInvalidateFramebuffer(glFunctions *f, GLenum target, GLenum attach
 	}
 }
 
+static GLenum glGetGraphicsResetStatus(glFunctions *f) {
+	// Without robustness support, resets are not reported.
+	if (f->glGetGraphicsResetStatus == NULL) {
+		return 0; // GL_NO_ERROR
+	}
+	return f->glGetGraphicsResetStatus();
+}
+
 static void glBeginQuery(glFunctions *f, GLenum target, GLenum attachment) {
 	f->glBeginQuery(target, attachment);
 }
@@ -680,6 +689,14 @@ func (f *Functions) load(forceES bool) error {
 	if f.f.glInvalidateFramebuffer == nil {
 		f.f.glInvalidateFramebuffer = load("glDiscardFramebufferEXT")
 	}
+	f.f.glGetGraphicsResetStatus = load("glGetGraphicsResetStatus")
+	// Fall back to the robustness extensions if available.
+	for _, name := range []string{"glGetGraphicsResetStatusKHR", "glGetGraphicsResetStatusEXT", "glGetGraphicsResetStatusARB"} {
+		if f.f.glGetGraphicsResetStatus != nil {
+			break
+		}
+		f.f.glGetGraphicsResetStatus = load(name)
+	}
 
 	f.f.glBeginQuery = load("glBeginQuery")
 	if f.f.glBeginQuery == nil {
@@ -970,6 +987,10 @@ func (f *Functions) GetError() Enum {
 	return Enum(C.glGetError(&f.f))
 }
 
+func (f *Functions) GetGraphicsResetStatus() Enum {
+	return Enum(C.glGetGraphicsResetStatus(&f.f))
+}
+
 func (f *Functions) GetRenderbufferParameteri(target, pname Enum) int {
 	C.glGetRenderbufferParameteriv(&f.f, C.GLenum(target), C.GLenum(pname), &f.ints[0])
 	return int(f.ints[0])
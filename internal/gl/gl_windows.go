@@ -64,6 +64,7 @@ var (
 	_glFramebufferTexture2D                = LibGLESv2.NewProc("glFramebufferTexture2D")
 	_glGenQueries                          = LibGLESv2.NewProc("glGenQueries")
 	_glGetError                            = LibGLESv2.NewProc("glGetError")
+	_glGetGraphicsResetStatusEXT           = LibGLESv2.NewProc("glGetGraphicsResetStatusEXT")
 	_glGetRenderbufferParameteriv          = LibGLESv2.NewProc("glGetRenderbufferParameteriv")
 	_glGetFloatv                           = LibGLESv2.NewProc("glGetFloatv")
 	_glGetFramebufferAttachmentParameteriv = LibGLESv2.NewProc("glGetFramebufferAttachmentParameteriv")
@@ -317,6 +318,14 @@ func (c *Functions) GetError() Enum {
 	e, _, _ := syscall.Syscall(_glGetError.Addr(), 0, 0, 0, 0)
 	return Enum(e)
 }
+func (c *Functions) GetGraphicsResetStatus() Enum {
+	if _glGetGraphicsResetStatusEXT.Find() != nil {
+		// Without robustness support, resets are not reported.
+		return NO_ERROR
+	}
+	s, _, _ := syscall.Syscall(_glGetGraphicsResetStatusEXT.Addr(), 0, 0, 0, 0)
+	return Enum(s)
+}
 func (c *Functions) GetRenderbufferParameteri(target, pname Enum) int {
 	syscall.Syscall(_glGetRenderbufferParameteriv.Addr(), 3, uintptr(target), uintptr(pname), uintptr(unsafe.Pointer(&c.int32s[0])))
 	return int(c.int32s[0])
@@ -4,8 +4,12 @@ package ops
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"image"
 	"math"
+	"runtime"
+	"strings"
 
 	"gioui.org/f32"
 	"gioui.org/internal/byteslice"
@@ -24,6 +28,8 @@ type Ops struct {
 	nextStateID int
 	// multipOp indicates a multi-op such as clip.Path is being added.
 	multipOp bool
+	// debug enables the tracking of the locations of stack pushes.
+	debug bool
 
 	macroStack stack
 	stacks     [_StackKind]stack
@@ -75,6 +81,18 @@ const (
 	TypeSemanticDisabled
 	TypeSnippet
 	TypeSelection
+	TypeHideLayer
+	TypePointerRegions
+	TypeSemanticLive
+	TypeSemanticAnnounce
+	TypeExpand
+	TypePopExpand
+	TypeTiledImage
+	TypeSize
+	TypeCommit
+	TypeModal
+	TypePopModal
+	TypePointerMask
 )
 
 type StackID struct {
@@ -95,6 +113,14 @@ type StateOp struct {
 type stack struct {
 	currentID int
 	nextID    int
+	// sites are the locations of the open pushes, if tracked.
+	sites []pushSite
+}
+
+// pushSite is the location of a stack push.
+type pushSite struct {
+	id   int
+	site string
 }
 
 type StackKind uint8
@@ -104,15 +130,35 @@ type ClipOp struct {
 	Bounds  image.Rectangle
 	Outline bool
 	Shape   Shape
+	Aliased bool
 }
 
 const (
 	ClipStack StackKind = iota
 	TransStack
 	PassStack
+	ExpandStack
+	ModalStack
 	_StackKind
 )
 
+func (k StackKind) String() string {
+	switch k {
+	case ClipStack:
+		return "clip"
+	case TransStack:
+		return "transform"
+	case PassStack:
+		return "pass"
+	case ExpandStack:
+		return "expand"
+	case ModalStack:
+		return "modal"
+	default:
+		panic("unknown StackKind")
+	}
+}
+
 const (
 	Path Shape = iota
 	Ellipse
@@ -122,30 +168,30 @@ const (
 const (
 	TypeMacroLen            = 1 + 4 + 4
 	TypeCallLen             = 1 + 4 + 4 + 4 + 4
-	TypeDeferLen            = 1
+	TypeDeferLen            = 1 + 4
 	TypePushTransformLen    = 1 + 4*6
 	TypeTransformLen        = 1 + 1 + 4*6
 	TypePopTransformLen     = 1
-	TypeRedrawLen           = 1 + 8
+	TypeRedrawLen           = 1 + 8 + 1
 	TypeImageLen            = 1
 	TypePaintLen            = 1
 	TypeColorLen            = 1 + 4
 	TypeLinearGradientLen   = 1 + 8*2 + 4*2
 	TypePassLen             = 1
 	TypePopPassLen          = 1
-	TypePointerInputLen     = 1 + 1 + 1*2 + 2*4 + 2*4
+	TypePointerInputLen     = 1 + 1 + 1*2 + 2*4 + 2*4 + 4 + 4
 	TypeClipboardReadLen    = 1
 	TypeClipboardWriteLen   = 1
 	TypeSourceLen           = 1
 	TypeTargetLen           = 1
 	TypeOfferLen            = 1
-	TypeKeyInputLen         = 1 + 1
+	TypeKeyInputLen         = 1 + 1 + 4 + 1
 	TypeKeyFocusLen         = 1 + 1
 	TypeKeySoftKeyboardLen  = 1 + 1
 	TypeSaveLen             = 1 + 4
 	TypeLoadLen             = 1 + 4
 	TypeAuxLen              = 1
-	TypeClipLen             = 1 + 4*4 + 1 + 1
+	TypeClipLen             = 1 + 4*4 + 1 + 1 + 1
 	TypePopClipLen          = 1
 	TypeProfileLen          = 1
 	TypeCursorLen           = 2
@@ -158,6 +204,18 @@ const (
 	TypeSemanticDisabledLen = 2
 	TypeSnippetLen          = 1 + 4 + 4
 	TypeSelectionLen        = 1 + 2*4 + 2*4 + 4 + 4
+	TypeHideLayerLen        = 1
+	TypePointerRegionsLen   = 1
+	TypeSemanticLiveLen     = 2
+	TypeSemanticAnnounceLen = 1
+	TypeExpandLen           = 1 + 4 + 4
+	TypePopExpandLen        = 1
+	TypeTiledImageLen       = 1 + 4*2 + 4*2
+	TypeSizeLen             = 1 + 4*2
+	TypeCommitLen           = 1
+	TypeModalLen            = 1
+	TypePopModalLen         = 1
+	TypePointerMaskLen      = 2
 )
 
 func (op *ClipOp) Decode(data []byte) {
@@ -179,6 +237,7 @@ func (op *ClipOp) Decode(data []byte) {
 		Bounds:  r,
 		Outline: data[17] == 1,
 		Shape:   Shape(data[18]),
+		Aliased: data[19] == 1,
 	}
 }
 
@@ -226,7 +285,7 @@ func WriteMulti(o *Ops, n int) []byte {
 }
 
 func PushMacro(o *Ops) StackID {
-	return o.macroStack.push()
+	return o.push(&o.macroStack)
 }
 
 func PopMacro(o *Ops, id StackID) {
@@ -255,7 +314,67 @@ func AddCall(o *Ops, callOps *Ops, pc PC, end PC) {
 }
 
 func PushOp(o *Ops, kind StackKind) (StackID, int) {
-	return o.stacks[kind].push(), o.macroStack.currentID
+	return o.push(&o.stacks[kind]), o.macroStack.currentID
+}
+
+func (o *Ops) push(s *stack) StackID {
+	sid := s.push()
+	if o.debug {
+		s.sites = append(s.sites, pushSite{id: sid.id, site: callSite()})
+	}
+	return sid
+}
+
+// SetDebug enables the tracking of the locations of stack pushes, for
+// reporting by Validate.
+func SetDebug(o *Ops, enable bool) {
+	o.debug = enable
+}
+
+// Validate returns an error describing the stack operations pushed but
+// not popped.
+func Validate(o *Ops) error {
+	var open []string
+	check := func(name, verb string, s *stack) {
+		if s.currentID == 0 {
+			return
+		}
+		var sites []string
+		for _, p := range s.sites {
+			sites = append(sites, p.site)
+		}
+		msg := name + " not " + verb
+		if len(sites) > 0 {
+			msg += " (pushed at " + strings.Join(sites, ", ") + ")"
+		}
+		open = append(open, msg)
+	}
+	for k := range o.stacks {
+		check(StackKind(k).String(), "popped", &o.stacks[k])
+	}
+	check("macro", "stopped", &o.macroStack)
+	if len(open) == 0 {
+		return nil
+	}
+	return errors.New("op: unbalanced operations: " + strings.Join(open, "; "))
+}
+
+// callSite returns the location of the first caller outside the
+// operation packages.
+func callSite() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		fn := f.Function
+		if !strings.HasPrefix(fn, "gioui.org/op.") && !strings.HasPrefix(fn, "gioui.org/op/") && !strings.HasPrefix(fn, "gioui.org/internal/ops.") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return "unknown location"
+		}
+	}
 }
 
 func PopOp(o *Ops, kind StackKind, sid StackID, macroID int) {
@@ -306,6 +425,9 @@ func (s *stack) check(sid StackID) {
 func (s *stack) pop(sid StackID) {
 	s.check(sid)
 	s.currentID = sid.prev
+	if n := len(s.sites); n > 0 && s.sites[n-1].id == sid.id {
+		s.sites = s.sites[:n-1]
+	}
 }
 
 // Save the effective transformation.
@@ -418,14 +540,26 @@ func (t OpType) Size() int {
 		TypeSemanticDisabledLen,
 		TypeSnippetLen,
 		TypeSelectionLen,
+		TypeHideLayerLen,
+		TypePointerRegionsLen,
+		TypeSemanticLiveLen,
+		TypeSemanticAnnounceLen,
+		TypeExpandLen,
+		TypePopExpandLen,
+		TypeTiledImageLen,
+		TypeSizeLen,
+		TypeCommitLen,
+		TypeModalLen,
+		TypePopModalLen,
+		TypePointerMaskLen,
 	}[t-firstOpIndex]
 }
 
 func (t OpType) NumRefs() int {
 	switch t {
-	case TypeKeyInput, TypeKeyFocus, TypePointerInput, TypeProfile, TypeCall, TypeClipboardRead, TypeClipboardWrite, TypeSemanticLabel, TypeSemanticDesc, TypeSelection:
+	case TypeKeyInput, TypeKeyFocus, TypePointerInput, TypeProfile, TypeCall, TypeDefer, TypeClipboardRead, TypeClipboardWrite, TypeSemanticLabel, TypeSemanticDesc, TypeSelection, TypeHideLayer, TypePointerRegions, TypeSemanticLive, TypeSemanticAnnounce, TypeSize, TypeCommit, TypePointerMask:
 		return 1
-	case TypeImage, TypeSource, TypeTarget, TypeSnippet:
+	case TypeImage, TypeSource, TypeTarget, TypeSnippet, TypeTiledImage:
 		return 2
 	case TypeOffer:
 		return 3
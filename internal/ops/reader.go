@@ -8,11 +8,22 @@ import (
 
 // Reader parses an ops list.
 type Reader struct {
-	pc        PC
-	stack     []macro
-	ops       *Ops
-	deferOps  Ops
-	deferDone bool
+	pc       PC
+	stack    []macro
+	ops      *Ops
+	deferOps Ops
+	// deferred are the deferred macros not yet copied to deferOps.
+	deferred []deferredCall
+	// hidden are the names of hidden layers.
+	hidden []string
+}
+
+// deferredCall is a macro call deferred to a layer.
+type deferredCall struct {
+	order int
+	name  *string
+	data  [TypeCallLen]byte
+	ops   interface{}
 }
 
 // EncodedOp represents an encoded op returned by
@@ -69,7 +80,8 @@ func (r *Reader) Reset(ops *Ops) {
 func (r *Reader) ResetAt(ops *Ops, pc PC) {
 	r.stack = r.stack[:0]
 	Reset(&r.deferOps)
-	r.deferDone = false
+	r.deferred = r.deferred[:0]
+	r.hidden = r.hidden[:0]
 	r.pc = pc
 	r.ops = ops
 }
@@ -79,6 +91,7 @@ func (r *Reader) Decode() (EncodedOp, bool) {
 		return EncodedOp{}, false
 	}
 	deferring := false
+	var layer deferredCall
 	for {
 		if len(r.stack) > 0 {
 			b := r.stack[len(r.stack)-1]
@@ -93,13 +106,16 @@ func (r *Reader) Decode() (EncodedOp, bool) {
 		data = data[r.pc.data:]
 		refs := r.ops.refs
 		if len(data) == 0 {
-			if r.deferDone {
+			if len(r.deferred) == 0 {
 				return EncodedOp{}, false
 			}
-			r.deferDone = true
-			// Execute deferred macros.
-			r.ops = &r.deferOps
-			r.pc = PC{}
+			// Execute deferred macros. Macros deferred during their
+			// execution are appended and executed afterwards.
+			r.flushDeferred()
+			if r.ops != &r.deferOps {
+				r.ops = &r.deferOps
+				r.pc = PC{}
+			}
 			continue
 		}
 		key := Key{ops: r.ops, pc: r.pc.data, version: r.ops.version}
@@ -112,6 +128,14 @@ func (r *Reader) Decode() (EncodedOp, bool) {
 		switch t {
 		case TypeDefer:
 			deferring = true
+			bo := binary.LittleEndian
+			layer.order = int(int32(bo.Uint32(data[1:])))
+			layer.name, _ = refs[0].(*string)
+			r.pc.data += n
+			r.pc.refs += nrefs
+			continue
+		case TypeHideLayer:
+			r.hidden = append(r.hidden, *refs[0].(*string))
 			r.pc.data += n
 			r.pc.refs += nrefs
 			continue
@@ -128,8 +152,9 @@ func (r *Reader) Decode() (EncodedOp, bool) {
 				if t.NumRefs() != 1 {
 					panic("internal error: unexpected number of macro refs")
 				}
-				deferData := Write1(&r.deferOps, t.Size(), refs[0])
-				copy(deferData, data)
+				copy(layer.data[:], data)
+				layer.ops = refs[0]
+				r.deferred = append(r.deferred, layer)
 				r.pc.data += n
 				r.pc.refs += nrefs
 				continue
@@ -159,6 +184,35 @@ func (r *Reader) Decode() (EncodedOp, bool) {
 	}
 }
 
+// flushDeferred copies the deferred macros of visible layers to deferOps,
+// sorted by layer order. Macros of equal order keep their relative order.
+func (r *Reader) flushDeferred() {
+	d := r.deferred
+	// Insertion sort is stable, and fast for the few layers expected.
+	for i := 1; i < len(d); i++ {
+		for j := i; j > 0 && d[j].order < d[j-1].order; j-- {
+			d[j], d[j-1] = d[j-1], d[j]
+		}
+	}
+	for i := range d {
+		if d[i].name == nil || !r.isHidden(*d[i].name) {
+			data := Write1(&r.deferOps, TypeCallLen, d[i].ops)
+			copy(data, d[i].data[:])
+		}
+		d[i] = deferredCall{}
+	}
+	r.deferred = d[:0]
+}
+
+func (r *Reader) isHidden(name string) bool {
+	for _, h := range r.hidden {
+		if h == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (op *opMacroDef) decode(data []byte) {
 	if OpType(data[0]) != TypeMacro {
 		panic("invalid op")
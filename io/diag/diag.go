@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package diag reports problems of the rendering and text subsystems to
+the program.
+
+Subsystems such as package gpu and the text shaper describe conditions
+that would otherwise go unnoticed, such as a lost GPU device or a font
+that lacks a glyph, with Diagnostics sent to a Reporter. The Reporter
+forwards them to the Sink registered by the program, or to a rate
+limited logger writing to standard error if no Sink is registered.
+
+Use app.Window.Diagnostics to register a Sink for the diagnostics of a
+window.
+*/
+package diag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Severity of a Diagnostic.
+type Severity uint8
+
+const (
+	// Info describes conditions that are handled, such as a recovered
+	// GPU device.
+	Info Severity = iota
+	// Warning describes conditions that degrade the output, such as the
+	// use of a fallback font.
+	Warning
+	// Error describes failures, such as a lost GPU device.
+	Error
+)
+
+// Diagnostic describes a problem reported by a subsystem.
+type Diagnostic struct {
+	Severity Severity
+	// Source names the reporting subsystem, such as "gpu" or "text".
+	Source string
+	// Message describes the problem.
+	Message string
+	// Once marks conditions that repeat, such as a missing glyph. A
+	// Reporter forwards a Once Diagnostic the first time only.
+	Once bool
+}
+
+// Sink receives Diagnostics. A Sink may be called from any goroutine,
+// but not concurrently by the same Reporter.
+type Sink func(d Diagnostic)
+
+// Reporter forwards Diagnostics to a Sink. The zero value is ready to
+// use and forwards to a logger shared by all Reporters without a Sink.
+// Reporter is safe for concurrent use.
+type Reporter struct {
+	mu   sync.Mutex
+	sink Sink
+	seen map[Diagnostic]struct{}
+}
+
+// defaultSink is the Sink of Reporters without a Sink.
+var defaultSink = NewLogger(os.Stderr, 10, time.Second)
+
+// SetSink replaces the Sink of r. A nil Sink restores the default logger.
+// The Once Diagnostics already reported are forgotten.
+func (r *Reporter) SetSink(s Sink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sink = s
+	r.seen = nil
+}
+
+// Report forwards d to the Sink of r, unless d is a Once Diagnostic
+// already reported. Report is a no-op for a nil Reporter.
+func (r *Reporter) Report(d Diagnostic) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d.Once {
+		if _, seen := r.seen[d]; seen {
+			return
+		}
+		if r.seen == nil {
+			r.seen = make(map[Diagnostic]struct{})
+		}
+		r.seen[d] = struct{}{}
+	}
+	sink := r.sink
+	if sink == nil {
+		sink = defaultSink
+	}
+	sink(d)
+}
+
+// NewLogger returns a Sink that writes Diagnostics to w, one per line.
+// At most limit Diagnostics are written per period; the rest are
+// counted and their number is written with the next Diagnostic that
+// fits the limit.
+func NewLogger(w io.Writer, limit int, period time.Duration) Sink {
+	l := &logger{w: w, limit: limit, period: period, now: time.Now}
+	return l.log
+}
+
+type logger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	limit  int
+	period time.Duration
+	now    func() time.Time
+
+	// start is the start of the current period, and n the number
+	// of Diagnostics written in it.
+	start   time.Time
+	n       int
+	dropped int
+}
+
+func (l *logger) log(d Diagnostic) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now := l.now(); now.Sub(l.start) >= l.period {
+		l.start = now
+		l.n = 0
+	}
+	if l.n >= l.limit {
+		l.dropped++
+		return
+	}
+	l.n++
+	if l.dropped > 0 {
+		fmt.Fprintf(l.w, "gio: %d diagnostics dropped\n", l.dropped)
+		l.dropped = 0
+	}
+	fmt.Fprintf(l.w, "gio: %s: %s: %s\n", d.Source, d.Severity, d.Message)
+}
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		panic("invalid Severity")
+	}
+}
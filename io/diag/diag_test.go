@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package diag
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReporterOnce(t *testing.T) {
+	var got []Diagnostic
+	var r Reporter
+	r.SetSink(func(d Diagnostic) {
+		got = append(got, d)
+	})
+	glyph := Diagnostic{Severity: Warning, Source: "text", Message: "missing glyph", Once: true}
+	lost := Diagnostic{Severity: Error, Source: "gpu", Message: "device lost"}
+	r.Report(glyph)
+	r.Report(lost)
+	r.Report(glyph)
+	r.Report(lost)
+	if want := []Diagnostic{glyph, lost, lost}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+	// A new Sink sees Once Diagnostics again.
+	got = nil
+	r.SetSink(func(d Diagnostic) {
+		got = append(got, d)
+	})
+	r.Report(glyph)
+	if want := []Diagnostic{glyph}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v after SetSink, expected %v", got, want)
+	}
+}
+
+func TestLoggerLimit(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Unix(0, 0)
+	l := &logger{w: &buf, limit: 2, period: time.Second, now: func() time.Time { return now }}
+	d := Diagnostic{Severity: Info, Source: "gpu", Message: "recovered"}
+	for i := 0; i < 5; i++ {
+		l.log(d)
+	}
+	now = now.Add(time.Second)
+	l.log(d)
+	want := "gio: gpu: info: recovered\n" +
+		"gio: gpu: info: recovered\n" +
+		"gio: 3 diagnostics dropped\n" +
+		"gio: gpu: info: recovered\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got log\n%s\nexpected\n%s", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package event
+
+import (
+	"gioui.org/internal/ops"
+	"gioui.org/op"
+)
+
+// ModalOp confines input to the handlers declared between its Push and
+// Pop, such as the handlers of a modal dialog. While a frame contains a
+// ModalOp, the pointer and key handlers declared outside every ModalOp
+// are inert: they receive no pointer and key events, except for pointer
+// cancel events, and can't hold the keyboard focus.
+type ModalOp struct{}
+
+// ModalStack represents a ModalOp on the modal stack.
+type ModalStack struct {
+	ops     *ops.Ops
+	id      ops.StackID
+	macroID int
+}
+
+// Push the ModalOp on the modal stack.
+func (ModalOp) Push(o *op.Ops) ModalStack {
+	id, mid := ops.PushOp(&o.Internal, ops.ModalStack)
+	data := ops.Write(&o.Internal, ops.TypeModalLen)
+	data[0] = byte(ops.TypeModal)
+	return ModalStack{ops: &o.Internal, id: id, macroID: mid}
+}
+
+func (m ModalStack) Pop() {
+	ops.PopOp(m.ops, ops.ModalStack, m.id, m.macroID)
+	data := ops.Write(m.ops, ops.TypePopModalLen)
+	data[0] = byte(ops.TypePopModal)
+}
@@ -27,6 +27,18 @@ import (
 type InputOp struct {
 	Tag  event.Tag
 	Hint InputHint
+	// TabIndex orders the handler in the focus traversal by the Tab
+	// key, like the HTML tabindex attribute. Handlers with positive
+	// indices are visited first by ascending TabIndex, followed by the
+	// handlers with zero indices. Handlers with equal indices are
+	// visited in the order of their InputOps. Tab skips handlers with
+	// negative indices, which can still be focused by FocusOp.
+	TabIndex int
+	// AutoFocus requests the focus for the handler in the first frame
+	// of its InputOp, if no handler has the focus. Only the first such
+	// handler in a frame gains focus, and a FocusOp in the same frame
+	// takes precedence.
+	AutoFocus bool
 }
 
 // SoftKeyboardOp shows or hide the on-screen keyboard, if available.
@@ -213,6 +225,10 @@ func (h InputOp) Add(o *op.Ops) {
 	data := ops.Write1(&o.Internal, ops.TypeKeyInputLen, h.Tag)
 	data[0] = byte(ops.TypeKeyInput)
 	data[1] = byte(h.Hint)
+	binary.LittleEndian.PutUint32(data[2:], uint32(int32(h.TabIndex)))
+	if h.AutoFocus {
+		data[6] = 1
+	}
 }
 
 func (h SoftKeyboardOp) Add(o *op.Ops) {
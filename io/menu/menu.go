@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package menu describes application menus, such as the menu bar of a
+window, and the events of choosing their items.
+
+A program sets the menu bar of a window with app.Window.SetMenuBar. The menu
+bar is displayed by the platform where supported, and choosing an item
+delivers an Event to the Tag of the item through the event queue of the
+window. On other platforms, lay out the same menu bar in the window with
+widget.MenuBar and material.MenuBar.
+
+Portable programs receive the chosen items of both kinds of menu bars
+from widget.MenuBar, whose Update collects the Events of the tags:
+
+	bar.Update(gtx)
+	for _, e := range bar.Events() {
+		...
+	}
+*/
+package menu
+
+import (
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+)
+
+// Menu is a list of items. The items of a menu bar are themselves menus,
+// such as "File" and "Edit", with Submenus.
+type Menu struct {
+	Items []Item
+}
+
+// Item is an item of a Menu. Choosing an item with a Tag delivers an Event
+// to the Tag; choosing an item with a Submenu opens it.
+type Item struct {
+	Title string
+	// Shortcut is the key combination that chooses the item, if any.
+	Shortcut Shortcut
+	Disabled bool
+	// Checkable items display a check mark if Checked is set.
+	Checkable bool
+	Checked   bool
+	Tag       event.Tag
+	Submenu   *Menu
+}
+
+// Shortcut is a key combination.
+type Shortcut struct {
+	Modifiers key.Modifiers
+	// Name of the key, in the format of key.Event.Name.
+	Name string
+}
+
+// Event is delivered to the Tag of a chosen Item.
+type Event struct {
+	Tag event.Tag
+}
+
+// ChangeType is the type of a Change.
+type ChangeType uint8
+
+const (
+	// ChangeItem updates the title, shortcut, tag, enabled and checked
+	// state of an item.
+	ChangeItem ChangeType = iota
+	// ChangeItems replaces the items of a menu.
+	ChangeItems
+)
+
+// Change describes an update of a menu tree by Diff.
+type Change struct {
+	Type ChangeType
+	// Path is the indices of the items leading from the root menu to
+	// the changed item, or to the item of the changed menu for
+	// ChangeItems. An empty Path refers to the root menu.
+	Path []int
+	// Item is the new item for ChangeItem.
+	Item Item
+	// Menu is the new menu for ChangeItems.
+	Menu Menu
+}
+
+// Diff returns the changes that update the menu tree old to new, for
+// platforms that update native menus in place. Changes to the attributes
+// of items are ChangeItem changes. Menus whose number of items change, or whose
+// items gain or lose submenus, are replaced by ChangeItems; the submenus
+// of unchanged menus are compared recursively.
+func Diff(old, new Menu) []Change {
+	return diff(nil, nil, old, new)
+}
+
+func diff(changes []Change, path []int, old, new Menu) []Change {
+	if !sameShape(old, new) {
+		return append(changes, Change{Type: ChangeItems, Path: clonePath(path), Menu: new})
+	}
+	for i, it := range new.Items {
+		o := old.Items[i]
+		p := append(path, i)
+		if !sameAttrs(o, it) {
+			changes = append(changes, Change{Type: ChangeItem, Path: clonePath(p), Item: it})
+		}
+		if it.Submenu != nil {
+			changes = diff(changes, p, *o.Submenu, *it.Submenu)
+		}
+	}
+	return changes
+}
+
+// sameShape reports whether the items of a and b have the same number and
+// placement of submenus.
+func sameShape(a, b Menu) bool {
+	if len(a.Items) != len(b.Items) {
+		return false
+	}
+	for i, it := range a.Items {
+		if (it.Submenu == nil) != (b.Items[i].Submenu == nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameAttrs reports whether items a and b differ in other ways than their
+// submenus.
+func sameAttrs(a, b Item) bool {
+	a.Submenu, b.Submenu = nil, nil
+	return a == b
+}
+
+func clonePath(p []int) []int {
+	return append([]int(nil), p...)
+}
+
+// Clone returns a deep copy of m.
+func (m Menu) Clone() Menu {
+	items := make([]Item, len(m.Items))
+	for i, it := range m.Items {
+		if it.Submenu != nil {
+			sub := it.Submenu.Clone()
+			it.Submenu = &sub
+		}
+		items[i] = it
+	}
+	return Menu{Items: items}
+}
+
+func (Event) ImplementsEvent() {}
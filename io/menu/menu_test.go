@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package menu
+
+import (
+	"reflect"
+	"testing"
+
+	"gioui.org/io/key"
+)
+
+func TestDiff(t *testing.T) {
+	var openTag, wrapTag, saveTag int
+	edit := func(wrap bool) Menu {
+		return Menu{Items: []Item{
+			{Title: "File", Submenu: &Menu{Items: []Item{
+				{Title: "Open", Tag: &openTag, Shortcut: Shortcut{Modifiers: key.ModShortcut, Name: "O"}},
+				{Title: "Save", Tag: &saveTag, Disabled: !wrap},
+			}}},
+			{Title: "View", Submenu: &Menu{Items: []Item{
+				{Title: "Word Wrap", Tag: &wrapTag, Checkable: true, Checked: wrap},
+			}}},
+		}}
+	}
+	old := edit(false)
+	if changes := Diff(old, old.Clone()); len(changes) != 0 {
+		t.Errorf("changes between equal menus: %+v", changes)
+	}
+
+	// The initial menu replaces the empty root menu.
+	changes := Diff(Menu{}, old)
+	if len(changes) != 1 || changes[0].Type != ChangeItems || len(changes[0].Path) != 0 {
+		t.Fatalf("got changes %+v from the empty menu; expected the root menu", changes)
+	}
+
+	// Attribute changes update the items in place.
+	new := edit(true)
+	changes = Diff(old, new)
+	want := []Change{
+		{Type: ChangeItem, Path: []int{0, 1}, Item: new.Items[0].Submenu.Items[1]},
+		{Type: ChangeItem, Path: []int{1, 0}, Item: new.Items[1].Submenu.Items[0]},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got changes %+v; expected %+v", changes, want)
+	}
+
+	// Adding an item replaces only its menu.
+	added := new.Clone()
+	view := added.Items[1].Submenu
+	view.Items = append(view.Items, Item{Title: "Zoom", Submenu: &Menu{}})
+	changes = Diff(new, added)
+	if len(changes) != 1 || changes[0].Type != ChangeItems || !reflect.DeepEqual(changes[0].Path, []int{1}) {
+		t.Errorf("got changes %+v after adding an item; expected the View menu", changes)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := Menu{Items: []Item{{Title: "File", Submenu: &Menu{Items: []Item{{Title: "Open"}}}}}}
+	c := m.Clone()
+	m.Items[0].Submenu.Items[0].Disabled = true
+	if c.Items[0].Submenu.Items[0].Disabled {
+		t.Error("Clone shares submenus")
+	}
+}
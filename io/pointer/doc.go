@@ -77,6 +77,12 @@ the matching repeats with the parent area.
 In the example above, all events will go to h2 because it and h1 are siblings
 and none are pass-through.
 
+The foremost area is the area added last, matching the painting order of
+the operations. Areas of deferred operations and layers are ordered after
+the operations of the frame, so that a floating button laid out through
+op.Defer occludes the list below it. Occluded handlers receive no events,
+even if the handlers above them don't ask for the event type.
+
 Pass-through
 
 The PassOp operations controls the pass-through setting. All handlers added
@@ -87,6 +93,16 @@ the user touches the side, both the (transparent) drawer handle and the
 interface below should receive pointer events. This effect is achieved by
 marking the drawer handle pass-through.
 
+Expanded areas
+
+The ExpandOp operations expand the areas of the clip operations inside
+them to a minimum size, for small widgets that are hard to hit on touch
+screens. An expanded area only receives events outside its real area
+where the real areas of other handlers either don't cover the position
+or are behind the expanded area. The Margin field of InputOp similarly
+expands the area of a single handler. Overlapping expansions resolve to
+the foremost handler.
+
 Disambiguation
 
 When more than one handler matches a pointer event, the event queue
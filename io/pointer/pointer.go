@@ -31,15 +31,34 @@ type Event struct {
 	// timestamp is relative to an undefined base.
 	Time time.Duration
 	// Buttons are the set of pressed mouse buttons for this event.
+	// For pens, ButtonPrimary is set while the tip touches the
+	// display and ButtonEraser while the eraser does, along with
+	// ButtonBarrel while the barrel button is pressed.
 	Buttons Buttons
 	// Position is the position of the event, relative to
 	// the current transformation, as set by op.TransformOp.
+	// Any transformation is supported, including rotations
+	// and shears.
 	Position f32.Point
-	// Scroll is the scroll amount, if any.
+	// Predicted is the position extrapolated from the recent velocity
+	// of the pointer, ahead by the prediction interval set by
+	// router.Router.SetPrediction. Drawing programs can draw ahead
+	// of the latest position to hide input latency. Predicted is
+	// Position if prediction is disabled or the velocity is unknown.
+	// Like Position, it is relative to the current transformation.
+	Predicted f32.Point
+	// Scroll is the scroll amount, if any. Like Position, it is
+	// relative to the current transformation, so a rotated or scaled
+	// handler receives scroll amounts along its own axes.
 	Scroll f32.Point
 	// Modifiers is the set of active modifiers when
 	// the mouse button was pressed.
 	Modifiers key.Modifiers
+	// Region is the index of the region under the pointer, for
+	// handlers of an area divided by a RegionsOp. It is -1 if the
+	// pointer is outside every region. Region is zero for handlers of
+	// other areas.
+	Region int
 }
 
 // PassOp sets the pass-through mode. InputOps added while the pass-through
@@ -54,6 +73,22 @@ type PassStack struct {
 	macroID int
 }
 
+// ExpandOp expands the input areas of clip operations to at least Min,
+// centered on their bounds, without affecting the drawing of the clip
+// operations. The expanded part of an area receives events only where no
+// other handler covers the position with its real area, so expansions
+// lose to overlapping content.
+type ExpandOp struct {
+	Min image.Point
+}
+
+// ExpandStack represents an ExpandOp on the expand stack.
+type ExpandStack struct {
+	ops     *ops.Ops
+	id      ops.StackID
+	macroID int
+}
+
 // InputOp declares an input handler ready for pointer
 // events.
 type InputOp struct {
@@ -69,6 +104,42 @@ type InputOp struct {
 	// ScrollBounds.Min.X <= e.Scroll.X <= ScrollBounds.Max.X (horizontal axis)
 	// ScrollBounds.Min.Y <= e.Scroll.Y <= ScrollBounds.Max.Y (vertical axis)
 	ScrollBounds image.Rectangle
+	// Margin expands the area of the handler by Margin pixels on every
+	// side, like an ExpandOp. The margin is still clipped by the
+	// enclosing clip areas.
+	Margin int
+	// ScrollModifiers, if non-zero, limits the Scroll events of the
+	// handler to those with at least the modifiers in ScrollModifiers,
+	// such as for zooming with key.ModShortcut and the mouse wheel.
+	// Other scroll events pass through to the handlers below.
+	ScrollModifiers key.Modifiers
+}
+
+// RegionsOp divides the current clip area into regions, each described
+// by a closed polygon in the current coordinate space. Only positions
+// inside a region hit the area, and events delivered to the handlers of
+// the area report the region in Event.Region. Later regions are above
+// earlier regions, and positions on the outline of a region are inside
+// it.
+//
+// A RegionsOp is cheaper than a clip area and InputOp per region,
+// because the regions are indexed spatially and the handlers shared.
+// Regions must not be modified until the next frame.
+type RegionsOp struct {
+	Regions [][]f32.Point
+}
+
+// MaskOp limits the current clip area to the pixels of Mask with alpha
+// values above Threshold, such that pointer events on the transparent
+// parts of an irregular image fall through to the areas below. Pixel
+// (x, y) of Mask covers the square from (x, y) to (x+1, y+1) in the
+// current coordinate space, and positions outside the bounds of Mask
+// miss the area.
+//
+// Mask must not be modified until the next frame.
+type MaskOp struct {
+	Mask      image.Image
+	Threshold uint8
 }
 
 type ID uint16
@@ -191,6 +262,11 @@ const (
 	Mouse Source = iota
 	// Touch generated event.
 	Touch
+	// Pen generated event, from a stylus touching or hovering over
+	// the display. A pen in proximity of the display generates Move
+	// events with zero Buttons, and a Leave event when it leaves
+	// proximity. Contact generates Press, Drag and Release events.
+	Pen
 )
 
 const (
@@ -213,6 +289,11 @@ const (
 	ButtonSecondary
 	// ButtonTertiary is the tertiary button, usually the middle button.
 	ButtonTertiary
+	// ButtonBarrel is the barrel button of a pen.
+	ButtonBarrel
+	// ButtonEraser is the eraser end of a pen, set while it touches
+	// the display.
+	ButtonEraser
 )
 
 // frect converts a rectangle to a f32.Rectangle.
@@ -243,6 +324,23 @@ func (p PassStack) Pop() {
 	data[0] = byte(ops.TypePopPass)
 }
 
+// Push the expansion to the expand stack.
+func (e ExpandOp) Push(o *op.Ops) ExpandStack {
+	id, mid := ops.PushOp(&o.Internal, ops.ExpandStack)
+	data := ops.Write(&o.Internal, ops.TypeExpandLen)
+	data[0] = byte(ops.TypeExpand)
+	bo := binary.LittleEndian
+	bo.PutUint32(data[1:], uint32(e.Min.X))
+	bo.PutUint32(data[5:], uint32(e.Min.Y))
+	return ExpandStack{ops: &o.Internal, id: id, macroID: mid}
+}
+
+func (e ExpandStack) Pop() {
+	ops.PopOp(e.ops, ops.ExpandStack, e.id, e.macroID)
+	data := ops.Write(e.ops, ops.TypePopExpandLen)
+	data[0] = byte(ops.TypePopExpand)
+}
+
 func (op Cursor) Add(o *op.Ops) {
 	data := ops.Write(&o.Internal, ops.TypeCursorLen)
 	data[0] = byte(ops.TypeCursor)
@@ -271,6 +369,22 @@ func (op InputOp) Add(o *op.Ops) {
 	bo.PutUint32(data[8:], uint32(op.ScrollBounds.Min.Y))
 	bo.PutUint32(data[12:], uint32(op.ScrollBounds.Max.X))
 	bo.PutUint32(data[16:], uint32(op.ScrollBounds.Max.Y))
+	bo.PutUint32(data[20:], uint32(op.Margin))
+	bo.PutUint32(data[24:], uint32(op.ScrollModifiers))
+}
+
+func (op MaskOp) Add(o *op.Ops) {
+	if op.Mask == nil {
+		panic("Mask must be non-nil")
+	}
+	data := ops.Write1(&o.Internal, ops.TypePointerMaskLen, op.Mask)
+	data[0] = byte(ops.TypePointerMask)
+	data[1] = op.Threshold
+}
+
+func (op RegionsOp) Add(o *op.Ops) {
+	data := ops.Write1(&o.Internal, ops.TypePointerRegionsLen, op.Regions)
+	data[0] = byte(ops.TypePointerRegions)
 }
 
 func (t Type) String() string {
@@ -331,6 +445,8 @@ func (s Source) String() string {
 		return "Mouse"
 	case Touch:
 		return "Touch"
+	case Pen:
+		return "Pen"
 	default:
 		panic("unknown source")
 	}
@@ -353,6 +469,12 @@ func (b Buttons) String() string {
 	if b.Contain(ButtonTertiary) {
 		strs = append(strs, "ButtonTertiary")
 	}
+	if b.Contain(ButtonBarrel) {
+		strs = append(strs, "ButtonBarrel")
+	}
+	if b.Contain(ButtonEraser) {
+		strs = append(strs, "ButtonEraser")
+	}
 	return strings.Join(strs, "|")
 }
 
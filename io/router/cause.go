@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"gioui.org/io/event"
+)
+
+// FrameCause describes a reason for a frame.
+type FrameCause struct {
+	Kind CauseKind
+	// Seq is the sequence number of the queued event, for CauseEvent.
+	Seq uint64
+	// Event is the queued event, for CauseEvent.
+	Event event.Event
+	// Tag is the first handler that received the event, for CauseEvent,
+	// or the pointer handler tag of the area that added the
+	// InvalidateOp, if any, for CauseInvalidate.
+	Tag event.Tag
+	// At is the time of the InvalidateOp, for CauseInvalidate.
+	At time.Time
+	// Size is the new size, for CauseResize.
+	Size image.Point
+}
+
+// CauseKind is the kind of a FrameCause.
+type CauseKind uint8
+
+const (
+	// CauseEvent is a queued event that reached a handler.
+	CauseEvent CauseKind = iota
+	// CauseInvalidate is an InvalidateOp of the previous frame.
+	CauseInvalidate
+	// CauseResize is a change of the window size, reported by
+	// Resize.
+	CauseResize
+)
+
+// EventStamp attributes a delivered event to the queued event it
+// originates from and to the frame that delivered it.
+type EventStamp struct {
+	// Seq is the sequence number of the queued event, or zero for
+	// events generated by the Router, such as focus changes.
+	Seq uint64
+	// Frame is the ID of the frame that delivered the event, that is
+	// the FrameID after the Frame call following the delivery.
+	Frame uint64
+}
+
+// invalidation is an InvalidateOp recorded during collect.
+type invalidation struct {
+	area int
+	at   time.Time
+}
+
+// FrameID returns the ID of the most recent frame. The ID is incremented
+// by every call to Frame, starting from zero before the first frame.
+func (q *Router) FrameID() uint64 {
+	return q.frameID
+}
+
+// FrameCauses returns the causes of the most recent frame: the events
+// queued since the frame before it that reached a handler, the
+// InvalidateOps of the frame before it and the resizes reported since.
+// The causes are in the order they happened, with the InvalidateOps
+// first.
+func (q *Router) FrameCauses() []FrameCause {
+	return q.causes
+}
+
+// EventStamps returns the stamps of the events returned by the most
+// recent call to Events for k during the current frame, in the same
+// order.
+func (q *Router) EventStamps(k event.Tag) []EventStamp {
+	return q.stamps[k]
+}
+
+// Resize reports the size of the next frame, and records a CauseResize
+// for it if the size changed.
+func (q *Router) Resize(sz image.Point) {
+	if sz == q.size {
+		return
+	}
+	q.size = sz
+	q.handlers.pendingCauses = append(q.handlers.pendingCauses, FrameCause{Kind: CauseResize, Size: sz})
+}
+
+// stamp records the stamps of the events delivered to k, from the
+// sequence numbers of their queued events.
+func (q *Router) stamp(k event.Tag, seqs []uint64) {
+	if q.stamps == nil {
+		q.stamps = make(map[event.Tag][]EventStamp)
+	}
+	stamps := q.stamps[k][:0]
+	for _, seq := range seqs {
+		stamps = append(stamps, EventStamp{Seq: seq, Frame: q.frameID + 1})
+	}
+	q.stamps[k] = stamps
+}
+
+// frameCauses finalizes the causes of the current frame.
+func (q *Router) frameCauses() {
+	h := &q.handlers
+	q.causes = append(q.causes[:0], q.invalidates...)
+	q.causes = append(q.causes, h.pendingCauses...)
+	for i := range h.pendingCauses {
+		h.pendingCauses[i] = FrameCause{}
+	}
+	h.pendingCauses = h.pendingCauses[:0]
+	for k, s := range q.stamps {
+		// Forget the tags without events in the previous frame.
+		if len(s) == 0 {
+			delete(q.stamps, k)
+			continue
+		}
+		q.stamps[k] = s[:0]
+	}
+}
+
+// invalidate records an InvalidateOp in the current area.
+func (q *Router) invalidate(at time.Time) {
+	area := q.pointer.collector.currentArea()
+	for i, inv := range q.invalidated {
+		if inv.area == area {
+			if at.Before(inv.at) {
+				q.invalidated[i].at = at
+			}
+			return
+		}
+	}
+	q.invalidated = append(q.invalidated, invalidation{area: area, at: at})
+}
+
+// invalidateCauses converts the recorded InvalidateOps to causes of the
+// next frame, identified by the pointer handler tags of their areas.
+func (q *Router) invalidateCauses() {
+	q.invalidates = q.invalidates[:0]
+	for _, inv := range q.invalidated {
+		var tag event.Tag
+		for a := inv.area; a != -1; a = q.pointer.queue.areas[a].parent {
+			if t := q.pointer.queue.areas[a].semantic.content.tag; t != nil {
+				tag = t
+				break
+			}
+		}
+		q.invalidates = append(q.invalidates, FrameCause{Kind: CauseInvalidate, Tag: tag, At: inv.at})
+	}
+	q.invalidated = q.invalidated[:0]
+}
+
+// addCause records the queued event being added to k as a cause of the
+// next frame.
+func (h *handlerEvents) addCause(k event.Tag) {
+	if h.seq == 0 {
+		return
+	}
+	if n := len(h.pendingCauses); n > 0 && h.pendingCauses[n-1].Seq == h.seq {
+		return
+	}
+	h.pendingCauses = append(h.pendingCauses, FrameCause{Kind: CauseEvent, Seq: h.seq, Event: h.event, Tag: k})
+}
+
+func (c FrameCause) String() string {
+	switch c.Kind {
+	case CauseEvent:
+		return fmt.Sprintf("%T#%d", c.Event, c.Seq)
+	case CauseInvalidate:
+		if c.Tag == nil {
+			return "invalidate"
+		}
+		return fmt.Sprintf("invalidate(%T)", c.Tag)
+	case CauseResize:
+		return fmt.Sprintf("resize(%dx%d)", c.Size.X, c.Size.Y)
+	default:
+		panic("invalid CauseKind")
+	}
+}
+
+// FormatCauses formats causes for profile output, such as
+// "pointer.Event#12 invalidate(*widget.Clickable) resize(800x600)".
+func FormatCauses(causes []FrameCause) string {
+	var b strings.Builder
+	for i, c := range causes {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(c.String())
+	}
+	return b.String()
+}
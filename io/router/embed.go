@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"image"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+)
+
+// Embedded routes the input of an embedded user interface, such as a
+// panel drawn into a texture of a 3D scene, to a child Router. The
+// embedded interface is an independent user interface: it uses Router
+// as its event.Queue, and the program calls Router.Frame with its
+// operations.
+//
+// In every frame of the parent interface, call Update before the layout
+// of the embedded interface, and Add in the area of the parent that
+// receives the input of the embedded interface.
+//
+// The embedded interface owns the keyboard focus from a click inside
+// it until Esc is pressed, the area of Add is clicked outside the
+// embedded interface, or the parent moves its focus elsewhere. Tab
+// presses move the focus of the parent.
+type Embedded struct {
+	// Router routes the events of the embedded interface.
+	Router Router
+	// Transform maps the coordinates of the area of Add to the
+	// coordinates of the embedded interface.
+	Transform f32.Affine2D
+	// Size is the size of the embedded interface. Clicks outside it
+	// leave the embedded interface.
+	Size image.Point
+
+	focused bool
+	// focusChange is set to request or release the parent focus in
+	// the next Add.
+	focusChange bool
+	// reading is set while the embedded interface waits for the
+	// clipboard.
+	reading bool
+	// pos is the most recent pointer position, in the coordinates of
+	// the embedded interface.
+	pos f32.Point
+}
+
+// Focused reports whether the embedded interface owns the keyboard
+// focus.
+func (e *Embedded) Focused() bool {
+	return e.focused
+}
+
+// Update forwards the events of the embedded interface from the parent
+// queue q to Router. Pointer positions are mapped by Transform and
+// pointer IDs are preserved. Key events are forwarded while Focused.
+func (e *Embedded) Update(q event.Queue) {
+	for _, evt := range q.Events(e) {
+		switch evt := evt.(type) {
+		case pointer.Event:
+			e.pointer(evt)
+		case key.FocusEvent:
+			if evt.Focus != e.focused {
+				e.setFocus(evt.Focus)
+			}
+			e.focusChange = false
+		case key.Event:
+			if !e.focused {
+				break
+			}
+			if evt.Name == key.NameEscape {
+				if evt.State == key.Press {
+					e.setFocus(false)
+					e.focusChange = true
+				}
+				break
+			}
+			e.Router.Queue(evt)
+		case key.EditEvent, key.SnippetEvent, key.SelectionEvent:
+			if e.focused {
+				e.Router.Queue(evt)
+			}
+		case clipboard.Event:
+			if e.reading {
+				e.reading = false
+				e.Router.Queue(evt)
+			}
+		}
+	}
+}
+
+func (e *Embedded) pointer(evt pointer.Event) {
+	evt.Position = e.Transform.Transform(evt.Position)
+	e.pos = evt.Position
+	switch evt.Type {
+	case pointer.Press:
+		p := evt.Position
+		in := 0 <= p.X && p.X < float32(e.Size.X) && 0 <= p.Y && p.Y < float32(e.Size.Y)
+		if in != e.focused {
+			e.setFocus(in)
+			e.focusChange = true
+		}
+	case pointer.Drag:
+		// The child Router converts moves of pressed pointers to drags.
+		evt.Type = pointer.Move
+	case pointer.Enter:
+		// The move that follows the enter event enters the areas of
+		// the embedded interface.
+		return
+	case pointer.Scroll:
+		evt.Scroll = e.Transform.Transform(evt.Scroll).Sub(e.Transform.Transform(f32.Point{}))
+	}
+	e.Router.Queue(evt)
+}
+
+// setFocus updates the focus state of the embedded interface, and
+// forwards it to the child Router.
+func (e *Embedded) setFocus(focus bool) {
+	e.focused = focus
+	e.Router.Queue(key.FocusEvent{Focus: focus})
+}
+
+// Add the input handlers of the embedded interface to the current clip
+// area of ops. Add also requests the frames of the embedded interface,
+// and forwards its cursor, keyboard and clipboard requests to the
+// parent. The scroll range of the handler is the range of the embedded
+// handlers under the pointer, so the parent handlers below receive the
+// remaining scroll. Call Add after the Router.Frame of the embedded
+// interface.
+func (e *Embedded) Add(ops *op.Ops) {
+	pointer.InputOp{
+		Tag:          e,
+		Types:        pointer.Press | pointer.Release | pointer.Move | pointer.Drag | pointer.Enter | pointer.Leave | pointer.Scroll | pointer.Cancel,
+		ScrollBounds: e.scrollBounds(),
+	}.Add(ops)
+	key.InputOp{Tag: e, Hint: e.textHint()}.Add(ops)
+	if e.focusChange {
+		e.focusChange = false
+		if e.focused {
+			key.FocusOp{Tag: e}.Add(ops)
+		} else {
+			key.FocusOp{}.Add(ops)
+		}
+	}
+	e.Router.Cursor().Add(ops)
+	switch e.Router.TextInputState() {
+	case TextInputOpen:
+		key.SoftKeyboardOp{Show: true}.Add(ops)
+	case TextInputClose:
+		key.SoftKeyboardOp{Show: false}.Add(ops)
+	}
+	if text, ok := e.Router.WriteClipboard(); ok {
+		clipboard.WriteOp{Text: text}.Add(ops)
+	}
+	if e.Router.ReadClipboard() {
+		e.reading = true
+		clipboard.ReadOp{Tag: e}.Add(ops)
+	}
+	if t, ok := e.Router.WakeupTime(); ok {
+		op.InvalidateOp{At: t}.Add(ops)
+	}
+}
+
+// scrollBounds returns the scroll range of the embedded handlers under
+// the pointer, in the coordinates of the area of Add, rounded outwards.
+func (e *Embedded) scrollBounds() image.Rectangle {
+	r := e.Router.pointer.queue.scrollRange(e.pos)
+	t := e.Transform.Invert()
+	r = f32.Rectangle{Min: transformVec(t, r.Min), Max: transformVec(t, r.Max)}.Canon()
+	return image.Rectangle{
+		Min: image.Pt(int(math.Floor(float64(r.Min.X))), int(math.Floor(float64(r.Min.Y)))),
+		Max: image.Pt(int(math.Ceil(float64(r.Max.X))), int(math.Ceil(float64(r.Max.Y)))),
+	}
+}
+
+func (e *Embedded) textHint() key.InputHint {
+	h, _ := e.Router.TextInputHint()
+	return h
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"image"
+	"math"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+func TestEmbedded(t *testing.T) {
+	var parent Router
+	emb := &Embedded{
+		Size: image.Pt(100, 50),
+		// The child is drawn scaled by 2, rotated by 90 degrees and
+		// offset by (200, 50).
+		Transform: f32.Affine2D{}.
+			Scale(f32.Point{}, f32.Pt(2, 2)).
+			Rotate(f32.Point{}, math.Pi/2).
+			Offset(f32.Pt(200, 50)).
+			Invert(),
+	}
+	// Child point (x, y) is drawn at (200-2y, 50+2x).
+	left, right, parentTag := new(int), new(int), new(int)
+	var childFocus, parentFocus event.Tag
+	var wakeup time.Time
+	var cops, pops op.Ops
+	frame := func() {
+		cops.Reset()
+		for _, h := range []struct {
+			tag  event.Tag
+			area image.Rectangle
+		}{{left, image.Rect(0, 0, 50, 50)}, {right, image.Rect(50, 0, 100, 50)}} {
+			stack := clip.Rect(h.area).Push(&cops)
+			pointer.InputOp{Tag: h.tag, Types: pointer.Press | pointer.Release}.Add(&cops)
+			key.InputOp{Tag: h.tag}.Add(&cops)
+			stack.Pop()
+		}
+		if childFocus != nil {
+			key.FocusOp{Tag: childFocus}.Add(&cops)
+			childFocus = nil
+		}
+		if !wakeup.IsZero() {
+			op.InvalidateOp{At: wakeup}.Add(&cops)
+		}
+		emb.Router.Frame(&cops)
+
+		pops.Reset()
+		stack := clip.Rect(image.Rect(0, 0, 300, 300)).Push(&pops)
+		emb.Add(&pops)
+		stack.Pop()
+		stack = clip.Rect(image.Rect(0, 300, 100, 400)).Push(&pops)
+		key.InputOp{Tag: parentTag}.Add(&pops)
+		stack.Pop()
+		if parentFocus != nil {
+			key.FocusOp{Tag: parentFocus}.Add(&pops)
+			parentFocus = nil
+		}
+		parent.Frame(&pops)
+	}
+	click := func(pos f32.Point) {
+		parent.Queue(
+			pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos},
+			pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: pos},
+		)
+		emb.Update(&parent)
+	}
+	// pressAt returns the position of the press received by tag, if any.
+	pressAt := func(tag event.Tag) (f32.Point, bool) {
+		for _, e := range emb.Router.Events(tag) {
+			if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+				return e.Position, true
+			}
+		}
+		return f32.Point{}, false
+	}
+
+	parentFocus = parentTag
+	frame()
+	assertFocus(t, &parent, parentTag)
+
+	// A click on the left child widget enters the child, and lands
+	// at its transformed position.
+	click(f32.Pt(150, 100))
+	if pos, ok := pressAt(left); !ok || !nearPoint(pos, f32.Pt(25, 25)) {
+		t.Errorf("got left press at %v (%v); expected (25, 25)", pos, ok)
+	}
+	if _, ok := pressAt(right); ok {
+		t.Error("right child widget received the click on the left widget")
+	}
+	if !emb.Focused() {
+		t.Error("click didn't focus the embedded interface")
+	}
+	childFocus = left
+	frame()
+	assertFocus(t, &parent, emb)
+	assertFocus(t, &emb.Router, left)
+
+	// Key events follow the focus into the child.
+	parent.Queue(key.Event{Name: "A", State: key.Press})
+	emb.Update(&parent)
+	assertKeyEvent(t, emb.Router.Events(left), true, key.Event{Name: "A", State: key.Press})
+	frame()
+
+	click(f32.Pt(150, 200))
+	if pos, ok := pressAt(right); !ok || !nearPoint(pos, f32.Pt(75, 25)) {
+		t.Errorf("got right press at %v (%v); expected (75, 25)", pos, ok)
+	}
+	frame()
+
+	// Esc leaves the child.
+	parent.Queue(key.Event{Name: key.NameEscape, State: key.Press})
+	emb.Update(&parent)
+	if emb.Focused() {
+		t.Error("Esc didn't leave the embedded interface")
+	}
+	assertKeyEvent(t, emb.Router.Events(left), false)
+	frame()
+	assertFocus(t, &parent, nil)
+	parent.Queue(key.Event{Name: "B", State: key.Press})
+	emb.Update(&parent)
+	assertNoKeyEvent(t, emb.Router.Events(left))
+	frame()
+
+	// Clicking outside the child leaves it.
+	click(f32.Pt(150, 100))
+	assertKeyEvent(t, emb.Router.Events(left), true)
+	frame()
+	assertFocus(t, &parent, emb)
+	click(f32.Pt(80, 100))
+	if emb.Focused() {
+		t.Error("click outside didn't leave the embedded interface")
+	}
+	assertKeyEvent(t, emb.Router.Events(left), false)
+	frame()
+	assertFocus(t, &parent, nil)
+
+	// Focusing a parent widget leaves the child.
+	click(f32.Pt(150, 100))
+	frame()
+	parentFocus = parentTag
+	frame()
+	assertFocus(t, &parent, parentTag)
+	emb.Update(&parent)
+	if emb.Focused() {
+		t.Error("parent focus didn't leave the embedded interface")
+	}
+	assertKeyEvent(t, emb.Router.Events(left), false)
+
+	// Child wakeups schedule parent frames.
+	wakeup = time.Now().Add(time.Second)
+	frame()
+	frame()
+	if got, ok := parent.WakeupTime(); !ok || !got.Equal(wakeup) {
+		t.Errorf("got parent wakeup %v (%v); expected %v", got, ok, wakeup)
+	}
+}
+
+func TestEmbeddedScroll(t *testing.T) {
+	var parent Router
+	emb := &Embedded{
+		Size: image.Pt(100, 100),
+		// The child is drawn scaled by 2.
+		Transform: f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(.5, .5)),
+	}
+	child, below := new(int), new(int)
+	var cops, pops op.Ops
+	frame := func() {
+		cops.Reset()
+		stack := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&cops)
+		pointer.InputOp{Tag: child, Types: pointer.Scroll, ScrollBounds: image.Rect(0, -10, 0, 20)}.Add(&cops)
+		stack.Pop()
+		emb.Router.Frame(&cops)
+
+		pops.Reset()
+		stack = clip.Rect(image.Rect(0, 0, 200, 200)).Push(&pops)
+		pointer.InputOp{Tag: below, Types: pointer.Scroll, ScrollBounds: image.Rect(0, -1000, 0, 1000)}.Add(&pops)
+		emb.Add(&pops)
+		stack.Pop()
+		parent.Frame(&pops)
+	}
+	// scrolled returns the sum of the scroll events received by tag.
+	scrolled := func(q event.Queue, tag event.Tag) f32.Point {
+		var sum f32.Point
+		for _, e := range q.Events(tag) {
+			if e, ok := e.(pointer.Event); ok && e.Type == pointer.Scroll {
+				sum = sum.Add(e.Scroll)
+			}
+		}
+		return sum
+	}
+	frame()
+	parent.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(50, 50)})
+	emb.Update(&parent)
+	frame()
+
+	// The embedded interface takes the range of the child handler,
+	// and the rest of the scroll reaches the parent handler below.
+	parent.Queue(pointer.Event{Type: pointer.Scroll, Source: pointer.Mouse, Position: f32.Pt(50, 50), Scroll: f32.Pt(0, 100)})
+	emb.Update(&parent)
+	if got, exp := scrolled(&emb.Router, child), f32.Pt(0, 20); !nearPoint(got, exp) {
+		t.Errorf("child scrolled %v; expected %v", got, exp)
+	}
+	if got, exp := scrolled(&parent, below), f32.Pt(0, 60); !nearPoint(got, exp) {
+		t.Errorf("parent handler scrolled %v; expected %v", got, exp)
+	}
+}
+
+func nearPoint(p, q f32.Point) bool {
+	d := p.Sub(q)
+	return d.X*d.X+d.Y*d.Y < 1e-3
+}
@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/internal/ops"
+	"gioui.org/io/diag"
+	"gioui.org/io/system"
+)
+
+// idleDetector finds the areas that invalidate every frame without
+// changing, such as a widget that unconditionally adds an InvalidateOp.
+// An area is idle if its operations are identical to the previous
+// frame, and no events were queued since.
+type idleDetector struct {
+	diag  *diag.Reporter
+	after time.Duration
+	now   func() time.Time
+
+	// hashes of the operations of the areas of the current frame,
+	// including the operations of their descendants.
+	hashes []uint64
+	// invalidated lists the areas with immediate InvalidateOps in the
+	// current frame.
+	invalidated []int
+	// scopes tracks the invalidated areas of the previous frame.
+	scopes map[int]idleScope
+	// input is set when events are queued.
+	input bool
+	// ids identify the references of the operations of the current
+	// frame, and prevIDs the references of the previous frame.
+	ids, prevIDs map[interface{}]uint64
+	nextID       uint64
+}
+
+// idleScope tracks an area that invalidated every frame since, with
+// identical operations.
+type idleScope struct {
+	hash     uint64
+	since    time.Time
+	frames   int
+	reported bool
+}
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// DetectIdleInvalidates enables the detection of areas that invalidate
+// frames unnecessarily. A Warning is reported to r when an area issues
+// immediate InvalidateOps for longer than d while its operations stay
+// identical and no events are queued. Animating content, whose
+// operations differ between frames, is never reported. Areas are
+// identified by their semantic label or description, or the type of
+// their pointer handler tag. A nil Reporter disables detection.
+//
+// References such as images and handler tags are compared by identity.
+// An image changed in place must be added through a new paint.ImageOp
+// from paint.NewImageOp to count as a change, as for drawing.
+//
+// Detection hashes the operations of every frame, and is intended for
+// debugging.
+func (q *Router) DetectIdleInvalidates(r *diag.Reporter, d time.Duration) {
+	q.idle = idleDetector{diag: r, after: d, now: q.idle.now}
+}
+
+func (d *idleDetector) enabled() bool {
+	return d.diag != nil
+}
+
+func (d *idleDetector) reset() {
+	d.hashes = d.hashes[:0]
+	d.invalidated = d.invalidated[:0]
+	// Forget the references missing from the previous frame.
+	d.ids, d.prevIDs = d.prevIDs, d.ids
+	for r := range d.ids {
+		delete(d.ids, r)
+	}
+}
+
+// refID returns the identity of r. References of consecutive frames keep
+// their identity, and new references never reuse an identity.
+func (d *idleDetector) refID(r interface{}) uint64 {
+	if t := reflect.TypeOf(r); t != nil && !t.Comparable() {
+		// Assume that references without identity, such as slices,
+		// change every frame.
+		d.nextID++
+		return d.nextID
+	}
+	if id, ok := d.ids[r]; ok {
+		return id
+	}
+	id, ok := d.prevIDs[r]
+	if !ok {
+		d.nextID++
+		id = d.nextID
+	}
+	if d.ids == nil {
+		d.ids = make(map[interface{}]uint64)
+	}
+	d.ids[r] = id
+	return id
+}
+
+// hashOp adds the operation to the hashes of the current area and its
+// ancestors.
+func (d *idleDetector) hashOp(pc *pointerCollector, op ops.EncodedOp) {
+	area := pc.currentArea()
+	if area == -1 {
+		return
+	}
+	h := uint64(fnvOffset)
+	add := func(b []byte) {
+		for _, c := range b {
+			h ^= uint64(c)
+			h *= fnvPrime
+		}
+	}
+	addWord := func(w uint64) {
+		for i := 0; i < 8; i++ {
+			h ^= w & 0xff
+			h *= fnvPrime
+			w >>= 8
+		}
+	}
+	add(op.Data)
+	for _, r := range op.Refs {
+		switch r := r.(type) {
+		case string:
+			add([]byte(r))
+		case *string:
+			add([]byte(*r))
+		case [][]f32.Point:
+			// The regions of a RegionsOp.
+			for _, reg := range r {
+				addWord(uint64(len(reg)))
+				for _, p := range reg {
+					addWord(uint64(math.Float32bits(p.X))<<32 | uint64(math.Float32bits(p.Y)))
+				}
+			}
+		default:
+			addWord(d.refID(r))
+		}
+	}
+	for len(d.hashes) < len(pc.q.areas) {
+		d.hashes = append(d.hashes, fnvOffset)
+	}
+	for a := area; a != -1; a = pc.q.areas[a].parent {
+		d.hashes[a] = (d.hashes[a] ^ h) * fnvPrime
+	}
+}
+
+// invalidate records an immediate InvalidateOp in the current area.
+func (d *idleDetector) invalidate(pc *pointerCollector) {
+	area := pc.currentArea()
+	if area == -1 {
+		return
+	}
+	for _, a := range d.invalidated {
+		if a == area {
+			return
+		}
+	}
+	d.invalidated = append(d.invalidated, area)
+}
+
+func (d *idleDetector) time() time.Time {
+	if d.now != nil {
+		return d.now()
+	}
+	return system.RealClock.Now()
+}
+
+// frame compares the invalidated areas with the previous frame and
+// reports the areas idle for too long.
+func (d *idleDetector) frame(q *pointerQueue, now time.Time) {
+	prev := d.scopes
+	d.scopes = make(map[int]idleScope, len(d.invalidated))
+	for _, a := range d.invalidated {
+		var h uint64 = fnvOffset
+		if a < len(d.hashes) {
+			h = d.hashes[a]
+		}
+		s, ok := prev[a]
+		if !ok || s.hash != h || d.input {
+			s = idleScope{hash: h, since: now}
+		}
+		s.frames++
+		if !s.reported && now.Sub(s.since) >= d.after {
+			s.reported = true
+			d.diag.Report(diag.Diagnostic{
+				Severity: diag.Warning,
+				Source:   "router",
+				Message: fmt.Sprintf("%s invalidated %d identical frames in %v without input",
+					idleName(q, a), s.frames, now.Sub(s.since).Round(time.Millisecond)),
+			})
+		}
+		d.scopes[a] = s
+	}
+	d.input = false
+}
+
+// idleName identifies an area by its nearest semantic label or
+// description, or pointer handler tag.
+func idleName(q *pointerQueue, area int) string {
+	for a := area; a != -1; a = q.areas[a].parent {
+		c := q.areas[a].semantic.content
+		switch {
+		case c.label != "":
+			return fmt.Sprintf("area %q", c.label)
+		case c.desc != "":
+			return fmt.Sprintf("area %q", c.desc)
+		case c.tag != nil:
+			return fmt.Sprintf("area of %T", c.tag)
+		}
+	}
+	return "frame"
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"gioui.org/io/diag"
+	"gioui.org/io/key"
+	"gioui.org/io/semantic"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+)
+
+func TestIdleInvalidates(t *testing.T) {
+	var (
+		ops   op.Ops
+		r     Router
+		rep   diag.Reporter
+		diags []diag.Diagnostic
+	)
+	rep.SetSink(func(d diag.Diagnostic) {
+		diags = append(diags, d)
+	})
+	now := time.Unix(0, 0)
+	r.idle.now = func() time.Time { return now }
+	r.DetectIdleInvalidates(&rep, time.Second)
+	frames := [2]paint.ImageOp{
+		paint.NewImageOp(image.NewRGBA(image.Rect(0, 0, 1, 1))),
+		paint.NewImageOp(image.NewRGBA(image.Rect(0, 0, 1, 1))),
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	frame := func(i int) {
+		ops.Reset()
+		// An animation that changes every frame.
+		area := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+		semantic.LabelOp("spinner").Add(&ops)
+		paint.ColorOp{Color: color.NRGBA{R: uint8(i), A: 0xff}}.Add(&ops)
+		paint.PaintOp{}.Add(&ops)
+		op.InvalidateOp{}.Add(&ops)
+		area.Pop()
+		// A constant widget that invalidates anyway.
+		area = clip.Rect(image.Rect(100, 0, 200, 100)).Push(&ops)
+		semantic.LabelOp("stuck").Add(&ops)
+		paint.ColorOp{Color: color.NRGBA{B: 0xff, A: 0xff}}.Add(&ops)
+		paint.PaintOp{}.Add(&ops)
+		op.InvalidateOp{}.Add(&ops)
+		area.Pop()
+		// An animation that alternates between images.
+		area = clip.Rect(image.Rect(0, 100, 100, 200)).Push(&ops)
+		semantic.LabelOp("flipbook").Add(&ops)
+		frames[i%2].Add(&ops)
+		paint.PaintOp{}.Add(&ops)
+		op.InvalidateOp{}.Add(&ops)
+		area.Pop()
+		// An animation that draws into the same image.
+		area = clip.Rect(image.Rect(100, 100, 200, 200)).Push(&ops)
+		semantic.LabelOp("canvas").Add(&ops)
+		canvas.Pix[0] = uint8(i)
+		paint.NewImageOp(canvas).Add(&ops)
+		paint.PaintOp{}.Add(&ops)
+		op.InvalidateOp{}.Add(&ops)
+		area.Pop()
+		// A constant image that invalidates anyway.
+		area = clip.Rect(image.Rect(200, 100, 300, 200)).Push(&ops)
+		semantic.LabelOp("still").Add(&ops)
+		frames[0].Add(&ops)
+		paint.PaintOp{}.Add(&ops)
+		op.InvalidateOp{}.Add(&ops)
+		area.Pop()
+		// A constant widget with a scheduled redraw.
+		area = clip.Rect(image.Rect(200, 0, 300, 100)).Push(&ops)
+		semantic.LabelOp("caret").Add(&ops)
+		op.InvalidateOp{At: now.Add(500 * time.Millisecond)}.Add(&ops)
+		area.Pop()
+		r.Frame(&ops)
+	}
+	for i := 0; i < 10; i++ {
+		frame(i)
+		now = now.Add(100 * time.Millisecond)
+		// Input resets the detection.
+		r.Queue(key.Event{Name: "A"})
+	}
+	if len(diags) > 0 {
+		t.Fatalf("reported %v despite input", diags)
+	}
+	for i := 0; i < 30; i++ {
+		frame(i)
+		now = now.Add(100 * time.Millisecond)
+	}
+	if len(diags) != 2 {
+		t.Fatalf("got diagnostics %v, expected 2", diags)
+	}
+	for i, label := range []string{`"stuck"`, `"still"`} {
+		d := diags[i]
+		if d.Severity != diag.Warning || d.Source != "router" || !strings.Contains(d.Message, label) {
+			t.Errorf("unexpected diagnostic %+v, expected %s", d, label)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package router
 
 import (
+	"image"
 	"math"
 	"sort"
 
@@ -19,6 +20,20 @@ type EditorState struct {
 		key.Caret
 	}
 	Snippet key.Snippet
+	// Area is the bounds of the area of the focused handler, in
+	// window coordinates. Platforms keep it visible while showing
+	// a soft keyboard.
+	Area f32.Rectangle
+}
+
+// CaretRect returns the bounds of the caret in window coordinates, for
+// placing input method windows such that they don't cover the caret.
+func (s EditorState) CaretRect() f32.Rectangle {
+	sel := s.Selection
+	return f32.Rectangle{
+		Min: sel.Transform.Transform(sel.Pos.Sub(f32.Pt(0, sel.Ascent))),
+		Max: sel.Transform.Transform(sel.Pos.Add(f32.Pt(0, sel.Descent))),
+	}.Canon()
 }
 
 type TextInputState uint8
@@ -28,9 +43,34 @@ type keyQueue struct {
 	order    []event.Tag
 	dirOrder []dirFocusEntry
 	handlers map[event.Tag]*keyHandler
-	state    TextInputState
-	hint     key.InputHint
-	content  EditorState
+	// unfocused tracks the tags that lost focus without a
+	// FocusEvent, because their handler disappeared while focused. The
+	// tags are kept until their handler re-appears, or until
+	// Router.Forget.
+	unfocused map[event.Tag]struct{}
+	state     TextInputState
+	hint      key.InputHint
+	content   EditorState
+	// pressed tracks the keys held down, for Router.AppendHeldKeys.
+	pressed []key.Event
+	// modifiers is the modifier state of the most recent key event.
+	modifiers key.Modifiers
+	// modal are the handlers that may hold the focus while the frame
+	// has ModalOps.
+	modal modalTags
+	// sorter is scratch space for sorting dirOrder without
+	// allocating.
+	sorter dirFocusSorter
+	// tabSorter is scratch space for sorting order by tab index.
+	tabSorter tabOrderSorter
+	// distWeight is the weight of the distance along the direction of
+	// vertical focus moves, relative to the horizontal misalignment.
+	distWeight float32
+	// focusSent tracks the Focus of the most recent FocusEvent of each
+	// tag, for coalescing repeated focus events. The coalescing is
+	// disabled by keepDuplicateFocus.
+	focusSent          map[event.Tag]bool
+	keepDuplicateFocus bool
 }
 
 type keyHandler struct {
@@ -39,8 +79,11 @@ type keyHandler struct {
 	visible  bool
 	new      bool
 	hint     key.InputHint
+	tabIndex int
 	order    int
 	dirOrder int
+	// area is the index of the pointer area of the handler.
+	area int
 }
 
 // keyCollector tracks state required to update a keyQueue
@@ -49,6 +92,8 @@ type keyCollector struct {
 	q       *keyQueue
 	focus   event.Tag
 	changed bool
+	// autoFocus is the first new handler requesting focus.
+	autoFocus event.Tag
 }
 
 type dirFocusEntry struct {
@@ -57,6 +102,20 @@ type dirFocusEntry struct {
 	bounds f32.Rectangle
 }
 
+// dirFocusSorter implements sort.Interface for ordering
+// dirFocusEntry by their vertical or horizontal position.
+type dirFocusSorter struct {
+	entries []dirFocusEntry
+	byX     bool
+}
+
+// tabOrderSorter implements sort.Interface for ordering tags by the
+// tab index of their handlers.
+type tabOrderSorter struct {
+	tags     []event.Tag
+	handlers map[event.Tag]*keyHandler
+}
+
 const (
 	TextInputKeep TextInputState = iota
 	TextInputClose
@@ -111,20 +170,85 @@ func (q *keyQueue) Frame(events *handlerEvents, collector keyCollector) {
 	for k, h := range q.handlers {
 		if !h.visible {
 			delete(q.handlers, k)
+			if q.focus != k {
+				// The tag is unfocused, or lost its focus while
+				// invisible.
+				if _, ok := q.unfocused[k]; !ok {
+					delete(q.focusSent, k)
+				}
+			}
 			if q.focus == k {
 				// Remove focus from the handler that is no longer visible.
 				q.focus = nil
 				q.state = TextInputClose
+				if q.unfocused == nil {
+					q.unfocused = make(map[event.Tag]struct{})
+				}
+				q.unfocused[k] = struct{}{}
+			}
+		} else if h.new {
+			// Reset a handler that lost its focus while invisible, but don't
+			// trigger redraw. Handlers that never had focus are already
+			// unfocused.
+			if _, ok := q.unfocused[k]; ok {
+				delete(q.unfocused, k)
+				if k != focus {
+					q.addFocus(events, k, key.FocusEvent{Focus: false}, false)
+				}
 			}
-		} else if h.new && k != focus {
-			// Reset the handler on (each) first appearance, but don't trigger redraw.
-			events.AddNoRedraw(k, key.FocusEvent{Focus: false})
 		}
 	}
-	if changed {
+	switch {
+	case changed:
 		q.setFocus(focus, events)
+	case q.focus == nil && collector.autoFocus != nil:
+		q.setFocus(collector.autoFocus, events)
 	}
+	if q.focus != nil && q.modal.inert(q.focus) {
+		// A ModalOp appeared outside the focused handler.
+		q.setFocus(nil, events)
+	}
+	q.updateTabOrder()
 	q.updateFocusLayout()
+	q.content.Area = f32.Rectangle{}
+	if _, ok := q.handlers[q.focus]; ok {
+		q.content.Area = q.BoundsFor(q.focus)
+	}
+}
+
+// Forget the focus state of tag.
+func (q *keyQueue) Forget(tag event.Tag) {
+	delete(q.unfocused, tag)
+	if _, ok := q.handlers[tag]; !ok {
+		delete(q.focusSent, tag)
+	}
+}
+
+// skipTab reports whether Tab skips the handler of tag.
+func (q *keyQueue) skipTab(tag event.Tag) bool {
+	return q.modal.inert(tag) || q.handlers[tag].tabIndex < 0
+}
+
+// updateTabOrder sorts the handlers with positive tab indices by
+// ascending index before the other handlers, keeping the op order for
+// equal indices.
+func (q *keyQueue) updateTabOrder() {
+	indexed := false
+	for _, tag := range q.order {
+		if q.handlers[tag].tabIndex != 0 {
+			indexed = true
+			break
+		}
+	}
+	if !indexed {
+		return
+	}
+	q.tabSorter = tabOrderSorter{tags: q.order, handlers: q.handlers}
+	sort.Stable(&q.tabSorter)
+	q.tabSorter = tabOrderSorter{}
+	for i, tag := range q.order {
+		q.handlers[tag].order = i
+	}
 }
 
 // updateFocusLayout partitions input handlers handlers into rows
@@ -137,9 +261,8 @@ func (q *keyQueue) Frame(events *handlerEvents, collector keyCollector) {
 func (q *keyQueue) updateFocusLayout() {
 	order := q.dirOrder
 	// Sort by ascending y position.
-	sort.SliceStable(order, func(i, j int) bool {
-		return order[i].bounds.Min.Y < order[j].bounds.Min.Y
-	})
+	q.sorter = dirFocusSorter{entries: order}
+	sort.Stable(&q.sorter)
 	row := 0
 	for len(order) > 0 {
 		h := &order[0]
@@ -155,18 +278,63 @@ func (q *keyQueue) updateFocusLayout() {
 			h.row = row
 		}
 		// Sort row by ascending x position.
-		sort.SliceStable(order[:end], func(i, j int) bool {
-			return order[i].bounds.Min.X < order[j].bounds.Min.X
-		})
+		q.sorter = dirFocusSorter{entries: order[:end], byX: true}
+		sort.Stable(&q.sorter)
 		order = order[end:]
 		row++
 	}
+	q.sorter = dirFocusSorter{}
 	for i, o := range q.dirOrder {
 		q.handlers[o.tag].dirOrder = i
 	}
 }
 
+func (s *tabOrderSorter) Len() int {
+	return len(s.tags)
+}
+
+func (s *tabOrderSorter) Less(i, j int) bool {
+	// Positive indices come first, followed by the other indices in op
+	// order.
+	a, b := s.handlers[s.tags[i]].tabIndex, s.handlers[s.tags[j]].tabIndex
+	if a > 0 && b > 0 {
+		return a < b
+	}
+	return a > 0 && b <= 0
+}
+
+func (s *tabOrderSorter) Swap(i, j int) {
+	s.tags[i], s.tags[j] = s.tags[j], s.tags[i]
+}
+
+func (s *dirFocusSorter) Len() int {
+	return len(s.entries)
+}
+
+func (s *dirFocusSorter) Less(i, j int) bool {
+	bi, bj := s.entries[i].bounds, s.entries[j].bounds
+	if s.byX {
+		return bi.Min.X < bj.Min.X
+	}
+	return bi.Min.Y < bj.Min.Y
+}
+
+func (s *dirFocusSorter) Swap(i, j int) {
+	s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+}
+
 func (q *keyQueue) MoveFocus(dir FocusDirection, events *handlerEvents) {
+	if next := q.nextFocus(dir); next != nil {
+		q.setFocus(next, events)
+	}
+}
+
+// nextFocus returns the handler a focus move in the direction dir
+// selects, or nil if focus doesn't move.
+func (q *keyQueue) nextFocus(dir FocusDirection) event.Tag {
+	if len(q.dirOrder) == 0 {
+		return nil
+	}
 	order := 0
 	if q.focus != nil {
 		order = q.handlers[q.focus].dirOrder
@@ -184,7 +352,7 @@ func (q *keyQueue) MoveFocus(dir FocusDirection, events *handlerEvents) {
 		if 0 <= next && next < len(q.dirOrder) {
 			newFocus := q.dirOrder[next]
 			if newFocus.row == focus.row {
-				q.setFocus(newFocus.tag, events)
+				return newFocus.tag
 			}
 		}
 	case FocusUp, FocusDown:
@@ -198,31 +366,40 @@ func (q *keyQueue) MoveFocus(dir FocusDirection, events *handlerEvents) {
 		}
 		var closest event.Tag
 		dist := float32(math.Inf(+1))
-		center := (focus.bounds.Min.X + focus.bounds.Max.X) * .5
-	loop:
-		for 0 <= order && order < len(q.dirOrder) {
+		center := focus.bounds.Min.Add(focus.bounds.Max).Mul(.5)
+		for ; 0 <= order && order < len(q.dirOrder); order += delta {
 			next := q.dirOrder[order]
-			switch next.row {
-			case nextRow:
-				nextCenter := (next.bounds.Min.X + next.bounds.Max.X) * .5
-				d := center - nextCenter
-				if d < 0 {
-					d = -d
-				}
-				if d > dist {
-					break loop
-				}
-				dist = d
+			if next.row == nextRow+delta {
+				break
+			}
+			if next.row != nextRow {
+				continue
+			}
+			// Weigh the horizontal misalignment against the vertical
+			// distance.
+			d := next.bounds.Min.Add(next.bounds.Max).Mul(.5).Sub(center)
+			score := abs(d.X) + q.distWeight*abs(d.Y)
+			if score <= dist {
+				dist = score
 				closest = next.tag
-			case nextRow + delta:
-				break loop
 			}
-			order += delta
-		}
-		if closest != nil {
-			q.setFocus(closest, events)
 		}
+		return closest
+	}
+	return nil
+}
+
+// SetDistanceWeight sets the weight of the distance of vertical
+// focus moves.
+func (q *keyQueue) SetDistanceWeight(w float32) {
+	q.distWeight = w
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
 	}
+	return v
 }
 
 func (q *keyQueue) Push(e event.Event, events *handlerEvents) {
@@ -245,14 +422,135 @@ func (q *keyQueue) Push(e event.Event, events *handlerEvents) {
 			}
 		}
 		order = (order + len(q.order)) % len(q.order)
+		// Skip the handlers outside ModalOps, and the handlers with
+		// negative tab indices.
+		for i := 0; q.skipTab(q.order[order]); i++ {
+			if i == len(q.order)-1 {
+				return
+			}
+			if forward {
+				order++
+			} else {
+				order--
+			}
+			order = (order + len(q.order)) % len(q.order)
+		}
 		q.setFocus(q.order[order], events)
 		return
 	}
+	switch e := e.(type) {
+	case key.Event:
+		q.trackKey(e)
+	case key.FocusEvent:
+		// Keys released while the window is unfocused are not reported.
+		if !e.Focus {
+			q.pressed = q.pressed[:0]
+			q.modifiers = 0
+		}
+		if q.focus != nil {
+			q.addFocus(events, q.focus, e, true)
+		}
+		return
+	}
 	if q.focus != nil {
 		events.Add(q.focus, e)
 	}
 }
 
+// addFocus adds the focus event e for k, unless the most recent focus
+// event of k has the same Focus. The event triggers a redraw if redraw
+// is set.
+func (q *keyQueue) addFocus(events *handlerEvents, k event.Tag, e key.FocusEvent, redraw bool) {
+	if !q.keepDuplicateFocus {
+		if f, ok := q.focusSent[k]; ok && f == e.Focus {
+			return
+		}
+		if q.focusSent == nil {
+			q.focusSent = make(map[event.Tag]bool)
+		}
+		q.focusSent[k] = e.Focus
+	}
+	if redraw {
+		events.Add(k, e)
+	} else {
+		events.AddNoRedraw(k, e)
+	}
+}
+
+// trackKey updates the held keys with e.
+func (q *keyQueue) trackKey(e key.Event) {
+	for i, p := range q.pressed {
+		if p.Name == e.Name {
+			q.pressed = append(q.pressed[:i], q.pressed[i+1:]...)
+			break
+		}
+	}
+	if e.State == key.Press {
+		q.pressed = append(q.pressed, e)
+	}
+	q.reconcileModifiers(e)
+}
+
+// reconcileModifiers updates the modifier state from the Modifiers of e,
+// which reflect the current state. Held modifier keys missing from the
+// state lost their releases, for example to a window switch, and are
+// released. Platforms may or may not include the modifier of a modifier
+// key in its own events, so e.Name is tracked by e.State instead.
+func (q *keyQueue) reconcileModifiers(e key.Event) {
+	mods := e.Modifiers
+	if m := modifierOf(e.Name); m != 0 {
+		if e.State == key.Press {
+			mods |= m
+		} else {
+			mods &^= m
+		}
+	}
+	q.modifiers = mods
+	n := 0
+	for _, p := range q.pressed {
+		if m := modifierOf(p.Name); m != 0 && !mods.Contain(m) {
+			continue
+		}
+		q.pressed[n] = p
+		n++
+	}
+	q.pressed = q.pressed[:n]
+}
+
+// modifierOf returns the modifier of a modifier key, or 0.
+func modifierOf(name string) key.Modifiers {
+	switch name {
+	case key.NameCtrl:
+		return key.ModCtrl
+	case key.NameShift:
+		return key.ModShift
+	case key.NameAlt:
+		return key.ModAlt
+	case key.NameSuper:
+		return key.ModSuper
+	}
+	return 0
+}
+
+// FocusableBounds returns the bounds of the visible handlers,
+// rounded outwards to integer coordinates.
+func (q *keyQueue) FocusableBounds() map[event.Tag]image.Rectangle {
+	bounds := make(map[event.Tag]image.Rectangle, len(q.dirOrder))
+	for _, e := range q.dirOrder {
+		b := e.bounds
+		bounds[e.tag] = image.Rect(
+			int(math.Floor(float64(b.Min.X))), int(math.Floor(float64(b.Min.Y))),
+			int(math.Ceil(float64(b.Max.X))), int(math.Ceil(float64(b.Max.Y))),
+		)
+	}
+	return bounds
+}
+
+// AreaFor returns the index of the pointer area of the handler of t.
+func (q *keyQueue) AreaFor(t event.Tag) int {
+	return q.handlers[t].area
+}
+
 func (q *keyQueue) BoundsFor(t event.Tag) f32.Rectangle {
 	order := q.handlers[t].dirOrder
 	return q.dirOrder[order].bounds
@@ -260,6 +558,10 @@ func (q *keyQueue) BoundsFor(t event.Tag) f32.Rectangle {
 
 func (q *keyQueue) setFocus(focus event.Tag, events *handlerEvents) {
 	if focus != nil {
+		if q.modal.inert(focus) {
+			// Handlers outside ModalOps can't take the focus.
+			return
+		}
 		if _, exists := q.handlers[focus]; !exists {
 			focus = nil
 		}
@@ -269,11 +571,11 @@ func (q *keyQueue) setFocus(focus event.Tag, events *handlerEvents) {
 	}
 	q.content = EditorState{}
 	if q.focus != nil {
-		events.Add(q.focus, key.FocusEvent{Focus: false})
+		q.addFocus(events, q.focus, key.FocusEvent{Focus: false}, true)
 	}
 	q.focus = focus
 	if q.focus != nil {
-		events.Add(q.focus, key.FocusEvent{Focus: true})
+		q.addFocus(events, q.focus, key.FocusEvent{Focus: true}, true)
 	}
 	if q.focus == nil || q.state == TextInputKeep {
 		q.state = TextInputClose
@@ -307,10 +609,15 @@ func (k *keyCollector) handlerFor(tag event.Tag, bounds f32.Rectangle) *keyHandl
 	return h
 }
 
-func (k *keyCollector) inputOp(op key.InputOp, bounds f32.Rectangle) {
+func (k *keyCollector) inputOp(op key.InputOp, area int, bounds f32.Rectangle) {
 	h := k.handlerFor(op.Tag, bounds)
 	h.visible = true
+	h.area = area
 	h.hint = op.Hint
+	h.tabIndex = op.TabIndex
+	if op.AutoFocus && h.new && k.autoFocus == nil {
+		k.autoFocus = op.Tag
+	}
 }
 
 func (k *keyCollector) selectionOp(t f32.Affine2D, op key.SelectionOp) {
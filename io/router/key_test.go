@@ -7,8 +7,10 @@ import (
 	"reflect"
 	"testing"
 
+	"gioui.org/f32"
 	"gioui.org/io/event"
 	"gioui.org/io/key"
+	"gioui.org/io/pointer"
 	"gioui.org/op"
 	"gioui.org/op/clip"
 )
@@ -24,14 +26,74 @@ func TestKeyWakeup(t *testing.T) {
 	if _, wake := r.WakeupTime(); wake {
 		t.Errorf("adding key.InputOp triggered a redraw")
 	}
-	// However, adding a handler queues a Focus(false) event.
-	if evts := r.Events(handler); len(evts) != 1 {
-		t.Errorf("no Focus event for newly registered key.InputOp")
+	// A handler that was never focused receives no Focus(false) event.
+	if evts := r.Events(handler); len(evts) != 0 {
+		t.Errorf("got %v for newly registered key.InputOp", evts)
 	}
-	// Verify that r.Events does trigger a redraw.
 	r.Frame(&ops)
-	if _, wake := r.WakeupTime(); !wake {
-		t.Errorf("key.FocusEvent event didn't trigger a redraw")
+	if _, wake := r.WakeupTime(); wake {
+		t.Errorf("never focused key.InputOp triggered a redraw")
+	}
+}
+
+func TestKeyRefocus(t *testing.T) {
+	handler := new(int)
+	ops := new(op.Ops)
+	r := new(Router)
+
+	key.InputOp{Tag: handler}.Add(ops)
+	key.FocusOp{Tag: handler}.Add(ops)
+	r.Frame(ops)
+	assertKeyEvent(t, r.Events(handler), true)
+
+	// Remove the focused handler.
+	ops.Reset()
+	r.Frame(ops)
+
+	// A re-appearing handler that lost its focus while gone is reset
+	// with a Focus(false) event, without a redraw.
+	key.InputOp{Tag: handler}.Add(ops)
+	r.Frame(ops)
+	if _, wake := r.WakeupTime(); wake {
+		t.Errorf("re-appearing key.InputOp triggered a redraw")
+	}
+	assertKeyEvent(t, r.Events(handler), false)
+
+	// The reset is delivered once.
+	ops.Reset()
+	r.Frame(ops)
+	key.InputOp{Tag: handler}.Add(ops)
+	r.Frame(ops)
+	if evts := r.Events(handler); len(evts) != 0 {
+		t.Errorf("got %v for re-appearing key.InputOp", evts)
+	}
+
+	// Handlers gone for several frames are reset as well.
+	key.FocusOp{Tag: handler}.Add(ops)
+	r.Frame(ops)
+	assertKeyEvent(t, r.Events(handler), true)
+	ops.Reset()
+	for i := 0; i < 3; i++ {
+		r.Frame(ops)
+	}
+	key.InputOp{Tag: handler}.Add(ops)
+	r.Frame(ops)
+	assertKeyEvent(t, r.Events(handler), false)
+
+	// Forgotten handlers are not.
+	key.FocusOp{Tag: handler}.Add(ops)
+	r.Frame(ops)
+	assertKeyEvent(t, r.Events(handler), true)
+	ops.Reset()
+	r.Frame(ops)
+	r.Forget(handler)
+	if n := len(r.key.queue.unfocused); n != 0 {
+		t.Errorf("%d unfocused handlers remembered after Forget", n)
+	}
+	key.InputOp{Tag: handler}.Add(ops)
+	r.Frame(ops)
+	if evts := r.Events(handler); len(evts) != 0 {
+		t.Errorf("got %v for re-appearing forgotten key.InputOp", evts)
 	}
 }
 
@@ -50,8 +112,8 @@ func TestKeyMultiples(t *testing.T) {
 
 	r.Frame(ops)
 
-	assertKeyEvent(t, r.Events(&handlers[0]), false)
-	assertKeyEvent(t, r.Events(&handlers[1]), false)
+	assertNoKeyEvent(t, r.Events(&handlers[0]))
+	assertNoKeyEvent(t, r.Events(&handlers[1]))
 	assertKeyEvent(t, r.Events(&handlers[2]), true)
 	assertFocus(t, r, &handlers[2])
 	assertKeyboard(t, r, TextInputOpen)
@@ -73,10 +135,10 @@ func TestKeyStacked(t *testing.T) {
 
 	r.Frame(ops)
 
-	assertKeyEvent(t, r.Events(&handlers[0]), false)
+	assertNoKeyEvent(t, r.Events(&handlers[0]))
 	assertKeyEvent(t, r.Events(&handlers[1]), true)
-	assertKeyEvent(t, r.Events(&handlers[2]), false)
-	assertKeyEvent(t, r.Events(&handlers[3]), false)
+	assertNoKeyEvent(t, r.Events(&handlers[2]))
+	assertNoKeyEvent(t, r.Events(&handlers[3]))
 	assertFocus(t, r, &handlers[1])
 	assertKeyboard(t, r, TextInputOpen)
 }
@@ -115,7 +177,7 @@ func TestKeyRemoveFocus(t *testing.T) {
 	r.Queue(event)
 
 	assertKeyEvent(t, r.Events(&handlers[0]), true, event)
-	assertKeyEvent(t, r.Events(&handlers[1]), false)
+	assertNoKeyEvent(t, r.Events(&handlers[1]))
 	assertFocus(t, r, &handlers[0])
 	assertKeyboard(t, r, TextInputOpen)
 
@@ -184,7 +246,7 @@ func TestKeyFocusedInvisible(t *testing.T) {
 	r.Frame(ops)
 
 	assertKeyEvent(t, r.Events(&handlers[0]), true)
-	assertKeyEvent(t, r.Events(&handlers[1]), false)
+	assertNoKeyEvent(t, r.Events(&handlers[1]))
 	assertFocus(t, r, &handlers[0])
 	assertKeyboard(t, r, TextInputOpen)
 
@@ -222,6 +284,55 @@ func TestKeyFocusedInvisible(t *testing.T) {
 
 }
 
+func TestKeyFocusCoalescing(t *testing.T) {
+	focusEvents := func(events []event.Event) []bool {
+		var focus []bool
+		for _, e := range events {
+			if e, ok := e.(key.FocusEvent); ok {
+				focus = append(focus, e.Focus)
+			}
+		}
+		return focus
+	}
+	for _, coalesce := range []bool{true, false} {
+		h := new(int)
+		ops := new(op.Ops)
+		var r Router
+		r.SetFocusCoalescing(coalesce)
+		key.InputOp{Tag: h}.Add(ops)
+		key.FocusOp{Tag: h}.Add(ops)
+		r.Frame(ops)
+		assertKeyEvent(t, r.Events(h), true)
+
+		// The focused handler churns out of a frame, and regains the
+		// focus in the next.
+		ops.Reset()
+		r.Frame(ops)
+		key.InputOp{Tag: h}.Add(ops)
+		key.FocusOp{Tag: h}.Add(ops)
+		r.Frame(ops)
+		got := focusEvents(r.Events(h))
+		var want []bool
+		if !coalesce {
+			want = []bool{true}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("coalesce %v: refocus delivered %v, expected %v", coalesce, got, want)
+		}
+
+		// Repeated window focus events.
+		r.Queue(key.FocusEvent{Focus: false}, key.FocusEvent{Focus: false})
+		got = focusEvents(r.Events(h))
+		want = []bool{false}
+		if !coalesce {
+			want = []bool{false, false}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("coalesce %v: window focus delivered %v, expected %v", coalesce, got, want)
+		}
+	}
+}
+
 func TestNoOps(t *testing.T) {
 	r := new(Router)
 	r.Frame(nil)
@@ -248,6 +359,148 @@ func TestTabFocus(t *testing.T) {
 	assertFocus(t, r, &handlers[2])
 }
 
+func TestTabIndex(t *testing.T) {
+	handlers := make([]int, 5)
+	ops := new(op.Ops)
+	r := new(Router)
+
+	// Visit the positive indices first, then the zero indices in op
+	// order, and skip the negative index: 2, 0, 1, 4.
+	indices := []int{2, 0, 1, -1, 0}
+	for i := range handlers {
+		key.InputOp{Tag: &handlers[i], TabIndex: indices[i]}.Add(ops)
+	}
+	r.Frame(ops)
+
+	tab := func(mod key.Modifiers) {
+		r.Queue(
+			key.Event{Name: key.NameTab, State: key.Press, Modifiers: mod},
+			key.Event{Name: key.NameTab, State: key.Release, Modifiers: mod},
+		)
+	}
+	for _, i := range []int{2, 0, 1, 4, 2} {
+		tab(0)
+		assertFocus(t, r, &handlers[i])
+	}
+	tab(key.ModShift)
+	assertFocus(t, r, &handlers[4])
+	tab(key.ModShift)
+	assertFocus(t, r, &handlers[1])
+
+	// Tab doesn't focus handlers with only negative indices.
+	ops.Reset()
+	key.InputOp{Tag: &handlers[3], TabIndex: -1}.Add(ops)
+	r.Frame(ops)
+	tab(0)
+	assertFocus(t, r, nil)
+}
+
+func TestTextInputArea(t *testing.T) {
+	var (
+		ops    op.Ops
+		r      Router
+		editor = new(int)
+		other  = new(int)
+		text   string
+		focus  bool
+	)
+	frame := func() {
+		for _, e := range r.Events(editor) {
+			if e, ok := e.(key.EditEvent); ok {
+				text += e.Text
+			}
+		}
+		ops.Reset()
+		if focus {
+			key.FocusOp{Tag: editor}.Add(&ops)
+			focus = false
+		}
+		// An unfocused handler with a selection.
+		area := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+		key.InputOp{Tag: other}.Add(&ops)
+		key.SelectionOp{Tag: other, Caret: key.Caret{Pos: f32.Pt(5, 5), Ascent: 5}}.Add(&ops)
+		area.Pop()
+		// The editor is offset and scaled, and its caret is at the end
+		// of its 10 pixels wide characters.
+		trans := op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(2, 2)).Offset(f32.Pt(50, 200))).Push(&ops)
+		area = clip.Rect(image.Rect(0, 0, 150, 30)).Push(&ops)
+		key.InputOp{Tag: editor}.Add(&ops)
+		n := len(text)
+		key.SelectionOp{
+			Tag:   editor,
+			Range: key.Range{Start: n, End: n},
+			Caret: key.Caret{Pos: f32.Pt(float32(n*10), 20), Ascent: 15, Descent: 5},
+		}.Add(&ops)
+		area.Pop()
+		trans.Pop()
+		r.Frame(&ops)
+	}
+	frame()
+	if a := r.EditorState().Area; a != (f32.Rectangle{}) {
+		t.Errorf("got area %v without focus", a)
+	}
+	// Focus the editor. The editor reports its selection in the frame
+	// after.
+	focus = true
+	frame()
+	frame()
+	if got, exp := r.EditorState().Area, f32.Rect(50, 200, 350, 260); got != exp {
+		t.Errorf("got area %v, expected %v", got, exp)
+	}
+	for i := 0; i < 3; i++ {
+		if got, exp := r.EditorState().CaretRect(), f32.Rect(50+float32(i)*20, 210, 50+float32(i)*20, 250); got != exp {
+			t.Errorf("caret %d: got rect %v, expected %v", i, got, exp)
+		}
+		r.Queue(key.EditEvent{Text: "a"})
+		frame()
+	}
+}
+
+func TestKeyAutoFocus(t *testing.T) {
+	handlers := make([]int, 3)
+	ops := new(op.Ops)
+	r := new(Router)
+
+	key.InputOp{Tag: &handlers[0]}.Add(ops)
+	key.InputOp{Tag: &handlers[1], AutoFocus: true}.Add(ops)
+	key.InputOp{Tag: &handlers[2], AutoFocus: true}.Add(ops)
+	r.Frame(ops)
+	assertFocus(t, r, &handlers[1])
+	assertKeyEvent(t, r.Events(&handlers[1]), true)
+
+	// Auto-focus applies only to new handlers without a focused
+	// handler.
+	ops.Reset()
+	key.InputOp{Tag: &handlers[0]}.Add(ops)
+	key.InputOp{Tag: &handlers[1], AutoFocus: true}.Add(ops)
+	key.InputOp{Tag: &handlers[2], AutoFocus: true}.Add(ops)
+	key.FocusOp{Tag: nil}.Add(ops)
+	r.Frame(ops)
+	assertFocus(t, r, nil)
+	ops.Reset()
+	key.InputOp{Tag: &handlers[0]}.Add(ops)
+	key.InputOp{Tag: &handlers[1], AutoFocus: true}.Add(ops)
+	r.Frame(ops)
+	assertFocus(t, r, nil)
+
+	// An explicit FocusOp takes precedence.
+	r = new(Router)
+	ops.Reset()
+	key.InputOp{Tag: &handlers[0]}.Add(ops)
+	key.InputOp{Tag: &handlers[1], AutoFocus: true}.Add(ops)
+	key.FocusOp{Tag: &handlers[0]}.Add(ops)
+	r.Frame(ops)
+	assertFocus(t, r, &handlers[0])
+
+	// A focused handler keeps the focus from new auto-focus handlers.
+	ops.Reset()
+	key.InputOp{Tag: &handlers[0]}.Add(ops)
+	key.InputOp{Tag: &handlers[1], AutoFocus: true}.Add(ops)
+	key.InputOp{Tag: &handlers[2], AutoFocus: true}.Add(ops)
+	r.Frame(ops)
+	assertFocus(t, r, &handlers[0])
+}
+
 func TestDirectionalFocus(t *testing.T) {
 	ops := new(op.Ops)
 	r := new(Router)
@@ -283,6 +536,106 @@ func TestDirectionalFocus(t *testing.T) {
 	assertFocus(t, r, &handlers[0])
 }
 
+func TestPeekFocus(t *testing.T) {
+	ops := new(op.Ops)
+	r := new(Router)
+	if tag := r.PeekFocus(FocusDown); tag != nil {
+		t.Errorf("PeekFocus without handlers: got %v, expected nil", tag)
+	}
+	handlers := []image.Rectangle{
+		image.Rect(10, 10, 50, 50),
+		image.Rect(50, 20, 100, 80),
+		image.Rect(20, 26, 60, 80),
+		image.Rect(10, 60, 50, 100),
+	}
+	for i, bounds := range handlers {
+		cl := clip.Rect(bounds).Push(ops)
+		key.InputOp{Tag: &handlers[i]}.Add(ops)
+		cl.Pop()
+	}
+	r.Frame(ops)
+
+	for _, dir := range []FocusDirection{FocusLeft, FocusLeft, FocusRight, FocusRight, FocusDown, FocusDown, FocusLeft, FocusUp} {
+		focus := r.key.queue.focus
+		peek := r.PeekFocus(dir)
+		assertFocus(t, r, focus)
+		r.MoveFocus(dir)
+		if peek == nil {
+			assertFocus(t, r, focus)
+		} else {
+			assertFocus(t, r, peek)
+		}
+	}
+	assertFocus(t, r, &handlers[0])
+}
+
+func TestDirectionalFocusWeight(t *testing.T) {
+	handlers := []image.Rectangle{
+		image.Rect(10, 10, 50, 50),
+		image.Rect(50, 20, 100, 80),
+		image.Rect(20, 26, 60, 80),
+		image.Rect(10, 60, 50, 100),
+	}
+	ops := new(op.Ops)
+	for i, bounds := range handlers {
+		cl := clip.Rect(bounds).Push(ops)
+		key.InputOp{Tag: &handlers[i]}.Add(ops)
+		cl.Pop()
+	}
+	key.FocusOp{Tag: &handlers[3]}.Add(ops)
+	// Moving up from handler 3, handler 0 is aligned but handler 1
+	// is closer.
+	for _, tc := range []struct {
+		weight float32
+		focus  event.Tag
+	}{
+		{weight: 0, focus: &handlers[0]},
+		{weight: 2, focus: &handlers[0]},
+		{weight: 3, focus: &handlers[1]},
+		{weight: 10, focus: &handlers[1]},
+	} {
+		r := new(Router)
+		r.SetFocusDistanceWeight(tc.weight)
+		r.Frame(ops)
+		assertFocus(t, r, &handlers[3])
+		r.MoveFocus(FocusUp)
+		assertFocus(t, r, tc.focus)
+	}
+}
+
+func TestFocusableBounds(t *testing.T) {
+	ops := new(op.Ops)
+	r := new(Router)
+	handlers := []image.Rectangle{
+		image.Rect(10, 10, 50, 50),
+		image.Rect(50, 20, 100, 80),
+		image.Rect(20, 26, 60, 80),
+	}
+	for i, bounds := range handlers {
+		cl := clip.Rect(bounds).Push(ops)
+		key.InputOp{Tag: &handlers[i]}.Add(ops)
+		cl.Pop()
+	}
+	// Transformed handler.
+	transformed := new(int)
+	off := op.Offset(f32.Pt(5, 5)).Push(ops)
+	cl := clip.Rect(image.Rect(0, 0, 10, 10)).Push(ops)
+	key.InputOp{Tag: transformed}.Add(ops)
+	cl.Pop()
+	off.Pop()
+	r.Frame(ops)
+
+	exp := map[event.Tag]image.Rectangle{
+		transformed: image.Rect(5, 5, 15, 15),
+	}
+	for i, b := range handlers {
+		exp[&handlers[i]] = b
+	}
+	if got := r.FocusableBounds(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("got bounds %v; expected %v", got, exp)
+	}
+}
+
 func assertKeyEvent(t *testing.T, events []event.Event, expected bool, expectedInputs ...event.Event) {
 	t.Helper()
 	var evtFocus int
@@ -315,6 +668,13 @@ func assertKeyEvent(t *testing.T, events []event.Event, expected bool, expectedI
 	}
 }
 
+func assertNoKeyEvent(t *testing.T, events []event.Event) {
+	t.Helper()
+	if len(events) > 0 {
+		t.Errorf("unexpected events %v", events)
+	}
+}
+
 func assertKeyEventUnexpected(t *testing.T, events []event.Event) {
 	t.Helper()
 	var evtFocus int
@@ -342,3 +702,183 @@ func assertKeyboard(t *testing.T, router *Router, expected TextInputState) {
 		t.Errorf("expected %v keyboard, got %v", expected, got)
 	}
 }
+
+func TestKeySuspend(t *testing.T) {
+	for _, mode := range []SuspendMode{SuspendBuffer, SuspendDrop} {
+		handler := new(int)
+		ops := new(op.Ops)
+		r := new(Router)
+		key.InputOp{Tag: handler}.Add(ops)
+		key.FocusOp{Tag: handler}.Add(ops)
+		r.Frame(ops)
+		assertKeyEvent(t, r.Events(handler), true)
+		ops.Reset()
+		key.InputOp{Tag: handler}.Add(ops)
+
+		r.Suspend(handler, mode)
+		r.Queue(
+			key.Event{Name: "A", State: key.Press},
+			key.Event{Name: "B", State: key.Press},
+		)
+		if evts := r.Events(handler); len(evts) != 0 {
+			t.Errorf("mode %d: suspended handler received %v", mode, evts)
+		}
+		r.Frame(ops)
+		dropped := 0
+		if mode == SuspendDrop {
+			dropped = 2
+		}
+		if got := r.QueueStats().Dropped; got != dropped {
+			t.Errorf("mode %d: %d events dropped, expected %d", mode, got, dropped)
+		}
+		// Dropped events don't cause redraws.
+		if redraw := r.Queue(key.Event{Name: "C", State: key.Press}); redraw != (mode == SuspendBuffer) {
+			t.Errorf("mode %d: redraw after queueing is %v", mode, redraw)
+		}
+		assertFocus(t, r, handler)
+
+		r.Resume(handler)
+		r.Frame(ops)
+		if _, wake := r.WakeupTime(); wake != (mode == SuspendBuffer) {
+			t.Errorf("mode %d: redraw after resume is %v", mode, wake)
+		}
+		r.Queue(key.Event{Name: "D", State: key.Press})
+		var exp []event.Event
+		if mode == SuspendBuffer {
+			exp = append(exp,
+				key.Event{Name: "A", State: key.Press},
+				key.Event{Name: "B", State: key.Press},
+				key.Event{Name: "C", State: key.Press},
+			)
+		}
+		exp = append(exp, key.Event{Name: "D", State: key.Press})
+		if got := r.Events(handler); !reflect.DeepEqual(got, exp) {
+			t.Errorf("mode %d: got %v after resume, expected %v", mode, got, exp)
+		}
+		assertFocus(t, r, handler)
+	}
+}
+
+func TestKeyHeldAcrossFocus(t *testing.T) {
+	ops := new(op.Ops)
+	r := new(Router)
+	h1, h2 := new(int), new(int)
+	frame := func(focus event.Tag) {
+		ops.Reset()
+		key.InputOp{Tag: h1}.Add(ops)
+		key.InputOp{Tag: h2}.Add(ops)
+		if focus != nil {
+			key.FocusOp{Tag: focus}.Add(ops)
+		}
+		r.Frame(ops)
+	}
+	shift := key.Event{Name: key.NameShift, Modifiers: key.ModShift, State: key.Press}
+	released := shift
+	released.State = key.Release
+
+	frame(h1)
+	r.Events(h1)
+	r.Events(h2)
+	r.Queue(shift)
+	if evts := r.Events(h1); !reflect.DeepEqual(evts, []event.Event{shift}) {
+		t.Errorf("got %v, expected the shift press", evts)
+	}
+
+	// The tag gaining focus can query the held key.
+	frame(h2)
+	if evts := r.Events(h1); !reflect.DeepEqual(evts, []event.Event{key.FocusEvent{Focus: false}}) {
+		t.Errorf("got %v for the unfocused tag, expected a focus loss", evts)
+	}
+	if evts := r.Events(h2); !reflect.DeepEqual(evts, []event.Event{key.FocusEvent{Focus: true}}) {
+		t.Errorf("got %v for the focused tag, expected a focus gain", evts)
+	}
+	if held := r.AppendHeldKeys(nil); !reflect.DeepEqual(held, []key.Event{shift}) {
+		t.Errorf("got held keys %v, expected %v", held, shift)
+	}
+
+	// The release of shift reaches the focused tag only.
+	r.Queue(released)
+	if evts := r.Events(h2); !reflect.DeepEqual(evts, []event.Event{released}) {
+		t.Errorf("got %v, expected the shift release", evts)
+	}
+	if evts := r.Events(h1); len(evts) != 0 {
+		t.Errorf("got %v for the unfocused tag", evts)
+	}
+	// Released keys are no longer held.
+	if held := r.AppendHeldKeys(nil); len(held) != 0 {
+		t.Errorf("got held keys %v after their release", held)
+	}
+
+	// Keys are released when the window loses focus.
+	r.Queue(shift, key.FocusEvent{Focus: false})
+	if held := r.AppendHeldKeys(nil); len(held) != 0 {
+		t.Errorf("got held keys %v after a window focus loss", held)
+	}
+}
+
+func TestKeyStuckModifier(t *testing.T) {
+	ops := new(op.Ops)
+	r := new(Router)
+	h1, h2 := new(int), new(int)
+	frame := func(focus event.Tag) {
+		ops.Reset()
+		key.InputOp{Tag: h1}.Add(ops)
+		key.InputOp{Tag: h2}.Add(ops)
+		key.FocusOp{Tag: focus}.Add(ops)
+		r.Frame(ops)
+	}
+	frame(h1)
+	// The release of shift is lost.
+	r.Queue(key.Event{Name: key.NameShift, Modifiers: key.ModShift, State: key.Press})
+	if m := r.Modifiers(); m != key.ModShift {
+		t.Errorf("got modifiers %v after the shift press, expected %v", m, key.ModShift)
+	}
+	// The next key event reports no modifiers.
+	a := key.Event{Name: "A", State: key.Press}
+	r.Queue(a)
+	if m := r.Modifiers(); m != 0 {
+		t.Errorf("got modifiers %v, expected none", m)
+	}
+	r.Events(h2)
+	frame(h2)
+	if held := r.AppendHeldKeys(nil); !reflect.DeepEqual(held, []key.Event{a}) {
+		t.Errorf("got held keys %v, expected %v", held, a)
+	}
+}
+
+func TestClearWakeup(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	key.InputOp{Tag: handler}.Add(&ops)
+	op.InvalidateOp{}.Add(&ops)
+
+	var r Router
+	r.Frame(&ops)
+	if _, wake := r.WakeupTime(); !wake {
+		t.Fatal("InvalidateOp didn't set a wakeup")
+	}
+	// WakeupTime doesn't consume the wakeup.
+	if _, wake := r.WakeupTime(); !wake {
+		t.Fatal("WakeupTime consumed the wakeup")
+	}
+	r.ClearWakeup()
+	if _, wake := r.WakeupTime(); wake {
+		t.Error("wakeup pending after ClearWakeup")
+	}
+	if _, wake := r.ScheduledWakeupTime(); wake {
+		t.Error("scheduled wakeup pending after ClearWakeup")
+	}
+	// Events without handlers set no wakeup.
+	r.Queue(pointer.Event{Type: pointer.Press, Position: f32.Pt(10, 10)})
+	if _, wake := r.WakeupTime(); wake {
+		t.Error("unhandled event set a wakeup")
+	}
+	// A delivered event sets an immediate wakeup.
+	key.FocusOp{Tag: handler}.Add(&ops)
+	r.Frame(&ops)
+	r.ClearWakeup()
+	r.Queue(key.Event{Name: "A", State: key.Press})
+	if at, wake := r.WakeupTime(); !wake || !at.IsZero() {
+		t.Errorf("got wakeup %v, %v after a key event; expected an immediate wakeup", at, wake)
+	}
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+)
+
+// alphaMask is the image of a pointer.MaskOp.
+type alphaMask struct {
+	img       image.Image
+	threshold uint8
+	// invTrans maps window coordinates to the coordinates of the
+	// image.
+	invTrans f32.Affine2D
+}
+
+// hit reports whether the pixel of the mask at the window position p
+// is more opaque than the threshold.
+func (m *alphaMask) hit(p f32.Point) bool {
+	lp := m.invTrans.Transform(p)
+	x, y := int(math.Floor(float64(lp.X))), int(math.Floor(float64(lp.Y)))
+	if !image.Pt(x, y).In(m.img.Bounds()) {
+		return false
+	}
+	var a uint8
+	switch img := m.img.(type) {
+	case *image.Alpha:
+		a = img.AlphaAt(x, y).A
+	case *image.NRGBA:
+		a = img.NRGBAAt(x, y).A
+	case *image.RGBA:
+		a = img.RGBAAt(x, y).A
+	default:
+		a = color.AlphaModel.Convert(img.At(x, y)).(color.Alpha).A
+	}
+	return a > m.threshold
+}
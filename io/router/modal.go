@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+)
+
+// modalTags is the set of handlers declared inside event.ModalOps, or nil
+// if the frame has no ModalOp.
+type modalTags map[event.Tag]struct{}
+
+// inert reports whether t is outside the ModalOps of a frame with
+// ModalOps.
+func (m modalTags) inert(t event.Tag) bool {
+	if m == nil {
+		return false
+	}
+	_, ok := m[t]
+	return !ok
+}
+
+// blocks reports whether the event e for t is dropped because t is
+// inert. Cancel events are delivered to let inert handlers reset, and
+// focus events to let them follow the loss of focus.
+func (m modalTags) blocks(t event.Tag, e event.Event) bool {
+	switch e := e.(type) {
+	case pointer.Event:
+		if e.Type == pointer.Cancel {
+			return false
+		}
+	case key.Event, key.EditEvent, key.SnippetEvent, key.SelectionEvent:
+	default:
+		return false
+	}
+	return m.inert(t)
+}
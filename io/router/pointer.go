@@ -5,22 +5,30 @@ package router
 import (
 	"image"
 	"io"
+	"time"
 
 	"gioui.org/f32"
 	"gioui.org/internal/ops"
 	"gioui.org/io/event"
+	"gioui.org/io/key"
 	"gioui.org/io/pointer"
 	"gioui.org/io/semantic"
 	"gioui.org/io/transfer"
 )
 
 type pointerQueue struct {
-	hitTree   []hitNode
-	areas     []areaNode
+	hitTree []hitNode
+	areas   []areaNode
+	regions []regionIndex
+	masks   []alphaMask
+	// expanded is set if any area has an expansion.
+	expanded  bool
 	cursor    pointer.Cursor
 	handlers  map[event.Tag]*pointerHandler
 	pointers  []pointerInfo
 	transfers []io.ReadCloser // pending data transfers
+	// prediction is how far ahead pointer motion is predicted.
+	prediction time.Duration
 
 	scratch []event.Tag
 
@@ -31,6 +39,9 @@ type pointerQueue struct {
 		// previously assigned. It is used to maintain stable IDs across
 		// frames.
 		contentIDs map[semanticContent][]semanticID
+		// announce contains the tags of the live regions changed in the
+		// current frame.
+		announce map[event.Tag]bool
 	}
 }
 
@@ -56,8 +67,28 @@ type pointerInfo struct {
 
 	dataSource event.Tag // dragging source tag
 	dataTarget event.Tag // dragging target tag
+
+	// motion is the recent positions of the pointer, oldest first.
+	motion  [motionSamples]motionSample
+	nmotion int
+}
+
+// motionSample is a position of a pointer at a time.
+type motionSample struct {
+	pos f32.Point
+	t   time.Duration
 }
 
+const (
+	// motionSamples is the number of recent pointer positions used for
+	// predicting pointer motion.
+	motionSamples = 4
+	// motionWindow is the maximum age of the positions used for
+	// predicting pointer motion. Older positions don't reflect the
+	// current velocity.
+	motionWindow = 100 * time.Millisecond
+)
+
 type pointerHandler struct {
 	area      int
 	active    bool
@@ -65,6 +96,8 @@ type pointerHandler struct {
 	types     pointer.Type
 	// min and max horizontal/vertical scroll
 	scrollRange image.Rectangle
+	// scrollMods are the modifiers required for scroll events.
+	scrollMods key.Modifiers
 
 	sourceMimes []string
 	targetMimes []string
@@ -79,9 +112,19 @@ type areaOp struct {
 
 type areaNode struct {
 	trans f32.Affine2D
-	area  areaOp
+	// invTrans is the inverse of trans, for mapping pointer
+	// positions to the local coordinates of the area.
+	invTrans f32.Affine2D
+	area     areaOp
+	// slack is the area expanded by an ExpandOp, in local coordinates,
+	// or the empty rectangle.
+	slack f32.Rectangle
 
 	cursor pointer.Cursor
+	// regions is the index of the regionIndex of the area, or -1.
+	regions int
+	// mask is the index of the alphaMask of the area, or -1.
+	mask int
 
 	// Tree indices, with -1 being the sentinel.
 	parent     int
@@ -105,14 +148,17 @@ type collectState struct {
 	// make the zero value collectState the initial state.
 	nodePlusOne int
 	pass        int
+	// expand is the minimum size of the current ExpandOp.
+	expand image.Point
 }
 
 // pointerCollector tracks the state needed to update an pointerQueue
 // from pointer ops.
 type pointerCollector struct {
-	q         *pointerQueue
-	state     collectState
-	nodeStack []int
+	q           *pointerQueue
+	state       collectState
+	nodeStack   []int
+	expandStack []image.Point
 }
 
 type semanticContent struct {
@@ -123,6 +169,9 @@ type semanticContent struct {
 	gestures SemanticGestures
 	selected bool
 	disabled bool
+	// live is the tag of a live region, or nil.
+	live       event.Tag
+	politeness semantic.Politeness
 }
 
 type semanticID struct {
@@ -138,6 +187,7 @@ const (
 func (c *pointerCollector) resetState() {
 	c.state = collectState{}
 	c.nodeStack = c.nodeStack[:0]
+	c.expandStack = c.expandStack[:0]
 	// Pop every node except the root.
 	if len(c.q.hitTree) > 0 {
 		c.state.nodePlusOne = 0 + 1
@@ -172,12 +222,18 @@ func (c *pointerCollector) pushArea(kind areaKind, bounds f32.Rectangle) {
 	}
 	an := areaNode{
 		trans:      c.state.t,
+		invTrans:   c.state.t.Invert(),
 		area:       areaOp,
+		regions:    -1,
+		mask:       -1,
 		parent:     parentID,
 		sibling:    -1,
 		firstChild: -1,
 		lastChild:  -1,
 	}
+	if e := c.state.expand; e != (image.Point{}) {
+		c.addSlack(&an, expandRect(bounds, fpt(e)))
+	}
 
 	c.q.areas = append(c.q.areas, an)
 	c.nodeStack = append(c.nodeStack, c.state.nodePlusOne-1)
@@ -215,6 +271,37 @@ func (c *pointerCollector) popPass() {
 	c.state.pass--
 }
 
+func (c *pointerCollector) expand(min image.Point) {
+	c.expandStack = append(c.expandStack, c.state.expand)
+	c.state.expand = min
+}
+
+func (c *pointerCollector) popExpand() {
+	n := len(c.expandStack)
+	c.state.expand = c.expandStack[n-1]
+	c.expandStack = c.expandStack[:n-1]
+}
+
+// addSlack adds r to the expanded area of an. The expanded areas of
+// ExpandOp and InputOp.Margin share the slack hit test.
+func (c *pointerCollector) addSlack(an *areaNode, r f32.Rectangle) {
+	an.slack = an.slack.Union(r)
+	c.q.expanded = true
+}
+
+// expandRect grows r to at least min, keeping its center.
+func expandRect(r f32.Rectangle, min f32.Point) f32.Rectangle {
+	if d := min.X - r.Dx(); d > 0 {
+		r.Min.X -= d / 2
+		r.Max.X += d / 2
+	}
+	if d := min.Y - r.Dy(); d > 0 {
+		r.Min.Y -= d / 2
+		r.Max.Y += d / 2
+	}
+	return r
+}
+
 func (c *pointerCollector) currentArea() int {
 	if i := c.state.nodePlusOne - 1; i != -1 {
 		n := c.q.hitTree[i]
@@ -266,10 +353,42 @@ func (c *pointerCollector) inputOp(op pointer.InputOp, events *handlerEvents) {
 		area.semantic.content.gestures |= ClickGesture
 	}
 	area.semantic.valid = area.semantic.content.gestures != 0
+	if m := float32(op.Margin); m > 0 {
+		// A margin expands the area like an ExpandOp to the size of
+		// the area plus the margins.
+		r := area.area.rect
+		c.addSlack(area, expandRect(r, r.Size().Add(f32.Pt(2*m, 2*m))))
+	}
 	h := c.newHandler(op.Tag, events)
 	h.wantsGrab = h.wantsGrab || op.Grab
 	h.types = h.types | op.Types
 	h.scrollRange = op.ScrollBounds
+	h.scrollMods = op.ScrollModifiers
+}
+
+func (c *pointerCollector) regions(regions [][]f32.Point) {
+	areaID := c.currentArea()
+	area := &c.q.areas[areaID]
+	q := c.q
+	n := len(q.regions)
+	// Re-use the index allocations from earlier frames.
+	if n < cap(q.regions) {
+		q.regions = q.regions[:n+1]
+	} else {
+		q.regions = append(q.regions, regionIndex{})
+	}
+	q.regions[n].reset(regions, c.state.t.Invert())
+	area.regions = n
+}
+
+func (c *pointerCollector) mask(img image.Image, threshold uint8) {
+	areaID := c.currentArea()
+	c.q.areas[areaID].mask = len(c.q.masks)
+	c.q.masks = append(c.q.masks, alphaMask{
+		img:       img,
+		threshold: threshold,
+		invTrans:  c.state.t.Invert(),
+	})
 }
 
 func (c *pointerCollector) semanticLabel(lbl string) {
@@ -307,6 +426,22 @@ func (c *pointerCollector) semanticDisabled(disabled bool) {
 	area.semantic.content.disabled = disabled
 }
 
+func (c *pointerCollector) semanticLive(op semantic.LiveRegionOp) {
+	areaID := c.currentArea()
+	area := &c.q.areas[areaID]
+	area.semantic.valid = true
+	area.semantic.content.live = op.Tag
+	area.semantic.content.politeness = op.Politeness
+}
+
+func (c *pointerCollector) semanticAnnounce(tag event.Tag) {
+	q := c.q
+	if q.semantic.announce == nil {
+		q.semantic.announce = make(map[event.Tag]bool)
+	}
+	q.semantic.announce[tag] = true
+}
+
 func (c *pointerCollector) cursor(cursor pointer.Cursor) {
 	areaID := c.currentArea()
 	area := &c.q.areas[areaID]
@@ -385,18 +520,9 @@ func (q *pointerQueue) appendSemanticChildren(nodes []SemanticNode, areaIdx int)
 	}
 	a := q.areas[areaIdx]
 	if semID := a.semantic.id; semID != 0 {
-		cnt := a.semantic.content
 		nodes = append(nodes, SemanticNode{
-			ID: semID,
-			Desc: SemanticDesc{
-				Bounds:      a.bounds(),
-				Label:       cnt.label,
-				Description: cnt.desc,
-				Class:       cnt.class,
-				Gestures:    cnt.gestures,
-				Selected:    cnt.selected,
-				Disabled:    cnt.disabled,
-			},
+			ID:      semID,
+			Desc:    q.semanticDesc(&a),
 			areaIdx: areaIdx,
 		})
 	} else {
@@ -405,6 +531,23 @@ func (q *pointerQueue) appendSemanticChildren(nodes []SemanticNode, areaIdx int)
 	return q.appendSemanticChildren(nodes, a.sibling)
 }
 
+// semanticDesc returns the semantic description of the area a.
+func (q *pointerQueue) semanticDesc(a *areaNode) SemanticDesc {
+	cnt := a.semantic.content
+	return SemanticDesc{
+		Bounds:      a.bounds(),
+		Label:       cnt.label,
+		Description: cnt.desc,
+		Class:       cnt.class,
+		Gestures:    cnt.gestures,
+		Selected:    cnt.selected,
+		Disabled:    cnt.disabled,
+		LiveRegion:  cnt.live != nil,
+		Politeness:  cnt.politeness,
+		Announce:    cnt.live != nil && q.semantic.announce[cnt.live],
+	}
+}
+
 func (q *pointerQueue) semanticIDFor(content semanticContent) SemanticID {
 	ids := q.semantic.contentIDs[content]
 	for i, id := range ids {
@@ -427,7 +570,7 @@ func (q *pointerQueue) SemanticAt(pos f32.Point) (SemanticID, bool) {
 	q.assignSemIDs()
 	for i := len(q.hitTree) - 1; i >= 0; i-- {
 		n := &q.hitTree[i]
-		hit, _ := q.hit(n.area, pos)
+		hit, _ := q.hit(n.area, pos, false)
 		if !hit {
 			continue
 		}
@@ -440,53 +583,202 @@ func (q *pointerQueue) SemanticAt(pos f32.Point) (SemanticID, bool) {
 }
 
 func (q *pointerQueue) opHit(pos f32.Point) ([]event.Tag, pointer.Cursor) {
+	hits := q.scratch[:0]
+	cursor := pointer.CursorDefault
+	q.walkHits(pos, func(n *hitNode, c pointer.Cursor) {
+		if cursor == pointer.CursorDefault {
+			cursor = c
+		}
+		if n.tag != nil {
+			if _, exists := q.handlers[n.tag]; exists {
+				hits = addHandler(hits, n.tag)
+			}
+		}
+	})
+	q.scratch = hits[:0]
+	return hits, cursor
+}
+
+// walkHits calls f for every hit node under pos, in the order of event
+// delivery. Nodes hidden by non pass-through nodes are skipped.
+func (q *pointerQueue) walkHits(pos f32.Point, f func(n *hitNode, c pointer.Cursor)) {
+	q.walk(pos, q.expanded && q.useSlack(pos), func(i int, c pointer.Cursor) {
+		f(&q.hitTree[i], c)
+	})
+}
+
+// useSlack reports whether the expanded areas take part in the hit test
+// at pos. An expanded area loses to the real area of another handler,
+// unless that handler also covers the center of the expanded area and
+// is thus behind it.
+func (q *pointerQueue) useSlack(pos f32.Point) bool {
+	top := q.topHandler(pos, true)
+	if top == -1 {
+		return false
+	}
+	area := q.hitTree[top].area
+	if hit, _ := q.hit(area, pos, false); hit {
+		return true
+	}
+	real := q.topHandler(pos, false)
+	if real == -1 {
+		return true
+	}
+	a := &q.areas[area]
+	b := a.area.rect
+	center := a.trans.Transform(b.Min.Add(b.Max).Mul(.5))
+	hit, _ := q.hit(q.hitTree[real].area, center, false)
+	return hit
+}
+
+// topHandler returns the index of the first non pass-through handler
+// node hit at pos, or -1.
+func (q *pointerQueue) topHandler(pos f32.Point, slack bool) int {
+	top := -1
+	q.walk(pos, slack, func(i int, c pointer.Cursor) {
+		n := &q.hitTree[i]
+		if top != -1 || n.tag == nil || n.pass {
+			return
+		}
+		if _, exists := q.handlers[n.tag]; exists {
+			top = i
+		}
+	})
+	return top
+}
+
+// walk calls f with the index of every hit node under pos. If slack is
+// set, expanded areas are hit by their expansions.
+func (q *pointerQueue) walk(pos f32.Point, slack bool, f func(idx int, c pointer.Cursor)) {
 	// Track whether we're passing through hits.
 	pass := true
-	hits := q.scratch[:0]
 	idx := len(q.hitTree) - 1
-	cursor := pointer.CursorDefault
 	for idx >= 0 {
 		n := &q.hitTree[idx]
-		hit, c := q.hit(n.area, pos)
+		hit, c := q.hit(n.area, pos, slack)
 		if !hit {
 			idx--
 			continue
 		}
-		if cursor == pointer.CursorDefault {
-			cursor = c
-		}
+		f(idx, c)
 		pass = pass && n.pass
 		if pass {
 			idx--
 		} else {
 			idx = n.next
 		}
+	}
+}
+
+// HitTest appends the handlers and semantic areas under pos to hits,
+// and returns the result.
+func (q *pointerQueue) HitTest(hits []Hit, pos f32.Point) []Hit {
+	q.assignSemIDs()
+	lastArea := -1
+	q.walkHits(pos, func(n *hitNode, c pointer.Cursor) {
+		a := &q.areas[n.area]
 		if n.tag != nil {
-			if _, exists := q.handlers[n.tag]; exists {
-				hits = addHandler(hits, n.tag)
+			if _, exists := q.handlers[n.tag]; !exists {
+				return
+			}
+			for _, h := range hits {
+				if h.Tag == n.tag {
+					return
+				}
 			}
+		} else if a.semantic.id == 0 || n.area == lastArea || n.area == 0 {
+			// Skip areas without semantics, areas already reported with
+			// their handlers, and the implicit root area.
+			return
 		}
-	}
-	q.scratch = hits[:0]
-	return hits, cursor
+		lastArea = n.area
+		h := Hit{
+			Tag:       n.tag,
+			Semantic:  a.semantic.id,
+			Bounds:    a.bounds(),
+			Transform: a.trans,
+		}
+		if h.Semantic != 0 {
+			h.Desc = q.semanticDesc(a)
+		}
+		hits = append(hits, h)
+	})
+	return hits
 }
 
 func (q *pointerQueue) invTransform(areaIdx int, p f32.Point) f32.Point {
 	if areaIdx == -1 {
 		return p
 	}
-	return q.areas[areaIdx].trans.Invert().Transform(p)
+	return q.areas[areaIdx].invTrans.Transform(p)
+}
+
+// invTransformVec is like invTransform, but ignores translation.
+// It is used for mapping relative amounts such as scroll distances.
+func (q *pointerQueue) invTransformVec(areaIdx int, v f32.Point) f32.Point {
+	if areaIdx == -1 {
+		return v
+	}
+	return transformVec(q.areas[areaIdx].invTrans, v)
+}
+
+// scrollRange returns the sum of the scroll ranges of the handlers under
+// pos, in window coordinates. Handlers that require modifiers for
+// scrolling are not included.
+func (q *pointerQueue) scrollRange(pos f32.Point) f32.Rectangle {
+	var sum f32.Rectangle
+	hits, _ := q.opHit(pos)
+	for _, k := range hits {
+		h := q.handlers[k]
+		if h.types&pointer.Scroll == 0 || h.scrollMods != 0 {
+			continue
+		}
+		r := f32.Rectangle{
+			Min: f32.Pt(float32(h.scrollRange.Min.X), float32(h.scrollRange.Min.Y)),
+			Max: f32.Pt(float32(h.scrollRange.Max.X), float32(h.scrollRange.Max.Y)),
+		}
+		if h.area != -1 {
+			t := q.areas[h.area].trans
+			r = f32.Rectangle{Min: transformVec(t, r.Min), Max: transformVec(t, r.Max)}.Canon()
+		}
+		sum.Min = sum.Min.Add(r.Min)
+		sum.Max = sum.Max.Add(r.Max)
+	}
+	return sum
+}
+
+// transformVec transforms v by the linear part of t.
+func transformVec(t f32.Affine2D, v f32.Point) f32.Point {
+	return t.Transform(v).Sub(t.Transform(f32.Point{}))
+}
+
+// region returns the region of the area at the window position pos, as
+// reported in pointer.Event.Region.
+func (q *pointerQueue) region(areaIdx int, pos f32.Point) int {
+	if areaIdx == -1 || q.areas[areaIdx].regions == -1 {
+		return 0
+	}
+	return q.regions[q.areas[areaIdx].regions].hit(pos)
 }
 
-func (q *pointerQueue) hit(areaIdx int, p f32.Point) (bool, pointer.Cursor) {
+// hit reports whether p is inside the area and its ancestors, and
+// returns the cursor of the innermost area that sets one. If slack is
+// set, the expansions of areas count as inside.
+func (q *pointerQueue) hit(areaIdx int, p f32.Point, slack bool) (bool, pointer.Cursor) {
 	c := pointer.CursorDefault
 	for areaIdx != -1 {
 		a := &q.areas[areaIdx]
 		if c == pointer.CursorDefault {
 			c = a.cursor
 		}
-		p := a.trans.Invert().Transform(p)
-		if !a.area.Hit(p) {
+		lp := a.invTrans.Transform(p)
+		if !a.area.Hit(lp) && !(slack && lp.In(a.slack)) {
+			return false, c
+		}
+		if a.regions != -1 && q.regions[a.regions].hit(p) == -1 {
+			return false, c
+		}
+		if a.mask != -1 && !q.masks[a.mask].hit(p) {
 			return false, c
 		}
 		areaIdx = a.parent
@@ -508,7 +800,17 @@ func (q *pointerQueue) reset() {
 	}
 	q.hitTree = q.hitTree[:0]
 	q.areas = q.areas[:0]
+	q.expanded = false
+	q.regions = q.regions[:0]
+	for i := range q.masks {
+		// Don't retain the images of earlier frames.
+		q.masks[i] = alphaMask{}
+	}
+	q.masks = q.masks[:0]
 	q.semantic.idsAssigned = false
+	for k := range q.semantic.announce {
+		delete(q.semantic.announce, k)
+	}
 	for k, ids := range q.semantic.contentIDs {
 		for i := len(ids) - 1; i >= 0; i-- {
 			if !ids[i].used {
@@ -604,6 +906,7 @@ func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 	}
 	pidx := q.pointerOf(e)
 	p := &q.pointers[pidx]
+	e.Predicted = p.predict(e, q.prediction)
 	p.last = e
 
 	switch e.Type {
@@ -628,6 +931,10 @@ func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 	case pointer.Scroll:
 		q.deliverEnterLeaveEvents(p, events, e)
 		q.deliverScrollEvent(p, events, e)
+	case pointer.Leave:
+		// The pointer left the window, or a pen left the proximity
+		// of the display.
+		q.deliverEnterLeaveEvents(p, events, e)
 	default:
 		panic("unsupported pointer event type")
 	}
@@ -638,6 +945,49 @@ func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 	}
 }
 
+// predict records the position of e and returns the position extrapolated
+// ahead from the average velocity of the recent positions.
+func (p *pointerInfo) predict(e pointer.Event, ahead time.Duration) f32.Point {
+	switch e.Type {
+	case pointer.Press:
+		p.nmotion = 0
+	case pointer.Move:
+	default:
+		return e.Position
+	}
+	if p.nmotion == motionSamples {
+		copy(p.motion[:], p.motion[1:])
+		p.nmotion--
+	}
+	p.motion[p.nmotion] = motionSample{pos: e.Position, t: e.Time}
+	p.nmotion++
+	if ahead <= 0 {
+		return e.Position
+	}
+	first := p.motion[p.nmotion-1]
+	for _, s := range p.motion[:p.nmotion] {
+		if e.Time-s.t <= motionWindow {
+			first = s
+			break
+		}
+	}
+	dt := e.Time - first.t
+	if dt <= 0 {
+		return e.Position
+	}
+	v := e.Position.Sub(first.pos).Mul(float32(ahead) / float32(dt))
+	return e.Position.Add(v)
+}
+
+// localEvent returns e with its positions in the coordinates of the
+// area.
+func (q *pointerQueue) localEvent(areaIdx int, e pointer.Event) pointer.Event {
+	e.Region = q.region(areaIdx, e.Position)
+	e.Position = q.invTransform(areaIdx, e.Position)
+	e.Predicted = q.invTransform(areaIdx, e.Predicted)
+	return e
+}
+
 func (q *pointerQueue) deliverEvent(p *pointerInfo, events *handlerEvents, e pointer.Event) {
 	foremost := true
 	if p.pressed && len(p.handlers) == 1 {
@@ -654,8 +1004,7 @@ func (q *pointerQueue) deliverEvent(p *pointerInfo, events *handlerEvents, e poi
 			foremost = false
 			e.Priority = pointer.Foremost
 		}
-		e.Position = q.invTransform(h.area, e.Position)
-		events.Add(k, e)
+		events.Add(k, q.localEvent(h.area, e))
 	}
 }
 
@@ -665,30 +1014,64 @@ func (q *pointerQueue) deliverScrollEvent(p *pointerInfo, events *handlerEvents,
 		e.Priority = pointer.Grabbed
 		foremost = false
 	}
-	var sx, sy = e.Scroll.X, e.Scroll.Y
+	// Remaining scroll amount, in window coordinates.
+	scroll := e.Scroll
+	wheel := e.Source == pointer.Mouse
+	if wheel && scroll.X == 0 && e.Modifiers.Contain(key.ModShift) {
+		// By convention, Shift turns vertical wheel scrolling
+		// horizontal.
+		scroll = f32.Point{X: scroll.Y}
+	}
 	for _, k := range p.handlers {
-		if sx == 0 && sy == 0 {
+		if scroll == (f32.Point{}) {
 			return
 		}
 		h := q.handlers[k]
-		// Distribute the scroll to the handler based on its ScrollRange.
-		sx, e.Scroll.X = setScrollEvent(sx, h.scrollRange.Min.X, h.scrollRange.Max.X)
-		sy, e.Scroll.Y = setScrollEvent(sy, h.scrollRange.Min.Y, h.scrollRange.Max.Y)
+		if h.types&pointer.Scroll == 0 || !e.Modifiers.Contain(h.scrollMods) {
+			continue
+		}
+		// Distribute the scroll to the handler based on its ScrollRange,
+		// in the local coordinates of the handler.
+		local := q.invTransformVec(h.area, scroll)
+		r := h.scrollRange
+		// Wheels scroll handlers that only scroll horizontally.
+		horizontal := wheel && local.X == 0 && r.Min.Y == 0 && r.Max.Y == 0 && (r.Min.X != 0 || r.Max.X != 0)
+		if horizontal {
+			local = f32.Point{X: local.Y}
+		}
+		var left f32.Point
+		left.X, e.Scroll.X = setScrollEvent(local.X, r.Min.X, r.Max.X)
+		left.Y, e.Scroll.Y = setScrollEvent(local.Y, r.Min.Y, r.Max.Y)
+		if horizontal {
+			// Leave the remaining scroll vertical for the
+			// handlers below.
+			left = f32.Point{Y: left.X}
+		}
+		if left == (f32.Point{}) {
+			scroll = f32.Point{}
+		} else if h.area != -1 {
+			scroll = transformVec(q.areas[h.area].trans, left)
+		} else {
+			scroll = left
+		}
 		e := e
 		if foremost {
 			foremost = false
 			e.Priority = pointer.Foremost
 		}
-		e.Position = q.invTransform(h.area, e.Position)
-		events.Add(k, e)
+		events.Add(k, q.localEvent(h.area, e))
 	}
 }
 
 func (q *pointerQueue) deliverEnterLeaveEvents(p *pointerInfo, events *handlerEvents, e pointer.Event) {
 	var hits []event.Tag
-	if e.Source != pointer.Mouse && !p.pressed && e.Type != pointer.Press {
-		// Consider non-mouse pointers leaving when they're released.
-	} else {
+	hovers := e.Source == pointer.Mouse || e.Source == pointer.Pen
+	switch {
+	case e.Type == pointer.Leave:
+		// The pointer left every area.
+	case !hovers && !p.pressed && e.Type != pointer.Press:
+		// Consider touch pointers leaving when they're released.
+	default:
 		hits, q.cursor = q.opHit(e.Position)
 		if p.pressed {
 			// Filter out non-participating handlers,
@@ -718,11 +1101,11 @@ func (q *pointerQueue) deliverEnterLeaveEvents(p *pointerInfo, events *handlerEv
 			continue
 		}
 		h := q.handlers[k]
+		e := e
 		e.Type = pointer.Leave
 
 		if e.Type&h.types != 0 {
-			e.Position = q.invTransform(h.area, e.Position)
-			events.Add(k, e)
+			events.Add(k, q.localEvent(h.area, e))
 		}
 	}
 	// Deliver Enter events.
@@ -731,11 +1114,11 @@ func (q *pointerQueue) deliverEnterLeaveEvents(p *pointerInfo, events *handlerEv
 		if _, found := searchTag(p.entered, k); found {
 			continue
 		}
+		e := e
 		e.Type = pointer.Enter
 
 		if e.Type&h.types != 0 {
-			e.Position = q.invTransform(h.area, e.Position)
-			events.Add(k, e)
+			events.Add(k, q.localEvent(h.area, e))
 		}
 	}
 	p.entered = append(p.entered[:0], hits...)
@@ -873,11 +1256,92 @@ func (op *areaOp) Hit(pos f32.Point) bool {
 	}
 }
 
+// bounds returns the bounding box of the area in window
+// coordinates.
 func (a *areaNode) bounds() f32.Rectangle {
-	return f32.Rectangle{
-		Min: a.trans.Transform(a.area.rect.Min),
-		Max: a.trans.Transform(a.area.rect.Max),
+	r := a.area.rect
+	corners := [...]f32.Point{
+		r.Min, r.Max,
+		{X: r.Min.X, Y: r.Max.Y},
+		{X: r.Max.X, Y: r.Min.Y},
+	}
+	p := a.trans.Transform(corners[0])
+	b := f32.Rectangle{Min: p, Max: p}
+	for _, c := range corners[1:] {
+		p := a.trans.Transform(c)
+		if p.X < b.Min.X {
+			b.Min.X = p.X
+		}
+		if p.Y < b.Min.Y {
+			b.Min.Y = p.Y
+		}
+		if p.X > b.Max.X {
+			b.Max.X = p.X
+		}
+		if p.Y > b.Max.Y {
+			b.Max.Y = p.Y
+		}
+	}
+	return b
+}
+
+// Reveal scrolls bounds, in window coordinates, into the bounds of the
+// scroll handlers of the ancestors of the area with index area, nearest
+// first. Each handler scrolls as far as its ScrollRange allows, and
+// bounds are moved by the scroll for the handlers farther out.
+func (q *pointerQueue) Reveal(area int, bounds f32.Rectangle, events *handlerEvents) {
+	for a := area; a != -1; a = q.areas[a].parent {
+		for _, n := range q.hitTree {
+			if n.area != a || n.tag == nil {
+				continue
+			}
+			h := q.handlers[n.tag]
+			if h == nil || h.types&pointer.Scroll == 0 || h.scrollRange == (image.Rectangle{}) {
+				continue
+			}
+			view := q.areas[a].bounds()
+			dist := f32.Point{X: revealDist(bounds.Min.X, bounds.Max.X, view.Min.X, view.Max.X), Y: revealDist(bounds.Min.Y, bounds.Max.Y, view.Min.Y, view.Max.Y)}
+			if dist == (f32.Point{}) {
+				break
+			}
+			local := q.invTransformVec(a, dist)
+			r := h.scrollRange
+			var scroll f32.Point
+			_, scroll.X = setScrollEvent(local.X, r.Min.X, r.Max.X)
+			_, scroll.Y = setScrollEvent(local.Y, r.Min.Y, r.Max.Y)
+			if scroll == (f32.Point{}) {
+				break
+			}
+			e := pointer.Event{
+				Type:     pointer.Scroll,
+				Source:   pointer.Mouse,
+				Priority: pointer.Foremost,
+				Position: q.invTransform(a, view.Min.Add(view.Max).Mul(.5)),
+				Scroll:   scroll,
+			}
+			events.Add(n.tag, e)
+			moved := transformVec(q.areas[a].trans, scroll)
+			bounds = bounds.Sub(moved)
+			break
+		}
+	}
+}
+
+// revealDist returns the distance to scroll the range [min, max] into
+// the range [viewMin, viewMax]. The start of the range is preferred if
+// the range doesn't fit.
+func revealDist(min, max, viewMin, viewMax float32) float32 {
+	switch {
+	case min < viewMin:
+		return min - viewMin
+	case max > viewMax:
+		d := max - viewMax
+		if s := min - viewMin; s < d {
+			d = s
+		}
+		return d
 	}
+	return 0
 }
 
 func setScrollEvent(scroll float32, min, max int) (left, scrolled float32) {
@@ -5,15 +5,19 @@ package router
 import (
 	"fmt"
 	"image"
+	"image/color"
+	"math"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"gioui.org/f32"
 	"gioui.org/gesture"
 	"gioui.org/io/event"
 	"gioui.org/io/key"
 	"gioui.org/io/pointer"
+	"gioui.org/io/semantic"
 	"gioui.org/io/transfer"
 	"gioui.org/op"
 	"gioui.org/op/clip"
@@ -294,6 +298,109 @@ func TestPointerPriority(t *testing.T) {
 	assertScrollEvent(t, hev3[1], f32.Pt(-20, -30))
 }
 
+func TestPointerWheelHorizontal(t *testing.T) {
+	horiz, vert := new(int), new(int)
+	var ops op.Ops
+
+	// A vertical list containing a horizontal-only row.
+	r1 := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{
+		Tag:          vert,
+		Types:        pointer.Scroll,
+		ScrollBounds: image.Rectangle{Min: image.Pt(0, -100), Max: image.Pt(0, 100)},
+	}.Add(&ops)
+	r2 := clip.Rect(image.Rect(0, 0, 100, 50)).Push(&ops)
+	pointer.InputOp{
+		Tag:          horiz,
+		Types:        pointer.Scroll,
+		ScrollBounds: image.Rectangle{Max: image.Pt(30, 0)},
+	}.Add(&ops)
+	r2.Pop()
+	r1.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Events(horiz)
+	r.Events(vert)
+	r.Queue(
+		// Shift+wheel outside the row scrolls horizontally, which the
+		// list can't.
+		pointer.Event{
+			Type:      pointer.Scroll,
+			Source:    pointer.Mouse,
+			Position:  f32.Pt(50, 75),
+			Scroll:    f32.Pt(0, 20),
+			Modifiers: key.ModShift,
+		},
+		// Shift+wheel inside the row.
+		pointer.Event{
+			Type:      pointer.Scroll,
+			Source:    pointer.Mouse,
+			Position:  f32.Pt(50, 25),
+			Scroll:    f32.Pt(0, 20),
+			Modifiers: key.ModShift,
+		},
+		// A plain wheel inside the row scrolls the row, and then the
+		// list with the remaining distance.
+		pointer.Event{
+			Type:     pointer.Scroll,
+			Source:   pointer.Mouse,
+			Position: f32.Pt(50, 25),
+			Scroll:   f32.Pt(0, 50),
+		},
+	)
+	hevs := r.Events(horiz)
+	vevs := r.Events(vert)
+	assertEventPointerTypeSequence(t, hevs, pointer.Scroll, pointer.Scroll)
+	assertEventPointerTypeSequence(t, vevs, pointer.Scroll, pointer.Scroll)
+	assertScrollEvent(t, vevs[0], f32.Pt(0, 0))
+	assertScrollEvent(t, hevs[0], f32.Pt(20, 0))
+	assertScrollEvent(t, hevs[1], f32.Pt(30, 0))
+	assertScrollEvent(t, vevs[1], f32.Pt(0, 20))
+}
+
+func TestPointerPen(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	addPointerHandler(&ops, handler, image.Rect(0, 0, 100, 100))
+	var r Router
+	r.Frame(&ops)
+	r.Events(handler)
+	pen := func(typ pointer.Type, x float32, btns pointer.Buttons) pointer.Event {
+		return pointer.Event{Type: typ, Source: pointer.Pen, Position: f32.Pt(x, 50), Buttons: btns}
+	}
+	for _, tc := range []struct {
+		label string
+		e     pointer.Event
+		types []pointer.Type
+	}{
+		{"proximity", pen(pointer.Move, 50, 0), []pointer.Type{pointer.Enter, pointer.Move}},
+		{"hover", pen(pointer.Move, 60, 0), []pointer.Type{pointer.Move}},
+		{"contact", pen(pointer.Press, 60, pointer.ButtonPrimary), []pointer.Type{pointer.Press}},
+		{"stroke", pen(pointer.Move, 70, pointer.ButtonPrimary), []pointer.Type{pointer.Drag}},
+		{"barrel", pen(pointer.Move, 80, pointer.ButtonPrimary|pointer.ButtonBarrel), []pointer.Type{pointer.Drag}},
+		{"lift", pen(pointer.Release, 80, 0), []pointer.Type{pointer.Release}},
+		{"hover outside", pen(pointer.Move, 150, 0), []pointer.Type{pointer.Leave}},
+		{"hover inside", pen(pointer.Move, 90, 0), []pointer.Type{pointer.Enter, pointer.Move}},
+		{"out of proximity", pen(pointer.Leave, 90, 0), []pointer.Type{pointer.Leave}},
+	} {
+		r.Queue(tc.e)
+		evs := r.Events(handler)
+		if got := pointerTypes(evs); !reflect.DeepEqual(got, tc.types) {
+			t.Errorf("%s: got %v events, expected %v", tc.label, got, tc.types)
+		}
+		for _, e := range evs {
+			e := e.(pointer.Event)
+			if e.Source != pointer.Pen || e.Buttons != tc.e.Buttons {
+				t.Errorf("%s: got %v event from %v with %v, expected pen with %v", tc.label, e.Type, e.Source, e.Buttons, tc.e.Buttons)
+			}
+		}
+	}
+	if n := len(r.pointer.queue.pointers); n != 0 {
+		t.Errorf("%d pointers tracked after the pen left proximity", n)
+	}
+}
+
 func TestPointerEnterLeave(t *testing.T) {
 	handler1 := new(int)
 	handler2 := new(int)
@@ -1109,6 +1216,104 @@ func TestDeferredInputOp(t *testing.T) {
 	r.Frame(&ops)
 }
 
+func TestLayerHitOrder(t *testing.T) {
+	var ops op.Ops
+	var r Router
+
+	layer := func(name string, order int, tag event.Tag) {
+		m := op.Record(&ops)
+		addPointerHandler(&ops, tag, image.Rect(0, 0, 100, 100))
+		op.Layer(&ops, name, order, m.Stop())
+	}
+	top, middle, bottom := new(int), new(int), new(int)
+	layer("top", 2, top)
+	layer("bottom", 0, bottom)
+	layer("middle", 1, middle)
+	r.Frame(&ops)
+	r.Queue(pointer.Event{Type: pointer.Press, Position: f32.Pt(50, 50)})
+	assertEventPointerTypeSequence(t, r.Events(top), pointer.Cancel, pointer.Enter, pointer.Press)
+	assertEventPointerTypeSequence(t, r.Events(middle), pointer.Cancel)
+	assertEventPointerTypeSequence(t, r.Events(bottom), pointer.Cancel)
+
+	// Hiding the top layer reveals the middle layer.
+	op.HideLayer(&ops, "top")
+	r.Frame(&ops)
+	r.Queue(pointer.Event{Type: pointer.Release, Position: f32.Pt(50, 50)})
+	r.Queue(pointer.Event{Type: pointer.Move, Position: f32.Pt(50, 50)})
+	assertEventPointerTypeSequence(t, r.Events(middle), pointer.Enter, pointer.Move)
+	assertEventPointerTypeSequence(t, r.Events(bottom))
+}
+
+func TestPointerOcclusion(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		deferred bool
+		pass     bool
+	}{
+		{name: "sibling"},
+		{name: "deferred", deferred: true},
+		{name: "pass-through", pass: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ops op.Ops
+			list, item, fab := new(int), new(int), new(int)
+			// The floating action button is drawn after the list.
+			layoutFAB := func() {
+				if tc.pass {
+					defer pointer.PassOp{}.Push(&ops).Pop()
+				}
+				addPointerHandler(&ops, fab, image.Rect(60, 160, 90, 190))
+			}
+			if tc.deferred {
+				m := op.Record(&ops)
+				layoutFAB()
+				op.Defer(&ops, m.Stop())
+			}
+			area := clip.Rect(image.Rect(0, 0, 100, 200)).Push(&ops)
+			pointer.InputOp{
+				Tag:          list,
+				Types:        pointer.Press | pointer.Release | pointer.Scroll,
+				ScrollBounds: image.Rect(0, -100, 0, 100),
+			}.Add(&ops)
+			addPointerHandler(&ops, item, image.Rect(0, 150, 100, 200))
+			area.Pop()
+			if !tc.deferred {
+				layoutFAB()
+			}
+
+			var r Router
+			r.Frame(&ops)
+			pos := f32.Pt(70, 170)
+			r.Queue(
+				pointer.Event{Type: pointer.Press, Position: pos},
+				pointer.Event{Type: pointer.Release, Position: pos},
+				pointer.Event{Type: pointer.Scroll, Position: pos, Scroll: f32.Pt(0, 10)},
+			)
+			// The FAB doesn't want scroll events.
+			assertEventPointerTypeSequence(t, r.Events(fab), pointer.Cancel, pointer.Enter, pointer.Press, pointer.Release)
+			if tc.pass {
+				assertEventPointerTypeSequence(t, r.Events(item), pointer.Cancel, pointer.Enter, pointer.Press, pointer.Release)
+				assertEventPointerTypeSequence(t, r.Events(list), pointer.Cancel, pointer.Press, pointer.Release, pointer.Scroll)
+			} else {
+				assertEventPointerTypeSequence(t, r.Events(item), pointer.Cancel)
+				assertEventPointerTypeSequence(t, r.Events(list), pointer.Cancel)
+			}
+			// Outside the FAB, the list and its item receive the events.
+			r.Queue(
+				pointer.Event{Type: pointer.Press, Position: f32.Pt(20, 170)},
+				pointer.Event{Type: pointer.Release, Position: f32.Pt(20, 170)},
+			)
+			if tc.pass {
+				// The item is still entered from the press through the FAB.
+				assertEventPointerTypeSequence(t, r.Events(item), pointer.Press, pointer.Release)
+			} else {
+				assertEventPointerTypeSequence(t, r.Events(item), pointer.Enter, pointer.Press, pointer.Release)
+			}
+			assertEventPointerTypeSequence(t, r.Events(list), pointer.Press, pointer.Release)
+		})
+	}
+}
+
 func TestPassCursor(t *testing.T) {
 	var ops op.Ops
 	var r Router
@@ -1277,3 +1482,668 @@ func BenchmarkRouterAdd(b *testing.B) {
 		})
 	}
 }
+
+func TestPointerRegions(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	regions := wedges(20, f32.Pt(100, 100), 80)
+	// Offset the regions to exercise the transformation.
+	trans := op.Offset(f32.Pt(10, 0)).Push(&ops)
+	area := clip.Rect(image.Rect(0, 0, 200, 200)).Push(&ops)
+	pointer.RegionsOp{Regions: regions}.Add(&ops)
+	pointer.InputOp{Tag: handler, Types: pointer.Press | pointer.Release}.Add(&ops)
+	area.Pop()
+	trans.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Events(handler)
+	press := func(p f32.Point) []event.Event {
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Position: p.Add(f32.Pt(10, 0))},
+			pointer.Event{Type: pointer.Release, Position: p.Add(f32.Pt(10, 0))},
+		)
+		return r.Events(handler)
+	}
+	angle := func(a float64, rad float32) f32.Point {
+		a = a * 2 * math.Pi / 20
+		return f32.Pt(100+rad*float32(math.Cos(a)), 100+rad*float32(math.Sin(a)))
+	}
+	for _, tc := range []struct {
+		name   string
+		pos    f32.Point
+		region int
+	}{
+		{"inside wedge 7", angle(7.5, 40), 7},
+		// The boundary between wedge 7 and 8 belongs to
+		// the topmost wedge.
+		{"boundary of wedge 7 and 8", angle(8, 40), 8},
+		{"outline of wedge 7", angle(7.5, 80), 7},
+	} {
+		events := press(tc.pos)
+		if len(events) != 2 {
+			t.Errorf("%s: got %d events; expected 2", tc.name, len(events))
+			continue
+		}
+		for _, e := range events {
+			if got := e.(pointer.Event).Region; got != tc.region {
+				t.Errorf("%s: got region %d; expected %d", tc.name, got, tc.region)
+			}
+		}
+	}
+	// Positions outside every region miss the area.
+	if events := press(f32.Pt(5, 5)); len(events) != 0 {
+		t.Errorf("got events outside every region: %v", events)
+	}
+}
+
+func TestPointerMask(t *testing.T) {
+	below, pin := new(int), new(int)
+	// The left half of the mask is opaque, the right half is
+	// translucent.
+	mask := image.NewAlpha(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			a := uint8(0xff)
+			if x >= 10 {
+				a = 0x40
+			}
+			mask.SetAlpha(x, y, color.Alpha{A: a})
+		}
+	}
+	var ops op.Ops
+	area := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{Tag: below, Types: pointer.Press}.Add(&ops)
+	area.Pop()
+	// Offset and scale the mask to exercise the transformation.
+	trans := op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(2, 2)).Offset(f32.Pt(20, 20))).Push(&ops)
+	area = clip.Rect(image.Rect(0, 0, 30, 30)).Push(&ops)
+	pointer.MaskOp{Mask: mask, Threshold: 0x80}.Add(&ops)
+	pointer.InputOp{Tag: pin, Types: pointer.Press}.Add(&ops)
+	area.Pop()
+	trans.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Events(below)
+	r.Events(pin)
+	for _, tc := range []struct {
+		name string
+		pos  f32.Point
+		tag  event.Tag
+	}{
+		{"opaque", f32.Pt(30, 30), pin},
+		// Translucent pixels below the threshold fall through.
+		{"translucent", f32.Pt(50, 30), below},
+		// Positions outside the mask miss the area.
+		{"outside mask", f32.Pt(70, 70), below},
+	} {
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Position: tc.pos},
+			pointer.Event{Type: pointer.Release, Position: tc.pos},
+		)
+		for _, tag := range []event.Tag{below, pin} {
+			n := len(r.Events(tag))
+			if tag == tc.tag && n != 1 {
+				t.Errorf("%s: got %d events; expected a press", tc.name, n)
+			} else if tag != tc.tag && n != 0 {
+				t.Errorf("%s: got %d events for the wrong handler", tc.name, n)
+			}
+		}
+	}
+	if hits := r.HitTest(f32.Pt(50, 30)); len(hits) != 1 || hits[0].Tag != below {
+		t.Errorf("got hits %v at a translucent pixel; expected the area below", hits)
+	}
+}
+
+// wedges returns n regions in the form of wedges of a circle.
+func wedges(n int, center f32.Point, radius float32) [][]f32.Point {
+	const arcPoints = 4
+	regions := make([][]f32.Point, n)
+	for i := range regions {
+		poly := []f32.Point{center}
+		for j := 0; j <= arcPoints; j++ {
+			a := (float64(i) + float64(j)/arcPoints) * 2 * math.Pi / float64(n)
+			poly = append(poly, center.Add(f32.Pt(radius*float32(math.Cos(a)), radius*float32(math.Sin(a)))))
+		}
+		regions[i] = poly
+	}
+	return regions
+}
+
+func BenchmarkPointerRegions(b *testing.B) {
+	const n = 1000
+	regions := wedges(n, f32.Pt(500, 500), 400)
+	b.Run("RegionsOp", func(b *testing.B) {
+		handler := new(int)
+		var ops op.Ops
+		var r Router
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ops.Reset()
+			area := clip.Rect(image.Rect(0, 0, 1000, 1000)).Push(&ops)
+			pointer.RegionsOp{Regions: regions}.Add(&ops)
+			pointer.InputOp{Tag: handler, Types: pointer.Move}.Add(&ops)
+			area.Pop()
+			r.Frame(&ops)
+			r.Queue(pointer.Event{Type: pointer.Move, Position: f32.Pt(600, 550)})
+			r.Events(handler)
+		}
+	})
+	b.Run("InputOps", func(b *testing.B) {
+		handlers := make([]int, n)
+		var ops op.Ops
+		var r Router
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ops.Reset()
+			for j, reg := range regions {
+				var p clip.Path
+				p.Begin(&ops)
+				p.MoveTo(reg[0])
+				for _, pt := range reg[1:] {
+					p.LineTo(pt)
+				}
+				p.Close()
+				area := clip.Outline{Path: p.End()}.Op().Push(&ops)
+				pointer.InputOp{Tag: &handlers[j], Types: pointer.Move}.Add(&ops)
+				area.Pop()
+			}
+			r.Frame(&ops)
+			r.Queue(pointer.Event{Type: pointer.Move, Position: f32.Pt(600, 550)})
+			for j := range handlers {
+				r.Events(&handlers[j])
+			}
+		}
+	})
+}
+
+func TestHitTest(t *testing.T) {
+	a, b, c := new(int), new(int), new(int)
+	for _, tc := range []struct {
+		name  string
+		build func(ops *op.Ops)
+		pos   f32.Point
+		hits  []event.Tag
+	}{
+		{
+			name: "overlapping",
+			build: func(ops *op.Ops) {
+				addPointerHandler(ops, a, image.Rect(0, 0, 100, 100))
+				addPointerHandler(ops, b, image.Rect(50, 50, 150, 150))
+			},
+			pos:  f32.Pt(75, 75),
+			hits: []event.Tag{b},
+		},
+		{
+			name: "nested",
+			build: func(ops *op.Ops) {
+				defer clip.Rect(image.Rect(0, 0, 100, 100)).Push(ops).Pop()
+				pointer.InputOp{Tag: a, Types: pointer.Press}.Add(ops)
+				addPointerHandler(ops, b, image.Rect(25, 25, 75, 75))
+			},
+			pos:  f32.Pt(50, 50),
+			hits: []event.Tag{b, a},
+		},
+		{
+			name: "transformed",
+			build: func(ops *op.Ops) {
+				defer op.Offset(f32.Pt(100, 0)).Push(ops).Pop()
+				defer op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(2, 2))).Push(ops).Pop()
+				addPointerHandler(ops, a, image.Rect(0, 0, 10, 10))
+			},
+			pos:  f32.Pt(115, 15),
+			hits: []event.Tag{a},
+		},
+		{
+			name: "clip shape",
+			build: func(ops *op.Ops) {
+				addPointerHandler(ops, a, image.Rect(0, 0, 100, 100))
+				cl := clip.Ellipse(f32.Rect(0, 0, 100, 100)).Push(ops)
+				pointer.InputOp{Tag: b, Types: pointer.Press}.Add(ops)
+				cl.Pop()
+			},
+			// Inside the bounds of the ellipse, but outside its shape.
+			pos:  f32.Pt(10, 10),
+			hits: []event.Tag{a},
+		},
+		{
+			name: "z-order",
+			build: func(ops *op.Ops) {
+				addPointerHandler(ops, a, image.Rect(0, 0, 100, 100))
+				pass := pointer.PassOp{}.Push(ops)
+				addPointerHandler(ops, b, image.Rect(0, 0, 100, 100))
+				addPointerHandler(ops, c, image.Rect(0, 0, 100, 100))
+				pass.Pop()
+			},
+			pos:  f32.Pt(50, 50),
+			hits: []event.Tag{c, b, a},
+		},
+		{
+			name: "pass-through",
+			build: func(ops *op.Ops) {
+				addPointerHandler(ops, a, image.Rect(0, 0, 100, 100))
+				addPointerHandler(ops, b, image.Rect(0, 0, 100, 100))
+				defer pointer.PassOp{}.Push(ops).Pop()
+				addPointerHandler(ops, c, image.Rect(0, 0, 100, 100))
+			},
+			pos:  f32.Pt(50, 50),
+			hits: []event.Tag{c, b},
+		},
+		{
+			name: "hidden layer",
+			build: func(ops *op.Ops) {
+				addPointerHandler(ops, a, image.Rect(0, 0, 100, 100))
+				m := op.Record(ops)
+				addPointerHandler(ops, b, image.Rect(0, 0, 100, 100))
+				op.Layer(ops, "top", 1, m.Stop())
+				op.HideLayer(ops, "top")
+			},
+			pos:  f32.Pt(50, 50),
+			hits: []event.Tag{a},
+		},
+		{
+			name: "miss",
+			build: func(ops *op.Ops) {
+				addPointerHandler(ops, a, image.Rect(0, 0, 100, 100))
+			},
+			pos: f32.Pt(150, 50),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ops op.Ops
+			var r Router
+			tc.build(&ops)
+			r.Frame(&ops)
+			var got []event.Tag
+			for _, h := range r.HitTest(tc.pos) {
+				if h.Tag != nil {
+					got = append(got, h.Tag)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.hits) {
+				t.Errorf("got hits %v, expected %v", got, tc.hits)
+			}
+			// HitTest must agree with event delivery, and have no side
+			// effects.
+			for _, tag := range []event.Tag{a, b, c} {
+				r.Events(tag)
+			}
+			r.Queue(pointer.Event{Type: pointer.Press, Position: tc.pos})
+			for _, tag := range []event.Tag{a, b, c} {
+				pressed := false
+				for _, e := range r.Events(tag) {
+					if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+						pressed = true
+					}
+				}
+				hit := false
+				for _, h := range got {
+					hit = hit || h == tag
+				}
+				if pressed != hit {
+					t.Errorf("handler %p: pressed %v, hit %v", tag, pressed, hit)
+				}
+			}
+		})
+	}
+}
+
+func TestHitTestBounds(t *testing.T) {
+	var ops op.Ops
+	var r Router
+	tag := new(int)
+	op.Offset(f32.Pt(10, 20)).Add(&ops)
+	cl := clip.Rect(image.Rect(0, 0, 30, 40)).Push(&ops)
+	semantic.LabelOp("label").Add(&ops)
+	addPointerHandler(&ops, tag, image.Rect(0, 0, 5, 5))
+	cl.Pop()
+	r.Frame(&ops)
+
+	hits := r.HitTest(f32.Pt(12, 22))
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits, expected 2", len(hits))
+	}
+	h := hits[0]
+	if h.Tag != tag {
+		t.Errorf("got top hit tag %v, expected the handler", h.Tag)
+	}
+	if exp := f32.Rect(10, 20, 15, 25); h.Bounds != exp {
+		t.Errorf("got handler bounds %v, expected %v", h.Bounds, exp)
+	}
+	if p := h.Transform.Transform(f32.Point{}); p != f32.Pt(10, 20) {
+		t.Errorf("handler transform maps the origin to %v", p)
+	}
+	h = hits[1]
+	if h.Tag != nil || h.Semantic == 0 || h.Semantic == hits[0].Semantic || h.Bounds != f32.Rect(10, 20, 40, 60) {
+		t.Errorf("got hit %+v, expected the labeled area", h)
+	}
+}
+
+func TestExpandOp(t *testing.T) {
+	var ops op.Ops
+
+	bg, small, neighbour := new(int), new(int), new(int)
+	addPointerHandler(&ops, bg, image.Rect(0, 0, 200, 200))
+	// A 20x20 handler expanded to 60x60, next to a real handler to its
+	// right.
+	addPointerHandler(&ops, neighbour, image.Rect(110, 40, 130, 60))
+	ex := pointer.ExpandOp{Min: image.Pt(60, 60)}.Push(&ops)
+	addPointerHandler(&ops, small, image.Rect(80, 40, 100, 60))
+	ex.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	for _, tc := range []struct {
+		pos f32.Point
+		tag event.Tag
+	}{
+		// Inside the real area.
+		{f32.Pt(90, 50), small},
+		// Inside the expansion, over the enclosing background.
+		{f32.Pt(70, 50), small},
+		{f32.Pt(90, 25), small},
+		// Inside the expansion, over the real neighbour.
+		{f32.Pt(115, 50), neighbour},
+		// Outside the expansion.
+		{f32.Pt(40, 50), bg},
+	} {
+		r.Events(bg)
+		r.Events(small)
+		r.Events(neighbour)
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Position: tc.pos},
+			pointer.Event{Type: pointer.Release, Position: tc.pos},
+		)
+		for _, tag := range []event.Tag{small, neighbour} {
+			got := false
+			for _, e := range r.Events(tag) {
+				if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+					got = true
+				}
+			}
+			if exp := tag == tc.tag; got != exp {
+				t.Errorf("%v: handler %p pressed: %v, expected %v", tc.pos, tag, got, exp)
+			}
+		}
+	}
+}
+
+func TestInputOpMargin(t *testing.T) {
+	// A margin of 10 pixels expands the 20x20 areas like an ExpandOp
+	// to 40x40.
+	for _, expand := range []bool{false, true} {
+		var ops op.Ops
+
+		h1, h2 := new(int), new(int)
+		for _, h := range []struct {
+			tag  event.Tag
+			area image.Rectangle
+		}{
+			{h1, image.Rect(20, 20, 40, 40)},
+			{h2, image.Rect(50, 20, 70, 40)},
+		} {
+			in := pointer.InputOp{Tag: h.tag, Types: pointer.Press, Margin: 10}
+			if expand {
+				in.Margin = 0
+				ex := pointer.ExpandOp{Min: image.Pt(40, 40)}.Push(&ops)
+				cl := clip.Rect(h.area).Push(&ops)
+				in.Add(&ops)
+				cl.Pop()
+				ex.Pop()
+				continue
+			}
+			cl := clip.Rect(h.area).Push(&ops)
+			in.Add(&ops)
+			cl.Pop()
+		}
+
+		var r Router
+		r.Frame(&ops)
+		for _, tc := range []struct {
+			pos f32.Point
+			tag event.Tag
+		}{
+			// Inside the margin of h1 only.
+			{f32.Pt(15, 30), h1},
+			{f32.Pt(30, 45), h1},
+			// Outside every margin.
+			{f32.Pt(5, 30), nil},
+			// Inside the real area of h1, and the margin of h2.
+			{f32.Pt(38, 30), h1},
+			// Inside both margins, where the topmost wins.
+			{f32.Pt(45, 30), h2},
+		} {
+			r.Events(h1)
+			r.Events(h2)
+			r.Queue(pointer.Event{Type: pointer.Press, Position: tc.pos})
+			for _, tag := range []event.Tag{h1, h2} {
+				got := len(r.Events(tag)) > 0
+				if exp := tag == tc.tag; got != exp {
+					t.Errorf("expand %v, %v: handler %p pressed: %v, expected %v", expand, tc.pos, tag, got, exp)
+				}
+			}
+			r.Queue(pointer.Event{Type: pointer.Release, Position: tc.pos})
+		}
+	}
+}
+
+func TestPointerPrediction(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	// Scale the handler coordinates to check that predictions are
+	// transformed like positions.
+	trans := op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(2, 2))).Push(&ops)
+	clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{Tag: handler, Types: pointer.Press | pointer.Drag | pointer.Release}.Add(&ops)
+	trans.Pop()
+
+	var r Router
+	r.SetPrediction(20 * time.Millisecond)
+	r.Frame(&ops)
+	// Drag to the right at 1 pixel per millisecond.
+	r.Queue(pointer.Event{Type: pointer.Press, Position: f32.Pt(10, 10)})
+	for i := 1; i <= 6; i++ {
+		r.Queue(pointer.Event{
+			Type:     pointer.Move,
+			Position: f32.Pt(10+float32(i)*10, 10),
+			Time:     time.Duration(i) * 10 * time.Millisecond,
+		})
+	}
+	r.Queue(pointer.Event{Type: pointer.Release, Position: f32.Pt(70, 10), Time: 70 * time.Millisecond})
+	var drags []pointer.Event
+	for _, e := range r.Events(handler) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press, pointer.Release:
+			if e.Predicted != e.Position {
+				t.Errorf("%v predicted at %v; expected its position %v", e.Type, e.Predicted, e.Position)
+			}
+		case pointer.Drag:
+			drags = append(drags, e)
+		}
+	}
+	if len(drags) != 6 {
+		t.Fatalf("got %d drags; expected 6", len(drags))
+	}
+	// The first drag predicts from the press, which is untimed; drags
+	// after predict 20 pixels ahead, 10 in handler coordinates.
+	for _, e := range drags[1:] {
+		if want := e.Position.Add(f32.Pt(10, 0)); e.Predicted != want {
+			t.Errorf("drag at %v predicted at %v; expected %v", e.Position, e.Predicted, want)
+		}
+	}
+
+	// Disabling prediction reports the positions.
+	r.SetPrediction(0)
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Position: f32.Pt(10, 10)},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 10), Time: 10 * time.Millisecond},
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(30, 10), Time: 20 * time.Millisecond},
+	)
+	for _, e := range r.Events(handler) {
+		if e, ok := e.(pointer.Event); ok && e.Predicted != e.Position {
+			t.Errorf("%v predicted at %v without prediction", e.Type, e.Predicted)
+		}
+	}
+}
+
+func TestPointerRotated(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	// A 100x100 square rotated 45° around its center at (50, 50).
+	rot := f32.Affine2D{}.Rotate(f32.Pt(50, 50), math.Pi/4)
+	trans := op.Affine(rot).Push(&ops)
+	addPointerHandler(&ops, handler, image.Rect(0, 0, 100, 100))
+	trans.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Events(handler)
+	for _, tc := range []struct {
+		pos f32.Point
+		hit bool
+	}{
+		{pos: f32.Pt(50, 50), hit: true},
+		// Inside the rotated square, outside the unrotated square.
+		{pos: f32.Pt(50, -15), hit: true},
+		{pos: f32.Pt(115, 50), hit: true},
+		// Corners of the axis aligned square that are outside
+		// the rotated square.
+		{pos: f32.Pt(2, 2), hit: false},
+		{pos: f32.Pt(98, 98), hit: false},
+	} {
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Position: tc.pos},
+			pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: tc.pos},
+		)
+		var hit bool
+		for _, e := range r.Events(handler) {
+			if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+				hit = true
+			}
+		}
+		if hit != tc.hit {
+			t.Errorf("press at %v: got hit %v; expected %v", tc.pos, hit, tc.hit)
+		}
+		r.Frame(&ops)
+	}
+
+	// Drag along the local x axis, which is diagonal in window
+	// coordinates.
+	start := f32.Pt(50, 50)
+	end := start.Add(rot.Transform(f32.Pt(20, 0)).Sub(rot.Transform(f32.Point{})))
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Position: start},
+		pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Position: end},
+	)
+	var positions []f32.Point
+	for _, e := range r.Events(handler) {
+		if e, ok := e.(pointer.Event); ok && (e.Type == pointer.Press || e.Type == pointer.Drag) {
+			positions = append(positions, e.Position)
+		}
+	}
+	if len(positions) != 2 {
+		t.Fatalf("got %d press and drag events; expected 2", len(positions))
+	}
+	delta := positions[1].Sub(positions[0])
+	if !approxPoint(delta, f32.Pt(20, 0)) {
+		t.Errorf("got local drag delta %v; expected %v", delta, f32.Pt(20, 0))
+	}
+}
+
+func TestPointerScrollRotated(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	// Rotate 90°, mapping the local y axis to the window x axis.
+	rot := f32.Affine2D{}.Rotate(f32.Point{}, math.Pi/2)
+	trans := op.Affine(rot).Push(&ops)
+	cl := clip.Rect(image.Rect(0, -100, 100, 0)).Push(&ops)
+	pointer.InputOp{
+		Tag:          handler,
+		Types:        pointer.Scroll,
+		ScrollBounds: image.Rect(-100, -100, 100, 100),
+	}.Add(&ops)
+	cl.Pop()
+	trans.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Events(handler)
+	r.Queue(pointer.Event{
+		Type:     pointer.Scroll,
+		Source:   pointer.Mouse,
+		Position: f32.Pt(50, 50),
+		Scroll:   f32.Pt(10, 0),
+	})
+	var scroll f32.Point
+	for _, e := range r.Events(handler) {
+		if e, ok := e.(pointer.Event); ok && e.Type == pointer.Scroll {
+			scroll = e.Scroll
+		}
+	}
+	if !approxPoint(scroll, f32.Pt(0, -10)) {
+		t.Errorf("got local scroll %v; expected %v", scroll, f32.Pt(0, -10))
+	}
+}
+
+func TestPointerScrollModifiers(t *testing.T) {
+	scroller, zoomer := new(int), new(int)
+	var ops op.Ops
+	cl := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{
+		Tag:          scroller,
+		Types:        pointer.Scroll,
+		ScrollBounds: image.Rect(0, -100, 0, 100),
+	}.Add(&ops)
+	pointer.InputOp{
+		Tag:             zoomer,
+		Types:           pointer.Scroll,
+		ScrollBounds:    image.Rect(0, -100, 0, 100),
+		ScrollModifiers: key.ModCtrl,
+	}.Add(&ops)
+	cl.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Events(scroller)
+	r.Events(zoomer)
+	scrolls := func(tag event.Tag) int {
+		n := 0
+		for _, e := range r.Events(tag) {
+			if e, ok := e.(pointer.Event); ok && e.Type == pointer.Scroll {
+				n++
+			}
+		}
+		return n
+	}
+	for _, tc := range []struct {
+		mods             key.Modifiers
+		scroller, zoomer int
+	}{
+		{0, 1, 0},
+		{key.ModShift, 1, 0},
+		{key.ModCtrl, 0, 1},
+		{key.ModCtrl | key.ModAlt, 0, 1},
+	} {
+		r.Queue(pointer.Event{
+			Type:      pointer.Scroll,
+			Source:    pointer.Mouse,
+			Position:  f32.Pt(50, 50),
+			Scroll:    f32.Pt(0, 10),
+			Modifiers: tc.mods,
+		})
+		if got, got2 := scrolls(scroller), scrolls(zoomer); got != tc.scroller || got2 != tc.zoomer {
+			t.Errorf("scroll with %v: got %d, %d events, expected %d, %d", tc.mods, got, got2, tc.scroller, tc.zoomer)
+		}
+	}
+}
+
+func approxPoint(p1, p2 f32.Point) bool {
+	const eps = 1e-3
+	d := p1.Sub(p2)
+	return -eps < d.X && d.X < eps && -eps < d.Y && d.Y < eps
+}
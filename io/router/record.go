@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+)
+
+// Recording is a log of the events queued to a Router and of its frames,
+// for reproducing input order bugs with Replay. Record a Router with
+// Router.Record, and save the log with MarshalText.
+type Recording struct {
+	Entries []RecordEntry
+
+	start time.Time
+	now   func() time.Time
+}
+
+// RecordEntry is a queued event or a frame of a Recording.
+type RecordEntry struct {
+	// Seq is the sequence number of the entry.
+	Seq int
+	// Time is the time of the entry since the start of the recording.
+	Time time.Duration
+	// Event is the queued event, or nil for frames.
+	Event event.Event
+	// Checkpoint is the state of the Router after a frame.
+	Checkpoint Checkpoint
+}
+
+// Checkpoint is the recorded state of a Router after a frame.
+type Checkpoint struct {
+	// Focus is the index of the focused key handler in the order of
+	// declaration, or -1 for no focus.
+	Focus int
+	// Delivered is the number of events delivered by Events between
+	// the frame and the frame before it.
+	Delivered int
+}
+
+// Divergence describes a replayed frame whose state differs from the
+// recorded checkpoint.
+type Divergence struct {
+	Seq       int
+	Want, Got Checkpoint
+}
+
+// recordingHeader identifies the text format of a Recording.
+const recordingHeader = "gio-events 1"
+
+// Record starts appending the queued events and frames of q to rec. Only
+// pointer, key, edit, snippet, selection and clipboard events are
+// recorded. Record(nil) stops recording.
+func (q *Router) Record(rec *Recording) {
+	if rec != nil {
+		rec.now = q.Now
+		if rec.start.IsZero() {
+			rec.start = rec.now()
+		}
+	}
+	q.rec = rec
+}
+
+func (r *Recording) add(e RecordEntry) {
+	e.Seq = len(r.Entries)
+	e.Time = r.now().Sub(r.start)
+	r.Entries = append(r.Entries, e)
+}
+
+// recordEvent records e if it has a text form.
+func (r *Recording) recordEvent(e event.Event) {
+	switch e.(type) {
+	case pointer.Event, key.Event, key.EditEvent, key.SnippetEvent, key.SelectionEvent, clipboard.Event:
+		r.add(RecordEntry{Event: e})
+	}
+}
+
+// checkpoint returns the state of q after a frame.
+func (q *Router) checkpoint() Checkpoint {
+	c := Checkpoint{Focus: -1, Delivered: q.stats.Processed}
+	if f := q.key.queue.focus; f != nil {
+		if h, ok := q.key.queue.handlers[f]; ok {
+			c.Focus = h.order
+		}
+	}
+	return c
+}
+
+// Replay queues the recorded events to q, and calls layout to fill the
+// operations of every recorded frame. Layout is expected to lay out the
+// program like it did during the recording, with q as its event.Queue.
+// Frames whose state differs from the recorded checkpoint don't stop the
+// replay, so that programs with slightly changed layouts can still be
+// replayed; they are reported as Divergences.
+func (r *Recording) Replay(q *Router, layout func(ops *op.Ops)) []Divergence {
+	var (
+		ops  op.Ops
+		divs []Divergence
+	)
+	for _, e := range r.Entries {
+		if e.Event != nil {
+			q.Queue(e.Event)
+			continue
+		}
+		ops.Reset()
+		layout(&ops)
+		q.Frame(&ops)
+		if got := q.checkpoint(); got != e.Checkpoint {
+			divs = append(divs, Divergence{Seq: e.Seq, Want: e.Checkpoint, Got: got})
+		}
+	}
+	return divs
+}
+
+func (d Divergence) String() string {
+	var diffs []string
+	if d.Want.Focus != d.Got.Focus {
+		diffs = append(diffs, fmt.Sprintf("focus %d, recorded %d", d.Got.Focus, d.Want.Focus))
+	}
+	if d.Want.Delivered != d.Got.Delivered {
+		diffs = append(diffs, fmt.Sprintf("%d events delivered, recorded %d", d.Got.Delivered, d.Want.Delivered))
+	}
+	return fmt.Sprintf("frame %d: %s", d.Seq, strings.Join(diffs, ", "))
+}
+
+// MarshalText encodes the recording in a compact text format of one entry
+// per line.
+func (r *Recording) MarshalText() ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, recordingHeader)
+	for _, e := range r.Entries {
+		if e.Event == nil {
+			c := e.Checkpoint
+			fmt.Fprintf(&b, "f %d %d %d %d\n", e.Seq, e.Time, c.Focus, c.Delivered)
+			continue
+		}
+		fmt.Fprintf(&b, "e %d %d ", e.Seq, e.Time)
+		switch ev := e.Event.(type) {
+		case pointer.Event:
+			fmt.Fprintf(&b, "pointer %d %d %d %d %d %d %g %g %g %g %d %d\n",
+				ev.Type, ev.Source, ev.PointerID, ev.Priority, ev.Time, ev.Buttons,
+				ev.Position.X, ev.Position.Y, ev.Scroll.X, ev.Scroll.Y, ev.Modifiers, ev.Region)
+		case key.Event:
+			fmt.Fprintf(&b, "key %q %d %d\n", ev.Name, ev.Modifiers, ev.State)
+		case key.EditEvent:
+			fmt.Fprintf(&b, "edit %d %d %q\n", ev.Range.Start, ev.Range.End, ev.Text)
+		case key.SnippetEvent:
+			fmt.Fprintf(&b, "snippet %d %d\n", ev.Start, ev.End)
+		case key.SelectionEvent:
+			fmt.Fprintf(&b, "selection %d %d\n", ev.Start, ev.End)
+		case clipboard.Event:
+			fmt.Fprintf(&b, "clipboard %q\n", ev.Text)
+		default:
+			return nil, fmt.Errorf("router: unsupported event %T in recording", ev)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalText decodes a recording encoded by MarshalText.
+func (r *Recording) UnmarshalText(data []byte) error {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	if !s.Scan() || s.Text() != recordingHeader {
+		return fmt.Errorf("router: missing recording header %q", recordingHeader)
+	}
+	var entries []RecordEntry
+	for line := 2; s.Scan(); line++ {
+		e, err := parseEntry(s.Text())
+		if err != nil {
+			return fmt.Errorf("router: recording line %d: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	r.Entries = entries
+	return nil
+}
+
+func parseEntry(line string) (RecordEntry, error) {
+	var e RecordEntry
+	f := strings.Fields(line)
+	if len(f) < 3 {
+		return e, fmt.Errorf("short entry %q", line)
+	}
+	if f[0] == "f" {
+		c := &e.Checkpoint
+		_, err := fmt.Sscanf(line, "f %d %d %d %d", &e.Seq, &e.Time, &c.Focus, &c.Delivered)
+		return e, err
+	}
+	if f[0] != "e" || len(f) < 4 {
+		return e, fmt.Errorf("unknown entry %q", line)
+	}
+	if _, err := fmt.Sscanf(line, "e %d %d", &e.Seq, &e.Time); err != nil {
+		return e, err
+	}
+	// Skip the sequence number, time and the event kind.
+	args := strings.SplitN(line, " ", 5)
+	rest := ""
+	if len(args) == 5 {
+		rest = args[4]
+	}
+	var err error
+	switch kind := f[3]; kind {
+	case "pointer":
+		var ev pointer.Event
+		_, err = fmt.Sscanf(rest, "%d %d %d %d %d %d %g %g %g %g %d %d",
+			&ev.Type, &ev.Source, &ev.PointerID, &ev.Priority, &ev.Time, &ev.Buttons,
+			&ev.Position.X, &ev.Position.Y, &ev.Scroll.X, &ev.Scroll.Y, &ev.Modifiers, &ev.Region)
+		e.Event = ev
+	case "key":
+		var ev key.Event
+		_, err = fmt.Sscanf(rest, "%q %d %d", &ev.Name, &ev.Modifiers, &ev.State)
+		e.Event = ev
+	case "edit":
+		var ev key.EditEvent
+		_, err = fmt.Sscanf(rest, "%d %d %q", &ev.Range.Start, &ev.Range.End, &ev.Text)
+		e.Event = ev
+	case "snippet":
+		var ev key.SnippetEvent
+		_, err = fmt.Sscanf(rest, "%d %d", &ev.Start, &ev.End)
+		e.Event = ev
+	case "selection":
+		var ev key.SelectionEvent
+		_, err = fmt.Sscanf(rest, "%d %d", &ev.Start, &ev.End)
+		e.Event = ev
+	case "clipboard":
+		var ev clipboard.Event
+		_, err = fmt.Sscanf(rest, "%q", &ev.Text)
+		e.Event = ev
+	default:
+		err = fmt.Errorf("unknown event kind %q", kind)
+	}
+	return e, err
+}
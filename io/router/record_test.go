@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+// focusFields returns a layout of two stacked 100x20 fields, the first at
+// the top unless swapped. A field takes the focus when pressed, and Tab
+// moves the focus to the other field.
+func focusFields(q *Router, tags *[2]int, swapped bool) func(ops *op.Ops) {
+	return func(ops *op.Ops) {
+		for i := range tags {
+			tag := &tags[i]
+			other := &tags[1-i]
+			y := i * 20
+			if swapped {
+				y = 20 - y
+			}
+			for _, e := range q.Events(tag) {
+				switch e := e.(type) {
+				case pointer.Event:
+					if e.Type == pointer.Press {
+						key.FocusOp{Tag: tag}.Add(ops)
+					}
+				case key.Event:
+					if e.Name == key.NameTab && e.State == key.Press {
+						key.FocusOp{Tag: other}.Add(ops)
+					}
+				}
+			}
+			area := clip.Rect(image.Rect(0, y, 100, y+20)).Push(ops)
+			pointer.InputOp{Tag: tag, Types: pointer.Press}.Add(ops)
+			key.InputOp{Tag: tag}.Add(ops)
+			area.Pop()
+		}
+	}
+}
+
+// focusOrderLog is a recording of a press on the second field of
+// focusFields, with a Tab press queued before the frame that moves the
+// focus to the field. The early Tab must not move the focus away from
+// the pressed field; the later one must.
+const focusOrderLog = `gio-events 1
+f 0 42540 -1 0
+e 1 43325 pointer 1 0 0 0 0 1 50 30 0 0 0 0
+e 2 46835 key "⇥" 0 0
+e 3 47671 pointer 2 0 0 0 0 0 50 30 0 0 0 0
+f 4 57763 1 4
+f 5 60490 1 2
+e 6 60593 key "⇥" 0 0
+e 7 60917 key "⇥" 0 1
+f 8 63193 0 2
+f 9 65166 0 0
+`
+
+func TestReplayFocusOrder(t *testing.T) {
+	var rec Recording
+	if err := rec.UnmarshalText([]byte(focusOrderLog)); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		r    Router
+		tags [2]int
+	)
+	for _, d := range rec.Replay(&r, focusFields(&r, &tags, false)) {
+		t.Error(d)
+	}
+	if r.key.queue.focus != &tags[0] {
+		t.Error("the first field doesn't have the focus after the replay")
+	}
+}
+
+func TestReplayDivergence(t *testing.T) {
+	var rec Recording
+	if err := rec.UnmarshalText([]byte(focusOrderLog)); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		r    Router
+		tags [2]int
+	)
+	// Swapping the fields presses the other field.
+	divs := rec.Replay(&r, focusFields(&r, &tags, true))
+	if len(divs) == 0 {
+		t.Fatal("replay of a changed layout didn't diverge")
+	}
+	if got, want := divs[0].String(), "frame 4: focus 0, recorded 1"; got != want {
+		t.Errorf("got divergence %q, expected %q", got, want)
+	}
+}
+
+func TestRecordingText(t *testing.T) {
+	var (
+		r   Router
+		rec Recording
+	)
+	r.Record(&rec)
+	r.Queue(
+		pointer.Event{Type: pointer.Scroll, Source: pointer.Touch, PointerID: 2, Time: 1234, Position: f32.Pt(1.5, -2.25), Scroll: f32.Pt(0, 0.1), Modifiers: key.ModShift, Region: -1},
+		key.Event{Name: "A B", Modifiers: key.ModCtrl, State: key.Release},
+		key.EditEvent{Range: key.Range{Start: 1, End: 3}, Text: "\"quoted\"\n"},
+		key.SnippetEvent{Start: 2, End: 5},
+		key.SelectionEvent{Start: 4, End: 4},
+		clipboard.Event{Text: ""},
+	)
+	r.Frame(nil)
+	r.Record(nil)
+	r.Queue(key.Event{Name: "B"})
+	if n := len(rec.Entries); n != 7 {
+		t.Fatalf("recorded %d entries, expected 7", n)
+	}
+	data, err := rec.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec Recording
+	if err := dec.UnmarshalText(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec.Entries, rec.Entries) {
+		t.Errorf("decoded entries\n%v\ndiffer from the recorded entries\n%v", dec.Entries, rec.Entries)
+	}
+	if err := dec.UnmarshalText([]byte(recordingHeader + "\ne 0 0 gesture 1\n")); err == nil {
+		t.Error("unknown event kind decoded without error")
+	}
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"math"
+
+	"gioui.org/f32"
+)
+
+// regionIndex is a spatial index of the regions of a pointer.RegionsOp. The
+// bounds of the regions are covered by a grid of cells, each listing the
+// regions that overlap it.
+type regionIndex struct {
+	regions [][]f32.Point
+	// invTrans maps window coordinates to the coordinates of
+	// the regions.
+	invTrans f32.Affine2D
+	bounds   []f32.Rectangle
+	// area is the union of bounds.
+	area f32.Rectangle
+	// cellSize is the size of a grid cell.
+	cellSize   f32.Point
+	cols, rows int
+	// The regions overlapping cell i are
+	// cellRegions[cellStart[i]:cellStart[i+1]], in increasing order.
+	cellStart   []int
+	cellRegions []int
+}
+
+// regionEpsilon is the distance from an outline within which a position is
+// considered on the outline.
+const regionEpsilon = 1e-3
+
+// reset the index to cover regions.
+func (idx *regionIndex) reset(regions [][]f32.Point, invTrans f32.Affine2D) {
+	idx.regions = regions
+	idx.invTrans = invTrans
+	idx.bounds = idx.bounds[:0]
+	idx.area = f32.Rectangle{}
+	for i, r := range regions {
+		var b f32.Rectangle
+		if len(r) > 0 {
+			b = f32.Rectangle{Min: r[0], Max: r[0]}
+		}
+		for _, p := range r[1:] {
+			b = extend(b, p)
+		}
+		idx.bounds = append(idx.bounds, b)
+		if i == 0 {
+			idx.area = b
+		} else {
+			idx.area = extend(extend(idx.area, b.Min), b.Max)
+		}
+	}
+	// Aim for a constant number of regions per cell.
+	n := int(math.Ceil(math.Sqrt(float64(len(regions)))))
+	if n < 1 {
+		n = 1
+	}
+	idx.cols, idx.rows = n, n
+	sz := idx.area.Size()
+	idx.cellSize = f32.Point{X: sz.X / float32(n), Y: sz.Y / float32(n)}
+	if idx.cellSize.X <= 0 {
+		idx.cellSize.X = 1
+	}
+	if idx.cellSize.Y <= 0 {
+		idx.cellSize.Y = 1
+	}
+	// Count the regions of each cell, then fill in the regions.
+	ncells := idx.cols * idx.rows
+	idx.cellStart = idx.cellStart[:0]
+	for i := 0; i <= ncells; i++ {
+		idx.cellStart = append(idx.cellStart, 0)
+	}
+	for _, b := range idx.bounds {
+		x0, y0 := idx.cell(b.Min)
+		x1, y1 := idx.cell(b.Max)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				idx.cellStart[y*idx.cols+x+1]++
+			}
+		}
+	}
+	for i := 1; i <= ncells; i++ {
+		idx.cellStart[i] += idx.cellStart[i-1]
+	}
+	total := idx.cellStart[ncells]
+	if cap(idx.cellRegions) < total {
+		idx.cellRegions = make([]int, total)
+	}
+	idx.cellRegions = idx.cellRegions[:total]
+	// Use the start offsets as insertion points, and restore
+	// them afterwards.
+	for i, b := range idx.bounds {
+		x0, y0 := idx.cell(b.Min)
+		x1, y1 := idx.cell(b.Max)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				c := y*idx.cols + x
+				idx.cellRegions[idx.cellStart[c]] = i
+				idx.cellStart[c]++
+			}
+		}
+	}
+	for i := ncells; i > 0; i-- {
+		idx.cellStart[i] = idx.cellStart[i-1]
+	}
+	idx.cellStart[0] = 0
+}
+
+// cell returns the grid cell of p, clamped to the grid.
+func (idx *regionIndex) cell(p f32.Point) (int, int) {
+	x := int((p.X - idx.area.Min.X) / idx.cellSize.X)
+	y := int((p.Y - idx.area.Min.Y) / idx.cellSize.Y)
+	return clamp(x, 0, idx.cols-1), clamp(y, 0, idx.rows-1)
+}
+
+// hit returns the index of the topmost region containing the window
+// position pos, or -1.
+func (idx *regionIndex) hit(pos f32.Point) int {
+	p := idx.invTrans.Transform(pos)
+	if !inRect(idx.area, p) {
+		return -1
+	}
+	x, y := idx.cell(p)
+	c := y*idx.cols + x
+	cands := idx.cellRegions[idx.cellStart[c]:idx.cellStart[c+1]]
+	for i := len(cands) - 1; i >= 0; i-- {
+		r := cands[i]
+		if inRect(idx.bounds[r], p) && inPolygon(idx.regions[r], p) {
+			return r
+		}
+	}
+	return -1
+}
+
+// inRect reports whether p is inside r, including its edges, allowing
+// for an error of regionEpsilon.
+func inRect(r f32.Rectangle, p f32.Point) bool {
+	const e = regionEpsilon
+	return r.Min.X-e <= p.X && p.X <= r.Max.X+e &&
+		r.Min.Y-e <= p.Y && p.Y <= r.Max.Y+e
+}
+
+// inPolygon reports whether p is inside or on the outline of the
+// polygon, using the even-odd rule.
+func inPolygon(poly []f32.Point, p f32.Point) bool {
+	inside := false
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		if onSegment(a, b, p) {
+			return true
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) {
+			x := a.X + (p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+			if p.X < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// onSegment reports whether p is within regionEpsilon of the segment
+// from a to b.
+func onSegment(a, b, p f32.Point) bool {
+	d := b.Sub(a)
+	l2 := d.X*d.X + d.Y*d.Y
+	ap := p.Sub(a)
+	if l2 == 0 {
+		return ap.X*ap.X+ap.Y*ap.Y <= regionEpsilon*regionEpsilon
+	}
+	// Projection of p onto the segment, in units of its length.
+	t := (ap.X*d.X + ap.Y*d.Y) / l2
+	if t < 0 || t > 1 {
+		return false
+	}
+	cross := ap.X*d.Y - ap.Y*d.X
+	return cross*cross <= regionEpsilon*regionEpsilon*l2
+}
+
+// extend r to include p. Unlike f32.Rectangle.Union, extend
+// includes empty rectangles.
+func extend(r f32.Rectangle, p f32.Point) f32.Rectangle {
+	if p.X < r.Min.X {
+		r.Min.X = p.X
+	}
+	if p.Y < r.Min.Y {
+		r.Min.Y = p.Y
+	}
+	if p.X > r.Max.X {
+		r.Max.X = p.X
+	}
+	if p.Y > r.Max.Y {
+		r.Max.Y = p.Y
+	}
+	return r
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
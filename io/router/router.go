@@ -23,9 +23,12 @@ import (
 	"gioui.org/io/clipboard"
 	"gioui.org/io/event"
 	"gioui.org/io/key"
+	"gioui.org/io/menu"
 	"gioui.org/io/pointer"
 	"gioui.org/io/profile"
 	"gioui.org/io/semantic"
+	"gioui.org/io/size"
+	"gioui.org/io/system"
 	"gioui.org/io/transfer"
 	"gioui.org/op"
 )
@@ -52,12 +55,109 @@ type Router struct {
 	// InvalidateOp summary.
 	wakeup     bool
 	wakeupTime time.Time
+	// scheduled and scheduledTime summarize the scheduled InvalidateOps.
+	scheduled     bool
+	scheduledTime time.Time
 
 	// ProfileOp summary.
 	profHandlers map[event.Tag]struct{}
 	profile      profile.Event
+
+	// sizes and prevSizes are the sizes recorded by size.Ops in the
+	// current and previous frames.
+	sizes, prevSizes map[event.Tag]image.Point
+
+	// stats of the most recent frame.
+	stats QueueStats
+
+	// rec is the active Recording, if any.
+	rec *Recording
+
+	// idle detects needless InvalidateOps, if enabled.
+	idle idleDetector
+	// clock is the Clock set by SetClock, or nil for the real time.
+	clock system.Clock
+
+	// frameID is incremented by every Frame.
+	frameID uint64
+	// seq numbers the queued events.
+	seq uint64
+	// causes of the most recent frame, and the causes of the next
+	// frame by InvalidateOps.
+	causes, invalidates []FrameCause
+	// invalidated records the InvalidateOps during collect.
+	invalidated []invalidation
+	// stamps of the events delivered in the current frame.
+	stamps map[event.Tag][]EventStamp
+	// size is the most recent size reported by Resize.
+	size image.Point
+
+	// commits are the CommitOps of the current frame.
+	commits []commit
+	// onFrame is the function set by OnFrame, and committing is set
+	// while it runs.
+	onFrame    func(tag event.Tag, e system.CommitEvent)
+	committing bool
+
+	// modal collects the handlers inside ModalOps during collect, and
+	// modalDepth is the depth of the current ModalOp. modalTags is
+	// reused for modal across frames.
+	modal      modalTags
+	modalDepth int
+	modalTags  modalTags
+}
+
+// commit is a tag registered by a CommitOp, and the bounds of its area.
+type commit struct {
+	tag    event.Tag
+	bounds image.Rectangle
+}
+
+// QueueStats describes the backlog of events of a Router. A Pending count
+// that stays high, or Dropped events, indicate that the program can't keep
+// up with its input.
+type QueueStats struct {
+	// Pending is the number of events waiting for delivery by Events.
+	Pending int
+	// Processed is the number of events delivered by Events in the
+	// most recent frame, between the two most recent calls to Frame.
+	Processed int
+	// Dropped is the number of events discarded by the most recent
+	// call to Frame, because their handlers didn't ask for them.
+	Dropped int
 }
 
+// QueueLimit bounds the events pending for each tag, to protect against
+// handlers that stop asking for their events, for example because they
+// are suspended with SuspendBuffer.
+type QueueLimit struct {
+	// Events is the maximum number of pending events per tag. When the
+	// limit is reached, the oldest pending event of the tag is dropped
+	// for every new event. Zero means no limit.
+	Events int
+	// Notify delivers an OverflowEvent before the events of tags that
+	// lost events to the limit.
+	Notify bool
+}
+
+// OverflowEvent is the first event delivered to a tag that lost events
+// to the QueueLimit of its Router.
+type OverflowEvent struct {
+	// Dropped is the number of events lost since the previous
+	// delivery.
+	Dropped int
+}
+
+// SuspendMode determines what happens to the events of a suspended tag.
+type SuspendMode uint8
+
+const (
+	// SuspendBuffer holds back events until the tag is resumed.
+	SuspendBuffer SuspendMode = iota
+	// SuspendDrop discards events until the tag is resumed.
+	SuspendDrop
+)
+
 // SemanticNode represents a node in the tree describing the components
 // contained in a frame.
 type SemanticNode struct {
@@ -78,6 +178,28 @@ type SemanticDesc struct {
 	Disabled    bool
 	Gestures    SemanticGestures
 	Bounds      f32.Rectangle
+	// LiveRegion is set for live regions, whose changes are announced
+	// with Politeness.
+	LiveRegion bool
+	Politeness semantic.Politeness
+	// Announce is set if the frame signaled a change of the live region.
+	Announce bool
+}
+
+// Hit describes a pointer handler or semantic area found by HitTest.
+type Hit struct {
+	// Tag is the tag of the handler, or nil for semantic areas without
+	// handlers.
+	Tag event.Tag
+	// Semantic is the semantic node of the area, or zero.
+	Semantic SemanticID
+	// Desc is the semantic description of the area, if Semantic is
+	// non-zero.
+	Desc SemanticDesc
+	// Bounds is the bounding box of the area in window coordinates.
+	Bounds f32.Rectangle
+	// Transform maps the coordinates of the area to window coordinates.
+	Transform f32.Affine2D
 }
 
 // SemanticGestures is a bit-set of supported gestures.
@@ -93,16 +215,44 @@ const (
 type SemanticID uint64
 
 type handlerEvents struct {
-	handlers  map[event.Tag][]event.Event
+	handlers  map[event.Tag]*tagEvents
 	hadEvents bool
+	// free holds the queues of cleared tags, to be reused by
+	// later frames.
+	free []*tagEvents
+	// pending and processed count the undelivered and
+	// delivered events.
+	pending, processed int
+	// dropped counts the events discarded for suspended tags.
+	dropped int
+	// suspended tracks the modes of suspended tags.
+	suspended map[event.Tag]SuspendMode
+	// buffered holds the events of suspended or resumed tags
+	// until they are delivered. Unlike handlers, buffered events
+	// survive Clear.
+	buffered map[event.Tag]*tagEvents
+	limit    QueueLimit
+	// overflows counts the events lost to limit, per tag.
+	overflows map[event.Tag]int
+	// seq and event are the sequence number and the queued event
+	// being routed, or zero.
+	seq   uint64
+	event event.Event
+	// pendingCauses are the causes of the next frame.
+	pendingCauses []FrameCause
+	// modal confines the input events to the handlers inside
+	// ModalOps.
+	modal modalTags
 }
 
 // Events returns the available events for the handler key.
 func (q *Router) Events(k event.Tag) []event.Event {
-	events := q.handlers.Events(k)
+	events, seqs := q.handlers.Events(k)
+	q.stamp(k, seqs)
 	if _, isprof := q.profHandlers[k]; isprof {
 		delete(q.profHandlers, k)
 		events = append(events, q.profile)
+		q.stamps[k] = append(q.stamps[k], EventStamp{Frame: q.frameID + 1})
 	}
 	return events
 }
@@ -111,8 +261,16 @@ func (q *Router) Events(k event.Tag) []event.Event {
 // operation list. The text input state, wakeup time and whether
 // there are active profile handlers is also saved.
 func (q *Router) Frame(frame *op.Ops) {
+	if q.committing {
+		panic("router: Frame called from the OnFrame function")
+	}
+	q.stats.Processed = q.handlers.processed
+	q.stats.Dropped = q.handlers.pending + q.handlers.dropped
 	q.handlers.Clear()
+	q.frameID++
+	q.frameCauses()
 	q.wakeup = false
+	q.scheduled = false
 	for k := range q.profHandlers {
 		delete(q.profHandlers, k)
 	}
@@ -121,35 +279,216 @@ func (q *Router) Frame(frame *op.Ops) {
 		ops = &frame.Internal
 	}
 	q.reader.Reset(ops)
+	q.idle.reset()
+	q.modal, q.modalDepth = nil, 0
 	q.collect()
+	q.handlers.modal = q.modal
+	q.key.queue.modal = q.modal
+	q.invalidateCauses()
+	if q.idle.enabled() {
+		q.idle.frame(&q.pointer.queue, q.idle.time())
+	}
 
 	q.pointer.queue.Frame(&q.handlers)
 	q.key.queue.Frame(&q.handlers, q.key.collector)
+	q.frameSizes()
 	if q.handlers.HadEvents() {
 		q.wakeup = true
 		q.wakeupTime = time.Time{}
 	}
+	if q.rec != nil {
+		q.rec.add(RecordEntry{Checkpoint: q.checkpoint()})
+	}
+	q.frameCommits()
+}
+
+// OnFrame sets the function called at the end of every Frame for each
+// tag with a CommitOp in the frame, in the order of their CommitOps. The
+// function runs after the state of the Router is updated, and receives
+// the CommitEvent that is also delivered to the tag in the next frame.
+// It may query the Router and Queue events, but must not call Frame.
+// OnFrame(nil) removes the function.
+func (q *Router) OnFrame(f func(tag event.Tag, e system.CommitEvent)) {
+	q.onFrame = f
+}
+
+// frameCommits delivers the CommitEvents of the frame, and calls the
+// OnFrame function.
+func (q *Router) frameCommits() {
+	if len(q.commits) == 0 {
+		return
+	}
+	q.committing = true
+	defer func() { q.committing = false }()
+	for i, c := range q.commits {
+		e := system.CommitEvent{Frame: q.frameID, Bounds: c.bounds}
+		// The event doesn't cause a frame by itself.
+		q.handlers.AddNoRedraw(c.tag, e)
+		if q.onFrame != nil {
+			q.onFrame(c.tag, e)
+		}
+		q.commits[i] = commit{}
+	}
+	q.commits = q.commits[:0]
+}
+
+// addCommit records a CommitOp in the current area, replacing earlier
+// CommitOps for tag.
+func (q *Router) addCommit(tag event.Tag) {
+	var bounds image.Rectangle
+	pc := &q.pointer.collector
+	if a := pc.currentArea(); a != -1 {
+		b := q.pointer.queue.areas[a].bounds()
+		bounds = image.Rect(
+			int(math.Floor(float64(b.Min.X))), int(math.Floor(float64(b.Min.Y))),
+			int(math.Ceil(float64(b.Max.X))), int(math.Ceil(float64(b.Max.Y))),
+		)
+	}
+	for i, c := range q.commits {
+		if c.tag == tag {
+			q.commits = append(q.commits[:i], q.commits[i+1:]...)
+			break
+		}
+	}
+	q.commits = append(q.commits, commit{tag: tag, bounds: bounds})
 }
 
 // Queue an event and report whether at least one handler had an event queued.
 func (q *Router) Queue(events ...event.Event) bool {
 	for _, e := range events {
+		if q.rec != nil {
+			q.rec.recordEvent(e)
+		}
+		q.idle.input = true
+		q.seq++
+		q.handlers.seq, q.handlers.event = q.seq, e
 		switch e := e.(type) {
 		case profile.Event:
 			q.profile = e
 		case pointer.Event:
 			q.pointer.queue.Push(e, &q.handlers)
 		case key.EditEvent, key.Event, key.FocusEvent, key.SnippetEvent, key.SelectionEvent:
+			focus := q.key.queue.focus
 			q.key.queue.Push(e, &q.handlers)
+			if q.key.queue.focus != focus {
+				// Tab moved the focus.
+				q.revealFocus()
+			}
 		case clipboard.Event:
 			q.cqueue.Push(e, &q.handlers)
+		case menu.Event:
+			// Menu events are addressed to the tag of their item.
+			if e.Tag != nil {
+				q.handlers.Add(e.Tag, e)
+			}
 		}
 	}
-	return q.handlers.HadEvents()
+	q.handlers.seq, q.handlers.event = 0, nil
+	if !q.handlers.HadEvents() {
+		return false
+	}
+	// The handlers need a frame to receive the events.
+	q.wakeup = true
+	q.wakeupTime = time.Time{}
+	return true
+}
+
+// Suspend the delivery of events to tag, without affecting its focus
+// or grabs. Mode determines whether the events are buffered or dropped
+// until a call to Resume. Events already queued for tag are subject to
+// mode as well.
+func (q *Router) Suspend(tag event.Tag, mode SuspendMode) {
+	q.handlers.Suspend(tag, mode)
 }
 
+// Forget discards the focus state kept for tag after its key handler
+// disappeared while focused. Call Forget for tags that won't re-appear;
+// a forgotten tag doesn't receive the Focus(false) event otherwise
+// delivered when its handler re-appears.
+func (q *Router) Forget(tag event.Tag) {
+	q.key.queue.Forget(tag)
+}
+
+// SetQueueLimit limits the events pending for each tag. The limit
+// applies to events queued after the call.
+func (q *Router) SetQueueLimit(l QueueLimit) {
+	q.handlers.limit = l
+}
+
+// Resume the delivery of events to a tag suspended by Suspend. Buffered
+// events are delivered before events queued later, and trigger a redraw.
+func (q *Router) Resume(tag event.Tag) {
+	q.handlers.Resume(tag)
+}
+
+// Modifiers returns the modifier keys held according to the most recent
+// key event. The state follows the Modifiers of every key.Event, so a
+// modifier whose release was lost is cleared by the next key event.
+func (q *Router) Modifiers() key.Modifiers {
+	return q.key.queue.modifiers
+}
+
+// MoveFocus moves the focus in the direction dir, and scrolls the newly
+// focused handler into view like Tab.
 func (q *Router) MoveFocus(dir FocusDirection) {
+	focus := q.key.queue.focus
 	q.key.queue.MoveFocus(dir, &q.handlers)
+	if q.key.queue.focus != focus {
+		q.revealFocus()
+	}
+}
+
+// revealFocus scrolls the focused handler into the view of its
+// scrollable ancestors, by sending pointer.Scroll events to their
+// handlers. Focus moves by Tab and MoveFocus reveal the handlers
+// outside a scrollable view, such as the elements a layout.List lays out
+// beyond its ends.
+func (q *Router) revealFocus() {
+	focus := q.key.queue.focus
+	if focus == nil {
+		return
+	}
+	area := q.key.queue.AreaFor(focus)
+	q.pointer.queue.Reveal(area, q.key.queue.BoundsFor(focus), &q.handlers)
+}
+
+// Focused returns the tag of the focused handler, or nil if no handler
+// has the focus.
+func (q *Router) Focused() event.Tag {
+	return q.key.queue.focus
+}
+
+// PeekFocus returns the tag MoveFocus(dir) would focus, or nil if the
+// focus wouldn't move. The focus is not changed.
+func (q *Router) PeekFocus(dir FocusDirection) event.Tag {
+	return q.key.queue.nextFocus(dir)
+}
+
+// SetFocusDistanceWeight tunes the preference of FocusUp and FocusDown moves
+// between aligned and nearby handlers. The candidate with the smallest
+// sum of its horizontal misalignment and w times its vertical distance is
+// chosen among the handlers in the adjacent row. The default weight of zero
+// chooses the most aligned handler; larger weights prefer closer handlers.
+func (q *Router) SetFocusDistanceWeight(w float32) {
+	q.key.queue.SetDistanceWeight(w)
+}
+
+// SetFocusCoalescing controls the coalescing of focus events. When
+// enabled, the default, a key.FocusEvent is dropped if the most recent
+// focus event of its tag has the same Focus, such as when a focused
+// handler disappears and reappears with the focus while the user
+// interface is rebuilt.
+func (q *Router) SetFocusCoalescing(enable bool) {
+	q.key.queue.keepDuplicateFocus = !enable
+	q.key.queue.focusSent = nil
+}
+
+// SetPrediction enables the prediction of pointer motion, so that the
+// Predicted positions of pointer events are extrapolated ahead by d from
+// the recent velocities of their pointers. It is typically set to the
+// input latency of the display. Zero disables prediction.
+func (q *Router) SetPrediction(d time.Duration) {
+	q.pointer.queue.prediction = d
 }
 
 func (q *Router) ClickFocus() {
@@ -169,6 +508,13 @@ func (q *Router) ClickFocus() {
 	q.pointer.queue.Push(e, &q.handlers)
 }
 
+// FocusableBounds returns the bounds of every key handler from the most
+// recent call to Frame. The bounds are the same as used for directional
+// focus moves.
+func (q *Router) FocusableBounds() map[event.Tag]image.Rectangle {
+	return q.key.queue.FocusableBounds()
+}
+
 // TextInputState returns the input state from the most recent
 // call to Frame.
 func (q *Router) TextInputState() TextInputState {
@@ -202,6 +548,21 @@ func (q *Router) SemanticAt(pos f32.Point) (SemanticID, bool) {
 	return q.pointer.queue.SemanticAt(pos)
 }
 
+// HitTest returns the pointer handlers and semantic areas under pos in
+// the most recent frame, in the order pointer events would be delivered
+// to them. HitTest has no side effects, and the hidden areas of layers and
+// the areas covered by the areas on top are omitted, as for events.
+func (q *Router) HitTest(pos f32.Point) []Hit {
+	return q.pointer.queue.HitTest(nil, pos)
+}
+
+// AppendHeldKeys appends the press events of the keys held down to keys,
+// and returns the result. The focused handler receives the releases of
+// the held keys, even if it gained focus after their presses.
+func (q *Router) AppendHeldKeys(keys []key.Event) []key.Event {
+	return append(keys, q.key.queue.pressed...)
+}
+
 // AppendSemantics appends the semantic tree to nodes, and returns the result.
 // The root node is the first added.
 func (q *Router) AppendSemantics(nodes []SemanticNode) []SemanticNode {
@@ -226,20 +587,41 @@ func (q *Router) collect() {
 	q.key.queue.Reset()
 	var t f32.Affine2D
 	bo := binary.LittleEndian
+	var now time.Time
+	if q.idle.enabled() {
+		now = q.idle.time()
+	}
 	for encOp, ok := q.reader.Decode(); ok; encOp, ok = q.reader.Decode() {
+		if q.idle.enabled() && ops.OpType(encOp.Data[0]) != ops.TypeInvalidate {
+			q.idle.hashOp(pc, encOp)
+		}
 		switch ops.OpType(encOp.Data[0]) {
 		case ops.TypeInvalidate:
 			op := decodeInvalidateOp(encOp.Data)
+			if q.idle.enabled() && !op.At.After(now) {
+				q.idle.invalidate(pc)
+			}
+			q.invalidate(op.At)
 			if !q.wakeup || op.At.Before(q.wakeupTime) {
 				q.wakeup = true
 				q.wakeupTime = op.At
 			}
+			if op.Scheduled && (!q.scheduled || op.At.Before(q.scheduledTime)) {
+				q.scheduled = true
+				q.scheduledTime = op.At
+			}
 		case ops.TypeProfile:
 			op := decodeProfileOp(encOp.Data, encOp.Refs)
 			if q.profHandlers == nil {
 				q.profHandlers = make(map[event.Tag]struct{})
 			}
 			q.profHandlers[op.Tag] = struct{}{}
+		case ops.TypeSize:
+			op := decodeSizeOp(encOp.Data, encOp.Refs)
+			if q.sizes == nil {
+				q.sizes = make(map[event.Tag]image.Point)
+			}
+			q.sizes[op.Tag] = op.Size
 		case ops.TypeClipboardRead:
 			q.cqueue.ProcessReadClipboard(encOp.Refs)
 		case ops.TypeClipboardWrite:
@@ -280,6 +662,14 @@ func (q *Router) collect() {
 			pc.pass()
 		case ops.TypePopPass:
 			pc.popPass()
+		case ops.TypeExpand:
+			bo := binary.LittleEndian
+			pc.expand(image.Point{
+				X: int(int32(bo.Uint32(encOp.Data[1:]))),
+				Y: int(int32(bo.Uint32(encOp.Data[5:]))),
+			})
+		case ops.TypePopExpand:
+			pc.popExpand()
 		case ops.TypePointerInput:
 			op := pointer.InputOp{
 				Tag:   encOp.Refs[0].(event.Tag),
@@ -295,8 +685,14 @@ func (q *Router) collect() {
 						Y: int(int32(bo.Uint32(encOp.Data[16:]))),
 					},
 				},
+				Margin:          int(int32(bo.Uint32(encOp.Data[20:]))),
+				ScrollModifiers: key.Modifiers(bo.Uint32(encOp.Data[24:])),
 			}
 			pc.inputOp(op, &q.handlers)
+		case ops.TypePointerRegions:
+			pc.regions(encOp.Refs[0].([][]f32.Point))
+		case ops.TypePointerMask:
+			pc.mask(encOp.Refs[0].(image.Image), encOp.Data[1])
 		case ops.TypeCursor:
 			name := pointer.Cursor(encOp.Data[1])
 			pc.cursor(name)
@@ -334,11 +730,13 @@ func (q *Router) collect() {
 			kc.softKeyboard(op.Show)
 		case ops.TypeKeyInput:
 			op := key.InputOp{
-				Tag:  encOp.Refs[0].(event.Tag),
-				Hint: key.InputHint(encOp.Data[1]),
+				Tag:       encOp.Refs[0].(event.Tag),
+				Hint:      key.InputHint(encOp.Data[1]),
+				TabIndex:  int(int32(bo.Uint32(encOp.Data[2:]))),
+				AutoFocus: encOp.Data[6] != 0,
 			}
 			b := pc.currentAreaBounds()
-			kc.inputOp(op, b)
+			kc.inputOp(op, pc.currentArea(), b)
 		case ops.TypeSnippet:
 			op := key.SnippetOp{
 				Tag: encOp.Refs[0].(event.Tag),
@@ -391,35 +789,219 @@ func (q *Router) collect() {
 			} else {
 				pc.semanticDisabled(false)
 			}
+		case ops.TypeSemanticLive:
+			op := semantic.LiveRegionOp{
+				Tag:        encOp.Refs[0].(event.Tag),
+				Politeness: semantic.Politeness(encOp.Data[1]),
+			}
+			pc.semanticLive(op)
+		case ops.TypeSemanticAnnounce:
+			pc.semanticAnnounce(encOp.Refs[0].(event.Tag))
+		case ops.TypeCommit:
+			q.addCommit(encOp.Refs[0].(event.Tag))
+		case ops.TypeModal:
+			if q.modal == nil {
+				if q.modalTags == nil {
+					q.modalTags = make(modalTags)
+				}
+				for t := range q.modalTags {
+					delete(q.modalTags, t)
+				}
+				q.modal = q.modalTags
+			}
+			q.modalDepth++
+		case ops.TypePopModal:
+			q.modalDepth--
+		}
+		switch ops.OpType(encOp.Data[0]) {
+		case ops.TypePointerInput, ops.TypeKeyInput, ops.TypeSource, ops.TypeTarget:
+			if q.modalDepth > 0 {
+				q.modal[encOp.Refs[0].(event.Tag)] = struct{}{}
+			}
 		}
 	}
 }
 
+// frameSizes delivers size.Events for the tags whose sizes changed since
+// the previous frame.
+func (q *Router) frameSizes() {
+	for tag, sz := range q.sizes {
+		if old, ok := q.prevSizes[tag]; ok && old != sz {
+			q.handlers.Add(tag, size.Event{Old: old, New: sz})
+		}
+	}
+	for tag := range q.prevSizes {
+		delete(q.prevSizes, tag)
+	}
+	q.sizes, q.prevSizes = q.prevSizes, q.sizes
+}
+
+// ObservedSize returns the size recorded for tag by a size.Op in the
+// most recent frame.
+func (q *Router) ObservedSize(tag event.Tag) (image.Point, bool) {
+	sz, ok := q.prevSizes[tag]
+	return sz, ok
+}
+
 // Profiling reports whether there was profile handlers in the
 // most recent Frame call.
 func (q *Router) Profiling() bool {
 	return len(q.profHandlers) > 0
 }
 
-// WakeupTime returns the most recent time for doing another frame,
-// as determined from the last call to Frame.
+// QueueStats returns the current number of pending events, and the
+// number of events processed and dropped by the most recent frame.
+func (q *Router) QueueStats() QueueStats {
+	s := q.stats
+	s.Pending = q.handlers.pending
+	for _, t := range q.handlers.buffered {
+		s.Pending += t.len()
+	}
+	return s
+}
+
+// SetClock sets the Clock of the times the Router reads itself, such as
+// the times of recordings and idle detection. A nil Clock means
+// system.RealClock. The times of frames, and so of InvalidateOps, are
+// set by the caller of Frame and should come from the same Clock.
+func (q *Router) SetClock(c system.Clock) {
+	q.clock = c
+	if c != nil {
+		q.idle.now = c.Now
+	} else {
+		q.idle.now = nil
+	}
+}
+
+// Now returns the time of the Clock of the Router, for stamping the
+// frames of test harnesses.
+func (q *Router) Now() time.Time {
+	if q.clock == nil {
+		return system.RealClock.Now()
+	}
+	return q.clock.Now()
+}
+
+// WakeupTime returns the earliest time for doing another frame, or false
+// if no frame is pending. A zero time means an immediate frame. The
+// wakeup is determined by the InvalidateOps and delivered events of the
+// most recent Frame, and by events queued since. WakeupTime doesn't
+// consume the wakeup: it is pending until the next call to Frame or
+// ClearWakeup.
 func (q *Router) WakeupTime() (time.Time, bool) {
 	return q.wakeupTime, q.wakeup
 }
 
+// ClearWakeup drops the pending wakeup, such as when the caller already
+// decided to draw a frame, to avoid scheduling the frame twice. The
+// scheduled wakeup of ScheduledWakeupTime is dropped as well. WakeupTime
+// reports no wakeup until a queued event or the next Frame sets a new
+// one.
+func (q *Router) ClearWakeup() {
+	q.wakeup, q.wakeupTime = false, time.Time{}
+	q.scheduled, q.scheduledTime = false, time.Time{}
+}
+
+// ScheduledWakeupTime is like WakeupTime, but considers only the
+// InvalidateOps marked as scheduled.
+func (q *Router) ScheduledWakeupTime() (time.Time, bool) {
+	return q.scheduledTime, q.scheduled
+}
+
 func (h *handlerEvents) init() {
 	if h.handlers == nil {
-		h.handlers = make(map[event.Tag][]event.Event)
+		h.handlers = make(map[event.Tag]*tagEvents)
 	}
 }
 
-func (h *handlerEvents) AddNoRedraw(k event.Tag, e event.Event) {
+// queue returns the queue of k in m, taking a recycled queue from the
+// free list for new tags.
+func (h *handlerEvents) queue(m map[event.Tag]*tagEvents, k event.Tag) *tagEvents {
+	if t, ok := m[k]; ok {
+		return t
+	}
+	var t *tagEvents
+	if n := len(h.free); n > 0 {
+		t = h.free[n-1]
+		h.free[n-1] = nil
+		h.free = h.free[:n-1]
+	} else {
+		t = new(tagEvents)
+	}
+	m[k] = t
+	return t
+}
+
+// recycle clears t and adds it to the free list.
+func (h *handlerEvents) recycle(t *tagEvents) {
+	// Drop the references to the undelivered events. The delivered
+	// events are outside t.events.
+	events := t.events[:cap(t.events)]
+	for i := range events {
+		events[i] = nil
+	}
+	t.events, t.seqs = t.events[:0], t.seqs[:0]
+	t.active = false
+	h.free = append(h.free, t)
+}
+
+// AddNoRedraw adds e to the events of k, and reports whether e was
+// added or buffered rather than dropped.
+func (h *handlerEvents) AddNoRedraw(k event.Tag, e event.Event) bool {
 	h.init()
-	h.handlers[k] = append(h.handlers[k], e)
+	if h.modal.blocks(k, e) {
+		h.dropped++
+		return false
+	}
+	if mode, ok := h.suspended[k]; ok {
+		switch mode {
+		case SuspendBuffer:
+			if h.full(k) {
+				h.dropOldest(k)
+			}
+			h.queue(h.buffered, k).add(e, h.seq)
+			return true
+		case SuspendDrop:
+			h.dropped++
+		}
+		return false
+	}
+	if h.full(k) {
+		h.dropOldest(k)
+	}
+	h.queue(h.handlers, k).add(e, h.seq)
+	h.pending++
+	return true
+}
+
+// full reports whether k has reached the limit of pending events.
+func (h *handlerEvents) full(k event.Tag) bool {
+	n := h.limit.Events
+	return n > 0 && h.handlers[k].len()+h.buffered[k].len() >= n
+}
+
+// dropOldest discards the oldest pending event of k.
+func (h *handlerEvents) dropOldest(k event.Tag) {
+	if buf := h.buffered[k]; buf.len() > 0 {
+		buf.dropOldest()
+	} else {
+		h.handlers[k].dropOldest()
+		h.pending--
+	}
+	h.dropped++
+	if h.limit.Notify {
+		if h.overflows == nil {
+			h.overflows = make(map[event.Tag]int)
+		}
+		h.overflows[k]++
+	}
 }
 
 func (h *handlerEvents) Add(k event.Tag, e event.Event) {
-	h.AddNoRedraw(k, e)
+	if !h.AddNoRedraw(k, e) {
+		return
+	}
+	h.addCause(k)
 	h.hadEvents = true
 }
 
@@ -429,28 +1011,175 @@ func (h *handlerEvents) HadEvents() bool {
 	return u
 }
 
-func (h *handlerEvents) Events(k event.Tag) []event.Event {
-	if events, ok := h.handlers[k]; ok {
-		h.handlers[k] = h.handlers[k][:0]
-		// Schedule another frame if we delivered events to the user
-		// to flush half-updated state. This is important when an
-		// event changes UI state that has already been laid out. In
-		// the worst case, we waste a frame, increasing power usage.
-		//
-		// Gio is expected to grow the ability to construct
-		// frame-to-frame differences and only render to changed
-		// areas. In that case, the waste of a spurious frame should
-		// be minimal.
+func (h *handlerEvents) Suspend(k event.Tag, mode SuspendMode) {
+	if h.suspended == nil {
+		h.suspended = make(map[event.Tag]SuspendMode)
+		h.buffered = make(map[event.Tag]*tagEvents)
+	}
+	h.suspended[k] = mode
+	t := h.handlers[k]
+	n := t.len()
+	if n == 0 {
+		return
+	}
+	h.pending -= n
+	switch mode {
+	case SuspendBuffer:
+		buf := h.queue(h.buffered, k)
+		for i, e := range t.events {
+			buf.add(e, t.seqs[i])
+		}
+	case SuspendDrop:
+		h.dropped += n
+	}
+	t.reset()
+}
+
+func (h *handlerEvents) Resume(k event.Tag) {
+	if _, ok := h.suspended[k]; !ok {
+		return
+	}
+	delete(h.suspended, k)
+	if h.buffered[k].len() > 0 {
+		h.hadEvents = true
+	}
+}
+
+// Events returns the pending events of k and the sequence numbers of
+// the queued events they originate from. The caller owns the returned
+// events, and the sequence numbers are valid until the next call to h.
+func (h *handlerEvents) Events(k event.Tag) ([]event.Event, []uint64) {
+	if _, ok := h.suspended[k]; ok {
+		return nil, nil
+	}
+	if n, ok := h.overflows[k]; ok {
+		delete(h.overflows, k)
+		events, seqs := h.Events(k)
+		events = append([]event.Event{OverflowEvent{Dropped: n}}, events...)
+		seqs = append([]uint64{0}, seqs...)
+		return events, seqs
+	}
+	t := h.handlers[k]
+	if buf, ok := h.buffered[k]; ok {
+		delete(h.buffered, k)
+		n := t.len()
+		if n > 0 {
+			for i, e := range t.events {
+				buf.add(e, t.seqs[i])
+			}
+			t.reset()
+		}
+		h.pending -= n
+		events, seqs := buf.deliver()
+		h.recycle(buf)
+		h.processed += len(events)
 		h.hadEvents = h.hadEvents || len(events) > 0
-		return events
+		return events, seqs
 	}
-	return nil
+	if t.len() == 0 {
+		return nil, nil
+	}
+	events, seqs := t.deliver()
+	h.pending -= len(events)
+	h.processed += len(events)
+	// Schedule another frame if we delivered events to the user
+	// to flush half-updated state. This is important when an
+	// event changes UI state that has already been laid out. In
+	// the worst case, we waste a frame, increasing power usage.
+	//
+	// Gio is expected to grow the ability to construct
+	// frame-to-frame differences and only render to changed
+	// areas. In that case, the waste of a spurious frame should
+	// be minimal.
+	h.hadEvents = h.hadEvents || len(events) > 0
+	return events, seqs
 }
 
 func (h *handlerEvents) Clear() {
-	for k := range h.handlers {
+	for k, t := range h.handlers {
+		if t.active {
+			// Keep the queues of tags with recent events, to
+			// avoid re-inserting stable tags every frame.
+			t.reset()
+			t.active = false
+			continue
+		}
+		h.recycle(t)
 		delete(h.handlers, k)
 	}
+	for k := range h.overflows {
+		// The events of tags that aren't buffered are gone.
+		if _, ok := h.buffered[k]; !ok {
+			delete(h.overflows, k)
+		}
+	}
+	h.pending, h.processed, h.dropped = 0, 0, 0
+}
+
+// tagEvents are the pending events of a tag, and the sequence numbers of
+// the queued events they originate from, or zero.
+type tagEvents struct {
+	events []event.Event
+	seqs   []uint64
+	// active is set when events are added, and cleared by
+	// handlerEvents.Clear.
+	active bool
+}
+
+// minTagEvents is the minimum capacity of the event slices of a tag.
+const minTagEvents = 16
+
+func (t *tagEvents) len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.events)
+}
+
+func (t *tagEvents) add(e event.Event, seq uint64) {
+	if len(t.events) == cap(t.events) {
+		// The space before t.events belongs to delivered events, so
+		// grow from the pending events alone.
+		c := 2 * len(t.events)
+		if c < minTagEvents {
+			c = minTagEvents
+		}
+		events := make([]event.Event, len(t.events), c)
+		copy(events, t.events)
+		t.events = events
+	}
+	t.events = append(t.events, e)
+	t.seqs = append(t.seqs, seq)
+	t.active = true
+}
+
+func (t *tagEvents) dropOldest() {
+	n := copy(t.events, t.events[1:])
+	t.events[n] = nil
+	t.events = t.events[:n]
+	copy(t.seqs, t.seqs[1:])
+	t.seqs = t.seqs[:n]
+}
+
+// reset discards the pending events.
+func (t *tagEvents) reset() {
+	for i := range t.events {
+		t.events[i] = nil
+	}
+	t.events, t.seqs = t.events[:0], t.seqs[:0]
+}
+
+// deliver hands the pending events over to the caller, and returns them
+// along with their sequence numbers. The sequence numbers are valid until
+// the next change of t. Later events are added after the delivered
+// events, and never overwrite them.
+func (t *tagEvents) deliver() ([]event.Event, []uint64) {
+	n := len(t.events)
+	events := t.events[:n:n]
+	t.events = t.events[n:]
+	seqs := t.seqs
+	t.seqs = t.seqs[:0]
+	return events, seqs
 }
 
 func decodeProfileOp(d []byte, refs []interface{}) profile.Op {
@@ -462,6 +1191,20 @@ func decodeProfileOp(d []byte, refs []interface{}) profile.Op {
 	}
 }
 
+func decodeSizeOp(d []byte, refs []interface{}) size.Op {
+	bo := binary.LittleEndian
+	if ops.OpType(d[0]) != ops.TypeSize {
+		panic("invalid op")
+	}
+	return size.Op{
+		Tag: refs[0].(event.Tag),
+		Size: image.Point{
+			X: int(int32(bo.Uint32(d[1:]))),
+			Y: int(int32(bo.Uint32(d[5:]))),
+		},
+	}
+}
+
 func decodeInvalidateOp(d []byte) op.InvalidateOp {
 	bo := binary.LittleEndian
 	if ops.OpType(d[0]) != ops.TypeInvalidate {
@@ -471,9 +1214,12 @@ func decodeInvalidateOp(d []byte) op.InvalidateOp {
 	if nanos := bo.Uint64(d[1:]); nanos > 0 {
 		o.At = time.Unix(0, int64(nanos))
 	}
+	o.Scheduled = d[9] != 0
 	return o
 }
 
+func (OverflowEvent) ImplementsEvent() {}
+
 func (s SemanticGestures) String() string {
 	var gestures []string
 	if s&ClickGesture != 0 {
@@ -0,0 +1,476 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"image"
+	"reflect"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/menu"
+	"gioui.org/io/pointer"
+	"gioui.org/io/size"
+	"gioui.org/io/system"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+func TestScheduledWakeup(t *testing.T) {
+	now := time.Now()
+	timer := now.Add(time.Second)
+	var ops op.Ops
+	op.InvalidateOp{}.Add(&ops)
+	op.InvalidateOp{At: timer.Add(time.Second), Scheduled: true}.Add(&ops)
+	op.InvalidateOp{At: timer, Scheduled: true}.Add(&ops)
+	var r Router
+	r.Frame(&ops)
+	if at, ok := r.WakeupTime(); !ok || !at.IsZero() {
+		t.Errorf("WakeupTime = %v, %v; expected immediate", at, ok)
+	}
+	if at, ok := r.ScheduledWakeupTime(); !ok || !at.Equal(timer) {
+		t.Errorf("ScheduledWakeupTime = %v, %v; expected %v", at, ok, timer)
+	}
+	ops.Reset()
+	op.InvalidateOp{}.Add(&ops)
+	r.Frame(&ops)
+	if _, ok := r.ScheduledWakeupTime(); ok {
+		t.Error("animation redraw reported as scheduled")
+	}
+}
+
+func TestRetainedEvents(t *testing.T) {
+	ops, tags := benchmarkOps(2)
+	var r Router
+	r.Frame(ops)
+	move := func(x float32) {
+		r.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(x, 5)})
+	}
+	move(5)
+	move(6)
+	retained := r.Events(tags[0])
+	want := append([]event.Event(nil), retained...)
+	// Neither events in the same frame nor in later frames may overwrite
+	// the retained events.
+	move(7)
+	r.Events(tags[0])
+	for i := 0; i < 3; i++ {
+		r.Frame(ops)
+		move(5)
+		move(15)
+		r.Events(tags[0])
+		r.Events(tags[1])
+	}
+	if !reflect.DeepEqual(retained, want) {
+		t.Errorf("retained events changed to %v, expected %v", retained, want)
+	}
+}
+
+// benchmarkOps returns an operation list with n side-by-side
+// pointer and key handlers.
+func benchmarkOps(n int) (*op.Ops, []event.Tag) {
+	ops := new(op.Ops)
+	tags := make([]event.Tag, n)
+	for i := range tags {
+		tags[i] = new(int)
+		area := clip.Rect(image.Rect(i*10, 0, (i+1)*10, 10)).Push(ops)
+		pointer.InputOp{
+			Tag:   tags[i],
+			Types: pointer.Press | pointer.Release | pointer.Move | pointer.Drag | pointer.Enter | pointer.Leave,
+		}.Add(ops)
+		key.InputOp{Tag: tags[i]}.Add(ops)
+		area.Pop()
+	}
+	return ops, tags
+}
+
+func BenchmarkRouterFrame(b *testing.B) {
+	ops, tags := benchmarkOps(200)
+	var r Router
+	r.Frame(ops)
+	for _, t := range tags {
+		r.Events(t)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Frame(ops)
+		for _, t := range tags {
+			r.Events(t)
+		}
+	}
+}
+
+// BenchmarkRouterQueue measures event delivery. The allocations are the
+// delivered pointer events, boxed into event.Event values and reported as
+// events/op, and the occasional event slice replacing the slices handed
+// out by Events.
+func BenchmarkRouterQueue(b *testing.B) {
+	ops, tags := benchmarkOps(200)
+	events := make([]event.Event, 50)
+	for i := range events {
+		events[i] = pointer.Event{
+			Type:     pointer.Move,
+			Source:   pointer.Mouse,
+			Position: f32.Pt(float32(i*10+5), 5),
+		}
+	}
+	var r Router
+	r.Frame(ops)
+	delivered := 0
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Queue(events...)
+		for _, t := range tags {
+			delivered += len(r.Events(t))
+		}
+		r.Frame(ops)
+	}
+	b.ReportMetric(float64(delivered)/float64(b.N), "events/op")
+}
+
+// BenchmarkRouterInvalidate measures frames where many widgets request
+// redraws at a few distinct times.
+func BenchmarkRouterInvalidate(b *testing.B) {
+	var ops op.Ops
+	var r Router
+	now := time.Now()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ops.Reset()
+		for j := 0; j < 500; j++ {
+			op.InvalidateOp{At: now.Add(time.Duration(j%5) * time.Millisecond)}.Add(&ops)
+		}
+		r.Frame(&ops)
+		if t, ok := r.WakeupTime(); !ok || !t.Equal(now) {
+			b.Fatalf("WakeupTime = %v, %v; expected %v", t, ok, now)
+		}
+	}
+}
+
+func TestMenuEvent(t *testing.T) {
+	var (
+		r    Router
+		ops  op.Ops
+		save int
+		quit int
+	)
+	r.Frame(&ops)
+	if !r.Queue(menu.Event{Tag: &save}) {
+		t.Error("menu event not queued")
+	}
+	if evts := r.Events(&quit); len(evts) != 0 {
+		t.Errorf("menu event delivered to %v", evts)
+	}
+	evts := r.Events(&save)
+	if want := []event.Event{menu.Event{Tag: &save}}; !reflect.DeepEqual(evts, want) {
+		t.Errorf("got events %v; expected %v", evts, want)
+	}
+}
+
+func TestSizeEvents(t *testing.T) {
+	var (
+		r     Router
+		ops   op.Ops
+		a, b  int
+		frame = func(sizes ...image.Point) {
+			ops.Reset()
+			for _, sz := range sizes {
+				size.Op{Tag: &a, Size: sz}.Add(&ops)
+			}
+			size.Op{Tag: &b, Size: image.Pt(5, 5)}.Add(&ops)
+			r.Frame(&ops)
+		}
+	)
+	frame(image.Pt(10, 20))
+	if evts := r.Events(&a); len(evts) != 0 {
+		t.Errorf("first observation delivered %v", evts)
+	}
+	if sz, ok := r.ObservedSize(&a); !ok || sz != image.Pt(10, 20) {
+		t.Errorf("ObservedSize = %v, %v; expected (10,20)", sz, ok)
+	}
+	// The last size of the frame wins.
+	frame(image.Pt(15, 20), image.Pt(30, 20))
+	evts := r.Events(&a)
+	if want := []event.Event{size.Event{Old: image.Pt(10, 20), New: image.Pt(30, 20)}}; !reflect.DeepEqual(evts, want) {
+		t.Errorf("got events %v; expected %v", evts, want)
+	}
+	if evts := r.Events(&b); len(evts) != 0 {
+		t.Errorf("unchanged size delivered %v", evts)
+	}
+	frame(image.Pt(30, 20))
+	if evts := r.Events(&a); len(evts) != 0 {
+		t.Errorf("unchanged size delivered %v", evts)
+	}
+	frame()
+	if _, ok := r.ObservedSize(&a); ok {
+		t.Error("ObservedSize reported an unobserved tag")
+	}
+}
+
+func TestQueueStats(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	area := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{Tag: handler, Types: pointer.Move}.Add(&ops)
+	area.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	move := func(n int) {
+		for i := 0; i < n; i++ {
+			r.Queue(pointer.Event{Type: pointer.Move, Position: f32.Pt(float32(i%100), 50)})
+		}
+	}
+	// The first move enters the handler area.
+	move(10)
+	if got, exp := r.QueueStats(), (QueueStats{Pending: 11}); got != exp {
+		t.Errorf("got %+v, expected %+v", got, exp)
+	}
+	r.Events(handler)
+	move(5)
+	if got, exp := r.QueueStats(), (QueueStats{Pending: 5}); got != exp {
+		t.Errorf("got %+v, expected %+v", got, exp)
+	}
+	// Frame drops the events not asked for.
+	r.Frame(&ops)
+	if got, exp := r.QueueStats(), (QueueStats{Processed: 11, Dropped: 5}); got != exp {
+		t.Errorf("got %+v, expected %+v", got, exp)
+	}
+	move(3)
+	r.Events(handler)
+	r.Frame(&ops)
+	if got, exp := r.QueueStats(), (QueueStats{Processed: 3}); got != exp {
+		t.Errorf("got %+v, expected %+v", got, exp)
+	}
+}
+
+func TestQueueLimit(t *testing.T) {
+	handler := new(int)
+	ops := new(op.Ops)
+	r := new(Router)
+	r.SetQueueLimit(QueueLimit{Events: 3, Notify: true})
+	key.InputOp{Tag: handler}.Add(ops)
+	key.FocusOp{Tag: handler}.Add(ops)
+	r.Frame(ops)
+	r.Events(handler)
+	ops.Reset()
+	key.InputOp{Tag: handler}.Add(ops)
+	press := func(names ...string) {
+		for _, n := range names {
+			r.Queue(key.Event{Name: n, State: key.Press})
+		}
+	}
+	expect := func(names ...string) []event.Event {
+		var events []event.Event
+		for _, n := range names {
+			events = append(events, key.Event{Name: n, State: key.Press})
+		}
+		return events
+	}
+
+	// Events beyond the limit drop the oldest events.
+	press("A", "B", "C", "D")
+	exp := append([]event.Event{OverflowEvent{Dropped: 1}}, expect("B", "C", "D")...)
+	if got := r.Events(handler); !reflect.DeepEqual(got, exp) {
+		t.Errorf("got %v, expected %v", got, exp)
+	}
+	r.Frame(ops)
+
+	// Buffered events of a suspended tag are limited across frames.
+	r.Suspend(handler, SuspendBuffer)
+	for _, n := range []string{"E", "F", "G", "H", "I"} {
+		press(n)
+		r.Frame(ops)
+	}
+	if got := r.QueueStats().Pending; got != 3 {
+		t.Errorf("%d events pending, expected 3", got)
+	}
+	r.Resume(handler)
+	exp = append([]event.Event{OverflowEvent{Dropped: 2}}, expect("G", "H", "I")...)
+	if got := r.Events(handler); !reflect.DeepEqual(got, exp) {
+		t.Errorf("got %v after resume, expected %v", got, exp)
+	}
+
+	// The tag still functions after overflowing.
+	r.Frame(ops)
+	press("J")
+	if got, exp := r.Events(handler), expect("J"); !reflect.DeepEqual(got, exp) {
+		t.Errorf("got %v, expected %v", got, exp)
+	}
+}
+
+func TestFrameCauses(t *testing.T) {
+	var (
+		ops op.Ops
+		r   Router
+	)
+	h := new(int)
+	frame := func() {
+		ops.Reset()
+		area := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+		pointer.InputOp{Tag: h, Types: pointer.Press}.Add(&ops)
+		op.InvalidateOp{}.Add(&ops)
+		area.Pop()
+		r.Frame(&ops)
+	}
+	frame()
+	r.Events(h)
+	if id := r.FrameID(); id != 1 {
+		t.Errorf("got frame ID %d, expected 1", id)
+	}
+	press := pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(50, 50)}
+	// Key events without handlers don't cause frames.
+	r.Queue(key.Event{Name: "A"}, press)
+	r.Resize(image.Pt(100, 100))
+	if evts := r.Events(h); len(evts) != 1 || evts[0].(pointer.Event).Type != pointer.Press {
+		t.Fatalf("got events %v, expected the press", evts)
+	}
+	exp := []EventStamp{{Seq: 2, Frame: 2}}
+	if stamps := r.EventStamps(h); !reflect.DeepEqual(stamps, exp) {
+		t.Errorf("got stamps %v, expected %v", stamps, exp)
+	}
+	frame()
+	if id := r.FrameID(); id != 2 {
+		t.Errorf("got frame ID %d, expected 2", id)
+	}
+	causes := r.FrameCauses()
+	if len(causes) != 3 {
+		t.Fatalf("got causes %v, expected 3", causes)
+	}
+	if c := causes[0]; c.Kind != CauseInvalidate || c.Tag != h {
+		t.Errorf("got cause %+v, expected an invalidate by the handler", c)
+	}
+	if c := causes[1]; c.Kind != CauseEvent || c.Seq != 2 || c.Tag != h {
+		t.Errorf("got cause %+v, expected the press", c)
+	}
+	if c := causes[2]; c.Kind != CauseResize || c.Size != image.Pt(100, 100) {
+		t.Errorf("got cause %+v, expected the resize", c)
+	}
+	if s, exp := FormatCauses(causes), "invalidate(*int) pointer.Event#2 resize(100x100)"; s != exp {
+		t.Errorf("formatted causes as %q, expected %q", s, exp)
+	}
+	// The same size is no cause.
+	r.Resize(image.Pt(100, 100))
+	frame()
+	if causes := r.FrameCauses(); len(causes) != 1 || causes[0].Kind != CauseInvalidate {
+		t.Errorf("got causes %v, expected the invalidate", causes)
+	}
+}
+
+func TestOnFrame(t *testing.T) {
+	var (
+		ops op.Ops
+		r   Router
+	)
+	h1, h2 := new(int), new(int)
+	calls := make(map[event.Tag][]system.CommitEvent)
+	r.OnFrame(func(tag event.Tag, e system.CommitEvent) {
+		calls[tag] = append(calls[tag], e)
+		defer func() {
+			if recover() == nil {
+				t.Error("reentrant Frame didn't panic")
+			}
+		}()
+		r.Frame(nil)
+	})
+	frame := func(both bool) {
+		ops.Reset()
+		area := clip.Rect(image.Rect(10, 20, 30, 40)).Push(&ops)
+		system.CommitOp{Tag: h1}.Add(&ops)
+		// Later CommitOps replace earlier ones.
+		system.CommitOp{Tag: h1}.Add(&ops)
+		area.Pop()
+		if both {
+			system.CommitOp{Tag: h2}.Add(&ops)
+		}
+		r.Frame(&ops)
+	}
+	frame(false)
+	frame(true)
+	if evts := r.Events(h2); len(evts) != 1 {
+		t.Errorf("got events %v, expected the commit event", evts)
+	}
+	frame(false)
+	if evts := r.Events(h1); len(evts) != 1 || evts[0] != (system.CommitEvent{Frame: 3, Bounds: image.Rect(10, 20, 30, 40)}) {
+		t.Errorf("got events %v, expected the commit event of frame 3", evts)
+	}
+	if n := len(calls[h1]); n != 3 {
+		t.Errorf("called %d times for the tag committed every frame, expected 3", n)
+	}
+	for i, e := range calls[h1] {
+		if e.Frame != uint64(i+1) {
+			t.Errorf("call %d for frame %d", i, e.Frame)
+		}
+	}
+	if c := calls[h2]; len(c) != 1 || c[0].Frame != 2 {
+		t.Errorf("got calls %v for the tag committed in frame 2", c)
+	}
+}
+
+func TestModal(t *testing.T) {
+	var ops op.Ops
+	var r Router
+	bg, dlg := new(int), new(int)
+	frame := func(modal bool, focus event.Tag) {
+		ops.Reset()
+		stack := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+		pointer.InputOp{Tag: bg, Types: pointer.Press | pointer.Release}.Add(&ops)
+		key.InputOp{Tag: bg}.Add(&ops)
+		stack.Pop()
+		if modal {
+			m := event.ModalOp{}.Push(&ops)
+			stack := clip.Rect(image.Rect(25, 25, 75, 75)).Push(&ops)
+			pointer.InputOp{Tag: dlg, Types: pointer.Press | pointer.Release}.Add(&ops)
+			key.InputOp{Tag: dlg}.Add(&ops)
+			stack.Pop()
+			m.Pop()
+		}
+		if focus != nil {
+			key.FocusOp{Tag: focus}.Add(&ops)
+		}
+		r.Frame(&ops)
+	}
+	click := func(pos f32.Point) {
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos},
+			pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: pos},
+		)
+	}
+	frame(false, bg)
+	r.Events(bg)
+	click(f32.Pt(10, 10))
+	assertEventPointerTypeSequence(t, r.Events(bg), pointer.Press, pointer.Release)
+
+	// Opening the modal takes the focus from the background.
+	frame(true, nil)
+	assertFocus(t, &r, nil)
+	assertKeyEvent(t, r.Events(bg), false)
+	click(f32.Pt(10, 10))
+	click(f32.Pt(50, 50))
+	r.Queue(key.Event{Name: key.NameTab, State: key.Press})
+	r.Queue(key.Event{Name: "A", State: key.Press})
+	assertEventSequence(t, r.Events(bg))
+	evts := r.Events(dlg)
+	assertEventPointerTypeSequence(t, evts, pointer.Cancel, pointer.Press, pointer.Release)
+	assertFocus(t, &r, dlg)
+	assertKeyEvent(t, evts, true, key.Event{Name: "A", State: key.Press})
+
+	// The background can't take the focus while the modal is open.
+	frame(true, bg)
+	assertFocus(t, &r, dlg)
+	r.Queue(key.Event{Name: key.NameTab, State: key.Press})
+	assertFocus(t, &r, dlg)
+
+	// Closing the modal restores the background.
+	frame(false, bg)
+	assertFocus(t, &r, bg)
+	r.Events(bg)
+	click(f32.Pt(50, 50))
+	assertEventPointerTypeSequence(t, r.Events(bg), pointer.Press, pointer.Release)
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"gioui.org/f32"
+	"gioui.org/io/event"
 	"gioui.org/io/pointer"
 	"gioui.org/io/semantic"
 	"gioui.org/op"
@@ -126,3 +127,52 @@ func printTree(indent int, n SemanticNode) {
 		printTree(indent+1, c)
 	}
 }
+
+func TestSemanticLiveRegion(t *testing.T) {
+	var (
+		ops op.Ops
+		r   Router
+	)
+	status, alert := new(int), new(int)
+	frame := func(announce ...event.Tag) SemanticDesc {
+		ops.Reset()
+		for _, tag := range announce {
+			semantic.AnnounceOp{Tag: tag}.Add(&ops)
+		}
+		cl := clip.Rect(image.Rect(0, 0, 50, 50)).Push(&ops)
+		semantic.LiveRegionOp{Tag: status}.Add(&ops)
+		semantic.LabelOp("Saved").Add(&ops)
+		cl.Pop()
+		r.Frame(&ops)
+		tree := r.AppendSemantics(nil)
+		if len(tree[0].Children) != 1 {
+			t.Fatalf("got %d live regions, expected 1", len(tree[0].Children))
+		}
+		return tree[0].Children[0].Desc
+	}
+	d := frame()
+	if !d.LiveRegion || d.Politeness != semantic.Polite {
+		t.Errorf("got live region %v with politeness %v, expected a polite live region", d.LiveRegion, d.Politeness)
+	}
+	if d.Announce {
+		t.Error("unchanged live region announced")
+	}
+	if d := frame(alert); d.Announce {
+		t.Error("live region announced for the change of another region")
+	}
+	if d := frame(status); !d.Announce {
+		t.Error("changed live region not announced")
+	}
+	if d := frame(); d.Announce {
+		t.Error("announcement repeated in the following frame")
+	}
+
+	ops.Reset()
+	semantic.LiveRegionOp{Tag: alert, Politeness: semantic.Assertive}.Add(&ops)
+	semantic.AnnounceOp{Tag: alert}.Add(&ops)
+	r.Frame(&ops)
+	root := r.AppendSemantics(nil)[0].Desc
+	if !root.Announce || root.Politeness != semantic.Assertive {
+		t.Errorf("got announce %v with politeness %v, expected an assertive announcement", root.Announce, root.Politeness)
+	}
+}
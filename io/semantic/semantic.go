@@ -11,6 +11,7 @@ package semantic
 
 import (
 	"gioui.org/internal/ops"
+	"gioui.org/io/event"
 	"gioui.org/op"
 )
 
@@ -30,6 +31,9 @@ const (
 	Editor
 	RadioButton
 	Switch
+	// ToggleButton is a button with a checked state, such as a bold
+	// button of a toolbar. SelectedOp describes the checked state.
+	ToggleButton
 )
 
 // SelectedOp describes the selected state for components that have
@@ -39,6 +43,35 @@ type SelectedOp bool
 // DisabledOp describes the disabled state.
 type DisabledOp bool
 
+// LiveRegionOp marks the current semantic node as a live region, whose
+// content changes are announced by screen readers without moving focus.
+// Tag identifies the region across frames.
+type LiveRegionOp struct {
+	Tag        event.Tag
+	Politeness Politeness
+}
+
+// AnnounceOp signals that the content of the live region identified by
+// Tag changed in the current frame. AnnounceOp may be added anywhere in
+// the frame, such as where the new content is decided.
+type AnnounceOp struct {
+	Tag event.Tag
+}
+
+// Politeness describes how urgently changes to a live region are
+// announced.
+type Politeness uint8
+
+const (
+	// Polite changes are announced when the screen reader is idle, such
+	// as after the current utterance. Use Polite for status messages.
+	Polite Politeness = iota
+	// Assertive changes interrupt the current utterance, and are
+	// announced immediately. Reserve Assertive for urgent messages such
+	// as errors.
+	Assertive
+)
+
 func (l LabelOp) Add(o *op.Ops) {
 	s := string(l)
 	data := ops.Write1(&o.Internal, ops.TypeSemanticLabelLen, &s)
@@ -73,6 +106,17 @@ func (d DisabledOp) Add(o *op.Ops) {
 	}
 }
 
+func (l LiveRegionOp) Add(o *op.Ops) {
+	data := ops.Write1(&o.Internal, ops.TypeSemanticLiveLen, l.Tag)
+	data[0] = byte(ops.TypeSemanticLive)
+	data[1] = byte(l.Politeness)
+}
+
+func (a AnnounceOp) Add(o *op.Ops) {
+	data := ops.Write1(&o.Internal, ops.TypeSemanticAnnounceLen, a.Tag)
+	data[0] = byte(ops.TypeSemanticAnnounce)
+}
+
 func (c ClassOp) String() string {
 	switch c {
 	case Unknown:
@@ -87,7 +131,20 @@ func (c ClassOp) String() string {
 		return "RadioButton"
 	case Switch:
 		return "Switch"
+	case ToggleButton:
+		return "ToggleButton"
 	default:
 		panic("invalid ClassOp")
 	}
 }
+
+func (p Politeness) String() string {
+	switch p {
+	case Polite:
+		return "Polite"
+	case Assertive:
+		return "Assertive"
+	default:
+		panic("invalid Politeness")
+	}
+}
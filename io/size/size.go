@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package size implements the observation of content sizes.
+
+An Op records the size of some content, such as the Dimensions of a
+widget, for a tag. When the recorded size of a tag changes between two
+frames, an Event with the old and new sizes is delivered to the tag in
+the following frame. The first size recorded for a tag delivers no
+event.
+
+Use layout.Observe to record the size of a widget.
+*/
+package size
+
+import (
+	"encoding/binary"
+	"image"
+
+	"gioui.org/internal/ops"
+	"gioui.org/io/event"
+	"gioui.org/op"
+)
+
+// Op records the size of the content identified by Tag. If several
+// Ops record the size of a tag in a frame, the last one wins.
+type Op struct {
+	Tag  event.Tag
+	Size image.Point
+}
+
+// Event is delivered when the recorded size of a tag changes between
+// frames.
+type Event struct {
+	// Old is the size recorded in the previous frame.
+	Old image.Point
+	// New is the size recorded in the most recent frame.
+	New image.Point
+}
+
+func (op Op) Add(o *op.Ops) {
+	data := ops.Write1(&o.Internal, ops.TypeSizeLen, op.Tag)
+	data[0] = byte(ops.TypeSize)
+	bo := binary.LittleEndian
+	bo.PutUint32(data[1:], uint32(op.Size.X))
+	bo.PutUint32(data[5:], uint32(op.Size.Y))
+}
+
+func (Event) ImplementsEvent() {}
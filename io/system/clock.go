@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package system
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a source of the frame times of FrameEvent.Now, and the
+// times of scheduled redraws such as by op.InvalidateOp.At.
+type Clock interface {
+	// Now returns the current time of the clock.
+	Now() time.Time
+	// NewTimer returns a Timer that sends the time of the clock on its
+	// channel once the clock reaches t.
+	NewTimer(t time.Time) Timer
+}
+
+// Timer is a single pending notification of a Clock.
+type Timer interface {
+	// C returns the channel that receives the time.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, and releases it.
+	Stop()
+}
+
+// RealClock is the Clock of the real time, and the default Clock of
+// windows and routers.
+var RealClock Clock = realClock{}
+
+// ManualClock is a Clock that advances only by Advance and Set, for
+// stepping animations frame by frame in tests. It is safe for
+// concurrent use.
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []clockWaiter
+}
+
+type clockWaiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// manualTimer is a Timer of a ManualClock.
+type manualTimer struct {
+	clock *ManualClock
+	c     chan time.Time
+}
+
+type realClock struct{}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(t time.Time) Timer {
+	return realTimer{t: time.NewTimer(time.Until(t))}
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTimer) Stop() {
+	t.t.Stop()
+}
+
+// NewManualClock returns a ManualClock at the time start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the time of the clock.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a Timer that receives the time of the clock when
+// Advance or Set moves it to or past t.
+func (c *ManualClock) NewTimer(t time.Time) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if !t.After(c.now) {
+		ch <- c.now
+	} else {
+		c.waiters = append(c.waiters, clockWaiter{at: t, c: ch})
+	}
+	return manualTimer{clock: c, c: ch}
+}
+
+func (t manualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t manualTimer) Stop() {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, w := range c.waiters {
+		if w.c == t.c {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			break
+		}
+	}
+}
+
+// Advance the clock by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set the time of the clock to t, and notify the timers for times up to
+// t, earliest first. The clock doesn't move backwards.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(t)
+}
+
+func (c *ManualClock) set(t time.Time) {
+	if t.Before(c.now) {
+		return
+	}
+	c.now = t
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].at.Before(c.waiters[j].at)
+	})
+	n := 0
+	for _, w := range c.waiters {
+		if w.at.After(t) {
+			break
+		}
+		w.c <- t
+		n++
+	}
+	c.waiters = append(c.waiters[:0], c.waiters[n:]...)
+}
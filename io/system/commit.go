@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package system
+
+import (
+	"image"
+
+	"gioui.org/internal/ops"
+	"gioui.org/io/event"
+	"gioui.org/op"
+)
+
+// CommitOp requests a CommitEvent for Tag once the operations of the
+// frame are committed, for example to learn the bounds a widget was drawn
+// at. Add a CommitOp in every frame that needs an event. If several
+// CommitOps register a tag in a frame, the last one wins.
+type CommitOp struct {
+	Tag event.Tag
+}
+
+// CommitEvent is delivered to the tag of a CommitOp in the frame
+// following the committed frame.
+type CommitEvent struct {
+	// Frame is the ID of the committed frame.
+	Frame uint64
+	// Bounds is the bounding box of the clip area of the CommitOp, in
+	// window coordinates.
+	Bounds image.Rectangle
+}
+
+func (c CommitOp) Add(o *op.Ops) {
+	data := ops.Write1(&o.Internal, ops.TypeCommitLen, c.Tag)
+	data[0] = byte(ops.TypeCommit)
+}
+
+func (CommitEvent) ImplementsEvent() {}
@@ -26,6 +26,9 @@ type FrameEvent struct {
 	Size image.Point
 	// Insets is the insets to apply.
 	Insets Insets
+	// Locale is the BCP 47 language tag of the system locale, such as
+	// "en-US", or empty if unknown.
+	Locale string
 	// Frame completes the FrameEvent by drawing the graphical operations
 	// from ops into the window.
 	Frame func(frame *op.Ops)
@@ -8,6 +8,7 @@ package layout
 import (
 	"image"
 	"testing"
+	"time"
 
 	"gioui.org/op"
 )
@@ -47,3 +48,34 @@ func TestFlexAllocs(t *testing.T) {
 		t.Errorf("expected no allocs, got %f", allocs)
 	}
 }
+
+func TestListAnimationAllocs(t *testing.T) {
+	// allocs returns the allocations of laying out a list of many
+	// elements with the animation duration anim.
+	allocs := func(anim time.Duration) float64 {
+		var ops op.Ops
+		items := make([]int, 10000)
+		l := List{
+			Axis:       Vertical,
+			Animate:    anim,
+			ElementKey: func(i int) interface{} { return &items[i] },
+		}
+		gtx := Context{
+			Ops:         &ops,
+			Constraints: Exact(image.Pt(10, 50)),
+		}
+		layout := func() {
+			ops.Reset()
+			l.Layout(gtx, len(items), func(gtx Context, i int) Dimensions {
+				return Dimensions{Size: image.Pt(10, 10)}
+			})
+		}
+		layout()
+		layout()
+		return testing.AllocsPerRun(1, layout)
+	}
+	// Detecting changes doesn't allocate.
+	if a, exp := allocs(100*time.Millisecond), allocs(0); a != exp {
+		t.Errorf("expected %f allocs, got %f", exp, a)
+	}
+}
@@ -3,9 +3,11 @@
 package layout
 
 import (
+	"strings"
 	"time"
 
 	"gioui.org/f32"
+	"gioui.org/gesture"
 	"gioui.org/io/event"
 	"gioui.org/io/system"
 	"gioui.org/op"
@@ -26,8 +28,27 @@ type Context struct {
 	Queue event.Queue
 	// Now is the animation time.
 	Now time.Time
+	// LocaleTag is an optional hint of the language and formatting
+	// conventions for widgets, in the form of a BCP 47 language tag such
+	// as "en-US" or "ar-EG". Use Locale for its parsed form.
+	LocaleTag string
+	// Gesture tunes the gestures of widgets.
+	Gesture gesture.Config
 
 	*op.Ops
+
+	// measuring is set for contexts returned by Measuring.
+	measuring bool
+}
+
+// Locale is the parsed form of a BCP 47 language tag.
+type Locale struct {
+	// Language is the lower case language subtag, such as "en".
+	Language string
+	// Script is the title case script subtag, such as "Latn", if any.
+	Script string
+	// Region is the upper case region subtag, such as "US", if any.
+	Region string
 }
 
 // NewContext is a shorthand for
@@ -62,6 +83,7 @@ func NewContext(ops *op.Ops, e system.FrameEvent) Context {
 		Ops:         ops,
 		Now:         e.Now,
 		Queue:       e.Queue,
+		LocaleTag:   e.Locale,
 		Metric:      e.Metric,
 		Constraints: Exact(size),
 	}
@@ -72,6 +94,18 @@ func (c Context) Px(v unit.Value) int {
 	return c.Metric.Px(v)
 }
 
+// Dp maps v device independent pixels to pixels, rounded to the nearest
+// pixel. It is short for c.Px(unit.Dp(v)).
+func (c Context) Dp(v float32) int {
+	return c.Metric.Px(unit.Dp(v))
+}
+
+// Sp maps v scaled points to pixels, rounded to the nearest pixel. It is
+// short for c.Px(unit.Sp(v)).
+func (c Context) Sp(v float32) int {
+	return c.Metric.Px(unit.Sp(v))
+}
+
 // Events returns the events available for the key. If no
 // queue is configured, Events returns nil.
 func (c Context) Events(k event.Tag) []event.Event {
@@ -81,6 +115,105 @@ func (c Context) Events(k event.Tag) []event.Event {
 	return c.Queue.Events(k)
 }
 
+// Locale returns the parsed LocaleTag. The Locale is the zero value if
+// LocaleTag is empty.
+func (c Context) Locale() Locale {
+	return ParseLocale(c.LocaleTag)
+}
+
+// ParseLocale parses the language, script and region subtags of a BCP 47
+// language tag. Subtags are separated by '-' or '_'; other subtags such as
+// variants and extensions are ignored.
+func ParseLocale(tag string) Locale {
+	var l Locale
+	subtags := strings.FieldsFunc(tag, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(subtags) == 0 {
+		return l
+	}
+	l.Language = strings.ToLower(subtags[0])
+	for _, s := range subtags[1:] {
+		switch {
+		case len(s) == 4 && l.Script == "" && l.Region == "" && isAlpha(s):
+			l.Script = strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+		case (len(s) == 2 && isAlpha(s) || len(s) == 3 && isDigits(s)) && l.Region == "":
+			l.Region = strings.ToUpper(s)
+		default:
+			// Variants and extensions end the relevant subtags.
+			return l
+		}
+	}
+	return l
+}
+
+// RTL reports whether the main writing direction of the locale is
+// right-to-left.
+func (l Locale) RTL() bool {
+	switch l.Script {
+	case "Arab", "Hebr", "Thaa", "Syrc", "Nkoo", "Adlm", "Rohg":
+		return true
+	case "":
+	default:
+		return false
+	}
+	switch l.Language {
+	case "ar", "he", "iw", "fa", "ur", "ps", "sd", "yi", "dv", "ug", "ckb", "syr":
+		return true
+	}
+	return false
+}
+
+// String returns the locale in the form of a BCP 47 language tag.
+func (l Locale) String() string {
+	s := l.Language
+	if l.Script != "" {
+		s += "-" + l.Script
+	}
+	if l.Region != "" {
+		s += "-" + l.Region
+	}
+	return s
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Measuring returns a copy of this context for computing the dimensions
+// of widgets without drawing them, such as for widgets outside the
+// visible area of a virtualized container. By convention, widgets
+// laid out with a measuring context compute their Dimensions exactly
+// as they would otherwise, but process no events and record no drawing
+// or input operations. Layouts may still record transformations and
+// macros.
+//
+// The returned Context has a nil Queue.
+func (c Context) Measuring() Context {
+	c.Queue = nil
+	c.measuring = true
+	return c
+}
+
+// IsMeasuring reports whether the context was returned by Measuring.
+func (c Context) IsMeasuring() bool {
+	return c.measuring
+}
+
 // Disabled returns a copy of this context with a nil Queue,
 // blocking events to widgets using it.
 //
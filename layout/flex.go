@@ -6,6 +6,7 @@ import (
 	"image"
 
 	"gioui.org/op"
+	"gioui.org/unit"
 )
 
 // Flex lays out child elements along an axis,
@@ -19,23 +20,56 @@ type Flex struct {
 	// Alignment is the alignment in the cross axis.
 	Alignment Alignment
 	// WeightSum is the sum of weights used for the weighted
-	// size of Flexed children. If WeightSum is zero, the sum
-	// of all Flexed weights is used.
+	// size of Flexed and Flexible children. If WeightSum is zero,
+	// the sum of all their grow weights is used.
 	WeightSum float32
+	// Measure enables a measuring pass that determines the natural
+	// main axis size of every Rigid child, with loose main axis
+	// constraints. Rigid children are then limited to their natural
+	// sizes, or to shares of the available space proportional to their
+	// natural sizes if they don't fit. Without Measure, a Rigid child
+	// may take all the space left by the children before it.
+	Measure bool
+	// BaselineGrid, if non-zero, snaps the baseline shared by
+	// horizontal Baseline aligned children to the grid, measured from
+	// the top of the Flex, and rounds the height up to the grid. See
+	// SnapBaseline.
+	BaselineGrid unit.Value
 }
 
 // FlexChild is the descriptor for a Flex child.
 type FlexChild struct {
 	flex   bool
 	weight float32
+	// natural is set for Flexible children, whose sizes start from
+	// their natural sizes and shrink by the shrink weights.
+	natural bool
+	shrink  float32
+	cross   CrossSize
 
 	widget Widget
 
 	// Scratch space.
 	call op.CallOp
 	dims Dimensions
+	// limit is the maximum main axis size from the measuring pass.
+	limit int
 }
 
+// CrossSize is the cross axis sizing policy of a Flex child.
+type CrossSize uint8
+
+const (
+	// CrossNatural lays out the child with the cross axis constraints
+	// of the Flex, and aligns it by the Flex Alignment.
+	CrossNatural CrossSize = iota
+	// CrossStretch lays out the child with exact cross axis constraints
+	// of the cross axis size of the Flex: the largest cross axis size of
+	// the CrossNatural children, within the cross axis constraints.
+	// Stretched children are measured before they are laid out.
+	CrossStretch
+)
+
 // Spacing determine the spacing mode for a Flex.
 type Spacing uint8
 
@@ -77,9 +111,35 @@ func Flexed(weight float32, widget Widget) FlexChild {
 	}
 }
 
+// Flexible returns a Flex child that starts from its natural main axis
+// size, measured with loose constraints. Space left over from Rigid
+// children and the natural sizes is distributed among Flexible and
+// Flexed children in proportion to their grow weights, as for Flexed.
+// If the natural sizes overflow the space left over from Rigid
+// children, the overflow is taken from the Flexible children in
+// proportion to their shrink weights, and Flexed children are left
+// empty. A Flexible child with zero shrink weight keeps its natural
+// size if it fits.
+func Flexible(grow, shrink float32, widget Widget) FlexChild {
+	return FlexChild{
+		flex:    true,
+		weight:  grow,
+		natural: true,
+		shrink:  shrink,
+		widget:  widget,
+	}
+}
+
+// Cross returns the child with the cross axis policy s. The main axis
+// policy is determined by Rigid, Flexed or Flexible.
+func (c FlexChild) Cross(s CrossSize) FlexChild {
+	c.cross = s
+	return c
+}
+
 // Layout a list of children. The position of the children are
 // determined by the specified order, but Rigid children are laid out
-// before Flexed children.
+// before Flexed and Flexible children.
 func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 	size := 0
 	cs := gtx.Constraints
@@ -88,15 +148,22 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 	remaining := mainMax
 	var totalWeight float32
 	cgtx := gtx
+	if f.Measure {
+		f.measure(gtx, children, mainMax, crossMin, crossMax)
+	}
 	// Lay out Rigid children.
 	for i, child := range children {
 		if child.flex {
 			totalWeight += child.weight
 			continue
 		}
+		max := remaining
+		if f.Measure && child.limit < max {
+			max = child.limit
+		}
 		macro := op.Record(gtx.Ops)
-		cgtx.Constraints = f.Axis.constraints(0, remaining, crossMin, crossMax)
-		dims := child.widget(cgtx)
+		cgtx.Constraints = f.Axis.constraints(0, max, crossMin, crossMax)
+		dims := child.widget(child.gtx(cgtx))
 		c := macro.Stop()
 		sz := f.Axis.Convert(dims.Size).X
 		size += sz
@@ -110,28 +177,44 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 	if w := f.WeightSum; w != 0 {
 		totalWeight = w
 	}
+	natural, totalShrink := f.measureNatural(gtx, children, mainMax, crossMin, crossMax)
 	// fraction is the rounding error from a Flex weighting.
 	var fraction float32
-	flexTotal := remaining
-	// Lay out Flexed children.
+	// flexTotal is the space to grow by, or the overflow to shrink by
+	// if negative.
+	flexTotal := remaining - natural
+	// Lay out Flexed and Flexible children.
 	for i, child := range children {
 		if !child.flex {
 			continue
 		}
 		var flexSize int
-		if remaining > 0 && totalWeight > 0 {
+		if child.natural {
+			flexSize = child.limit
+		}
+		switch {
+		case flexTotal > 0 && totalWeight > 0:
 			// Apply weight and add any leftover fraction from a
 			// previous Flexed.
 			childSize := float32(flexTotal) * child.weight / totalWeight
-			flexSize = int(childSize + fraction + .5)
-			fraction = childSize - float32(flexSize)
-			if flexSize > remaining {
-				flexSize = remaining
+			grow := int(childSize + fraction + .5)
+			fraction = childSize - float32(grow)
+			flexSize += grow
+		case flexTotal < 0 && totalShrink > 0 && child.shrink > 0:
+			childSize := float32(-flexTotal) * child.shrink / totalShrink
+			shrink := int(childSize + fraction + .5)
+			fraction = childSize - float32(shrink)
+			flexSize -= shrink
+			if flexSize < 0 {
+				flexSize = 0
 			}
 		}
+		if flexSize > remaining {
+			flexSize = remaining
+		}
 		macro := op.Record(gtx.Ops)
 		cgtx.Constraints = f.Axis.constraints(flexSize, flexSize, crossMin, crossMax)
-		dims := child.widget(cgtx)
+		dims := child.widget(child.gtx(cgtx))
 		c := macro.Stop()
 		sz := f.Axis.Convert(dims.Size).X
 		size += sz
@@ -144,7 +227,18 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 	}
 	var maxCross int
 	var maxBaseline int
+	// stretchCross is the largest measured cross size of the stretched
+	// children, for stretching them when no other child sizes the cross
+	// axis.
+	stretchCross, stretchOnly := 0, true
 	for _, child := range children {
+		if child.cross == CrossStretch {
+			if c := f.Axis.Convert(child.dims.Size).Y; c > stretchCross {
+				stretchCross = c
+			}
+			continue
+		}
+		stretchOnly = false
 		if c := f.Axis.Convert(child.dims.Size).Y; c > maxCross {
 			maxCross = c
 		}
@@ -152,6 +246,28 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 			maxBaseline = b
 		}
 	}
+	if g := gtx.Px(f.BaselineGrid); g > 0 && f.Alignment == Baseline && f.Axis == Horizontal {
+		maxBaseline = snapUp(maxBaseline, g)
+		for _, child := range children {
+			b := child.dims.Size.Y - child.dims.Baseline
+			if c := maxBaseline - b + child.dims.Size.Y; c > maxCross {
+				maxCross = c
+			}
+		}
+		maxCross = snapUp(maxCross, g)
+	}
+	if stretchOnly {
+		maxCross = stretchCross
+	}
+	f.stretch(gtx, children, maxCross, crossMin, crossMax)
+	for _, child := range children {
+		if child.cross != CrossStretch {
+			continue
+		}
+		if c := f.Axis.Convert(child.dims.Size).Y; c > maxCross {
+			maxCross = c
+		}
+	}
 	var space int
 	if mainMin > size {
 		space = mainMin - size
@@ -173,12 +289,14 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 		dims := child.dims
 		b := dims.Size.Y - dims.Baseline
 		var cross int
-		switch f.Alignment {
-		case End:
+		switch {
+		case child.cross == CrossStretch:
+			// Stretched children fill the cross axis.
+		case f.Alignment == End:
 			cross = maxCross - f.Axis.Convert(dims.Size).Y
-		case Middle:
+		case f.Alignment == Middle:
 			cross = (maxCross - f.Axis.Convert(dims.Size).Y) / 2
-		case Baseline:
+		case f.Alignment == Baseline:
 			if f.Axis == Horizontal {
 				cross = maxBaseline - b
 			}
@@ -216,7 +334,109 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 		}
 	}
 	sz := f.Axis.Convert(image.Pt(mainSize, maxCross))
-	return Dimensions{Size: sz, Baseline: sz.Y - maxBaseline}
+	// Children that ignore their constraints may produce sizes outside
+	// the constraints.
+	csz := cs.Constrain(sz)
+	return Dimensions{Size: csz, Baseline: csz.Y - maxBaseline}
+}
+
+// gtx returns the context for laying out the child in the main axis
+// passes of Flex.Layout. Stretched children are measured, and laid out
+// by stretch.
+func (c FlexChild) gtx(gtx Context) Context {
+	if c.cross == CrossStretch {
+		return gtx.Measuring()
+	}
+	return gtx
+}
+
+// stretch lays out the stretched children with their main axis sizes
+// and the cross axis size cross, bounded by the cross axis constraints.
+func (f Flex) stretch(gtx Context, children []FlexChild, cross, crossMin, crossMax int) {
+	if cross < crossMin {
+		cross = crossMin
+	}
+	if cross > crossMax {
+		cross = crossMax
+	}
+	cgtx := gtx
+	for i, child := range children {
+		if child.cross != CrossStretch {
+			continue
+		}
+		sz := f.Axis.Convert(child.dims.Size).X
+		macro := op.Record(gtx.Ops)
+		cgtx.Constraints = f.Axis.constraints(sz, sz, cross, cross)
+		dims := child.widget(cgtx)
+		children[i].call = macro.Stop()
+		children[i].dims = dims
+	}
+}
+
+// measure the natural sizes of the Rigid children, and set their limits.
+func (f Flex) measure(gtx Context, children []FlexChild, mainMax, crossMin, crossMax int) {
+	mgtx := gtx.Measuring()
+	mgtx.Constraints = f.Axis.constraints(0, mainMax, crossMin, crossMax)
+	// Discard the operations of children that don't skip them when
+	// measuring.
+	macro := op.Record(gtx.Ops)
+	total := 0
+	for i, child := range children {
+		if child.flex {
+			continue
+		}
+		sz := f.Axis.Convert(child.widget(mgtx).Size).X
+		children[i].limit = sz
+		total += sz
+	}
+	macro.Stop()
+	if total <= mainMax {
+		return
+	}
+	// Share the space in proportion to the natural sizes.
+	var fraction float32
+	for i, child := range children {
+		if child.flex {
+			continue
+		}
+		share := float32(mainMax) * float32(child.limit) / float32(total)
+		limit := int(share + fraction + .5)
+		fraction = share - float32(limit)
+		children[i].limit = limit
+	}
+}
+
+// measureNatural measures the natural sizes of the Flexible children,
+// stores them in their limits, and returns the sum of the sizes and of
+// the shrink weights.
+func (f Flex) measureNatural(gtx Context, children []FlexChild, mainMax, crossMin, crossMax int) (int, float32) {
+	var (
+		total       int
+		totalShrink float32
+		macro       op.MacroOp
+		recording   bool
+	)
+	for i, child := range children {
+		if !child.natural {
+			continue
+		}
+		if !recording {
+			// Discard the operations of children that don't skip them
+			// when measuring.
+			macro = op.Record(gtx.Ops)
+			recording = true
+		}
+		mgtx := gtx.Measuring()
+		mgtx.Constraints = f.Axis.constraints(0, mainMax, crossMin, crossMax)
+		sz := f.Axis.Convert(child.widget(mgtx).Size).X
+		children[i].limit = sz
+		total += sz
+		totalShrink += child.shrink
+	}
+	if recording {
+		macro.Stop()
+	}
+	return total, totalShrink
 }
 
 func (s Spacing) String() string {
@@ -3,9 +3,12 @@
 package layout
 
 import (
+	"fmt"
 	"image"
 
 	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/size"
 	"gioui.org/op"
 	"gioui.org/unit"
 )
@@ -78,6 +81,13 @@ func Exact(size image.Point) Constraints {
 	}
 }
 
+// Skeleton returns inert Dimensions of the given size, for widgets
+// that take up space without drawing or handling input, such as
+// placeholders for widgets skipped by a virtualized container.
+func Skeleton(size image.Point) Dimensions {
+	return Dimensions{Size: size}
+}
+
 // FPt converts an point to a f32.Point.
 func FPt(p image.Point) f32.Point {
 	return f32.Point{
@@ -109,45 +119,109 @@ func (c Constraints) Constrain(size image.Point) image.Point {
 	return size
 }
 
+// SubMax returns c with the maximum decreased by pt and floored at zero.
+// The minimum is clamped to the new maximum.
+func (c Constraints) SubMax(pt image.Point) Constraints {
+	c.Max = c.Max.Sub(pt)
+	if c.Max.X < 0 {
+		c.Max.X = 0
+	}
+	if c.Max.Y < 0 {
+		c.Max.Y = 0
+	}
+	if c.Min.X > c.Max.X {
+		c.Min.X = c.Max.X
+	}
+	if c.Min.Y > c.Max.Y {
+		c.Min.Y = c.Max.Y
+	}
+	return c
+}
+
+// AddMin returns c with the minimum increased by pt and clamped to the
+// range [0;max].
+func (c Constraints) AddMin(pt image.Point) Constraints {
+	c.Min = Constraints{Max: c.Max}.Constrain(c.Min.Add(pt))
+	return c
+}
+
+// ConstrainAspect scales a size uniformly to fit the constraints, keeping
+// its aspect ratio. If no scale satisfies both the minimum and maximum
+// constraints, the size fits the maximum and is then constrained like
+// Constrain, at the expense of the aspect ratio.
+func (c Constraints) ConstrainAspect(size image.Point) image.Point {
+	if size.X <= 0 || size.Y <= 0 {
+		return c.Constrain(size)
+	}
+	w, h := float32(size.X), float32(size.Y)
+	scale := float32(1)
+	if s := float32(c.Min.X) / w; s > scale {
+		scale = s
+	}
+	if s := float32(c.Min.Y) / h; s > scale {
+		scale = s
+	}
+	if s := float32(c.Max.X) / w; s < scale {
+		scale = s
+	}
+	if s := float32(c.Max.Y) / h; s < scale {
+		scale = s
+	}
+	size = image.Pt(int(w*scale+.5), int(h*scale+.5))
+	return c.Constrain(size)
+}
+
+// Validate returns an error if c has negative sizes, or if its minimum
+// exceeds its maximum. Containers never pass invalid constraints to their
+// children.
+func (c Constraints) Validate() error {
+	switch {
+	case c.Min.X < 0 || c.Min.Y < 0 || c.Max.X < 0 || c.Max.Y < 0:
+		return fmt.Errorf("layout: negative constraints %v", c)
+	case c.Min.X > c.Max.X || c.Min.Y > c.Max.Y:
+		return fmt.Errorf("layout: minimum exceeds maximum in %v", c)
+	}
+	return nil
+}
+
 // Inset adds space around a widget by decreasing its maximum
 // constraints. The minimum constraints will be adjusted to ensure
 // they do not exceed the maximum.
+//
+// Negative values are outsets: they increase the maximum constraints
+// and offset the widget outwards, such that it may draw outside the
+// bounds of the Inset. The returned dimensions exclude the outsets,
+// so outset content overlaps its neighbours, but are never smaller than
+// the minimum constraints.
 type Inset struct {
 	Top, Bottom, Left, Right unit.Value
 }
 
-// Layout a widget.
+// Layout a widget. Insets that exceed the maximum constraints along an
+// axis are dropped, leaving no space for the widget along that axis.
 func (in Inset) Layout(gtx Context, w Widget) Dimensions {
 	top := gtx.Px(in.Top)
 	right := gtx.Px(in.Right)
 	bottom := gtx.Px(in.Bottom)
 	left := gtx.Px(in.Left)
-	mcs := gtx.Constraints
-	mcs.Max.X -= left + right
-	if mcs.Max.X < 0 {
-		left = 0
-		right = 0
-		mcs.Max.X = 0
-	}
-	if mcs.Min.X > mcs.Max.X {
-		mcs.Min.X = mcs.Max.X
-	}
-	mcs.Max.Y -= top + bottom
-	if mcs.Max.Y < 0 {
-		bottom = 0
-		top = 0
-		mcs.Max.Y = 0
-	}
-	if mcs.Min.Y > mcs.Max.Y {
-		mcs.Min.Y = mcs.Max.Y
-	}
-	gtx.Constraints = mcs
+	cs := gtx.Constraints
+	gtx.Constraints = cs.SubMax(image.Pt(left+right, top+bottom))
+	if cs.Max.X < left+right {
+		left, right = 0, 0
+	}
+	if cs.Max.Y < top+bottom {
+		top, bottom = 0, 0
+	}
 	trans := op.Offset(FPt(image.Point{X: left, Y: top})).Push(gtx.Ops)
 	dims := w(gtx)
 	trans.Pop()
+	sz := dims.Size.Add(image.Point{X: right + left, Y: top + bottom})
+	// Widgets that ignore their constraints may produce sizes outside
+	// the constraints, and sizes exclude outsets.
+	csz := cs.Constrain(sz)
 	return Dimensions{
-		Size:     dims.Size.Add(image.Point{X: right + left, Y: top + bottom}),
-		Baseline: dims.Baseline + bottom,
+		Size:     csz,
+		Baseline: dims.Baseline + bottom + csz.Y - sz.Y,
 	}
 }
 
@@ -157,6 +231,65 @@ func UniformInset(v unit.Value) Inset {
 	return Inset{Top: v, Right: v, Bottom: v, Left: v}
 }
 
+// SnapBaseline pads a widget vertically so that the distance from its
+// top to its baseline and its height are multiples of Grid. Widgets
+// snapped to the same grid and stacked vertically have their baselines
+// on the grid lines, for a consistent vertical rhythm of text.
+//
+// The padding depends only on the dimensions of the widget, and grows
+// the widget by less than two grid units. Widgets without a baseline
+// only have their heights rounded up. A zero Grid disables snapping.
+type SnapBaseline struct {
+	Grid unit.Value
+}
+
+// Layout a widget. The padding is limited by the maximum constraints,
+// which may leave the baseline off the grid.
+func (s SnapBaseline) Layout(gtx Context, w Widget) Dimensions {
+	g := gtx.Px(s.Grid)
+	if g <= 0 {
+		return w(gtx)
+	}
+	cs := gtx.Constraints
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx)
+	call := macro.Stop()
+	var pad int
+	if dims.Baseline != 0 {
+		top := dims.Size.Y - dims.Baseline
+		pad = snapUp(top, g) - top
+	}
+	h := snapUp(dims.Size.Y+pad, g)
+	if h > cs.Max.Y {
+		h = cs.Max.Y
+		if h < dims.Size.Y {
+			h = dims.Size.Y
+		}
+		if extra := h - dims.Size.Y; pad > extra {
+			pad = extra
+		}
+	}
+	trans := op.Offset(FPt(image.Pt(0, pad))).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	trans.Pop()
+	baseline := dims.Baseline
+	if baseline != 0 {
+		baseline = h - (dims.Size.Y - dims.Baseline + pad)
+	}
+	return Dimensions{Size: image.Pt(dims.Size.X, h), Baseline: baseline}
+}
+
+// snapUp rounds v up to a multiple of g.
+func snapUp(v, g int) int {
+	if r := v % g; r != 0 {
+		if v < 0 {
+			return v - r
+		}
+		return v + g - r
+	}
+	return v
+}
+
 // Layout a widget according to the direction.
 // The widget is called with the context constraints minimum cleared.
 func (d Direction) Layout(gtx Context, w Widget) Dimensions {
@@ -190,7 +323,10 @@ func (d Direction) Layout(gtx Context, w Widget) Dimensions {
 	}
 }
 
-// Position calculates widget position according to the direction.
+// Position calculates the offset of a widget of the given size inside
+// bounds according to the direction. It does not lay out anything, and
+// is useful for custom layouts that need to place content the same way
+// as Layout.
 func (d Direction) Position(widget, bounds image.Point) image.Point {
 	var p image.Point
 
@@ -319,3 +455,47 @@ func (d Direction) String() string {
 		panic("unreachable")
 	}
 }
+
+// IfFits returns a widget that lays out primary, and below it secondary
+// if secondary fits in the vertical space left by primary. Secondary is
+// measured with the full constraints, so it is omitted rather than
+// squeezed when the space is insufficient.
+func IfFits(primary, secondary Widget) Widget {
+	return func(gtx Context) Dimensions {
+		cs := gtx.Constraints
+		gtx.Constraints.Min.Y = 0
+		dims := primary(gtx)
+		left := cs.Max.Y - dims.Size.Y
+		m := op.Record(gtx.Ops)
+		sdims := secondary(gtx)
+		call := m.Stop()
+		if sdims.Size.Y > left {
+			return Dimensions{
+				Size:     cs.Constrain(dims.Size),
+				Baseline: dims.Baseline,
+			}
+		}
+		trans := op.Offset(FPt(image.Pt(0, dims.Size.Y))).Push(gtx.Ops)
+		call.Add(gtx.Ops)
+		trans.Pop()
+		sz := image.Pt(dims.Size.X, dims.Size.Y+sdims.Size.Y)
+		if sdims.Size.X > sz.X {
+			sz.X = sdims.Size.X
+		}
+		return Dimensions{
+			Size:     cs.Constrain(sz),
+			Baseline: dims.Baseline + sdims.Size.Y,
+		}
+	}
+}
+
+// Observe lays out w and records its size for tag with a size.Op, so
+// that tag receives a size.Event in the following frame whenever the
+// size changes. Measuring contexts record nothing.
+func Observe(gtx Context, tag event.Tag, w Widget) Dimensions {
+	dims := w(gtx)
+	if !gtx.IsMeasuring() {
+		size.Op{Tag: tag, Size: dims.Size}.Add(gtx.Ops)
+	}
+	return dims
+}
@@ -6,7 +6,12 @@ import (
 	"image"
 	"testing"
 
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
 	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/unit"
 )
 
 func TestStack(t *testing.T) {
@@ -30,6 +35,215 @@ func TestStack(t *testing.T) {
 	}
 }
 
+func TestStackOffset(t *testing.T) {
+	var r router.Router
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+		Queue:       &r,
+	}
+	tag := new(int)
+	badge := Stacked(func(gtx Context) Dimensions {
+		sz := image.Pt(10, 10)
+		defer clip.Rect(image.Rectangle{Max: sz}).Push(gtx.Ops).Pop()
+		pointer.InputOp{Tag: tag, Types: pointer.Press}.Add(gtx.Ops)
+		return Dimensions{Size: sz}
+	})
+	badge.Offset = image.Pt(5, -5)
+	dims := Stack{Alignment: NE}.Layout(gtx,
+		Stacked(func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Pt(50, 50)}
+		}),
+		badge,
+	)
+	if got, exp := dims.Size, image.Pt(50, 50); got != exp {
+		t.Errorf("got size %v; expected %v", got, exp)
+	}
+	r.Frame(gtx.Ops)
+	// The badge is aligned at (40, 0) and offset to (45, -5).
+	r.Queue(pointer.Event{
+		Type:     pointer.Press,
+		Position: f32.Pt(46, -4),
+	})
+	var pressed bool
+	for _, e := range r.Events(tag) {
+		if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+			pressed = true
+			if got, exp := e.Position, f32.Pt(1, 1); got != exp {
+				t.Errorf("got press position %v; expected %v", got, exp)
+			}
+		}
+	}
+	if !pressed {
+		t.Error("offset child did not receive press at its offset position")
+	}
+}
+
+func TestFlexMeasure(t *testing.T) {
+	// text wraps a line 80 pixels wide into lines of 10 pixels.
+	text := func(gtx Context) Dimensions {
+		w := gtx.Constraints.Max.X
+		if w > 80 {
+			w = 80
+		}
+		lines := (80 + w - 1) / w
+		return Dimensions{Size: image.Pt(w, lines*10)}
+	}
+	// image keeps a 2:1 aspect ratio at the maximum height.
+	img := func(gtx Context) Dimensions {
+		sz := image.Pt(2*gtx.Constraints.Max.Y, gtx.Constraints.Max.Y)
+		return Dimensions{Size: gtx.Constraints.Constrain(sz)}
+	}
+	var sizes [2]image.Point
+	record := func(i int, w Widget) Widget {
+		return func(gtx Context) Dimensions {
+			dims := w(gtx)
+			if !gtx.IsMeasuring() {
+				sizes[i] = dims.Size
+			}
+			return dims
+		}
+	}
+	for _, tc := range []struct {
+		measure bool
+		max     image.Point
+		exp     [2]image.Point
+	}{
+		// Without measuring, the text takes the space of the image.
+		{false, image.Pt(100, 30), [2]image.Point{{80, 10}, {20, 30}}},
+		{true, image.Pt(200, 30), [2]image.Point{{80, 10}, {60, 30}}},
+		// The natural sizes of 80 and 60 pixels share 100 pixels.
+		{true, image.Pt(100, 30), [2]image.Point{{57, 20}, {43, 30}}},
+	} {
+		gtx := Context{
+			Ops:         new(op.Ops),
+			Constraints: Constraints{Max: tc.max},
+		}
+		sizes = [2]image.Point{}
+		dims := Flex{Measure: tc.measure}.Layout(gtx,
+			Rigid(record(0, text)),
+			Rigid(record(1, img)),
+		)
+		if sizes != tc.exp {
+			t.Errorf("measure %v, max %v: got sizes %v, expected %v", tc.measure, tc.max, sizes, tc.exp)
+		}
+		if got, exp := dims.Size, image.Pt(tc.exp[0].X+tc.exp[1].X, 30); got != exp {
+			t.Errorf("measure %v, max %v: got size %v, expected %v", tc.measure, tc.max, got, exp)
+		}
+	}
+}
+
+func TestFlexShrink(t *testing.T) {
+	var sizes [3]int
+	// box has a natural width of w pixels, and records its width.
+	box := func(i, w int) Widget {
+		return func(gtx Context) Dimensions {
+			sz := gtx.Constraints.Constrain(image.Pt(w, 10))
+			if !gtx.IsMeasuring() {
+				sizes[i] = sz.X
+			}
+			return Dimensions{Size: sz}
+		}
+	}
+	for _, tc := range []struct {
+		name     string
+		max      int
+		children func() []FlexChild
+		exp      [3]int
+	}{
+		// The overflow of 40 pixels is shared 1:3.
+		{"overflow", 100, func() []FlexChild {
+			return []FlexChild{Flexible(1, 1, box(0, 60)), Flexible(1, 3, box(1, 80))}
+		}, [3]int{50, 50}},
+		// The Rigid child leaves 80 pixels, an overflow of 60 pixels.
+		{"overflow rigid", 100, func() []FlexChild {
+			return []FlexChild{Rigid(box(2, 20)), Flexible(1, 1, box(0, 60)), Flexible(1, 3, box(1, 80))}
+		}, [3]int{45, 35, 20}},
+		// Children without shrink weight keep their natural sizes.
+		{"overflow zero shrink", 100, func() []FlexChild {
+			return []FlexChild{Flexible(1, 0, box(0, 30)), Flexible(1, 1, box(1, 80))}
+		}, [3]int{30, 70}},
+		// The extra 60 pixels are shared 1:2 by grow weight.
+		{"underfill", 200, func() []FlexChild {
+			return []FlexChild{Flexible(1, 1, box(0, 60)), Flexible(2, 1, box(1, 80))}
+		}, [3]int{80, 120}},
+		// Flexed children grow from zero.
+		{"underfill flexed", 200, func() []FlexChild {
+			return []FlexChild{Flexible(1, 1, box(0, 60)), Flexible(2, 1, box(1, 80)), Flexed(1, box(2, 0))}
+		}, [3]int{75, 110, 15}},
+	} {
+		gtx := Context{
+			Ops:         new(op.Ops),
+			Constraints: Constraints{Max: image.Pt(tc.max, 10)},
+		}
+		sizes = [3]int{}
+		Flex{}.Layout(gtx, tc.children()...)
+		if sizes != tc.exp {
+			t.Errorf("%s: got sizes %v, expected %v", tc.name, sizes, tc.exp)
+		}
+	}
+}
+
+func TestFlexCross(t *testing.T) {
+	var sizes [2]image.Point
+	// box has a natural size of sz, and records its size.
+	box := func(i int, sz image.Point) Widget {
+		return func(gtx Context) Dimensions {
+			sz := gtx.Constraints.Constrain(sz)
+			if !gtx.IsMeasuring() {
+				sizes[i] = sz
+			}
+			return Dimensions{Size: sz}
+		}
+	}
+	for _, tc := range []struct {
+		name     string
+		axis     Axis
+		children func() []FlexChild
+		exp      [2]image.Point
+		size     image.Point
+	}{
+		{"rigid natural", Horizontal, func() []FlexChild {
+			return []FlexChild{Rigid(box(0, image.Pt(20, 10))), Rigid(box(1, image.Pt(30, 40)))}
+		}, [2]image.Point{{20, 10}, {30, 40}}, image.Pt(50, 40)},
+		// The stretched child keeps its width, and takes the height
+		// of the other child.
+		{"rigid stretch", Horizontal, func() []FlexChild {
+			return []FlexChild{Rigid(box(0, image.Pt(20, 10))).Cross(CrossStretch), Rigid(box(1, image.Pt(30, 40)))}
+		}, [2]image.Point{{20, 40}, {30, 40}}, image.Pt(50, 40)},
+		// Stretched children fill the Flex.
+		{"vertical stretch", Vertical, func() []FlexChild {
+			return []FlexChild{Rigid(box(0, image.Pt(20, 10))).Cross(CrossStretch), Rigid(box(1, image.Pt(30, 40)))}
+		}, [2]image.Point{{30, 10}, {30, 40}}, image.Pt(30, 50)},
+		// The flexed child takes the remaining width, and keeps its
+		// natural height.
+		{"flexed natural", Horizontal, func() []FlexChild {
+			return []FlexChild{Flexed(1, box(0, image.Pt(0, 10))), Rigid(box(1, image.Pt(30, 40)))}
+		}, [2]image.Point{{70, 10}, {30, 40}}, image.Pt(100, 40)},
+		{"flexed stretch", Horizontal, func() []FlexChild {
+			return []FlexChild{Flexed(1, box(0, image.Pt(0, 10))).Cross(CrossStretch), Rigid(box(1, image.Pt(30, 40)))}
+		}, [2]image.Point{{70, 40}, {30, 40}}, image.Pt(100, 40)},
+		// Without other children, the stretched children take the
+		// largest of their heights.
+		{"all stretch", Horizontal, func() []FlexChild {
+			return []FlexChild{Rigid(box(0, image.Pt(20, 10))).Cross(CrossStretch), Rigid(box(1, image.Pt(30, 40))).Cross(CrossStretch)}
+		}, [2]image.Point{{20, 40}, {30, 40}}, image.Pt(50, 40)},
+	} {
+		gtx := Context{
+			Ops:         new(op.Ops),
+			Constraints: Constraints{Max: image.Pt(100, 100)},
+		}
+		sizes = [2]image.Point{}
+		dims := Flex{Axis: tc.axis, Alignment: Middle}.Layout(gtx, tc.children()...)
+		if sizes != tc.exp {
+			t.Errorf("%s: got sizes %v, expected %v", tc.name, sizes, tc.exp)
+		}
+		if dims.Size != tc.size {
+			t.Errorf("%s: got size %v, expected %v", tc.name, dims.Size, tc.size)
+		}
+	}
+}
+
 func TestDirection(t *testing.T) {
 	max := image.Pt(100, 100)
 	for _, tc := range []struct {
@@ -62,3 +276,334 @@ func TestDirection(t *testing.T) {
 		})
 	}
 }
+
+func TestDirectionPosition(t *testing.T) {
+	bounds := image.Pt(100, 50)
+	widget := image.Pt(20, 10)
+	for _, tc := range []struct {
+		dir Direction
+		exp image.Point
+	}{
+		{NW, image.Pt(0, 0)},
+		{N, image.Pt(40, 0)},
+		{NE, image.Pt(80, 0)},
+		{E, image.Pt(80, 20)},
+		{SE, image.Pt(80, 40)},
+		{S, image.Pt(40, 40)},
+		{SW, image.Pt(0, 40)},
+		{W, image.Pt(0, 20)},
+		{Center, image.Pt(40, 20)},
+	} {
+		t.Run(tc.dir.String(), func(t *testing.T) {
+			if got := tc.dir.Position(widget, bounds); got != tc.exp {
+				t.Errorf("got %v; expected %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestInsetNegative(t *testing.T) {
+	var r router.Router
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(100, 100)),
+		Queue:       &r,
+	}
+	tag := new(int)
+	var cs Constraints
+	dims := Inset{Top: unit.Px(-10)}.Layout(gtx, func(gtx Context) Dimensions {
+		cs = gtx.Constraints
+		sz := gtx.Constraints.Min
+		defer clip.Rect(image.Rectangle{Max: sz}).Push(gtx.Ops).Pop()
+		pointer.InputOp{Tag: tag, Types: pointer.Press}.Add(gtx.Ops)
+		return Dimensions{Size: sz}
+	})
+	if got, exp := cs.Max, image.Pt(100, 110); got != exp {
+		t.Errorf("got max constraints %v; expected %v", got, exp)
+	}
+	// The size excluding the outset is constrained to the minimum.
+	if got, exp := dims.Size, image.Pt(100, 100); got != exp {
+		t.Errorf("got size %v; expected %v", got, exp)
+	}
+	r.Frame(gtx.Ops)
+	r.Queue(pointer.Event{
+		Type:     pointer.Press,
+		Position: f32.Pt(50, -5),
+	})
+	var pressed bool
+	for _, e := range r.Events(tag) {
+		if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+			pressed = true
+			if got, exp := e.Position, f32.Pt(50, 5); got != exp {
+				t.Errorf("got press position %v; expected %v", got, exp)
+			}
+		}
+	}
+	if !pressed {
+		t.Error("outset child did not receive press above the inset bounds")
+	}
+}
+
+func TestContextLocale(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(100, 100)),
+		LocaleTag:   "ar-EG",
+	}
+	exp := Locale{Language: "ar", Region: "EG"}
+	var got []Locale
+	w := func(gtx Context) Dimensions {
+		got = append(got, gtx.Locale())
+		return Dimensions{Size: gtx.Constraints.Min}
+	}
+	UniformInset(unit.Px(10)).Layout(gtx, w)
+	Flex{}.Layout(gtx, Rigid(w), Flexed(1, w))
+	Stack{}.Layout(gtx, Expanded(w), Stacked(w))
+	(&List{}).Layout(gtx, 1, func(gtx Context, i int) Dimensions { return w(gtx) })
+	w(gtx.Disabled())
+	if len(got) != 7 {
+		t.Fatalf("got %d layouts; expected 7", len(got))
+	}
+	for i, l := range got {
+		if l != exp {
+			t.Errorf("layout %d: got locale %+v; expected %+v", i, l, exp)
+		}
+	}
+	if !gtx.Locale().RTL() {
+		t.Error("expected right-to-left locale")
+	}
+}
+
+func TestContextUnits(t *testing.T) {
+	gtx := Context{Metric: unit.Metric{PxPerDp: 1.5, PxPerSp: 2.25}}
+	for _, c := range []struct {
+		v      float32
+		dp, sp int
+	}{
+		{0, 0, 0},
+		{1, 2, 2},
+		{2, 3, 5},
+		{3, 5, 7},
+		{10, 15, 23},
+		{-3, -5, -7},
+	} {
+		if got := gtx.Dp(c.v); got != c.dp {
+			t.Errorf("Dp(%v) = %d; expected %d", c.v, got, c.dp)
+		}
+		if got := gtx.Sp(c.v); got != c.sp {
+			t.Errorf("Sp(%v) = %d; expected %d", c.v, got, c.sp)
+		}
+	}
+	// The zero Metric maps one unit to one pixel.
+	if got := (Context{}).Dp(7); got != 7 {
+		t.Errorf("Dp(7) = %d with the zero Metric; expected 7", got)
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	for _, tc := range []struct {
+		tag string
+		exp Locale
+		rtl bool
+	}{
+		{"", Locale{}, false},
+		{"en", Locale{Language: "en"}, false},
+		{"en_us", Locale{Language: "en", Region: "US"}, false},
+		{"es-419", Locale{Language: "es", Region: "419"}, false},
+		{"zh-hant-TW", Locale{Language: "zh", Script: "Hant", Region: "TW"}, false},
+		{"he-IL-u-nu-hebr", Locale{Language: "he", Region: "IL"}, true},
+		{"az-Arab", Locale{Language: "az", Script: "Arab"}, true},
+		{"ur-Latn", Locale{Language: "ur", Script: "Latn"}, false},
+	} {
+		l := ParseLocale(tc.tag)
+		if l != tc.exp {
+			t.Errorf("%q: got %+v; expected %+v", tc.tag, l, tc.exp)
+		}
+		if l.RTL() != tc.rtl {
+			t.Errorf("%q: got RTL %v; expected %v", tc.tag, l.RTL(), tc.rtl)
+		}
+	}
+}
+
+func TestIfFits(t *testing.T) {
+	tag := new(int)
+	w := IfFits(
+		func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Pt(50, 40)}
+		},
+		func(gtx Context) Dimensions {
+			sz := image.Pt(80, 30)
+			defer clip.Rect{Max: sz}.Push(gtx.Ops).Pop()
+			pointer.InputOp{Tag: tag, Types: pointer.Press}.Add(gtx.Ops)
+			return Dimensions{Size: sz}
+		},
+	)
+	for _, tc := range []struct {
+		height int
+		exp    image.Point
+		shown  bool
+	}{
+		{height: 60, exp: image.Pt(50, 40)},
+		{height: 70, exp: image.Pt(80, 70), shown: true},
+		{height: 100, exp: image.Pt(80, 70), shown: true},
+	} {
+		var r router.Router
+		gtx := Context{
+			Ops:         new(op.Ops),
+			Constraints: Constraints{Max: image.Pt(100, tc.height)},
+		}
+		dims := w(gtx)
+		if dims.Size != tc.exp {
+			t.Errorf("height %d: got size %v, expected %v", tc.height, dims.Size, tc.exp)
+		}
+		r.Frame(gtx.Ops)
+		r.Queue(pointer.Event{Type: pointer.Press, Position: f32.Pt(60, 50)})
+		if shown := len(r.Events(tag)) > 1; shown != tc.shown {
+			t.Errorf("height %d: secondary shown %v, expected %v", tc.height, shown, tc.shown)
+		}
+	}
+}
+
+func TestConstraintsHelpers(t *testing.T) {
+	cs := Constraints{Min: image.Pt(50, 20), Max: image.Pt(100, 40)}
+	if got, exp := cs.SubMax(image.Pt(60, 100)), (Constraints{Min: image.Pt(40, 0), Max: image.Pt(40, 0)}); got != exp {
+		t.Errorf("SubMax: got %v, expected %v", got, exp)
+	}
+	if got, exp := cs.AddMin(image.Pt(60, -30)), (Constraints{Min: image.Pt(100, 0), Max: image.Pt(100, 40)}); got != exp {
+		t.Errorf("AddMin: got %v, expected %v", got, exp)
+	}
+	for _, tc := range []struct {
+		cs        Constraints
+		size, exp image.Point
+	}{
+		// Shrink to the maximum, keeping the 2:1 aspect.
+		{cs, image.Pt(200, 100), image.Pt(80, 40)},
+		// Grow to the minimum.
+		{Constraints{Min: image.Pt(50, 20), Max: image.Pt(100, 50)}, image.Pt(10, 10), image.Pt(50, 50)},
+		// No scale satisfies both; fit the maximum and constrain.
+		{Constraints{Min: image.Pt(50, 20), Max: image.Pt(100, 50)}, image.Pt(10, 100), image.Pt(50, 50)},
+		{cs, image.Pt(0, 10), image.Pt(50, 20)},
+	} {
+		if got := tc.cs.ConstrainAspect(tc.size); got != tc.exp {
+			t.Errorf("ConstrainAspect(%v) in %v: got %v, expected %v", tc.size, tc.cs, got, tc.exp)
+		}
+	}
+	for _, tc := range []struct {
+		cs    Constraints
+		valid bool
+	}{
+		{Constraints{}, true},
+		{cs, true},
+		{Constraints{Min: image.Pt(0, -1)}, false},
+		{Constraints{Min: image.Pt(10, 0), Max: image.Pt(5, 5)}, false},
+	} {
+		if err := tc.cs.Validate(); (err == nil) != tc.valid {
+			t.Errorf("Validate(%v): got %v, expected valid %v", tc.cs, err, tc.valid)
+		}
+	}
+}
+
+func TestContainerConstraints(t *testing.T) {
+	var (
+		cs  Constraints
+		gtx Context
+	)
+	// checked is a middleware that fails for invalid constraints.
+	checked := func(w Widget) Widget {
+		return func(gtx Context) Dimensions {
+			if err := gtx.Constraints.Validate(); err != nil {
+				t.Errorf("%v: child constraints: %v", cs, err)
+			}
+			return w(gtx)
+		}
+	}
+	children := map[string]Widget{
+		"zero": func(gtx Context) Dimensions { return Dimensions{} },
+		"min":  func(gtx Context) Dimensions { return Dimensions{Size: gtx.Constraints.Min} },
+		"max":  func(gtx Context) Dimensions { return Dimensions{Size: gtx.Constraints.Max} },
+		// huge ignores its constraints.
+		"huge": func(gtx Context) Dimensions { return Dimensions{Size: image.Pt(1e4, 1e4)} },
+	}
+	containers := map[string]func(w Widget) Dimensions{
+		"inset": func(w Widget) Dimensions {
+			return UniformInset(unit.Px(10)).Layout(gtx, checked(w))
+		},
+		"huge inset": func(w Widget) Dimensions {
+			return UniformInset(unit.Px(1e4)).Layout(gtx, checked(w))
+		},
+		"outset": func(w Widget) Dimensions {
+			return UniformInset(unit.Px(-10)).Layout(gtx, checked(w))
+		},
+		"mixed inset": func(w Widget) Dimensions {
+			return Inset{Top: unit.Px(-5), Bottom: unit.Px(20), Left: unit.Px(30), Right: unit.Px(-1e4)}.Layout(gtx, checked(w))
+		},
+		"flex": func(w Widget) Dimensions {
+			return Flex{}.Layout(gtx, Rigid(checked(w)), Rigid(checked(w)), Flexed(1, checked(w)))
+		},
+		"flex measure": func(w Widget) Dimensions {
+			return Flex{Axis: Vertical, Measure: true, Spacing: SpaceEvenly}.Layout(gtx,
+				Rigid(checked(w)), Flexible(1, 1, checked(w)), Flexed(1, checked(w)))
+		},
+		"stack": func(w Widget) Dimensions {
+			return Stack{}.Layout(gtx, Stacked(checked(w)), Expanded(checked(w)))
+		},
+	}
+	for _, c := range []Constraints{
+		{},
+		Exact(image.Pt(100, 50)),
+		{Min: image.Pt(80, 80), Max: image.Pt(100, 100)},
+		{Max: image.Pt(10, 10)},
+	} {
+		for cname, container := range containers {
+			for wname, w := range children {
+				cs = c
+				gtx = Context{Ops: new(op.Ops), Constraints: c}
+				sz := container(w).Size
+				if sz != c.Constrain(sz) {
+					t.Errorf("%s of %s child in %v: size %v outside the constraints", cname, wname, c, sz)
+				}
+			}
+		}
+	}
+}
+
+func TestSnapBaseline(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	// text has a baseline 13 pixels from its top, and a height that
+	// grows with h.
+	text := func(h int) Widget {
+		return func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Pt(20, h), Baseline: h - 13}
+		}
+	}
+	snap := SnapBaseline{Grid: unit.Px(4)}
+	// Sub-grid changes of the height don't move the baseline.
+	for h := 17; h <= 19; h++ {
+		dims := snap.Layout(gtx, text(h))
+		if top := dims.Size.Y - dims.Baseline; top != 16 {
+			t.Errorf("height %d: baseline at %d, expected 16", h, top)
+		}
+		if dims.Size.Y%4 != 0 || dims.Size.Y < h+3 {
+			t.Errorf("height %d: snapped height %d", h, dims.Size.Y)
+		}
+	}
+	// Widgets without a baseline are only rounded.
+	if dims := snap.Layout(gtx, func(gtx Context) Dimensions {
+		return Dimensions{Size: image.Pt(10, 9)}
+	}); dims != (Dimensions{Size: image.Pt(10, 12)}) {
+		t.Errorf("got %+v for a widget without baseline", dims)
+	}
+	// Horizontal baseline alignment snaps the shared baseline.
+	dims := Flex{Alignment: Baseline, BaselineGrid: unit.Px(4)}.Layout(gtx,
+		Rigid(text(17)),
+		Rigid(func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Pt(20, 10), Baseline: 3}
+		}),
+	)
+	if top := dims.Size.Y - dims.Baseline; top != 16 || dims.Size.Y != 20 {
+		t.Errorf("flex: got baseline at %d and height %d, expected 16 and 20", top, dims.Size.Y)
+	}
+}
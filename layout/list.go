@@ -3,7 +3,10 @@
 package layout
 
 import (
+	"encoding/binary"
+	"errors"
 	"image"
+	"time"
 
 	"gioui.org/gesture"
 	"gioui.org/op"
@@ -17,7 +20,8 @@ type scrollChild struct {
 
 // List displays a subsection of a potentially infinitely
 // large underlying list. List accepts user input to scroll
-// the subsection.
+// the subsection. Horizontal lists also scroll by the mouse
+// wheel, with or without Shift held.
 type List struct {
 	Axis Axis
 	// ScrollToEnd instructs the list to stay scrolled to the far end position
@@ -25,8 +29,49 @@ type List struct {
 	// false draws its content with the last item at the bottom of the list
 	// area.
 	ScrollToEnd bool
+	// AnchorEnd anchors the list to its end, for content that grows at
+	// the end such as a chat. An AnchorEnd list behaves like a
+	// ScrollToEnd list, and while scrolled away from the end, the
+	// elements inserted at the start and reported by Prepend, such as
+	// older messages of a chat, don't move the visible elements.
+	// Appended elements never move them. Key anchoring by ElementKey
+	// and ElementIndex takes precedence. Positions set by the caller
+	// are never overridden.
+	AnchorEnd bool
 	// Alignment is the cross axis alignment of list elements.
 	Alignment Alignment
+	// Overscan is the number of elements beyond each end of the visible
+	// elements that are laid out but not drawn. Overscan keeps the state
+	// of elements just outside the viewport up to date, for smoother
+	// re-entry when scrolling back and forth. The elements outside the
+	// viewport take part in keyboard focus traversal, and the list
+	// scrolls to reveal a focused element outside the viewport, so a
+	// non-zero Overscan lets Tab traverse all the elements.
+	Overscan int
+	// ElementKey, if set together with ElementIndex, anchors the
+	// position to the first visible element across changes of the
+	// underlying data, such as a reload that reorders the elements.
+	// ElementKey returns a stable, comparable key of the element at
+	// index, and ElementIndex returns the index of the element with the
+	// key, or false if it is gone. When the key of the element at
+	// Position.First changes, Layout seeks to the new index of the
+	// anchored element, keeping Position.Offset. Positions set by the
+	// caller are never overridden.
+	ElementKey   func(index int) interface{}
+	ElementIndex func(key interface{}) (index int, ok bool)
+	// Animate, if non-zero and ElementKey is set, is the duration of
+	// the animations of inserted and removed elements. Inserted
+	// elements grow from zero size, and the space of removed elements
+	// collapses, moving the surviving elements smoothly. Changes are
+	// detected every frame by comparing the keys of the laid out
+	// elements, located by ElementIndex if set, or else by searching
+	// the nearby elements. Elements before the first visible element
+	// are not animated, and ElementIndex keeps the position anchored.
+	Animate time.Duration
+	// RemovedElement, if set, lays out the removed element with key in
+	// its collapsing space during the removal animation. Otherwise,
+	// the space is empty.
+	RemovedElement func(gtx Context, key interface{}) Dimensions
 
 	cs          Constraints
 	scroll      gesture.Scroll
@@ -40,10 +85,30 @@ type List struct {
 
 	len int
 
+	// anchorKey is the key of the first visible element in the most
+	// recent layout, and anchorFirst its index. anchored is set if
+	// they are valid.
+	anchorKey   interface{}
+	anchorFirst int
+	anchored    bool
+
+	anim listAnimation
+
+	// prepended is the number of elements reported by Prepend since the
+	// most recent layout, and first and offset its position.
+	prepended struct {
+		n             int
+		valid         bool
+		first, offset int
+	}
+
 	// maxSize is the total size of visible children.
 	maxSize  int
 	children []scrollChild
-	dir      iterationDir
+	// before and after are the overscan children before and after
+	// children, nearest first.
+	before, after []scrollChild
+	dir           iterationDir
 }
 
 // ListElement is a function that computes the dimensions of
@@ -85,6 +150,54 @@ const (
 
 const inf = 1e6
 
+// positionVersion is the version of the Position encoding.
+const positionVersion = 1
+
+var errPositionEncoding = errors.New("layout: invalid Position encoding")
+
+// MarshalBinary encodes BeforeEnd, First and Offset, for restoring the
+// position with UnmarshalBinary. The other fields are computed by the
+// next layout of the list. The first byte of the encoding is a version.
+func (p Position) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 2+2*binary.MaxVarintLen64)
+	buf[0] = positionVersion
+	if p.BeforeEnd {
+		buf[1] = 1
+	}
+	n := 2
+	n += binary.PutVarint(buf[n:], int64(p.First))
+	n += binary.PutVarint(buf[n:], int64(p.Offset))
+	return buf[:n], nil
+}
+
+// UnmarshalBinary restores a position encoded by MarshalBinary, and
+// leaves p unchanged if data is invalid. A position beyond the end of
+// the list is clamped by the next layout.
+func (p *Position) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 || data[0] != positionVersion {
+		return errPositionEncoding
+	}
+	first, n1 := binary.Varint(data[2:])
+	if n1 <= 0 {
+		return errPositionEncoding
+	}
+	off, n2 := binary.Varint(data[2+n1:])
+	if n2 <= 0 || 2+n1+n2 != len(data) {
+		return errPositionEncoding
+	}
+	if first < 0 {
+		first = 0
+	}
+	if first > inf {
+		first = inf
+	}
+	if off < -inf || off > inf {
+		off = 0
+	}
+	*p = Position{BeforeEnd: data[1] != 0, First: int(first), Offset: int(off)}
+	return nil
+}
+
 // init prepares the list for iterating through its children with next.
 func (l *List) init(gtx Context, len int) {
 	if l.more() {
@@ -93,7 +206,14 @@ func (l *List) init(gtx Context, len int) {
 	l.cs = gtx.Constraints
 	l.maxSize = 0
 	l.children = l.children[:0]
+	l.before = l.before[:0]
+	l.after = l.after[:0]
 	l.len = len
+	l.seekAnchor()
+	l.seekEnd()
+	if l.animates() && !gtx.IsMeasuring() {
+		l.detectChanges(gtx)
+	}
 	l.update(gtx)
 	if l.scrollToEnd() || l.Position.First > len {
 		l.Position.Offset = 0
@@ -114,21 +234,105 @@ func (l *List) Layout(gtx Context, len int, w ListElement) Dimensions {
 		child := op.Record(gtx.Ops)
 		dims := w(gtx, l.index())
 		call := child.Stop()
+		if l.animates() {
+			dims, call = l.animate(gtx, l.index(), dims, call)
+		}
 		l.end(dims, call)
 		laidOutTotalLength += l.Axis.Convert(dims.Size).X
 		numLaidOut++
 	}
+	// Lay out the overscan elements.
+	first, last := l.Position.First, l.Position.First+numLaidOut
+	for i := 1; i <= l.Overscan; i++ {
+		for j, idx := range [2]int{first - i, last + i - 1} {
+			if idx < 0 || idx >= len {
+				continue
+			}
+			child := op.Record(gtx.Ops)
+			dims := w(gtx, idx)
+			c := scrollChild{dims.Size, child.Stop()}
+			if j == 0 {
+				l.before = append(l.before, c)
+			} else {
+				l.after = append(l.after, c)
+			}
+			laidOutTotalLength += l.Axis.Convert(dims.Size).X
+			numLaidOut++
+		}
+	}
 
 	if numLaidOut > 0 {
 		l.Position.Length = laidOutTotalLength * len / numLaidOut
 	} else {
 		l.Position.Length = 0
 	}
-	return l.layout(gtx.Ops, macro)
+	dims := l.layout(gtx.Ops, macro)
+	l.setAnchor()
+	l.setEnd()
+	if l.animates() && !gtx.IsMeasuring() {
+		l.endLayout()
+	}
+	return dims
+}
+
+// Prepend reports that n elements were inserted at the start of the
+// list since the most recent layout. The next layout of an AnchorEnd
+// list scrolled away from its end moves the position past the inserted
+// elements.
+func (l *List) Prepend(n int) {
+	l.prepended.n += n
+}
+
+// seekEnd moves the position of an AnchorEnd list past the prepended
+// elements.
+func (l *List) seekEnd() {
+	p := l.prepended
+	l.prepended.n = 0
+	if !l.AnchorEnd || p.n <= 0 || !p.valid || l.scrollToEnd() || l.ElementIndex != nil {
+		return
+	}
+	if l.Position.First != p.first || l.Position.Offset != p.offset {
+		return
+	}
+	l.Position.First += p.n
+	if l.Position.First > l.len {
+		l.Position.First = l.len
+	}
+}
+
+// setEnd records the position for seekEnd.
+func (l *List) setEnd() {
+	l.prepended.valid = true
+	l.prepended.first, l.prepended.offset = l.Position.First, l.Position.Offset
+}
+
+// seekAnchor moves the position to the anchored element, if the element
+// at Position.First changed since the previous layout.
+func (l *List) seekAnchor() {
+	if !l.anchored || l.ElementIndex == nil || l.Position.First != l.anchorFirst || l.scrollToEnd() {
+		return
+	}
+	if l.Position.First < l.len && l.ElementKey(l.Position.First) == l.anchorKey {
+		return
+	}
+	if i, ok := l.ElementIndex(l.anchorKey); ok && i >= 0 && i < l.len {
+		l.Position.First = i
+	}
+}
+
+// setAnchor records the key of the first visible element.
+func (l *List) setAnchor() {
+	l.anchored = l.ElementKey != nil && l.Position.First < l.len
+	if l.anchored {
+		l.anchorKey = l.ElementKey(l.Position.First)
+		l.anchorFirst = l.Position.First
+	} else {
+		l.anchorKey = nil
+	}
 }
 
 func (l *List) scrollToEnd() bool {
-	return l.ScrollToEnd && !l.Position.BeforeEnd
+	return (l.ScrollToEnd || l.AnchorEnd) && !l.Position.BeforeEnd
 }
 
 // Dragging reports whether the List is being dragged.
@@ -137,6 +341,7 @@ func (l *List) Dragging() bool {
 }
 
 func (l *List) update(gtx Context) {
+	l.scroll.Config = gtx.Gesture
 	d := l.scroll.Scroll(gtx.Metric, gtx, gtx.Now, gesture.Axis(l.Axis))
 	l.scrollDelta = d
 	l.Position.Offset += d
@@ -212,6 +417,41 @@ func (l *List) end(dims Dimensions, call op.CallOp) {
 	l.dir = iterateNone
 }
 
+// addChild adds the operations of child at the main axis position pos,
+// and returns the position after the child. The child is clipped to
+// the viewport, unless it is entirely outside.
+func (l *List) addChild(ops *op.Ops, child scrollChild, pos, maxCross, mainMax int) int {
+	sz := l.Axis.Convert(child.size)
+	var cross int
+	switch l.Alignment {
+	case End:
+		cross = maxCross - sz.Y
+	case Middle:
+		cross = (maxCross - sz.Y) / 2
+	}
+	childSize := sz.X
+	max := childSize + pos
+	if max > mainMax {
+		max = mainMax
+	}
+	min := pos
+	if min < 0 {
+		min = 0
+	}
+	if min >= max {
+		min, max = pos, pos+childSize
+	}
+	r := image.Rectangle{
+		Min: l.Axis.Convert(image.Pt(min, -inf)),
+		Max: l.Axis.Convert(image.Pt(max, inf)),
+	}
+	defer clip.Rect(r).Push(ops).Pop()
+	pt := l.Axis.Convert(image.Pt(pos, cross))
+	defer op.Offset(FPt(pt)).Push(ops).Pop()
+	child.call.Add(ops)
+	return pos + childSize
+}
+
 // Layout the List and return its dimensions.
 func (l *List) layout(ops *op.Ops, macro op.MacroOp) Dimensions {
 	if l.more() {
@@ -219,6 +459,7 @@ func (l *List) layout(ops *op.Ops, macro op.MacroOp) Dimensions {
 	}
 	mainMin, mainMax := l.Axis.mainConstraint(l.cs)
 	children := l.children
+	skipped := 0
 	// Skip invisible children
 	for len(children) > 0 {
 		sz := children[0].size
@@ -230,6 +471,7 @@ func (l *List) layout(ops *op.Ops, macro op.MacroOp) Dimensions {
 		l.Position.First++
 		l.Position.Offset -= mainSize
 		children = children[1:]
+		skipped++
 	}
 	size := -l.Position.Offset
 	var maxCross int
@@ -248,38 +490,37 @@ func (l *List) layout(ops *op.Ops, macro op.MacroOp) Dimensions {
 	l.Position.OffsetLast = mainMax - size
 	pos := -l.Position.Offset
 	// ScrollToEnd lists are end aligned.
-	if space := l.Position.OffsetLast; l.ScrollToEnd && space > 0 {
+	if space := l.Position.OffsetLast; (l.ScrollToEnd || l.AnchorEnd) && space > 0 {
 		pos += space
 	}
-	for _, child := range children {
-		sz := l.Axis.Convert(child.size)
-		var cross int
-		switch l.Alignment {
-		case End:
-			cross = maxCross - sz.Y
-		case Middle:
-			cross = (maxCross - sz.Y) / 2
+	if l.Overscan > 0 {
+		// The children outside the viewport are added for keyboard
+		// focus traversal, in index order, and clipped by the list.
+		start := pos
+		for _, c := range l.before {
+			start -= l.Axis.Convert(c.size).X
+		}
+		for _, c := range l.children[:skipped] {
+			start -= l.Axis.Convert(c.size).X
+		}
+		for i := len(l.before) - 1; i >= 0; i-- {
+			start = l.addChild(ops, l.before[i], start, maxCross, mainMax)
 		}
-		childSize := sz.X
-		max := childSize + pos
-		if max > mainMax {
-			max = mainMax
+		for _, c := range l.children[:skipped] {
+			start = l.addChild(ops, c, start, maxCross, mainMax)
 		}
-		min := pos
-		if min < 0 {
-			min = 0
+	}
+	for _, child := range children {
+		pos = l.addChild(ops, child, pos, maxCross, mainMax)
+	}
+	if l.Overscan > 0 {
+		end := pos
+		for _, c := range l.children[skipped+len(children):] {
+			end = l.addChild(ops, c, end, maxCross, mainMax)
 		}
-		r := image.Rectangle{
-			Min: l.Axis.Convert(image.Pt(min, -inf)),
-			Max: l.Axis.Convert(image.Pt(max, inf)),
+		for _, c := range l.after {
+			end = l.addChild(ops, c, end, maxCross, mainMax)
 		}
-		cl := clip.Rect(r).Push(ops)
-		pt := l.Axis.Convert(image.Pt(pos, cross))
-		trans := op.Offset(FPt(pt)).Push(ops)
-		child.call.Add(ops)
-		trans.Pop()
-		cl.Pop()
-		pos += childSize
 	}
 	atStart := l.Position.First == 0 && l.Position.Offset <= 0
 	atEnd := l.Position.First+len(children) == l.len && mainMax >= pos
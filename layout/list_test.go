@@ -4,13 +4,18 @@ package layout
 
 import (
 	"image"
+	"reflect"
 	"testing"
+	"time"
 
 	"gioui.org/f32"
 	"gioui.org/io/event"
+	"gioui.org/io/key"
 	"gioui.org/io/pointer"
 	"gioui.org/io/router"
+	"gioui.org/io/system"
 	"gioui.org/op"
+	"gioui.org/op/clip"
 )
 
 func TestEmptyList(t *testing.T) {
@@ -25,6 +30,32 @@ func TestEmptyList(t *testing.T) {
 	}
 }
 
+func TestListOverscan(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(20, 10)),
+	}
+	l := List{
+		Axis:     Horizontal,
+		Overscan: 2,
+		Position: Position{First: 5},
+	}
+	laidOut := make(map[int]bool)
+	l.Layout(gtx, 20, func(gtx Context, idx int) Dimensions {
+		laidOut[idx] = true
+		return Dimensions{Size: image.Pt(10, 10)}
+	})
+	if l.Position.First != 5 || l.Position.Count != 2 {
+		t.Fatalf("got %d visible elements from %d, expected 2 from 5", l.Position.Count, l.Position.First)
+	}
+	for idx := 0; idx < 20; idx++ {
+		want := idx >= 3 && idx < 9
+		if laidOut[idx] != want {
+			t.Errorf("element %d laid out: %v, expected %v", idx, laidOut[idx], want)
+		}
+	}
+}
+
 func TestListPosition(t *testing.T) {
 	_s := func(e ...event.Event) []event.Event { return e }
 	r := new(router.Router)
@@ -140,3 +171,430 @@ func TestListPosition(t *testing.T) {
 		})
 	}
 }
+
+func TestListShiftWheel(t *testing.T) {
+	var (
+		r   router.Router
+		ops op.Ops
+	)
+	gtx := Context{
+		Ops:         &ops,
+		Constraints: Exact(image.Pt(20, 10)),
+		Queue:       &r,
+	}
+	// A horizontal list inside a vertical list.
+	var outer, inner List
+	outer.Axis = Vertical
+	inner.Axis = Horizontal
+	frame := func() {
+		ops.Reset()
+		outer.Layout(gtx, 2, func(gtx Context, idx int) Dimensions {
+			if idx > 0 {
+				return Dimensions{Size: image.Pt(20, 10)}
+			}
+			return inner.Layout(gtx, 10, func(gtx Context, idx int) Dimensions {
+				return Dimensions{Size: image.Pt(10, 10)}
+			})
+		})
+		r.Frame(&ops)
+	}
+	frame()
+	r.Queue(pointer.Event{
+		Source:    pointer.Mouse,
+		Type:      pointer.Scroll,
+		Position:  f32.Pt(5, 5),
+		Scroll:    f32.Pt(0, 5),
+		Modifiers: key.ModShift,
+	})
+	frame()
+	if got := inner.Position; got.First != 0 || got.Offset != 5 {
+		t.Errorf("got horizontal position %+v, expected offset 5", got)
+	}
+	if outer.Position.Offset != 0 || outer.Position.First != 0 {
+		t.Errorf("Shift+wheel scrolled the vertical list to %+v", outer.Position)
+	}
+}
+
+func TestPositionMarshal(t *testing.T) {
+	pos := Position{BeforeEnd: true, First: 15, Offset: -3, Count: 2, Length: 100}
+	data, err := pos.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Position
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if exp := (Position{BeforeEnd: true, First: 15, Offset: -3}); got != exp {
+		t.Errorf("got %+v, expected %+v", got, exp)
+	}
+	corrupt := [][]byte{
+		nil,
+		{positionVersion},
+		append([]byte{positionVersion + 1}, data[1:]...),
+		data[:len(data)-1],
+		append(data, 0),
+	}
+	for _, c := range corrupt {
+		p := got
+		if err := p.UnmarshalBinary(c); err == nil {
+			t.Errorf("%v: no error", c)
+		}
+		if p != got {
+			t.Errorf("%v: position changed to %+v", c, p)
+		}
+	}
+	// Restore against fewer elements.
+	l := List{Position: got}
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(20, 10)),
+	}
+	l.Layout(gtx, 10, func(gtx Context, idx int) Dimensions {
+		return Dimensions{Size: image.Pt(10, 10)}
+	})
+	if p := l.Position; p.First < 0 || p.First+p.Count > 10 {
+		t.Errorf("restored position %+v outside 10 elements", p)
+	}
+}
+
+func TestListElementKey(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(10, 30)),
+	}
+	data := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	index := func(key interface{}) (int, bool) {
+		for i, k := range data {
+			if k == key {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	l := List{
+		Axis:         Vertical,
+		Position:     Position{First: 3, Offset: 4},
+		ElementKey:   func(i int) interface{} { return data[i] },
+		ElementIndex: index,
+	}
+	var top string
+	layout := func() {
+		top = ""
+		l.Layout(gtx, len(data), func(gtx Context, i int) Dimensions {
+			if top == "" {
+				top = data[i]
+			}
+			return Dimensions{Size: image.Pt(10, 10)}
+		})
+	}
+	layout()
+	if top != "d" || l.Position.First != 3 {
+		t.Fatalf("got %q at %v at the top; expected \"d\" at 3", top, l.Position)
+	}
+	// Reverse the data.
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+	layout()
+	if top != "d" || l.Position.First != 4 || l.Position.Offset != 4 {
+		t.Errorf("got %q at %+v at the top after reordering; expected \"d\" at 4 with offset 4", top, l.Position)
+	}
+	// Removing the anchored element keeps the index.
+	data = append(data[:4], data[5:]...)
+	layout()
+	if l.Position.First != 4 {
+		t.Errorf("got first %d after removing the anchor; expected 4", l.Position.First)
+	}
+	// Positions set by the caller are kept.
+	l.Position.First = 1
+	data[0], data[1] = data[1], data[0]
+	layout()
+	if l.Position.First != 1 {
+		t.Errorf("got first %d after scrolling; expected 1", l.Position.First)
+	}
+}
+
+func TestListAnimation(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(10, 50)),
+		Now:         time.Unix(1, 0),
+	}
+	data := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	l := List{
+		Axis:       Vertical,
+		Animate:    100 * time.Millisecond,
+		ElementKey: func(i int) interface{} { return data[i] },
+		ElementIndex: func(key interface{}) (int, bool) {
+			for i, k := range data {
+				if k == key {
+					return i, true
+				}
+			}
+			return 0, false
+		},
+	}
+	// sizes returns the main axis sizes of the visible elements after a
+	// layout at time now.
+	sizes := func(now time.Duration) []int {
+		gtx.Ops.Reset()
+		gtx.Now = time.Unix(1, 0).Add(now)
+		l.Layout(gtx, len(data), func(gtx Context, i int) Dimensions {
+			return Dimensions{Size: image.Pt(10, 10)}
+		})
+		var s []int
+		for _, c := range l.children {
+			s = append(s, c.size.Y)
+		}
+		return s
+	}
+	check := func(name string, got []int, exp ...int) {
+		t.Helper()
+		if len(got) < len(exp) {
+			t.Errorf("%s: got sizes %v; expected %v", name, got, exp)
+			return
+		}
+		for i, e := range exp {
+			if got[i] != e {
+				t.Errorf("%s: got sizes %v; expected %v", name, got, exp)
+				return
+			}
+		}
+	}
+	sizes(0)
+
+	// An inserted element grows from zero size.
+	data = append(data[:2], append([]int{10}, data[2:]...)...)
+	check("inserted", sizes(time.Second), 10, 10, 0, 10, 10)
+	check("inserted halfway", sizes(time.Second+50*time.Millisecond), 10, 10, 5, 10, 10)
+	check("inserted end", sizes(time.Second+100*time.Millisecond), 10, 10, 10, 10, 10)
+
+	// The space of a removed element collapses into its successor.
+	data = append(data[:1], data[2:]...)
+	check("removed", sizes(2*time.Second), 10, 20, 10, 10)
+	check("removed halfway", sizes(2*time.Second+50*time.Millisecond), 10, 15, 10, 10)
+	check("removed end", sizes(2*time.Second+100*time.Millisecond), 10, 10, 10, 10)
+
+	// Replacing an element keeps the length, and animates both the
+	// removal and the insertion.
+	data[1] = 12
+	check("replaced", sizes(2*time.Second+200*time.Millisecond), 10, 0, 20, 10, 10)
+	check("replaced end", sizes(2*time.Second+300*time.Millisecond), 10, 10, 10, 10, 10)
+
+	// Elements inserted above the viewport are not animated, and the
+	// position stays anchored.
+	l.Position = Position{First: 3}
+	sizes(3 * time.Second)
+	data = append([]int{11}, data...)
+	check("inserted above", sizes(3*time.Second), 10, 10, 10, 10, 10)
+	if l.Position.First != 4 || l.Position.Offset != 0 {
+		t.Errorf("got position %+v after inserting above; expected first 4 and offset 0", l.Position)
+	}
+	// Neither are removed elements above the viewport.
+	data = data[1:]
+	check("removed above", sizes(4*time.Second), 10, 10, 10, 10, 10)
+	if l.Position.First != 3 {
+		t.Errorf("got position %+v after removing above; expected first 3", l.Position)
+	}
+}
+
+func TestListFocusTraversal(t *testing.T) {
+	r := new(router.Router)
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(10, 30)),
+		Queue:       r,
+	}
+	l := List{Axis: Vertical, Overscan: 1}
+	tags := make([]int, 10)
+	focused := -1
+	layout := func() {
+		gtx.Ops.Reset()
+		l.Layout(gtx, len(tags), func(gtx Context, i int) Dimensions {
+			for _, e := range gtx.Events(&tags[i]) {
+				if e, ok := e.(key.FocusEvent); ok && e.Focus {
+					focused = i
+				}
+			}
+			defer clip.Rect{Max: image.Pt(10, 10)}.Push(gtx.Ops).Pop()
+			key.InputOp{Tag: &tags[i]}.Add(gtx.Ops)
+			return Dimensions{Size: image.Pt(10, 10)}
+		})
+		r.Frame(gtx.Ops)
+	}
+	tab := func() {
+		r.Queue(
+			key.Event{Name: key.NameTab, State: key.Press},
+			key.Event{Name: key.NameTab, State: key.Release},
+		)
+		layout()
+	}
+	layout()
+	// Tab through the visible elements.
+	for i := 0; i < 3; i++ {
+		tab()
+		if focused != i || l.Position.First != 0 {
+			t.Fatalf("got focus %d at first %d; expected focus %d at first 0", focused, l.Position.First, i)
+		}
+	}
+	// Tab past the visible elements scrolls to reveal the focus.
+	for i := 3; i < 6; i++ {
+		tab()
+		if focused != i {
+			t.Fatalf("got focus %d; expected %d", focused, i)
+		}
+		if exp := i - 2; l.Position.First != exp || l.Position.Offset != 0 {
+			t.Errorf("got position %+v after focusing %d; expected first %d", l.Position, i, exp)
+		}
+	}
+	// Shift-Tab before the visible elements scrolls back.
+	l.Position = Position{First: 5}
+	layout()
+	r.Queue(key.Event{Name: key.NameTab, Modifiers: key.ModShift, State: key.Press})
+	layout()
+	if focused != 4 || l.Position.First != 4 || l.Position.Offset != 0 {
+		t.Errorf("got focus %d at %+v after Shift-Tab; expected focus 4 at first 4", focused, l.Position)
+	}
+}
+
+func TestListFlingClock(t *testing.T) {
+	// fling the list with a touch drag and return the positions of the
+	// frames of the fling, stepped by a system.ManualClock.
+	fling := func() []int {
+		clk := system.NewManualClock(time.Unix(1, 0))
+		r := new(router.Router)
+		r.SetClock(clk)
+		gtx := Context{
+			Ops:         new(op.Ops),
+			Constraints: Exact(image.Pt(20, 100)),
+			Queue:       r,
+		}
+		l := List{Axis: Vertical}
+		pos := func() int {
+			return l.Position.First*10 + l.Position.Offset
+		}
+		frame := func() {
+			gtx.Ops.Reset()
+			gtx.Now = r.Now()
+			l.Layout(gtx, 1000, func(gtx Context, i int) Dimensions {
+				return Dimensions{Size: image.Pt(20, 10)}
+			})
+			r.Frame(gtx.Ops)
+		}
+		frame()
+		for i := 0; i <= 6; i++ {
+			typ := pointer.Move
+			if i == 0 {
+				typ = pointer.Press
+			}
+			e := pointer.Event{
+				Type:     typ,
+				Source:   pointer.Touch,
+				Position: f32.Pt(10, 90-float32(i)*12),
+				Time:     time.Duration(i) * 8 * time.Millisecond,
+			}
+			r.Queue(e)
+			if i == 6 {
+				e.Type = pointer.Release
+				r.Queue(e)
+			}
+			clk.Advance(8 * time.Millisecond)
+			frame()
+		}
+		var positions []int
+		for n := 0; ; n++ {
+			if n == 1000 {
+				t.Fatal("fling didn't stop")
+			}
+			wakeup, ok := r.WakeupTime()
+			if !ok {
+				break
+			}
+			if wakeup.After(clk.Now()) {
+				t.Fatalf("got wakeup at %v; expected an immediate redraw", wakeup)
+			}
+			// The position doesn't change without the clock.
+			p := pos()
+			frame()
+			if n > 0 && pos() != p {
+				t.Fatalf("position moved from %d to %d without the clock advancing", p, pos())
+			}
+			clk.Advance(16 * time.Millisecond)
+			frame()
+			positions = append(positions, pos())
+		}
+		return positions
+	}
+	run1, run2 := fling(), fling()
+	if len(run1) < 2 || run1[len(run1)-1] <= run1[0] {
+		t.Fatalf("got fling positions %v; expected a forward fling", run1)
+	}
+	if !reflect.DeepEqual(run1, run2) {
+		t.Errorf("fling positions differ between runs:\n%v\n%v", run1, run2)
+	}
+}
+
+func TestListAnchorEnd(t *testing.T) {
+	r := new(router.Router)
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(10, 30)),
+		Queue:       r,
+	}
+	l := List{Axis: Vertical, AnchorEnd: true}
+	n := 2
+	layout := func() {
+		gtx.Ops.Reset()
+		l.Layout(gtx, n, func(gtx Context, i int) Dimensions {
+			return Dimensions{Size: image.Pt(10, 10)}
+		})
+		r.Frame(gtx.Ops)
+	}
+	scroll := func(dy float32) {
+		r.Queue(
+			pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(5, 15)},
+			pointer.Event{Type: pointer.Scroll, Source: pointer.Mouse, Position: f32.Pt(5, 15), Scroll: f32.Pt(0, dy)},
+		)
+		layout()
+	}
+	layout()
+	if l.Position.First != 0 || l.Position.Count != 2 || l.Position.BeforeEnd {
+		t.Fatalf("got %+v; expected 2 visible elements at the end", l.Position)
+	}
+	// Appending elements at the end keeps the newest visible.
+	for n < 10 {
+		n++
+		layout()
+		if last := l.Position.First + l.Position.Count; last != n || l.Position.BeforeEnd {
+			t.Fatalf("got %+v with %d elements; expected the last element visible", l.Position, n)
+		}
+	}
+	// Scroll away from the end.
+	scroll(-15)
+	if l.Position.First != 5 || l.Position.Offset != 5 || !l.Position.BeforeEnd {
+		t.Fatalf("got %+v after scrolling; expected first 5 with offset 5", l.Position)
+	}
+	// Appending elements keeps the visible elements.
+	n += 2
+	layout()
+	if l.Position.First != 5 || l.Position.Offset != 5 || !l.Position.BeforeEnd {
+		t.Errorf("got %+v after appending; expected first 5 with offset 5", l.Position)
+	}
+	// Inserting elements at the start keeps the visible elements.
+	n += 3
+	l.Prepend(3)
+	layout()
+	if l.Position.First != 8 || l.Position.Offset != 5 || l.Position.OffsetLast != -5 {
+		t.Errorf("got %+v after inserting at the start; expected first 8 with offset 5", l.Position)
+	}
+	// Scrolling back to the end keeps the list at the end.
+	for i := 0; i < 10 && l.Position.BeforeEnd; i++ {
+		scroll(10)
+	}
+	n++
+	layout()
+	if last := l.Position.First + l.Position.Count; last != n || l.Position.BeforeEnd || l.Position.OffsetLast != 0 {
+		t.Errorf("got %+v after appending at the end; expected the last element at the end", l.Position)
+	}
+}
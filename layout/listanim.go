@@ -0,0 +1,256 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+	"time"
+
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+// listAnimation tracks the insertions and removals of the elements of
+// a List with Animate set.
+type listAnimation struct {
+	// window are the elements of the most recent layout, in index
+	// order, and first its first visible index. next collects the
+	// elements of the current layout.
+	window, next []listElement
+	first        int
+	// found are the current indices of the elements of window, or -1
+	// for removed elements. prev is the set of the keys of window.
+	found []int
+	prev  map[interface{}]bool
+	// entering are the start times of the insertion animations, by key.
+	entering map[interface{}]time.Time
+	gaps     []listGap
+}
+
+// listElement is a laid out element, with its main axis size.
+type listElement struct {
+	key   interface{}
+	index int
+	size  int
+}
+
+// listGap is the space of a removed element, collapsing next to a
+// surviving neighbour.
+type listGap struct {
+	// at is the key of the neighbour, and after is set if the gap
+	// follows the neighbour.
+	at    interface{}
+	after bool
+	// key is the key of the removed element, and size its main axis
+	// size.
+	key   interface{}
+	size  int
+	start time.Time
+}
+
+// animates reports whether l animates its elements.
+func (l *List) animates() bool {
+	return l.Animate > 0 && l.ElementKey != nil
+}
+
+// detectChanges starts the animations of the elements inserted into or
+// removed from the elements of the most recent layout, and ends the
+// finished animations. Elements are inserted if they appear between
+// elements of the most recent layout. Elements before the first visible
+// element are not animated, keeping the position anchored.
+func (l *List) detectChanges(gtx Context) {
+	a := &l.anim
+	if a.entering == nil {
+		a.entering = make(map[interface{}]time.Time)
+		a.prev = make(map[interface{}]bool)
+	}
+	for k, start := range a.entering {
+		if l.progress(gtx, start) >= 1 {
+			delete(a.entering, k)
+		}
+	}
+	gaps := a.gaps[:0]
+	for _, g := range a.gaps {
+		if l.progress(gtx, g.start) < 1 {
+			gaps = append(gaps, g)
+		}
+	}
+	a.gaps = gaps
+	if len(a.window) == 0 {
+		return
+	}
+	// Locate the elements of the most recent layout.
+	a.found = a.found[:0]
+	lo, hi := -1, -1
+	for _, e := range a.window {
+		i := l.indexOf(e.key, e.index, 2*len(a.window))
+		a.found = append(a.found, i)
+		if i == -1 {
+			continue
+		}
+		if lo == -1 || i < lo {
+			lo = i
+		}
+		if i > hi {
+			hi = i
+		}
+	}
+	// New elements between the located elements are insertions.
+	if lo != -1 {
+		for k := range a.prev {
+			delete(a.prev, k)
+		}
+		for _, e := range a.window {
+			a.prev[e.key] = true
+		}
+		start := lo + 1
+		if start < l.Position.First {
+			start = l.Position.First
+		}
+		for i := start; i < hi; i++ {
+			if k := l.ElementKey(i); !a.prev[k] {
+				a.entering[k] = gtx.Now
+			}
+		}
+	}
+	for i, e := range a.window {
+		if a.found[i] != -1 || e.index < a.first {
+			continue
+		}
+		g := listGap{key: e.key, size: e.size, start: gtx.Now}
+		if n, ok := a.neighbour(i, 1); ok {
+			g.at = n
+		} else if n, ok := a.neighbour(i, -1); ok {
+			g.at, g.after = n, true
+		} else {
+			continue
+		}
+		a.gaps = append(a.gaps, g)
+	}
+}
+
+// indexOf returns the index of the element with key, which had the
+// index near in the most recent layout. Without ElementIndex, the
+// element is searched for within span elements of near. indexOf returns
+// -1 for removed elements.
+func (l *List) indexOf(key interface{}, near, span int) int {
+	if l.ElementIndex != nil {
+		if i, ok := l.ElementIndex(key); ok && i >= 0 && i < l.len {
+			return i
+		}
+		return -1
+	}
+	for d := 0; d <= span; d++ {
+		for _, i := range [2]int{near + d, near - d} {
+			if i >= 0 && i < l.len && l.ElementKey(i) == key {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// neighbour returns the key of the first surviving element of the most
+// recent layout after or before the element at i, in the direction dir.
+func (a *listAnimation) neighbour(i, dir int) (interface{}, bool) {
+	for i += dir; i >= 0 && i < len(a.window); i += dir {
+		if a.found[i] != -1 {
+			return a.window[i].key, true
+		}
+	}
+	return nil, false
+}
+
+// endLayout records the elements of the completed layout for detecting
+// the changes of the next.
+func (l *List) endLayout() {
+	a := &l.anim
+	a.window, a.next = a.next, a.window[:0]
+	a.first = l.Position.First
+	// Elements are laid out in index order, except for the elements
+	// laid out backwards from the first element.
+	w := a.window
+	for i := 1; i < len(w); i++ {
+		for j := i; j > 0 && w[j].index < w[j-1].index; j-- {
+			w[j], w[j-1] = w[j-1], w[j]
+		}
+	}
+}
+
+// progress returns the eased progress of an animation started at start,
+// from 0 to 1.
+func (l *List) progress(gtx Context, start time.Time) float32 {
+	t := float32(gtx.Now.Sub(start)) / float32(l.Animate)
+	if t >= 1 {
+		return 1
+	}
+	if t < 0 {
+		t = 0
+	}
+	return t * t * (3 - 2*t)
+}
+
+// animate the element at index with dimensions dims and operations call,
+// and returns the animated dimensions and operations. An entering
+// element grows from zero size, and the gaps of the removed elements
+// next to it collapse to zero size.
+func (l *List) animate(gtx Context, index int, dims Dimensions, call op.CallOp) (Dimensions, op.CallOp) {
+	a := &l.anim
+	key := l.ElementKey(index)
+	sz := l.Axis.Convert(dims.Size)
+	if !gtx.IsMeasuring() {
+		a.next = append(a.next, listElement{key: key, index: index, size: sz.X})
+	}
+	start, entering := a.entering[key]
+	hasGap := false
+	for _, g := range a.gaps {
+		if g.at == key {
+			hasGap = true
+			break
+		}
+	}
+	if !entering && !hasGap {
+		return dims, call
+	}
+	op.InvalidateOp{}.Add(gtx.Ops)
+	macro := op.Record(gtx.Ops)
+	pos := l.layoutGaps(gtx, key, false, 0)
+	size := sz.X
+	if entering {
+		size = int(float32(size)*l.progress(gtx, start) + .5)
+	}
+	l.place(gtx.Ops, pos, size, call)
+	pos = l.layoutGaps(gtx, key, true, pos+size)
+	dims.Size = l.Axis.Convert(image.Pt(pos, sz.Y))
+	return dims, macro.Stop()
+}
+
+// layoutGaps lays out the gaps before or after the element with key,
+// starting at pos, and returns the end position of the gaps.
+func (l *List) layoutGaps(gtx Context, key interface{}, after bool, pos int) int {
+	for _, g := range l.anim.gaps {
+		if g.at != key || g.after != after {
+			continue
+		}
+		size := int(float32(g.size)*(1-l.progress(gtx, g.start)) + .5)
+		if l.RemovedElement != nil {
+			macro := op.Record(gtx.Ops)
+			l.RemovedElement(gtx, g.key)
+			l.place(gtx.Ops, pos, size, macro.Stop())
+		}
+		pos += size
+	}
+	return pos
+}
+
+// place adds call at the main axis position pos, clipped to the main
+// axis size.
+func (l *List) place(ops *op.Ops, pos, size int, call op.CallOp) {
+	r := image.Rectangle{
+		Min: l.Axis.Convert(image.Pt(pos, -inf)),
+		Max: l.Axis.Convert(image.Pt(pos+size, inf)),
+	}
+	defer clip.Rect(r).Push(ops).Pop()
+	defer op.Offset(FPt(l.Axis.Convert(image.Pt(pos, 0)))).Push(ops).Pop()
+	call.Add(ops)
+}
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+	"math"
+
+	"gioui.org/gesture"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/unit"
+)
+
+// Splitter lays out two widgets along an axis, separated by a divider
+// that resizes them when dragged. A double click on the divider resets
+// the split to DefaultRatio.
+type Splitter struct {
+	Axis Axis
+	// Ratio is the fraction of the space, excluding the divider, given
+	// to the first widget. Ratio is updated by drags of the divider. A
+	// zero Ratio before the first Layout is replaced by DefaultRatio.
+	Ratio float32
+	// DefaultRatio is the initial Ratio, and the Ratio restored by
+	// double clicks. Zero means 0.5.
+	DefaultRatio float32
+	// FirstMin and SecondMin are the minimum sizes of the widgets along
+	// the axis. If both don't fit, the first widget takes precedence.
+	FirstMin, SecondMin unit.Value
+
+	drag  gesture.Drag
+	click gesture.Click
+	// grab is the position along the axis of the drag in the divider.
+	grab float32
+	// pos is the divider position, and avail the space of the widgets,
+	// in the most recent Layout.
+	pos, avail int
+	changed    bool
+	laidOut    bool
+}
+
+// Changed reports whether Ratio changed by a drag or double click of the
+// divider since the last call to Changed.
+func (s *Splitter) Changed() bool {
+	c := s.changed
+	s.changed = false
+	return c
+}
+
+// Dragging reports whether the divider is being dragged.
+func (s *Splitter) Dragging() bool {
+	return s.drag.Dragging()
+}
+
+// Layout the first and second widgets and the divider between them. The
+// splitter fills the maximum constraints, and the widgets and divider are
+// laid out with exact constraints but for the divider size along the
+// axis.
+func (s *Splitter) Layout(gtx Context, first, divider, second Widget) Dimensions {
+	if !s.laidOut {
+		s.laidOut = true
+		if s.Ratio == 0 {
+			s.Ratio = s.defaultRatio()
+		}
+	}
+	if !gtx.IsMeasuring() {
+		s.update(gtx)
+	}
+	mainMax, _ := s.Axis.mainConstraint(gtx.Constraints)
+	_, crossMax := s.Axis.crossConstraint(gtx.Constraints)
+
+	macro := op.Record(gtx.Ops)
+	dgtx := gtx
+	dgtx.Constraints = s.Axis.constraints(0, mainMax, crossMax, crossMax)
+	ddims := divider(dgtx)
+	dcall := macro.Stop()
+	dsize := s.Axis.Convert(ddims.Size).X
+
+	avail := mainMax - dsize
+	if avail < 0 {
+		avail = 0
+	}
+	pos := s.clamp(gtx, int(math.Round(float64(s.Ratio)*float64(avail))), avail)
+	if !gtx.IsMeasuring() {
+		s.pos, s.avail = pos, avail
+	}
+
+	cgtx := gtx
+	cgtx.Constraints = s.Axis.constraints(pos, pos, crossMax, crossMax)
+	first(cgtx)
+
+	off := s.Axis.Convert(image.Pt(pos+dsize, 0))
+	trans := op.Offset(FPt(off)).Push(gtx.Ops)
+	cgtx.Constraints = s.Axis.constraints(avail-pos, avail-pos, crossMax, crossMax)
+	second(cgtx)
+	trans.Pop()
+
+	off = s.Axis.Convert(image.Pt(pos, 0))
+	trans = op.Offset(FPt(off)).Push(gtx.Ops)
+	dcall.Add(gtx.Ops)
+	if !gtx.IsMeasuring() {
+		area := clip.Rect{Max: s.Axis.Convert(image.Pt(dsize, crossMax))}.Push(gtx.Ops)
+		s.drag.Add(gtx.Ops)
+		s.click.Add(gtx.Ops)
+		if s.Axis == Horizontal {
+			pointer.CursorColResize.Add(gtx.Ops)
+		} else {
+			pointer.CursorRowResize.Add(gtx.Ops)
+		}
+		area.Pop()
+	}
+	trans.Pop()
+	return Dimensions{Size: s.Axis.Convert(image.Pt(mainMax, crossMax))}
+}
+
+// update the Ratio from the drags and double clicks of the divider. The
+// event positions are relative to the divider of the previous Layout.
+func (s *Splitter) update(gtx Context) {
+	for _, e := range s.drag.Events(gtx.Metric, gtx, gesture.Axis(s.Axis)) {
+		p := s.Axis.FConvert(e.Position).X
+		switch e.Type {
+		case pointer.Press:
+			s.grab = p
+		case pointer.Drag:
+			if s.avail == 0 {
+				break
+			}
+			pos := s.clamp(gtx, s.pos+int(math.Round(float64(p-s.grab))), s.avail)
+			if r := float32(pos) / float32(s.avail); r != s.Ratio {
+				s.Ratio = r
+				s.changed = true
+			}
+		}
+	}
+	for _, e := range s.click.Events(gtx) {
+		if e.Type == gesture.TypeClick && e.NumClicks == 2 {
+			if r := s.defaultRatio(); r != s.Ratio {
+				s.Ratio = r
+				s.changed = true
+			}
+		}
+	}
+}
+
+// clamp the divider position pos to the minimum sizes of the widgets.
+func (s *Splitter) clamp(gtx Context, pos, avail int) int {
+	if max := avail - gtx.Px(s.SecondMin); pos > max {
+		pos = max
+	}
+	if min := gtx.Px(s.FirstMin); pos < min {
+		pos = min
+	}
+	if pos > avail {
+		pos = avail
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	return pos
+}
+
+func (s *Splitter) defaultRatio() float32 {
+	if s.DefaultRatio == 0 {
+		return .5
+	}
+	return s.DefaultRatio
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+func TestSplitter(t *testing.T) {
+	r := new(router.Router)
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(210, 50)),
+		Queue:       r,
+	}
+	s := Splitter{
+		FirstMin:  unit.Px(20),
+		SecondMin: unit.Px(30),
+	}
+	var sizes [2]int
+	layout := func() {
+		gtx.Ops.Reset()
+		s.Layout(gtx,
+			func(gtx Context) Dimensions {
+				sizes[0] = gtx.Constraints.Min.X
+				return Dimensions{Size: gtx.Constraints.Min}
+			},
+			func(gtx Context) Dimensions {
+				return Dimensions{Size: image.Pt(10, gtx.Constraints.Min.Y)}
+			},
+			func(gtx Context) Dimensions {
+				sizes[1] = gtx.Constraints.Min.X
+				return Dimensions{Size: gtx.Constraints.Min}
+			},
+		)
+		r.Frame(gtx.Ops)
+	}
+	layout()
+	if s.Ratio != .5 || sizes != [2]int{100, 100} {
+		t.Fatalf("got ratio %v and sizes %v; expected 0.5 and [100 100]", s.Ratio, sizes)
+	}
+
+	// drag the divider from x to the positions.
+	drag := func(x float32, positions ...float32) {
+		r.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(x, 25)})
+		for _, x := range positions {
+			r.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(x, 25)})
+			layout()
+		}
+		r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(positions[len(positions)-1], 25)})
+		layout()
+	}
+	drag(105, 125, 155)
+	if !s.Changed() {
+		t.Error("drag didn't change the splitter")
+	}
+	if s.Ratio != .75 || sizes != [2]int{150, 50} {
+		t.Errorf("got ratio %v and sizes %v; expected 0.75 and [150 50]", s.Ratio, sizes)
+	}
+	if s.Changed() {
+		t.Error("Changed reported a change twice")
+	}
+
+	// Drags are clamped to the minimum sizes.
+	drag(155, 300)
+	if exp := float32(170) / 200; s.Ratio != exp || sizes != [2]int{170, 30} {
+		t.Errorf("got ratio %v and sizes %v; expected %v and [170 30]", s.Ratio, sizes, exp)
+	}
+	drag(175, 0)
+	if exp := float32(20) / 200; s.Ratio != exp || sizes != [2]int{20, 180} {
+		t.Errorf("got ratio %v and sizes %v; expected %v and [20 180]", s.Ratio, sizes, exp)
+	}
+
+	// A double click resets the ratio.
+	s.Changed()
+	for i := 0; i < 2; i++ {
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(25, 25)},
+			pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(25, 25)},
+		)
+	}
+	layout()
+	layout()
+	if !s.Changed() || s.Ratio != .5 || sizes != [2]int{100, 100} {
+		t.Errorf("got ratio %v and sizes %v after double click; expected 0.5 and [100 100]", s.Ratio, sizes)
+	}
+}
@@ -18,6 +18,10 @@ type Stack struct {
 
 // StackChild represents a child for a Stack layout.
 type StackChild struct {
+	// Offset displaces the child from its aligned position. It
+	// doesn't affect the size of the Stack.
+	Offset image.Point
+
 	expanded bool
 	widget   Widget
 
@@ -74,7 +78,8 @@ func (s Stack) Layout(gtx Context, children ...StackChild) Dimensions {
 			continue
 		}
 		macro := op.Record(gtx.Ops)
-		cgtx.Constraints.Min = maxSZ
+		// Stacked children may exceed the maximum constraints.
+		cgtx.Constraints.Min = gtx.Constraints.Constrain(maxSZ)
 		dims := w.widget(cgtx)
 		call := macro.Stop()
 		if w := dims.Size.X; w > maxSZ.X {
@@ -91,19 +96,7 @@ func (s Stack) Layout(gtx Context, children ...StackChild) Dimensions {
 	var baseline int
 	for _, ch := range children {
 		sz := ch.dims.Size
-		var p image.Point
-		switch s.Alignment {
-		case N, S, Center:
-			p.X = (maxSZ.X - sz.X) / 2
-		case NE, SE, E:
-			p.X = maxSZ.X - sz.X
-		}
-		switch s.Alignment {
-		case W, Center, E:
-			p.Y = (maxSZ.Y - sz.Y) / 2
-		case SW, S, SE:
-			p.Y = maxSZ.Y - sz.Y
-		}
+		p := s.Alignment.Position(sz, maxSZ).Add(ch.Offset)
 		trans := op.Offset(FPt(p)).Push(gtx.Ops)
 		ch.call.Add(gtx.Ops)
 		trans.Pop()
@@ -22,6 +22,15 @@ type Op struct {
 
 	outline bool
 	width   float32
+	aliased bool
+}
+
+// Aliased returns a copy of p without antialiasing: the clip area covers
+// exactly the pixels whose centers are inside it. Adjacent aliased clip
+// areas tile without seams.
+func (p Op) Aliased() Op {
+	p.aliased = true
+	return p
 }
 
 // Stack represents an Op pushed on the clip stack.
@@ -98,6 +107,9 @@ func (p Op) add(o *op.Ops) {
 		data[17] = byte(1)
 	}
 	data[18] = byte(path.shape)
+	if p.aliased {
+		data[19] = byte(1)
+	}
 }
 
 func (s Stack) Pop() {
@@ -3,12 +3,14 @@
 package clip_test
 
 import (
+	"image"
 	"image/color"
 	"math"
 	"testing"
 
 	"gioui.org/f32"
 	"gioui.org/gpu/headless"
+	"gioui.org/internal/ops"
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
@@ -72,6 +74,33 @@ func TestEmptyPath(t *testing.T) {
 	}.Op().Push(&ops).Pop()
 }
 
+func TestAliased(t *testing.T) {
+	aliased := func(c clip.Op) bool {
+		var o op.Ops
+		c.Push(&o).Pop()
+		var r ops.Reader
+		r.Reset(&o.Internal)
+		for {
+			e, ok := r.Decode()
+			if !ok {
+				t.Fatal("no clip op")
+			}
+			if ops.OpType(e.Data[0]) == ops.TypeClip {
+				var c ops.ClipOp
+				c.Decode(e.Data)
+				return c.Aliased
+			}
+		}
+	}
+	r := clip.Rect(image.Rect(0, 0, 10, 10)).Op()
+	if aliased(r) {
+		t.Error("clip op is aliased by default")
+	}
+	if !aliased(r.Aliased()) {
+		t.Error("Aliased clip op decoded without Aliased")
+	}
+}
+
 func newWindow(t testing.TB, width, height int) *headless.Window {
 	w, err := headless.NewWindow(width, height)
 	if err != nil {
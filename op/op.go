@@ -101,6 +101,11 @@ type CallOp struct {
 // the zero value to request an immediate redraw.
 type InvalidateOp struct {
 	At time.Time
+	// Scheduled marks the redraw as scheduled, such as for a timer, in
+	// contrast to a redraw for continuing an animation. Animation redraws
+	// are dropped while a window is paused, whereas scheduled redraws are
+	// kept until the window resumes.
+	Scheduled bool
 }
 
 // TransformOp represents a transformation that can be pushed on the
@@ -123,7 +128,33 @@ type TransformStack struct {
 //
 // Note that deferred operations are executed in first-in-first-out order,
 // unlike the Go facility of the same name.
+//
+// Defer is equivalent to an unnamed Layer of order 0.
 func Defer(o *Ops, c CallOp) {
+	deferLayer(o, nil, 0, c)
+}
+
+// Layer is like Defer, except that c is executed in the named layer of the
+// given order. Layers execute after all other operations, in increasing
+// order, regardless of the order they were added. Operations added to
+// layers of equal order execute in first-in-first-out order. Unnamed
+// deferred operations execute in layer order 0.
+//
+// Layers are useful for content that must be drawn and receive input
+// above other content, such as a heads-up display above a canvas.
+func Layer(o *Ops, name string, order int, c CallOp) {
+	deferLayer(o, &name, order, c)
+}
+
+// HideLayer omits the operations of the named layer. The layer is hidden
+// for the remainder of the operation list, including operations added to
+// the layer before HideLayer.
+func HideLayer(o *Ops, name string) {
+	data := ops.Write1(&o.Internal, ops.TypeHideLayerLen, &name)
+	data[0] = byte(ops.TypeHideLayer)
+}
+
+func deferLayer(o *Ops, name *string, order int, c CallOp) {
 	if c.ops == nil {
 		return
 	}
@@ -135,8 +166,10 @@ func Defer(o *Ops, c CallOp) {
 	c = m.Stop()
 	// A Defer is recorded as a TypeDefer followed by the
 	// wrapped macro.
-	data := ops.Write(&o.Internal, ops.TypeDeferLen)
+	data := ops.Write1(&o.Internal, ops.TypeDeferLen, name)
 	data[0] = byte(ops.TypeDefer)
+	bo := binary.LittleEndian
+	bo.PutUint32(data[1:], uint32(order))
 	c.Add(o)
 }
 
@@ -146,6 +179,23 @@ func (o *Ops) Reset() {
 	ops.Reset(&o.Internal)
 }
 
+// Validate returns an error if a clip, transform, pass or expand
+// operation was pushed but not popped, or a macro was recorded but not
+// stopped. Unbalanced operations corrupt the operations added after
+// them, so Validate is useful for checking a frame before drawing it.
+// The error includes the call sites of the pushes if debugging is
+// enabled by SetDebug.
+func (o *Ops) Validate() error {
+	return ops.Validate(&o.Internal)
+}
+
+// SetDebug enables the tracking of the call sites of the operations
+// pushed after the call, for the errors of Validate. Tracking slows down
+// the adding of operations.
+func (o *Ops) SetDebug(enable bool) {
+	ops.SetDebug(&o.Internal, enable)
+}
+
 // Record a macro of operations.
 func Record(o *Ops) MacroOp {
 	m := MacroOp{
@@ -193,6 +243,9 @@ func (r InvalidateOp) Add(o *Ops) {
 			bo.PutUint64(data[1:], uint64(nanos))
 		}
 	}
+	if r.Scheduled {
+		data[9] = 1
+	}
 }
 
 // Offset creates a TransformOp with the offset o.
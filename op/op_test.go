@@ -3,9 +3,11 @@
 package op
 
 import (
+	"reflect"
 	"testing"
 
 	"gioui.org/f32"
+	"gioui.org/internal/ops"
 )
 
 func TestTransformChecks(t *testing.T) {
@@ -19,3 +21,38 @@ func TestTransformChecks(t *testing.T) {
 	Record(&ops)
 	trans.Pop()
 }
+
+func TestLayerOrder(t *testing.T) {
+	var o Ops
+	layer := func(name string, order int, x float32) {
+		m := Record(&o)
+		Offset(f32.Pt(x, 0)).Add(&o)
+		Layer(&o, name, order, m.Stop())
+	}
+	layer("hud", 2, 4)
+	layer("canvas", 0, 1)
+	layer("overlay", 1, 3)
+	m := Record(&o)
+	Offset(f32.Pt(2, 0)).Add(&o)
+	Defer(&o, m.Stop())
+	layer("hidden", 1, 5)
+	Offset(f32.Pt(0, 0)).Add(&o)
+	HideLayer(&o, "hidden")
+
+	var got []float32
+	var r ops.Reader
+	r.Reset(&o.Internal)
+	for {
+		e, ok := r.Decode()
+		if !ok {
+			break
+		}
+		if ops.OpType(e.Data[0]) == ops.TypeTransform {
+			t, _ := ops.DecodeTransform(e.Data)
+			got = append(got, t.Transform(f32.Point{}).X)
+		}
+	}
+	if exp := []float32{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("got offsets %v; expected %v", got, exp)
+	}
+}
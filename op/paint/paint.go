@@ -44,6 +44,21 @@ type LinearGradientOp struct {
 type PaintOp struct {
 }
 
+// TiledImageOp fills the current clip area with repeated copies, or
+// tiles, of an image. The tiles are laid out in a grid in the current
+// transformation, with a tile at Offset and every tile scaled to
+// TileSize. A zero TileSize means the size of the image. The current
+// brush is set to Src.
+//
+// Unlike an ImageOp followed by a PaintOp for every tile, a
+// TiledImageOp is a single operation regardless of the number of tiles
+// in the clip area.
+type TiledImageOp struct {
+	Src      ImageOp
+	TileSize f32.Point
+	Offset   f32.Point
+}
+
 // NewImageOp creates an ImageOp backed by src.
 //
 // NewImageOp assumes the backing image is immutable, and may cache a
@@ -126,6 +141,25 @@ func (c LinearGradientOp) Add(o *op.Ops) {
 	data[21+3] = c.Color2.A
 }
 
+func (t TiledImageOp) Add(o *op.Ops) {
+	src := t.Src
+	if src.uniform {
+		// Uniform tiles are a uniform fill.
+		src.Add(o)
+		PaintOp{}.Add(o)
+		return
+	} else if src.src == nil || src.src.Bounds().Empty() {
+		return
+	}
+	data := ops.Write2(&o.Internal, ops.TypeTiledImageLen, src.src, src.handle)
+	data[0] = byte(ops.TypeTiledImage)
+	bo := binary.LittleEndian
+	bo.PutUint32(data[1:], math.Float32bits(t.TileSize.X))
+	bo.PutUint32(data[5:], math.Float32bits(t.TileSize.Y))
+	bo.PutUint32(data[9:], math.Float32bits(t.Offset.X))
+	bo.PutUint32(data[13:], math.Float32bits(t.Offset.Y))
+}
+
 func (d PaintOp) Add(o *op.Ops) {
 	data := ops.Write(&o.Internal, ops.TypePaintLen)
 	data[0] = byte(ops.TypePaint)
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package op_test
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+func TestValidate(t *testing.T) {
+	var ops op.Ops
+	ops.SetDebug(true)
+	cl := clip.Rect(image.Rect(0, 0, 10, 10)).Push(&ops)
+	_, file, line, _ := runtime.Caller(0)
+	pushed := fmt.Sprintf("%s:%d", filepath.Base(file), line-1)
+	op.Offset(f32.Pt(1, 1)).Push(&ops).Pop()
+	if err := ops.Validate(); err == nil {
+		t.Fatal("unbalanced clip not reported")
+	} else if msg := err.Error(); !strings.Contains(msg, "clip not popped") || !strings.Contains(msg, pushed) {
+		t.Errorf("error %q doesn't report the clip push", msg)
+	} else if strings.Contains(msg, "transform") {
+		t.Errorf("error %q reports the balanced transform", msg)
+	}
+	cl.Pop()
+	if err := ops.Validate(); err != nil {
+		t.Errorf("balanced operations reported: %v", err)
+	}
+
+	op.Record(&ops)
+	if err := ops.Validate(); err == nil || !strings.Contains(err.Error(), "macro not stopped") {
+		t.Errorf("unstopped macro not reported: %v", err)
+	}
+	ops.Reset()
+	if err := ops.Validate(); err != nil {
+		t.Errorf("reset operations reported: %v", err)
+	}
+	// Without debugging, the errors don't include locations.
+	var ops2 op.Ops
+	clip.Rect(image.Rect(0, 0, 10, 10)).Push(&ops2)
+	if err := ops2.Validate(); err == nil || strings.Contains(err.Error(), "pushed at") {
+		t.Errorf("got error %v, expected an error without location", err)
+	}
+}
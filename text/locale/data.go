@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package locale
+
+import "time"
+
+var (
+	englishMonths = [12]string{
+		"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December",
+	}
+	englishWeekdays = [7]string{
+		"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday",
+	}
+	englishShortWeekdays = [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+)
+
+// formats are the built-in formats. Months are in their stand-alone
+// forms, as used in calendar headers.
+var formats = []*Format{
+	{
+		Tag:           "en",
+		Decimal:       '.',
+		Group:         ',',
+		Months:        englishMonths,
+		Weekdays:      englishWeekdays,
+		ShortWeekdays: englishShortWeekdays,
+		FirstDay:      time.Sunday,
+		MonthYear:     "%[1]s %[2]d",
+	},
+	{
+		Tag:           "en-GB",
+		Decimal:       '.',
+		Group:         ',',
+		Months:        englishMonths,
+		Weekdays:      englishWeekdays,
+		ShortWeekdays: englishShortWeekdays,
+		FirstDay:      time.Monday,
+		MonthYear:     "%[1]s %[2]d",
+	},
+	{
+		Tag:     "de",
+		Decimal: ',',
+		Group:   '.',
+		Months: [12]string{
+			"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember",
+		},
+		Weekdays: [7]string{
+			"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag",
+		},
+		ShortWeekdays: [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+		FirstDay:      time.Monday,
+		MonthYear:     "%[1]s %[2]d",
+	},
+	{
+		Tag:     "fr",
+		Decimal: ',',
+		Group:   '\u202f',
+		Months: [12]string{
+			"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+		},
+		Weekdays: [7]string{
+			"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi",
+		},
+		ShortWeekdays: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		FirstDay:      time.Monday,
+		MonthYear:     "%[1]s %[2]d",
+	},
+	{
+		Tag:     "es",
+		Decimal: ',',
+		Group:   '.',
+		Months: [12]string{
+			"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+		},
+		Weekdays: [7]string{
+			"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado",
+		},
+		ShortWeekdays: [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		FirstDay:      time.Monday,
+		MonthYear:     "%[1]s de %[2]d",
+	},
+	{
+		Tag:     "it",
+		Decimal: ',',
+		Group:   '.',
+		Months: [12]string{
+			"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno",
+			"luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre",
+		},
+		Weekdays: [7]string{
+			"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato",
+		},
+		ShortWeekdays: [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+		FirstDay:      time.Monday,
+		MonthYear:     "%[1]s %[2]d",
+	},
+	{
+		Tag:     "pt",
+		Decimal: ',',
+		Group:   '.',
+		Months: [12]string{
+			"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+			"julho", "agosto", "setembro", "outubro", "novembro", "dezembro",
+		},
+		Weekdays: [7]string{
+			"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado",
+		},
+		ShortWeekdays: [7]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+		FirstDay:      time.Sunday,
+		MonthYear:     "%[1]s de %[2]d",
+	},
+	{
+		Tag:     "nl",
+		Decimal: ',',
+		Group:   '.',
+		Months: [12]string{
+			"januari", "februari", "maart", "april", "mei", "juni",
+			"juli", "augustus", "september", "oktober", "november", "december",
+		},
+		Weekdays: [7]string{
+			"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag",
+		},
+		ShortWeekdays: [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+		FirstDay:      time.Monday,
+		MonthYear:     "%[1]s %[2]d",
+	},
+	{
+		Tag:     "sv",
+		Decimal: ',',
+		Group:   '\u00a0',
+		Months: [12]string{
+			"januari", "februari", "mars", "april", "maj", "juni",
+			"juli", "augusti", "september", "oktober", "november", "december",
+		},
+		Weekdays: [7]string{
+			"söndag", "måndag", "tisdag", "onsdag", "torsdag", "fredag", "lördag",
+		},
+		ShortWeekdays: [7]string{"sön", "mån", "tis", "ons", "tors", "fre", "lör"},
+		FirstDay:      time.Monday,
+		MonthYear:     "%[1]s %[2]d",
+	},
+	{
+		Tag:     "pl",
+		Decimal: ',',
+		Group:   '\u00a0',
+		Months: [12]string{
+			"styczeń", "luty", "marzec", "kwiecień", "maj", "czerwiec",
+			"lipiec", "sierpień", "wrzesień", "październik", "listopad", "grudzień",
+		},
+		Weekdays: [7]string{
+			"niedziela", "poniedziałek", "wtorek", "środa", "czwartek", "piątek", "sobota",
+		},
+		ShortWeekdays: [7]string{"niedz.", "pon.", "wt.", "śr.", "czw.", "pt.", "sob."},
+		FirstDay:      time.Monday,
+		MonthYear:     "%[1]s %[2]d",
+	},
+	{
+		Tag:     "ru",
+		Decimal: ',',
+		Group:   '\u00a0',
+		Months: [12]string{
+			"январь", "февраль", "март", "апрель", "май", "июнь",
+			"июль", "август", "сентябрь", "октябрь", "ноябрь", "декабрь",
+		},
+		Weekdays: [7]string{
+			"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота",
+		},
+		ShortWeekdays: [7]string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"},
+		FirstDay:      time.Monday,
+		MonthYear:     "%[1]s %[2]d г.",
+	},
+	{
+		Tag:     "ja",
+		Decimal: '.',
+		Group:   ',',
+		Months: [12]string{
+			"1月", "2月", "3月", "4月", "5月", "6月",
+			"7月", "8月", "9月", "10月", "11月", "12月",
+		},
+		Weekdays: [7]string{
+			"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日",
+		},
+		ShortWeekdays: [7]string{"日", "月", "火", "水", "木", "金", "土"},
+		FirstDay:      time.Sunday,
+		MonthYear:     "%[2]d年%[1]s",
+	},
+	{
+		Tag:     "zh",
+		Decimal: '.',
+		Group:   ',',
+		Months: [12]string{
+			"1月", "2月", "3月", "4月", "5月", "6月",
+			"7月", "8月", "9月", "10月", "11月", "12月",
+		},
+		Weekdays: [7]string{
+			"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六",
+		},
+		ShortWeekdays: [7]string{"日", "一", "二", "三", "四", "五", "六"},
+		FirstDay:      time.Monday,
+		MonthYear:     "%[2]d年%[1]s",
+	},
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package locale implements the number and date conventions of locales,
+for widgets that format numbers and dates.
+
+Formats are looked up by BCP 47 language tags, such as the tag reported
+by the platform in a system.FrameEvent. The package includes the formats
+of a practical set of locales; use Register to add formats for others.
+*/
+package locale
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format describes the formatting conventions of a locale.
+type Format struct {
+	// Tag is the BCP 47 language tag of the locale, such as "en" or
+	// "en-GB".
+	Tag string
+	// Decimal is the decimal separator.
+	Decimal rune
+	// Group is the separator between groups of three digits, or zero
+	// for no grouping.
+	Group rune
+	// Months are the names of the months, starting with January.
+	Months [12]string
+	// Weekdays are the names of the weekdays, starting with Sunday.
+	Weekdays [7]string
+	// ShortWeekdays are the abbreviated names of the weekdays, starting
+	// with Sunday.
+	ShortWeekdays [7]string
+	// FirstDay is the first day of the week.
+	FirstDay time.Weekday
+	// MonthYear is the fmt format of a month and year, where the first
+	// operand is the month name and the second is the year.
+	MonthYear string
+}
+
+// Neutral is the Format used for locales without a registered Format.
+var Neutral = &Format{
+	Decimal:       '.',
+	Months:        englishMonths,
+	Weekdays:      englishWeekdays,
+	ShortWeekdays: englishShortWeekdays,
+	FirstDay:      time.Monday,
+	MonthYear:     "%[1]s %[2]d",
+}
+
+var registry struct {
+	mu      sync.RWMutex
+	formats map[string]*Format
+}
+
+func init() {
+	registry.formats = make(map[string]*Format)
+	for _, f := range formats {
+		Register(f)
+	}
+}
+
+// Register a Format for its Tag, replacing any previous Format for the
+// same tag.
+func Register(f *Format) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.formats[normalize(f.Tag)] = f
+}
+
+// Lookup returns the Format that best matches a language tag. Lookup
+// tries the full tag, then the language and region, then the language
+// alone, and returns Neutral if no Format matches.
+func Lookup(tag string) *Format {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	tag = normalize(tag)
+	if f, ok := registry.formats[tag]; ok {
+		return f
+	}
+	subtags := strings.Split(tag, "-")
+	lang := subtags[0]
+	for _, s := range subtags[1:] {
+		// Region subtags are two letters or three digits.
+		if len(s) == 2 || len(s) == 3 && s[0] >= '0' && s[0] <= '9' {
+			if f, ok := registry.formats[lang+"-"+s]; ok {
+				return f
+			}
+			break
+		}
+	}
+	if f, ok := registry.formats[lang]; ok {
+		return f
+	}
+	return Neutral
+}
+
+func normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+}
+
+// FormatInt formats an integer with grouped digits.
+func (f *Format) FormatInt(v int64) string {
+	return f.localize(strconv.FormatInt(v, 10))
+}
+
+// FormatFloat formats a number with prec digits after the decimal
+// separator. A prec of -1 uses the smallest number of digits necessary
+// to represent the value exactly.
+func (f *Format) FormatFloat(v float64, prec int) string {
+	return f.localize(strconv.FormatFloat(v, 'f', prec, 64))
+}
+
+// localize the separators of a number formatted by strconv.
+func (f *Format) localize(s string) string {
+	var b strings.Builder
+	if strings.HasPrefix(s, "-") {
+		b.WriteByte('-')
+		s = s[1:]
+	}
+	digits, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		digits, frac = s[:i], s[i+1:]
+	}
+	for i, d := range digits {
+		if i > 0 && f.Group != 0 && (len(digits)-i)%3 == 0 {
+			b.WriteRune(f.Group)
+		}
+		b.WriteRune(d)
+	}
+	if frac != "" {
+		b.WriteRune(f.Decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// ParseFloat parses a number formatted in the Format. Group separators
+// are ignored, and spaces are accepted for separators that are spaces.
+func (f *Format) ParseFloat(s string) (float64, error) {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case r == f.Decimal:
+			b.WriteByte('.')
+		case r == f.Group, isSpace(r) && isSpace(f.Group):
+		case r == '\u2212':
+			b.WriteByte('-')
+		case r == '.' || r == ',':
+			// Reject foreign separators, so "1.5" is not parsed as 15
+			// in locales with a ',' decimal separator.
+			return 0, fmt.Errorf("locale: invalid number %q", s)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	v, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("locale: invalid number %q", s)
+	}
+	return v, nil
+}
+
+func isSpace(r rune) bool {
+	switch r {
+	case ' ', '\u00a0', '\u202f':
+		return true
+	}
+	return false
+}
+
+// FormatMonthYear formats the month and year of t.
+func (f *Format) FormatMonthYear(t time.Time) string {
+	return fmt.Sprintf(f.MonthYear, f.Months[t.Month()-1], t.Year())
+}
+
+// Column returns the column of a weekday in a week that starts on
+// FirstDay.
+func (f *Format) Column(wd time.Weekday) int {
+	return (int(wd) - int(f.FirstDay) + 7) % 7
+}
+
+// WeekdayAt returns the weekday of a column in a week that starts on
+// FirstDay.
+func (f *Format) WeekdayAt(col int) time.Weekday {
+	return time.Weekday((int(f.FirstDay) + col) % 7)
+}
+
+// String returns the tag of the Format.
+func (f *Format) String() string {
+	if f.Tag == "" {
+		return "neutral"
+	}
+	return f.Tag
+}
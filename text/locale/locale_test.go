@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	for _, tc := range []struct {
+		tag, exp string
+	}{
+		{"en-US", "en"},
+		{"en_GB", "en-GB"},
+		{"en-Latn-GB", "en-GB"},
+		{"de-AT", "de"},
+		{"DE", "de"},
+		{"zh-Hans-CN", "zh"},
+		{"xx-YY", "neutral"},
+		{"", "neutral"},
+	} {
+		if got := Lookup(tc.tag).String(); got != tc.exp {
+			t.Errorf("Lookup(%q) = %s; expected %s", tc.tag, got, tc.exp)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		tag  string
+		v    float64
+		prec int
+		exp  string
+	}{
+		{"en", 1234567.891, 3, "1,234,567.891"},
+		{"de", 1234567.891, 3, "1.234.567,891"},
+		{"fr", -1234.5, 1, "-1\u202f234,5"},
+		{"ru", 100, 2, "100,00"},
+		{"ja", 1000, 0, "1,000"},
+		{"xx", 1234.25, -1, "1234.25"},
+	} {
+		f := Lookup(tc.tag)
+		s := f.FormatFloat(tc.v, tc.prec)
+		if s != tc.exp {
+			t.Errorf("%s: FormatFloat(%v) = %q; expected %q", tc.tag, tc.v, s, tc.exp)
+		}
+		v, err := f.ParseFloat(s)
+		if err != nil {
+			t.Errorf("%s: ParseFloat(%q) failed: %v", tc.tag, s, err)
+			continue
+		}
+		if v != tc.v {
+			t.Errorf("%s: ParseFloat(%q) = %v; expected %v", tc.tag, s, v, tc.v)
+		}
+	}
+	if got := Lookup("en").FormatInt(-1000000); got != "-1,000,000" {
+		t.Errorf("FormatInt = %q; expected -1,000,000", got)
+	}
+}
+
+func TestParseFloat(t *testing.T) {
+	fr := Lookup("fr")
+	if v, err := fr.ParseFloat("1 234,5"); err != nil || v != 1234.5 {
+		t.Errorf("ParseFloat with a space separator = %v, %v; expected 1234.5", v, err)
+	}
+	if _, err := fr.ParseFloat("1.5"); err == nil {
+		t.Error("ParseFloat accepted a foreign decimal separator")
+	}
+	if _, err := Lookup("en").ParseFloat("1,5x"); err == nil {
+		t.Error("ParseFloat accepted an invalid number")
+	}
+}
+
+func TestDates(t *testing.T) {
+	d := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		tag, exp string
+		first    time.Weekday
+	}{
+		{"en-US", "March 2021", time.Sunday},
+		{"en-GB", "March 2021", time.Monday},
+		{"es", "marzo de 2021", time.Monday},
+		{"ja", "2021年3月", time.Sunday},
+	} {
+		f := Lookup(tc.tag)
+		if got := f.FormatMonthYear(d); got != tc.exp {
+			t.Errorf("%s: FormatMonthYear = %q; expected %q", tc.tag, got, tc.exp)
+		}
+		if f.WeekdayAt(0) != tc.first || f.Column(tc.first) != 0 {
+			t.Errorf("%s: week doesn't start on %v", tc.tag, tc.first)
+		}
+		if f.Column(f.WeekdayAt(6)) != 6 {
+			t.Errorf("%s: Column and WeekdayAt disagree", tc.tag)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	f := &Format{Tag: "tlh", Decimal: '.', MonthYear: "%[1]s %[2]d"}
+	Register(f)
+	if Lookup("tlh-Latn") != f {
+		t.Error("registered format not found")
+	}
+}
@@ -3,11 +3,14 @@
 package text
 
 import (
+	"fmt"
 	"io"
 	"strings"
+	"unicode"
 
 	"golang.org/x/image/math/fixed"
 
+	"gioui.org/io/diag"
 	"gioui.org/op/clip"
 )
 
@@ -38,17 +41,39 @@ type FontFace struct {
 type Cache struct {
 	def   Typeface
 	faces map[Font]*faceCache
+	diag  *diag.Reporter
 }
 
 type faceCache struct {
+	font        Font
 	face        Face
 	layoutCache layoutCache
 	pathCache   pathCache
 }
 
+// glyphCoverage is implemented by faces that report whether they have
+// glyphs for runes, such as the faces of package font/opentype.
+type glyphCoverage interface {
+	HasGlyph(r rune) bool
+}
+
+// SetDiagnostics directs the diagnostics of c, such as the use of a
+// fallback face or missing glyphs, to r.
+func (c *Cache) SetDiagnostics(r *diag.Reporter) {
+	c.diag = r
+}
+
 func (c *Cache) lookup(font Font) *faceCache {
 	f := c.faceForStyle(font)
 	if f == nil {
+		if font.Typeface != "" {
+			c.diag.Report(diag.Diagnostic{
+				Severity: diag.Warning,
+				Source:   "text",
+				Message:  fmt.Sprintf("no face for typeface %q, using %q", font.Typeface, c.def),
+				Once:     true,
+			})
+		}
 		font.Typeface = c.def
 		f = c.faceForStyle(font)
 	}
@@ -102,7 +127,7 @@ func NewCache(collection []FontFace) *Cache {
 		if i == 0 {
 			c.def = ff.Font.Typeface
 		}
-		c.faces[ff.Font] = &faceCache{face: ff.Face}
+		c.faces[ff.Font] = &faceCache{font: ff.Font, face: ff.Face}
 	}
 	return c
 }
@@ -116,7 +141,7 @@ func (c *Cache) Layout(font Font, size fixed.Int26_6, maxWidth int, txt io.Reade
 // LayoutString is a caching implementation of the Shaper interface.
 func (c *Cache) LayoutString(font Font, size fixed.Int26_6, maxWidth int, str string) []Line {
 	cache := c.lookup(font)
-	return cache.layout(size, maxWidth, str)
+	return cache.layout(c.diag, size, maxWidth, str)
 }
 
 // Shape is a caching implementation of the Shaper interface. Shape assumes that the layout
@@ -126,7 +151,17 @@ func (c *Cache) Shape(font Font, size fixed.Int26_6, layout Layout) clip.PathSpe
 	return cache.shape(size, layout)
 }
 
-func (f *faceCache) layout(ppem fixed.Int26_6, maxWidth int, str string) []Line {
+// Trim releases the cached layouts and shapes. They are recomputed when
+// needed, so Trim is suitable for reducing the memory use of an idle or
+// hidden window.
+func (c *Cache) Trim() {
+	for _, f := range c.faces {
+		f.layoutCache = layoutCache{}
+		f.pathCache = pathCache{}
+	}
+}
+
+func (f *faceCache) layout(r *diag.Reporter, ppem fixed.Int26_6, maxWidth int, str string) []Line {
 	if f == nil {
 		return nil
 	}
@@ -140,9 +175,29 @@ func (f *faceCache) layout(ppem fixed.Int26_6, maxWidth int, str string) []Line
 	}
 	l, _ := f.face.Layout(ppem, maxWidth, strings.NewReader(str))
 	f.layoutCache.Put(lk, l)
+	f.checkCoverage(r, str)
 	return l
 }
 
+// checkCoverage reports the runes of str that the face has no glyphs for.
+func (f *faceCache) checkCoverage(r *diag.Reporter, str string) {
+	cov, ok := f.face.(glyphCoverage)
+	if !ok || r == nil {
+		return
+	}
+	for _, c := range str {
+		if unicode.IsControl(c) || cov.HasGlyph(c) {
+			continue
+		}
+		r.Report(diag.Diagnostic{
+			Severity: diag.Warning,
+			Source:   "text",
+			Message:  fmt.Sprintf("font %s lacks glyph for %U", f.font.Typeface, c),
+			Once:     true,
+		})
+	}
+}
+
 func (f *faceCache) shape(ppem fixed.Int26_6, layout Layout) clip.PathSpec {
 	if f == nil {
 		return clip.PathSpec{}
@@ -1,7 +1,13 @@
 package text
 
 import (
+	"io"
+	"reflect"
 	"testing"
+
+	"gioui.org/io/diag"
+	"gioui.org/op/clip"
+	"golang.org/x/image/math/fixed"
 )
 
 var (
@@ -105,6 +111,83 @@ func TestClosestFontByWeight(t *testing.T) {
 	}
 }
 
+func TestCacheTrim(t *testing.T) {
+	face := new(countingFace)
+	font := Font{Typeface: testTF1}
+	c := &Cache{def: testTF1, faces: map[Font]*faceCache{font: {face: face}}}
+	shape := func() {
+		l := c.LayoutString(font, fixed.I(10), 100, "text")
+		c.Shape(font, fixed.I(10), l[0].Layout)
+	}
+	shape()
+	shape()
+	if face.layouts != 1 || face.shapes != 1 {
+		t.Fatalf("got %d layouts and %d shapes, expected 1 of each", face.layouts, face.shapes)
+	}
+	c.Trim()
+	shape()
+	shape()
+	if face.layouts != 2 || face.shapes != 2 {
+		t.Errorf("got %d layouts and %d shapes after Trim, expected 2 of each", face.layouts, face.shapes)
+	}
+}
+
+func TestCacheDiagnostics(t *testing.T) {
+	var got []diag.Diagnostic
+	var r diag.Reporter
+	r.SetSink(func(d diag.Diagnostic) {
+		got = append(got, d)
+	})
+	font := Font{Typeface: testTF1}
+	face := &coverageFace{missing: '😀'}
+	c := &Cache{def: testTF1, faces: map[Font]*faceCache{font: {font: font, face: face}}}
+	c.SetDiagnostics(&r)
+	c.LayoutString(font, fixed.I(10), 100, "hi 😀")
+	c.LayoutString(font, fixed.I(10), 100, "😀 again")
+	c.LayoutString(font, fixed.I(10), 100, "fine\n")
+	want := []diag.Diagnostic{
+		{Severity: diag.Warning, Source: "text", Message: "font MockFace lacks glyph for U+1F600", Once: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got diagnostics %v, expected %v", got, want)
+	}
+	got = nil
+	for i := 0; i < 2; i++ {
+		c.LayoutString(Font{Typeface: testTF2}, fixed.I(10), 100, "text")
+	}
+	want = []diag.Diagnostic{
+		{Severity: diag.Warning, Source: "text", Message: `no face for typeface "TestFace", using "MockFace"`, Once: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got diagnostics %v, expected %v", got, want)
+	}
+}
+
+// coverageFace lacks a glyph for a single rune.
+type coverageFace struct {
+	countingFace
+	missing rune
+}
+
+func (f *coverageFace) HasGlyph(r rune) bool {
+	return r != f.missing
+}
+
+// countingFace counts the calls to its methods.
+type countingFace struct {
+	layouts, shapes int
+}
+
+func (f *countingFace) Layout(ppem fixed.Int26_6, maxWidth int, txt io.Reader) ([]Line, error) {
+	f.layouts++
+	return []Line{{Layout: Layout{Text: "text"}}}, nil
+}
+
+func (f *countingFace) Shape(ppem fixed.Int26_6, str Layout) clip.PathSpec {
+	f.shapes++
+	return clip.PathSpec{}
+}
+
 func newTestCache(fonts ...Font) *Cache {
 	c := &Cache{faces: make(map[Font]*faceCache)}
 	c.def = testTF1
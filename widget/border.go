@@ -21,6 +21,9 @@ type Border struct {
 
 func (b Border) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
 	dims := w(gtx)
+	if gtx.IsMeasuring() {
+		return dims
+	}
 	sz := layout.FPt(dims.Size)
 
 	rr := float32(gtx.Px(b.CornerRadius))
@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"time"
+
+	"gioui.org/anim"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+// Crumb is a segment of a Breadcrumbs path.
+type Crumb struct {
+	Label string
+	// Tag identifies the crumb for the program.
+	Tag interface{}
+}
+
+// Breadcrumbs holds the state of a path bar. When the crumbs don't fit,
+// the crumbs between the first and the last are collapsed into an overflow
+// chip that opens a Menu of the collapsed crumbs.
+type Breadcrumbs struct {
+	Crumbs []Crumb
+	// Menu lists the collapsed crumbs.
+	Menu Menu
+
+	clicks   []Clickable
+	overflow Clickable
+	// sizes caches the measured crumb sizes.
+	sizes        map[crumbKey]image.Point
+	overflowSize image.Point
+	metric       unit.Metric
+	// The crumbs in [collapseStart, collapseEnd) are collapsed.
+	collapseStart, collapseEnd int
+	// pos animates the horizontal crumb positions.
+	pos []anim.Value[float32]
+	// shown tracks the crumbs visible in the previous Layout.
+	shown   []bool
+	laidOut bool
+	// chipX is the most recent position of the overflow chip.
+	chipX   float32
+	clicked []int
+}
+
+// crumbKey identifies a crumb for measuring. The first crumb is
+// distinguished because it is usually laid out without a separator.
+type crumbKey struct {
+	label string
+	first bool
+}
+
+// crumbAnimDuration is the duration of crumb movements.
+const crumbAnimDuration = 150 * time.Millisecond
+
+// Clicked returns the index of the next clicked crumb, if any, whether
+// clicked directly or through the overflow Menu.
+func (b *Breadcrumbs) Clicked() (int, bool) {
+	b.updateClicks()
+	if len(b.clicked) == 0 {
+		return 0, false
+	}
+	i := b.clicked[0]
+	n := copy(b.clicked, b.clicked[1:])
+	b.clicked = b.clicked[:n]
+	return i, true
+}
+
+// Collapsed reports whether crumb i is hidden in the overflow menu in the
+// most recent Layout.
+func (b *Breadcrumbs) Collapsed(i int) bool {
+	return b.collapseStart <= i && i < b.collapseEnd
+}
+
+// Clickable returns the clickable for crumb i.
+func (b *Breadcrumbs) Clickable(i int) *Clickable {
+	if n := len(b.Crumbs); n > len(b.clicks) {
+		b.clicks = append(b.clicks, make([]Clickable, n-len(b.clicks))...)
+	}
+	return &b.clicks[i]
+}
+
+// Overflow returns the clickable for the overflow chip.
+func (b *Breadcrumbs) Overflow() *Clickable {
+	return &b.overflow
+}
+
+// Invalidate forgets the measured crumb sizes. Call Invalidate after
+// changing the style of the crumbs.
+func (b *Breadcrumbs) Invalidate() {
+	b.sizes = nil
+	b.overflowSize = image.Point{}
+}
+
+// Layout the crumbs in a row, collapsing crumbs as needed to fit the
+// constraints. The crumb widget lays out crumb i, usually with a separator
+// before every crumb except the first. The overflow widget lays out the
+// chip for the collapsed crumbs, and the menu widget lays out Menu with
+// the origin and constraints of the bar.
+func (b *Breadcrumbs) Layout(gtx layout.Context, crumb func(gtx layout.Context, i int) layout.Dimensions, overflow, menu layout.Widget) layout.Dimensions {
+	b.update(gtx)
+	widths, height := b.measure(gtx, crumb, overflow)
+	b.collapse(widths, gtx.Constraints.Max.X)
+
+	x := 0
+	var chip image.Rectangle
+	for i := range b.Crumbs {
+		if b.Collapsed(i) {
+			if i == b.collapseStart {
+				chip = image.Rect(x, 0, x+b.overflowSize.X, height)
+				b.chipX = float32(x)
+				x += b.overflowSize.X
+			}
+			continue
+		}
+		target := float32(x)
+		switch p := &b.pos[i]; {
+		case !b.laidOut:
+			p.Set(gtx.Now, target, 0, nil)
+		case !b.shown[i]:
+			// Reveal crumbs from the position of the overflow chip.
+			p.Set(gtx.Now, b.chipX, 0, nil)
+			fallthrough
+		case p.Target() != target:
+			p.Set(gtx.Now, target, crumbAnimDuration, anim.EaseOut)
+		}
+		x += widths[i]
+	}
+	b.laidOut = true
+	for i := range b.shown {
+		b.shown[i] = !b.Collapsed(i)
+	}
+	animating := false
+	for i := range b.Crumbs {
+		if b.Collapsed(i) {
+			continue
+		}
+		p := &b.pos[i]
+		animating = animating || p.Animating(gtx.Now)
+		cgtx := gtx
+		cgtx.Constraints = layout.Constraints{Max: image.Pt(widths[i], height)}
+		trans := op.Offset(layout.FPt(image.Pt(int(p.Get(gtx.Now)+.5), 0))).Push(gtx.Ops)
+		crumb(cgtx, i)
+		trans.Pop()
+	}
+	if animating {
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	if b.collapseEnd > b.collapseStart {
+		cgtx := gtx
+		cgtx.Constraints = layout.Exact(chip.Size())
+		trans := op.Offset(layout.FPt(chip.Min)).Push(gtx.Ops)
+		b.overflow.Layout(cgtx, overflow)
+		trans.Pop()
+		if b.overflow.Clicked() {
+			b.Menu.Open(chip)
+		}
+	}
+	if b.Menu.Visible() {
+		macro := op.Record(gtx.Ops)
+		mgtx := gtx
+		mgtx.Constraints.Min = image.Point{}
+		menu(mgtx)
+		op.Defer(gtx.Ops, macro.Stop())
+	}
+	if x > gtx.Constraints.Max.X {
+		x = gtx.Constraints.Max.X
+	}
+	return layout.Dimensions{Size: gtx.Constraints.Constrain(image.Pt(x, height))}
+}
+
+func (b *Breadcrumbs) update(gtx layout.Context) {
+	b.updateClicks()
+	if n := len(b.Crumbs); len(b.pos) != n {
+		if len(b.clicks) > n {
+			b.clicks = b.clicks[:n]
+		}
+		b.pos = make([]anim.Value[float32], n)
+		b.shown = make([]bool, n)
+		b.laidOut = false
+	}
+	if gtx.Metric != b.metric {
+		b.metric = gtx.Metric
+		b.Invalidate()
+	}
+}
+
+func (b *Breadcrumbs) updateClicks() {
+	for i := range b.clicks {
+		for b.clicks[i].Clicked() {
+			b.clicked = append(b.clicked, i)
+		}
+	}
+}
+
+// measure the crumbs and the overflow chip, caching the sizes by label.
+func (b *Breadcrumbs) measure(gtx layout.Context, crumb func(gtx layout.Context, i int) layout.Dimensions, overflow layout.Widget) ([]int, int) {
+	mgtx := gtx.Measuring()
+	mgtx.Constraints = layout.Constraints{Max: image.Pt(inf, gtx.Constraints.Max.Y)}
+	if b.sizes == nil {
+		b.sizes = make(map[crumbKey]image.Point)
+	}
+	widths := make([]int, len(b.Crumbs))
+	height := 0
+	for i, c := range b.Crumbs {
+		k := crumbKey{label: c.Label, first: i == 0}
+		sz, ok := b.sizes[k]
+		if !ok {
+			sz = crumb(mgtx, i).Size
+			b.sizes[k] = sz
+		}
+		widths[i] = sz.X
+		if sz.Y > height {
+			height = sz.Y
+		}
+	}
+	if b.overflowSize == (image.Point{}) {
+		b.overflowSize = overflow(mgtx).Size
+	}
+	if h := b.overflowSize.Y; h > height {
+		height = h
+	}
+	return widths, height
+}
+
+// collapse the fewest middle crumbs needed to fit width, starting from the
+// second crumb. The first and last crumbs are never collapsed.
+func (b *Breadcrumbs) collapse(widths []int, width int) {
+	start, end := 0, 0
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	if n := len(widths); total > width && n > 2 {
+		start, end = 1, 1
+		total += b.overflowSize.X
+		for end < n-1 && total > width {
+			total -= widths[end]
+			end++
+		}
+	}
+	if start != b.collapseStart || end != b.collapseEnd {
+		b.collapseStart, b.collapseEnd = start, end
+		b.Menu.Close()
+	}
+	// Rebuild the items when the collapsed range or crumbs change.
+	items := b.Menu.Items
+	stale := len(items) != end-start
+	for j := 0; !stale && j < len(items); j++ {
+		stale = items[j].Title != b.Crumbs[start+j].Label
+	}
+	if !stale {
+		return
+	}
+	b.Menu.Items = items[:0]
+	for i := start; i < end; i++ {
+		i := i
+		b.Menu.Items = append(b.Menu.Items, MenuItem{
+			Title: b.Crumbs[i].Label,
+			Do:    func() { b.clicked = append(b.clicked, i) },
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+// layoutCrumbs lays out b with crumbs of 50x20 pixels and an overflow chip
+// of 20x20 pixels. Menu items are 100x20 pixels.
+func layoutCrumbs(gtx layout.Context, b *Breadcrumbs) layout.Dimensions {
+	return b.Layout(gtx,
+		func(gtx layout.Context, i int) layout.Dimensions {
+			return b.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Dimensions{Size: image.Pt(50, 20)}
+			})
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{Size: image.Pt(20, 20)}
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Max.Y = 200
+			return b.Menu.Layout(gtx, func(gtx layout.Context, m *Menu) layout.Dimensions {
+				return m.LayoutItems(gtx, func(gtx layout.Context, i int) layout.Dimensions {
+					return m.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return layout.Dimensions{Size: image.Pt(100, 20)}
+					})
+				})
+			})
+		},
+	)
+}
+
+func newCrumbs(n int) *Breadcrumbs {
+	b := new(Breadcrumbs)
+	for i := 0; i < n; i++ {
+		b.Crumbs = append(b.Crumbs, Crumb{Label: string(rune('a' + i))})
+	}
+	return b
+}
+
+func TestBreadcrumbsCollapse(t *testing.T) {
+	b := newCrumbs(5)
+	for _, tc := range []struct {
+		width     int
+		collapsed []bool
+		size      image.Point
+		animating bool
+	}{
+		{300, []bool{false, false, false, false, false}, image.Pt(250, 20), false},
+		{250, []bool{false, false, false, false, false}, image.Pt(250, 20), false},
+		{200, []bool{false, true, true, false, false}, image.Pt(170, 20), true},
+		// The first and last crumbs are never collapsed.
+		{100, []bool{false, true, true, true, false}, image.Pt(100, 20), true},
+		{300, []bool{false, false, false, false, false}, image.Pt(250, 20), true},
+	} {
+		gtx := layout.Context{
+			Ops:         new(op.Ops),
+			Constraints: layout.Constraints{Max: image.Pt(tc.width, 100)},
+		}
+		dims := layoutCrumbs(gtx, b)
+		for i, exp := range tc.collapsed {
+			if got := b.Collapsed(i); got != exp {
+				t.Errorf("width %d: crumb %d collapsed %v; expected %v", tc.width, i, got, exp)
+			}
+		}
+		if dims.Size != tc.size {
+			t.Errorf("width %d: size %v; expected %v", tc.width, dims.Size, tc.size)
+		}
+		if got := b.pos[4].Animating(gtx.Now); got != tc.animating {
+			t.Errorf("width %d: last crumb animating %v; expected %v", tc.width, got, tc.animating)
+		}
+		if got, exp := len(b.Menu.Items), b.collapseEnd-b.collapseStart; got != exp {
+			t.Errorf("width %d: %d menu items; expected %d", tc.width, got, exp)
+		}
+	}
+	// Renaming collapsed crumbs renames their items.
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Constraints{Max: image.Pt(100, 100)},
+	}
+	layoutCrumbs(gtx, b)
+	b.Crumbs[2].Label = "renamed"
+	layoutCrumbs(gtx, b)
+	if got := b.Menu.Items[1].Title; got != "renamed" {
+		t.Errorf("got item title %q after renaming its crumb; expected \"renamed\"", got)
+	}
+}
+
+func TestBreadcrumbsMenu(t *testing.T) {
+	var r router.Router
+	b := newCrumbs(5)
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Constraints{Max: image.Pt(200, 100)},
+		Queue:       &r,
+	}
+	frame := func() {
+		gtx.Ops.Reset()
+		layoutCrumbs(gtx, b)
+		r.Frame(gtx.Ops)
+	}
+	frame()
+	// A direct click reports the crumb.
+	click := func(pos f32.Point) {
+		r.Queue(
+			pointer.Event{Source: pointer.Mouse, Type: pointer.Move, Position: pos},
+			pointer.Event{Source: pointer.Mouse, Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: pos},
+			pointer.Event{Source: pointer.Mouse, Type: pointer.Release, Position: pos},
+		)
+		frame()
+	}
+	click(f32.Pt(150, 10))
+	if i, ok := b.Clicked(); !ok || i != 4 {
+		t.Fatalf("clicked crumb %d, %v; expected 4", i, ok)
+	}
+	// Clicking the overflow chip opens the menu below it.
+	click(f32.Pt(60, 10))
+	frame()
+	if !b.Menu.Visible() {
+		t.Fatal("overflow click did not open the menu")
+	}
+	if got, exp := b.Menu.rect.Min, image.Pt(50, 20); got != exp {
+		t.Errorf("menu at %v; expected %v", got, exp)
+	}
+	// The second menu item is the third crumb.
+	click(f32.Pt(60, 50))
+	if i, ok := b.Clicked(); !ok || i != 2 {
+		t.Errorf("clicked crumb %d, %v; expected 2", i, ok)
+	}
+	if b.Menu.Visible() {
+		t.Error("menu not closed after choosing a crumb")
+	}
+	if _, ok := b.Clicked(); ok {
+		t.Error("spurious click")
+	}
+}
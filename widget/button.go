@@ -25,6 +25,9 @@ type Clickable struct {
 	// clicks bounded.
 	prevClicks int
 	history    []Press
+	// updated is set when Update is called before Layout, to
+	// flush clicks once per frame.
+	updated bool
 
 	keyTag  struct{}
 	focused bool
@@ -102,7 +105,11 @@ func (b *Clickable) History() []Press {
 
 // Layout and update the button state
 func (b *Clickable) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
-	b.update(gtx)
+	if gtx.IsMeasuring() {
+		return w(gtx)
+	}
+	b.Update(gtx)
+	b.updated = false
 	m := op.Record(gtx.Ops)
 	dims := w(gtx)
 	c := m.Stop()
@@ -127,13 +134,20 @@ func (b *Clickable) Layout(gtx layout.Context, w layout.Widget) layout.Dimension
 	return dims
 }
 
-// update the button state by processing events.
-func (b *Clickable) update(gtx layout.Context) {
-	// Flush clicks from before the last update.
-	n := copy(b.clicks, b.clicks[b.prevClicks:])
-	b.clicks = b.clicks[:n]
-	b.prevClicks = n
+// Update the button state by processing events. The state reported by
+// Pressed, Hovered, Focused and Clicks reflects the events up to the most
+// recent Update. Layout calls Update; call Update before Layout to observe
+// the state before laying out the widget.
+func (b *Clickable) Update(gtx layout.Context) {
+	if !b.updated {
+		b.updated = true
+		// Flush clicks from before the last update.
+		n := copy(b.clicks, b.clicks[b.prevClicks:])
+		b.clicks = b.clicks[:n]
+		b.prevClicks = n
+	}
 
+	b.click.Config = gtx.Gesture
 	for _, e := range b.click.Events(gtx) {
 		switch e.Type {
 		case gesture.TypeClick:
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget_test
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget"
+)
+
+func TestClickableStates(t *testing.T) {
+	var (
+		ops op.Ops
+		r   router.Router
+		b   widget.Clickable
+	)
+	gtx := layout.NewContext(&ops, system.FrameEvent{Queue: &r})
+	// frame updates b with events, checks its state and lays it out.
+	frame := func(step string, hovered, pressed, focused bool, clicks int, events ...event.Event) {
+		t.Helper()
+		r.Queue(events...)
+		ops.Reset()
+		b.Update(gtx)
+		if got := b.Hovered(); got != hovered {
+			t.Errorf("%s: Hovered is %v, expected %v", step, got, hovered)
+		}
+		if got := b.Pressed(); got != pressed {
+			t.Errorf("%s: Pressed is %v, expected %v", step, got, pressed)
+		}
+		if got := b.Focused(); got != focused {
+			t.Errorf("%s: Focused is %v, expected %v", step, got, focused)
+		}
+		if got := len(b.Clicks()); got != clicks {
+			t.Errorf("%s: got %d clicks, expected %d", step, got, clicks)
+		}
+		b.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{Size: image.Pt(100, 100)}
+		})
+		r.Frame(gtx.Ops)
+	}
+	frame("initial", false, false, false, 0)
+	frame("enter", true, false, false, 0,
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Move, Position: f32.Pt(50, 50)},
+	)
+	// The press requests focus.
+	frame("press", true, true, false, 0,
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: f32.Pt(50, 50)},
+	)
+	frame("focus", true, true, true, 0)
+	frame("release", true, false, true, 1,
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Release, Position: f32.Pt(50, 50)},
+	)
+	frame("leave", false, false, true, 0,
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Move, Position: f32.Pt(150, 50)},
+	)
+	frame("key", false, false, true, 1,
+		key.Event{Name: key.NameReturn, State: key.Press},
+		key.Event{Name: key.NameReturn, State: key.Release},
+	)
+	// A press elsewhere doesn't click.
+	frame("press outside", false, false, true, 0,
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: f32.Pt(150, 50)},
+	)
+	// Remove focus.
+	key.FocusOp{}.Add(gtx.Ops)
+	r.Frame(gtx.Ops)
+	frame("unfocus", false, false, false, 0)
+}
+
+func TestClickableUpdate(t *testing.T) {
+	var (
+		ops op.Ops
+		r   router.Router
+		b   widget.Clickable
+	)
+	gtx := layout.NewContext(&ops, system.FrameEvent{Queue: &r})
+	frame := func() {
+		ops.Reset()
+		b.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{Size: image.Pt(100, 100)}
+		})
+		r.Frame(gtx.Ops)
+	}
+	frame()
+	r.Queue(
+		pointer.Event{Source: pointer.Touch, Type: pointer.Press, Position: f32.Pt(50, 50)},
+		pointer.Event{Source: pointer.Touch, Type: pointer.Release, Position: f32.Pt(50, 50)},
+	)
+	// Updating before and during Layout must not flush the click.
+	b.Update(gtx)
+	frame()
+	if !b.Clicked() {
+		t.Error("click flushed by Update before Layout")
+	}
+}
@@ -0,0 +1,456 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+)
+
+// Command describes an application action that can be shared between
+// menus, toolbars, keyboard shortcuts and a CommandPalette.
+type Command struct {
+	// ID uniquely identifies the command.
+	ID string
+	// Title is the human readable name of the command.
+	Title string
+	// Category groups related commands, such as "File" or "Edit".
+	Category string
+	// Shortcut is the key combination that runs the command, if any.
+	Shortcut Shortcut
+	// Enabled reports whether the command can run. A nil Enabled
+	// means always enabled.
+	Enabled func() bool
+	// Do runs the command.
+	Do func()
+}
+
+// Shortcut is a key combination.
+type Shortcut struct {
+	Modifiers key.Modifiers
+	// Name of the key, in the format of key.Event.Name.
+	Name string
+}
+
+// Commands is a registry of Commands.
+type Commands struct {
+	cmds []Command
+}
+
+// CommandPalette holds the state for an overlay for searching and
+// running commands.
+type CommandPalette struct {
+	// Commands is the registry of the palette.
+	Commands *Commands
+	// Shortcut opens the palette when passed to Dispatch.
+	Shortcut Shortcut
+	// List is the list of results.
+	List layout.List
+
+	visible      bool
+	requestFocus bool
+	focused      bool
+	query        string
+	selected     int
+	results      []Command
+	clicks       []Clickable
+	keyTag       struct{}
+}
+
+// IsZero reports whether s is the empty shortcut.
+func (s Shortcut) IsZero() bool {
+	return s == Shortcut{}
+}
+
+// Matches reports whether e is a press of the shortcut.
+func (s Shortcut) Matches(e key.Event) bool {
+	return !s.IsZero() && e.State == key.Press && e.Name == s.Name && e.Modifiers == s.Modifiers
+}
+
+func (s Shortcut) String() string {
+	if s.IsZero() {
+		return ""
+	}
+	if s.Modifiers == 0 {
+		return s.Name
+	}
+	return strings.ReplaceAll(s.Modifiers.String(), "|", "+") + "+" + s.Name
+}
+
+// IsEnabled reports whether the command can run.
+func (c Command) IsEnabled() bool {
+	return c.Do != nil && (c.Enabled == nil || c.Enabled())
+}
+
+// Register adds a command to the registry. It is an error to register a
+// command with an empty ID, an ID already registered, or a shortcut bound
+// to another command.
+func (c *Commands) Register(cmd Command) error {
+	if cmd.ID == "" {
+		return fmt.Errorf("widget: command %q has no ID", cmd.Title)
+	}
+	for _, c2 := range c.cmds {
+		if c2.ID == cmd.ID {
+			return fmt.Errorf("widget: duplicate command ID %q", cmd.ID)
+		}
+		if !cmd.Shortcut.IsZero() && c2.Shortcut == cmd.Shortcut {
+			return fmt.Errorf("widget: shortcut %v of command %q is bound to command %q", cmd.Shortcut, cmd.ID, c2.ID)
+		}
+	}
+	c.cmds = append(c.cmds, cmd)
+	return nil
+}
+
+// Unregister removes the command with the ID from the registry.
+func (c *Commands) Unregister(id string) {
+	for i, cmd := range c.cmds {
+		if cmd.ID == id {
+			c.cmds = append(c.cmds[:i], c.cmds[i+1:]...)
+			return
+		}
+	}
+}
+
+// Lookup returns the command with the ID.
+func (c *Commands) Lookup(id string) (Command, bool) {
+	for _, cmd := range c.cmds {
+		if cmd.ID == id {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// All returns the registered commands in registration order.
+func (c *Commands) All() []Command {
+	return c.cmds
+}
+
+// Run the command with the ID and report whether it was run. Disabled
+// commands are not run.
+func (c *Commands) Run(id string) bool {
+	cmd, ok := c.Lookup(id)
+	if !ok || !cmd.IsEnabled() {
+		return false
+	}
+	cmd.Do()
+	return true
+}
+
+// Dispatch runs the enabled command whose shortcut matches e, and reports
+// whether a command was run. The shortcuts of disabled commands don't
+// match, so a shortcut bound to a command that only applies in some states
+// stays registered, and its events fall through to the program's other
+// handlers, such as the focused widget, while the command is disabled.
+func (c *Commands) Dispatch(e key.Event) bool {
+	for _, cmd := range c.cmds {
+		if cmd.Shortcut.Matches(e) && cmd.IsEnabled() {
+			cmd.Do()
+			return true
+		}
+	}
+	return false
+}
+
+// Filter appends to results the enabled commands whose titles match query,
+// ranked by how well they match, and returns the result. Prefix matches rank
+// above matches at word boundaries, which rank above other matches. Equal
+// matches are ordered by title length. An empty query matches every enabled
+// command in registration order.
+func (c *Commands) Filter(results []Command, query string) []Command {
+	type match struct {
+		cmd   Command
+		score int
+	}
+	if strings.TrimSpace(query) == "" {
+		for _, cmd := range c.cmds {
+			if cmd.IsEnabled() {
+				results = append(results, cmd)
+			}
+		}
+		return results
+	}
+	var matches []match
+	for _, cmd := range c.cmds {
+		if !cmd.IsEnabled() {
+			continue
+		}
+		if score, ok := fuzzyMatch(query, cmd.Title); ok {
+			matches = append(matches, match{cmd: cmd, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		mi, mj := matches[i], matches[j]
+		if mi.score != mj.score {
+			return mi.score > mj.score
+		}
+		// Prefer shorter titles for equal scores.
+		return len(mi.cmd.Title) < len(mj.cmd.Title)
+	})
+	for _, m := range matches {
+		results = append(results, m.cmd)
+	}
+	return results
+}
+
+// fuzzyMatch reports whether the runes of query appear in order in text,
+// ignoring case and spaces in query. The score is higher for prefix matches,
+// word boundary matches and consecutive matches.
+func fuzzyMatch(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	if len(q) == 0 {
+		return 0, true
+	}
+	t := []rune(text)
+	score := 0
+	last := -1
+	for i, r := range q {
+		idx := -1
+		// Prefer a consecutive match, then a match at a word boundary,
+		// then the earliest match.
+		if n := last + 1; n < len(t) && unicode.ToLower(t[n]) == r {
+			idx = n
+		} else {
+			for j := last + 1; j < len(t); j++ {
+				if unicode.ToLower(t[j]) != r {
+					continue
+				}
+				if idx == -1 {
+					idx = j
+				}
+				if isWordStart(t, j) {
+					idx = j
+					break
+				}
+			}
+		}
+		if idx == -1 {
+			return 0, false
+		}
+		score++
+		switch {
+		case idx == last+1 && i > 0:
+			score += 3
+		case idx > last+1 && last >= 0:
+			// Penalize gaps.
+			score--
+		}
+		if isWordStart(t, idx) {
+			score += 5
+		}
+		last = idx
+	}
+	if strings.HasPrefix(strings.ToLower(text), strings.ToLower(query)) {
+		score += 100
+	}
+	return score, true
+}
+
+// isWordStart reports whether t[i] is the first rune of a word.
+func isWordStart(t []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := t[i-1], t[i]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// Dispatch opens the palette if e matches its Shortcut, or runs the command
+// matching e. It reports whether the event was handled.
+func (p *CommandPalette) Dispatch(e key.Event) bool {
+	if p.Shortcut.Matches(e) {
+		p.Open()
+		return true
+	}
+	if p.Commands == nil {
+		return false
+	}
+	return p.Commands.Dispatch(e)
+}
+
+// Open the palette with an empty query.
+func (p *CommandPalette) Open() {
+	p.visible = true
+	p.requestFocus = true
+	p.SetQuery("")
+}
+
+// Close the palette.
+func (p *CommandPalette) Close() {
+	p.visible = false
+}
+
+// Visible reports whether the palette is open.
+func (p *CommandPalette) Visible() bool {
+	return p.visible
+}
+
+// Focused reports whether the palette has the keyboard focus.
+func (p *CommandPalette) Focused() bool {
+	return p.focused
+}
+
+// Query returns the current filter text.
+func (p *CommandPalette) Query() string {
+	return p.query
+}
+
+// SetQuery replaces the filter text.
+func (p *CommandPalette) SetQuery(q string) {
+	p.query = q
+	p.selected = 0
+	p.List.Position = layout.Position{}
+	p.filter()
+}
+
+// Results returns the commands matching the query, best match first.
+func (p *CommandPalette) Results() []Command {
+	return p.results
+}
+
+// Selected returns the index of the selected result.
+func (p *CommandPalette) Selected() int {
+	return p.selected
+}
+
+// Clickable returns the clickable for the result at index i. It
+// is valid until the next call to Layout.
+func (p *CommandPalette) Clickable(i int) *Clickable {
+	return &p.clicks[i]
+}
+
+func (p *CommandPalette) filter() {
+	p.results = p.results[:0]
+	if p.Commands != nil {
+		p.results = p.Commands.Filter(p.results, p.query)
+	}
+	if n := len(p.results); n > len(p.clicks) {
+		p.clicks = append(p.clicks, make([]Clickable, n-len(p.clicks))...)
+	}
+}
+
+// run the selected command and close the palette.
+func (p *CommandPalette) run(idx int) {
+	if idx < 0 || idx >= len(p.results) {
+		return
+	}
+	cmd := p.results[idx]
+	p.Close()
+	if cmd.IsEnabled() {
+		cmd.Do()
+	}
+}
+
+// Layout processes events and lays out the palette with w. Layout
+// draws nothing if the palette is closed.
+func (p *CommandPalette) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	p.update(gtx)
+	if !p.visible {
+		return layout.Dimensions{}
+	}
+	if p.requestFocus {
+		key.FocusOp{Tag: &p.keyTag}.Add(gtx.Ops)
+		key.SoftKeyboardOp{Show: true}.Add(gtx.Ops)
+		p.requestFocus = false
+	}
+	key.InputOp{Tag: &p.keyTag, Hint: key.HintText}.Add(gtx.Ops)
+	// The caret is always at the end of the query.
+	n := utf8.RuneCountInString(p.query)
+	key.SnippetOp{
+		Tag:     &p.keyTag,
+		Snippet: key.Snippet{Range: key.Range{End: n}, Text: p.query},
+	}.Add(gtx.Ops)
+	key.SelectionOp{Tag: &p.keyTag, Range: key.Range{Start: n, End: n}}.Add(gtx.Ops)
+	return w(gtx)
+}
+
+func (p *CommandPalette) update(gtx layout.Context) {
+	for _, e := range gtx.Events(&p.keyTag) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			p.focused = e.Focus
+		case key.EditEvent:
+			p.edit(e)
+		case key.Event:
+			if !p.visible || e.State != key.Press {
+				break
+			}
+			switch e.Name {
+			case key.NameEscape:
+				p.Close()
+			case key.NameReturn, key.NameEnter:
+				p.run(p.selected)
+			case key.NameUpArrow:
+				if p.selected > 0 {
+					p.selected--
+					p.scrollToSelected()
+				}
+			case key.NameDownArrow:
+				if p.selected < len(p.results)-1 {
+					p.selected++
+					p.scrollToSelected()
+				}
+			case key.NameDeleteBackward:
+				if _, n := utf8.DecodeLastRuneInString(p.query); n > 0 {
+					p.SetQuery(p.query[:len(p.query)-n])
+				}
+			}
+		}
+	}
+	if !p.visible {
+		return
+	}
+	for i := range p.results {
+		if p.clicks[i].Clicked() {
+			p.run(i)
+			return
+		}
+	}
+}
+
+// scrollToSelected scrolls the result list to make the selected
+// result visible.
+func (p *CommandPalette) scrollToSelected() {
+	pos := &p.List.Position
+	switch {
+	case p.selected <= pos.First:
+		pos.First = p.selected
+		pos.Offset = 0
+	case pos.Count > 0 && p.selected >= pos.First+pos.Count-1:
+		// Keep the selected result above the partially visible last
+		// element, but never scroll past it.
+		pos.First = p.selected - pos.Count + 2
+		if pos.First > p.selected {
+			pos.First = p.selected
+		}
+		pos.Offset = 0
+	}
+}
+
+// edit applies an input method edit to the query.
+func (p *CommandPalette) edit(e key.EditEvent) {
+	q := []rune(p.query)
+	start, end := e.Range.Start, e.Range.End
+	if start > end {
+		start, end = end, start
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(q) {
+		end = len(q)
+	}
+	if start > end {
+		start = end
+	}
+	p.SetQuery(string(q[:start]) + e.Text + string(q[end:]))
+}
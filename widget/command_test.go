@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"gioui.org/io/key"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+func TestCommandsRegister(t *testing.T) {
+	var cmds Commands
+	save := Shortcut{Modifiers: key.ModShortcut, Name: "S"}
+	if err := cmds.Register(Command{ID: "save", Title: "Save", Shortcut: save}); err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range []Command{
+		{Title: "No ID"},
+		{ID: "save", Title: "Duplicate ID"},
+		{ID: "store", Title: "Duplicate shortcut", Shortcut: save},
+	} {
+		if err := cmds.Register(cmd); err == nil {
+			t.Errorf("registering %q succeeded", cmd.Title)
+		}
+	}
+	if got := len(cmds.All()); got != 1 {
+		t.Errorf("got %d commands; expected 1", got)
+	}
+}
+
+func TestCommandsEnabled(t *testing.T) {
+	var cmds Commands
+	enabled := false
+	var runs int
+	cmds.Register(Command{
+		ID:       "undo",
+		Title:    "Undo",
+		Shortcut: Shortcut{Modifiers: key.ModShortcut, Name: "Z"},
+		Enabled:  func() bool { return enabled },
+		Do:       func() { runs++ },
+	})
+	undo := key.Event{Name: "Z", Modifiers: key.ModShortcut, State: key.Press}
+	if cmds.Dispatch(undo) || cmds.Run("undo") {
+		t.Error("disabled command ran")
+	}
+	if res := cmds.Filter(nil, ""); len(res) != 0 {
+		t.Errorf("disabled command matched: %v", res)
+	}
+	enabled = true
+	if !cmds.Dispatch(undo) || !cmds.Run("undo") {
+		t.Error("enabled command did not run")
+	}
+	if runs != 2 {
+		t.Errorf("got %d runs; expected 2", runs)
+	}
+	if res := cmds.Filter(nil, "un"); len(res) != 1 {
+		t.Errorf("enabled command did not match")
+	}
+}
+
+func TestCommandsFallThrough(t *testing.T) {
+	var (
+		r        router.Router
+		ops      op.Ops
+		cmds     Commands
+		focus    int
+		selected bool
+		deleted  int
+	)
+	cmds.Register(Command{
+		ID:       "delete",
+		Title:    "Delete Item",
+		Shortcut: Shortcut{Name: key.NameDeleteForward},
+		Enabled:  func() bool { return selected },
+		Do:       func() { deleted++ },
+	})
+	key.InputOp{Tag: &focus}.Add(&ops)
+	key.FocusOp{Tag: &focus}.Add(&ops)
+	r.Frame(&ops)
+	// press delivers a Delete press to the focused handler, which
+	// dispatches the shortcuts before handling the rest, and returns the
+	// events it handled.
+	press := func() []key.Event {
+		r.Queue(key.Event{Name: key.NameDeleteForward, State: key.Press})
+		var handled []key.Event
+		for _, e := range r.Events(&focus) {
+			if e, ok := e.(key.Event); ok && !cmds.Dispatch(e) {
+				handled = append(handled, e)
+			}
+		}
+		return handled
+	}
+	if got := press(); len(got) != 1 || deleted != 0 {
+		t.Errorf("disabled binding: focused handler got %v, %d deletions; expected the event and none", got, deleted)
+	}
+	selected = true
+	if got := press(); len(got) != 0 || deleted != 1 {
+		t.Errorf("enabled binding: focused handler got %v, %d deletions; expected no events and one", got, deleted)
+	}
+}
+
+func TestCommandsFilterRanking(t *testing.T) {
+	var cmds Commands
+	for _, title := range []string{
+		"Toggle Sidebar",
+		"Sort Lines Ascending",
+		"Select All",
+		"Open Settings",
+		"Save As",
+		"Save File",
+	} {
+		cmds.Register(Command{ID: title, Title: title, Do: func() {}})
+	}
+	for _, tc := range []struct {
+		query string
+		exp   []string
+	}{
+		{"sa", []string{"Save As", "Save File", "Select All", "Sort Lines Ascending", "Toggle Sidebar"}},
+		{"set", []string{"Open Settings", "Select All"}},
+		{"save f", []string{"Save File"}},
+		{"SIDE", []string{"Toggle Sidebar"}},
+		{"xyz", nil},
+	} {
+		var got []string
+		for _, cmd := range cmds.Filter(nil, tc.query) {
+			got = append(got, cmd.Title)
+		}
+		if !reflect.DeepEqual(got, tc.exp) {
+			t.Errorf("query %q: got %q; expected %q", tc.query, got, tc.exp)
+		}
+	}
+}
+
+func TestCommandPaletteKeyboard(t *testing.T) {
+	var cmds Commands
+	var ran string
+	for _, title := range []string{"Select All", "Save As", "Save File"} {
+		title := title
+		cmds.Register(Command{ID: title, Title: title, Do: func() { ran = title }})
+	}
+	p := &CommandPalette{
+		Commands: &cmds,
+		Shortcut: Shortcut{Modifiers: key.ModShortcut | key.ModShift, Name: "P"},
+	}
+	var r router.Router
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(100, 100)),
+		Queue:       &r,
+	}
+	frame := func() {
+		gtx.Ops.Reset()
+		p.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{Size: gtx.Constraints.Max}
+		})
+		r.Frame(gtx.Ops)
+	}
+	if !p.Dispatch(key.Event{Name: "P", Modifiers: key.ModShortcut | key.ModShift, State: key.Press}) {
+		t.Fatal("shortcut did not open the palette")
+	}
+	frame()
+	if !p.Visible() {
+		t.Fatal("palette not visible")
+	}
+	r.Queue(
+		key.EditEvent{Text: "s"},
+		key.EditEvent{Range: key.Range{Start: 1, End: 1}, Text: "a"},
+		key.Event{Name: key.NameDownArrow, State: key.Press},
+	)
+	frame()
+	if !p.Focused() {
+		t.Error("palette not focused")
+	}
+	if got, exp := p.Query(), "sa"; got != exp {
+		t.Errorf("got query %q; expected %q", got, exp)
+	}
+	if got, exp := p.Selected(), 1; got != exp {
+		t.Errorf("got selection %d; expected %d", got, exp)
+	}
+	r.Queue(key.Event{Name: key.NameReturn, State: key.Press})
+	frame()
+	if got, exp := ran, "Save File"; got != exp {
+		t.Errorf("ran %q; expected %q", got, exp)
+	}
+	if p.Visible() {
+		t.Error("palette still visible after running a command")
+	}
+
+	// Escape closes without running.
+	ran = ""
+	p.Open()
+	frame()
+	r.Queue(key.Event{Name: key.NameEscape, State: key.Press})
+	frame()
+	if p.Visible() || ran != "" {
+		t.Errorf("escape: visible %v, ran %q", p.Visible(), ran)
+	}
+}
+
+func TestCommandPaletteScroll(t *testing.T) {
+	p := new(CommandPalette)
+	for _, tc := range []struct {
+		first, count, selected int
+		exp                    int
+	}{
+		{0, 4, 1, 0},
+		{0, 4, 3, 1},
+		{2, 4, 1, 1},
+		// A single visible result scrolls to the selected result.
+		{0, 1, 1, 1},
+		{0, 0, 5, 0},
+	} {
+		p.List.Position = layout.Position{First: tc.first, Count: tc.count, Offset: 3}
+		p.selected = tc.selected
+		p.scrollToSelected()
+		if got := p.List.Position.First; got != tc.exp {
+			t.Errorf("first %d, count %d, selected %d: scrolled to %d, expected %d",
+				tc.first, tc.count, tc.selected, got, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text/locale"
+)
+
+// DatePicker holds the state of a calendar for picking a date. The
+// calendar shows a month as six weeks, starting on the first day of the
+// week of a locale.
+type DatePicker struct {
+	// Selected is the picked date, or the zero time if none.
+	Selected time.Time
+
+	// month is the first day of the displayed month.
+	month      time.Time
+	prev, next Clickable
+	days       [datePickerDays]Clickable
+	// grid is the first date of the most recent Layout.
+	grid    time.Time
+	changed bool
+}
+
+// datePickerDays is the number of days in the calendar grid.
+const datePickerDays = 6 * 7
+
+// Changed reports whether Selected was changed by a click since the last
+// call to Changed.
+func (d *DatePicker) Changed() bool {
+	d.processClicks()
+	c := d.changed
+	d.changed = false
+	return c
+}
+
+// Month returns the first day of the displayed month. Before the first
+// Layout, the displayed month defaults to the month of Selected, or the
+// current month.
+func (d *DatePicker) Month() time.Time {
+	return d.month
+}
+
+// SetMonth displays the month of t.
+func (d *DatePicker) SetMonth(t time.Time) {
+	d.month = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// Prev returns the clickable for showing the previous month.
+func (d *DatePicker) Prev() *Clickable {
+	return &d.prev
+}
+
+// Next returns the clickable for showing the next month.
+func (d *DatePicker) Next() *Clickable {
+	return &d.next
+}
+
+// Header returns the title of the displayed month, formatted by f.
+func (d *DatePicker) Header(f *locale.Format) string {
+	return f.FormatMonthYear(d.month)
+}
+
+// Days returns the dates of the calendar grid for the displayed month, in
+// rows of weeks that start on the first day of the week of f.
+func (d *DatePicker) Days(f *locale.Format) [datePickerDays]time.Time {
+	var days [datePickerDays]time.Time
+	first := d.month.AddDate(0, 0, -f.Column(d.month.Weekday()))
+	for i := range days {
+		days[i] = first.AddDate(0, 0, i)
+	}
+	return days
+}
+
+// IsSelected reports whether date is the day of Selected.
+func (d *DatePicker) IsSelected(date time.Time) bool {
+	if d.Selected.IsZero() {
+		return false
+	}
+	y1, m1, d1 := date.Date()
+	y2, m2, d2 := d.Selected.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+// Layout the calendar of the displayed month, with the header above the
+// names of the weekdays and the grid of days, formatted by f. The
+// columns share the maximum width. The header typically lays out Header
+// and the Prev and Next buttons.
+func (d *DatePicker) Layout(gtx layout.Context, f *locale.Format, header layout.Widget, weekday func(gtx layout.Context, wd time.Weekday) layout.Dimensions, day func(gtx layout.Context, date time.Time, click *Clickable) layout.Dimensions) layout.Dimensions {
+	if d.month.IsZero() {
+		t := d.Selected
+		if t.IsZero() {
+			t = gtx.Now
+		}
+		d.SetMonth(t)
+	}
+	d.processClicks()
+	days := d.Days(f)
+	d.grid = days[0]
+
+	cellW := gtx.Constraints.Max.X / 7
+	cgtx := gtx
+	cgtx.Constraints.Min = image.Point{}
+	dims := header(cgtx)
+	y := dims.Size.Y
+	row := func(cell func(gtx layout.Context, col int) layout.Dimensions) {
+		h := 0
+		for col := 0; col < 7; col++ {
+			cgtx.Constraints = layout.Constraints{
+				Min: image.Pt(cellW, 0),
+				Max: image.Pt(cellW, gtx.Constraints.Max.Y),
+			}
+			trans := op.Offset(layout.FPt(image.Pt(col*cellW, y))).Push(gtx.Ops)
+			dims := cell(cgtx, col)
+			trans.Pop()
+			if dims.Size.Y > h {
+				h = dims.Size.Y
+			}
+		}
+		y += h
+	}
+	row(func(gtx layout.Context, col int) layout.Dimensions {
+		return weekday(gtx, f.WeekdayAt(col))
+	})
+	for r := 0; r < datePickerDays/7; r++ {
+		row(func(gtx layout.Context, col int) layout.Dimensions {
+			i := r*7 + col
+			return day(gtx, days[i], &d.days[i])
+		})
+	}
+	w := 7 * cellW
+	if dims.Size.X > w {
+		w = dims.Size.X
+	}
+	return layout.Dimensions{Size: gtx.Constraints.Constrain(image.Pt(w, y))}
+}
+
+// processClicks selects the clicked days of the previous Layout, and
+// changes the displayed month.
+func (d *DatePicker) processClicks() {
+	if !d.grid.IsZero() {
+		for i := range d.days {
+			if !d.days[i].Clicked() {
+				continue
+			}
+			date := d.grid.AddDate(0, 0, i)
+			d.Selected = date
+			d.changed = true
+			if date.Month() != d.month.Month() {
+				d.SetMonth(date)
+			}
+		}
+	}
+	for d.prev.Clicked() {
+		d.month = d.month.AddDate(0, -1, 0)
+	}
+	for d.next.Clicked() {
+		d.month = d.month.AddDate(0, 1, 0)
+	}
+}
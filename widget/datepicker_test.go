@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text/locale"
+)
+
+func TestDatePickerLocale(t *testing.T) {
+	var r router.Router
+	d := new(DatePicker)
+	d.SetMonth(time.Date(2021, time.September, 15, 0, 0, 0, 0, time.UTC))
+	f := locale.Lookup("de-DE")
+	if got, exp := d.Header(f), "September 2021"; got != exp {
+		t.Errorf("header %q; expected %q", got, exp)
+	}
+	d.SetMonth(time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC))
+	if got, exp := d.Header(f), "März 2021"; got != exp {
+		t.Errorf("header %q; expected %q", got, exp)
+	}
+	d.SetMonth(time.Date(2021, time.September, 15, 0, 0, 0, 0, time.UTC))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Constraints{Max: image.Pt(140, 200)},
+		Queue:       &r,
+	}
+	var weekdays []time.Weekday
+	var days []time.Time
+	frame := func() {
+		weekdays, days = weekdays[:0], days[:0]
+		gtx.Ops.Reset()
+		d.Layout(gtx, f,
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Dimensions{Size: image.Pt(140, 20)}
+			},
+			func(gtx layout.Context, wd time.Weekday) layout.Dimensions {
+				weekdays = append(weekdays, wd)
+				return layout.Dimensions{Size: image.Pt(gtx.Constraints.Min.X, 10)}
+			},
+			func(gtx layout.Context, date time.Time, click *Clickable) layout.Dimensions {
+				days = append(days, date)
+				return click.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Dimensions{Size: image.Pt(gtx.Constraints.Min.X, 10)}
+				})
+			},
+		)
+		r.Frame(gtx.Ops)
+	}
+	frame()
+	// Weeks start on Monday in German.
+	expWeek := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+	if len(weekdays) != len(expWeek) {
+		t.Fatalf("got weekdays %v; expected %v", weekdays, expWeek)
+	}
+	for i, wd := range weekdays {
+		if wd != expWeek[i] {
+			t.Fatalf("got weekdays %v; expected %v", weekdays, expWeek)
+		}
+	}
+	if len(days) != 42 {
+		t.Fatalf("laid out %d days; expected 42", len(days))
+	}
+	// September 1, 2021 is a Wednesday.
+	if exp := time.Date(2021, time.August, 30, 0, 0, 0, 0, time.UTC); !days[0].Equal(exp) {
+		t.Errorf("first day %v; expected %v", days[0], exp)
+	}
+	if days[2].Day() != 1 || days[2].Weekday() != time.Wednesday {
+		t.Errorf("third day %v; expected Wednesday, September 1", days[2])
+	}
+	if en := d.Days(locale.Lookup("en-US")); en[0].Weekday() != time.Sunday || en[0].Day() != 29 {
+		t.Errorf("first en-US day %v; expected Sunday, August 29", en[0])
+	}
+
+	// Click the last day of the first week, Sunday September 5.
+	pos := f32.Pt(6*20+5, 20+10+5)
+	r.Queue(
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: pos},
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Release, Position: pos},
+	)
+	frame()
+	if !d.Changed() {
+		t.Fatal("click did not change the selection")
+	}
+	if exp := time.Date(2021, time.September, 5, 0, 0, 0, 0, time.UTC); !d.IsSelected(exp) {
+		t.Errorf("selected %v; expected %v", d.Selected, exp)
+	}
+}
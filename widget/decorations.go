@@ -28,6 +28,7 @@ type Decorations struct {
 // LayoutMove lays out the widget that makes a window movable.
 func (d *Decorations) LayoutMove(gtx layout.Context, w layout.Widget) layout.Dimensions {
 	dims := w(gtx)
+	d.move.Config = gtx.Gesture
 	d.move.Events(gtx.Metric, gtx, gesture.Both)
 	st := clip.Rect{Max: dims.Size}.Push(gtx.Ops)
 	d.move.Add(gtx.Ops)
@@ -87,6 +88,7 @@ func (d *Decorations) LayoutResize(gtx layout.Context, actions system.Action) {
 			continue
 		}
 		rsz := &d.resize[i]
+		rsz.Config = gtx.Gesture
 		rsz.Events(gtx.Metric, gtx, gesture.Both)
 		if rsz.Drag.Dragging() {
 			d.actions |= action
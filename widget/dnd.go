@@ -30,6 +30,7 @@ func (d *Draggable) Layout(gtx layout.Context, w, drag layout.Widget) layout.Dim
 		return w(gtx)
 	}
 	pos := d.pos
+	d.drag.Config = gtx.Gesture
 	for _, ev := range d.drag.Events(gtx.Metric, gtx.Queue, gesture.Both) {
 		switch ev.Type {
 		case pointer.Press:
@@ -5,6 +5,8 @@ package widget
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
+	"errors"
 	"image"
 	"io"
 	"math"
@@ -47,6 +49,16 @@ type Editor struct {
 	Mask rune
 	// InputHint specifies the type of on-screen keyboard to be displayed.
 	InputHint key.InputHint
+	// Zoom, if non-nil, scales the text size by the zoom gesture over the
+	// editor, keeping the text under the pointer in place.
+	Zoom *TextZoom
+	// CaretWidth is the width of the caret, rounded to whole pixels of
+	// at least one. Zero means 1dp, rounded to an even number of at
+	// least 2 pixels.
+	CaretWidth unit.Value
+	// BlockCaret draws the caret as a block covering the rune after
+	// it, for terminal style editors. See PaintCaretText.
+	BlockCaret bool
 
 	eventKey     int
 	font         text.Font
@@ -64,6 +76,10 @@ type Editor struct {
 	dims         layout.Dimensions
 	requestFocus bool
 
+	// paragraphs caches the layouts of the lines of the text, for
+	// re-shaping only the edited paragraphs.
+	paragraphs paragraphCache
+
 	// index tracks combined caret positions at regularly
 	// spaced intervals to speed up caret seeking.
 	index []combinedPos
@@ -98,12 +114,31 @@ type Editor struct {
 
 	clicker gesture.Click
 
+	// touch is the selection interface for touch screens.
+	touch touchSelection
+
+	// changes are the edits of the text since the most recent call to
+	// Changes, excluding remote edits. Edits are recorded once tracking
+	// is set by the first call to Changes.
+	changes  []EditorEdit
+	tracking bool
+
 	// events is the list of events not yet processed.
 	events []EditorEvent
 	// prevEvents is the number of events from the previous frame.
 	prevEvents int
 }
 
+// EditorEdit is a replacement of a range of text in an Editor. An
+// insertion has equal Start and End, and a deletion has empty Text.
+type EditorEdit struct {
+	// Start and End are the rune offsets of the replaced range, in the
+	// text before the edit.
+	Start, End int
+	// Text is the replacement text.
+	Text string
+}
+
 type offEntry struct {
 	runes int
 	bytes int
@@ -262,6 +297,7 @@ func (e *Editor) processPointer(gtx layout.Context) {
 		axis = gesture.Vertical
 		smin, smax = sbounds.Min.Y, sbounds.Max.Y
 	}
+	e.scroller.Config = gtx.Gesture
 	sdist := e.scroller.Scroll(gtx.Metric, gtx, gtx.Now, axis)
 	var soff int
 	if e.SingleLine {
@@ -274,6 +310,27 @@ func (e *Editor) processPointer(gtx layout.Context) {
 	for _, evt := range e.clickDragEvents(gtx) {
 		switch evt := evt.(type) {
 		case gesture.ClickEvent:
+			switch {
+			case evt.Type == gesture.TypePress && evt.Source == pointer.Touch:
+				e.touch.pressed = true
+				e.touch.longPressed = false
+				e.touch.pressAt = gtx.Now
+				e.touch.pressPos = evt.Position
+			case evt.Type == gesture.TypeCancel:
+				e.touch.pressed = false
+			}
+			if evt.Type == gesture.TypeClick && evt.Source == pointer.Touch {
+				e.touch.pressed = false
+				if e.touch.longPressed {
+					// The release of a long press keeps the selection.
+					e.touch.longPressed = false
+					break
+				}
+				e.touch.active = false
+			}
+			if evt.Type == gesture.TypePress && evt.Source == pointer.Mouse {
+				e.touch.active = false
+			}
 			switch {
 			case evt.Type == gesture.TypePress && evt.Source == pointer.Mouse,
 				evt.Type == gesture.TypeClick && evt.Source != pointer.Mouse:
@@ -329,6 +386,8 @@ func (e *Editor) processPointer(gtx layout.Context) {
 		}
 	}
 
+	e.processTouch(gtx)
+
 	if (sdist > 0 && soff >= smax) || (sdist < 0 && soff <= smin) {
 		e.scroller.Stop()
 	}
@@ -336,9 +395,11 @@ func (e *Editor) processPointer(gtx layout.Context) {
 
 func (e *Editor) clickDragEvents(gtx layout.Context) []event.Event {
 	var combinedEvents []event.Event
+	e.clicker.Config = gtx.Gesture
 	for _, evt := range e.clicker.Events(gtx) {
 		combinedEvents = append(combinedEvents, evt)
 	}
+	e.dragger.Config = gtx.Gesture
 	for _, evt := range e.dragger.Events(gtx.Metric, gtx, gesture.Both) {
 		combinedEvents = append(combinedEvents, evt)
 	}
@@ -468,18 +529,13 @@ func (e *Editor) command(gtx layout.Context, k key.Event) bool {
 		if k.Modifiers != key.ModShortcut {
 			return false
 		}
-		clipboard.ReadOp{Tag: &e.eventKey}.Add(gtx.Ops)
+		e.paste(gtx)
 	// Copy or Cut selection -- ignored if nothing selected.
 	case "C", "X":
 		if k.Modifiers != key.ModShortcut {
 			return false
 		}
-		if text := e.SelectedText(); text != "" {
-			clipboard.WriteOp{Text: text}.Add(gtx.Ops)
-			if k.Name == "X" {
-				e.Delete(1)
-			}
-		}
+		e.copySelection(gtx, k.Name == "X")
 	// Select all
 	case "A":
 		if k.Modifiers != key.ModShortcut {
@@ -505,6 +561,13 @@ func (e *Editor) Focused() bool {
 
 // Layout lays out the editor. If content is not nil, it is laid out on top.
 func (e *Editor) Layout(gtx layout.Context, sh text.Shaper, font text.Font, size unit.Value, content layout.Widget) layout.Dimensions {
+	var anchor *zoomAnchor
+	if z := e.Zoom; z != nil {
+		if !gtx.IsMeasuring() && z.update(gtx) && e.shaper != nil {
+			anchor = e.zoomAnchor(z.pos)
+		}
+		size = z.Size(size)
+	}
 	textSize := fixed.I(gtx.Px(size))
 	if e.font != font || e.textSize != textSize {
 		e.invalidate()
@@ -529,15 +592,23 @@ func (e *Editor) Layout(gtx layout.Context, sh text.Shaper, font text.Font, size
 	}
 
 	e.makeValid()
-	e.processEvents(gtx)
-	e.makeValid()
+	if !gtx.IsMeasuring() {
+		e.processEvents(gtx)
+		e.makeValid()
+	}
 
 	if viewSize := gtx.Constraints.Constrain(e.dims.Size); viewSize != e.viewSize {
 		e.viewSize = viewSize
 		e.invalidate()
 	}
 	e.makeValid()
+	if anchor != nil {
+		e.scrollToAnchor(*anchor)
+	}
 
+	if gtx.IsMeasuring() {
+		return layout.Dimensions{Size: e.viewSize, Baseline: e.dims.Baseline}
+	}
 	dims := e.layout(gtx, content)
 
 	if e.focused {
@@ -640,6 +711,9 @@ func (e *Editor) layout(gtx layout.Context, content layout.Widget) layout.Dimens
 
 	e.clicker.Add(gtx.Ops)
 	e.dragger.Add(gtx.Ops)
+	if e.Zoom != nil {
+		e.Zoom.add(gtx.Ops)
+	}
 	e.caret.on = false
 	if e.focused {
 		now := gtx.Now
@@ -746,34 +820,85 @@ func (e *Editor) PaintText(gtx layout.Context) {
 	}
 }
 
+// PaintCaret paints the caret with the current color, shaped by
+// CaretWidth and BlockCaret.
 func (e *Editor) PaintCaret(gtx layout.Context) {
-	if !e.caret.on {
+	if carRect, ok := e.caretRect(gtx); ok {
+		defer clip.Rect(carRect).Push(gtx.Ops).Pop()
+		paint.PaintOp{}.Add(gtx.Ops)
+	}
+}
+
+// PaintCaretText paints the text covered by a BlockCaret with the
+// current color, to contrast with the caret. PaintCaretText does nothing
+// for line carets.
+func (e *Editor) PaintCaretText(gtx layout.Context) {
+	if !e.BlockCaret {
 		return
 	}
-	carWidth2 := gtx.Px(unit.Dp(1)) / 2
-	if carWidth2 < 1 {
-		carWidth2 = 1
+	if carRect, ok := e.caretRect(gtx); ok {
+		defer clip.Rect(carRect).Push(gtx.Ops).Pop()
+		e.PaintText(gtx)
 	}
-	caretPos, carAsc, carDesc := e.caretInfo()
+}
 
+// caretRect returns the visible bounds of the caret, if it is shown.
+func (e *Editor) caretRect(gtx layout.Context) (image.Rectangle, bool) {
+	if !e.caret.on {
+		return image.Rectangle{}, false
+	}
+	caretPos, carAsc, carDesc := e.caretInfo()
+	var left, right int
+	switch {
+	case e.BlockCaret:
+		right, carAsc, carDesc = e.blockCaret()
+	case e.CaretWidth.V == 0:
+		carWidth2 := gtx.Px(unit.Dp(1)) / 2
+		if carWidth2 < 1 {
+			carWidth2 = 1
+		}
+		left, right = carWidth2, carWidth2
+	default:
+		w := gtx.Px(e.CaretWidth)
+		if w < 1 {
+			w = 1
+		}
+		left, right = w/2, w-w/2
+	}
 	carRect := image.Rectangle{
-		Min: caretPos.Sub(image.Pt(carWidth2, carAsc)),
-		Max: caretPos.Add(image.Pt(carWidth2, carDesc)),
+		Min: caretPos.Sub(image.Pt(left, carAsc)),
+		Max: caretPos.Add(image.Pt(right, carDesc)),
 	}
 	cl := textPadding(e.lines)
 	// Account for caret width to each side.
-	if cl.Max.X < carWidth2 {
-		cl.Max.X = carWidth2
+	if cl.Max.X < right {
+		cl.Max.X = right
 	}
-	if cl.Min.X > -carWidth2 {
-		cl.Min.X = -carWidth2
+	if cl.Min.X > -left {
+		cl.Min.X = -left
 	}
 	cl.Max = cl.Max.Add(e.viewSize)
 	carRect = cl.Intersect(carRect)
-	if !carRect.Empty() {
-		defer clip.Rect(carRect).Push(gtx.Ops).Pop()
-		paint.PaintOp{}.Add(gtx.Ops)
+	return carRect, !carRect.Empty()
+}
+
+// blockCaret returns the width of a BlockCaret, and the ascent and
+// descent of its line. Like the selection, the block spans the line
+// height, and it covers the advance of the rune after the caret, or half
+// the line height at the end of a line.
+func (e *Editor) blockCaret() (width, ascent, descent int) {
+	start := e.closestPosition(combinedPos{runes: e.caret.start})
+	line := e.lines[start.lineCol.Y]
+	ascent, descent = line.Ascent.Ceil(), line.Descent.Ceil()
+	next := e.closestPosition(combinedPos{runes: e.caret.start + 1})
+	if next.runes == start.runes || next.lineCol.Y != start.lineCol.Y {
+		return (ascent + descent) / 2, ascent, descent
+	}
+	width = next.x.Round() - start.x.Round()
+	if width < 1 {
+		width = 1
 	}
+	return width, ascent, descent
 }
 
 func (e *Editor) seekFirstVisibleLine(y int) combinedPos {
@@ -823,11 +948,13 @@ func (e *Editor) Text() string {
 
 // SetText replaces the contents of the editor, clearing any selection first.
 func (e *Editor) SetText(s string) {
+	n := utf8.RuneCountInString(e.rr.String())
 	e.rr = editBuffer{}
 	e.caret.start = 0
 	e.caret.end = 0
-	e.replace(e.caret.start, e.caret.end, s)
+	s = e.splice(0, 0, s)
 	e.caret.xoff = 0
+	e.record(EditorEdit{End: n, Text: s})
 }
 
 func (e *Editor) scrollBounds() image.Rectangle {
@@ -846,6 +973,33 @@ func (e *Editor) scrollBounds() image.Rectangle {
 	return b
 }
 
+// zoomAnchor is a text position kept in place when zooming.
+type zoomAnchor struct {
+	// runes is the offset of the position.
+	runes int
+	// pos is the position relative to the editor.
+	pos image.Point
+}
+
+// zoomAnchor returns the anchor of the text position closest to pos,
+// relative to the editor.
+func (e *Editor) zoomAnchor(pos f32.Point) *zoomAnchor {
+	p := e.closestPosition(combinedPos{
+		x: fixed.I(int(math.Round(float64(pos.X))) + e.scrollOff.X),
+		y: int(math.Round(float64(pos.Y))) + e.scrollOff.Y,
+	})
+	return &zoomAnchor{
+		runes: p.runes,
+		pos:   image.Pt(p.x.Round(), p.y).Sub(e.scrollOff),
+	}
+}
+
+// scrollToAnchor scrolls the text position of a to its previous position.
+func (e *Editor) scrollToAnchor(a zoomAnchor) {
+	p := e.closestPosition(combinedPos{runes: a.runes})
+	e.scrollAbs(p.x.Round()-a.pos.X, p.y-a.pos.Y)
+}
+
 func (e *Editor) scrollRel(dx, dy int) {
 	e.scrollAbs(e.scrollOff.X+dx, e.scrollOff.Y+dy)
 }
@@ -884,7 +1038,13 @@ func (e *Editor) layoutText(s text.Shaper) ([]text.Line, layout.Dimensions) {
 	}
 	var lines []text.Line
 	if s != nil {
-		lines, _ = s.Layout(e.font, e.textSize, e.maxWidth, r)
+		txt := e.rr.String()
+		if e.Mask != 0 {
+			var masked strings.Builder
+			io.Copy(&masked, r)
+			txt = masked.String()
+		}
+		lines = e.paragraphs.layout(s, e.font, e.textSize, e.maxWidth, txt)
 	} else {
 		lines, _ = nullLayout(r)
 	}
@@ -901,8 +1061,25 @@ func (e *Editor) CaretPos() (line, col int) {
 // CaretCoords returns the coordinates of the caret, relative to the
 // editor itself.
 func (e *Editor) CaretCoords() f32.Point {
-	caret := e.closestPosition(combinedPos{runes: e.caret.start})
-	return f32.Pt(float32(caret.x)/64-float32(e.scrollOff.X), float32(caret.y-e.scrollOff.Y))
+	return e.OffsetCoords(e.caret.start)
+}
+
+// OffsetAt returns the rune offset closest to pos, relative to the
+// editor itself. OffsetAt is suitable for mapping the positions of
+// gesture.SelectionHandles to offsets.
+func (e *Editor) OffsetAt(pos f32.Point) int {
+	p := e.closestPosition(combinedPos{
+		x: fixed.I(int(math.Round(float64(pos.X))) + e.scrollOff.X),
+		y: int(math.Round(float64(pos.Y))) + e.scrollOff.Y,
+	})
+	return p.runes
+}
+
+// OffsetCoords returns the coordinates of the caret position at the rune
+// offset, relative to the editor itself.
+func (e *Editor) OffsetCoords(offset int) f32.Point {
+	p := e.closestPosition(combinedPos{runes: offset})
+	return f32.Pt(float32(p.x)/64-float32(e.scrollOff.X), float32(p.y-e.scrollOff.Y))
 }
 
 // indexPosition returns the latest position from the index no later than pos.
@@ -1092,24 +1269,38 @@ func (e *Editor) append(s string) {
 
 // replace the text between start and end with s. Indices are in runes.
 func (e *Editor) replace(start, end int, s string) {
-	if e.SingleLine {
-		s = strings.ReplaceAll(s, "\n", " ")
-	}
 	if start > end {
 		start, end = end, start
 	}
-	startPos := e.closestPosition(combinedPos{runes: start})
-	endPos := e.closestPosition(combinedPos{runes: end})
-	startOff := e.runeOffset(startPos.runes)
-	e.rr.deleteRunes(startOff, endPos.runes-startPos.runes)
+	start = e.closestPosition(combinedPos{runes: start}).runes
+	end = e.closestPosition(combinedPos{runes: end}).runes
+	s = e.splice(start, end, s)
+	e.record(EditorEdit{Start: start, End: end, Text: s})
+}
+
+// record a local edit for Changes.
+func (e *Editor) record(ed EditorEdit) {
+	if e.tracking && (ed.Start != ed.End || ed.Text != "") {
+		e.changes = append(e.changes, ed)
+	}
+}
+
+// splice replaces the text between the valid rune indices start and end
+// with s, adjusts the caret, and returns the inserted text.
+func (e *Editor) splice(start, end int, s string) string {
+	if e.SingleLine {
+		s = strings.ReplaceAll(s, "\n", " ")
+	}
+	startOff := e.runeOffset(start)
+	e.rr.deleteRunes(startOff, end-start)
 	e.rr.prepend(startOff, s)
-	newEnd := startPos.runes + utf8.RuneCountInString(s)
+	newEnd := start + utf8.RuneCountInString(s)
 	adjust := func(pos int) int {
 		switch {
-		case newEnd < pos && pos <= endPos.runes:
+		case newEnd < pos && pos <= end:
 			pos = newEnd
-		case endPos.runes < pos:
-			diff := newEnd - endPos.runes
+		case end < pos:
+			diff := newEnd - end
 			pos = pos + diff
 		}
 		return pos
@@ -1119,6 +1310,113 @@ func (e *Editor) replace(start, end int, s string) {
 	e.ime.start = adjust(e.ime.start)
 	e.ime.end = adjust(e.ime.end)
 	e.invalidate()
+	return s
+}
+
+// Changes returns the edits of the text since the previous call to
+// Changes, in the order they were applied. Remote edits applied by
+// ApplyRemote are excluded, and the positions of the returned edits
+// include them. Edits are recorded only after the first call to Changes,
+// which returns nil.
+func (e *Editor) Changes() []EditorEdit {
+	e.tracking = true
+	c := e.changes
+	e.changes = nil
+	return c
+}
+
+// ApplyRemote applies edits from an external source, such as a
+// collaborator or a language server, and keeps the caret and selection
+// on the same text. The edits are applied in order, and their positions
+// are relative to the text as of the most recent call to Changes; they
+// are moved past the local edits not yet returned by Changes, and those
+// local edits are moved past them in turn. Where a local and a remote
+// edit start at the same position, the local text is placed after the
+// remote text. Text inserted inside a range replaced by the other side
+// is replaced along with the range.
+//
+// ApplyRemote uses simple position transformation, so the result of
+// overlapping edits may differ from what either author intended, but
+// both sides arrive at the same text.
+func (e *Editor) ApplyRemote(edits []EditorEdit) {
+	n := utf8.RuneCountInString(e.rr.String())
+	for _, r := range edits {
+		if r.Start > r.End {
+			r.Start, r.End = r.End, r.Start
+		}
+		for i, l := range e.changes {
+			e.changes[i] = l.moved(r, true)
+			r = r.moved(l, false)
+		}
+		r.Start = clampInt(r.Start, 0, n)
+		r.End = clampInt(r.End, r.Start, n)
+		r.Text = e.splice(r.Start, r.End, r.Text)
+		n += utf8.RuneCountInString(r.Text) - (r.End - r.Start)
+	}
+}
+
+// moved returns e with its positions moved across the edit o applied
+// before it. An edit that contains the range of o replaces the text of
+// o too, and an edit inside the range of o loses its text. Otherwise, if
+// after is set, an edit that starts where o starts is placed after the
+// text of o.
+func (e EditorEdit) moved(o EditorEdit, after bool) EditorEdit {
+	switch {
+	case e.Start == o.Start && e.End == o.End:
+		// Place the text of e before or after the text of o.
+		e.Start = o.movePos(e.Start, after)
+		e.End = e.Start
+	case e.contains(o):
+		if e.Start > o.Start {
+			e.Start = o.movePos(e.Start, false)
+		}
+		e.End = o.movePos(e.End, true)
+	case o.contains(e):
+		e.Start = o.movePos(e.Start, after)
+		e.End = e.Start
+		e.Text = ""
+	default:
+		// Edits that overlap o keep their text on the side of o they
+		// start. Of an insertion and a range at the same position, the
+		// insertion goes first.
+		switch ins := o.Start == o.End; {
+		case e.Start > o.Start:
+			after = true
+		case e.Start == o.Start && ins != (e.Start == e.End):
+			after = ins
+		}
+		e.Start = o.movePos(e.Start, after)
+		e.End = o.movePos(e.End, false)
+		if e.End < e.Start {
+			e.End = e.Start
+		}
+	}
+	return e
+}
+
+// contains reports whether the range of e contains the distinct range
+// of o. An insertion is contained only by ranges around it.
+func (e EditorEdit) contains(o EditorEdit) bool {
+	if o.Start == o.End {
+		return e.Start < o.Start && o.End < e.End
+	}
+	return e.Start <= o.Start && o.End <= e.End
+}
+
+// movePos maps the position pos in the text before e to the text after
+// it. Positions at the start of or inside the replaced range map to the
+// start of the replacement, or its end if after is set.
+func (e EditorEdit) movePos(pos int, after bool) int {
+	switch {
+	case pos < e.Start:
+		return pos
+	case pos > e.End, pos == e.End && e.Start < e.End:
+		return pos + utf8.RuneCountInString(e.Text) - (e.End - e.Start)
+	case after:
+		return e.Start + utf8.RuneCountInString(e.Text)
+	default:
+		return e.Start
+	}
 }
 
 func (e *Editor) movePages(pages int, selAct selectionAction) {
@@ -1307,6 +1605,89 @@ func (e *Editor) SetCaret(start, end int) {
 	e.scroller.Stop()
 }
 
+// editorStateVersion is the version of the Editor state encoding.
+const editorStateVersion = 1
+
+var errEditorState = errors.New("widget: invalid Editor state")
+
+// MarshalBinary encodes the text, caret, selection and scroll position
+// of the editor, for restoring them with UnmarshalBinary. The first byte
+// of the encoding is a version.
+func (e *Editor) MarshalBinary() ([]byte, error) {
+	return e.marshal(true), nil
+}
+
+// MarshalCaret is like MarshalBinary but omits the text, for editors
+// whose text is saved elsewhere.
+func (e *Editor) MarshalCaret() ([]byte, error) {
+	return e.marshal(false), nil
+}
+
+func (e *Editor) marshal(withText bool) []byte {
+	buf := make([]byte, 2+4*binary.MaxVarintLen64)
+	buf[0] = editorStateVersion
+	n := 2
+	for _, v := range [...]int{e.caret.start, e.caret.end, e.scrollOff.X, e.scrollOff.Y} {
+		n += binary.PutVarint(buf[n:], int64(v))
+	}
+	buf = buf[:n]
+	if withText {
+		buf[1] = 1
+		buf = append(buf, e.Text()...)
+	}
+	return buf
+}
+
+// UnmarshalBinary restores a state encoded by MarshalBinary or
+// MarshalCaret, and leaves the editor unchanged if data is invalid. The
+// caret and selection are clamped to the text, and the next Layout clamps
+// the scroll position to the text layout.
+func (e *Editor) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 || data[0] != editorStateVersion || data[1] > 1 {
+		return errEditorState
+	}
+	var vals [4]int
+	n := 2
+	for i := range vals {
+		v, m := binary.Varint(data[n:])
+		if m <= 0 {
+			return errEditorState
+		}
+		// Clamp the caret to the text, and the scroll offset to
+		// plausible values.
+		lo, hi := int64(0), int64(math.MaxInt32)
+		if i >= 2 {
+			lo, hi = -inf, inf
+		}
+		if v < lo {
+			v = lo
+		}
+		if v > hi {
+			v = hi
+		}
+		vals[i] = int(v)
+		n += m
+	}
+	txt := data[n:]
+	withText := data[1] == 1
+	if withText && !utf8.Valid(txt) || !withText && len(txt) > 0 {
+		return errEditorState
+	}
+	if withText {
+		e.SetText(string(txt))
+	}
+	// Clamp against the text itself rather than its layout, which may
+	// not reflect the restored text until the next Layout.
+	runes := utf8.RuneCountInString(e.rr.String())
+	e.caret.start = min(vals[0], runes)
+	e.caret.end = min(vals[1], runes)
+	e.caret.xoff = 0
+	e.scroller.Stop()
+	// The next layout clamps the offset to the text.
+	e.scrollOff = image.Pt(vals[2], vals[3])
+	return nil
+}
+
 // SelectedText returns the currently selected text (if any) from the editor.
 func (e *Editor) SelectedText() string {
 	startOff := e.runeOffset(e.caret.start)
@@ -1365,6 +1746,10 @@ func min(a, b int) int {
 	return b
 }
 
+func clampInt(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
 func abs(n int) int {
 	if n < 0 {
 		return -n
@@ -12,14 +12,18 @@ import (
 	"strings"
 	"testing"
 	"testing/quick"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
 	"gioui.org/f32"
 	"gioui.org/font/gofont"
+	"gioui.org/gesture"
+	"gioui.org/internal/ops"
 	"gioui.org/io/event"
 	"gioui.org/io/key"
 	"gioui.org/io/pointer"
+	"gioui.org/io/router"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/text"
@@ -65,6 +69,40 @@ func TestEditorConfigurations(t *testing.T) {
 	}
 }
 
+func TestEditorMeasuring(t *testing.T) {
+	cache := text.NewCache(gofont.Collection())
+	fontSize := unit.Px(10)
+	font := text.Font{}
+	var r router.Router
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Constraints{Max: image.Pt(100, 100)},
+		Queue:       &r,
+	}
+	e := new(Editor)
+	e.SetText("æbc\naøå•")
+	e.Focus()
+	want := e.Layout(gtx, cache, font, fontSize, nil)
+
+	gtx.Ops.Reset()
+	m := new(Editor)
+	m.SetText("æbc\naøå•")
+	m.Focus()
+	got := m.Layout(gtx.Measuring(), cache, font, fontSize, nil)
+	if got != want {
+		t.Errorf("measured %+v, laid out %+v", got, want)
+	}
+	var rd ops.Reader
+	rd.Reset(&gtx.Ops.Internal)
+	if encOp, ok := rd.Decode(); ok {
+		t.Errorf("measuring recorded op %v", ops.OpType(encOp.Data[0]))
+	}
+	r.Frame(gtx.Ops)
+	if n := len(r.FocusableBounds()); n != 0 {
+		t.Errorf("measuring registered %d focusable handlers", n)
+	}
+}
+
 func TestEditor(t *testing.T) {
 	e := new(Editor)
 	gtx := layout.Context{
@@ -187,6 +225,112 @@ const (
 	moveLast // Mark end; never generated.
 )
 
+func TestEditorOffsetAt(t *testing.T) {
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(100, 100)),
+	}
+	cache := text.NewCache(gofont.Collection())
+	e := new(Editor)
+	e.SetText("æbc\naøå•\nxyz")
+	e.Layout(gtx, cache, text.Font{}, unit.Px(10), nil)
+	for off := 0; off <= e.Len(); off++ {
+		// Positions right above the baseline map to the offset.
+		pos := e.OffsetCoords(off).Sub(f32.Pt(0, 2))
+		if got := e.OffsetAt(pos); got != off {
+			t.Errorf("OffsetAt(OffsetCoords(%d)) = %d", off, got)
+		}
+	}
+}
+
+func TestEditorTouchSelection(t *testing.T) {
+	cache := text.NewCache(gofont.Collection())
+	var r router.Router
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(200, 100)),
+		Queue:       &r,
+	}
+	e := new(Editor)
+	e.SetText("hello world\nfoo bar")
+	start := time.Unix(0, 0)
+	frame := func(t time.Duration) {
+		gtx.Ops.Reset()
+		gtx.Now = start.Add(t)
+		e.Layout(gtx, cache, text.Font{}, unit.Px(10), nil)
+		e.LayoutHandles(gtx, func(gtx layout.Context, h gesture.Handle) layout.Dimensions {
+			return layout.Dimensions{Size: image.Pt(10, 10)}
+		})
+		r.Frame(gtx.Ops)
+	}
+	frame(0)
+
+	// Mouse presses don't select.
+	world := e.OffsetCoords(8).Sub(f32.Pt(0, 2))
+	r.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: world})
+	frame(0)
+	frame(time.Second)
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: world})
+	frame(time.Second)
+	if e.TouchSelecting() || e.SelectionLen() != 0 {
+		t.Fatalf("mouse press selected %q", e.SelectedText())
+	}
+
+	// A long press selects the word.
+	r.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: world})
+	frame(2 * time.Second)
+	if e.TouchSelecting() {
+		t.Error("touch press selected before the long press")
+	}
+	frame(2*time.Second + editorLongPress)
+	if !e.TouchSelecting() || e.SelectedText() != "world" {
+		t.Fatalf("long press selected %q, expected \"world\"", e.SelectedText())
+	}
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: world})
+	frame(3 * time.Second)
+	if !e.TouchSelecting() || e.SelectedText() != "world" {
+		t.Fatalf("release after long press selected %q", e.SelectedText())
+	}
+	line0 := e.touch.rects[gesture.HandleEnd]
+	if exp := int(e.OffsetCoords(11).Y + float32(e.lines[0].Descent)/64 + .5); line0.Min.Y != exp {
+		t.Errorf("end handle at y=%d, expected the bottom of the line at %d", line0.Min.Y, exp)
+	}
+
+	// Drag the end handle to the end of "foo" on the next line, grabbing
+	// it outside its visual bounds.
+	grab := layout.FPt(line0.Max).Add(f32.Pt(2, 2))
+	end := e.OffsetCoords(15).Sub(f32.Pt(0, 2))
+	drag := grab.Add(end.Sub(e.OffsetCoords(11)))
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: grab},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: drag},
+	)
+	frame(4 * time.Second)
+	frame(4 * time.Second)
+	if got := e.SelectedText(); got != "world\nfoo" {
+		t.Errorf("dragged selection to %q, expected \"world\\nfoo\"", got)
+	}
+	if pos, ok := e.Magnifier(); !ok || pos.Y < e.OffsetCoords(11).Y || pos.Y > e.OffsetCoords(15).Y {
+		t.Errorf("magnifier at %v, %v while dragging to the second line", pos, ok)
+	}
+	line1 := e.touch.rects[gesture.HandleEnd]
+	if exp := int(e.OffsetCoords(15).Y + float32(e.lines[1].Descent)/64 + .5); line1.Min.Y != exp {
+		t.Errorf("dragged end handle at y=%d, expected the bottom of the second line at %d", line1.Min.Y, exp)
+	}
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: drag})
+	frame(5 * time.Second)
+	if _, ok := e.Magnifier(); ok {
+		t.Error("magnifier shown after the drag")
+	}
+
+	// Mouse presses dismiss the touch selection.
+	r.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: world})
+	frame(6 * time.Second)
+	if e.TouchSelecting() {
+		t.Error("mouse press kept the touch selection")
+	}
+}
+
 func TestEditorCaretConsistency(t *testing.T) {
 	gtx := layout.Context{
 		Ops:         new(op.Ops),
@@ -505,6 +649,115 @@ func TestEditorNoLayout(t *testing.T) {
 	e.MoveCaret(1, 1)
 }
 
+func TestEditorRemoteEdits(t *testing.T) {
+	var e Editor
+	if c := e.Changes(); c != nil {
+		t.Errorf("got changes %v before tracking", c)
+	}
+	e.SetText("hello world")
+	// remote is the text of the collaborator.
+	remote := applyEdits("", e.Changes())
+	if remote != e.Text() {
+		t.Fatalf("got remote text %q, expected %q", remote, e.Text())
+	}
+	if c := e.Changes(); len(c) != 0 {
+		t.Errorf("changes returned twice: %v", c)
+	}
+	e.SetCaret(5, 5)
+
+	// Type locally while the collaborator inserts before and after
+	// the caret.
+	e.Insert(",")
+	redits := []EditorEdit{{Text: "Hi! "}, {Start: 15, End: 15, Text: "!"}}
+	remote = applyEdits(remote, redits)
+	e.ApplyRemote(redits)
+	if exp := "Hi! hello, world!"; e.Text() != exp {
+		t.Errorf("got text %q, expected %q", e.Text(), exp)
+	}
+	if start, end := e.Selection(); start != 10 || end != 10 {
+		t.Errorf("got caret %d-%d, expected 10", start, end)
+	}
+	remote = applyEdits(remote, e.Changes())
+	if remote != e.Text() {
+		t.Errorf("got remote text %q, expected %q", remote, e.Text())
+	}
+
+	// Concurrent inserts at the same position, and a remote deletion
+	// of the selected text.
+	e.SetCaret(16, 11)
+	e.Insert("there")
+	e.SetCaret(4, 4)
+	e.Insert("Oh, ")
+	redits = []EditorEdit{{Start: 4, End: 4, Text: "So, "}, {Start: 15, End: 20}}
+	remote = applyEdits(remote, redits)
+	e.ApplyRemote(redits)
+	if exp := "Hi! So, Oh, hello, there!"; e.Text() != exp {
+		t.Errorf("got text %q, expected %q", e.Text(), exp)
+	}
+	if start, end := e.Selection(); start != 12 || end != 12 {
+		t.Errorf("got caret %d-%d, expected 12", start, end)
+	}
+	changes := e.Changes()
+	if len(changes) != 2 {
+		t.Errorf("got %d local changes, expected 2", len(changes))
+	}
+	remote = applyEdits(remote, changes)
+	if remote != e.Text() {
+		t.Errorf("got remote text %q, expected %q", remote, e.Text())
+	}
+
+	// The selection follows its text.
+	e.SetCaret(12, 17)
+	e.ApplyRemote([]EditorEdit{{Start: 0, End: 4}})
+	if got := e.SelectedText(); got != "hello" {
+		t.Errorf("got selection %q after a remote edit, expected \"hello\"", got)
+	}
+	if c := e.Changes(); len(c) != 0 {
+		t.Errorf("remote edits returned as changes: %v", c)
+	}
+}
+
+// TestEditorRemoteConvergence checks that concurrent local and remote
+// edits lead to the same text on both sides.
+func TestEditorRemoteConvergence(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	randEdits := func(s string, n int) ([]EditorEdit, string) {
+		var edits []EditorEdit
+		for i := 0; i < n; i++ {
+			l := utf8.RuneCountInString(s)
+			start := rnd.Intn(l + 1)
+			end := start + rnd.Intn(l-start+1)
+			e := EditorEdit{Start: start, End: end, Text: "abc"[:rnd.Intn(4)]}
+			edits = append(edits, e)
+			s = applyEdits(s, []EditorEdit{e})
+		}
+		return edits, s
+	}
+	for i := 0; i < 1000; i++ {
+		var e Editor
+		e.SetText("0123456789")
+		e.Changes()
+		local, _ := randEdits(e.Text(), 1+rnd.Intn(3))
+		redits, remote := randEdits(e.Text(), 1+rnd.Intn(3))
+		for _, l := range local {
+			e.replace(l.Start, l.End, l.Text)
+		}
+		e.ApplyRemote(redits)
+		if remote = applyEdits(remote, e.Changes()); remote != e.Text() {
+			t.Fatalf("local edits %v, remote edits %v: got %q locally, %q remotely", local, redits, e.Text(), remote)
+		}
+	}
+}
+
+// applyEdits applies edits to s, as a collaborator would.
+func applyEdits(s string, edits []EditorEdit) string {
+	for _, e := range edits {
+		r := []rune(s)
+		s = string(r[:e.Start]) + e.Text + string(r[e.End:])
+	}
+	return s
+}
+
 // Generate generates a value of itself, for testing/quick.
 func (editMutation) Generate(rand *rand.Rand, size int) reflect.Value {
 	t := editMutation(rand.Intn(int(moveLast)))
@@ -735,3 +988,85 @@ func sortInts(a, b int) (a2, b2 int) {
 	}
 	return a, b
 }
+
+// TestEditorIncrementalLayout compares the lines of the incremental
+// layout of random edits with a layout of the whole text.
+func TestEditorIncrementalLayout(t *testing.T) {
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(120, 1000)),
+	}
+	cache := text.NewCache(gofont.Collection())
+	fontSize := unit.Px(10)
+	rnd := rand.New(rand.NewSource(42))
+	words := []string{"the", "quick", "brown", "fox", " ", " ", "\n", "\n\n", "jumps-over-the-lazy-dog", "é"}
+	e := new(Editor)
+	e.SetText("the quick brown fox\njumps over\n\nthe lazy dog")
+	e.Layout(gtx, cache, text.Font{}, fontSize, nil)
+	for i := 0; i < 500; i++ {
+		n := e.Len()
+		start := rnd.Intn(n + 1)
+		switch rnd.Intn(3) {
+		case 0, 1:
+			e.SetCaret(start, start)
+			var b strings.Builder
+			for j := rnd.Intn(4); j >= 0; j-- {
+				b.WriteString(words[rnd.Intn(len(words))])
+			}
+			e.Insert(b.String())
+		case 2:
+			end := start + rnd.Intn(20)
+			if end > n {
+				end = n
+			}
+			e.SetCaret(start, end)
+			e.Delete(1)
+		}
+		if i%50 == 0 {
+			// Width changes lay out all paragraphs.
+			gtx.Constraints = layout.Exact(image.Pt(100+rnd.Intn(50), 1000))
+		}
+		e.Layout(gtx, cache, text.Font{}, fontSize, nil)
+		want, _ := cache.Layout(text.Font{}, fixed.I(10), e.maxWidth, strings.NewReader(e.Text()))
+		if !reflect.DeepEqual(e.lines, want) {
+			t.Fatalf("edit %d: incremental layout of %q differs from a full layout:\n%v\n%v", i, e.Text(), e.lines, want)
+		}
+		if got, exp := e.dims, linesDimens(want); got != exp {
+			t.Fatalf("edit %d: got dimensions %v; expected %v", i, got, exp)
+		}
+	}
+}
+
+func BenchmarkEditorTyping(b *testing.B) {
+	for _, full := range []bool{false, true} {
+		name := "incremental"
+		if full {
+			name = "full"
+		}
+		b.Run(name, func(b *testing.B) {
+			gtx := layout.Context{
+				Ops:         new(op.Ops),
+				Constraints: layout.Exact(image.Pt(400, 600)),
+			}
+			cache := text.NewCache(gofont.Collection())
+			fontSize := unit.Px(10)
+			var doc strings.Builder
+			for i := 0; i < 5000; i++ {
+				fmt.Fprintf(&doc, "line %d: the quick brown fox jumps over the lazy dog\n", i)
+			}
+			e := new(Editor)
+			e.SetText(doc.String())
+			e.SetCaret(e.Len()/2, e.Len()/2)
+			e.Layout(gtx, cache, text.Font{}, fontSize, nil)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if full {
+					e.paragraphs = paragraphCache{}
+				}
+				e.Insert("a")
+				gtx.Ops.Reset()
+				e.Layout(gtx, cache, text.Font{}, fontSize, nil)
+			}
+		})
+	}
+}
@@ -42,6 +42,29 @@ func (e *Enum) index(k string) *enumKey {
 	return nil
 }
 
+// neighbour returns the key before or after k for the arrow key name, in
+// the order of their first layout, or nil.
+func (e *Enum) neighbour(k *enumKey, name string) *enumKey {
+	d := 0
+	switch name {
+	case key.NameLeftArrow, key.NameUpArrow:
+		d = -1
+	case key.NameRightArrow, key.NameDownArrow:
+		d = 1
+	default:
+		return nil
+	}
+	for i, v := range e.keys {
+		if v == k {
+			if j := i + d; j >= 0 && j < len(e.keys) {
+				return e.keys[j]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
 // Changed reports whether Value has changed by user interaction since the last
 // call to Changed.
 func (e *Enum) Changed() bool {
@@ -60,7 +83,9 @@ func (e *Enum) Focused() (string, bool) {
 	return e.focus, e.focused
 }
 
-// Layout adds the event handler for the key k.
+// Layout adds the event handler for the key k. The arrow keys move the
+// focus and the selection from a focused key to the previous or next key,
+// in the order the keys were first laid out.
 func (e *Enum) Layout(gtx layout.Context, k string, content layout.Widget) layout.Dimensions {
 	m := op.Record(gtx.Ops)
 	dims := content(gtx)
@@ -75,6 +100,7 @@ func (e *Enum) Layout(gtx layout.Context, k string, content layout.Widget) layou
 		e.keys = append(e.keys, state)
 	}
 	clk := &state.click
+	clk.Config = gtx.Gesture
 	for _, ev := range clk.Events(gtx) {
 		switch ev.Type {
 		case gesture.TypePress:
@@ -98,6 +124,16 @@ func (e *Enum) Layout(gtx layout.Context, k string, content layout.Widget) layou
 				e.focused = false
 			}
 		case key.Event:
+			if ev.State == key.Press {
+				if next := e.neighbour(state, ev.Name); next != nil {
+					// Arrows move the selection within the group.
+					key.FocusOp{Tag: &next.tag}.Add(gtx.Ops)
+					if next.key != e.Value {
+						e.Value = next.key
+						e.changed = true
+					}
+				}
+			}
 			if ev.State != key.Release {
 				break
 			}
@@ -33,6 +33,7 @@ func (f *Float) Layout(gtx layout.Context, pointerMargin int, min, max float32)
 	f.length = float32(f.Axis.Convert(size).X)
 
 	var de *pointer.Event
+	f.drag.Config = gtx.Gesture
 	for _, e := range f.drag.Events(gtx.Metric, gtx, gesture.Axis(f.Axis)) {
 		if e.Type == pointer.Press || e.Type == pointer.Drag {
 			de = &e
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"errors"
+)
+
+// Form tracks the validation of a set of fields, and decides when
+// their errors are shown.
+type Form struct {
+	Fields []*Field
+	// Rules validate combinations of fields.
+	Rules []FormRule
+}
+
+// Field binds an input to its validation.
+type Field struct {
+	// Editor is the input of the field. If nil, Value is used.
+	Editor *Editor
+	// Value returns the value of fields without an Editor.
+	Value func() string
+	// Validator checks a value. It returns ErrPending for
+	// checks that complete later through Resolve.
+	Validator func(value string) error
+	// Timing determines when validation errors are shown.
+	Timing ValidationTiming
+
+	value   string
+	checked bool
+	err     error
+	ruleErr error
+	pending bool
+	// shown reports whether the errors are visible.
+	shown   bool
+	focused bool
+}
+
+// FormRule is a validator of several fields.
+type FormRule struct {
+	// Field receives the error from Check.
+	Field *Field
+	Check func() error
+}
+
+// ValidationTiming determines when a Field shows its errors.
+type ValidationTiming uint8
+
+const (
+	// ValidateOnBlur shows errors when the field editor loses
+	// focus, and from then on.
+	ValidateOnBlur ValidationTiming = iota
+	// ValidateOnChange shows errors after the first change.
+	ValidateOnChange
+	// ValidateOnSubmit shows errors after Form.Validate.
+	ValidateOnSubmit
+)
+
+// ErrPending is returned by validators whose result is resolved later
+// by Field.Resolve.
+var ErrPending = errors.New("validation pending")
+
+// Update the fields from their inputs. Update validates every changed
+// value, and shows errors according to the field timings. Call Update
+// once per frame, after the field editors are laid out.
+func (f *Form) Update() {
+	changed := false
+	for _, fd := range f.Fields {
+		if fd.update() {
+			changed = true
+		}
+	}
+	if changed {
+		f.checkRules()
+	}
+}
+
+// Validate shows the errors of every field, typically when the form is
+// submitted, and reports whether the form is valid.
+func (f *Form) Validate() bool {
+	f.Update()
+	for _, fd := range f.Fields {
+		fd.shown = true
+	}
+	return f.Valid()
+}
+
+// Valid reports whether every field and rule passes validation, shown
+// or not. A form with pending fields is not valid. Use Valid to gate the
+// submit button of a form, for example by laying out the button with
+// layout.Context.Disabled.
+func (f *Form) Valid() bool {
+	for _, fd := range f.Fields {
+		if !fd.checked || fd.pending || fd.err != nil || fd.ruleErr != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Form) checkRules() {
+	for _, fd := range f.Fields {
+		fd.ruleErr = nil
+	}
+	for _, r := range f.Rules {
+		if r.Field.ruleErr != nil {
+			continue
+		}
+		r.Field.ruleErr = r.Check()
+	}
+}
+
+// update the field and report whether its value changed.
+func (fd *Field) update() bool {
+	focused := fd.Editor != nil && fd.Editor.Focused()
+	blurred := fd.focused && !focused
+	fd.focused = focused
+	if blurred && fd.Timing == ValidateOnBlur {
+		fd.shown = true
+	}
+	v := fd.current()
+	if fd.checked && v == fd.value {
+		return false
+	}
+	if fd.checked && fd.Timing == ValidateOnChange {
+		fd.shown = true
+	}
+	fd.value = v
+	fd.checked = true
+	fd.pending = false
+	fd.err = nil
+	if fd.Validator != nil {
+		fd.err = fd.Validator(v)
+	}
+	if errors.Is(fd.err, ErrPending) {
+		fd.err = nil
+		fd.pending = true
+	}
+	return true
+}
+
+func (fd *Field) current() string {
+	switch {
+	case fd.Editor != nil:
+		return fd.Editor.Text()
+	case fd.Value != nil:
+		return fd.Value()
+	}
+	return ""
+}
+
+// Resolve the pending validation of value. Resolve does nothing if the
+// field no longer has value, so results of stale checks are ignored.
+func (fd *Field) Resolve(value string, err error) {
+	if !fd.pending || value != fd.value {
+		return
+	}
+	fd.pending = false
+	fd.err = err
+}
+
+// Pending reports whether the validation of the field is pending.
+func (fd *Field) Pending() bool {
+	return fd.pending
+}
+
+// Error returns the validation error to display for the field, or the
+// empty string if there is none or it is not yet shown.
+func (fd *Field) Error() string {
+	if !fd.shown {
+		return ""
+	}
+	switch {
+	case fd.err != nil:
+		return fd.err.Error()
+	case fd.ruleErr != nil:
+		return fd.ruleErr.Error()
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+)
+
+func TestFormPasswordConfirmation(t *testing.T) {
+	var (
+		r                 router.Router
+		password, confirm Editor
+	)
+	short := errors.New("too short")
+	mismatch := errors.New("passwords differ")
+	pwField := &Field{
+		Editor: &password,
+		Validator: func(v string) error {
+			if len(v) < 4 {
+				return short
+			}
+			return nil
+		},
+		Timing: ValidateOnChange,
+	}
+	confirmField := &Field{Editor: &confirm}
+	form := &Form{
+		Fields: []*Field{pwField, confirmField},
+		Rules: []FormRule{{
+			Field: confirmField,
+			Check: func() error {
+				if password.Text() != confirm.Text() {
+					return mismatch
+				}
+				return nil
+			},
+		}},
+	}
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(200, 200)),
+		Queue:       &r,
+	}
+	cache := text.NewCache(gofont.Collection())
+	fnt := gofont.Collection()[0].Font
+	frame := func() {
+		gtx.Ops.Reset()
+		layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return password.Layout(gtx, cache, fnt, unit.Px(10), nil)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return confirm.Layout(gtx, cache, fnt, unit.Px(10), nil)
+			}),
+		)
+		form.Update()
+		r.Frame(gtx.Ops)
+	}
+	check := func(step, pwErr, confirmErr string, valid bool) {
+		t.Helper()
+		if got := pwField.Error(); got != pwErr {
+			t.Errorf("%s: password error %q, expected %q", step, got, pwErr)
+		}
+		if got := confirmField.Error(); got != confirmErr {
+			t.Errorf("%s: confirmation error %q, expected %q", step, got, confirmErr)
+		}
+		if got := form.Valid(); got != valid {
+			t.Errorf("%s: form valid %v, expected %v", step, got, valid)
+		}
+	}
+	frame()
+	check("initial", "", "", false)
+
+	password.Focus()
+	frame()
+	r.Queue(key.EditEvent{Text: "abc"})
+	frame()
+	frame()
+	// The password shows errors on change.
+	check("short password", "too short", "", false)
+
+	confirm.Focus()
+	frame()
+	r.Queue(key.EditEvent{Text: "abcd"})
+	frame()
+	frame()
+	// The confirmation shows errors only after blur.
+	check("confirmation", "too short", "", false)
+	password.Focus()
+	frame()
+	frame()
+	check("blurred confirmation", "too short", "passwords differ", false)
+
+	r.Queue(key.EditEvent{Range: key.Range{Start: 3, End: 3}, Text: "d"})
+	frame()
+	frame()
+	check("matching", "", "", true)
+}
+
+func TestFormSubmit(t *testing.T) {
+	var name string
+	field := &Field{
+		Value: func() string { return name },
+		Validator: func(v string) error {
+			if v == "" {
+				return errors.New("required")
+			}
+			return nil
+		},
+		Timing: ValidateOnSubmit,
+	}
+	avail := &Field{
+		Value:     func() string { return name },
+		Validator: func(v string) error { return ErrPending },
+		Timing:    ValidateOnChange,
+	}
+	form := &Form{Fields: []*Field{field, avail}}
+	form.Update()
+	if form.Valid() || field.Error() != "" {
+		t.Fatal("empty form valid or showing errors before submit")
+	}
+	if form.Validate() || field.Error() != "required" {
+		t.Fatalf("validate didn't show the error, got %q", field.Error())
+	}
+	name = "gopher"
+	form.Update()
+	if field.Error() != "" {
+		t.Errorf("fixed field shows %q", field.Error())
+	}
+	if !avail.Pending() || form.Valid() {
+		t.Fatal("pending field doesn't block the form")
+	}
+	// Stale results are ignored.
+	avail.Resolve("", errors.New("taken"))
+	if !avail.Pending() {
+		t.Error("stale result resolved the field")
+	}
+	avail.Resolve("gopher", nil)
+	if avail.Pending() || !form.Valid() {
+		t.Error("resolved field doesn't validate the form")
+	}
+	name = "root"
+	form.Update()
+	avail.Resolve("root", errors.New("taken"))
+	if got := avail.Error(); got != "taken" || form.Valid() {
+		t.Errorf("resolved error %q not shown", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"sync"
 
 	"gioui.org/internal/f32color"
 	"gioui.org/layout"
@@ -18,12 +19,29 @@ import (
 
 type Icon struct {
 	src []byte
-	// Cached values.
-	op       paint.ImageOp
-	imgSize  int
-	imgColor color.NRGBA
+
+	// mu protects the cache, for icons shared between windows.
+	mu sync.Mutex
+	// cache holds the recently rasterized images, most recently used
+	// last.
+	cache []iconImage
+	// rasterizations counts the rasterized images.
+	rasterizations int
+}
+
+// iconImage is a rasterized icon.
+type iconImage struct {
+	size  int
+	color color.NRGBA
+	// pxPerDp is the scale of the metric the image was rasterized for.
+	pxPerDp float32
+	op      paint.ImageOp
 }
 
+// iconCacheSize is the number of images cached by an Icon; enough for a
+// few colors in a couple of windows of different scales.
+const iconCacheSize = 4
+
 var defaultIconSize = unit.Dp(24)
 
 // NewIcon returns a new Icon from IconVG data.
@@ -42,9 +60,16 @@ func (ic *Icon) Layout(gtx layout.Context, color color.NRGBA) layout.Dimensions
 		sz = gtx.Metric.Px(defaultIconSize)
 	}
 	size := gtx.Constraints.Constrain(image.Pt(sz, sz))
+	if gtx.IsMeasuring() {
+		m, _ := iconvg.DecodeMetadata(ic.src)
+		dx, dy := m.ViewBox.AspectRatio()
+		return layout.Dimensions{
+			Size: image.Point{X: size.X, Y: int(float32(size.X) * dy / dx)},
+		}
+	}
 	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
 
-	ico := ic.image(size.X, color)
+	ico := ic.image(gtx.Metric, size.X, color)
 	ico.Add(gtx.Ops)
 	paint.PaintOp{}.Add(gtx.Ops)
 	return layout.Dimensions{
@@ -52,9 +77,35 @@ func (ic *Icon) Layout(gtx layout.Context, color color.NRGBA) layout.Dimensions
 	}
 }
 
-func (ic *Icon) image(sz int, color color.NRGBA) paint.ImageOp {
-	if sz == ic.imgSize && color == ic.imgColor {
-		return ic.op
+// InvalidateMetric drops the images rasterized for m. Call it with the
+// previous metric of a window when its metric changes, for example when
+// the window moves to a screen of a different density, so the images of
+// the old density don't linger in the cache.
+func (ic *Icon) InvalidateMetric(m unit.Metric) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	n := 0
+	for _, img := range ic.cache {
+		if img.pxPerDp != m.PxPerDp {
+			ic.cache[n] = img
+			n++
+		}
+	}
+	for i := n; i < len(ic.cache); i++ {
+		ic.cache[i] = iconImage{}
+	}
+	ic.cache = ic.cache[:n]
+}
+
+func (ic *Icon) image(metric unit.Metric, sz int, color color.NRGBA) paint.ImageOp {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	for i, img := range ic.cache {
+		if img.size == sz && img.color == color {
+			copy(ic.cache[i:], ic.cache[i+1:])
+			ic.cache[len(ic.cache)-1] = img
+			return img.op
+		}
 	}
 	m, _ := iconvg.DecodeMetadata(ic.src)
 	dx, dy := m.ViewBox.AspectRatio()
@@ -65,8 +116,13 @@ func (ic *Icon) image(sz int, color color.NRGBA) paint.ImageOp {
 	iconvg.Decode(&ico, ic.src, &iconvg.DecodeOptions{
 		Palette: &m.Palette,
 	})
-	ic.op = paint.NewImageOp(img)
-	ic.imgSize = sz
-	ic.imgColor = color
-	return ic.op
+	ic.rasterizations++
+	entry := iconImage{size: sz, color: color, pxPerDp: metric.PxPerDp, op: paint.NewImageOp(img)}
+	if len(ic.cache) == iconCacheSize {
+		// Evict the least recently used image.
+		copy(ic.cache, ic.cache[1:])
+		ic.cache = ic.cache[:len(ic.cache)-1]
+	}
+	ic.cache = append(ic.cache, entry)
+	return entry.op
 }
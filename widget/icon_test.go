@@ -5,10 +5,12 @@ package widget
 import (
 	"image"
 	"image/color"
+	"sync"
 	"testing"
 
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/unit"
 
 	"golang.org/x/exp/shiny/materialdesign/icons"
 )
@@ -29,6 +31,66 @@ func TestIcon_Alpha(t *testing.T) {
 	_ = icon.Layout(gtx, col)
 }
 
+func TestIconScales(t *testing.T) {
+	icon, err := NewIcon(icons.ToggleCheckBox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := color.NRGBA{A: 0xff}
+	layoutAt := func(scale float32) {
+		gtx := layout.Context{
+			Ops:         new(op.Ops),
+			Metric:      unit.Metric{PxPerDp: scale, PxPerSp: scale},
+			Constraints: layout.Constraints{Max: image.Pt(100, 100)},
+		}
+		icon.Layout(gtx, col)
+	}
+	for i := 0; i < 4; i++ {
+		layoutAt(1)
+		layoutAt(2)
+	}
+	if got := icon.rasterizations; got != 2 {
+		t.Errorf("icon rasterized %d times at alternating scales, expected 2", got)
+	}
+	icon.InvalidateMetric(unit.Metric{PxPerDp: 1, PxPerSp: 1})
+	layoutAt(2)
+	if got := icon.rasterizations; got != 2 {
+		t.Errorf("invalidating a metric dropped the images of another")
+	}
+	layoutAt(1)
+	if got := icon.rasterizations; got != 3 {
+		t.Errorf("invalidated image not rasterized again")
+	}
+}
+
+func TestIconConcurrent(t *testing.T) {
+	icon, err := NewIcon(icons.ToggleCheckBox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for w := 1; w <= 2; w++ {
+		wg.Add(1)
+		scale := float32(w)
+		go func() {
+			defer wg.Done()
+			gtx := layout.Context{
+				Ops:         new(op.Ops),
+				Metric:      unit.Metric{PxPerDp: scale, PxPerSp: scale},
+				Constraints: layout.Constraints{Max: image.Pt(100, 100)},
+			}
+			for i := 0; i < 50; i++ {
+				gtx.Ops.Reset()
+				icon.Layout(gtx, color.NRGBA{A: 0xff})
+			}
+		}()
+	}
+	wg.Wait()
+	if got := icon.rasterizations; got != 2 {
+		t.Errorf("icon rasterized %d times by two windows, expected 2", got)
+	}
+}
+
 // TestWidgetConstraints tests that widgets returns dimensions within their constraints.
 func TestWidgetConstraints(t *testing.T) {
 	_cs := func(v ...layout.Constraints) []layout.Constraints { return v }
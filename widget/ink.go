@@ -0,0 +1,386 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/gesture"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// Ink is a canvas for freehand drawing. Strokes are smoothed, and their
+// widths follow the pressure of their points. A pen hovering over the
+// canvas shows the size of the brush, and the eraser end of a pen
+// erases.
+type Ink struct {
+	// Erasing switches the pointer from drawing strokes to erasing
+	// the strokes it touches.
+	Erasing bool
+
+	drag    gesture.Drag
+	strokes []*inkStroke
+	// current is the stroke in progress, if drawing.
+	current []InkPoint
+	drawing bool
+	// erasing is set while the pointer erases.
+	erasing bool
+	// hover is the position of the hovering pen, if hovering.
+	hover    f32.Point
+	hovering bool
+	changed  bool
+	// width is the stroke width in pixels of the cached outlines.
+	width float32
+	// tessellations counts the outlines built for committed strokes.
+	tessellations int
+}
+
+// InkPoint is a sampled point of a stroke.
+type InkPoint struct {
+	Pos f32.Point
+	// Pressure is the pen pressure in the range [0, 1].
+	Pressure float32
+}
+
+// Stroke is the point data of a stroke, suitable for serialization.
+type Stroke struct {
+	Points []InkPoint
+}
+
+type inkStroke struct {
+	Stroke
+	// bounds of the points.
+	bounds f32.Rectangle
+	// ops caches the outline and paint of the stroke.
+	ops  op.Ops
+	call op.CallOp
+	// valid reports whether the cache is up to date.
+	valid bool
+}
+
+// inkSample is a point of a smoothed stroke.
+type inkSample struct {
+	pos    f32.Point
+	radius float32
+}
+
+const (
+	// inkSubdivisions is the number of samples per stroke point.
+	inkSubdivisions = 4
+	// inkMinWidth is the width of a stroke at zero pressure,
+	// relative to the full width.
+	inkMinWidth = 0.2
+)
+
+// Strokes returns a copy of the committed strokes.
+func (k *Ink) Strokes() []Stroke {
+	strokes := make([]Stroke, len(k.strokes))
+	for i, s := range k.strokes {
+		strokes[i] = Stroke{Points: append([]InkPoint(nil), s.Points...)}
+	}
+	return strokes
+}
+
+// SetStrokes replaces the committed strokes, and cancels the stroke in
+// progress.
+func (k *Ink) SetStrokes(strokes []Stroke) {
+	k.strokes = k.strokes[:0]
+	for _, s := range strokes {
+		k.add(Stroke{Points: append([]InkPoint(nil), s.Points...)})
+	}
+	k.drawing = false
+	k.changed = true
+}
+
+// Undo removes the most recently committed stroke, and reports whether
+// there was one.
+func (k *Ink) Undo() bool {
+	n := len(k.strokes)
+	if n == 0 {
+		return false
+	}
+	k.strokes[n-1] = nil
+	k.strokes = k.strokes[:n-1]
+	k.changed = true
+	return true
+}
+
+// Changed reports whether the strokes have changed since the last call
+// to Changed.
+func (k *Ink) Changed() bool {
+	c := k.changed
+	k.changed = false
+	return c
+}
+
+// Layout the canvas with the size of the maximum constraints, and paint
+// the strokes with the given width and color. Only the stroke in progress
+// is rebuilt every frame; the outlines of committed strokes are cached.
+//
+// Strokes drawn by the pointer have full pressure, because pointer.Event
+// doesn't report pen pressure.
+func (k *Ink) Layout(gtx layout.Context, width unit.Value, col color.NRGBA) layout.Dimensions {
+	size := gtx.Constraints.Max
+	if gtx.IsMeasuring() {
+		return layout.Dimensions{Size: size}
+	}
+	w := float32(gtx.Px(width))
+	k.processEvents(gtx, w)
+	if w != k.width {
+		k.width = w
+		for _, s := range k.strokes {
+			s.valid = false
+		}
+	}
+
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	pointer.CursorCrosshair.Add(gtx.Ops)
+	k.drag.Add(gtx.Ops)
+	pointer.InputOp{Tag: k, Types: pointer.Move | pointer.Leave}.Add(gtx.Ops)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	for _, s := range k.strokes {
+		if !s.valid {
+			s.ops.Reset()
+			m := op.Record(&s.ops)
+			paintStroke(&s.ops, s.Points, w)
+			s.call = m.Stop()
+			s.valid = true
+			k.tessellations++
+		}
+		s.call.Add(gtx.Ops)
+	}
+	if k.drawing {
+		paintStroke(gtx.Ops, k.current, w)
+	}
+	if k.hovering {
+		// Outline the brush under the pen.
+		r := inkRadius(w, 1)
+		ring := clip.Ellipse{Min: k.hover.Sub(f32.Pt(r, r)), Max: k.hover.Add(f32.Pt(r, r))}
+		paint.FillShape(gtx.Ops, col, clip.Stroke{Path: ring.Path(gtx.Ops), Width: float32(gtx.Px(unit.Dp(1)))}.Op())
+	}
+	return layout.Dimensions{Size: size}
+}
+
+func (k *Ink) processEvents(gtx layout.Context, width float32) {
+	for _, e := range gtx.Events(k) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch {
+		case e.Type == pointer.Move && e.Source == pointer.Pen && e.Buttons == 0:
+			k.hover = e.Position
+			k.hovering = true
+		case e.Type == pointer.Move, e.Type == pointer.Leave, e.Type == pointer.Cancel:
+			k.hovering = false
+		}
+	}
+	k.drag.Config = gtx.Gesture
+	for _, e := range k.drag.Events(gtx.Metric, gtx, gesture.Both) {
+		switch e.Type {
+		case pointer.Press:
+			k.hovering = false
+			// The eraser end of a pen erases.
+			k.erasing = k.Erasing || e.Buttons.Contain(pointer.ButtonEraser)
+			if k.erasing {
+				k.erase(e.Position, width)
+				break
+			}
+			k.current = append(k.current[:0], InkPoint{Pos: e.Position, Pressure: 1})
+			k.drawing = true
+		case pointer.Drag:
+			if k.erasing {
+				k.erase(e.Position, width)
+			}
+			if !k.drawing {
+				break
+			}
+			if last := k.current[len(k.current)-1]; last.Pos != e.Position {
+				k.current = append(k.current, InkPoint{Pos: e.Position, Pressure: 1})
+			}
+		case pointer.Release:
+			k.erasing = false
+			if k.drawing {
+				k.drawing = false
+				k.add(Stroke{Points: append([]InkPoint(nil), k.current...)})
+				k.changed = true
+			}
+		case pointer.Cancel:
+			k.drawing = false
+			k.erasing = false
+		}
+	}
+}
+
+func (k *Ink) add(s Stroke) {
+	st := &inkStroke{Stroke: s}
+	if len(s.Points) > 0 {
+		p := s.Points[0].Pos
+		st.bounds = f32.Rectangle{Min: p, Max: p}
+		for _, pt := range s.Points[1:] {
+			b := &st.bounds
+			b.Min.X = float32(math.Min(float64(b.Min.X), float64(pt.Pos.X)))
+			b.Min.Y = float32(math.Min(float64(b.Min.Y), float64(pt.Pos.Y)))
+			b.Max.X = float32(math.Max(float64(b.Max.X), float64(pt.Pos.X)))
+			b.Max.Y = float32(math.Max(float64(b.Max.Y), float64(pt.Pos.Y)))
+		}
+	}
+	k.strokes = append(k.strokes, st)
+}
+
+// erase the strokes within a stroke width of pos.
+func (k *Ink) erase(pos f32.Point, width float32) {
+	n := 0
+	for _, s := range k.strokes {
+		if s.hit(pos, width) {
+			k.changed = true
+			continue
+		}
+		k.strokes[n] = s
+		n++
+	}
+	for i := n; i < len(k.strokes); i++ {
+		k.strokes[i] = nil
+	}
+	k.strokes = k.strokes[:n]
+}
+
+// hit reports whether pos is within width of the stroke outline.
+func (s *inkStroke) hit(pos f32.Point, width float32) bool {
+	r := width * 1.5
+	b := s.bounds
+	if pos.X < b.Min.X-r || pos.X > b.Max.X+r || pos.Y < b.Min.Y-r || pos.Y > b.Max.Y+r {
+		return false
+	}
+	pts := s.Points
+	for i := range pts {
+		a, b := pts[i], pts[i]
+		if i > 0 {
+			a = pts[i-1]
+		}
+		rad := width + inkRadius(width, math.Max(float64(a.Pressure), float64(b.Pressure)))
+		if segmentDist(pos, a.Pos, b.Pos) <= rad {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentDist returns the distance from p to the line segment from a to b.
+func segmentDist(p, a, b f32.Point) float32 {
+	ab := b.Sub(a)
+	t := float32(0)
+	if l := ab.X*ab.X + ab.Y*ab.Y; l > 0 {
+		ap := p.Sub(a)
+		t = (ap.X*ab.X + ap.Y*ab.Y) / l
+		if t < 0 {
+			t = 0
+		} else if t > 1 {
+			t = 1
+		}
+	}
+	return length(p.Sub(a.Add(ab.Mul(t))))
+}
+
+func inkRadius(width float32, pressure float64) float32 {
+	return width * (inkMinWidth + (1-inkMinWidth)*float32(pressure)) / 2
+}
+
+// paintStroke fills the outline of the smoothed stroke through pts.
+func paintStroke(ops *op.Ops, pts []InkPoint, width float32) {
+	samples := inkSamples(pts, width)
+	if len(samples) == 0 {
+		return
+	}
+	defer clip.Outline{Path: inkOutline(ops, samples)}.Op().Push(ops).Pop()
+	paint.PaintOp{}.Add(ops)
+}
+
+// inkSamples smooths a stroke by sampling the Catmull-Rom spline through
+// its points, with radii interpolated from the point pressures.
+func inkSamples(pts []InkPoint, width float32) []inkSample {
+	n := len(pts)
+	if n == 0 {
+		return nil
+	}
+	samples := make([]inkSample, 0, (n-1)*inkSubdivisions+1)
+	at := func(i int) InkPoint {
+		if i < 0 {
+			i = 0
+		} else if i >= n {
+			i = n - 1
+		}
+		return pts[i]
+	}
+	for i := 0; i < n-1; i++ {
+		p0, p1, p2, p3 := at(i-1).Pos, at(i).Pos, at(i+1).Pos, at(i+2).Pos
+		for j := 0; j < inkSubdivisions; j++ {
+			t := float32(j) / inkSubdivisions
+			t2, t3 := t*t, t*t*t
+			pos := p1.Mul(2).
+				Add(p2.Sub(p0).Mul(t)).
+				Add(p0.Mul(2).Sub(p1.Mul(5)).Add(p2.Mul(4)).Sub(p3).Mul(t2)).
+				Add(p1.Mul(3).Sub(p0).Sub(p2.Mul(3)).Add(p3).Mul(t3)).
+				Mul(.5)
+			pressure := at(i).Pressure + (at(i+1).Pressure-at(i).Pressure)*t
+			samples = append(samples, inkSample{pos: pos, radius: inkRadius(width, float64(pressure))})
+		}
+	}
+	last := pts[n-1]
+	return append(samples, inkSample{pos: last.Pos, radius: inkRadius(width, float64(last.Pressure))})
+}
+
+// inkOutline returns the closed outline of samples, with round caps.
+func inkOutline(ops *op.Ops, samples []inkSample) clip.PathSpec {
+	left, right := inkSides(samples)
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(left[0])
+	for _, pt := range left[1:] {
+		p.LineTo(pt)
+	}
+	end := samples[len(samples)-1].pos
+	p.ArcTo(end, end, -math.Pi)
+	for i := len(right) - 2; i >= 0; i-- {
+		p.LineTo(right[i])
+	}
+	start := samples[0].pos
+	p.ArcTo(start, start, -math.Pi)
+	p.Close()
+	return p.End()
+}
+
+// inkSides returns the points offset to the left and right of samples by
+// their radii.
+func inkSides(samples []inkSample) (left, right []f32.Point) {
+	left = make([]f32.Point, len(samples))
+	right = make([]f32.Point, len(samples))
+	normal := f32.Pt(0, 1)
+	for i, s := range samples {
+		prev, next := s.pos, s.pos
+		if i > 0 {
+			prev = samples[i-1].pos
+		}
+		if i < len(samples)-1 {
+			next = samples[i+1].pos
+		}
+		if d := next.Sub(prev); d != (f32.Point{}) {
+			l := length(d)
+			normal = f32.Pt(-d.Y/l, d.X/l)
+		}
+		left[i] = s.pos.Add(normal.Mul(s.radius))
+		right[i] = s.pos.Sub(normal.Mul(s.radius))
+	}
+	return left, right
+}
+
+func length(p f32.Point) float32 {
+	return float32(math.Hypot(float64(p.X), float64(p.Y)))
+}
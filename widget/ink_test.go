@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+type inkTest struct {
+	r   router.Router
+	gtx layout.Context
+}
+
+func newInkTest() *inkTest {
+	it := new(inkTest)
+	it.gtx = layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(200, 200)),
+		Queue:       &it.r,
+	}
+	return it
+}
+
+func (it *inkTest) frame(k *Ink, events ...pointer.Event) {
+	for _, e := range events {
+		e.Source = pointer.Mouse
+		if e.Type != pointer.Release {
+			e.Buttons = pointer.ButtonPrimary
+		}
+		it.r.Queue(e)
+	}
+	it.gtx.Ops.Reset()
+	k.Layout(it.gtx, unit.Px(10), color.NRGBA{A: 0xff})
+	it.r.Frame(it.gtx.Ops)
+}
+
+func TestInkPressure(t *testing.T) {
+	pts := []InkPoint{
+		{Pos: f32.Pt(0, 0), Pressure: 0},
+		{Pos: f32.Pt(50, 10), Pressure: .5},
+		{Pos: f32.Pt(100, 0), Pressure: 1},
+	}
+	samples := inkSamples(pts, 10)
+	if n, exp := len(samples), 2*inkSubdivisions+1; n != exp {
+		t.Fatalf("got %d samples, expected %d", n, exp)
+	}
+	// The smoothed stroke passes through the points.
+	if got := samples[inkSubdivisions].pos; got != pts[1].Pos {
+		t.Errorf("smoothed stroke at %v; expected %v", got, pts[1].Pos)
+	}
+	left, right := inkSides(samples)
+	width := func(i int) float32 {
+		return length(left[i].Sub(right[i]))
+	}
+	const eps = 1e-3
+	if got, exp := width(0), float32(10*inkMinWidth); math.Abs(float64(got-exp)) > eps {
+		t.Errorf("outline width %v at zero pressure; expected %v", got, exp)
+	}
+	if got, exp := width(len(samples)-1), float32(10); math.Abs(float64(got-exp)) > eps {
+		t.Errorf("outline width %v at full pressure; expected %v", got, exp)
+	}
+	for i := 1; i < len(samples); i++ {
+		if width(i) <= width(i-1) {
+			t.Errorf("outline width doesn't grow with pressure at sample %d", i)
+		}
+	}
+}
+
+func TestInkErase(t *testing.T) {
+	k := new(Ink)
+	k.SetStrokes([]Stroke{
+		{Points: []InkPoint{{Pos: f32.Pt(10, 10), Pressure: 1}, {Pos: f32.Pt(100, 10), Pressure: 1}}},
+		{Points: []InkPoint{{Pos: f32.Pt(10, 100), Pressure: 1}, {Pos: f32.Pt(100, 100), Pressure: 1}}},
+	})
+	k.Changed()
+	it := newInkTest()
+	it.frame(k)
+	k.Erasing = true
+	// Miss both strokes.
+	it.frame(k, pointer.Event{Type: pointer.Press, Position: f32.Pt(50, 50)})
+	it.frame(k, pointer.Event{Type: pointer.Release, Position: f32.Pt(50, 50)})
+	if len(k.Strokes()) != 2 || k.Changed() {
+		t.Fatal("eraser removed strokes out of reach")
+	}
+	// Hit the second stroke within the stroke width.
+	it.frame(k, pointer.Event{Type: pointer.Press, Position: f32.Pt(70, 92)})
+	it.frame(k, pointer.Event{Type: pointer.Release, Position: f32.Pt(70, 92)})
+	strokes := k.Strokes()
+	if len(strokes) != 1 || strokes[0].Points[0].Pos.Y != 10 || !k.Changed() {
+		t.Errorf("eraser didn't remove the hit stroke: %v", strokes)
+	}
+}
+
+func TestInkPen(t *testing.T) {
+	k := new(Ink)
+	k.SetStrokes([]Stroke{
+		{Points: []InkPoint{{Pos: f32.Pt(10, 10), Pressure: 1}, {Pos: f32.Pt(100, 10), Pressure: 1}}},
+	})
+	it := newInkTest()
+	it.frame(k)
+	pen := func(typ pointer.Type, x, y float32, btns pointer.Buttons) {
+		it.r.Queue(pointer.Event{Type: typ, Source: pointer.Pen, Position: f32.Pt(x, y), Buttons: btns})
+		it.gtx.Ops.Reset()
+		k.Layout(it.gtx, unit.Px(10), color.NRGBA{A: 0xff})
+		it.r.Frame(it.gtx.Ops)
+	}
+	pen(pointer.Move, 50, 50, 0)
+	if !k.hovering || k.hover != f32.Pt(50, 50) {
+		t.Errorf("hovering pen at %v not tracked", k.hover)
+	}
+	// The eraser erases even though Erasing is not set.
+	pen(pointer.Press, 50, 12, pointer.ButtonEraser)
+	if k.hovering {
+		t.Error("pen in contact still hovering")
+	}
+	pen(pointer.Release, 50, 12, 0)
+	if len(k.Strokes()) != 0 || k.drawing {
+		t.Errorf("eraser didn't erase the stroke: %v", k.Strokes())
+	}
+	// The tip draws.
+	pen(pointer.Press, 50, 50, pointer.ButtonPrimary)
+	pen(pointer.Move, 80, 50, pointer.ButtonPrimary|pointer.ButtonBarrel)
+	pen(pointer.Release, 80, 50, 0)
+	if got := len(k.Strokes()); got != 1 {
+		t.Errorf("got %d strokes drawn by the pen tip, expected 1", got)
+	}
+	pen(pointer.Move, 90, 90, 0)
+	pen(pointer.Leave, 90, 90, 0)
+	if k.hovering {
+		t.Error("pen out of proximity still hovering")
+	}
+}
+
+func TestInkCache(t *testing.T) {
+	k := new(Ink)
+	it := newInkTest()
+	it.frame(k)
+	draw := func(y float32) {
+		n := k.tessellations
+		it.frame(k, pointer.Event{Type: pointer.Press, Position: f32.Pt(10, y)})
+		for x := float32(20); x <= 100; x += 20 {
+			it.frame(k, pointer.Event{Type: pointer.Move, Position: f32.Pt(x, y)})
+		}
+		if !k.drawing {
+			t.Fatal("no stroke in progress")
+		}
+		if k.tessellations != n {
+			t.Error("stroke in progress re-tessellated committed strokes")
+		}
+		it.frame(k, pointer.Event{Type: pointer.Release, Position: f32.Pt(100, y)})
+	}
+	draw(50)
+	it.frame(k)
+	if got := len(k.Strokes()); got != 1 {
+		t.Fatalf("got %d strokes, expected 1", got)
+	}
+	if got := len(k.Strokes()[0].Points); got != 6 {
+		t.Errorf("got %d stroke points, expected 6", got)
+	}
+	draw(100)
+	for i := 0; i < 3; i++ {
+		it.frame(k)
+	}
+	if k.tessellations != 2 {
+		t.Errorf("committed strokes tessellated %d times; expected 2", k.tessellations)
+	}
+	if !k.Undo() || len(k.Strokes()) != 1 {
+		t.Error("undo didn't remove the last stroke")
+	}
+
+	// Many committed strokes are tessellated once.
+	var strokes []Stroke
+	for i := 0; i < 500; i++ {
+		y := float32(i % 200)
+		strokes = append(strokes, Stroke{Points: []InkPoint{
+			{Pos: f32.Pt(0, y), Pressure: .3},
+			{Pos: f32.Pt(100, y+5), Pressure: .6},
+			{Pos: f32.Pt(200, y), Pressure: .9},
+		}})
+	}
+	k.SetStrokes(strokes)
+	k.tessellations = 0
+	for i := 0; i < 3; i++ {
+		it.frame(k)
+	}
+	if k.tessellations != 500 {
+		t.Errorf("500 strokes tessellated %d times; expected 500", k.tessellations)
+	}
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+)
+
+// HitTester is implemented by event queues that can report the pointer
+// handlers under a position, such as router.Router and the queues of
+// app.Window frames.
+type HitTester interface {
+	HitTest(pos f32.Point) []router.Hit
+}
+
+// focusReporter is implemented by event queues that report the focused
+// handler, for restoring it after inspection.
+type focusReporter interface {
+	Focused() event.Tag
+}
+
+// Inspector tracks the pointer handlers and semantic areas under the
+// pointer, for debug overlays. The pointer is inspected while hovering
+// with Modifiers held, or while Inspecting.
+//
+// Inspecting is toggled by the key chord of Key and Modifiers. While
+// Inspecting, the inspected widgets receive no input, the inspector owns
+// the keyboard focus, and the up and down arrows select the enclosing
+// and enclosed hits. Esc stops inspecting, and restores the focus to the
+// handler focused before, if the gtx.Queue reports it.
+//
+// The positions of hits are in window coordinates, so the Inspector must
+// be laid out at the window origin, untransformed. Otherwise the pointer
+// position is not translated to window coordinates and the wrong
+// handlers are inspected.
+type Inspector struct {
+	// Modifiers must be held for inspecting, and are the modifiers of
+	// the toggle chord. If zero, key.ModShortcut and key.ModShift are
+	// used.
+	Modifiers key.Modifiers
+	// Key is the name of the key of the toggle chord. If empty, "I" is
+	// used.
+	Key string
+
+	pos        f32.Point
+	active     bool
+	inspecting bool
+	// focus is set when the focus should move to or from the inspector
+	// in the next Layout.
+	focus bool
+	// prevFocus is the tag focused before inspecting.
+	prevFocus event.Tag
+	hits      []router.Hit
+	selected  int
+}
+
+// Hits returns the hits under the pointer as of the most recent Layout,
+// topmost first. Hits is empty while the pointer is not inspected.
+func (in *Inspector) Hits() []router.Hit {
+	return in.hits
+}
+
+// Selected returns the index into Hits of the selected hit. The topmost
+// hit is selected when the hits change by a pointer move.
+func (in *Inspector) Selected() int {
+	return in.selected
+}
+
+// Position returns the most recent pointer position, relative to the
+// inspector and therefore in window coordinates.
+func (in *Inspector) Position() f32.Point {
+	return in.pos
+}
+
+// Inspecting reports whether inspection is toggled on.
+func (in *Inspector) Inspecting() bool {
+	return in.inspecting
+}
+
+// Toggle inspection on or off.
+func (in *Inspector) Toggle() {
+	in.inspecting = !in.inspecting
+	in.focus = true
+	in.selected = 0
+}
+
+// Intercept toggles inspection if e is a press of the toggle chord, and
+// reports whether e is a key event of the chord. Windows call Intercept with their events
+// before routing them, because the chord must be recognized regardless
+// of the keyboard focus.
+func (in *Inspector) Intercept(e event.Event) bool {
+	ke, ok := e.(key.Event)
+	if !ok {
+		return false
+	}
+	name := in.Key
+	if name == "" {
+		name = "I"
+	}
+	if ke.Name != name || ke.Modifiers != in.modifiers() {
+		return false
+	}
+	if ke.State == key.Press {
+		in.Toggle()
+	}
+	return true
+}
+
+// Layout w, and track the pointer above it. Inspecting requires a
+// gtx.Queue that implements HitTester.
+func (in *Inspector) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	in.update(gtx)
+	dims := w(gtx)
+	if in.inspecting {
+		// Confine the input to the inspector.
+		defer event.ModalOp{}.Push(gtx.Ops).Pop()
+	}
+	// Don't hide the inspected widgets from hit tests.
+	defer pointer.PassOp{}.Push(gtx.Ops).Pop()
+	defer clip.Rect{Max: dims.Size}.Push(gtx.Ops).Pop()
+	pointer.InputOp{
+		Tag:   in,
+		Types: pointer.Press | pointer.Move | pointer.Enter | pointer.Leave,
+	}.Add(gtx.Ops)
+	if in.inspecting {
+		key.InputOp{Tag: in}.Add(gtx.Ops)
+	}
+	if in.focus {
+		in.focus = false
+		if in.inspecting {
+			in.prevFocus = nil
+			if fr, ok := gtx.Queue.(focusReporter); ok {
+				in.prevFocus = fr.Focused()
+			}
+			key.FocusOp{Tag: in}.Add(gtx.Ops)
+		} else {
+			key.FocusOp{Tag: in.prevFocus}.Add(gtx.Ops)
+			in.prevFocus = nil
+		}
+	}
+	return dims
+}
+
+func (in *Inspector) update(gtx layout.Context) {
+	mods := in.modifiers()
+	for _, e := range gtx.Events(in) {
+		switch e := e.(type) {
+		case pointer.Event:
+			switch e.Type {
+			case pointer.Move, pointer.Enter:
+				if e.Position != in.pos {
+					in.selected = 0
+				}
+				in.pos = e.Position
+				in.active = e.Modifiers.Contain(mods)
+			case pointer.Leave, pointer.Cancel:
+				in.active = false
+			}
+		case key.Event:
+			if !in.inspecting || e.State != key.Press {
+				break
+			}
+			switch e.Name {
+			case key.NameEscape:
+				in.Toggle()
+			case key.NameUpArrow:
+				in.selected++
+			case key.NameDownArrow:
+				in.selected--
+			}
+		}
+	}
+	in.hits = in.hits[:0]
+	ht, ok := gtx.Queue.(HitTester)
+	if (!in.active && !in.inspecting) || !ok {
+		in.selected = 0
+		return
+	}
+	// The pointer positions are relative to the inspector, while
+	// HitTest expects window coordinates. They coincide because the
+	// Inspector is laid out at the window origin; see the Inspector
+	// documentation.
+	for _, h := range ht.HitTest(in.pos) {
+		if h.Tag != in {
+			in.hits = append(in.hits, h)
+		}
+	}
+	if in.selected >= len(in.hits) {
+		in.selected = len(in.hits) - 1
+	}
+	if in.selected < 0 {
+		in.selected = 0
+	}
+}
+
+func (in *Inspector) modifiers() key.Modifiers {
+	if in.Modifiers == 0 {
+		return key.ModShortcut | key.ModShift
+	}
+	return in.Modifiers
+}
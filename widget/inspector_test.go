@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/io/semantic"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+func TestInspector(t *testing.T) {
+	var r router.Router
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(100, 100)),
+		Queue:       &r,
+	}
+	var in Inspector
+	btn := new(int)
+	frame := func() {
+		gtx.Ops.Reset()
+		in.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			defer clip.Rect(image.Rect(10, 10, 50, 50)).Push(gtx.Ops).Pop()
+			pointer.InputOp{Tag: btn, Types: pointer.Press}.Add(gtx.Ops)
+			return layout.Dimensions{Size: gtx.Constraints.Max}
+		})
+		r.Frame(gtx.Ops)
+	}
+	frame()
+
+	r.Queue(pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 20)})
+	frame()
+	if n := len(in.Hits()); n != 0 {
+		t.Errorf("got %d hits without modifiers", n)
+	}
+	r.Queue(pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 21), Modifiers: key.ModShortcut | key.ModShift})
+	frame()
+	hits := in.Hits()
+	if len(hits) != 1 || hits[0].Tag != btn {
+		t.Fatalf("got hits %+v, expected the button", hits)
+	}
+	if exp := f32.Rect(10, 10, 50, 50); hits[0].Bounds != exp {
+		t.Errorf("got bounds %v, expected %v", hits[0].Bounds, exp)
+	}
+	// HitTest is side effect free; the button received no events.
+	for _, e := range r.Events(btn) {
+		if e, ok := e.(pointer.Event); ok && e.Type != pointer.Cancel {
+			t.Errorf("button received %v", e.Type)
+		}
+	}
+}
+
+func TestInspectorToggle(t *testing.T) {
+	var r router.Router
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(100, 100)),
+		Queue:       &r,
+	}
+	var in Inspector
+	panel, btn := new(int), new(int)
+	focusBtn := true
+	frame := func() {
+		gtx.Ops.Reset()
+		in.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			// The button is inside the panel.
+			outer := clip.Rect(image.Rect(0, 0, 80, 80)).Push(gtx.Ops)
+			pointer.InputOp{Tag: panel, Types: pointer.Press}.Add(gtx.Ops)
+			inner := clip.Rect(image.Rect(10, 10, 50, 50)).Push(gtx.Ops)
+			pointer.InputOp{Tag: btn, Types: pointer.Press}.Add(gtx.Ops)
+			key.InputOp{Tag: btn}.Add(gtx.Ops)
+			semantic.Button.Add(gtx.Ops)
+			semantic.LabelOp("OK").Add(gtx.Ops)
+			inner.Pop()
+			outer.Pop()
+			if focusBtn {
+				focusBtn = false
+				key.FocusOp{Tag: btn}.Add(gtx.Ops)
+			}
+			return layout.Dimensions{Size: gtx.Constraints.Max}
+		})
+		r.Frame(gtx.Ops)
+	}
+	// delivered records the events of the inspected handlers by send.
+	delivered := make(map[event.Tag][]event.Event)
+	// send routes events like a window with an inspector, and lays out
+	// a frame.
+	send := func(events ...event.Event) {
+		for _, e := range events {
+			if !in.Intercept(e) {
+				r.Queue(e)
+			}
+		}
+		for _, tag := range []event.Tag{panel, btn} {
+			delivered[tag] = append(delivered[tag][:0], r.Events(tag)...)
+		}
+		frame()
+	}
+	chord := func() {
+		send(
+			key.Event{Name: "I", Modifiers: key.ModShortcut | key.ModShift, State: key.Press},
+			key.Event{Name: "I", Modifiers: key.ModShortcut | key.ModShift, State: key.Release},
+		)
+	}
+	press := func(name string) {
+		send(key.Event{Name: name, State: key.Press})
+	}
+	frame()
+
+	chord()
+	if !in.Inspecting() {
+		t.Fatal("chord didn't toggle inspection")
+	}
+	if evts := r.Events(btn); len(evts) != 1 {
+		t.Errorf("got button events %v; expected a loss of focus", evts)
+	} else if e, ok := evts[0].(key.FocusEvent); !ok || e.Focus {
+		t.Errorf("got button event %v; expected a loss of focus", e)
+	}
+
+	// Inspection needs no modifiers, and freezes the inspected widgets.
+	send(
+		pointer.Event{Type: pointer.Move, Position: f32.Pt(20, 20)},
+		pointer.Event{Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: f32.Pt(20, 20)},
+		pointer.Event{Type: pointer.Release, Position: f32.Pt(20, 20)},
+	)
+	for _, tag := range []event.Tag{panel, btn} {
+		for _, e := range delivered[tag] {
+			if e, ok := e.(pointer.Event); ok && e.Type != pointer.Cancel {
+				t.Errorf("inspected handler received %v", e.Type)
+			}
+		}
+	}
+	hits := in.Hits()
+	if len(hits) != 2 || hits[0].Tag != btn || hits[1].Tag != panel {
+		t.Fatalf("got hits %+v, expected the button and the panel", hits)
+	}
+	if in.Selected() != 0 {
+		t.Errorf("got selection %d, expected the topmost hit", in.Selected())
+	}
+	if d := hits[0].Desc; d.Class != semantic.Button || d.Label != "OK" {
+		t.Errorf("got button description %+v", d)
+	}
+	if exp := f32.Rect(0, 0, 80, 80); hits[1].Bounds != exp {
+		t.Errorf("got panel bounds %v, expected %v", hits[1].Bounds, exp)
+	}
+
+	// The arrows walk the hits, and are not delivered to the inspected
+	// widgets.
+	press(key.NameUpArrow)
+	if in.Selected() != 1 {
+		t.Errorf("got selection %d after up, expected the panel", in.Selected())
+	}
+	press(key.NameUpArrow)
+	if in.Selected() != 1 {
+		t.Errorf("got selection %d, expected the selection to stop at the panel", in.Selected())
+	}
+	press(key.NameDownArrow)
+	if in.Selected() != 0 {
+		t.Errorf("got selection %d after down, expected the button", in.Selected())
+	}
+	if evts := delivered[btn]; len(evts) != 0 {
+		t.Errorf("button received %v while inspecting", evts)
+	}
+
+	press(key.NameEscape)
+	if in.Inspecting() || len(in.Hits()) != 0 {
+		t.Error("Esc didn't stop inspection")
+	}
+	// The focus returns to the button.
+	if f := r.Focused(); f != btn {
+		t.Errorf("focused %v after inspection, expected the button", f)
+	}
+	chord()
+	chord()
+	if in.Inspecting() {
+		t.Error("chord didn't toggle inspection off")
+	}
+	if f := r.Focused(); f != btn {
+		t.Errorf("focused %v after toggling inspection, expected the button", f)
+	}
+	send(pointer.Event{Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: f32.Pt(20, 20)})
+	if !pressed(delivered[btn]) {
+		t.Error("button didn't receive a press after inspection")
+	}
+}
+
+func pressed(evts []event.Event) bool {
+	for _, e := range evts {
+		if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+			return true
+		}
+	}
+	return false
+}
@@ -24,6 +24,10 @@ type Label struct {
 	Alignment text.Alignment
 	// MaxLines limits the number of lines. Zero means no limit.
 	MaxLines int
+	// AutoTooltip, if non-nil, tracks the pointer over the label when
+	// its text is truncated, to show the full text in a tooltip. A label
+	// that fits its text tracks nothing.
+	AutoTooltip *Tooltip
 }
 
 // screenPos describes a character position (in text line and column numbers,
@@ -91,14 +95,28 @@ func (l Label) Layout(gtx layout.Context, s text.Shaper, font text.Font, size un
 	cs := gtx.Constraints
 	textSize := fixed.I(gtx.Px(size))
 	lines := s.LayoutString(font, textSize, cs.Max.X, txt)
+	truncated := false
 	if max := l.MaxLines; max > 0 && len(lines) > max {
 		lines = lines[:max]
+		truncated = true
 	}
 	dims := linesDimens(lines)
-	dims.Size = cs.Constrain(dims.Size)
-	if len(lines) == 0 {
+	sz := cs.Constrain(dims.Size)
+	if sz.X < dims.Size.X || sz.Y < dims.Size.Y {
+		truncated = true
+	}
+	dims.Size = sz
+	if len(lines) == 0 || gtx.IsMeasuring() {
 		return dims
 	}
+	if t := l.AutoTooltip; t != nil {
+		if truncated {
+			t.text = txt
+			t.add(gtx, dims.Size)
+		} else {
+			t.hide()
+		}
+	}
 	cl := textPadding(lines)
 	cl.Max = cl.Max.Add(dims.Size)
 	defer clip.Rect(cl).Push(gtx.Ops).Pop()
@@ -3,7 +3,7 @@
 package widget
 
 import (
-	"image"
+	"time"
 
 	"gioui.org/gesture"
 	"gioui.org/io/key"
@@ -18,17 +18,39 @@ import (
 // position can be adjusted by drag operations along the display area,
 // or by clicks within the display area.
 //
+// Pressing the track outside the indicator pages the viewport towards
+// the pointer, and holding the press repeats the paging until the
+// indicator reaches the pointer. Shift-pressing the track centers the
+// indicator on the pointer.
+//
 // Scrollbar additionally detects when a scroll indicator region is
 // hovered.
 type Scrollbar struct {
+	// Focusable makes the track take part in keyboard focus
+	// navigation. A focused scrollbar scrolls by a tenth of the viewport
+	// for the arrow keys, by the viewport for Page Up and Page Down, and
+	// to the ends for Home and End. Pointer presses don't focus the
+	// scrollbar.
+	Focusable bool
+
 	track, indicator gesture.Click
 	drag             gesture.Drag
 	delta            float32
 
 	dragging   bool
 	oldDragPos float32
+
+	// paging is set while the track is held, and pagePos is the
+	// normalized position of the pointer. nextPage is the time of the
+	// next repeat.
+	paging   bool
+	pagePos  float32
+	nextPage time.Time
 }
 
+// scrollbarRepeat is the period of paging while the track is held.
+const scrollbarRepeat = 250 * time.Millisecond
+
 // Layout updates the internal state of the scrollbar based on events
 // since the previous call to Layout. The provided axis will be used to
 // normalize input event coordinates and constraints into an axis-
@@ -46,22 +68,58 @@ func (s *Scrollbar) Layout(gtx layout.Context, axis layout.Axis, viewportStart,
 	trackHeight := float32(axis.Convert(gtx.Constraints.Max).X)
 	s.delta = 0
 
-	// Jump to a click in the track.
+	// Page towards a press in the track, or jump to a Shift-press.
+	s.track.Config = gtx.Gesture
 	for _, event := range s.track.Events(gtx) {
-		if event.Type != gesture.TypeClick ||
-			event.Modifiers != key.Modifiers(0) ||
-			event.NumClicks > 1 {
+		switch event.Type {
+		case gesture.TypePress:
+			pos := axis.FConvert(event.Position).X / trackHeight
+			switch event.Modifiers {
+			case key.ModShift:
+				s.delta += pos - (viewportStart+viewportEnd)/2
+			case 0:
+				s.paging = true
+				s.pagePos = pos
+				s.page(viewportStart, viewportEnd)
+				s.nextPage = gtx.Now.Add(scrollbarRepeat)
+			}
+		case gesture.TypeClick, gesture.TypeCancel:
+			s.paging = false
+		}
+	}
+	if s.paging && !gtx.Now.Before(s.nextPage) {
+		s.nextPage = gtx.Now.Add(scrollbarRepeat)
+		s.page(viewportStart, viewportEnd)
+	}
+	if s.paging {
+		op.InvalidateOp{At: s.nextPage}.Add(gtx.Ops)
+	}
+
+	// Scroll by keys.
+	for _, event := range gtx.Events(s) {
+		e, ok := event.(key.Event)
+		if !ok || e.State != key.Press {
 			continue
 		}
-		pos := axis.Convert(image.Point{
-			X: int(event.Position.X),
-			Y: int(event.Position.Y),
-		})
-		normalizedPos := float32(pos.X) / trackHeight
-		s.delta += normalizedPos - viewportStart
+		view := viewportEnd - viewportStart
+		switch e.Name {
+		case key.NameUpArrow, key.NameLeftArrow:
+			s.delta -= view / 10
+		case key.NameDownArrow, key.NameRightArrow:
+			s.delta += view / 10
+		case key.NamePageUp:
+			s.delta -= view
+		case key.NamePageDown:
+			s.delta += view
+		case key.NameHome:
+			s.delta -= viewportStart
+		case key.NameEnd:
+			s.delta += 1 - viewportEnd
+		}
 	}
 
 	// Offset to account for any drags.
+	s.drag.Config = gtx.Gesture
 	for _, event := range s.drag.Events(gtx.Metric, gtx, gesture.Axis(axis)) {
 		switch event.Type {
 		case pointer.Drag:
@@ -91,10 +149,39 @@ func (s *Scrollbar) Layout(gtx layout.Context, axis layout.Axis, viewportStart,
 	return layout.Dimensions{}
 }
 
-// AddTrack configures the track click listener for the scrollbar to use
-// the current clip area.
+// page moves the viewport by its length towards the held pointer, and
+// stops paging when the viewport reaches the pointer. A page never moves
+// the viewport past the pointer.
+func (s *Scrollbar) page(viewportStart, viewportEnd float32) {
+	view := viewportEnd - viewportStart
+	switch {
+	case s.pagePos < viewportStart:
+		if d := viewportEnd - s.pagePos; d < view {
+			view = d
+		}
+		s.delta -= view
+	case s.pagePos > viewportEnd:
+		if d := s.pagePos - viewportStart; d < view {
+			view = d
+		}
+		s.delta += view
+	default:
+		s.paging = false
+	}
+}
+
+// Paging reports whether the track is held for paging.
+func (s *Scrollbar) Paging() bool {
+	return s.paging
+}
+
+// AddTrack configures the track click listener, and the key handler of
+// a Focusable scrollbar, to use the current clip area.
 func (s *Scrollbar) AddTrack(ops *op.Ops) {
 	s.track.Add(ops)
+	if s.Focusable {
+		key.InputOp{Tag: s}.Add(ops)
+	}
 }
 
 // AddIndicator configures the indicator click listener for the scrollbar to use
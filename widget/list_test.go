@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"math"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+func TestScrollbarPaging(t *testing.T) {
+	var (
+		r   router.Router
+		s   Scrollbar
+		ops op.Ops
+	)
+	gtx := layout.Context{
+		Ops:         &ops,
+		Queue:       &r,
+		Constraints: layout.Exact(image.Pt(10, 100)),
+		Now:         time.Unix(0, 0),
+	}
+	// The viewport shows a tenth of the content, starting at start.
+	const view = .1
+	start := float32(0)
+	frame := func() {
+		ops.Reset()
+		s.Layout(gtx, layout.Vertical, start, start+view)
+		start += s.ScrollDistance()
+		area := clip.Rect(image.Rect(0, 0, 10, 100)).Push(&ops)
+		s.AddTrack(&ops)
+		area.Pop()
+		r.Frame(&ops)
+	}
+	near := func(a, b float32) bool {
+		return math.Abs(float64(a-b)) < 1e-4
+	}
+	frame()
+	// Hold the track below the indicator.
+	r.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(5, 75)})
+	frame()
+	if !near(start, .1) || !s.Paging() {
+		t.Fatalf("press paged to %v, paging: %v; expected 0.1", start, s.Paging())
+	}
+	var pages []time.Duration
+	begin := gtx.Now
+	for i := 0; i < 60; i++ {
+		gtx.Now = gtx.Now.Add(50 * time.Millisecond)
+		old := start
+		frame()
+		if start != old {
+			pages = append(pages, gtx.Now.Sub(begin))
+		}
+	}
+	// The pages stop with the indicator under the pointer.
+	if !near(start, .7) || s.Paging() {
+		t.Errorf("holding paged to %v, paging: %v; expected 0.7", start, s.Paging())
+	}
+	if len(pages) != 6 {
+		t.Fatalf("got pages at %v; expected 6 pages", pages)
+	}
+	for i, d := range pages {
+		if exp := time.Duration(i+1) * scrollbarRepeat; d != exp {
+			t.Errorf("page %d at %v; expected %v", i, d, exp)
+		}
+	}
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(5, 75)})
+	frame()
+
+	// Shift-press centers the indicator on the pointer.
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(5, 30), Modifiers: key.ModShift},
+		pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(5, 30), Modifiers: key.ModShift},
+	)
+	frame()
+	if !near(start, .25) || s.Paging() {
+		t.Errorf("Shift-press jumped to %v, paging: %v; expected 0.25", start, s.Paging())
+	}
+
+	// Presses don't focus the scrollbar, and keys don't scroll an
+	// unfocusable scrollbar.
+	r.Queue(key.Event{Name: key.NameEnd, State: key.Press})
+	frame()
+	if !near(start, .25) {
+		t.Errorf("key scrolled an unfocusable scrollbar to %v", start)
+	}
+	s.Focusable = true
+	frame()
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(5, 30), Modifiers: key.ModShift},
+		pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(5, 30), Modifiers: key.ModShift},
+	)
+	r.Queue(key.Event{Name: key.NameEnd, State: key.Press})
+	frame()
+	if !near(start, .25) {
+		t.Errorf("key scrolled a pressed scrollbar to %v", start)
+	}
+	// Focus the scrollbar by Tab.
+	r.Queue(key.Event{Name: key.NameTab, State: key.Press})
+	frame()
+
+	// The focused scrollbar scrolls by keys.
+	for _, k := range []struct {
+		name string
+		exp  float32
+	}{
+		{key.NamePageDown, .35},
+		{key.NameUpArrow, .34},
+		{key.NamePageUp, .24},
+		{key.NameEnd, .9},
+		{key.NameHome, 0},
+	} {
+		r.Queue(key.Event{Name: k.name, State: key.Press})
+		frame()
+		if !near(start, k.exp) {
+			t.Errorf("key %s scrolled to %v; expected %v", k.name, start, k.exp)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !race
+// +build !race
+
+package material_test
+
+import (
+	"testing"
+
+	"gioui.org/font/gofont"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+func TestThemeDefaultsAllocs(t *testing.T) {
+	var (
+		click  widget.Clickable
+		editor widget.Editor
+		check  widget.Bool
+		float  widget.Float
+	)
+	th := material.NewTheme(gofont.Collection())
+	th.RegisterButtonDefaults(func(b *material.ButtonStyle) { b.CornerRadius = unit.Dp(12) })
+	th.RegisterEditorDefaults(func(e *material.EditorStyle) { e.TextSize = unit.Sp(20) })
+	th.RegisterCheckBoxDefaults(func(c *material.CheckBoxStyle) { c.Size = unit.Dp(20) })
+	th.RegisterSliderDefaults(func(s *material.SliderStyle) { s.FingerSize = unit.Dp(40) })
+	th.RegisterLabelDefaults(func(l *material.LabelStyle) { l.MaxLines = 1 })
+	allocs := testing.AllocsPerRun(100, func() {
+		material.Button(th, &click, "OK")
+		material.Editor(th, &editor, "")
+		material.CheckBox(th, &check, "")
+		material.Slider(th, &float, 0, 1)
+		material.H1(th, "")
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocs, got %f", allocs)
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/internal/f32color"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// BreadcrumbsStyle lays out a widget.Breadcrumbs as a row of labels
+// separated by arrows.
+type BreadcrumbsStyle struct {
+	Breadcrumbs *widget.Breadcrumbs
+	TextSize    unit.Value
+	Color       color.NRGBA
+	// SeparatorColor is the color of the separators between crumbs.
+	SeparatorColor color.NRGBA
+	// Separator is the text between crumbs.
+	Separator string
+	// Inset is the padding of each crumb.
+	Inset layout.Inset
+	// MenuHeight is the maximum height of the overflow menu.
+	MenuHeight unit.Value
+	Menu       MenuStyle
+	shaper     text.Shaper
+}
+
+func Breadcrumbs(th *Theme, crumbs *widget.Breadcrumbs) BreadcrumbsStyle {
+	return BreadcrumbsStyle{
+		Breadcrumbs:    crumbs,
+		TextSize:       th.TextSize.Scale(14.0 / 16.0),
+		Color:          th.Palette.Fg,
+		SeparatorColor: f32color.MulAlpha(th.Palette.Fg, 0x99),
+		Separator:      "›",
+		Inset: layout.Inset{
+			Top: unit.Dp(4), Bottom: unit.Dp(4),
+			Left: unit.Dp(6), Right: unit.Dp(6),
+		},
+		MenuHeight: unit.Dp(320),
+		Menu:       Menu(th, &crumbs.Menu),
+		shaper:     th.Shaper,
+	}
+}
+
+func (b BreadcrumbsStyle) Layout(gtx layout.Context) layout.Dimensions {
+	return b.Breadcrumbs.Layout(gtx, b.layoutCrumb, b.layoutOverflow, b.layoutMenu)
+}
+
+func (b BreadcrumbsStyle) layoutCrumb(gtx layout.Context, i int) layout.Dimensions {
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if i == 0 {
+				return layout.Dimensions{}
+			}
+			return b.label(b.Separator, b.SeparatorColor).Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			click := b.Breadcrumbs.Clickable(i)
+			return b.layoutChip(gtx, click, b.Breadcrumbs.Crumbs[i].Label)
+		}),
+	)
+}
+
+func (b BreadcrumbsStyle) layoutOverflow(gtx layout.Context) layout.Dimensions {
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(b.label(b.Separator, b.SeparatorColor).Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return b.layoutChip(gtx, b.Breadcrumbs.Overflow(), "…")
+		}),
+	)
+}
+
+// layoutChip lays out a clickable label with a highlight when hovered.
+func (b BreadcrumbsStyle) layoutChip(gtx layout.Context, click *widget.Clickable, txt string) layout.Dimensions {
+	return click.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min = image.Point{}
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+				sz := gtx.Constraints.Min
+				if click.Hovered() && !gtx.IsMeasuring() {
+					rr := float32(gtx.Px(unit.Dp(4)))
+					defer clip.UniformRRect(layout.FRect(image.Rectangle{Max: sz}), rr).Push(gtx.Ops).Pop()
+					paint.Fill(gtx.Ops, f32color.MulAlpha(b.Color, 0x20))
+				}
+				return layout.Dimensions{Size: sz}
+			}),
+			layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+				return b.Inset.Layout(gtx, b.label(txt, b.Color).Layout)
+			}),
+		)
+	})
+}
+
+func (b BreadcrumbsStyle) layoutMenu(gtx layout.Context) layout.Dimensions {
+	gtx.Constraints.Max.Y = gtx.Px(b.MenuHeight)
+	return b.Menu.Layout(gtx)
+}
+
+func (b BreadcrumbsStyle) label(txt string, col color.NRGBA) LabelStyle {
+	return LabelStyle{
+		Text:     txt,
+		Color:    col,
+		TextSize: b.TextSize,
+		MaxLines: 1,
+		shaper:   b.shaper,
+	}
+}
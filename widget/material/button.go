@@ -29,6 +29,9 @@ type ButtonStyle struct {
 	CornerRadius unit.Value
 	Inset        layout.Inset
 	Button       *widget.Clickable
+	// BaselineGrid is the grid for the baseline of the text. Zero
+	// disables snapping.
+	BaselineGrid unit.Value
 	shaper       text.Shaper
 }
 
@@ -48,10 +51,12 @@ type IconButtonStyle struct {
 	Inset       layout.Inset
 	Button      *widget.Clickable
 	Description string
+	// MinTouchTarget is the minimum size of the pointer input area.
+	MinTouchTarget unit.Value
 }
 
 func Button(th *Theme, button *widget.Clickable, txt string) ButtonStyle {
-	return ButtonStyle{
+	return th.buttonDefaults(ButtonStyle{
 		Text:         txt,
 		Color:        th.Palette.ContrastFg,
 		CornerRadius: unit.Dp(4),
@@ -61,9 +66,10 @@ func Button(th *Theme, button *widget.Clickable, txt string) ButtonStyle {
 			Top: unit.Dp(10), Bottom: unit.Dp(10),
 			Left: unit.Dp(12), Right: unit.Dp(12),
 		},
-		Button: button,
-		shaper: th.Shaper,
-	}
+		Button:       button,
+		BaselineGrid: th.BaselineGrid,
+		shaper:       th.Shaper,
+	})
 }
 
 func ButtonLayout(th *Theme, button *widget.Clickable) ButtonLayoutStyle {
@@ -76,13 +82,14 @@ func ButtonLayout(th *Theme, button *widget.Clickable) ButtonLayoutStyle {
 
 func IconButton(th *Theme, button *widget.Clickable, icon *widget.Icon, description string) IconButtonStyle {
 	return IconButtonStyle{
-		Background:  th.Palette.ContrastBg,
-		Color:       th.Palette.ContrastFg,
-		Icon:        icon,
-		Size:        unit.Dp(24),
-		Inset:       layout.UniformInset(unit.Dp(12)),
-		Button:      button,
-		Description: description,
+		Background:     th.Palette.ContrastBg,
+		Color:          th.Palette.ContrastFg,
+		Icon:           icon,
+		Size:           unit.Dp(24),
+		Inset:          layout.UniformInset(unit.Dp(12)),
+		Button:         button,
+		Description:    description,
+		MinTouchTarget: th.MinTouchTarget,
 	}
 }
 
@@ -117,9 +124,13 @@ func (b ButtonStyle) Layout(gtx layout.Context) layout.Dimensions {
 		CornerRadius: b.CornerRadius,
 		Button:       b.Button,
 	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		return b.Inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-			paint.ColorOp{Color: b.Color}.Add(gtx.Ops)
-			return widget.Label{Alignment: text.Middle}.Layout(gtx, b.shaper, b.Font, b.TextSize, b.Text)
+		return layout.SnapBaseline{Grid: b.BaselineGrid}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return b.Inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				if !gtx.IsMeasuring() {
+					paint.ColorOp{Color: b.Color}.Add(gtx.Ops)
+				}
+				return widget.Label{Alignment: text.Middle}.Layout(gtx, b.shaper, b.Font, b.TextSize, b.Text)
+			})
 		})
 	})
 }
@@ -127,9 +138,14 @@ func (b ButtonStyle) Layout(gtx layout.Context) layout.Dimensions {
 func (b ButtonLayoutStyle) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
 	min := gtx.Constraints.Min
 	return b.Button.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		semantic.Button.Add(gtx.Ops)
+		if !gtx.IsMeasuring() {
+			semantic.Button.Add(gtx.Ops)
+		}
 		return layout.Stack{Alignment: layout.Center}.Layout(gtx,
 			layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+				if gtx.IsMeasuring() {
+					return layout.Skeleton(gtx.Constraints.Min)
+				}
 				rr := float32(gtx.Px(b.CornerRadius))
 				defer clip.UniformRRect(f32.Rectangle{Max: f32.Point{
 					X: float32(gtx.Constraints.Min.X),
@@ -157,14 +173,32 @@ func (b ButtonLayoutStyle) Layout(gtx layout.Context, w layout.Widget) layout.Di
 }
 
 func (b IconButtonStyle) Layout(gtx layout.Context) layout.Dimensions {
+	if gtx.IsMeasuring() {
+		return b.layout(gtx)
+	}
 	m := op.Record(gtx.Ops)
-	dims := b.Button.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		semantic.Button.Add(gtx.Ops)
-		if d := b.Description; d != "" {
-			semantic.DescriptionOp(b.Description).Add(gtx.Ops)
+	dims := b.layout(gtx)
+	c := m.Stop()
+	defer expandTouchTarget(gtx, b.MinTouchTarget).Pop()
+	bounds := f32.Rectangle{Max: layout.FPt(dims.Size)}
+	defer clip.Ellipse(bounds).Push(gtx.Ops).Pop()
+	c.Add(gtx.Ops)
+	return dims
+}
+
+func (b IconButtonStyle) layout(gtx layout.Context) layout.Dimensions {
+	return b.Button.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		if !gtx.IsMeasuring() {
+			semantic.Button.Add(gtx.Ops)
+			if d := b.Description; d != "" {
+				semantic.DescriptionOp(b.Description).Add(gtx.Ops)
+			}
 		}
 		return layout.Stack{Alignment: layout.Center}.Layout(gtx,
 			layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+				if gtx.IsMeasuring() {
+					return layout.Skeleton(gtx.Constraints.Min)
+				}
 				sizex, sizey := gtx.Constraints.Min.X, gtx.Constraints.Min.Y
 				sizexf, sizeyf := float32(sizex), float32(sizey)
 				rr := (sizexf + sizeyf) * .25
@@ -198,11 +232,6 @@ func (b IconButtonStyle) Layout(gtx layout.Context) layout.Dimensions {
 			}),
 		)
 	})
-	c := m.Stop()
-	bounds := f32.Rectangle{Max: layout.FPt(dims.Size)}
-	defer clip.Ellipse(bounds).Push(gtx.Ops).Pop()
-	c.Add(gtx.Ops)
-	return dims
 }
 
 func drawInk(gtx layout.Context, c widget.Press) {
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material_test
+
+import (
+	"image"
+	"testing"
+
+	"golang.org/x/exp/shiny/materialdesign/icons"
+
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/io/semantic"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+func TestIconButtonTouchTarget(t *testing.T) {
+	var (
+		ops      op.Ops
+		r        router.Router
+		btn, nbr widget.Clickable
+	)
+	ic, err := widget.NewIcon(icons.ContentAdd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	th := material.NewTheme(gofont.Collection())
+	gtx := layout.NewContext(&ops, system.FrameEvent{
+		Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Size:   image.Pt(300, 300),
+		Queue:  &r,
+	})
+	// button lays out a 16dp icon button at x.
+	button := func(click *widget.Clickable, x float32) {
+		defer op.Offset(f32.Pt(x, 100)).Push(gtx.Ops).Pop()
+		b := material.IconButton(th, click, ic, "")
+		b.Size = unit.Dp(16)
+		b.Inset = layout.Inset{}
+		gtx := gtx
+		gtx.Constraints.Min = image.Point{}
+		b.Layout(gtx)
+	}
+	frame := func() {
+		ops.Reset()
+		// The neighbour is 4dp to the left, and below in painting order.
+		button(&nbr, 80)
+		button(&btn, 100)
+		r.Frame(gtx.Ops)
+	}
+	click := func(pos f32.Point) {
+		r.Queue(
+			pointer.Event{Source: pointer.Touch, Type: pointer.Press, Position: pos},
+			pointer.Event{Source: pointer.Touch, Type: pointer.Release, Position: pos},
+		)
+		frame()
+	}
+	frame()
+
+	// 10dp above and below the button.
+	for _, pos := range []f32.Point{f32.Pt(108, 90), f32.Pt(108, 126)} {
+		click(pos)
+		if !btn.Clicked() {
+			t.Errorf("click at %v outside the button didn't activate it", pos)
+		}
+		if nbr.Clicked() {
+			t.Errorf("click at %v activated the neighbour", pos)
+		}
+	}
+	// Inside the neighbour, and inside the expanded area of the button.
+	click(f32.Pt(94, 108))
+	if !nbr.Clicked() {
+		t.Error("click inside the neighbour didn't activate it")
+	}
+	if btn.Clicked() {
+		t.Error("expanded button won over the neighbour")
+	}
+}
+
+func TestToggleIconButton(t *testing.T) {
+	var (
+		ops    op.Ops
+		r      router.Router
+		toggle widget.Bool
+	)
+	ic, err := widget.NewIcon(icons.EditorFormatBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	th := material.NewTheme(gofont.Collection())
+	gtx := layout.NewContext(&ops, system.FrameEvent{
+		Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Size:   image.Pt(300, 300),
+		Queue:  &r,
+	})
+	frame := func() {
+		ops.Reset()
+		gtx := gtx
+		gtx.Constraints.Min = image.Point{}
+		material.ToggleIconButton(th, &toggle, ic, "Bold").Layout(gtx)
+		r.Frame(gtx.Ops)
+	}
+	// checked returns the checked state of the toggle button node.
+	checked := func() bool {
+		t.Helper()
+		for _, n := range r.AppendSemantics(nil) {
+			if n.Desc.Class == semantic.ToggleButton {
+				return n.Desc.Selected
+			}
+		}
+		t.Fatal("no toggle button")
+		return false
+	}
+	frame()
+	if toggle.Value || checked() {
+		t.Fatal("toggle button initially on")
+	}
+	r.Queue(
+		pointer.Event{Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Type: pointer.Press, Position: f32.Pt(24, 24)},
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Release, Position: f32.Pt(24, 24)},
+	)
+	frame()
+	frame()
+	if !toggle.Value || !checked() {
+		t.Errorf("click didn't toggle the button on")
+	}
+	// The click focused the button.
+	r.Queue(
+		key.Event{Name: key.NameSpace, State: key.Press},
+		key.Event{Name: key.NameSpace, State: key.Release},
+	)
+	frame()
+	frame()
+	if toggle.Value || checked() {
+		t.Errorf("Space didn't toggle the button off")
+	}
+}
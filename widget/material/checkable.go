@@ -26,6 +26,9 @@ type checkable struct {
 	shaper             text.Shaper
 	checkedStateIcon   *widget.Icon
 	uncheckedStateIcon *widget.Icon
+
+	// MinTouchTarget is the minimum size of the pointer input area.
+	MinTouchTarget unit.Value
 }
 
 func (c *checkable) layout(gtx layout.Context, checked, hovered bool) layout.Dimensions {
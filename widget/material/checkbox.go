@@ -15,7 +15,7 @@ type CheckBoxStyle struct {
 }
 
 func CheckBox(th *Theme, checkBox *widget.Bool, label string) CheckBoxStyle {
-	return CheckBoxStyle{
+	return th.checkBoxDefaults(CheckBoxStyle{
 		CheckBox: checkBox,
 		checkable: checkable{
 			Label:              label,
@@ -23,15 +23,17 @@ func CheckBox(th *Theme, checkBox *widget.Bool, label string) CheckBoxStyle {
 			IconColor:          th.Palette.ContrastBg,
 			TextSize:           th.TextSize.Scale(14.0 / 16.0),
 			Size:               unit.Dp(26),
+			MinTouchTarget:     th.MinTouchTarget,
 			shaper:             th.Shaper,
 			checkedStateIcon:   th.Icon.CheckBoxChecked,
 			uncheckedStateIcon: th.Icon.CheckBoxUnchecked,
 		},
-	}
+	})
 }
 
 // Layout updates the checkBox and displays it.
 func (c CheckBoxStyle) Layout(gtx layout.Context) layout.Dimensions {
+	defer expandTouchTarget(gtx, c.MinTouchTarget).Pop()
 	return c.CheckBox.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		semantic.CheckBox.Add(gtx.Ops)
 		return c.layout(gtx, c.CheckBox.Value, c.CheckBox.Hovered() || c.CheckBox.Focused())
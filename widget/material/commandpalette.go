@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/internal/f32color"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// CommandPaletteStyle lays out a widget.CommandPalette as a panel with the
+// query above the matching commands and their shortcuts.
+type CommandPaletteStyle struct {
+	Palette *widget.CommandPalette
+	// Hint is shown when the query is empty.
+	Hint string
+	// Width is the maximum width of the panel.
+	Width unit.Value
+	// MaxResults limits the number of visible results.
+	MaxResults int
+
+	TextSize unit.Value
+	// Color is the text color.
+	Color color.NRGBA
+	// HintColor is the color of Hint and shortcuts.
+	HintColor  color.NRGBA
+	Background color.NRGBA
+	// SelectedColor is the background of the selected result.
+	SelectedColor color.NRGBA
+	shaper        text.Shaper
+}
+
+func CommandPalette(th *Theme, palette *widget.CommandPalette) CommandPaletteStyle {
+	return CommandPaletteStyle{
+		Palette:       palette,
+		Hint:          "Type a command",
+		Width:         unit.Dp(480),
+		MaxResults:    8,
+		TextSize:      th.TextSize,
+		Color:         th.Palette.Fg,
+		HintColor:     f32color.MulAlpha(th.Palette.Fg, 0xbb),
+		Background:    th.Palette.Bg,
+		SelectedColor: f32color.MulAlpha(th.Palette.ContrastBg, 0x44),
+		shaper:        th.Shaper,
+	}
+}
+
+// Layout the palette at the top of the available space. Nothing is laid out
+// if the palette is closed.
+func (c CommandPaletteStyle) Layout(gtx layout.Context) layout.Dimensions {
+	return c.Palette.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		if w := gtx.Px(c.Width); gtx.Constraints.Max.X > w {
+			gtx.Constraints.Max.X = w
+		}
+		gtx.Constraints.Min = image.Point{X: gtx.Constraints.Max.X}
+		return layout.N.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Stack{}.Layout(gtx,
+				layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+					sz := gtx.Constraints.Min
+					defer clip.UniformRRect(layout.FRect(image.Rectangle{Max: sz}), float32(gtx.Px(unit.Dp(4)))).Push(gtx.Ops).Pop()
+					paint.Fill(gtx.Ops, c.Background)
+					return layout.Dimensions{Size: sz}
+				}),
+				layout.Stacked(c.layoutContent),
+			)
+		})
+	})
+}
+
+func (c CommandPaletteStyle) layoutContent(gtx layout.Context) layout.Dimensions {
+	inset := layout.UniformInset(unit.Dp(8))
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				lbl := c.label(c.TextSize, c.Palette.Query(), c.Color)
+				if lbl.Text == "" {
+					lbl.Text = c.Hint
+					lbl.Color = c.HintColor
+				}
+				return lbl.Layout(gtx)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			results := c.Palette.Results()
+			if max := gtx.Px(c.TextSize) * 2 * c.MaxResults; c.MaxResults > 0 && gtx.Constraints.Max.Y > max {
+				gtx.Constraints.Max.Y = max
+			}
+			c.Palette.List.Axis = layout.Vertical
+			return c.Palette.List.Layout(gtx, len(results), func(gtx layout.Context, i int) layout.Dimensions {
+				return c.layoutResult(gtx, i, results[i])
+			})
+		}),
+	)
+}
+
+func (c CommandPaletteStyle) layoutResult(gtx layout.Context, i int, cmd widget.Command) layout.Dimensions {
+	return c.Palette.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+				sz := gtx.Constraints.Min
+				if i == c.Palette.Selected() {
+					defer clip.Rect{Max: sz}.Push(gtx.Ops).Pop()
+					paint.Fill(gtx.Ops, c.SelectedColor)
+				}
+				return layout.Dimensions{Size: sz}
+			}),
+			layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					title := cmd.Title
+					if cmd.Category != "" {
+						title = cmd.Category + ": " + title
+					}
+					return layout.Flex{Alignment: layout.Baseline, Spacing: layout.SpaceBetween}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return c.label(c.TextSize, title, c.Color).Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return c.label(c.TextSize.Scale(14.0/16.0), cmd.Shortcut.String(), c.HintColor).Layout(gtx)
+						}),
+					)
+				})
+			}),
+		)
+	})
+}
+
+// label returns a single line label.
+func (c CommandPaletteStyle) label(size unit.Value, txt string, col color.NRGBA) LabelStyle {
+	return LabelStyle{
+		Text:     txt,
+		Color:    col,
+		TextSize: size,
+		MaxLines: 1,
+		shaper:   c.shaper,
+	}
+}
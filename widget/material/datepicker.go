@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/internal/f32color"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/text/locale"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// DatePickerStyle lays out a widget.DatePicker as a month calendar.
+type DatePickerStyle struct {
+	Picker *widget.DatePicker
+	// Format formats the month names, weekdays and days. If nil, the
+	// format of the layout.Context locale is used.
+	Format   *locale.Format
+	TextSize unit.Value
+	Color    color.NRGBA
+	// MutedColor is the color of the weekday names and of the days
+	// outside the displayed month.
+	MutedColor color.NRGBA
+	// SelectedColor is the background of the selected day.
+	SelectedColor color.NRGBA
+	// SelectedTextColor is the text color of the selected day.
+	SelectedTextColor color.NRGBA
+	shaper            text.Shaper
+}
+
+func DatePicker(th *Theme, picker *widget.DatePicker) DatePickerStyle {
+	return DatePickerStyle{
+		Picker:            picker,
+		TextSize:          th.TextSize.Scale(14.0 / 16.0),
+		Color:             th.Palette.Fg,
+		MutedColor:        f32color.MulAlpha(th.Palette.Fg, 0x99),
+		SelectedColor:     th.Palette.ContrastBg,
+		SelectedTextColor: th.Palette.ContrastFg,
+		shaper:            th.Shaper,
+	}
+}
+
+func (d DatePickerStyle) Layout(gtx layout.Context) layout.Dimensions {
+	f := d.Format
+	if f == nil {
+		f = locale.Lookup(gtx.LocaleTag)
+	}
+	header := func(gtx layout.Context) layout.Dimensions {
+		return d.layoutHeader(gtx, f)
+	}
+	weekday := func(gtx layout.Context, wd time.Weekday) layout.Dimensions {
+		l := d.label(f.ShortWeekdays[wd], d.MutedColor)
+		l.Alignment = text.Middle
+		return layout.UniformInset(unit.Dp(4)).Layout(gtx, l.Layout)
+	}
+	day := func(gtx layout.Context, date time.Time, click *widget.Clickable) layout.Dimensions {
+		return d.layoutDay(gtx, f, date, click)
+	}
+	return d.Picker.Layout(gtx, f, header, weekday, day)
+}
+
+func (d DatePickerStyle) layoutHeader(gtx layout.Context, f *locale.Format) layout.Dimensions {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	arrow := func(click *widget.Clickable, txt string) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return Clickable(gtx, click, func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, d.label(txt, d.Color).Layout)
+			})
+		})
+	}
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+		arrow(d.Picker.Prev(), "‹"),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			l := d.label(d.Picker.Header(f), d.Color)
+			l.Alignment = text.Middle
+			l.Font.Weight = text.Medium
+			return l.Layout(gtx)
+		}),
+		arrow(d.Picker.Next(), "›"),
+	)
+}
+
+func (d DatePickerStyle) layoutDay(gtx layout.Context, f *locale.Format, date time.Time, click *widget.Clickable) layout.Dimensions {
+	sz := gtx.Constraints.Min.X
+	if sz > gtx.Constraints.Max.Y {
+		sz = gtx.Constraints.Max.Y
+	}
+	gtx.Constraints = layout.Exact(image.Pt(gtx.Constraints.Min.X, sz))
+	col := d.Color
+	if date.Month() != d.Picker.Month().Month() {
+		col = d.MutedColor
+	}
+	return Clickable(gtx, click, func(gtx layout.Context) layout.Dimensions {
+		if d.Picker.IsSelected(date) {
+			col = d.SelectedTextColor
+			cs := gtx.Constraints.Min
+			x := (cs.X - cs.Y) / 2
+			circle := clip.Ellipse(layout.FRect(image.Rect(x, 0, x+cs.Y, cs.Y)))
+			paint.FillShape(gtx.Ops, d.SelectedColor, circle.Op(gtx.Ops))
+		}
+		return layout.Center.Layout(gtx, d.label(f.FormatInt(int64(date.Day())), col).Layout)
+	})
+}
+
+func (d DatePickerStyle) label(txt string, col color.NRGBA) LabelStyle {
+	return LabelStyle{
+		Text:     txt,
+		Color:    col,
+		TextSize: d.TextSize,
+		MaxLines: 1,
+		shaper:   d.shaper,
+	}
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import "sync"
+
+// styleDefaults are the overrides of the default styles of a Theme.
+type styleDefaults struct {
+	button   []func(*ButtonStyle)
+	editor   []func(*EditorStyle)
+	checkBox []func(*CheckBoxStyle)
+	slider   []func(*SliderStyle)
+	label    []func(*LabelStyle)
+}
+
+// The scratch styles passed to the overrides. Passing the address of
+// the constructed style would move every style to the heap.
+var (
+	buttonScratch   = sync.Pool{New: func() interface{} { return new(ButtonStyle) }}
+	editorScratch   = sync.Pool{New: func() interface{} { return new(EditorStyle) }}
+	checkBoxScratch = sync.Pool{New: func() interface{} { return new(CheckBoxStyle) }}
+	sliderScratch   = sync.Pool{New: func() interface{} { return new(SliderStyle) }}
+	labelScratch    = sync.Pool{New: func() interface{} { return new(LabelStyle) }}
+)
+
+// RegisterButtonDefaults registers f for overriding the default style
+// of the buttons constructed by Button. The overrides run in the order
+// of registration, before the style is returned to the caller. A nil f
+// is ignored.
+//
+// The overrides are not safe to register concurrently with the
+// construction of styles.
+func (t *Theme) RegisterButtonDefaults(f func(*ButtonStyle)) {
+	if f != nil {
+		// Don't share the backing array with copies of t.
+		fs := t.defaults.button
+		t.defaults.button = append(fs[:len(fs):len(fs)], f)
+	}
+}
+
+// RegisterEditorDefaults is like RegisterButtonDefaults for the editors
+// constructed by Editor.
+func (t *Theme) RegisterEditorDefaults(f func(*EditorStyle)) {
+	if f != nil {
+		fs := t.defaults.editor
+		t.defaults.editor = append(fs[:len(fs):len(fs)], f)
+	}
+}
+
+// RegisterCheckBoxDefaults is like RegisterButtonDefaults for the check
+// boxes constructed by CheckBox.
+func (t *Theme) RegisterCheckBoxDefaults(f func(*CheckBoxStyle)) {
+	if f != nil {
+		fs := t.defaults.checkBox
+		t.defaults.checkBox = append(fs[:len(fs):len(fs)], f)
+	}
+}
+
+// RegisterSliderDefaults is like RegisterButtonDefaults for the sliders
+// constructed by Slider.
+func (t *Theme) RegisterSliderDefaults(f func(*SliderStyle)) {
+	if f != nil {
+		fs := t.defaults.slider
+		t.defaults.slider = append(fs[:len(fs):len(fs)], f)
+	}
+}
+
+// RegisterLabelDefaults is like RegisterButtonDefaults for the labels
+// constructed by Label and the typographic constructors such as H1 and
+// Body1.
+func (t *Theme) RegisterLabelDefaults(f func(*LabelStyle)) {
+	if f != nil {
+		fs := t.defaults.label
+		t.defaults.label = append(fs[:len(fs):len(fs)], f)
+	}
+}
+
+func (t *Theme) buttonDefaults(b ButtonStyle) ButtonStyle {
+	if len(t.defaults.button) == 0 {
+		return b
+	}
+	s := buttonScratch.Get().(*ButtonStyle)
+	*s = b
+	for _, f := range t.defaults.button {
+		f(s)
+	}
+	b = *s
+	*s = ButtonStyle{}
+	buttonScratch.Put(s)
+	return b
+}
+
+func (t *Theme) editorDefaults(e EditorStyle) EditorStyle {
+	if len(t.defaults.editor) == 0 {
+		return e
+	}
+	s := editorScratch.Get().(*EditorStyle)
+	*s = e
+	for _, f := range t.defaults.editor {
+		f(s)
+	}
+	e = *s
+	*s = EditorStyle{}
+	editorScratch.Put(s)
+	return e
+}
+
+func (t *Theme) checkBoxDefaults(c CheckBoxStyle) CheckBoxStyle {
+	if len(t.defaults.checkBox) == 0 {
+		return c
+	}
+	s := checkBoxScratch.Get().(*CheckBoxStyle)
+	*s = c
+	for _, f := range t.defaults.checkBox {
+		f(s)
+	}
+	c = *s
+	*s = CheckBoxStyle{}
+	checkBoxScratch.Put(s)
+	return c
+}
+
+func (t *Theme) sliderDefaults(sl SliderStyle) SliderStyle {
+	if len(t.defaults.slider) == 0 {
+		return sl
+	}
+	s := sliderScratch.Get().(*SliderStyle)
+	*s = sl
+	for _, f := range t.defaults.slider {
+		f(s)
+	}
+	sl = *s
+	*s = SliderStyle{}
+	sliderScratch.Put(s)
+	return sl
+}
+
+func (t *Theme) labelDefaults(l LabelStyle) LabelStyle {
+	if len(t.defaults.label) == 0 {
+		return l
+	}
+	s := labelScratch.Get().(*LabelStyle)
+	*s = l
+	for _, f := range t.defaults.label {
+		f(s)
+	}
+	l = *s
+	*s = LabelStyle{}
+	labelScratch.Put(s)
+	return l
+}
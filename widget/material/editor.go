@@ -3,12 +3,16 @@
 package material
 
 import (
+	"image"
 	"image/color"
 
+	"gioui.org/f32"
+	"gioui.org/gesture"
 	"gioui.org/internal/f32color"
 	"gioui.org/io/semantic"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/op/clip"
 	"gioui.org/op/paint"
 	"gioui.org/text"
 	"gioui.org/unit"
@@ -26,24 +30,61 @@ type EditorStyle struct {
 	HintColor color.NRGBA
 	// SelectionColor is the color of the background for selected text.
 	SelectionColor color.NRGBA
+	// CaretColor is the color of the caret, and CaretTextColor the color
+	// of the text under a block caret. A zero CaretColor means Color.
+	CaretColor     color.NRGBA
+	CaretTextColor color.NRGBA
 	Editor         *widget.Editor
+	// BaselineGrid is the grid for the first baseline. Zero disables
+	// snapping.
+	BaselineGrid unit.Value
+	// HandleColor is the color of the selection handles on touch
+	// screens.
+	HandleColor color.NRGBA
+	// MagnifierBackground is the background color of the magnifier
+	// shown while dragging a selection handle.
+	MagnifierBackground color.NRGBA
+	// BarColor and BarBackground are the text and background colors of
+	// the cut, copy and paste bar shown over touch selections.
+	BarColor      color.NRGBA
+	BarBackground color.NRGBA
 
 	shaper text.Shaper
 }
 
 func Editor(th *Theme, editor *widget.Editor, hint string) EditorStyle {
-	return EditorStyle{
+	sel := th.SelectionColor
+	if sel == (color.NRGBA{}) {
+		sel = f32color.MulAlpha(th.Palette.ContrastBg, 0x60)
+	}
+	return th.editorDefaults(EditorStyle{
 		Editor:         editor,
 		TextSize:       th.TextSize,
 		Color:          th.Palette.Fg,
 		shaper:         th.Shaper,
 		Hint:           hint,
 		HintColor:      f32color.MulAlpha(th.Palette.Fg, 0xbb),
-		SelectionColor: f32color.MulAlpha(th.Palette.ContrastBg, 0x60),
-	}
+		SelectionColor: sel,
+		CaretColor:     th.CaretColor,
+		CaretTextColor: th.Palette.Bg,
+		BaselineGrid:   th.BaselineGrid,
+
+		HandleColor:         th.Palette.ContrastBg,
+		MagnifierBackground: th.Palette.Bg,
+		BarColor:            th.Palette.ContrastFg,
+		BarBackground:       th.Palette.ContrastBg,
+	})
 }
 
 func (e EditorStyle) Layout(gtx layout.Context) layout.Dimensions {
+	return layout.SnapBaseline{Grid: e.BaselineGrid}.Layout(gtx, e.layout)
+}
+
+// layout the editor without snapping its baseline.
+func (e EditorStyle) layout(gtx layout.Context) layout.Dimensions {
+	if gtx.IsMeasuring() {
+		return e.measure(gtx)
+	}
 	macro := op.Record(gtx.Ops)
 	paint.ColorOp{Color: e.HintColor}.Add(gtx.Ops)
 	var maxlines int
@@ -71,11 +112,127 @@ func (e EditorStyle) Layout(gtx layout.Context) layout.Dimensions {
 			call.Add(gtx.Ops)
 		}
 		if !disabled {
-			paint.ColorOp{Color: e.Color}.Add(gtx.Ops)
+			caret := e.CaretColor
+			if caret == (color.NRGBA{}) {
+				caret = e.Color
+			}
+			paint.ColorOp{Color: caret}.Add(gtx.Ops)
 			e.Editor.PaintCaret(gtx)
+			paint.ColorOp{Color: e.CaretTextColor}.Add(gtx.Ops)
+			e.Editor.PaintCaretText(gtx)
 		}
 		return dims
 	})
+	if gtx.Queue != nil {
+		e.layoutTouchSelection(gtx)
+	}
+	return dims
+}
+
+// layoutTouchSelection lays out the handles, magnifier and action bar of
+// a touch selection.
+func (e EditorStyle) layoutTouchSelection(gtx layout.Context) {
+	if !e.Editor.TouchSelecting() {
+		return
+	}
+	e.Editor.LayoutHandles(gtx, e.layoutHandle)
+	if pos, ok := e.Editor.Magnifier(); ok {
+		e.layoutMagnifier(gtx, pos)
+	}
+	e.Editor.LayoutSelectionBar(gtx, e.layoutBar)
+}
+
+// layoutHandle draws a teardrop handle, whose sharp corner points to the
+// selection end.
+func (e EditorStyle) layoutHandle(gtx layout.Context, h gesture.Handle) layout.Dimensions {
+	d := gtx.Px(unit.Dp(22))
+	r := float32(d) / 2
+	rr := clip.RRect{
+		Rect: f32.Rectangle{Max: f32.Pt(float32(d), float32(d))},
+		NW:   r, NE: r, SE: r, SW: r,
+	}
+	if h == gesture.HandleStart {
+		rr.NE = 0
+	} else {
+		rr.NW = 0
+	}
+	paint.FillShape(gtx.Ops, e.HandleColor, rr.Op(gtx.Ops))
+	return layout.Dimensions{Size: image.Pt(d, d)}
+}
+
+// layoutMagnifier draws the text around pos enlarged, in a loupe above
+// pos. The loupe is drawn on top of other widgets.
+func (e EditorStyle) layoutMagnifier(gtx layout.Context, pos f32.Point) {
+	const zoom = 1.25
+	sz := layout.FPt(image.Pt(gtx.Px(unit.Dp(100)), gtx.Px(unit.Dp(48))))
+	// Leave room for the magnified line above the finger.
+	gap := float32(gtx.Px(unit.Dp(24)))
+	center := f32.Pt(pos.X, pos.Y-gap-sz.Y/2)
+	m := op.Record(gtx.Ops)
+	b := f32.Rectangle{Min: center.Sub(sz.Mul(.5)), Max: center.Add(sz.Mul(.5))}
+	cl := clip.UniformRRect(b, float32(gtx.Px(unit.Dp(8)))).Push(gtx.Ops)
+	paint.Fill(gtx.Ops, e.MagnifierBackground)
+	t := op.Affine(f32.Affine2D{}.Offset(pos.Mul(-1)).Scale(f32.Point{}, f32.Pt(zoom, zoom)).Offset(center)).Push(gtx.Ops)
+	paint.ColorOp{Color: e.SelectionColor}.Add(gtx.Ops)
+	e.Editor.PaintSelection(gtx)
+	paint.ColorOp{Color: e.Color}.Add(gtx.Ops)
+	e.Editor.PaintText(gtx)
+	t.Pop()
+	cl.Pop()
+	op.Defer(gtx.Ops, m.Stop())
+}
+
+// layoutBar draws the bar of selection actions.
+func (e EditorStyle) layoutBar(gtx layout.Context, cut, copy, paste *widget.Clickable) layout.Dimensions {
+	action := func(c *widget.Clickable, label string) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				semantic.Button.Add(gtx.Ops)
+				return layout.Inset{
+					Top: unit.Dp(10), Bottom: unit.Dp(10),
+					Left: unit.Dp(12), Right: unit.Dp(12),
+				}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					paint.ColorOp{Color: e.BarColor}.Add(gtx.Ops)
+					return widget.Label{}.Layout(gtx, e.shaper, e.Font, e.TextSize.Scale(14.0/16.0), label)
+				})
+			})
+		})
+	}
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			rr := float32(gtx.Px(unit.Dp(4)))
+			defer clip.UniformRRect(f32.Rectangle{Max: layout.FPt(gtx.Constraints.Min)}, rr).Push(gtx.Ops).Pop()
+			paint.Fill(gtx.Ops, e.BarBackground)
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx,
+				action(cut, "Cut"),
+				action(copy, "Copy"),
+				action(paste, "Paste"),
+			)
+		}),
+	)
+}
+
+// measure is like Layout for measuring contexts.
+func (e EditorStyle) measure(gtx layout.Context) layout.Dimensions {
+	var maxlines int
+	if e.Editor.SingleLine {
+		maxlines = 1
+	}
+	tl := widget.Label{Alignment: e.Editor.Alignment, MaxLines: maxlines}
+	dims := tl.Layout(gtx, e.shaper, e.Font, e.TextSize, e.Hint)
+	if w := dims.Size.X; gtx.Constraints.Min.X < w {
+		gtx.Constraints.Min.X = w
+	}
+	if h := dims.Size.Y; gtx.Constraints.Min.Y < h {
+		gtx.Constraints.Min.Y = h
+	}
+	edims := e.Editor.Layout(gtx, e.shaper, e.Font, e.TextSize, nil)
+	if e.Editor.Len() > 0 {
+		return edims
+	}
 	return dims
 }
 
@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/internal/f32color"
+	"gioui.org/internal/ops"
+	"gioui.org/io/router"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+func TestEditorBlockCaret(t *testing.T) {
+	var (
+		ops    op.Ops
+		r      router.Router
+		editor widget.Editor
+	)
+	th := material.NewTheme(gofont.Collection())
+	th.SelectionColor = color.NRGBA{R: 0xff, A: 0x60}
+	th.CaretColor = color.NRGBA{G: 0xff, A: 0xff}
+	gtx := layout.NewContext(&ops, system.FrameEvent{
+		Now:    time.Unix(1, 0),
+		Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Size:   image.Pt(300, 100),
+		Queue:  &r,
+	})
+	editor.BlockCaret = true
+	editor.SetText("abc")
+	editor.Focus()
+	style := material.Editor(th, &editor, "")
+	frame := func() {
+		ops.Reset()
+		style.Layout(gtx)
+		r.Frame(gtx.Ops)
+	}
+	frame()
+	// Select "bc", with the caret before "b".
+	editor.SetCaret(1, 3)
+	frame()
+
+	var sel, caret, inverted []image.Rectangle
+	for _, p := range decodePaints(&ops) {
+		switch p.color {
+		case th.SelectionColor:
+			sel = append(sel, p.bounds)
+		case th.CaretColor:
+			caret = append(caret, p.bounds)
+		case style.CaretTextColor:
+			inverted = append(inverted, p.bounds)
+		}
+	}
+	if len(sel) != 1 || len(caret) != 1 {
+		t.Fatalf("got selection %v and caret %v; expected one of each", sel, caret)
+	}
+	s, c := sel[0], caret[0]
+	if pos := editor.CaretCoords(); c.Min.X != s.Min.X || c.Min.X != int(pos.X) {
+		t.Errorf("caret %v doesn't start at the selection %v and caret position %v", c, s, pos)
+	}
+	if c.Min.Y != s.Min.Y || c.Max.Y != s.Max.Y {
+		t.Errorf("caret %v doesn't span the line of the selection %v", c, s)
+	}
+	// The block covers "b" of the selection "bc".
+	if w := c.Dx(); w < 2 || w >= s.Dx() {
+		t.Errorf("got block caret width %d; expected the width of a single rune of %d", w, s.Dx())
+	}
+	// The glyph under the block is painted in the inverted color, and
+	// after the caret.
+	if len(inverted) == 0 {
+		t.Fatal("no inverted glyph under the block caret")
+	}
+	for _, b := range inverted {
+		if !b.In(c) {
+			t.Errorf("inverted glyph %v outside the caret %v", b, c)
+		}
+	}
+
+	// Line carets snap their width to whole pixels.
+	editor.BlockCaret = false
+	editor.CaretWidth = unit.Dp(1.6)
+	gtx.Metric = unit.Metric{PxPerDp: 2.5, PxPerSp: 2.5}
+	frame()
+	caret = caret[:0]
+	for _, p := range decodePaints(&ops) {
+		switch p.color {
+		case th.CaretColor:
+			caret = append(caret, p.bounds)
+		case style.CaretTextColor:
+			t.Errorf("line caret painted inverted text at %v", p.bounds)
+		}
+	}
+	if len(caret) != 1 || caret[0].Dx() != 4 {
+		t.Errorf("got caret %v; expected one caret 4 pixels wide", caret)
+	}
+}
+
+func TestEditorDefaultColors(t *testing.T) {
+	var (
+		ops    op.Ops
+		r      router.Router
+		editor widget.Editor
+	)
+	th := material.NewTheme(gofont.Collection())
+	gtx := layout.NewContext(&ops, system.FrameEvent{
+		Now:    time.Unix(1, 0),
+		Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Size:   image.Pt(300, 100),
+		Queue:  &r,
+	})
+	editor.SetText("abc")
+	editor.Focus()
+	style := material.Editor(th, &editor, "")
+	if exp := f32color.MulAlpha(th.Palette.ContrastBg, 0x60); style.SelectionColor != exp {
+		t.Errorf("got selection color %v; expected %v", style.SelectionColor, exp)
+	}
+	// The caret follows the text color.
+	style.Color = color.NRGBA{B: 0xff, A: 0xff}
+	frame := func() {
+		ops.Reset()
+		style.Layout(gtx)
+		r.Frame(gtx.Ops)
+	}
+	frame()
+	editor.SetCaret(1, 1)
+	frame()
+	carets := 0
+	for _, p := range decodePaints(&ops) {
+		if p.color == style.Color && p.bounds.Dx() <= 2 {
+			carets++
+		}
+	}
+	if carets != 1 {
+		t.Errorf("got %d carets in the text color; expected 1", carets)
+	}
+}
+
+type paintRecord struct {
+	color  color.NRGBA
+	bounds image.Rectangle
+}
+
+// decodePaints returns the colors and clip bounds of the paint
+// operations of o. Only translations are supported.
+func decodePaints(o *op.Ops) []paintRecord {
+	var (
+		rd      ops.Reader
+		paints  []paintRecord
+		col     color.NRGBA
+		trans   f32.Affine2D
+		transes []f32.Affine2D
+		clips   []image.Rectangle
+	)
+	clips = append(clips, image.Rect(-1e6, -1e6, 1e6, 1e6))
+	rd.Reset(&o.Internal)
+	for {
+		encOp, ok := rd.Decode()
+		if !ok {
+			break
+		}
+		switch ops.OpType(encOp.Data[0]) {
+		case ops.TypeColor:
+			col = color.NRGBA{R: encOp.Data[1], G: encOp.Data[2], B: encOp.Data[3], A: encOp.Data[4]}
+		case ops.TypeTransform:
+			t, push := ops.DecodeTransform(encOp.Data)
+			if push {
+				transes = append(transes, trans)
+			}
+			trans = trans.Mul(t)
+		case ops.TypePopTransform:
+			trans = transes[len(transes)-1]
+			transes = transes[:len(transes)-1]
+		case ops.TypeClip:
+			var c ops.ClipOp
+			c.Decode(encOp.Data)
+			off := trans.Transform(f32.Point{})
+			b := c.Bounds.Add(image.Pt(int(off.X), int(off.Y)))
+			clips = append(clips, clips[len(clips)-1].Intersect(b))
+		case ops.TypePopClip:
+			clips = clips[:len(clips)-1]
+		case ops.TypePaint:
+			paints = append(paints, paintRecord{color: col, bounds: clips[len(clips)-1]})
+		}
+	}
+	return paints
+}
@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"gioui.org/widget"
+)
+
+// errorColor is the color of validation errors.
+var errorColor = rgb(0xb00020)
+
+// FieldError returns a caption with the validation error of field, as
+// shown by field.Error.
+func FieldError(th *Theme, field *widget.Field) LabelStyle {
+	l := Caption(th, field.Error())
+	l.Color = errorColor
+	return l
+}
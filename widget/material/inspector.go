@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"strings"
+
+	"gioui.org/f32"
+	"gioui.org/internal/f32color"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// InspectorStyle draws a debug overlay that highlights the selected area
+// under an inspecting pointer, and lists the tags of the hits. While
+// Inspecting, the hits are listed in a side panel with their bounds and
+// semantic descriptions.
+type InspectorStyle struct {
+	Inspector *widget.Inspector
+	// Highlight is the color of the selected hit area, and the
+	// background of its entry in the side panel.
+	Highlight  color.NRGBA
+	Label      LabelStyle
+	Background color.NRGBA
+}
+
+func Inspector(th *Theme, in *widget.Inspector) InspectorStyle {
+	l := Caption(th, "")
+	l.Color = th.Palette.ContrastFg
+	return InspectorStyle{
+		Inspector:  in,
+		Highlight:  f32color.MulAlpha(th.Palette.ContrastBg, 0x60),
+		Label:      l,
+		Background: f32color.MulAlpha(th.Palette.Fg, 0xcc),
+	}
+}
+
+// Layout w and the overlay.
+func (s InspectorStyle) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	dims := s.Inspector.Layout(gtx, w)
+	hits := s.Inspector.Hits()
+	if len(hits) == 0 {
+		return dims
+	}
+	sel := s.Inspector.Selected()
+	paint.FillShape(gtx.Ops, s.Highlight, clip.Rect(pixelBounds(hits[sel].Bounds)).Op())
+	if s.Inspector.Inspecting() {
+		s.layoutPanel(gtx, dims.Size)
+		return dims
+	}
+
+	var lines []string
+	for _, h := range hits {
+		lines = append(lines, hitTag(h))
+	}
+	l := s.Label
+	l.Text = strings.Join(lines, "\n")
+	m := op.Record(gtx.Ops)
+	gtx.Constraints.Min = image.Point{}
+	ldims := layout.UniformInset(unit.Dp(4)).Layout(gtx, l.Layout)
+	call := m.Stop()
+	// Place the list below and to the right of the pointer, inside the
+	// inspected widget.
+	pos := s.Inspector.Position().Add(f32.Pt(12, 12))
+	if max := float32(dims.Size.X - ldims.Size.X); pos.X > max {
+		pos.X = max
+	}
+	if max := float32(dims.Size.Y - ldims.Size.Y); pos.Y > max {
+		pos.Y = max
+	}
+	defer op.Offset(pos).Push(gtx.Ops).Pop()
+	paint.FillShape(gtx.Ops, s.Background, clip.Rect{Max: ldims.Size}.Op())
+	call.Add(gtx.Ops)
+	return dims
+}
+
+// layoutPanel lays out the side panel of the hits, on the side of size
+// away from the pointer.
+func (s InspectorStyle) layoutPanel(gtx layout.Context, size image.Point) {
+	hits := s.Inspector.Hits()
+	sel := s.Inspector.Selected()
+	children := make([]layout.FlexChild, len(hits))
+	for i, h := range hits {
+		l := s.Label
+		l.Text = hitTag(h) + "\n" + hitDetails(h)
+		selected := i == sel
+		children[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			m := op.Record(gtx.Ops)
+			dims := layout.UniformInset(unit.Dp(4)).Layout(gtx, l.Layout)
+			call := m.Stop()
+			if selected {
+				paint.FillShape(gtx.Ops, s.Highlight, clip.Rect{Max: dims.Size}.Op())
+			}
+			call.Add(gtx.Ops)
+			return dims
+		})
+	}
+	width := size.X / 3
+	var x int
+	if s.Inspector.Position().X < float32(size.X)/2 {
+		x = size.X - width
+	}
+	defer op.Offset(f32.Pt(float32(x), 0)).Push(gtx.Ops).Pop()
+	rect := image.Pt(width, size.Y)
+	defer clip.Rect{Max: rect}.Push(gtx.Ops).Pop()
+	paint.Fill(gtx.Ops, s.Background)
+	gtx.Constraints = layout.Exact(rect)
+	layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+// hitTag describes the tag of h.
+func hitTag(h router.Hit) string {
+	var line string
+	switch v := reflect.ValueOf(h.Tag); v.Kind() {
+	case reflect.Invalid:
+		line = "area"
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		line = fmt.Sprintf("%T %p", h.Tag, h.Tag)
+	default:
+		line = fmt.Sprintf("%T %v", h.Tag, h.Tag)
+	}
+	if h.Semantic != 0 {
+		line += fmt.Sprintf(" #%d", h.Semantic)
+	}
+	return line
+}
+
+// hitDetails describes the bounds and semantic description of h.
+func hitDetails(h router.Hit) string {
+	b := pixelBounds(h.Bounds)
+	line := fmt.Sprintf("%v %dx%d", b.Min, b.Dx(), b.Dy())
+	if h.Semantic == 0 {
+		return line
+	}
+	d := h.Desc
+	line += "\n" + d.Class.String()
+	if d.Label != "" {
+		line += fmt.Sprintf(" %q", d.Label)
+	}
+	if d.Description != "" {
+		line += fmt.Sprintf(" (%s)", d.Description)
+	}
+	if d.Disabled {
+		line += " disabled"
+	}
+	if d.Selected {
+		line += " selected"
+	}
+	return line
+}
+
+// pixelBounds returns the smallest integer rectangle covering r.
+func pixelBounds(r f32.Rectangle) image.Rectangle {
+	return image.Rectangle{
+		Min: image.Pt(int(math.Floor(float64(r.Min.X))), int(math.Floor(float64(r.Min.Y)))),
+		Max: image.Pt(int(math.Ceil(float64(r.Max.X))), int(math.Ceil(float64(r.Max.Y)))),
+	}
+}
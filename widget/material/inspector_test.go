@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"testing"
+
+	"gioui.org/io/router"
+)
+
+func TestHitTag(t *testing.T) {
+	type tag struct{ id int }
+	for _, tc := range []struct {
+		tag interface{}
+		exp string
+	}{
+		{nil, "area"},
+		{"menu", "string menu"},
+		{tag{7}, "material.tag {7}"},
+	} {
+		if got := hitTag(router.Hit{Tag: tc.tag}); got != tc.exp {
+			t.Errorf("tag %#v: got %q, expected %q", tc.tag, got, tc.exp)
+		}
+	}
+}
@@ -23,77 +23,100 @@ type LabelStyle struct {
 	MaxLines int
 	Text     string
 	TextSize unit.Value
+	// AutoTooltip, if non-nil, shows the full text in a tooltip when
+	// the text is truncated. See widget.Label.
+	AutoTooltip *widget.Tooltip
+	// BaselineGrid is the grid for the first baseline. Zero disables
+	// snapping.
+	BaselineGrid unit.Value
 
 	shaper text.Shaper
+	theme  *Theme
 }
 
 func H1(th *Theme, txt string) LabelStyle {
-	label := Label(th, th.TextSize.Scale(96.0/16.0), txt)
-	label.Font.Weight = text.Light
-	return label
+	l := label(th, th.TextSize.Scale(96.0/16.0), txt)
+	l.Font.Weight = text.Light
+	return th.labelDefaults(l)
 }
 
 func H2(th *Theme, txt string) LabelStyle {
-	label := Label(th, th.TextSize.Scale(60.0/16.0), txt)
-	label.Font.Weight = text.Light
-	return label
+	l := label(th, th.TextSize.Scale(60.0/16.0), txt)
+	l.Font.Weight = text.Light
+	return th.labelDefaults(l)
 }
 
 func H3(th *Theme, txt string) LabelStyle {
-	return Label(th, th.TextSize.Scale(48.0/16.0), txt)
+	return th.labelDefaults(label(th, th.TextSize.Scale(48.0/16.0), txt))
 }
 
 func H4(th *Theme, txt string) LabelStyle {
-	return Label(th, th.TextSize.Scale(34.0/16.0), txt)
+	return th.labelDefaults(label(th, th.TextSize.Scale(34.0/16.0), txt))
 }
 
 func H5(th *Theme, txt string) LabelStyle {
-	return Label(th, th.TextSize.Scale(24.0/16.0), txt)
+	return th.labelDefaults(label(th, th.TextSize.Scale(24.0/16.0), txt))
 }
 
 func H6(th *Theme, txt string) LabelStyle {
-	label := Label(th, th.TextSize.Scale(20.0/16.0), txt)
-	label.Font.Weight = text.Medium
-	return label
+	l := label(th, th.TextSize.Scale(20.0/16.0), txt)
+	l.Font.Weight = text.Medium
+	return th.labelDefaults(l)
 }
 
 func Subtitle1(th *Theme, txt string) LabelStyle {
-	return Label(th, th.TextSize.Scale(16.0/16.0), txt)
+	return th.labelDefaults(label(th, th.TextSize.Scale(16.0/16.0), txt))
 }
 
 func Subtitle2(th *Theme, txt string) LabelStyle {
-	label := Label(th, th.TextSize.Scale(14.0/16.0), txt)
-	label.Font.Weight = text.Medium
-	return label
+	l := label(th, th.TextSize.Scale(14.0/16.0), txt)
+	l.Font.Weight = text.Medium
+	return th.labelDefaults(l)
 }
 
 func Body1(th *Theme, txt string) LabelStyle {
-	return Label(th, th.TextSize, txt)
+	return th.labelDefaults(label(th, th.TextSize, txt))
 }
 
 func Body2(th *Theme, txt string) LabelStyle {
-	return Label(th, th.TextSize.Scale(14.0/16.0), txt)
+	return th.labelDefaults(label(th, th.TextSize.Scale(14.0/16.0), txt))
 }
 
 func Caption(th *Theme, txt string) LabelStyle {
-	return Label(th, th.TextSize.Scale(12.0/16.0), txt)
+	return th.labelDefaults(label(th, th.TextSize.Scale(12.0/16.0), txt))
 }
 
 func Overline(th *Theme, txt string) LabelStyle {
-	return Label(th, th.TextSize.Scale(10.0/16.0), txt)
+	return th.labelDefaults(label(th, th.TextSize.Scale(10.0/16.0), txt))
 }
 
 func Label(th *Theme, size unit.Value, txt string) LabelStyle {
+	return th.labelDefaults(label(th, size, txt))
+}
+
+// label returns the default style of a label, without the overrides of
+// th.
+func label(th *Theme, size unit.Value, txt string) LabelStyle {
 	return LabelStyle{
-		Text:     txt,
-		Color:    th.Palette.Fg,
-		TextSize: size,
-		shaper:   th.Shaper,
+		Text:         txt,
+		Color:        th.Palette.Fg,
+		TextSize:     size,
+		shaper:       th.Shaper,
+		theme:        th,
+		BaselineGrid: th.BaselineGrid,
 	}
 }
 
 func (l LabelStyle) Layout(gtx layout.Context) layout.Dimensions {
-	paint.ColorOp{Color: l.Color}.Add(gtx.Ops)
-	tl := widget.Label{Alignment: l.Alignment, MaxLines: l.MaxLines}
-	return tl.Layout(gtx, l.shaper, l.Font, l.TextSize, l.Text)
+	if !gtx.IsMeasuring() {
+		paint.ColorOp{Color: l.Color}.Add(gtx.Ops)
+	}
+	tl := widget.Label{Alignment: l.Alignment, MaxLines: l.MaxLines, AutoTooltip: l.AutoTooltip}
+	dims := layout.SnapBaseline{Grid: l.BaselineGrid}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return tl.Layout(gtx, l.shaper, l.Font, l.TextSize, l.Text)
+	})
+	if t := l.AutoTooltip; t != nil && t.Visible() && l.theme != nil {
+		Tooltip(l.theme, t, t.Text()).Layout(gtx)
+	}
+	return dims
 }
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material_test
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/font/gofont"
+	"gioui.org/io/router"
+	"gioui.org/io/size"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+func TestLabelSizeEvent(t *testing.T) {
+	var (
+		ops op.Ops
+		r   router.Router
+		tag int
+	)
+	th := material.NewTheme(gofont.Collection())
+	frame := func(txt string) layout.Dimensions {
+		ops.Reset()
+		gtx := layout.NewContext(&ops, system.FrameEvent{
+			Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+			Size:   image.Pt(500, 500),
+			Queue:  &r,
+		})
+		gtx.Constraints.Min = image.Point{}
+		dims := layout.Observe(gtx, &tag, material.Body1(th, txt).Layout)
+		r.Frame(gtx.Ops)
+		return dims
+	}
+	short := frame("Hi")
+	if evts := r.Events(&tag); len(evts) != 0 {
+		t.Fatalf("first layout delivered %v", evts)
+	}
+	long := frame("Hello, world")
+	if long.Size.X <= short.Size.X {
+		t.Fatalf("longer text is not wider: %v, %v", short.Size, long.Size)
+	}
+	evts := r.Events(&tag)
+	if len(evts) != 1 {
+		t.Fatalf("got %d events, expected 1: %v", len(evts), evts)
+	}
+	e, ok := evts[0].(size.Event)
+	if !ok {
+		t.Fatalf("got %T, expected size.Event", evts[0])
+	}
+	if e.Old != short.Size || e.New != long.Size {
+		t.Errorf("got %v -> %v, expected %v -> %v", e.Old, e.New, short.Size, long.Size)
+	}
+	if d := e.New.Sub(e.Old); d.X <= 0 || d.Y != 0 {
+		t.Errorf("unexpected size delta %v", d)
+	}
+	frame("Hello, world")
+	if evts := r.Events(&tag); len(evts) != 0 {
+		t.Errorf("unchanged text delivered %v", evts)
+	}
+}
+
+func TestLabelBaselineGrid(t *testing.T) {
+	th := material.NewTheme(gofont.Collection())
+	th.BaselineGrid = unit.Dp(4)
+	var ops op.Ops
+	gtx := layout.NewContext(&ops, system.FrameEvent{
+		Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Size:   image.Pt(1000, 2000),
+	})
+	gtx.Constraints.Min = image.Point{}
+	labels := []material.LabelStyle{
+		material.H1(th, "Headline"),
+		material.Body1(th, "Body"),
+		material.Caption(th, "Caption"),
+		material.H5(th, "Title"),
+		material.Overline(th, "Overline"),
+		material.Body2(th, "Two\nlines"),
+	}
+	var baselines []int
+	var children []layout.FlexChild
+	y := 0
+	for _, l := range labels {
+		l := l
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			dims := l.Layout(gtx)
+			baselines = append(baselines, y+dims.Size.Y-dims.Baseline)
+			y += dims.Size.Y
+			return dims
+		}))
+	}
+	layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	if len(baselines) != len(labels) {
+		t.Fatalf("got %d baselines, expected %d", len(baselines), len(labels))
+	}
+	for i, b := range baselines {
+		if b%4 != 0 {
+			t.Errorf("label %d: baseline at %d is off the grid", i, b)
+		}
+	}
+	// Without the grid, some baselines are off the grid.
+	off := false
+	for _, l := range labels {
+		l.BaselineGrid = unit.Value{}
+		dims := l.Layout(gtx)
+		if (dims.Size.Y-dims.Baseline)%4 != 0 || dims.Size.Y%4 != 0 {
+			off = true
+		}
+	}
+	if !off {
+		t.Error("every label is on the grid without snapping")
+	}
+}
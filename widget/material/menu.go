@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/internal/f32color"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// MenuStyle lays out a widget.Menu and its submenus as panels of items
+// with check marks, shortcuts and submenu arrows.
+type MenuStyle struct {
+	Menu     *widget.Menu
+	TextSize unit.Value
+	// Color is the text color.
+	Color color.NRGBA
+	// HintColor is the color of shortcuts and disabled items.
+	HintColor  color.NRGBA
+	Background color.NRGBA
+	// SelectedColor is the background of the selected item.
+	SelectedColor color.NRGBA
+	// MinWidth is the minimum width of a menu panel.
+	MinWidth unit.Value
+	shaper   text.Shaper
+}
+
+func Menu(th *Theme, menu *widget.Menu) MenuStyle {
+	return MenuStyle{
+		Menu:          menu,
+		TextSize:      th.TextSize.Scale(14.0 / 16.0),
+		Color:         th.Palette.Fg,
+		HintColor:     f32color.MulAlpha(th.Palette.Fg, 0x99),
+		Background:    th.Palette.Bg,
+		SelectedColor: f32color.MulAlpha(th.Palette.ContrastBg, 0x44),
+		MinWidth:      unit.Dp(112),
+		shaper:        th.Shaper,
+	}
+}
+
+// Layout the menu cascade in the available space, which is usually the
+// whole window. Nothing is laid out if the menu is closed.
+func (m MenuStyle) Layout(gtx layout.Context) layout.Dimensions {
+	return m.Menu.Layout(gtx, m.layoutPanel)
+}
+
+func (m MenuStyle) layoutPanel(gtx layout.Context, menu *widget.Menu) layout.Dimensions {
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			sz := gtx.Constraints.Min
+			rr := float32(gtx.Px(unit.Dp(4)))
+			defer clip.UniformRRect(layout.FRect(image.Rectangle{Max: sz}), rr).Push(gtx.Ops).Pop()
+			paint.Fill(gtx.Ops, m.Background)
+			return layout.Dimensions{Size: sz}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8)}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				if w := gtx.Px(m.MinWidth); gtx.Constraints.Min.X < w {
+					gtx.Constraints.Min.X = w
+				}
+				return layout.Stack{}.Layout(gtx,
+					layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+						return menu.LayoutItems(gtx, func(gtx layout.Context, i int) layout.Dimensions {
+							return m.layoutItem(gtx, menu, i)
+						})
+					}),
+					layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+						return m.layoutArrows(gtx, menu)
+					}),
+				)
+			})
+		}),
+	)
+}
+
+// layoutArrows lays out the edge arrows of a scrollable menu.
+func (m MenuStyle) layoutArrows(gtx layout.Context, menu *widget.Menu) layout.Dimensions {
+	sz := gtx.Constraints.Min
+	for _, up := range []bool{true, false} {
+		if !menu.CanScroll(up) {
+			continue
+		}
+		up := up
+		h := gtx.Px(m.TextSize)
+		dir := layout.N
+		if !up {
+			dir = layout.S
+		}
+		gtx := gtx
+		gtx.Constraints = layout.Exact(sz)
+		dir.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return menu.ScrollArrow(up).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				asz := image.Pt(sz.X, h)
+				defer clip.Rect{Max: asz}.Push(gtx.Ops).Pop()
+				paint.Fill(gtx.Ops, m.Background)
+				paint.ColorOp{Color: m.HintColor}.Add(gtx.Ops)
+				trans := op.Offset(layout.FPt(image.Pt((sz.X-h)/2, 0))).Push(gtx.Ops)
+				if up {
+					triangle(gtx, h, 0)
+				} else {
+					triangle(gtx, h, 2)
+				}
+				trans.Pop()
+				return layout.Dimensions{Size: asz}
+			})
+		})
+	}
+	return layout.Dimensions{Size: sz}
+}
+
+func (m MenuStyle) layoutItem(gtx layout.Context, menu *widget.Menu, i int) layout.Dimensions {
+	it := menu.Items[i]
+	col := m.Color
+	if it.Disabled {
+		gtx = gtx.Disabled()
+		col = m.HintColor
+	}
+	return menu.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+				sz := gtx.Constraints.Min
+				if i == menu.Selected() && !gtx.IsMeasuring() {
+					defer clip.Rect{Max: sz}.Push(gtx.Ops).Pop()
+					paint.Fill(gtx.Ops, m.SelectedColor)
+				}
+				return layout.Dimensions{Size: sz}
+			}),
+			layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+				inset := layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(8), Right: unit.Dp(8)}
+				return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.Y = 0
+					return layout.Flex{Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									return m.layoutGlyph(gtx, it, col)
+								}),
+								layout.Rigid(m.label(it.Title, m.TextSize, col).Layout),
+							)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									s := it.Shortcut.String()
+									if s == "" {
+										return layout.Dimensions{}
+									}
+									return layout.Inset{Left: unit.Dp(24)}.Layout(gtx,
+										m.label(s, m.TextSize, m.HintColor).Layout,
+									)
+								}),
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									h := gtx.Px(m.TextSize)
+									if it.Submenu != nil && !gtx.IsMeasuring() {
+										paint.ColorOp{Color: col}.Add(gtx.Ops)
+										triangle(gtx, h, 1)
+									}
+									return layout.Dimensions{Size: image.Pt(h, h)}
+								}),
+							)
+						}),
+					)
+				})
+			}),
+		)
+	})
+}
+
+// layoutGlyph lays out the check mark or radio dot of checked items, and
+// reserves space for it otherwise.
+func (m MenuStyle) layoutGlyph(gtx layout.Context, it widget.MenuItem, col color.NRGBA) layout.Dimensions {
+	h := gtx.Px(m.TextSize)
+	sz := image.Pt(h+gtx.Px(unit.Dp(8)), h)
+	if !it.Checked() || gtx.IsMeasuring() {
+		return layout.Dimensions{Size: sz}
+	}
+	h32 := float32(h)
+	paint.ColorOp{Color: col}.Add(gtx.Ops)
+	if it.Bool != nil {
+		var p clip.Path
+		p.Begin(gtx.Ops)
+		p.MoveTo(f32.Pt(h32*.15, h32*.55))
+		p.LineTo(f32.Pt(h32*.4, h32*.8))
+		p.LineTo(f32.Pt(h32*.85, h32*.25))
+		st := clip.Stroke{
+			Path:  p.End(),
+			Width: h32 * .12,
+		}.Op().Push(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		st.Pop()
+	} else {
+		st := clip.Ellipse(f32.Rect(h32*.3, h32*.3, h32*.7, h32*.7)).Push(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		st.Pop()
+	}
+	return layout.Dimensions{Size: sz}
+}
+
+func (m MenuStyle) label(txt string, size unit.Value, col color.NRGBA) LabelStyle {
+	return LabelStyle{
+		Text:     txt,
+		Color:    col,
+		TextSize: size,
+		MaxLines: 1,
+		shaper:   m.shaper,
+	}
+}
+
+// triangle fills a triangle in a square of size h, pointing up, right,
+// down or left for quarter turns 0 to 3.
+func triangle(gtx layout.Context, h int, quarterTurns int) {
+	h32 := float32(h)
+	pts := [...]f32.Point{{X: .3, Y: .65}, {X: .5, Y: .4}, {X: .7, Y: .65}}
+	rot := f32.Affine2D{}.Rotate(f32.Pt(.5, .5), float32(quarterTurns)*math.Pi/2)
+	var p clip.Path
+	p.Begin(gtx.Ops)
+	for i, pt := range pts {
+		pt = rot.Transform(pt).Mul(h32)
+		if i == 0 {
+			p.MoveTo(pt)
+		} else {
+			p.LineTo(pt)
+		}
+	}
+	p.Close()
+	st := clip.Outline{Path: p.End()}.Op().Push(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	st.Pop()
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// MenuBarStyle lays out a widget.MenuBar above the window content, with
+// its open menu in the style of Menu.
+type MenuBarStyle struct {
+	MenuBar *widget.MenuBar
+	// Menu styles the open menu. Its Menu field is ignored.
+	Menu       MenuStyle
+	TextSize   unit.Value
+	Color      color.NRGBA
+	Background color.NRGBA
+	// SelectedColor is the background of the title of the open menu.
+	SelectedColor color.NRGBA
+}
+
+func MenuBar(th *Theme, bar *widget.MenuBar) MenuBarStyle {
+	ms := Menu(th, nil)
+	return MenuBarStyle{
+		MenuBar:       bar,
+		Menu:          ms,
+		TextSize:      ms.TextSize,
+		Color:         ms.Color,
+		Background:    ms.Background,
+		SelectedColor: ms.SelectedColor,
+	}
+}
+
+// Layout the menu bar above the content w, in the available space, which
+// is usually the whole window. Programs handle the key events not handled
+// by the content with MenuBar.Dispatch, for the keyboard shortcuts of the
+// menu bar.
+func (b MenuBarStyle) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	bar := b.MenuBar
+	bar.Update(gtx)
+	dims := layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(b.layoutBar),
+		layout.Flexed(1, w),
+	)
+	if i := bar.Opened(); i != -1 {
+		ms := b.Menu
+		ms.Menu = bar.Item(i).Submenu
+		ms.Layout(gtx)
+	}
+	return dims
+}
+
+func (b MenuBarStyle) layoutBar(gtx layout.Context) layout.Dimensions {
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			sz := image.Pt(gtx.Constraints.Max.X, gtx.Constraints.Min.Y)
+			defer clip.Rect{Max: sz}.Push(gtx.Ops).Pop()
+			paint.Fill(gtx.Ops, b.Background)
+			return layout.Dimensions{Size: sz}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			return b.MenuBar.LayoutTitles(gtx, b.layoutTitle)
+		}),
+	)
+}
+
+func (b MenuBarStyle) layoutTitle(gtx layout.Context, i int) layout.Dimensions {
+	bar := b.MenuBar
+	it := bar.Item(i)
+	col := b.Color
+	if it.Disabled {
+		gtx = gtx.Disabled()
+		col = b.Menu.HintColor
+	}
+	return bar.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+				sz := gtx.Constraints.Min
+				if i == bar.Opened() {
+					defer clip.Rect{Max: sz}.Push(gtx.Ops).Pop()
+					paint.Fill(gtx.Ops, b.SelectedColor)
+				}
+				return layout.Dimensions{Size: sz}
+			}),
+			layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+				inset := layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(8), Right: unit.Dp(8)}
+				return inset.Layout(gtx, b.Menu.label(it.Title, b.TextSize, col).Layout)
+			}),
+		)
+	})
+}
@@ -5,10 +5,13 @@ package material
 import (
 	"image"
 	"image/color"
+	"time"
 
+	"gioui.org/anim"
 	"gioui.org/f32"
 	"gioui.org/internal/f32color"
 	"gioui.org/layout"
+	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
 	"gioui.org/unit"
@@ -18,8 +21,13 @@ type ProgressBarStyle struct {
 	Color      color.NRGBA
 	TrackColor color.NRGBA
 	Progress   float32
+	// Animation, if set, animates changes to Progress.
+	Animation *anim.Value[float32]
 }
 
+// progressAnimDuration is the duration of Progress animations.
+const progressAnimDuration = 150 * time.Millisecond
+
 func ProgressBar(th *Theme, progress float32) ProgressBarStyle {
 	return ProgressBarStyle{
 		Progress:   progress,
@@ -43,13 +51,23 @@ func (p ProgressBarStyle) Layout(gtx layout.Context) layout.Dimensions {
 		return layout.Dimensions{Size: d}
 	}
 
+	progress := clamp1(p.Progress)
+	if a := p.Animation; a != nil {
+		if a.Target() != progress {
+			a.Set(gtx.Now, progress, progressAnimDuration, anim.EaseOut)
+		}
+		progress = a.Get(gtx.Now)
+		if a.Animating(gtx.Now) {
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
 	progressBarWidth := float32(gtx.Constraints.Max.X)
 	return layout.Stack{Alignment: layout.W}.Layout(gtx,
 		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
 			return shader(progressBarWidth, p.TrackColor)
 		}),
 		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
-			fillWidth := progressBarWidth * clamp1(p.Progress)
+			fillWidth := progressBarWidth * progress
 			fillColor := p.Color
 			if gtx.Queue == nil {
 				fillColor = f32color.Disabled(fillColor)
@@ -27,6 +27,7 @@ func RadioButton(th *Theme, group *widget.Enum, key, label string) RadioButtonSt
 			IconColor:          th.Palette.ContrastBg,
 			TextSize:           th.TextSize.Scale(14.0 / 16.0),
 			Size:               unit.Dp(26),
+			MinTouchTarget:     th.MinTouchTarget,
 			shaper:             th.Shaper,
 			checkedStateIcon:   th.Icon.RadioChecked,
 			uncheckedStateIcon: th.Icon.RadioUnchecked,
@@ -39,6 +40,7 @@ func RadioButton(th *Theme, group *widget.Enum, key, label string) RadioButtonSt
 func (r RadioButtonStyle) Layout(gtx layout.Context) layout.Dimensions {
 	hovered, hovering := r.Group.Hovered()
 	focus, focused := r.Group.Focused()
+	defer expandTouchTarget(gtx, r.MinTouchTarget).Pop()
 	return r.Group.Layout(gtx, r.Key, func(gtx layout.Context) layout.Dimensions {
 		semantic.RadioButton.Add(gtx.Ops)
 		highlight := hovering && hovered == r.Key || focused && focus == r.Key
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/internal/f32color"
+	"gioui.org/io/semantic"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// Segment is an option of SegmentedButtons.
+type Segment struct {
+	// Key identifies the option in the Enum of single-select
+	// SegmentedButtons.
+	Key   string
+	Label string
+}
+
+// SegmentedButtonsStyle lays out a connected row of options, with the
+// selected options highlighted. In single-select mode, the options are
+// the keys of Enum and the arrow keys move the selection. In
+// multi-select mode, Bools holds the state of every option.
+type SegmentedButtonsStyle struct {
+	Enum     *widget.Enum
+	Bools    []*widget.Bool
+	Segments []Segment
+	// Color is the text color of the options, and SelectedColor the
+	// text color of the selected options.
+	Color         color.NRGBA
+	SelectedColor color.NRGBA
+	// SelectedBackground is the background color of the selected
+	// options.
+	SelectedBackground color.NRGBA
+	BorderColor        color.NRGBA
+	BorderWidth        unit.Value
+	// CornerRadius is the radius of the outer corners of the first
+	// and last options.
+	CornerRadius unit.Value
+	Font         text.Font
+	TextSize     unit.Value
+	Inset        layout.Inset
+	shaper       text.Shaper
+}
+
+// SegmentedButtons returns single-select SegmentedButtons for enum.
+func SegmentedButtons(th *Theme, enum *widget.Enum, segments ...Segment) SegmentedButtonsStyle {
+	s := segmentedButtons(th, segments)
+	s.Enum = enum
+	return s
+}
+
+// MultiSegmentedButtons returns multi-select SegmentedButtons, whose
+// option i is selected by bools[i].
+func MultiSegmentedButtons(th *Theme, bools []*widget.Bool, segments ...Segment) SegmentedButtonsStyle {
+	s := segmentedButtons(th, segments)
+	s.Bools = bools
+	return s
+}
+
+func segmentedButtons(th *Theme, segments []Segment) SegmentedButtonsStyle {
+	return SegmentedButtonsStyle{
+		Segments:           segments,
+		Color:              th.Palette.Fg,
+		SelectedColor:      th.Palette.ContrastFg,
+		SelectedBackground: th.Palette.ContrastBg,
+		BorderColor:        f32color.MulAlpha(th.Palette.Fg, 0x60),
+		BorderWidth:        unit.Dp(1),
+		CornerRadius:       unit.Dp(16),
+		TextSize:           th.TextSize.Scale(14.0 / 16.0),
+		Inset: layout.Inset{
+			Top: unit.Dp(8), Bottom: unit.Dp(8),
+			Left: unit.Dp(12), Right: unit.Dp(12),
+		},
+		shaper: th.Shaper,
+	}
+}
+
+func (s SegmentedButtonsStyle) Layout(gtx layout.Context) layout.Dimensions {
+	children := make([]layout.FlexChild, len(s.Segments))
+	for i := range s.Segments {
+		i := i
+		children[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = 0
+			return s.layoutSegment(gtx, i)
+		})
+	}
+	return layout.Flex{}.Layout(gtx, children...)
+}
+
+func (s SegmentedButtonsStyle) layoutSegment(gtx layout.Context, i int) layout.Dimensions {
+	seg := s.Segments[i]
+	if s.Enum == nil {
+		b := s.Bools[i]
+		return b.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			if !gtx.IsMeasuring() {
+				semantic.ToggleButton.Add(gtx.Ops)
+			}
+			return s.drawSegment(gtx, i, b.Value, b.Hovered() || b.Focused())
+		})
+	}
+	return s.Enum.Layout(gtx, seg.Key, func(gtx layout.Context) layout.Dimensions {
+		if !gtx.IsMeasuring() {
+			semantic.RadioButton.Add(gtx.Ops)
+		}
+		hovered, hovering := s.Enum.Hovered()
+		focus, focused := s.Enum.Focused()
+		active := hovering && hovered == seg.Key || focused && focus == seg.Key
+		return s.drawSegment(gtx, i, s.Enum.Value == seg.Key, active)
+	})
+}
+
+// drawSegment draws the option i with its label, background and border.
+// Only the first and last options have rounded outer corners.
+func (s SegmentedButtonsStyle) drawSegment(gtx layout.Context, i int, selected, hovered bool) layout.Dimensions {
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			if gtx.IsMeasuring() {
+				return layout.Skeleton(gtx.Constraints.Min)
+			}
+			sz := layout.FPt(gtx.Constraints.Min)
+			bw := float32(gtx.Px(s.BorderWidth))
+			r := float32(gtx.Px(s.CornerRadius))
+			if max := sz.Y / 2; r > max {
+				r = max
+			}
+			// Inset the outline by half the border width, to keep the
+			// stroke inside the option.
+			rr := clip.RRect{Rect: f32.Rectangle{
+				Min: f32.Pt(bw/2, bw/2),
+				Max: sz.Sub(f32.Pt(bw/2, bw/2)),
+			}}
+			if i == 0 {
+				rr.NW, rr.SW = r, r
+			}
+			if i == len(s.Segments)-1 {
+				rr.NE, rr.SE = r, r
+			}
+			bg := toggleBackground(gtx, selected, hovered, s.SelectedBackground, s.Color)
+			paint.FillShape(gtx.Ops, bg, rr.Op(gtx.Ops))
+			if bw > 0 {
+				paint.FillShape(gtx.Ops, s.BorderColor, clip.Stroke{
+					Path:  rr.Path(gtx.Ops),
+					Width: bw,
+				}.Op())
+			}
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			return s.Inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				if !gtx.IsMeasuring() {
+					col := s.Color
+					if selected {
+						col = s.SelectedColor
+					}
+					if gtx.Queue == nil {
+						col = f32color.Disabled(col)
+					}
+					paint.ColorOp{Color: col}.Add(gtx.Ops)
+				}
+				return widget.Label{Alignment: text.Middle}.Layout(gtx, s.shaper, s.Font, s.TextSize, s.Segments[i].Label)
+			})
+		}),
+	)
+}
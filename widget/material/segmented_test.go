@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material_test
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/io/semantic"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+func TestSegmentedButtons(t *testing.T) {
+	var (
+		ops  op.Ops
+		r    router.Router
+		enum widget.Enum
+	)
+	th := material.NewTheme(gofont.Collection())
+	gtx := layout.NewContext(&ops, system.FrameEvent{
+		Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Size:   image.Pt(300, 100),
+		Queue:  &r,
+	})
+	frame := func() {
+		ops.Reset()
+		gtx := gtx
+		gtx.Constraints.Min = image.Point{}
+		material.SegmentedButtons(th, &enum,
+			material.Segment{Key: "day", Label: "Day"},
+			material.Segment{Key: "week", Label: "Week"},
+			material.Segment{Key: "month", Label: "Month"},
+		).Layout(gtx)
+		r.Frame(gtx.Ops)
+	}
+	// checked returns the checked states of the options.
+	checked := func() []bool {
+		var states []bool
+		for _, n := range r.AppendSemantics(nil) {
+			if n.Desc.Class == semantic.RadioButton {
+				states = append(states, n.Desc.Selected)
+			}
+		}
+		return states
+	}
+	frame()
+	// Click the first option.
+	r.Queue(
+		pointer.Event{Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Type: pointer.Press, Position: f32.Pt(5, 5)},
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Release, Position: f32.Pt(5, 5)},
+	)
+	frame()
+	frame()
+	if enum.Value != "day" {
+		t.Fatalf("click selected %q, expected \"day\"", enum.Value)
+	}
+	for _, k := range []struct {
+		name    string
+		value   string
+		checked []bool
+	}{
+		{key.NameRightArrow, "week", []bool{false, true, false}},
+		{key.NameRightArrow, "month", []bool{false, false, true}},
+		// The selection stops at the ends.
+		{key.NameRightArrow, "month", []bool{false, false, true}},
+		{key.NameLeftArrow, "week", []bool{false, true, false}},
+	} {
+		r.Queue(key.Event{Name: k.name, State: key.Press})
+		frame()
+		frame()
+		if enum.Value != k.value {
+			t.Errorf("%s selected %q, expected %q", k.name, enum.Value, k.value)
+		}
+		if c := checked(); !reflect.DeepEqual(c, k.checked) {
+			t.Errorf("%s: got checked states %v, expected %v", k.name, c, k.checked)
+		}
+	}
+}
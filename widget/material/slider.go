@@ -18,13 +18,14 @@ import (
 
 // Slider is for selecting a value in a range.
 func Slider(th *Theme, float *widget.Float, min, max float32) SliderStyle {
-	return SliderStyle{
-		Min:        min,
-		Max:        max,
-		Color:      th.Palette.ContrastBg,
-		Float:      float,
-		FingerSize: th.FingerSize,
-	}
+	return th.sliderDefaults(SliderStyle{
+		Min:            min,
+		Max:            max,
+		Color:          th.Palette.ContrastBg,
+		Float:          float,
+		FingerSize:     th.FingerSize,
+		MinTouchTarget: th.MinTouchTarget,
+	})
 }
 
 type SliderStyle struct {
@@ -33,6 +34,8 @@ type SliderStyle struct {
 	Float    *widget.Float
 
 	FingerSize unit.Value
+	// MinTouchTarget is the minimum size of the pointer input area.
+	MinTouchTarget unit.Value
 }
 
 func (s SliderStyle) Layout(gtx layout.Context) layout.Dimensions {
@@ -52,7 +55,9 @@ func (s SliderStyle) Layout(gtx layout.Context) layout.Dimensions {
 	o := axis.Convert(image.Pt(thumbRadius, 0))
 	trans := op.Offset(layout.FPt(o)).Push(gtx.Ops)
 	gtx.Constraints.Min = axis.Convert(image.Pt(sizeMain-2*thumbRadius, sizeCross))
+	ex := expandTouchTarget(gtx, s.MinTouchTarget)
 	s.Float.Layout(gtx, thumbRadius, s.Min, s.Max)
+	ex.Pop()
 	gtx.Constraints.Min = gtx.Constraints.Min.Add(axis.Convert(image.Pt(0, sizeCross)))
 	thumbPos := thumbRadius + int(s.Float.Pos())
 	trans.Pop()
@@ -25,13 +25,16 @@ type SwitchStyle struct {
 		Track    color.NRGBA
 	}
 	Switch *widget.Bool
+	// MinTouchTarget is the minimum size of the pointer input area.
+	MinTouchTarget unit.Value
 }
 
 // Switch is for selecting a boolean value.
 func Switch(th *Theme, swtch *widget.Bool, description string) SwitchStyle {
 	sw := SwitchStyle{
-		Switch:      swtch,
-		Description: description,
+		Switch:         swtch,
+		Description:    description,
+		MinTouchTarget: th.MinTouchTarget,
 	}
 	sw.Color.Enabled = th.Palette.ContrastBg
 	sw.Color.Disabled = th.Palette.Bg
@@ -121,6 +124,7 @@ func (s SwitchStyle) Layout(gtx layout.Context) layout.Dimensions {
 	}
 	defer op.Offset(clickOff).Push(gtx.Ops).Pop()
 	sz := image.Pt(clickSize, clickSize)
+	defer expandTouchTarget(gtx, s.MinTouchTarget).Pop()
 	defer clip.Ellipse(f32.Rectangle{Max: layout.FPt(sz)}).Push(gtx.Ops).Pop()
 	s.Switch.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		if d := s.Description; d != "" {
@@ -3,10 +3,13 @@
 package material
 
 import (
+	"image"
 	"image/color"
 
 	"golang.org/x/exp/shiny/materialdesign/icons"
 
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
 	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
@@ -44,6 +47,23 @@ type Theme struct {
 
 	// FingerSize is the minimum touch target size.
 	FingerSize unit.Value
+	// MinTouchTarget is the minimum size of the pointer input areas of
+	// small interactive widgets. The input areas are expanded around
+	// the widgets without affecting their layout.
+	MinTouchTarget unit.Value
+	// BaselineGrid, if non-zero, is the grid for the baselines of the
+	// text of labels, buttons and editors. Their vertical padding is
+	// adjusted to place their first baselines on the grid lines, and
+	// their heights are rounded up to the grid. See layout.SnapBaseline.
+	BaselineGrid unit.Value
+	// SelectionColor is the background color of selected text, and
+	// CaretColor the color of the text caret. A zero SelectionColor
+	// means ContrastBg with reduced alpha, and a zero CaretColor paints
+	// carets in the text color of their editors.
+	SelectionColor color.NRGBA
+	CaretColor     color.NRGBA
+
+	defaults styleDefaults
 }
 
 func NewTheme(fontCollection []text.FontFace) *Theme {
@@ -65,10 +85,18 @@ func NewTheme(fontCollection []text.FontFace) *Theme {
 
 	// 38dp is on the lower end of possible finger size.
 	t.FingerSize = unit.Dp(38)
+	t.MinTouchTarget = unit.Dp(48)
 
 	return t
 }
 
+// expandTouchTarget expands the pointer input areas of the widgets laid
+// out until Pop to at least size.
+func expandTouchTarget(gtx layout.Context, size unit.Value) pointer.ExpandStack {
+	px := gtx.Px(size)
+	return pointer.ExpandOp{Min: image.Pt(px, px)}.Push(gtx.Ops)
+}
+
 func (t Theme) WithPalette(p Palette) Theme {
 	t.Palette = p
 	return t
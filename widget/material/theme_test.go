@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material_test
+
+import (
+	"image/color"
+	"testing"
+
+	"gioui.org/font/gofont"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+func TestThemeDefaults(t *testing.T) {
+	var (
+		click  widget.Clickable
+		editor widget.Editor
+		check  widget.Bool
+		float  widget.Float
+	)
+	th := material.NewTheme(gofont.Collection())
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	th.RegisterButtonDefaults(func(b *material.ButtonStyle) {
+		b.CornerRadius = unit.Dp(12)
+		b.Background = red
+	})
+	// Overrides run in order.
+	th.RegisterButtonDefaults(func(b *material.ButtonStyle) {
+		b.CornerRadius = b.CornerRadius.Scale(2)
+	})
+	th.RegisterButtonDefaults(nil)
+	th.RegisterEditorDefaults(func(e *material.EditorStyle) {
+		e.TextSize = unit.Sp(20)
+	})
+	th.RegisterCheckBoxDefaults(func(c *material.CheckBoxStyle) {
+		c.IconColor = red
+	})
+	th.RegisterSliderDefaults(func(s *material.SliderStyle) {
+		s.Color = red
+	})
+	th.RegisterLabelDefaults(func(l *material.LabelStyle) {
+		l.Font.Weight = text.Bold
+	})
+
+	btn := material.Button(th, &click, "OK")
+	if btn.CornerRadius != unit.Dp(24) || btn.Background != red {
+		t.Errorf("button got corner radius %v and background %v; expected overrides", btn.CornerRadius, btn.Background)
+	}
+	if btn.Text != "OK" || btn.Color != th.Palette.ContrastFg {
+		t.Errorf("button override changed other defaults: %+v", btn)
+	}
+	if e := material.Editor(th, &editor, ""); e.TextSize != unit.Sp(20) {
+		t.Errorf("editor got text size %v; expected override", e.TextSize)
+	}
+	if c := material.CheckBox(th, &check, ""); c.IconColor != red {
+		t.Errorf("check box got icon color %v; expected override", c.IconColor)
+	}
+	if s := material.Slider(th, &float, 0, 1); s.Color != red {
+		t.Errorf("slider got color %v; expected override", s.Color)
+	}
+	// Label overrides apply after the weights of the typographic
+	// constructors.
+	for _, l := range []material.LabelStyle{
+		material.Label(th, unit.Sp(10), ""),
+		material.H1(th, ""),
+		material.H6(th, ""),
+		material.Body1(th, ""),
+	} {
+		if l.Font.Weight != text.Bold {
+			t.Errorf("label got weight %v; expected override", l.Font.Weight)
+		}
+	}
+
+	// Changes of a constructed style take precedence.
+	btn = material.Button(th, &click, "OK")
+	btn.CornerRadius = unit.Dp(1)
+	if btn.CornerRadius != unit.Dp(1) {
+		t.Errorf("button got corner radius %v; expected per-instance value", btn.CornerRadius)
+	}
+	h := material.H2(th, "")
+	h.Font.Weight = text.Light
+	if h.Font.Weight != text.Light {
+		t.Errorf("label got weight %v; expected per-instance value", h.Font.Weight)
+	}
+
+	// Themes without overrides construct the plain defaults.
+	plain := material.NewTheme(gofont.Collection())
+	if r := material.Button(plain, &click, "").CornerRadius; r != unit.Dp(4) {
+		t.Errorf("button of plain theme got corner radius %v", r)
+	}
+}
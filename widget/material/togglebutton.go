@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/internal/f32color"
+	"gioui.org/io/semantic"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// ToggleIconButtonStyle is an icon button with an on and off state, such
+// as the bold button of a toolbar. The button is filled with Background
+// when on, and transparent when off.
+type ToggleIconButtonStyle struct {
+	// Background is the background color when on.
+	Background color.NRGBA
+	// Color is the icon color when on.
+	Color color.NRGBA
+	// OffColor is the icon color when off.
+	OffColor color.NRGBA
+	Icon     *widget.Icon
+	// Size is the icon size.
+	Size        unit.Value
+	Inset       layout.Inset
+	Toggle      *widget.Bool
+	Description string
+	// MinTouchTarget is the minimum size of the pointer input area.
+	MinTouchTarget unit.Value
+}
+
+func ToggleIconButton(th *Theme, toggle *widget.Bool, icon *widget.Icon, description string) ToggleIconButtonStyle {
+	return ToggleIconButtonStyle{
+		Background:     th.Palette.ContrastBg,
+		Color:          th.Palette.ContrastFg,
+		OffColor:       th.Palette.Fg,
+		Icon:           icon,
+		Size:           unit.Dp(24),
+		Inset:          layout.UniformInset(unit.Dp(12)),
+		Toggle:         toggle,
+		Description:    description,
+		MinTouchTarget: th.MinTouchTarget,
+	}
+}
+
+func (b ToggleIconButtonStyle) Layout(gtx layout.Context) layout.Dimensions {
+	if gtx.IsMeasuring() {
+		return b.layout(gtx)
+	}
+	m := op.Record(gtx.Ops)
+	dims := b.layout(gtx)
+	c := m.Stop()
+	defer expandTouchTarget(gtx, b.MinTouchTarget).Pop()
+	bounds := f32.Rectangle{Max: layout.FPt(dims.Size)}
+	defer clip.Ellipse(bounds).Push(gtx.Ops).Pop()
+	c.Add(gtx.Ops)
+	return dims
+}
+
+func (b ToggleIconButtonStyle) layout(gtx layout.Context) layout.Dimensions {
+	return b.Toggle.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		if !gtx.IsMeasuring() {
+			semantic.ToggleButton.Add(gtx.Ops)
+			if d := b.Description; d != "" {
+				semantic.DescriptionOp(b.Description).Add(gtx.Ops)
+			}
+		}
+		on := b.Toggle.Value
+		return layout.Stack{Alignment: layout.Center}.Layout(gtx,
+			layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+				if gtx.IsMeasuring() {
+					return layout.Skeleton(gtx.Constraints.Min)
+				}
+				sizexf, sizeyf := float32(gtx.Constraints.Min.X), float32(gtx.Constraints.Min.Y)
+				rr := (sizexf + sizeyf) * .25
+				defer clip.UniformRRect(f32.Rectangle{
+					Max: f32.Point{X: sizexf, Y: sizeyf},
+				}, rr).Push(gtx.Ops).Pop()
+				paint.Fill(gtx.Ops, toggleBackground(gtx, on, b.Toggle.Hovered() || b.Toggle.Focused(), b.Background, b.OffColor))
+				for _, c := range b.Toggle.History() {
+					drawInk(gtx, c)
+				}
+				return layout.Dimensions{Size: gtx.Constraints.Min}
+			}),
+			layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+				return b.Inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					size := gtx.Px(b.Size)
+					if b.Icon != nil {
+						col := b.OffColor
+						if on {
+							col = b.Color
+						}
+						if gtx.Queue == nil {
+							col = f32color.Disabled(col)
+						}
+						gtx.Constraints.Min = image.Point{X: size}
+						b.Icon.Layout(gtx, col)
+					}
+					return layout.Dimensions{
+						Size: image.Point{X: size, Y: size},
+					}
+				})
+			}),
+		)
+	})
+}
+
+// toggleBackground returns the background of a toggled widget: bg when
+// on, and transparent when off, with a translucent layer of the off
+// content color fg while hovered or focused.
+func toggleBackground(gtx layout.Context, on, hovered bool, bg, fg color.NRGBA) color.NRGBA {
+	switch {
+	case on && gtx.Queue == nil:
+		return f32color.Disabled(bg)
+	case on && hovered:
+		return f32color.Hovered(bg)
+	case on:
+		return bg
+	case hovered && gtx.Queue != nil:
+		return f32color.MulAlpha(fg, 0x20)
+	}
+	return color.NRGBA{}
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/internal/f32color"
+	"gioui.org/io/semantic"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// ToolbarStyle lays out a widget.Toolbar as a row of icon buttons, with
+// the actions that don't fit listed in an overflow menu. Actions without
+// icons are shown by their labels.
+type ToolbarStyle struct {
+	Toolbar  *widget.Toolbar
+	TextSize unit.Value
+	// Color is the color of the icons and labels.
+	Color color.NRGBA
+	// IconSize is the size of the action icons.
+	IconSize unit.Value
+	// Inset is the padding of each action.
+	Inset layout.Inset
+	// MenuHeight is the maximum height of the overflow menu.
+	MenuHeight unit.Value
+	Menu       MenuStyle
+	shaper     text.Shaper
+}
+
+func Toolbar(th *Theme, toolbar *widget.Toolbar) ToolbarStyle {
+	return ToolbarStyle{
+		Toolbar:    toolbar,
+		TextSize:   th.TextSize.Scale(14.0 / 16.0),
+		Color:      th.Palette.Fg,
+		IconSize:   unit.Dp(24),
+		Inset:      layout.UniformInset(unit.Dp(12)),
+		MenuHeight: unit.Dp(320),
+		Menu:       Menu(th, &toolbar.Menu),
+		shaper:     th.Shaper,
+	}
+}
+
+func (t ToolbarStyle) Layout(gtx layout.Context) layout.Dimensions {
+	return t.Toolbar.Layout(gtx, t.layoutAction, t.layoutOverflow, t.layoutMenu)
+}
+
+func (t ToolbarStyle) layoutAction(gtx layout.Context, i int) layout.Dimensions {
+	a := t.Toolbar.Actions[i]
+	return t.layoutButton(gtx, t.Toolbar.Clickable(i), a.Icon, a.Label)
+}
+
+func (t ToolbarStyle) layoutOverflow(gtx layout.Context) layout.Dimensions {
+	return t.layoutButton(gtx, t.Toolbar.Overflow(), nil, "⋮")
+}
+
+// layoutButton lays out a clickable icon, or label if icon is nil, with
+// a highlight when hovered.
+func (t ToolbarStyle) layoutButton(gtx layout.Context, click *widget.Clickable, icon *widget.Icon, label string) layout.Dimensions {
+	return click.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min = image.Point{}
+		if !gtx.IsMeasuring() {
+			semantic.Button.Add(gtx.Ops)
+			if icon != nil && label != "" {
+				semantic.DescriptionOp(label).Add(gtx.Ops)
+			}
+		}
+		col := t.Color
+		if gtx.Queue == nil {
+			col = f32color.Disabled(col)
+		}
+		return layout.Stack{Alignment: layout.Center}.Layout(gtx,
+			layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+				sz := gtx.Constraints.Min
+				if click.Hovered() && !gtx.IsMeasuring() {
+					rr := float32(gtx.Px(unit.Dp(4)))
+					defer clip.UniformRRect(layout.FRect(image.Rectangle{Max: sz}), rr).Push(gtx.Ops).Pop()
+					paint.Fill(gtx.Ops, f32color.MulAlpha(t.Color, 0x20))
+				}
+				return layout.Dimensions{Size: sz}
+			}),
+			layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+				return t.Inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					if icon == nil {
+						return LabelStyle{
+							Text:     label,
+							Color:    col,
+							TextSize: t.TextSize,
+							MaxLines: 1,
+							shaper:   t.shaper,
+						}.Layout(gtx)
+					}
+					size := gtx.Px(t.IconSize)
+					if !gtx.IsMeasuring() {
+						gtx.Constraints.Min = image.Point{X: size}
+						icon.Layout(gtx, col)
+					}
+					return layout.Dimensions{Size: image.Pt(size, size)}
+				})
+			}),
+		)
+	})
+}
+
+func (t ToolbarStyle) layoutMenu(gtx layout.Context) layout.Dimensions {
+	gtx.Constraints.Max.Y = gtx.Px(t.MenuHeight)
+	return t.Menu.Layout(gtx)
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/internal/f32color"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// TooltipStyle lays out the text of a widget.Tooltip in a panel below
+// the pointer, on top of other content.
+type TooltipStyle struct {
+	Tooltip *widget.Tooltip
+	Text    LabelStyle
+	// Background is the color of the panel.
+	Background   color.NRGBA
+	CornerRadius unit.Value
+	Inset        layout.Inset
+	// MaxWidth bounds the width of the panel.
+	MaxWidth unit.Value
+}
+
+func Tooltip(th *Theme, tooltip *widget.Tooltip, txt string) TooltipStyle {
+	text := Label(th, th.TextSize.Scale(14.0/16.0), txt)
+	text.Color = th.Palette.Bg
+	return TooltipStyle{
+		Tooltip:      tooltip,
+		Text:         text,
+		Background:   f32color.MulAlpha(th.Palette.Fg, 0xe6),
+		CornerRadius: unit.Dp(4),
+		Inset:        layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(8), Right: unit.Dp(8)},
+		MaxWidth:     unit.Dp(320),
+	}
+}
+
+// Layout the tooltip if visible, in the coordinates of the area tracked
+// by the Tooltip. The panel is deferred above other content, and takes
+// no space.
+func (t TooltipStyle) Layout(gtx layout.Context) layout.Dimensions {
+	if !t.Tooltip.Visible() {
+		return layout.Dimensions{}
+	}
+	macro := op.Record(gtx.Ops)
+	pos := t.Tooltip.Position()
+	// Place the panel below the pointer cursor.
+	pos.Y += float32(gtx.Px(unit.Dp(20)))
+	trans := op.Offset(pos).Push(gtx.Ops)
+	gtx.Constraints = layout.Constraints{Max: image.Pt(gtx.Px(t.MaxWidth), gtx.Constraints.Max.Y)}
+	layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+			sz := gtx.Constraints.Min
+			rr := float32(gtx.Px(t.CornerRadius))
+			defer clip.UniformRRect(layout.FRect(image.Rectangle{Max: sz}), rr).Push(gtx.Ops).Pop()
+			paint.Fill(gtx.Ops, t.Background)
+			return layout.Dimensions{Size: sz}
+		}),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			return t.Inset.Layout(gtx, t.Text.Layout)
+		}),
+	)
+	trans.Pop()
+	op.Defer(gtx.Ops, macro.Stop())
+	return layout.Dimensions{}
+}
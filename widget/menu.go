@@ -0,0 +1,525 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"time"
+
+	"gioui.org/gesture"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+// Menu holds the state of a menu and its cascade of open submenus.
+type Menu struct {
+	Items []MenuItem
+	// SubmenuDelay is how long the pointer must rest on an item before
+	// its submenu opens, or before the open submenu of another item
+	// closes. Zero means a default delay.
+	SubmenuDelay time.Duration
+
+	open bool
+	// anchor is the area the menu is placed next to. Root menus are
+	// placed below their anchor, submenus beside it.
+	anchor image.Rectangle
+	// bounds is the area available to the cascade.
+	bounds image.Rectangle
+	// rect is the placed area of the menu, in the coordinates of bounds.
+	rect   image.Rectangle
+	parent *Menu
+	// child is the open submenu of the item at childItem.
+	child        *Menu
+	childItem    int
+	selected     int
+	requestFocus bool
+	focused      bool
+	tag          struct{}
+	clicks       []Clickable
+	// dismiss detects presses outside the cascade of a root menu.
+	dismiss gesture.Click
+
+	// hover is the item the pointer has rested on since hoverStart.
+	hover      int
+	hoverStart time.Time
+
+	scroller gesture.Scroll
+	// scroll is the scroll offset of the items.
+	scroll int
+	// viewport is the visible height of the items.
+	viewport int
+	// itemBounds are the bounds of the items, unscrolled.
+	itemBounds       []image.Rectangle
+	scrollToSelected bool
+	arrows           [2]Clickable
+
+	// sibling, if set, opens the next menu of a MenuBar in the direction
+	// of dir, in response to the left and right arrow keys.
+	sibling func(dir int)
+}
+
+// MenuItem is an item of a Menu. An item runs Do, toggles Bool, sets
+// Enum to Value or opens Submenu when chosen.
+type MenuItem struct {
+	Title string
+	// Shortcut is the key combination displayed next to the
+	// title, if any.
+	Shortcut Shortcut
+	Disabled bool
+	Do       func()
+	// Bool makes the item checkable.
+	Bool *Bool
+	// Enum makes the item a radio item that is checked when
+	// the Enum has the item Value.
+	Enum  *Enum
+	Value string
+	// Submenu is the child menu of the item.
+	Submenu *Menu
+}
+
+// defaultSubmenuDelay is the SubmenuDelay of menus that don't
+// specify one.
+const defaultSubmenuDelay = 300 * time.Millisecond
+
+// Checkable reports whether the item has a checked state.
+func (it MenuItem) Checkable() bool {
+	return it.Bool != nil || it.Enum != nil
+}
+
+// Checked reports whether the item is checked.
+func (it MenuItem) Checked() bool {
+	switch {
+	case it.Bool != nil:
+		return it.Bool.Value
+	case it.Enum != nil:
+		return it.Enum.Value == it.Value
+	}
+	return false
+}
+
+// Open the menu below anchor, in the coordinates of the area passed to
+// Layout. Use an empty anchor to open a context menu at a point.
+func (m *Menu) Open(anchor image.Rectangle) {
+	m.open = true
+	m.anchor = anchor
+	m.parent = nil
+	m.reset()
+}
+
+// Close the menu and its submenus.
+func (m *Menu) Close() {
+	m.closeChild()
+	m.open = false
+	m.focused = false
+}
+
+// Visible reports whether the menu is open.
+func (m *Menu) Visible() bool {
+	return m.open
+}
+
+// Focused reports whether the menu has the keyboard focus.
+func (m *Menu) Focused() bool {
+	return m.focused
+}
+
+// Selected returns the index of the selected item, or -1.
+func (m *Menu) Selected() int {
+	return m.selected
+}
+
+// Submenu returns the index of the item whose submenu is open, or -1.
+func (m *Menu) Submenu() int {
+	if m.child == nil {
+		return -1
+	}
+	return m.childItem
+}
+
+// Clickable returns the clickable for the item at index i.
+func (m *Menu) Clickable(i int) *Clickable {
+	if n := len(m.Items); n > len(m.clicks) {
+		m.clicks = append(m.clicks, make([]Clickable, n-len(m.clicks))...)
+	}
+	return &m.clicks[i]
+}
+
+// CanScroll reports whether items are hidden above the visible items, or
+// below them if up is false.
+func (m *Menu) CanScroll(up bool) bool {
+	if up {
+		return m.scroll > 0
+	}
+	n := len(m.itemBounds)
+	return n > 0 && m.scroll+m.viewport < m.itemBounds[n-1].Max.Y
+}
+
+// ScrollArrow returns the clickable that scrolls the items up, or down if
+// up is false.
+func (m *Menu) ScrollArrow(up bool) *Clickable {
+	if up {
+		return &m.arrows[0]
+	}
+	return &m.arrows[1]
+}
+
+func (m *Menu) reset() {
+	m.closeChild()
+	m.selected = -1
+	m.hover = -1
+	m.scroll = 0
+	m.requestFocus = true
+}
+
+// root returns the root menu of the cascade.
+func (m *Menu) root() *Menu {
+	for m.parent != nil {
+		m = m.parent
+	}
+	return m
+}
+
+// openChild opens the submenu of item i. If focus is set, the submenu
+// takes the keyboard focus and selects its first item.
+func (m *Menu) openChild(i int, focus bool) {
+	c := m.Items[i].Submenu
+	if m.child != c {
+		m.closeChild()
+		c.open = true
+		c.reset()
+		c.parent = m
+		c.requestFocus = false
+		m.child, m.childItem = c, i
+	}
+	if focus {
+		c.requestFocus = true
+		if c.selected == -1 {
+			c.selectNext(1)
+		}
+	}
+}
+
+func (m *Menu) closeChild() {
+	if m.child == nil {
+		return
+	}
+	m.child.Close()
+	m.child = nil
+}
+
+// activate chooses item i.
+func (m *Menu) activate(i int, keyboard bool) {
+	if i < 0 || i >= len(m.Items) {
+		return
+	}
+	it := m.Items[i]
+	if it.Disabled {
+		return
+	}
+	if it.Submenu != nil {
+		m.openChild(i, keyboard)
+		return
+	}
+	m.root().Close()
+	switch {
+	case it.Bool != nil:
+		it.Bool.Value = !it.Bool.Value
+		it.Bool.changed = true
+	case it.Enum != nil:
+		if it.Enum.Value != it.Value {
+			it.Enum.Value = it.Value
+			it.Enum.changed = true
+		}
+	}
+	if it.Do != nil {
+		it.Do()
+	}
+}
+
+// selectNext selects the next enabled item in the direction of dir,
+// wrapping around.
+func (m *Menu) selectNext(dir int) {
+	n := len(m.Items)
+	i := m.selected
+	if i == -1 && dir < 0 {
+		i = n
+	}
+	for j := 0; j < n; j++ {
+		i = (i + dir + n) % n
+		if !m.Items[i].Disabled {
+			m.selected = i
+			m.scrollToSelected = true
+			return
+		}
+	}
+}
+
+// Layout the menu and its open submenus in the area of the constraints
+// maximum, typically above the rest of the user interface. The panel
+// widget lays out a single menu of the cascade, with LayoutItems. Layout
+// draws nothing if the menu is closed. A press outside the cascade closes
+// it.
+func (m *Menu) Layout(gtx layout.Context, panel func(gtx layout.Context, m *Menu) layout.Dimensions) layout.Dimensions {
+	if !m.open {
+		return layout.Dimensions{}
+	}
+	m.bounds = image.Rectangle{Max: gtx.Constraints.Max}
+	for _, e := range m.dismiss.Events(gtx) {
+		if e.Type == gesture.TypePress {
+			m.Close()
+		}
+	}
+	if !m.open {
+		return layout.Dimensions{}
+	}
+	area := clip.Rect(m.bounds).Push(gtx.Ops)
+	m.dismiss.Add(gtx.Ops)
+	area.Pop()
+	m.layout(gtx, panel)
+	return layout.Dimensions{Size: m.bounds.Size()}
+}
+
+func (m *Menu) layout(gtx layout.Context, panel func(gtx layout.Context, m *Menu) layout.Dimensions) {
+	m.update(gtx)
+	if !m.open {
+		return
+	}
+	cgtx := gtx
+	cgtx.Constraints = layout.Constraints{Max: m.bounds.Size()}
+	macro := op.Record(gtx.Ops)
+	dims := panel(cgtx, m)
+	call := macro.Stop()
+	pos := placeMenu(m.anchor, dims.Size, m.bounds, m.parent != nil)
+	m.rect = image.Rectangle{Min: pos, Max: pos.Add(dims.Size)}
+
+	trans := op.Offset(layout.FPt(pos)).Push(gtx.Ops)
+	area := clip.Rect{Max: dims.Size}.Push(gtx.Ops)
+	// Block pointer events from reaching the user interface below.
+	pointer.InputOp{Tag: &m.tag, Types: pointer.Press}.Add(gtx.Ops)
+	key.InputOp{Tag: &m.tag}.Add(gtx.Ops)
+	if m.requestFocus {
+		key.FocusOp{Tag: &m.tag}.Add(gtx.Ops)
+		m.requestFocus = false
+	}
+	call.Add(gtx.Ops)
+	area.Pop()
+	trans.Pop()
+
+	c := m.child
+	if c == nil || m.childItem >= len(m.itemBounds) {
+		return
+	}
+	// Align the submenu with its item. Both menus share the panel
+	// style, so the distance from panel to items cancels out.
+	b := m.itemBounds[m.childItem]
+	c.bounds = m.bounds
+	c.anchor = image.Rect(m.rect.Min.X, pos.Y+b.Min.Y-m.scroll, m.rect.Max.X, pos.Y+b.Max.Y-m.scroll)
+	c.layout(gtx, panel)
+}
+
+// update the menu state by processing events.
+func (m *Menu) update(gtx layout.Context) {
+	for _, e := range gtx.Events(&m.tag) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			m.focused = e.Focus
+		case key.Event:
+			if e.State != key.Press {
+				break
+			}
+			switch e.Name {
+			case key.NameEscape:
+				m.root().Close()
+				return
+			case key.NameUpArrow:
+				m.selectNext(-1)
+			case key.NameDownArrow:
+				m.selectNext(1)
+			case key.NameRightArrow:
+				if i := m.selected; i != -1 && m.Items[i].Submenu != nil && !m.Items[i].Disabled {
+					m.openChild(i, true)
+				} else if r := m.root(); r.sibling != nil {
+					r.sibling(1)
+					op.InvalidateOp{}.Add(gtx.Ops)
+					return
+				}
+			case key.NameLeftArrow:
+				if p := m.parent; p != nil {
+					p.closeChild()
+					p.requestFocus = true
+					// The parent is already laid out.
+					op.InvalidateOp{}.Add(gtx.Ops)
+					return
+				}
+				if m.sibling != nil {
+					m.sibling(-1)
+					op.InvalidateOp{}.Add(gtx.Ops)
+					return
+				}
+			case key.NameReturn, key.NameEnter, key.NameSpace:
+				m.activate(m.selected, true)
+				if !m.open {
+					return
+				}
+			}
+		}
+	}
+	for i := range m.Items {
+		c := m.Clickable(i)
+		c.Update(gtx)
+		if c.Clicked() {
+			m.selected = i
+			m.activate(i, false)
+			if !m.open {
+				return
+			}
+		}
+	}
+	step := 1
+	if len(m.itemBounds) > 0 {
+		step = m.itemBounds[0].Dy()
+	}
+	for m.arrows[0].Clicked() {
+		m.scroll -= step
+	}
+	for m.arrows[1].Clicked() {
+		m.scroll += step
+	}
+	m.updateHover(gtx)
+}
+
+// updateHover selects the hovered item and opens or closes submenus when
+// the pointer rests on an item.
+func (m *Menu) updateHover(gtx layout.Context) {
+	hover := -1
+	for i := range m.Items {
+		if m.clicks[i].Hovered() && !m.Items[i].Disabled {
+			hover = i
+			break
+		}
+	}
+	if hover != m.hover {
+		m.hover = hover
+		m.hoverStart = gtx.Now
+	}
+	if hover == -1 {
+		return
+	}
+	m.selected = hover
+	want := m.Items[hover].Submenu
+	if want == m.child {
+		return
+	}
+	delay := m.SubmenuDelay
+	if delay == 0 {
+		delay = defaultSubmenuDelay
+	}
+	if at := m.hoverStart.Add(delay); gtx.Now.Before(at) {
+		op.InvalidateOp{At: at}.Add(gtx.Ops)
+		return
+	}
+	if want == nil {
+		m.closeChild()
+	} else {
+		m.openChild(hover, false)
+	}
+}
+
+// LayoutItems lays out the items of the menu with item, stacked vertically
+// and as wide as the widest item. Items that don't fit the constraints are
+// scrolled into view by the pointer, the keyboard or the ScrollArrow
+// clickables.
+func (m *Menu) LayoutItems(gtx layout.Context, item func(gtx layout.Context, i int) layout.Dimensions) layout.Dimensions {
+	// Measure the items.
+	mgtx := gtx.Measuring()
+	mgtx.Constraints.Min = image.Point{}
+	mgtx.Constraints.Max.Y = inf
+	m.itemBounds = m.itemBounds[:0]
+	width, y := gtx.Constraints.Min.X, 0
+	for i := range m.Items {
+		dims := item(mgtx, i)
+		m.itemBounds = append(m.itemBounds, image.Rect(0, y, dims.Size.X, y+dims.Size.Y))
+		y += dims.Size.Y
+		if dims.Size.X > width {
+			width = dims.Size.X
+		}
+	}
+	if width > gtx.Constraints.Max.X {
+		width = gtx.Constraints.Max.X
+	}
+	m.viewport = y
+	if max := gtx.Constraints.Max.Y; m.viewport > max {
+		m.viewport = max
+	}
+
+	m.scroller.Config = gtx.Gesture
+	m.scroll += m.scroller.Scroll(gtx.Metric, gtx, gtx.Now, gesture.Vertical)
+	if i := m.selected; m.scrollToSelected && i >= 0 && i < len(m.itemBounds) {
+		b := m.itemBounds[i]
+		if b.Min.Y < m.scroll {
+			m.scroll = b.Min.Y
+		}
+		if b.Max.Y > m.scroll+m.viewport {
+			m.scroll = b.Max.Y - m.viewport
+		}
+	}
+	m.scrollToSelected = false
+	if max := y - m.viewport; m.scroll > max {
+		m.scroll = max
+	}
+	if m.scroll < 0 {
+		m.scroll = 0
+	}
+
+	sz := image.Point{X: width, Y: m.viewport}
+	defer clip.Rect{Max: sz}.Push(gtx.Ops).Pop()
+	if y > m.viewport {
+		m.scroller.Add(gtx.Ops, image.Rect(0, -m.scroll, 0, y-m.viewport-m.scroll))
+	}
+	for i, b := range m.itemBounds {
+		m.itemBounds[i].Max.X = width
+		if b.Max.Y <= m.scroll || b.Min.Y >= m.scroll+m.viewport {
+			continue
+		}
+		cgtx := gtx
+		cgtx.Constraints = layout.Exact(image.Pt(width, b.Dy()))
+		trans := op.Offset(layout.FPt(image.Pt(0, b.Min.Y-m.scroll))).Push(gtx.Ops)
+		item(cgtx, i)
+		trans.Pop()
+	}
+	return layout.Dimensions{Size: sz}
+}
+
+// placeMenu returns the position of a menu of size sz below anchor,
+// or beside it if side is set. The menu is moved to the other side of
+// anchor and then shifted to fit inside bounds.
+func placeMenu(anchor image.Rectangle, sz image.Point, bounds image.Rectangle, side bool) image.Point {
+	var p image.Point
+	if side {
+		p = image.Pt(anchor.Max.X, anchor.Min.Y)
+		if p.X+sz.X > bounds.Max.X {
+			p.X = anchor.Min.X - sz.X
+		}
+	} else {
+		p = image.Pt(anchor.Min.X, anchor.Max.Y)
+		if p.Y+sz.Y > bounds.Max.Y {
+			p.Y = anchor.Min.Y - sz.Y
+		}
+	}
+	if p.X+sz.X > bounds.Max.X {
+		p.X = bounds.Max.X - sz.X
+	}
+	if p.X < bounds.Min.X {
+		p.X = bounds.Min.X
+	}
+	if p.Y+sz.Y > bounds.Max.Y {
+		p.Y = bounds.Max.Y - sz.Y
+	}
+	if p.Y < bounds.Min.Y {
+		p.Y = bounds.Min.Y
+	}
+	return p
+}
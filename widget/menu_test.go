@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+type menuTest struct {
+	t   *testing.T
+	r   router.Router
+	gtx layout.Context
+}
+
+func newMenuTest(t *testing.T, size image.Point) *menuTest {
+	mt := &menuTest{t: t}
+	mt.gtx = layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(size),
+		Queue:       &mt.r,
+	}
+	return mt
+}
+
+// frame lays out m with items of 100x20 pixels.
+func (mt *menuTest) frame(m *Menu) {
+	mt.gtx.Ops.Reset()
+	m.Layout(mt.gtx, func(gtx layout.Context, m *Menu) layout.Dimensions {
+		return m.LayoutItems(gtx, func(gtx layout.Context, i int) layout.Dimensions {
+			return m.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Dimensions{Size: image.Pt(100, 20)}
+			})
+		})
+	})
+	mt.r.Frame(mt.gtx.Ops)
+}
+
+func (mt *menuTest) press(m *Menu, names ...string) {
+	for _, n := range names {
+		mt.r.Queue(key.Event{Name: n, State: key.Press})
+		mt.frame(m)
+	}
+}
+
+func TestMenuCascade(t *testing.T) {
+	var wrap Bool
+	var ran string
+	nested := &Menu{Items: []MenuItem{
+		{Title: "Reset", Do: func() { ran = "reset" }},
+	}}
+	view := &Menu{Items: []MenuItem{
+		{Title: "Zoom", Submenu: nested},
+		{Title: "Word Wrap", Bool: &wrap},
+	}}
+	root := &Menu{Items: []MenuItem{
+		{Title: "Open", Do: func() { ran = "open" }},
+		{Title: "Disabled", Disabled: true},
+		{Title: "View", Submenu: view},
+	}}
+	mt := newMenuTest(t, image.Pt(400, 400))
+	root.Open(image.Rect(10, 0, 60, 10))
+	mt.frame(root)
+	mt.frame(root)
+	if !root.Focused() {
+		t.Fatal("root menu not focused")
+	}
+	// Down selects the first item and skips the disabled item.
+	mt.press(root, key.NameDownArrow, key.NameDownArrow)
+	if got := root.Selected(); got != 2 {
+		t.Fatalf("selected %d; expected 2", got)
+	}
+	mt.press(root, key.NameRightArrow)
+	mt.frame(root)
+	if root.Submenu() != 2 || !view.Visible() || !view.Focused() {
+		t.Fatalf("submenu not open and focused")
+	}
+	if got := view.Selected(); got != 0 {
+		t.Errorf("submenu selected %d; expected 0", got)
+	}
+	// The submenu is placed beside its item.
+	if got, exp := view.rect.Min, image.Pt(110, 10+2*20); got != exp {
+		t.Errorf("submenu at %v; expected %v", got, exp)
+	}
+	// Left returns to the parent.
+	mt.press(root, key.NameLeftArrow)
+	mt.frame(root)
+	mt.frame(root)
+	if view.Visible() || !root.Focused() {
+		t.Errorf("left arrow did not close the submenu")
+	}
+	mt.press(root, key.NameRightArrow)
+	mt.frame(root)
+
+	// Toggle the checkable item.
+	mt.press(root, key.NameDownArrow, key.NameReturn)
+	if !wrap.Value || !wrap.Changed() {
+		t.Error("checkable item not toggled")
+	}
+	if root.Visible() || view.Visible() {
+		t.Error("choosing an item did not close the cascade")
+	}
+
+	// Escape from two levels deep closes the cascade.
+	root.Open(image.Rect(10, 0, 60, 10))
+	mt.frame(root)
+	mt.press(root, key.NameUpArrow, key.NameRightArrow)
+	mt.frame(root)
+	mt.press(root, key.NameRightArrow)
+	mt.frame(root)
+	if !nested.Visible() || !nested.Focused() {
+		t.Fatal("nested submenu not open and focused")
+	}
+	mt.press(root, key.NameEscape)
+	if root.Visible() || view.Visible() || nested.Visible() {
+		t.Errorf("escape did not close the cascade")
+	}
+	if ran != "" {
+		t.Errorf("escape ran %q", ran)
+	}
+}
+
+func TestMenuPointer(t *testing.T) {
+	var ran bool
+	sub := &Menu{Items: []MenuItem{{Title: "Item"}}}
+	root := &Menu{
+		Items: []MenuItem{
+			{Title: "Run", Do: func() { ran = true }},
+			{Title: "More", Submenu: sub},
+		},
+	}
+	mt := newMenuTest(t, image.Pt(400, 400))
+	root.Open(image.Rectangle{})
+	mt.frame(root)
+
+	// Resting on a submenu item opens the submenu after the delay.
+	mt.r.Queue(pointer.Event{Source: pointer.Mouse, Type: pointer.Move, Position: f32.Pt(50, 30)})
+	mt.frame(root)
+	mt.frame(root)
+	if sub.Visible() {
+		t.Fatal("submenu opened before the delay")
+	}
+	if _, ok := mt.r.WakeupTime(); !ok {
+		t.Error("no wakeup scheduled for the submenu delay")
+	}
+	mt.gtx.Now = mt.gtx.Now.Add(defaultSubmenuDelay)
+	mt.frame(root)
+	if !sub.Visible() {
+		t.Fatal("submenu not opened after the delay")
+	}
+	if got := root.Selected(); got != 1 {
+		t.Errorf("selected %d; expected the hovered item", got)
+	}
+
+	// Clicking an item runs it.
+	mt.r.Queue(
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Move, Position: f32.Pt(50, 10)},
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: f32.Pt(50, 10)},
+		pointer.Event{Source: pointer.Mouse, Type: pointer.Release, Position: f32.Pt(50, 10)},
+	)
+	mt.frame(root)
+	if !ran || root.Visible() {
+		t.Errorf("click did not run the item and close the menu")
+	}
+
+	// A press outside closes the menu.
+	root.Open(image.Rectangle{})
+	mt.frame(root)
+	mt.r.Queue(pointer.Event{Source: pointer.Mouse, Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: f32.Pt(300, 300)})
+	mt.frame(root)
+	if root.Visible() {
+		t.Error("press outside did not close the menu")
+	}
+}
+
+func TestMenuScroll(t *testing.T) {
+	m := new(Menu)
+	for i := 0; i < 20; i++ {
+		m.Items = append(m.Items, MenuItem{Title: "Item"})
+	}
+	mt := newMenuTest(t, image.Pt(400, 100))
+	m.Open(image.Rectangle{})
+	mt.frame(m)
+	mt.frame(m)
+	if m.rect.Dy() != 100 {
+		t.Fatalf("menu height %d; expected the window height", m.rect.Dy())
+	}
+	if m.CanScroll(true) || !m.CanScroll(false) {
+		t.Fatalf("menu not scrollable down only")
+	}
+	// Selecting the last item scrolls it into view.
+	mt.press(m, key.NameUpArrow)
+	mt.frame(m)
+	if got := m.Selected(); got != 19 {
+		t.Fatalf("selected %d; expected 19", got)
+	}
+	if !m.CanScroll(true) || m.CanScroll(false) {
+		t.Errorf("menu not scrolled to the end")
+	}
+	m.ScrollArrow(true).Click()
+	mt.frame(m)
+	if got, exp := m.scroll, 20*20-100-20; got != exp {
+		t.Errorf("scroll arrow scrolled to %d; expected %d", got, exp)
+	}
+}
+
+func TestPlaceMenu(t *testing.T) {
+	bounds := image.Rect(0, 0, 100, 100)
+	sz := image.Pt(40, 30)
+	for _, tc := range []struct {
+		anchor image.Rectangle
+		side   bool
+		exp    image.Point
+	}{
+		{image.Rect(10, 10, 20, 20), false, image.Pt(10, 20)},
+		// Flip above the anchor.
+		{image.Rect(10, 80, 20, 90), false, image.Pt(10, 50)},
+		// Shift into bounds.
+		{image.Rect(90, 10, 95, 20), false, image.Pt(60, 20)},
+		{image.Rect(10, 10, 50, 20), true, image.Pt(50, 10)},
+		// Cascade to the left.
+		{image.Rect(50, 10, 90, 20), true, image.Pt(10, 10)},
+		{image.Rect(50, 90, 90, 100), true, image.Pt(10, 70)},
+	} {
+		if got := placeMenu(tc.anchor, sz, bounds, tc.side); got != tc.exp {
+			t.Errorf("placeMenu(%v, side %v) = %v; expected %v", tc.anchor, tc.side, got, tc.exp)
+		}
+	}
+}
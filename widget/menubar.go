@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"strconv"
+
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/menu"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+// MenuBar holds the state of a menu bar laid out in the window, for
+// platforms without native menu bars.
+//
+// Events reports the chosen items on every platform: the items chosen in
+// the laid out menu bar, and the menu.Events native menu bars deliver to
+// the tags of the items, which Update collects. Programs with a native
+// menu bar call Update every frame, without laying out the bar, and
+// don't read the menu.Events of the tags themselves.
+type MenuBar struct {
+	// Menu is the model of the menu bar, as for app.Window.SetMenuBar.
+	// The items of Menu are the menus of the bar.
+	Menu menu.Menu
+
+	// synced is a copy of Menu as of the most recent sync.
+	synced menu.Menu
+	// tags are the tags of the items of synced.
+	tags   []event.Tag
+	items  []MenuItem
+	clicks []Clickable
+	// titles are the bounds of the titles, in the coordinates of the
+	// area passed to LayoutTitles.
+	titles []image.Rectangle
+	// shortcuts are the commands of the items with shortcuts.
+	shortcuts Commands
+	events    []menu.Event
+}
+
+// Update the state of the menu bar from its model, process the clicks on
+// its titles, and collect the menu.Events of the tags of its items.
+func (b *MenuBar) Update(gtx layout.Context) {
+	b.sync()
+	for _, t := range b.tags {
+		for _, e := range gtx.Events(t) {
+			if e, ok := e.(menu.Event); ok {
+				b.events = append(b.events, e)
+			}
+		}
+	}
+	open := b.Opened()
+	for i := range b.items {
+		c := b.Clickable(i)
+		c.Update(gtx)
+		it := b.items[i]
+		if c.Clicked() && !it.Disabled {
+			switch {
+			case i == open:
+				it.Submenu.Close()
+			case it.Submenu != nil:
+				b.open(i, false)
+			case it.Do != nil:
+				it.Do()
+			}
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+		// Open menus follow the pointer.
+		if open != -1 && i != open && c.Hovered() && it.Submenu != nil && !it.Disabled {
+			b.open(i, false)
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+}
+
+// Dispatch chooses the enabled item whose shortcut matches e, or opens the
+// first menu for the F10 key, and reports whether e was handled.
+func (b *MenuBar) Dispatch(e key.Event) bool {
+	b.sync()
+	if e.State == key.Press && e.Name == key.NameF10 && e.Modifiers == 0 {
+		if open := b.Opened(); open != -1 {
+			b.items[open].Submenu.Close()
+			return true
+		}
+		for i, it := range b.items {
+			if it.Submenu != nil && !it.Disabled {
+				b.open(i, true)
+				return true
+			}
+		}
+		return false
+	}
+	return b.shortcuts.Dispatch(e)
+}
+
+// Events returns the events of the items chosen since the previous call.
+func (b *MenuBar) Events() []menu.Event {
+	events := b.events
+	b.events = nil
+	return events
+}
+
+// Opened returns the index of the menu bar item whose menu is open, or -1.
+func (b *MenuBar) Opened() int {
+	for i, it := range b.items {
+		if it.Submenu != nil && it.Submenu.Visible() {
+			return i
+		}
+	}
+	return -1
+}
+
+// Len returns the number of items of the menu bar.
+func (b *MenuBar) Len() int {
+	return len(b.items)
+}
+
+// Item returns the item at index i.
+func (b *MenuBar) Item(i int) MenuItem {
+	return b.items[i]
+}
+
+// Clickable returns the clickable of the title of the item at index i.
+func (b *MenuBar) Clickable(i int) *Clickable {
+	if n := len(b.items); n > len(b.clicks) {
+		b.clicks = append(b.clicks, make([]Clickable, n-len(b.clicks))...)
+	}
+	return &b.clicks[i]
+}
+
+// LayoutTitles lays out the titles of the menu bar items with title, in a
+// row. The menus are placed below their titles, so the area passed to
+// the Layout of the open menu must have the same origin as the titles.
+func (b *MenuBar) LayoutTitles(gtx layout.Context, title func(gtx layout.Context, i int) layout.Dimensions) layout.Dimensions {
+	b.sync()
+	b.titles = b.titles[:0]
+	x, h := 0, 0
+	for i := range b.items {
+		cgtx := gtx
+		cgtx.Constraints.Min = image.Point{}
+		cgtx.Constraints.Max.X -= x
+		if cgtx.Constraints.Max.X < 0 {
+			cgtx.Constraints.Max.X = 0
+		}
+		trans := op.Offset(layout.FPt(image.Pt(x, 0))).Push(gtx.Ops)
+		dims := title(cgtx, i)
+		trans.Pop()
+		b.titles = append(b.titles, image.Rect(x, 0, x+dims.Size.X, dims.Size.Y))
+		x += dims.Size.X
+		if dims.Size.Y > h {
+			h = dims.Size.Y
+		}
+	}
+	for i := range b.titles {
+		b.titles[i].Max.Y = h
+	}
+	return layout.Dimensions{Size: gtx.Constraints.Constrain(image.Pt(gtx.Constraints.Max.X, h))}
+}
+
+// open the menu of item i, and select its first item if keyboard is set.
+func (b *MenuBar) open(i int, keyboard bool) {
+	for j, it := range b.items {
+		if j != i && it.Submenu != nil {
+			it.Submenu.Close()
+		}
+	}
+	var anchor image.Rectangle
+	if i < len(b.titles) {
+		anchor = b.titles[i]
+	}
+	m := b.items[i].Submenu
+	m.Open(anchor)
+	if keyboard {
+		m.selectNext(1)
+	}
+}
+
+// move opens the next enabled menu in the direction of dir, wrapping
+// around.
+func (b *MenuBar) move(dir int) {
+	n := len(b.items)
+	i := b.Opened()
+	if i == -1 {
+		return
+	}
+	for j := 0; j < n; j++ {
+		i = (i + dir + n) % n
+		if it := b.items[i]; it.Submenu != nil && !it.Disabled {
+			b.open(i, true)
+			return
+		}
+	}
+}
+
+// sync the items and shortcuts from the model, if it changed since the
+// previous sync. The submenus of the items are kept, to keep the state of
+// open menus.
+func (b *MenuBar) sync() {
+	if b.synced.Items != nil && sameMenu(b.synced, b.Menu) {
+		return
+	}
+	b.synced = b.Menu.Clone()
+	b.shortcuts = Commands{}
+	b.tags = b.tags[:0]
+	b.items = b.syncItems(b.items, b.Menu.Items, "")
+	for _, it := range b.items {
+		if m := it.Submenu; m != nil && m.sibling == nil {
+			m.sibling = b.move
+		}
+	}
+}
+
+func (b *MenuBar) syncItems(dst []MenuItem, items []menu.Item, path string) []MenuItem {
+	for len(dst) < len(items) {
+		dst = append(dst, MenuItem{})
+	}
+	dst = dst[:len(items)]
+	for i, it := range items {
+		sub := dst[i].Submenu
+		mi := MenuItem{
+			Title:    it.Title,
+			Shortcut: Shortcut(it.Shortcut),
+			Disabled: it.Disabled,
+		}
+		if it.Checkable {
+			mi.Bool = &Bool{Value: it.Checked}
+		}
+		id := path + strconv.Itoa(i)
+		if it.Submenu != nil {
+			if sub == nil {
+				sub = new(Menu)
+			}
+			sub.Items = b.syncItems(sub.Items, it.Submenu.Items, id+"/")
+			mi.Submenu = sub
+		} else if it.Tag != nil {
+			tag := it.Tag
+			b.tags = append(b.tags, tag)
+			mi.Do = func() {
+				b.events = append(b.events, menu.Event{Tag: tag})
+			}
+			if !mi.Shortcut.IsZero() && !it.Disabled {
+				// Shortcuts bound to previous items take precedence.
+				b.shortcuts.Register(Command{ID: id, Title: it.Title, Shortcut: mi.Shortcut, Do: mi.Do})
+			}
+		}
+		dst[i] = mi
+	}
+	return dst
+}
+
+// sameMenu reports whether the menu trees a and b are identical.
+func sameMenu(a, b menu.Menu) bool {
+	if len(a.Items) != len(b.Items) {
+		return false
+	}
+	for i, it := range a.Items {
+		o := b.Items[i]
+		if (it.Submenu == nil) != (o.Submenu == nil) {
+			return false
+		}
+		if it.Submenu != nil && !sameMenu(*it.Submenu, *o.Submenu) {
+			return false
+		}
+		it.Submenu, o.Submenu = nil, nil
+		if it != o {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"gioui.org/io/key"
+	"gioui.org/io/menu"
+	"gioui.org/layout"
+)
+
+// frameBar lays out b with titles of 50x10 pixels, and its open menu with
+// items of 100x20 pixels.
+func (mt *menuTest) frameBar(b *MenuBar) {
+	mt.gtx.Ops.Reset()
+	b.Update(mt.gtx)
+	b.LayoutTitles(mt.gtx, func(gtx layout.Context, i int) layout.Dimensions {
+		return b.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{Size: image.Pt(50, 10)}
+		})
+	})
+	if i := b.Opened(); i != -1 {
+		b.Item(i).Submenu.Layout(mt.gtx, func(gtx layout.Context, m *Menu) layout.Dimensions {
+			return m.LayoutItems(gtx, func(gtx layout.Context, i int) layout.Dimensions {
+				return m.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Dimensions{Size: image.Pt(100, 20)}
+				})
+			})
+		})
+	}
+	mt.r.Frame(mt.gtx.Ops)
+}
+
+func (mt *menuTest) pressBar(b *MenuBar, names ...string) {
+	for _, n := range names {
+		mt.r.Queue(key.Event{Name: n, State: key.Press})
+		// Handle the key, lay out the new menu and deliver its
+		// focus.
+		mt.frameBar(b)
+		mt.frameBar(b)
+		mt.frameBar(b)
+	}
+}
+
+func TestMenuBarNavigation(t *testing.T) {
+	var open, save, wrap, about int
+	b := &MenuBar{Menu: menu.Menu{Items: []menu.Item{
+		{Title: "File", Submenu: &menu.Menu{Items: []menu.Item{
+			{Title: "Open", Tag: &open},
+			{Title: "Save", Tag: &save, Shortcut: menu.Shortcut{Modifiers: key.ModShortcut, Name: "S"}},
+		}}},
+		{Title: "Disabled", Disabled: true, Submenu: &menu.Menu{}},
+		{Title: "View", Submenu: &menu.Menu{Items: []menu.Item{
+			{Title: "Word Wrap", Tag: &wrap, Checkable: true},
+		}}},
+		{Title: "Help", Submenu: &menu.Menu{Items: []menu.Item{
+			{Title: "About", Tag: &about, Disabled: true},
+		}}},
+	}}}
+	mt := newMenuTest(t, image.Pt(400, 400))
+	mt.frameBar(b)
+	if b.Opened() != -1 {
+		t.Fatal("menu open before F10")
+	}
+
+	// F10 opens the first menu.
+	if !b.Dispatch(key.Event{Name: key.NameF10, State: key.Press}) {
+		t.Fatal("F10 not handled")
+	}
+	mt.frameBar(b)
+	mt.frameBar(b)
+	file := b.Item(0).Submenu
+	if b.Opened() != 0 || !file.Focused() || file.Selected() != 0 {
+		t.Fatalf("F10 opened menu %d; expected the focused File menu", b.Opened())
+	}
+	// The menu is placed below its title.
+	if got, exp := file.rect.Min, image.Pt(0, 10); got != exp {
+		t.Errorf("menu at %v; expected %v", got, exp)
+	}
+
+	// Right skips the disabled menu, and wraps around.
+	mt.pressBar(b, key.NameRightArrow)
+	if got := b.Opened(); got != 2 {
+		t.Fatalf("right arrow opened menu %d; expected 2", got)
+	}
+	if view := b.Item(2).Submenu; !view.Focused() || view.rect.Min != image.Pt(100, 10) {
+		t.Errorf("View menu not focused below its title")
+	}
+	mt.pressBar(b, key.NameRightArrow, key.NameRightArrow)
+	if got := b.Opened(); got != 0 {
+		t.Fatalf("right arrow opened menu %d; expected to wrap around to 0", got)
+	}
+	mt.pressBar(b, key.NameLeftArrow)
+	if got := b.Opened(); got != 3 {
+		t.Fatalf("left arrow opened menu %d; expected 3", got)
+	}
+
+	// Choosing an item delivers its event.
+	mt.pressBar(b, key.NameLeftArrow, key.NameReturn)
+	if got, exp := b.Events(), []menu.Event{{Tag: &wrap}}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("got events %v; expected %v", got, exp)
+	}
+	if b.Opened() != -1 {
+		t.Error("choosing an item did not close the menu")
+	}
+
+	// Escape closes the menu.
+	b.Dispatch(key.Event{Name: key.NameF10, State: key.Press})
+	mt.frameBar(b)
+	mt.pressBar(b, key.NameEscape)
+	if b.Opened() != -1 {
+		t.Error("escape did not close the menu")
+	}
+
+	// Shortcuts choose items without opening their menus.
+	if !b.Dispatch(key.Event{Name: "S", Modifiers: key.ModShortcut, State: key.Press}) {
+		t.Error("shortcut not handled")
+	}
+	if got, exp := b.Events(), []menu.Event{{Tag: &save}}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("got events %v; expected %v", got, exp)
+	}
+	// Disabled items don't respond to their shortcuts.
+	b.Menu.Items[0].Submenu.Items[1].Disabled = true
+	if b.Dispatch(key.Event{Name: "S", Modifiers: key.ModShortcut, State: key.Press}) {
+		t.Error("shortcut of disabled item handled")
+	}
+
+	// The events of native menu bars are delivered through Events.
+	mt.r.Queue(menu.Event{Tag: &open})
+	mt.frameBar(b)
+	if got, exp := b.Events(), []menu.Event{{Tag: &open}}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("got events %v; expected %v", got, exp)
+	}
+
+	// Items are only rebuilt when the model changes.
+	check := b.Item(2).Submenu.Items[0].Bool
+	mt.frameBar(b)
+	if b.Item(2).Submenu.Items[0].Bool != check {
+		t.Error("items rebuilt for an unchanged model")
+	}
+	b.Menu.Items[2].Submenu.Items[0].Checked = true
+	mt.frameBar(b)
+	if c := b.Item(2).Submenu.Items[0].Bool; !c.Value {
+		t.Error("item not checked after checking its model")
+	}
+}
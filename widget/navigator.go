@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/anim"
+	"gioui.org/f32"
+	"gioui.org/gesture"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+)
+
+// Navigator holds a stack of pages, and animates the transitions between
+// the top page and the page below it. Pages are pushed and popped
+// programmatically, by system back commands through HandleBack, or by
+// back swipes from the left edge.
+type Navigator struct {
+	// Transition draws the pages during transitions. If nil,
+	// SlideTransition with a translucent black scrim is used.
+	Transition Transition
+	// Swipe recognizes back swipes. Its Edge defaults to
+	// gesture.EdgeLeft.
+	Swipe gesture.EdgeSwipe
+
+	pages []layout.Widget
+	// progress is the visible fraction of the top page.
+	progress anim.Value[float32]
+	// popping is set while the top page is leaving.
+	popping bool
+	// started is set by Push and Pop until the next Layout starts
+	// their animations.
+	started bool
+	swiping bool
+	// last is the progress of the most recent Layout.
+	last float32
+}
+
+// Transition draws a transition between the page below and the page
+// above it. Progress is the visible fraction of above, 0 when only below
+// is visible and 1 when above has fully arrived. Transitions can't fade
+// the pages, because there is no operation for the opacity of a group
+// of operations.
+type Transition func(gtx layout.Context, progress float32, below, above layout.Widget) layout.Dimensions
+
+// navTransitionDuration is the duration of page transitions.
+const navTransitionDuration = 300 * time.Millisecond
+
+// Push a page on the stack, and animate its arrival. A departing page
+// is removed first, ending its animation or back swipe.
+func (n *Navigator) Push(page layout.Widget) {
+	if n.popping {
+		n.removeTop()
+		n.popping = false
+		n.swiping = false
+	}
+	n.pages = append(n.pages, page)
+	n.started = true
+	if len(n.pages) == 1 {
+		// The first page has nothing to arrive on.
+		n.started = false
+		n.progress = anim.Value[float32]{}
+		n.progress.Set(time.Time{}, 1, 0, nil)
+	}
+}
+
+// Pop animates the departure of the top page, and removes it from the
+// stack when the animation ends. Pop does nothing if the stack holds
+// fewer than two pages.
+func (n *Navigator) Pop() {
+	if len(n.pages) < 2 || n.popping {
+		return
+	}
+	n.popping = true
+	n.started = true
+}
+
+// HandleBack pops the top page for system back commands, and cancels the
+// default action of the command if there was a page to pop. Call
+// HandleBack with the CommandEvents of the window.
+//
+// A back command animates the whole departure of the top page; the
+// progress of system back gestures, such as the predictive back of
+// Android, is not delivered to the program.
+func (n *Navigator) HandleBack(e *system.CommandEvent) {
+	if e.Type != system.CommandBack || len(n.pages) < 2 {
+		return
+	}
+	e.Cancel = true
+	n.Pop()
+}
+
+// Len returns the number of pages on the stack, including a departing
+// page.
+func (n *Navigator) Len() int {
+	return len(n.pages)
+}
+
+// Progress returns the visible fraction of the top page in the most
+// recent Layout.
+func (n *Navigator) Progress() float32 {
+	return n.last
+}
+
+// Transitioning reports whether a transition was in progress in the most
+// recent Layout.
+func (n *Navigator) Transitioning() bool {
+	return n.last < 1
+}
+
+// Layout the top page, or the transition between the top page and the
+// page below it.
+func (n *Navigator) Layout(gtx layout.Context) layout.Dimensions {
+	if len(n.pages) == 0 {
+		n.last = 1
+		return layout.Dimensions{Size: gtx.Constraints.Min}
+	}
+	n.update(gtx)
+	p := n.progress.Get(gtx.Now)
+	if n.popping && !n.progress.Animating(gtx.Now) && !n.swiping {
+		// The top page has left.
+		n.removeTop()
+		n.popping = false
+		n.progress.Set(gtx.Now, 1, 0, nil)
+		p = 1
+	}
+	n.last = p
+	top := n.pages[len(n.pages)-1]
+	var dims layout.Dimensions
+	if p >= 1 || len(n.pages) < 2 {
+		dims = top(gtx)
+	} else {
+		tr := n.Transition
+		if tr == nil {
+			tr = SlideTransition(color.NRGBA{A: 0x66})
+		}
+		// Pages don't receive events while moving.
+		dims = tr(gtx.Disabled(), p, n.pages[len(n.pages)-2], top)
+		if n.progress.Animating(gtx.Now) {
+			// Swipes redraw by their events.
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+	if len(n.pages) > 1 && !n.popping || n.swiping {
+		n.Swipe.Add(gtx.Ops, gtx.Metric, image.Rectangle{Max: gtx.Constraints.Max})
+	}
+	return dims
+}
+
+func (n *Navigator) removeTop() {
+	n.pages[len(n.pages)-1] = nil
+	n.pages = n.pages[:len(n.pages)-1]
+}
+
+func (n *Navigator) update(gtx layout.Context) {
+	n.Swipe.Config = gtx.Gesture
+	for _, e := range n.Swipe.Events(gtx.Metric, gtx) {
+		switch e.Type {
+		case gesture.SwipeMove:
+			if len(n.pages) < 2 {
+				break
+			}
+			n.swiping = true
+			n.popping = true
+			n.started = false
+			n.progress.Set(gtx.Now, 1-e.Progress, 0, nil)
+		case gesture.SwipeRelease, gesture.SwipeCancel:
+			if !n.swiping {
+				break
+			}
+			n.swiping = false
+			target := float32(1)
+			n.popping = e.Type == gesture.SwipeRelease && e.Open
+			if n.popping {
+				target = 0
+			}
+			// Finish the remaining distance in proportion.
+			rem := target - n.progress.Get(gtx.Now)
+			if rem < 0 {
+				rem = -rem
+			}
+			n.progress.Set(gtx.Now, target, time.Duration(rem*float32(navTransitionDuration)), anim.Spring)
+		}
+	}
+	if !n.started {
+		return
+	}
+	n.started = false
+	if n.popping {
+		n.progress.Set(gtx.Now, 0, navTransitionDuration, anim.Spring)
+	} else {
+		n.progress.Set(gtx.Now, 0, 0, nil)
+		n.progress.Set(gtx.Now, 1, navTransitionDuration, anim.Spring)
+	}
+}
+
+// SlideTransition returns a Transition that slides the page above in
+// from the right, over the page below covered by a scrim that darkens as
+// the page above arrives.
+func SlideTransition(scrim color.NRGBA) Transition {
+	return func(gtx layout.Context, progress float32, below, above layout.Widget) layout.Dimensions {
+		size := gtx.Constraints.Max
+		below(gtx)
+		s := scrim
+		s.A = uint8(float32(s.A)*progress + .5)
+		paint.FillShape(gtx.Ops, s, clip.Rect{Max: size}.Op())
+		x := (1 - progress) * float32(size.X)
+		defer op.Offset(f32.Pt(x, 0)).Push(gtx.Ops).Pop()
+		above(gtx)
+		return layout.Dimensions{Size: size}
+	}
+}
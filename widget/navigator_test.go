@@ -0,0 +1,298 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+func TestNavigator(t *testing.T) {
+	var r router.Router
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Metric:      unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Constraints: layout.Exact(image.Pt(400, 300)),
+		Queue:       &r,
+		Now:         time.Unix(0, 0),
+	}
+	page := func(gtx layout.Context) layout.Dimensions {
+		return layout.Dimensions{Size: gtx.Constraints.Max}
+	}
+	var n Navigator
+	// frame lays out n and returns its progress.
+	frame := func() float32 {
+		gtx.Now = gtx.Now.Add(16 * time.Millisecond)
+		gtx.Ops.Reset()
+		n.Layout(gtx)
+		r.Frame(gtx.Ops)
+		return n.Progress()
+	}
+	// settle lays out frames until the transition ends, and checks that
+	// the progress moves towards the end.
+	settle := func(increasing bool) {
+		t.Helper()
+		prev := n.Progress()
+		for i := 0; n.Transitioning(); i++ {
+			if i == 100 {
+				t.Fatal("transition didn't end")
+			}
+			p := frame()
+			if p != 1 && (increasing && p < prev || !increasing && p > prev) {
+				t.Fatalf("progress moved from %v to %v", prev, p)
+			}
+			prev = p
+		}
+	}
+	// swipe drags from the left edge to x, and releases slowly.
+	now := time.Duration(0)
+	swipe := func(x, exp float32) {
+		t.Helper()
+		now += time.Second
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: f32.Pt(5, 50), Time: now},
+			pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(x, 50), Time: now + 50*time.Millisecond},
+		)
+		if p := frame(); p < exp-.01 || p > exp+.01 {
+			t.Errorf("progress %v during swipe, expected %v", p, exp)
+		}
+		r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: f32.Pt(x, 50), Time: now + 100*time.Millisecond})
+	}
+
+	n.Push(page)
+	if frame(); n.Transitioning() {
+		t.Error("first page transitioned")
+	}
+	n.Push(page)
+	if p := frame(); p != 0 {
+		t.Errorf("pushed page starts at progress %v", p)
+	}
+	if p := frame(); p <= 0 || p >= 1 {
+		t.Errorf("progress %v while pushing", p)
+	}
+	settle(true)
+	if n.Len() != 2 {
+		t.Fatalf("%d pages after push, expected 2", n.Len())
+	}
+
+	// A partial swipe cancels and returns the top page.
+	swipe(5+.3*280, .7)
+	settle(true)
+	if n.Len() != 2 || n.Progress() != 1 {
+		t.Errorf("%d pages, progress %v after a canceled swipe; expected 2, 1", n.Len(), n.Progress())
+	}
+
+	// A completed swipe pops the top page.
+	swipe(5+.8*280, .2)
+	settle(false)
+	if n.Len() != 1 || n.Progress() != 1 {
+		t.Errorf("%d pages, progress %v after a completed swipe; expected 1, 1", n.Len(), n.Progress())
+	}
+
+	// System back commands pop pages too.
+	e := &system.CommandEvent{Type: system.CommandBack}
+	n.HandleBack(e)
+	if e.Cancel {
+		t.Error("back command canceled without a page to pop")
+	}
+	n.Push(page)
+	settle(true)
+	frame()
+	n.HandleBack(e)
+	if !e.Cancel {
+		t.Error("back command not canceled")
+	}
+	frame()
+	settle(false)
+	if n.Len() != 1 {
+		t.Errorf("%d pages after back, expected 1", n.Len())
+	}
+}
+
+func TestNavigatorPushWhilePopping(t *testing.T) {
+	var r router.Router
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(400, 300)),
+		Queue:       &r,
+		Now:         time.Unix(0, 0),
+	}
+	var top string
+	page := func(name string) layout.Widget {
+		return func(gtx layout.Context) layout.Dimensions {
+			top = name
+			return layout.Dimensions{Size: gtx.Constraints.Max}
+		}
+	}
+	var n Navigator
+	frame := func() {
+		gtx.Now = gtx.Now.Add(16 * time.Millisecond)
+		gtx.Ops.Reset()
+		n.Layout(gtx)
+		r.Frame(gtx.Ops)
+	}
+	settle := func() {
+		t.Helper()
+		for i := 0; n.Transitioning(); i++ {
+			if i == 100 {
+				t.Fatal("transition didn't end")
+			}
+			frame()
+		}
+	}
+	n.Push(page("a"))
+	n.Push(page("b"))
+	frame()
+	settle()
+	n.Pop()
+	frame()
+	frame()
+	if !n.Transitioning() {
+		t.Fatal("pop didn't animate")
+	}
+	// Pushing during the pop removes the departing page.
+	n.Push(page("c"))
+	if n.Len() != 2 {
+		t.Errorf("%d pages after push during pop, expected 2", n.Len())
+	}
+	frame()
+	settle()
+	if n.Len() != 2 || top != "c" {
+		t.Errorf("%d pages with top page %q, expected 2 and c", n.Len(), top)
+	}
+	n.Pop()
+	frame()
+	frame()
+	settle()
+	if n.Len() != 1 || top != "a" {
+		t.Errorf("%d pages with top page %q after pop, expected 1 and a", n.Len(), top)
+	}
+}
+
+func TestNavigatorSwipeRedraws(t *testing.T) {
+	var r router.Router
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Metric:      unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Constraints: layout.Exact(image.Pt(400, 300)),
+		Queue:       &r,
+		Now:         time.Unix(0, 0),
+	}
+	page := func(gtx layout.Context) layout.Dimensions {
+		return layout.Dimensions{Size: gtx.Constraints.Max}
+	}
+	var n Navigator
+	frame := func() {
+		gtx.Now = gtx.Now.Add(16 * time.Millisecond)
+		gtx.Ops.Reset()
+		n.Layout(gtx)
+		r.Frame(gtx.Ops)
+	}
+	n.Push(page)
+	n.Push(page)
+	for i := 0; i < 50; i++ {
+		frame()
+	}
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: f32.Pt(5, 50), Time: time.Second},
+		pointer.Event{Type: pointer.Move, Source: pointer.Touch, Position: f32.Pt(100, 50), Time: time.Second + 50*time.Millisecond},
+	)
+	frame()
+	frame()
+	if !n.Transitioning() {
+		t.Fatal("no transition during the swipe")
+	}
+	// A held swipe doesn't move, and needs no redraws.
+	if _, ok := r.WakeupTime(); ok {
+		t.Error("redraw requested for a held swipe")
+	}
+}
+
+func TestNavigatorBackCommand(t *testing.T) {
+	var r router.Router
+	page := func(gtx layout.Context) layout.Dimensions {
+		return layout.Dimensions{Size: gtx.Constraints.Max}
+	}
+	var n Navigator
+	n.Push(page)
+	n.Push(page)
+	// The window events: a back command while the top page is shown,
+	// the frames of the transition, and a back command with a single
+	// page left.
+	now := time.Unix(0, 0)
+	frame := func() system.FrameEvent {
+		now = now.Add(16 * time.Millisecond)
+		return system.FrameEvent{
+			Now:    now,
+			Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+			Size:   image.Pt(400, 300),
+			Frame:  r.Frame,
+			Queue:  &r,
+		}
+	}
+	back1, back2 := &system.CommandEvent{Type: system.CommandBack}, &system.CommandEvent{Type: system.CommandBack}
+	events := make(chan event.Event, 100)
+	for i := 0; i < 30; i++ {
+		events <- frame()
+	}
+	events <- back1
+	for i := 0; i < 60; i++ {
+		events <- frame()
+	}
+	events <- back2
+	close(events)
+
+	var ops op.Ops
+	var progress []float32
+	for e := range events {
+		switch e := e.(type) {
+		case *system.CommandEvent:
+			n.HandleBack(e)
+			if e == back1 {
+				// Record the progress of the departure.
+				progress = progress[:0]
+			}
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			n.Layout(gtx)
+			e.Frame(gtx.Ops)
+			progress = append(progress, n.Progress())
+		}
+	}
+	if !back1.Cancel {
+		t.Error("back command with two pages not canceled")
+	}
+	if back2.Cancel {
+		t.Error("back command with a single page canceled")
+	}
+	if n.Len() != 1 {
+		t.Errorf("%d pages after back, expected 1", n.Len())
+	}
+	// The departure animates the progress down from 1, and ends with
+	// the page below fully visible.
+	if len(progress) == 0 || progress[len(progress)-1] != 1 {
+		t.Fatalf("transition didn't end: %v", progress)
+	}
+	animated := false
+	for i := 1; i < len(progress); i++ {
+		if p := progress[i]; p != 1 {
+			animated = true
+			if p > progress[i-1] {
+				t.Errorf("progress moved from %v to %v", progress[i-1], p)
+			}
+		}
+	}
+	if !animated {
+		t.Error("back command didn't animate the departure")
+	}
+}
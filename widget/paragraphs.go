@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"strings"
+
+	"gioui.org/text"
+	"golang.org/x/image/math/fixed"
+)
+
+// paragraphCache caches the line layouts of the paragraphs, or hard
+// lines, of a text. Laying out an edited text re-shapes only the
+// paragraphs that changed, and splices their lines between the lines
+// of the unchanged paragraphs. A paragraph is laid out independently of
+// its neighbours, because line breaking and kerning reset at newlines.
+type paragraphCache struct {
+	shaper   text.Shaper
+	font     text.Font
+	size     fixed.Int26_6
+	maxWidth int
+	// paras are the laid out paragraphs of the text, in order.
+	paras []paragraph
+	// lines are the lines of all paras.
+	lines []text.Line
+	// scratch holds the paragraphs of the new text during layout.
+	scratch []paragraph
+}
+
+// paragraph is a hard line of text including its newline, and its
+// lines.
+type paragraph struct {
+	text  string
+	lines []text.Line
+}
+
+// layout returns the lines of txt, reusing the lines of the paragraphs
+// that are unchanged since the previous layout with the same shaper,
+// font, size and width. The returned slice is valid until the next call
+// to layout.
+func (c *paragraphCache) layout(s text.Shaper, font text.Font, size fixed.Int26_6, maxWidth int, txt string) []text.Line {
+	if s != c.shaper || font != c.font || size != c.size || maxWidth != c.maxWidth {
+		*c = paragraphCache{shaper: s, font: font, size: size, maxWidth: maxWidth}
+	}
+	paras := c.scratch[:0]
+	for {
+		n := strings.IndexByte(txt, '\n') + 1
+		if n == 0 {
+			// The last paragraph has no newline, and may be empty.
+			paras = append(paras, paragraph{text: txt})
+			break
+		}
+		paras = append(paras, paragraph{text: txt[:n]})
+		txt = txt[n:]
+	}
+	old := c.paras
+	// Match the unchanged paragraphs at both ends.
+	prefix := 0
+	for prefix < len(old) && prefix < len(paras) && old[prefix].text == paras[prefix].text {
+		paras[prefix] = old[prefix]
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(paras)-prefix {
+		o, p := len(old)-1-suffix, len(paras)-1-suffix
+		if old[o].text != paras[p].text {
+			break
+		}
+		paras[p] = old[o]
+		suffix++
+	}
+	for i := prefix; i < len(paras)-suffix; i++ {
+		p := &paras[i]
+		// Copy the text to not retain the previous versions of the
+		// whole text.
+		p.text = string([]byte(p.text))
+		p.lines = layoutParagraph(s, font, size, maxWidth, p.text)
+	}
+	c.scratch = old[:0]
+	c.paras = paras
+	lines := c.lines[:0]
+	for _, p := range paras {
+		lines = append(lines, p.lines...)
+	}
+	c.lines = lines
+	return lines
+}
+
+// layoutParagraph lays out a paragraph. The empty line a shaper adds
+// after a trailing newline belongs to the next paragraph.
+func layoutParagraph(s text.Shaper, font text.Font, size fixed.Int26_6, maxWidth int, para string) []text.Line {
+	lines, _ := s.Layout(font, size, maxWidth, strings.NewReader(para))
+	if n := len(lines); n > 1 && strings.HasSuffix(para, "\n") && lines[n-1].Layout.Text == "" {
+		lines = lines[:n-1]
+	}
+	return lines
+}
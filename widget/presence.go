@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image/color"
+	"time"
+
+	"gioui.org/anim"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+// Presence animates the entry and exit of a widget that is shown
+// conditionally. A hidden widget keeps being laid out until its exit
+// animation ends, and the widget reads the Progress of the animation and
+// composes the Slide, Scale and Fade modifiers to draw the transition.
+// Showing or hiding the widget during an animation reverses it from the
+// current progress.
+//
+// The zero Presence is hidden; Show animates the entry of the widget.
+type Presence struct {
+	// Duration is the duration of a complete transition. Zero means
+	// 250 milliseconds.
+	Duration time.Duration
+	// Curve maps the progress of transitions. Nil means anim.Spring.
+	Curve anim.Curve
+
+	progress anim.Value[float32]
+	visible  bool
+	// changed is set by Show and Hide until the next Layout starts
+	// their animations.
+	changed bool
+	// present is set while the widget is laid out.
+	present bool
+	exited  bool
+	// last is the progress of the most recent Layout.
+	last float32
+}
+
+// defaultPresenceDuration is the default Presence.Duration.
+const defaultPresenceDuration = 250 * time.Millisecond
+
+// Show the widget, animating its entry.
+func (p *Presence) Show() {
+	p.SetVisible(true)
+}
+
+// Hide the widget, animating its exit.
+func (p *Presence) Hide() {
+	p.SetVisible(false)
+}
+
+// SetVisible shows or hides the widget.
+func (p *Presence) SetVisible(visible bool) {
+	if visible != p.visible {
+		p.visible = visible
+		p.changed = true
+	}
+}
+
+// Visible reports whether the widget is shown, regardless of its
+// animation.
+func (p *Presence) Visible() bool {
+	return p.visible
+}
+
+// Present reports whether the widget was laid out by the most recent
+// Layout, because it was shown or exiting.
+func (p *Presence) Present() bool {
+	return p.present
+}
+
+// Exited reports whether the exit of the widget ended in the most recent
+// Layout, after which the state of the widget may be discarded.
+func (p *Presence) Exited() bool {
+	return p.exited
+}
+
+// Progress returns the visible fraction of the widget in the most recent
+// Layout, from 0 when absent to 1 when fully present.
+func (p *Presence) Progress() float32 {
+	return p.last
+}
+
+// Layout the widget while it is shown or exiting. The widget receives
+// no events while exiting.
+func (p *Presence) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	p.update(gtx)
+	p.exited = false
+	v := p.progress.Get(gtx.Now)
+	animating := p.progress.Animating(gtx.Now)
+	if !p.visible && !animating {
+		if p.present {
+			p.present = false
+			p.exited = true
+		}
+		p.last = 0
+		return layout.Dimensions{}
+	}
+	p.present = true
+	p.last = v
+	if animating {
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	if !p.visible {
+		gtx = gtx.Disabled()
+	}
+	return w(gtx)
+}
+
+func (p *Presence) update(gtx layout.Context) {
+	if !p.changed {
+		return
+	}
+	p.changed = false
+	target := float32(0)
+	if p.visible {
+		target = 1
+	}
+	d := p.Duration
+	if d == 0 {
+		d = defaultPresenceDuration
+	}
+	c := p.Curve
+	if c == nil {
+		c = anim.Spring
+	}
+	// Cover the remaining distance in proportion, so that an
+	// interrupted transition reverses at the same pace.
+	rem := target - p.progress.Get(gtx.Now)
+	if rem < 0 {
+		rem = -rem
+	}
+	p.progress.Set(gtx.Now, target, time.Duration(rem*float32(d)), c)
+}
+
+// Slide lays out w offset by from when absent, moving to its place as
+// it becomes present.
+func (p *Presence) Slide(gtx layout.Context, from f32.Point, w layout.Widget) layout.Dimensions {
+	defer op.Offset(from.Mul(1 - p.last)).Push(gtx.Ops).Pop()
+	return w(gtx)
+}
+
+// Scale lays out w scaled about pivot by the progress, from min when
+// absent to 1 when present.
+func (p *Presence) Scale(gtx layout.Context, pivot f32.Point, min float32, w layout.Widget) layout.Dimensions {
+	s := min + (1-min)*p.last
+	defer op.Affine(f32.Affine2D{}.Scale(pivot, f32.Pt(s, s))).Push(gtx.Ops).Pop()
+	return w(gtx)
+}
+
+// Fade returns c with its alpha scaled by the progress, for fading the
+// colors of the widget. The progress is clamped to [0, 1] for curves
+// that overshoot, such as anim.Spring.
+func (p *Presence) Fade(c color.NRGBA) color.NRGBA {
+	v := p.last
+	if v < 0 {
+		v = 0
+	}
+	if v > 1 {
+		v = 1
+	}
+	c.A = uint8(float32(c.A)*v + .5)
+	return c
+}
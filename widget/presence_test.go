@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"gioui.org/anim"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+func TestPresence(t *testing.T) {
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(100, 100)),
+		Now:         time.Unix(0, 0),
+	}
+	p := Presence{Duration: 160 * time.Millisecond, Curve: anim.Linear}
+	laidOut := false
+	// frame lays out p and returns its progress.
+	frame := func() float32 {
+		gtx.Now = gtx.Now.Add(16 * time.Millisecond)
+		gtx.Ops.Reset()
+		laidOut = false
+		p.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			laidOut = true
+			return p.Slide(gtx, f32.Pt(0, 50), func(gtx layout.Context) layout.Dimensions {
+				return p.Scale(gtx, f32.Pt(50, 50), .5, func(gtx layout.Context) layout.Dimensions {
+					return layout.Dimensions{Size: gtx.Constraints.Max}
+				})
+			})
+		})
+		return p.Progress()
+	}
+	if frame(); laidOut || p.Present() {
+		t.Fatal("hidden widget laid out")
+	}
+	// step checks that the progress moved by at most one frame of the
+	// transition, towards the target after the first frame of a
+	// transition.
+	prev := float32(0)
+	step := func(first, entry bool) {
+		t.Helper()
+		v := frame()
+		d := v - prev
+		if !entry {
+			d = -d
+		}
+		if !laidOut || d > .11 || d < -.11 || !first && d <= 0 {
+			t.Fatalf("progress went from %v to %v, entering: %v, laid out: %v", prev, v, entry, laidOut)
+		}
+		prev = v
+	}
+	// Toggle the presence in the middle of the entry and exit.
+	p.Show()
+	for _, hideAt := range []int{5, 3} {
+		for i := 0; i < hideAt; i++ {
+			step(i == 0, true)
+		}
+		p.Hide()
+		step(true, false)
+		step(false, false)
+		p.Show()
+	}
+	step(true, true)
+	if c := p.Fade(color.NRGBA{A: 200}); c.A != uint8(200*p.Progress()+.5) {
+		t.Errorf("faded alpha %d at progress %v", c.A, p.Progress())
+	}
+	// Let the exit run to completion.
+	p.Hide()
+	step(true, false)
+	end := gtx.Now.Add(time.Duration(prev * float32(p.Duration)))
+	for {
+		if gtx.Now.Add(16 * time.Millisecond).Before(end) {
+			if step(false, false); p.Exited() {
+				t.Fatal("exited before the end of the exit")
+			}
+			continue
+		}
+		v := frame()
+		if laidOut || p.Present() || !p.Exited() || v != 0 {
+			t.Errorf("exit at %v after %v: laid out %v with progress %v, exited: %v", gtx.Now, end, laidOut, v, p.Exited())
+		}
+		break
+	}
+	if frame(); laidOut || p.Exited() {
+		t.Error("laid out or exited after the exit")
+	}
+}
+
+func TestPresenceFadeOvershoot(t *testing.T) {
+	for _, tc := range []struct {
+		progress float32
+		alpha    uint8
+	}{
+		{1.2, 200},
+		{-.1, 0},
+		{.5, 100},
+	} {
+		p := Presence{last: tc.progress}
+		if got := p.Fade(color.NRGBA{A: 200}).A; got != tc.alpha {
+			t.Errorf("progress %v: faded alpha to %d, expected %d", tc.progress, got, tc.alpha)
+		}
+	}
+}
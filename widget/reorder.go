@@ -0,0 +1,309 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"math"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/unit"
+)
+
+// Reorder implements the reordering of the elements of a layout.List by
+// dragging them. Mouse and pen pointers pick up an element by dragging
+// it, touch pointers by a long press, so that touch drags still scroll
+// the list. The picked element follows the pointer above the list, while
+// a gap of its size opens where it would be dropped. Dropping the
+// element at a new index reports a ReorderEvent; moving the element in
+// the underlying data is left to the program.
+type Reorder struct {
+	// LongPress is how long a touch pointer must press an element to
+	// pick it up. Zero means 500 milliseconds.
+	LongPress time.Duration
+
+	// pressed is set while a pointer presses an element, and dragging
+	// once the element is picked up.
+	pressed  bool
+	dragging bool
+	pid      pointer.ID
+	source   pointer.Source
+	press    f32.Point
+	pressAt  time.Time
+	pos      f32.Point
+	// from is the index of the picked element, and to the index it
+	// would be dropped at.
+	from, to int
+	// grab is the position of the pointer along the main axis,
+	// relative to the picked element.
+	grab float32
+	// size is the size of the picked element.
+	size image.Point
+	// slots are the extents of the elements laid out by the most
+	// recent Layout, in the order of the list.
+	slots  []reorderSlot
+	events []ReorderEvent
+}
+
+// ReorderEvent reports the drop of the element at index From to index
+// To, counted after removing the element from its old index.
+type ReorderEvent struct {
+	From, To int
+}
+
+// reorderSlot is the extent of a laid out element along the main axis.
+type reorderSlot struct {
+	index      int
+	start, end int
+	size       image.Point
+}
+
+// defaultReorderLongPress is the default Reorder.LongPress.
+const defaultReorderLongPress = 500 * time.Millisecond
+
+// Layout the list with len elements drawn by w, and track the dragging
+// of its elements. While an element is dragged, w is called with the
+// indices of the elements in their new order.
+func (r *Reorder) Layout(gtx layout.Context, l *layout.List, len int, w layout.ListElement) layout.Dimensions {
+	r.update(gtx, l.Axis, len)
+	sizes := make(map[int]image.Point)
+	dims := l.Layout(gtx, len, func(gtx layout.Context, slot int) layout.Dimensions {
+		var dims layout.Dimensions
+		if r.dragging && slot == r.to {
+			// The gap for the picked element.
+			dims.Size = r.size
+		} else {
+			dims = w(gtx, r.element(slot))
+		}
+		sizes[slot] = dims.Size
+		return dims
+	})
+	r.record(l, sizes)
+
+	defer clip.Rect{Max: dims.Size}.Push(gtx.Ops).Pop()
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	pointer.InputOp{
+		Tag:   r,
+		Grab:  r.dragging,
+		Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel,
+	}.Add(gtx.Ops)
+	pass.Pop()
+
+	if r.dragging {
+		// Draw the picked element above the list, following the
+		// pointer along the main axis.
+		m := op.Record(gtx.Ops)
+		off := l.Axis.Convert(image.Pt(int(math.Round(float64(mainAxis(l.Axis, r.pos)-r.grab))), 0))
+		op.Offset(layout.FPt(off)).Add(gtx.Ops)
+		egtx := gtx
+		egtx.Constraints.Min = image.Point{}
+		max := l.Axis.Convert(egtx.Constraints.Max)
+		max.X = inf
+		egtx.Constraints.Max = l.Axis.Convert(max)
+		w(egtx, r.from)
+		op.Defer(gtx.Ops, m.Stop())
+	}
+	return dims
+}
+
+// Events returns the reorders since the previous call.
+func (r *Reorder) Events() []ReorderEvent {
+	events := r.events
+	r.events = nil
+	return events
+}
+
+// Dragging returns the index of the picked element, if any.
+func (r *Reorder) Dragging() (index int, ok bool) {
+	return r.from, r.dragging
+}
+
+// element maps a slot of the list to the index of the element it shows.
+func (r *Reorder) element(slot int) int {
+	if !r.dragging {
+		return slot
+	}
+	switch {
+	case r.from < r.to && slot >= r.from && slot < r.to:
+		return slot + 1
+	case r.to < r.from && slot > r.to && slot <= r.from:
+		return slot - 1
+	}
+	return slot
+}
+
+// record the slots laid out by l, from their sizes.
+func (r *Reorder) record(l *layout.List, sizes map[int]image.Point) {
+	r.slots = r.slots[:0]
+	first := l.Position.First
+	pos := -l.Position.Offset
+	// Overscan elements are laid out before the first visible.
+	for {
+		sz, ok := sizes[first-1]
+		if !ok {
+			break
+		}
+		first--
+		pos -= l.Axis.Convert(sz).X
+	}
+	for i := first; ; i++ {
+		sz, ok := sizes[i]
+		if !ok {
+			break
+		}
+		end := pos + l.Axis.Convert(sz).X
+		r.slots = append(r.slots, reorderSlot{index: i, start: pos, end: end, size: sz})
+		pos = end
+	}
+}
+
+func (r *Reorder) update(gtx layout.Context, axis layout.Axis, len int) {
+	if r.dragging && r.from >= len {
+		r.cancel()
+	}
+	slop := gtx.Gesture.TouchSlop
+	if slop.V == 0 {
+		slop = unit.Dp(3)
+	}
+	for _, e := range gtx.Events(r) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Press:
+			if r.pressed || !(e.Buttons == pointer.ButtonPrimary || e.Source == pointer.Touch || e.Source == pointer.Pen) {
+				break
+			}
+			if _, ok := r.slotAt(mainAxis(axis, e.Position)); !ok {
+				break
+			}
+			r.pressed = true
+			r.pid = e.PointerID
+			r.source = e.Source
+			r.press, r.pos = e.Position, e.Position
+			r.pressAt = gtx.Now
+		case pointer.Drag:
+			if !r.pressed || e.PointerID != r.pid {
+				break
+			}
+			r.pos = e.Position
+			if r.dragging {
+				break
+			}
+			d := e.Position.Sub(r.press)
+			if moved := d.X*d.X+d.Y*d.Y > float32(gtx.Px(slop)*gtx.Px(slop)); moved {
+				if r.source == pointer.Touch {
+					// The touch is a scroll of the list.
+					r.pressed = false
+				} else {
+					r.start(axis)
+				}
+			}
+		case pointer.Release:
+			if !r.pressed || e.PointerID != r.pid {
+				break
+			}
+			if r.dragging {
+				r.pos = e.Position
+				r.target(axis, len)
+				if r.to != r.from {
+					r.events = append(r.events, ReorderEvent{From: r.from, To: r.to})
+				}
+			}
+			r.cancel()
+		case pointer.Cancel:
+			r.cancel()
+		}
+	}
+	if r.pressed && !r.dragging && r.source == pointer.Touch {
+		d := r.LongPress
+		if d == 0 {
+			d = defaultReorderLongPress
+		}
+		if at := r.pressAt.Add(d); gtx.Now.Before(at) {
+			op.InvalidateOp{At: at}.Add(gtx.Ops)
+		} else {
+			r.start(axis)
+		}
+	}
+	if r.dragging {
+		r.target(axis, len)
+	}
+}
+
+// start picking up the pressed element.
+func (r *Reorder) start(axis layout.Axis) {
+	s, ok := r.slotAt(mainAxis(axis, r.press))
+	if !ok {
+		r.cancel()
+		return
+	}
+	r.dragging = true
+	r.from, r.to = s.index, s.index
+	r.grab = mainAxis(axis, r.press) - float32(s.start)
+	r.size = s.size
+}
+
+func (r *Reorder) cancel() {
+	r.pressed = false
+	r.dragging = false
+}
+
+// target updates the drop index from the position of the center of the
+// picked element, among the laid out elements other than the gap.
+func (r *Reorder) target(axis layout.Axis, len int) {
+	c := mainAxis(axis, r.pos) - r.grab + float32(axis.Convert(r.size).X)/2
+	to := -1
+	last := -1
+	for _, s := range r.slots {
+		if s.index == r.to {
+			continue
+		}
+		rank := s.index
+		if rank > r.to {
+			rank--
+		}
+		if float32(s.start+s.end)/2 >= c {
+			to = rank
+			break
+		}
+		last = rank
+	}
+	if to == -1 {
+		if last == -1 {
+			return
+		}
+		to = last + 1
+	}
+	if to >= len {
+		to = len - 1
+	}
+	if to < 0 {
+		to = 0
+	}
+	r.to = to
+}
+
+// slotAt returns the laid out element at position v along the main
+// axis.
+func (r *Reorder) slotAt(v float32) (reorderSlot, bool) {
+	for _, s := range r.slots {
+		if v >= float32(s.start) && v < float32(s.end) {
+			return s, true
+		}
+	}
+	return reorderSlot{}, false
+}
+
+func mainAxis(axis layout.Axis, p f32.Point) float32 {
+	if axis == layout.Horizontal {
+		return p.X
+	}
+	return p.Y
+}
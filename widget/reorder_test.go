@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"reflect"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+func TestReorder(t *testing.T) {
+	var (
+		r   router.Router
+		ro  Reorder
+		ops op.Ops
+	)
+	l := &layout.List{Axis: layout.Vertical}
+	gtx := layout.Context{
+		Ops:         &ops,
+		Queue:       &r,
+		Constraints: layout.Exact(image.Pt(100, 100)),
+		Now:         time.Unix(0, 0),
+	}
+	// The elements are 10 pixels tall.
+	var order []int
+	frame := func() {
+		ops.Reset()
+		order = order[:0]
+		ro.Layout(gtx, l, 10, func(gtx layout.Context, index int) layout.Dimensions {
+			order = append(order, index)
+			return layout.Dimensions{Size: image.Pt(100, 10)}
+		})
+		r.Frame(&ops)
+	}
+	drag := func(y float32) {
+		r.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(50, y)})
+		frame()
+	}
+	frame()
+	// Drag element 0 by its center past the center of element 2.
+	r.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(50, 5)})
+	frame()
+	drag(15)
+	if i, ok := ro.Dragging(); !ok || i != 0 {
+		t.Fatalf("dragging %d, %v; expected element 0", i, ok)
+	}
+	drag(27)
+	// The elements move up to open a gap at index 2, and the dragged
+	// element is drawn last.
+	if exp := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 0}; !reflect.DeepEqual(order, exp) {
+		t.Errorf("laid out %v, expected %v", order, exp)
+	}
+	if evts := ro.Events(); len(evts) > 0 {
+		t.Errorf("reordered %v before the drop", evts)
+	}
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(50, 27)})
+	frame()
+	if evts, exp := ro.Events(), []ReorderEvent{{From: 0, To: 2}}; !reflect.DeepEqual(evts, exp) {
+		t.Errorf("got reorders %v, expected %v", evts, exp)
+	}
+	if _, ok := ro.Dragging(); ok {
+		t.Error("dragging after the drop")
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// statesVersion is the version of the MarshalStates encoding.
+const statesVersion = 1
+
+var errStates = errors.New("widget: invalid states encoding")
+
+// MarshalStates encodes the states of several widgets under their keys
+// into one blob, for saving the state of a user interface. The states
+// are typically a *widget.Editor or a layout.Position.
+func MarshalStates(states map[string]encoding.BinaryMarshaler) ([]byte, error) {
+	keys := make([]string, 0, len(states))
+	for k := range states {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buf := []byte{statesVersion}
+	var tmp [binary.MaxVarintLen64]byte
+	appendBytes := func(b []byte) {
+		n := binary.PutUvarint(tmp[:], uint64(len(b)))
+		buf = append(buf, tmp[:n]...)
+		buf = append(buf, b...)
+	}
+	for _, k := range keys {
+		data, err := states[k].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		appendBytes([]byte(k))
+		appendBytes(data)
+	}
+	return buf, nil
+}
+
+// UnmarshalStates restores the states encoded by MarshalStates. States
+// missing from data are left unchanged, and states in data without a
+// key in states are ignored. If data is invalid, no state is restored;
+// otherwise, every state is restored and the first error, in key order,
+// is returned.
+func UnmarshalStates(data []byte, states map[string]encoding.BinaryUnmarshaler) error {
+	if len(data) < 1 || data[0] != statesVersion {
+		return errStates
+	}
+	data = data[1:]
+	next := func() ([]byte, bool) {
+		n, m := binary.Uvarint(data)
+		if m <= 0 || n > uint64(len(data)-m) {
+			return nil, false
+		}
+		b := data[m : m+int(n)]
+		data = data[m+int(n):]
+		return b, true
+	}
+	entries := make(map[string][]byte)
+	for len(data) > 0 {
+		k, ok := next()
+		if !ok {
+			return errStates
+		}
+		v, ok := next()
+		if !ok {
+			return errStates
+		}
+		entries[string(k)] = v
+	}
+	keys := make([]string, 0, len(states))
+	for k := range states {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var err error
+	for _, k := range keys {
+		v, ok := entries[k]
+		if !ok {
+			continue
+		}
+		if err2 := states[k].UnmarshalBinary(v); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
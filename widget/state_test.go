@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"encoding"
+	"image"
+	"testing"
+
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+)
+
+func TestEditorState(t *testing.T) {
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(100, 20)),
+	}
+	cache := text.NewCache(gofont.Collection())
+	e := new(Editor)
+	e.SetText("one\ntwo\nthree\nfour\nfive\nsix")
+	e.SetCaret(10, 6)
+	e.Layout(gtx, cache, text.Font{}, unit.Px(10), nil)
+	e.scrollAbs(0, 15)
+	if e.scrollOff.Y != 15 {
+		t.Fatalf("editor didn't scroll")
+	}
+	data, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caret, err := e.MarshalCaret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e2 := new(Editor)
+	if err := e2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	e2.Layout(gtx, cache, text.Font{}, unit.Px(10), nil)
+	if e2.Text() != e.Text() || e2.caret.start != 10 || e2.caret.end != 6 || e2.scrollOff != e.scrollOff {
+		t.Errorf("restored %q, caret %d-%d, scroll %v; expected %q, 10-6, %v",
+			e2.Text(), e2.caret.start, e2.caret.end, e2.scrollOff, e.Text(), e.scrollOff)
+	}
+
+	// The caret is clamped to the restored text, not the text laid
+	// out before.
+	e4 := new(Editor)
+	e4.SetText("one")
+	e4.Layout(gtx, cache, text.Font{}, unit.Px(10), nil)
+	if err := e4.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if start, end := e4.Selection(); start != 10 || end != 6 {
+		t.Errorf("restored caret %d-%d before layout, expected 10-6", start, end)
+	}
+
+	// Restore the caret against a shorter text.
+	e3 := new(Editor)
+	e3.SetText("one")
+	if err := e3.UnmarshalBinary(caret); err != nil {
+		t.Fatal(err)
+	}
+	if start, end := e3.Selection(); start != 3 || end != 3 {
+		t.Errorf("restored caret %d-%d before layout, expected 3-3", start, end)
+	}
+	e3.Layout(gtx, cache, text.Font{}, unit.Px(10), nil)
+	if e3.Text() != "one" || e3.caret.start != 3 || e3.caret.end != 3 || e3.scrollOff != (image.Point{}) {
+		t.Errorf("restored %q, caret %d-%d, scroll %v; expected \"one\", 3-3, (0,0)",
+			e3.Text(), e3.caret.start, e3.caret.end, e3.scrollOff)
+	}
+
+	for _, c := range [][]byte{
+		nil,
+		{editorStateVersion},
+		append([]byte{editorStateVersion + 1}, data[1:]...),
+		caret[:len(caret)-1],
+		append(caret, 'x'),
+		append(data, 0xff),
+	} {
+		if err := e3.UnmarshalBinary(c); err == nil {
+			t.Errorf("%v: no error", c)
+		}
+		if e3.Text() != "one" || e3.caret.start != 3 {
+			t.Errorf("%v: editor changed", c)
+		}
+	}
+}
+
+func TestStates(t *testing.T) {
+	e := new(Editor)
+	e.SetText("hello")
+	e.SetCaret(2, 4)
+	pos := layout.Position{First: 3, Offset: 7}
+	data, err := MarshalStates(map[string]encoding.BinaryMarshaler{
+		"editor": e,
+		"list":   pos,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		e2   Editor
+		pos2 layout.Position
+		pos3 = layout.Position{First: 1}
+	)
+	err = UnmarshalStates(data, map[string]encoding.BinaryUnmarshaler{
+		"editor":  &e2,
+		"list":    &pos2,
+		"missing": &pos3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e2.Text() != "hello" || e2.caret.start != 2 || e2.caret.end != 4 {
+		t.Errorf("restored editor %q, caret %d-%d", e2.Text(), e2.caret.start, e2.caret.end)
+	}
+	if pos2 != pos {
+		t.Errorf("restored position %+v, expected %+v", pos2, pos)
+	}
+	if pos3 != (layout.Position{First: 1}) {
+		t.Errorf("missing state changed to %+v", pos3)
+	}
+
+	// A corrupt blob restores nothing.
+	for _, c := range [][]byte{
+		nil,
+		{statesVersion + 1},
+		data[:len(data)-1],
+		append(data, 1),
+	} {
+		var pos layout.Position
+		if err := UnmarshalStates(c, map[string]encoding.BinaryUnmarshaler{"list": &pos}); err == nil {
+			t.Errorf("%v: no error", c)
+		}
+		if pos != (layout.Position{}) {
+			t.Errorf("%v: restored %+v", c, pos)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/unit"
+)
+
+// TextZoom holds the scale of text zoomed by scrolling with the
+// key.ModShortcut modifier held, such as Ctrl+wheel. Set Editor.Zoom to
+// zoom an editor, or wrap the layout of labels in Layout and scale their
+// text size by Size.
+type TextZoom struct {
+	// Step is the scale change of each scroll event. Zero means 0.1.
+	Step float32
+	// Min and Max bound the scale. Zero means 0.5 and 3.
+	Min, Max float32
+
+	init  bool
+	scale float32
+	// pos is the pointer position of the most recent zoom.
+	pos f32.Point
+}
+
+const (
+	defaultZoomStep = 0.1
+	defaultZoomMin  = 0.5
+	defaultZoomMax  = 3
+)
+
+// Scale returns the scale of the text, initially 1.
+func (z *TextZoom) Scale() float32 {
+	if !z.init {
+		z.init = true
+		z.scale = 1
+	}
+	return z.scale
+}
+
+// SetScale sets the scale, for example to restore a saved scale. The
+// scale is clamped to the bounds.
+func (z *TextZoom) SetScale(s float32) {
+	z.init = true
+	lo, hi := z.Min, z.Max
+	if lo == 0 {
+		lo = defaultZoomMin
+	}
+	if hi == 0 {
+		hi = defaultZoomMax
+	}
+	if s < lo {
+		s = lo
+	}
+	if s > hi {
+		s = hi
+	}
+	z.scale = s
+}
+
+// Percent returns the scale as a rounded percentage, such as 120 for the
+// scale 1.2.
+func (z *TextZoom) Percent() int {
+	return int(math.Round(float64(z.Scale()) * 100))
+}
+
+// Size returns the text size scaled by the zoom.
+func (z *TextZoom) Size(size unit.Value) unit.Value {
+	return unit.Value{V: size.V * z.Scale(), U: size.U}
+}
+
+// Layout the widget with the zoom gesture covering its area. The widget
+// must scale its text size by Size.
+func (z *TextZoom) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	z.update(gtx)
+	dims := w(gtx)
+	defer clip.Rect(image.Rectangle{Max: dims.Size}).Push(gtx.Ops).Pop()
+	z.add(gtx.Ops)
+	return dims
+}
+
+// add the zoom handler, above the scroll handlers of the current area.
+func (z *TextZoom) add(ops *op.Ops) {
+	pointer.InputOp{
+		Tag:             z,
+		Types:           pointer.Scroll,
+		ScrollBounds:    image.Rect(-inf, -inf, inf, inf),
+		ScrollModifiers: key.ModShortcut,
+	}.Add(ops)
+}
+
+// update the scale from the zoom events, and report whether it changed.
+func (z *TextZoom) update(q event.Queue) bool {
+	changed := false
+	for _, e := range q.Events(z) {
+		e, ok := e.(pointer.Event)
+		if !ok || e.Type != pointer.Scroll || e.Scroll.Y == 0 {
+			continue
+		}
+		step := z.Step
+		if step == 0 {
+			step = defaultZoomStep
+		}
+		// Scrolling up zooms in.
+		if e.Scroll.Y > 0 {
+			step = -step
+		}
+		old := z.Scale()
+		// Round to avoid accumulating errors, such as a scale of
+		// 1.2000000001 from 1+0.1+0.1.
+		z.SetScale(float32(math.Round(float64(old+step)*1000) / 1000))
+		if z.scale != old {
+			changed = true
+			z.pos = e.Position
+		}
+	}
+	return changed
+}
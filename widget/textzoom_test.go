@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestEditorZoom(t *testing.T) {
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	gtx := layout.Context{
+		Ops:         &ops,
+		Queue:       &r,
+		Constraints: layout.Exact(image.Pt(200, 100)),
+	}
+	cache := text.NewCache(gofont.Collection())
+	z := new(TextZoom)
+	e := &Editor{Zoom: z}
+	e.SetText(strings.Repeat("line\n", 100))
+	frame := func() {
+		ops.Reset()
+		e.Layout(gtx, cache, text.Font{}, unit.Px(10), nil)
+		r.Frame(&ops)
+	}
+	frame()
+	// Scroll to and place the caret on a line in view.
+	r.Queue(pointer.Event{Type: pointer.Scroll, Source: pointer.Mouse, Position: f32.Pt(10, 10), Scroll: f32.Pt(0, 300)})
+	frame()
+	if e.scrollOff.Y == 0 {
+		t.Fatal("editor didn't scroll")
+	}
+	off := e.OffsetAt(f32.Pt(10, 50))
+	e.SetCaret(off, off)
+	caret := e.CaretCoords()
+	pos := f32.Pt(10, caret.Y)
+	zoom := func(dy float32) {
+		r.Queue(pointer.Event{Type: pointer.Scroll, Source: pointer.Mouse, Position: pos, Scroll: f32.Pt(0, dy), Modifiers: key.ModShortcut})
+		frame()
+	}
+	for _, exp := range []int{110, 120, 130} {
+		scroll := e.scrollOff.Y
+		zoom(-1)
+		if got := z.Percent(); got != exp {
+			t.Errorf("got zoom %d%%, expected %d%%", got, exp)
+		}
+		if got, exp := e.textSize, fixed.I(exp/10); got != exp {
+			t.Errorf("got text size %v, expected %v", got, exp)
+		}
+		if e.scrollOff.Y == scroll {
+			t.Error("zoom didn't scroll")
+		}
+		if got := e.CaretCoords(); got.Y < caret.Y-1 || got.Y > caret.Y+1 {
+			t.Errorf("zoom moved the caret line from %v to %v", caret.Y, got.Y)
+		}
+	}
+	// Zoom to the bounds.
+	for i := 0; i < 30; i++ {
+		zoom(1)
+	}
+	if got, exp := z.Scale(), float32(defaultZoomMin); got != exp {
+		t.Errorf("got scale %v, expected the minimum %v", got, exp)
+	}
+	for i := 0; i < 30; i++ {
+		zoom(-1)
+	}
+	if got, exp := z.Scale(), float32(defaultZoomMax); got != exp {
+		t.Errorf("got scale %v, expected the maximum %v", got, exp)
+	}
+	// Scrolling without the modifier scrolls the editor.
+	scroll, scale := e.scrollOff.Y, z.Scale()
+	r.Queue(pointer.Event{Type: pointer.Scroll, Source: pointer.Mouse, Position: pos, Scroll: f32.Pt(0, -10)})
+	frame()
+	if e.scrollOff.Y != scroll-10 || z.Scale() != scale {
+		t.Errorf("scroll without modifier: got offset %d and scale %v, expected %d and %v", e.scrollOff.Y, z.Scale(), scroll-10, scale)
+	}
+}
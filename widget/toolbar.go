@@ -0,0 +1,324 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"time"
+
+	"gioui.org/anim"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+// Action is an action of a Toolbar.
+type Action struct {
+	Icon  *Icon
+	Label string
+	// Shortcut is the key combination displayed next to the label in
+	// the overflow menu, if any.
+	Shortcut Shortcut
+	// Tag identifies the action for the program.
+	Tag      interface{}
+	Disabled bool
+	Priority ActionPriority
+}
+
+// ActionPriority controls whether an Action may move to the overflow
+// menu of a Toolbar.
+type ActionPriority uint8
+
+const (
+	// ActionAuto shows the action in the bar if it fits.
+	ActionAuto ActionPriority = iota
+	// ActionAlwaysShow keeps the action in the bar.
+	ActionAlwaysShow
+	// ActionAlwaysOverflow lists the action in the overflow menu.
+	ActionAlwaysOverflow
+)
+
+// Toolbar holds the state of a row of actions. The actions that don't fit
+// are collapsed into an overflow button that opens a Menu of them. The
+// actions keep their order both in the bar and in the menu.
+type Toolbar struct {
+	Actions []Action
+	// Menu lists the overflowed actions.
+	Menu Menu
+
+	clicks   []Clickable
+	overflow Clickable
+	// sizes caches the measured action sizes.
+	sizes        map[actionKey]image.Point
+	overflowSize image.Point
+	metric       unit.Metric
+	// overflowed are the actions in the overflow menu in the most
+	// recent Layout.
+	overflowed []bool
+	// pos animates the horizontal action positions.
+	pos []anim.Value[float32]
+	// shown tracks the actions visible in the previous Layout.
+	shown   []bool
+	laidOut bool
+	// chipX is the most recent position of the overflow button.
+	chipX     float32
+	activated []interface{}
+}
+
+// toolbarAnimDuration is the duration of action movements.
+const toolbarAnimDuration = 150 * time.Millisecond
+
+// actionKey identifies an action for measuring.
+type actionKey struct {
+	icon  *Icon
+	label string
+}
+
+// Activated returns the tag of the next activated action, if any,
+// whether clicked in the bar or chosen from the overflow menu.
+func (t *Toolbar) Activated() (interface{}, bool) {
+	t.updateClicks()
+	if len(t.activated) == 0 {
+		return nil, false
+	}
+	tag := t.activated[0]
+	n := copy(t.activated, t.activated[1:])
+	t.activated = t.activated[:n]
+	return tag, true
+}
+
+// Overflowed reports whether action i is in the overflow menu in the
+// most recent Layout.
+func (t *Toolbar) Overflowed(i int) bool {
+	return i < len(t.overflowed) && t.overflowed[i]
+}
+
+// Clickable returns the clickable for action i.
+func (t *Toolbar) Clickable(i int) *Clickable {
+	if n := len(t.Actions); n > len(t.clicks) {
+		t.clicks = append(t.clicks, make([]Clickable, n-len(t.clicks))...)
+	}
+	return &t.clicks[i]
+}
+
+// Overflow returns the clickable for the overflow button.
+func (t *Toolbar) Overflow() *Clickable {
+	return &t.overflow
+}
+
+// Invalidate forgets the measured action sizes. Call Invalidate after
+// changing the style of the actions.
+func (t *Toolbar) Invalidate() {
+	t.sizes = nil
+	t.overflowSize = image.Point{}
+}
+
+// Layout the actions that fit the constraints in a row, followed by the
+// overflow button if any action overflows. The action widget lays out
+// action i, with a disabled context for disabled actions. The overflow
+// widget lays out the overflow button, and the menu widget lays out Menu
+// with the origin and constraints of the bar.
+func (t *Toolbar) Layout(gtx layout.Context, action func(gtx layout.Context, i int) layout.Dimensions, overflow, menu layout.Widget) layout.Dimensions {
+	t.update(gtx)
+	widths, height := t.measure(gtx, action, overflow)
+	t.split(widths, gtx.Constraints.Max.X)
+
+	x := 0
+	for i := range t.Actions {
+		if t.overflowed[i] {
+			continue
+		}
+		target := float32(x)
+		switch p := &t.pos[i]; {
+		case !t.laidOut:
+			p.Set(gtx.Now, target, 0, nil)
+		case !t.shown[i]:
+			// Reveal actions from the position of the overflow button.
+			p.Set(gtx.Now, t.chipX, 0, nil)
+			fallthrough
+		case p.Target() != target:
+			p.Set(gtx.Now, target, toolbarAnimDuration, anim.EaseOut)
+		}
+		x += widths[i]
+	}
+	hasOverflow := false
+	for _, o := range t.overflowed {
+		hasOverflow = hasOverflow || o
+	}
+	chip := image.Rect(x, 0, x+t.overflowSize.X, height)
+	if hasOverflow {
+		t.chipX = float32(x)
+		x += t.overflowSize.X
+	}
+	t.laidOut = true
+	for i := range t.shown {
+		t.shown[i] = !t.overflowed[i]
+	}
+	animating := false
+	for i, a := range t.Actions {
+		if t.overflowed[i] {
+			continue
+		}
+		p := &t.pos[i]
+		animating = animating || p.Animating(gtx.Now)
+		cgtx := gtx
+		if a.Disabled {
+			cgtx = cgtx.Disabled()
+		}
+		cgtx.Constraints = layout.Constraints{Max: image.Pt(widths[i], height)}
+		trans := op.Offset(layout.FPt(image.Pt(int(p.Get(gtx.Now)+.5), 0))).Push(gtx.Ops)
+		action(cgtx, i)
+		trans.Pop()
+	}
+	if animating {
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	if hasOverflow {
+		cgtx := gtx
+		cgtx.Constraints = layout.Exact(chip.Size())
+		trans := op.Offset(layout.FPt(chip.Min)).Push(gtx.Ops)
+		t.overflow.Layout(cgtx, overflow)
+		trans.Pop()
+		if t.overflow.Clicked() {
+			t.Menu.Open(chip)
+		}
+	}
+	if t.Menu.Visible() {
+		macro := op.Record(gtx.Ops)
+		mgtx := gtx
+		mgtx.Constraints.Min = image.Point{}
+		menu(mgtx)
+		op.Defer(gtx.Ops, macro.Stop())
+	}
+	if x > gtx.Constraints.Max.X {
+		x = gtx.Constraints.Max.X
+	}
+	return layout.Dimensions{Size: gtx.Constraints.Constrain(image.Pt(x, height))}
+}
+
+func (t *Toolbar) update(gtx layout.Context) {
+	t.updateClicks()
+	if n := len(t.Actions); len(t.pos) != n {
+		if len(t.clicks) > n {
+			t.clicks = t.clicks[:n]
+		}
+		t.pos = make([]anim.Value[float32], n)
+		t.shown = make([]bool, n)
+		t.overflowed = make([]bool, n)
+		t.laidOut = false
+		t.Menu.Close()
+		t.Menu.Items = t.Menu.Items[:0]
+	}
+	if gtx.Metric != t.metric {
+		t.metric = gtx.Metric
+		t.Invalidate()
+	}
+}
+
+func (t *Toolbar) updateClicks() {
+	for i := range t.clicks {
+		for t.clicks[i].Clicked() {
+			if i < len(t.Actions) && !t.Actions[i].Disabled {
+				t.activated = append(t.activated, t.Actions[i].Tag)
+			}
+		}
+	}
+}
+
+// measure the actions and the overflow button, caching the sizes by icon
+// and label.
+func (t *Toolbar) measure(gtx layout.Context, action func(gtx layout.Context, i int) layout.Dimensions, overflow layout.Widget) ([]int, int) {
+	mgtx := gtx.Measuring()
+	mgtx.Constraints = layout.Constraints{Max: image.Pt(inf, gtx.Constraints.Max.Y)}
+	if t.sizes == nil {
+		t.sizes = make(map[actionKey]image.Point)
+	}
+	widths := make([]int, len(t.Actions))
+	height := 0
+	for i, a := range t.Actions {
+		k := actionKey{icon: a.Icon, label: a.Label}
+		sz, ok := t.sizes[k]
+		if !ok {
+			sz = action(mgtx, i).Size
+			t.sizes[k] = sz
+		}
+		widths[i] = sz.X
+		if sz.Y > height {
+			height = sz.Y
+		}
+	}
+	if t.overflowSize == (image.Point{}) {
+		t.overflowSize = overflow(mgtx).Size
+	}
+	if h := t.overflowSize.Y; h > height {
+		height = h
+	}
+	return widths, height
+}
+
+// split the actions between the bar and the overflow menu. The width of
+// the actions that always show is reserved, and the other actions fill
+// the remaining width in order; once an action doesn't fit, the actions
+// after it overflow as well, to keep the order.
+func (t *Toolbar) split(widths []int, width int) {
+	avail := width
+	menu := false
+	auto := 0
+	for i, a := range t.Actions {
+		switch a.Priority {
+		case ActionAlwaysShow:
+			avail -= widths[i]
+		case ActionAlwaysOverflow:
+			menu = true
+		default:
+			auto += widths[i]
+		}
+	}
+	if menu || auto > avail {
+		avail -= t.overflowSize.X
+	}
+	changed := false
+	full := false
+	for i, a := range t.Actions {
+		o := false
+		switch a.Priority {
+		case ActionAlwaysOverflow:
+			o = true
+		case ActionAuto:
+			if !full && widths[i] <= avail {
+				avail -= widths[i]
+			} else {
+				full = true
+				o = true
+			}
+		}
+		if o != t.overflowed[i] {
+			changed = true
+			t.overflowed[i] = o
+		}
+	}
+	if changed {
+		t.Menu.Close()
+		// Rebuild the items for the new overflow set.
+		t.Menu.Items = t.Menu.Items[:0]
+		for i := range t.Actions {
+			if !t.overflowed[i] {
+				continue
+			}
+			i := i
+			t.Menu.Items = append(t.Menu.Items, MenuItem{
+				Do: func() { t.activated = append(t.activated, t.Actions[i].Tag) },
+			})
+		}
+	}
+	// Follow the changes of the overflowed actions.
+	j := 0
+	for i, a := range t.Actions {
+		if !t.overflowed[i] {
+			continue
+		}
+		it := &t.Menu.Items[j]
+		it.Title, it.Shortcut, it.Disabled = a.Label, a.Shortcut, a.Disabled
+		j++
+	}
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+// layoutToolbar lays out t with actions of 40x20 pixels and an overflow
+// button of 20x20 pixels. Menu items are 100x20 pixels.
+func layoutToolbar(gtx layout.Context, t *Toolbar) layout.Dimensions {
+	return t.Layout(gtx,
+		func(gtx layout.Context, i int) layout.Dimensions {
+			return t.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Dimensions{Size: image.Pt(40, 20)}
+			})
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{Size: image.Pt(20, 20)}
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Max.Y = 200
+			return t.Menu.Layout(gtx, func(gtx layout.Context, m *Menu) layout.Dimensions {
+				return m.LayoutItems(gtx, func(gtx layout.Context, i int) layout.Dimensions {
+					return m.Clickable(i).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return layout.Dimensions{Size: image.Pt(100, 20)}
+					})
+				})
+			})
+		},
+	)
+}
+
+func newToolbar() *Toolbar {
+	return &Toolbar{Actions: []Action{
+		{Label: "a", Tag: "a", Priority: ActionAlwaysShow},
+		{Label: "b", Tag: "b"},
+		{Label: "c", Tag: "c"},
+		{Label: "d", Tag: "d"},
+		{Label: "e", Tag: "e", Priority: ActionAlwaysOverflow},
+	}}
+}
+
+func TestToolbarOverflow(t *testing.T) {
+	tb := newToolbar()
+	for _, tc := range []struct {
+		width      int
+		overflowed []bool
+		menu       []string
+		size       image.Point
+	}{
+		{300, []bool{false, false, false, false, true}, []string{"e"}, image.Pt(180, 20)},
+		{140, []bool{false, false, false, true, true}, []string{"d", "e"}, image.Pt(140, 20)},
+		// Actions that always show never overflow.
+		{60, []bool{false, true, true, true, true}, []string{"b", "c", "d", "e"}, image.Pt(60, 20)},
+	} {
+		gtx := layout.Context{
+			Ops:         new(op.Ops),
+			Constraints: layout.Constraints{Max: image.Pt(tc.width, 100)},
+		}
+		dims := layoutToolbar(gtx, tb)
+		for i, exp := range tc.overflowed {
+			if got := tb.Overflowed(i); got != exp {
+				t.Errorf("width %d: action %d overflowed %v; expected %v", tc.width, i, got, exp)
+			}
+		}
+		var menu []string
+		for _, it := range tb.Menu.Items {
+			menu = append(menu, it.Title)
+		}
+		if !reflect.DeepEqual(menu, tc.menu) {
+			t.Errorf("width %d: menu %v; expected %v", tc.width, menu, tc.menu)
+		}
+		if dims.Size != tc.size {
+			t.Errorf("width %d: size %v; expected %v", tc.width, dims.Size, tc.size)
+		}
+	}
+	// The items follow the overflowed actions, and are only rebuilt
+	// when the overflow set changes.
+	tb.Actions[2].Label = "renamed"
+	widths := []int{40, 40, 40, 40, 40}
+	allocs := testing.AllocsPerRun(1, func() {
+		tb.split(widths, 60)
+	})
+	if allocs != 0 {
+		t.Errorf("split of an unchanged overflow set allocated %f times", allocs)
+	}
+	if got := tb.Menu.Items[1].Title; got != "renamed" {
+		t.Errorf("got item title %q after renaming its action; expected \"renamed\"", got)
+	}
+}
+
+func TestToolbarActivation(t *testing.T) {
+	var r router.Router
+	tb := newToolbar()
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Constraints{Max: image.Pt(60, 100)},
+		Queue:       &r,
+	}
+	frame := func() {
+		gtx.Ops.Reset()
+		layoutToolbar(gtx, tb)
+		r.Frame(gtx.Ops)
+	}
+	frame()
+	click := func(pos f32.Point) {
+		r.Queue(
+			pointer.Event{Source: pointer.Mouse, Type: pointer.Move, Position: pos},
+			pointer.Event{Source: pointer.Mouse, Type: pointer.Press, Buttons: pointer.ButtonPrimary, Position: pos},
+			pointer.Event{Source: pointer.Mouse, Type: pointer.Release, Position: pos},
+		)
+		frame()
+	}
+	click(f32.Pt(20, 10))
+	if tag, ok := tb.Activated(); !ok || tag != "a" {
+		t.Fatalf("activated %v, %v; expected a", tag, ok)
+	}
+	// The overflow button opens the menu below it.
+	click(f32.Pt(50, 10))
+	frame()
+	if !tb.Menu.Visible() {
+		t.Fatal("overflow click did not open the menu")
+	}
+	// The second menu item is the overflowed action c.
+	click(f32.Pt(30, 50))
+	if tag, ok := tb.Activated(); !ok || tag != "c" {
+		t.Errorf("activated %v, %v through the menu; expected c", tag, ok)
+	}
+	if _, ok := tb.Activated(); ok {
+		t.Error("spurious activation")
+	}
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+// Tooltip tracks the hovering and long pressing of an area, to show a
+// tooltip after a delay. Mouse pointers show the tooltip by resting on
+// the area, touch pointers by pressing it.
+type Tooltip struct {
+	// Delay is how long the pointer must rest on or press the area
+	// before the tooltip is shown. Zero means a default delay.
+	Delay time.Duration
+
+	text string
+	// active is set while the pointer rests on or presses the area,
+	// since start.
+	active  bool
+	start   time.Time
+	visible bool
+	// pos is the position of the pointer that showed the tooltip.
+	pos f32.Point
+}
+
+// defaultTooltipDelay is the Delay of tooltips that don't specify one.
+const defaultTooltipDelay = 500 * time.Millisecond
+
+// Layout w and track the pointer over its area.
+func (t *Tooltip) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	dims := w(gtx)
+	t.add(gtx, dims.Size)
+	return dims
+}
+
+// Visible reports whether the tooltip is shown.
+func (t *Tooltip) Visible() bool {
+	return t.visible
+}
+
+// Position returns the position of the pointer that showed the tooltip,
+// relative to the tracked area.
+func (t *Tooltip) Position() f32.Point {
+	return t.pos
+}
+
+// Text returns the full text of a truncated Label, for tooltips
+// registered through Label.AutoTooltip.
+func (t *Tooltip) Text() string {
+	return t.text
+}
+
+// add processes the pointer events of t, and tracks the pointer over an
+// area of size sz.
+func (t *Tooltip) add(gtx layout.Context, sz image.Point) {
+	if gtx.IsMeasuring() {
+		return
+	}
+	t.update(gtx)
+	defer clip.Rect{Max: sz}.Push(gtx.Ops).Pop()
+	// Let presses through to the widget underneath.
+	defer pointer.PassOp{}.Push(gtx.Ops).Pop()
+	pointer.InputOp{
+		Tag:   t,
+		Types: pointer.Enter | pointer.Leave | pointer.Move | pointer.Press | pointer.Release | pointer.Cancel,
+	}.Add(gtx.Ops)
+}
+
+func (t *Tooltip) update(gtx layout.Context) {
+	for _, e := range gtx.Events(t) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Type {
+		case pointer.Enter, pointer.Move:
+			if e.Source == pointer.Mouse && !t.active {
+				t.begin(gtx.Now, e.Position)
+			}
+			if !t.visible {
+				t.pos = e.Position
+			}
+		case pointer.Press:
+			if e.Source == pointer.Touch {
+				t.begin(gtx.Now, e.Position)
+			} else {
+				// Clicks dismiss tooltips.
+				t.hide()
+			}
+		case pointer.Release, pointer.Leave, pointer.Cancel:
+			t.hide()
+		}
+	}
+	if !t.active || t.visible {
+		return
+	}
+	delay := t.Delay
+	if delay == 0 {
+		delay = defaultTooltipDelay
+	}
+	if at := t.start.Add(delay); gtx.Now.Before(at) {
+		op.InvalidateOp{At: at}.Add(gtx.Ops)
+		return
+	}
+	t.visible = true
+}
+
+func (t *Tooltip) begin(now time.Time, pos f32.Point) {
+	t.active = true
+	t.start = now
+	t.pos = pos
+}
+
+func (t *Tooltip) hide() {
+	t.active = false
+	t.visible = false
+}
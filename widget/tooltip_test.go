@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+)
+
+func TestLabelAutoTooltip(t *testing.T) {
+	const txt = "The quick brown fox jumps over the lazy dog"
+	var (
+		r       router.Router
+		ops     op.Ops
+		tooltip Tooltip
+	)
+	cache := text.NewCache(gofont.Collection())
+	start := time.Now()
+	frame := func(width int, at time.Duration) {
+		ops.Reset()
+		gtx := layout.Context{
+			Ops:         &ops,
+			Constraints: layout.Constraints{Max: image.Pt(width, 100)},
+			Now:         start.Add(at),
+			Queue:       &r,
+		}
+		l := Label{MaxLines: 1, AutoTooltip: &tooltip}
+		l.Layout(gtx, cache, text.Font{}, unit.Px(10), txt)
+		r.Frame(gtx.Ops)
+	}
+	frame(60, 0)
+	r.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(5, 5)})
+	frame(60, 0)
+	if tooltip.Visible() {
+		t.Fatal("tooltip visible before the delay")
+	}
+	frame(60, 600*time.Millisecond)
+	if !tooltip.Visible() {
+		t.Fatal("tooltip not visible after the delay")
+	}
+	if got := tooltip.Text(); got != txt {
+		t.Errorf("tooltip text is %q, expected %q", got, txt)
+	}
+	tracked := func() bool {
+		for _, h := range r.HitTest(f32.Pt(5, 5)) {
+			if h.Tag == &tooltip {
+				return true
+			}
+		}
+		return false
+	}
+	if !tracked() {
+		t.Error("truncated label registered no pointer handler")
+	}
+	// A label that fits its text tracks nothing.
+	frame(1000, 700*time.Millisecond)
+	if tooltip.Visible() {
+		t.Error("tooltip visible for a label that fits")
+	}
+	if tracked() {
+		t.Error("label that fits registered a pointer handler")
+	}
+}
+
+func TestTooltipLongPress(t *testing.T) {
+	var (
+		r       router.Router
+		ops     op.Ops
+		tooltip Tooltip
+		click   Clickable
+	)
+	start := time.Now()
+	frame := func(at time.Duration) {
+		ops.Reset()
+		gtx := layout.Context{
+			Ops:         &ops,
+			Constraints: layout.Exact(image.Pt(100, 20)),
+			Now:         start.Add(at),
+			Queue:       &r,
+		}
+		tooltip.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return click.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Dimensions{Size: gtx.Constraints.Min}
+			})
+		})
+		r.Frame(gtx.Ops)
+	}
+	frame(0)
+	r.Queue(pointer.Event{Type: pointer.Press, Source: pointer.Touch, Position: f32.Pt(10, 10)})
+	frame(0)
+	frame(600 * time.Millisecond)
+	if !tooltip.Visible() {
+		t.Fatal("tooltip not visible after a long press")
+	}
+	r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Touch, Position: f32.Pt(10, 10)})
+	frame(700 * time.Millisecond)
+	if tooltip.Visible() {
+		t.Error("tooltip visible after release")
+	}
+	// The press reaches the widget underneath the tooltip.
+	if !click.Clicked() {
+		t.Error("the widget underneath the tooltip was not clicked")
+	}
+}
+
+func TestTooltipClock(t *testing.T) {
+	var (
+		r       router.Router
+		ops     op.Ops
+		tooltip = Tooltip{Delay: 300 * time.Millisecond}
+	)
+	start := time.Unix(1, 0)
+	clk := system.NewManualClock(start)
+	r.SetClock(clk)
+	frame := func() {
+		ops.Reset()
+		gtx := layout.Context{
+			Ops:         &ops,
+			Constraints: layout.Exact(image.Pt(100, 20)),
+			Now:         r.Now(),
+			Queue:       &r,
+		}
+		tooltip.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		})
+		r.Frame(gtx.Ops)
+	}
+	frame()
+	r.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(10, 10)})
+	frame()
+	// Step the clock 16ms per frame until the scheduled wakeup.
+	var shown time.Duration
+	for i := 0; i < 100 && !tooltip.Visible(); i++ {
+		wakeup, ok := r.WakeupTime()
+		if !ok {
+			t.Fatal("no wakeup scheduled for the tooltip")
+		}
+		if wakeup.IsZero() {
+			// Immediate redraw for pending events.
+			frame()
+			continue
+		}
+		if exp := start.Add(300 * time.Millisecond); !wakeup.Equal(exp) {
+			t.Fatalf("got wakeup %v; expected %v", wakeup.Sub(start), exp.Sub(start))
+		}
+		clk.Advance(16 * time.Millisecond)
+		frame()
+		shown = clk.Now().Sub(start)
+	}
+	// The first frame at or after the delay shows the tooltip.
+	if !tooltip.Visible() || shown != 304*time.Millisecond {
+		t.Errorf("tooltip visible %v at %v; expected visible at 304ms", tooltip.Visible(), shown)
+	}
+	if _, ok := r.WakeupTime(); ok {
+		t.Error("wakeup pending after the tooltip is shown")
+	}
+	// Timers are notified by the clock, unless stopped.
+	tm := clk.NewTimer(clk.Now().Add(time.Second))
+	stopped := clk.NewTimer(clk.Now().Add(time.Second))
+	stopped.Stop()
+	clk.Advance(999 * time.Millisecond)
+	select {
+	case <-tm.C():
+		t.Error("timer notified before its time")
+	default:
+	}
+	clk.Advance(time.Millisecond)
+	select {
+	case <-tm.C():
+	default:
+		t.Error("timer not notified at its time")
+	}
+	select {
+	case <-stopped.C():
+		t.Error("stopped timer notified")
+	default:
+	}
+}
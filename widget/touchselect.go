@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"math"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/gesture"
+	"gioui.org/io/clipboard"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+)
+
+// touchSelection is the selection user interface of an Editor for touch
+// screens: the handles at the ends of the selection, the magnifier shown
+// while dragging a handle, and the bar of selection actions.
+type touchSelection struct {
+	// active is set by a long press of a touch pointer, and cleared by
+	// taps and mouse presses.
+	active bool
+	// pressed is set while a touch pointer presses the editor, until
+	// the press becomes a long press.
+	pressed  bool
+	pressAt  time.Time
+	pressPos f32.Point
+	// longPressed suppresses the click that ends a long press.
+	longPressed bool
+
+	handles gesture.SelectionHandles
+	// dragged is the most recently dragged handle.
+	dragged gesture.Handle
+	// rects are the bounds of the handles laid out by LayoutHandles.
+	rects [2]image.Rectangle
+
+	cut, copy, paste Clickable
+}
+
+// editorLongPress is the duration of a touch press that selects a word.
+const editorLongPress = 500 * time.Millisecond
+
+// handleSlack is the extent of the handle drag area beyond the visual
+// handle.
+var handleSlack = unit.Dp(8)
+
+// TouchSelecting reports whether the editor shows the selection
+// interface for touch screens: a long press by a touch pointer selected
+// a word, and the selection is not empty.
+func (e *Editor) TouchSelecting() bool {
+	return e.touch.active && e.caret.start != e.caret.end
+}
+
+// LayoutHandles lays out the draggable handles at the ends of the
+// selection while TouchSelecting. The handle function draws the handle
+// h; the start handle is placed with its top right corner at the bottom
+// of the line of the selection start, and the end handle with its top
+// left corner at the bottom of the line of the selection end. Call
+// LayoutHandles after Layout, so the handles are above the text and may
+// extend below the editor.
+func (e *Editor) LayoutHandles(gtx layout.Context, handle func(gtx layout.Context, h gesture.Handle) layout.Dimensions) {
+	if !e.TouchSelecting() || e.shaper == nil {
+		return
+	}
+	slack := gtx.Px(handleSlack)
+	for i, off := range e.selectionEnds() {
+		h := gesture.Handle(i)
+		anchor, _, descent := e.offsetLine(off)
+		// Keep the dragged handle, even if it moves out of view.
+		dragged := e.touch.handles.Dragging() && e.touch.dragged == h
+		if !dragged && !e.visible(anchor) {
+			continue
+		}
+		hgtx := gtx
+		hgtx.Constraints.Min = image.Point{}
+		m := op.Record(gtx.Ops)
+		dims := handle(hgtx, h)
+		c := m.Stop()
+		pos := image.Pt(int(math.Round(float64(anchor.X))), int(math.Round(float64(anchor.Y+descent))))
+		if h == gesture.HandleStart {
+			pos.X -= dims.Size.X
+		}
+		t := op.Offset(layout.FPt(pos)).Push(gtx.Ops)
+		c.Add(gtx.Ops)
+		t.Pop()
+		r := image.Rectangle{Min: pos, Max: pos.Add(dims.Size)}
+		e.touch.rects[h] = r
+		e.touch.handles.Add(gtx.Ops, h, anchor, r.Inset(-slack))
+	}
+}
+
+// Magnifier returns the middle of the line at the selection end of the
+// dragged handle, relative to the editor, while a handle is dragged. The
+// magnifier shows the text around that position enlarged, because the
+// finger covers it.
+func (e *Editor) Magnifier() (pos f32.Point, ok bool) {
+	if !e.TouchSelecting() || !e.touch.handles.Dragging() {
+		return f32.Point{}, false
+	}
+	anchor, ascent, descent := e.offsetLine(e.selectionEnds()[e.touch.dragged])
+	return anchor.Add(f32.Pt(0, (descent-ascent)/2)), true
+}
+
+// LayoutSelectionBar lays out the bar of selection actions above the
+// selection while TouchSelecting, or below the handles if the top of
+// the selection is scrolled out of view. The bar function draws the bar
+// with the clickables of the cut, copy and paste actions. The bar is
+// drawn on top of other widgets, and is hidden while a handle is
+// dragged.
+func (e *Editor) LayoutSelectionBar(gtx layout.Context, bar func(gtx layout.Context, cut, copy, paste *Clickable) layout.Dimensions) {
+	if !e.TouchSelecting() || e.touch.handles.Dragging() || e.shaper == nil {
+		return
+	}
+	bgtx := gtx
+	bgtx.Constraints.Min = image.Point{}
+	m := op.Record(gtx.Ops)
+	dims := bar(bgtx, &e.touch.cut, &e.touch.copy, &e.touch.paste)
+	c := m.Stop()
+
+	anchor := e.selectionBounds()
+	below := anchor.Intersect(image.Rectangle{Max: e.viewSize})
+	for _, r := range e.touch.rects {
+		below = below.Union(r)
+	}
+	pos := placeMenu(below, dims.Size, image.Rect(0, -inf, e.viewSize.X, inf), false)
+	if anchor.Min.Y >= 0 {
+		pos.Y = anchor.Min.Y - dims.Size.Y
+	}
+	m = op.Record(gtx.Ops)
+	op.Offset(layout.FPt(pos)).Add(gtx.Ops)
+	c.Add(gtx.Ops)
+	op.Defer(gtx.Ops, m.Stop())
+}
+
+// processTouch detects long presses and processes the handle drags and
+// the actions of the selection bar.
+func (e *Editor) processTouch(gtx layout.Context) {
+	t := &e.touch
+	t.handles.Config = gtx.Gesture
+	for _, evt := range t.handles.Events(gtx.Metric, gtx, e.OffsetAt) {
+		e.moveHandle(evt)
+	}
+	if t.pressed && !t.longPressed {
+		if at := t.pressAt.Add(editorLongPress); gtx.Now.Before(at) {
+			op.InvalidateOp{At: at}.Add(gtx.Ops)
+		} else {
+			t.pressed = false
+			t.longPressed = true
+			t.active = true
+			e.moveCoord(image.Point{
+				X: int(math.Round(float64(t.pressPos.X))),
+				Y: int(math.Round(float64(t.pressPos.Y))),
+			})
+			e.moveWord(-1, selectionClear)
+			e.moveWord(1, selectionExtend)
+			e.requestFocus = true
+			e.dragging = false
+		}
+	}
+	if !e.TouchSelecting() {
+		return
+	}
+	if t.cut.Clicked() {
+		e.copySelection(gtx, true)
+	}
+	if t.copy.Clicked() {
+		e.copySelection(gtx, false)
+		e.ClearSelection()
+	}
+	if t.paste.Clicked() {
+		e.paste(gtx)
+	}
+}
+
+// moveHandle moves the selection end of a dragged handle. The handles
+// don't cross, so the selection never becomes empty.
+func (e *Editor) moveHandle(evt gesture.SelectionEvent) {
+	ends := e.selectionEnds()
+	start, end := ends[0], ends[1]
+	e.touch.dragged = evt.Handle
+	e.caret.scroll = true
+	e.caret.xoff = 0
+	switch evt.Handle {
+	case gesture.HandleStart:
+		start = max(min(evt.Offset, end-1), 0)
+		e.caret.start, e.caret.end = start, end
+	case gesture.HandleEnd:
+		end = max(evt.Offset, start+1)
+		e.caret.start, e.caret.end = end, start
+	}
+}
+
+// copySelection writes the selected text to the clipboard, and deletes
+// it if cut is set.
+func (e *Editor) copySelection(gtx layout.Context, cut bool) {
+	if text := e.SelectedText(); text != "" {
+		clipboard.WriteOp{Text: text}.Add(gtx.Ops)
+		if cut {
+			e.Delete(1)
+		}
+	}
+}
+
+// paste requests the clipboard contents, to replace the selection when
+// they arrive.
+func (e *Editor) paste(gtx layout.Context) {
+	clipboard.ReadOp{Tag: &e.eventKey}.Add(gtx.Ops)
+}
+
+// selectionEnds returns the rune offsets of the start and end of the
+// selection, in text order.
+func (e *Editor) selectionEnds() [2]int {
+	start, end := e.caret.start, e.caret.end
+	if start > end {
+		start, end = end, start
+	}
+	return [2]int{start, end}
+}
+
+// offsetLine returns the position of the caret at the rune offset,
+// relative to the editor, and the ascent and descent of its line.
+func (e *Editor) offsetLine(offset int) (pos f32.Point, ascent, descent float32) {
+	p := e.closestPosition(combinedPos{runes: offset})
+	l := e.lines[p.lineCol.Y]
+	pos = f32.Pt(float32(p.x)/64-float32(e.scrollOff.X), float32(p.y-e.scrollOff.Y))
+	return pos, float32(l.Ascent) / 64, float32(l.Descent) / 64
+}
+
+// selectionBounds returns the bounds of the lines of the selection,
+// relative to the editor. The bounds span the width of the view if the
+// selection spans several lines.
+func (e *Editor) selectionBounds() image.Rectangle {
+	ends := e.selectionEnds()
+	start, sasc, _ := e.offsetLine(ends[0])
+	end, _, edesc := e.offsetLine(ends[1])
+	r := image.Rect(0, int(start.Y-sasc), e.viewSize.X, int(math.Ceil(float64(end.Y+edesc))))
+	if start.Y == end.Y {
+		r.Min.X, r.Max.X = int(start.X), int(math.Ceil(float64(end.X)))
+	}
+	return r
+}
+
+// visible reports whether the caret position pos is inside the view.
+func (e *Editor) visible(pos f32.Point) bool {
+	v := layout.FRect(image.Rectangle{Max: e.viewSize})
+	return pos.X >= v.Min.X && pos.X <= v.Max.X && pos.Y >= v.Min.Y && pos.Y <= v.Max.Y
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/gesture"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+)
+
+// Zoomable holds the transformation of content that two-finger gestures
+// scale, rotate and move, such as a photo or a map. A rotation released
+// near a multiple of 90° snaps to it.
+type Zoomable struct {
+	// Rotate detects the two-finger gestures.
+	Rotate gesture.Rotate
+	// SnapAngle is the largest angle in radians from a multiple of 90°
+	// that snaps to it at the end of a gesture. Zero means 10°, and a
+	// negative angle disables snapping.
+	SnapAngle float32
+
+	// The transformation, as a scale followed by a rotation followed by
+	// an offset.
+	init   bool
+	scale  float32
+	angle  float32
+	offset f32.Point
+}
+
+// defaultSnapAngle is the default Zoomable.SnapAngle.
+const defaultSnapAngle = 10 * math.Pi / 180
+
+// Transform returns the transformation from the coordinates of the
+// content to the coordinates of the Zoomable.
+func (z *Zoomable) Transform() f32.Affine2D {
+	z.initialize()
+	return f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(z.scale, z.scale)).Rotate(f32.Point{}, z.angle).Offset(z.offset)
+}
+
+// Scale returns the scale of the content.
+func (z *Zoomable) Scale() float32 {
+	z.initialize()
+	return z.scale
+}
+
+// Angle returns the rotation of the content in radians, in the range
+// (-π, π].
+func (z *Zoomable) Angle() float32 {
+	return z.angle
+}
+
+// Reset the transformation to the identity.
+func (z *Zoomable) Reset() {
+	z.init = false
+	z.initialize()
+}
+
+func (z *Zoomable) initialize() {
+	if z.init {
+		return
+	}
+	z.init = true
+	z.scale = 1
+	z.angle = 0
+	z.offset = f32.Point{}
+}
+
+// Layout the content transformed, with the gesture area covering the
+// maximum constraints.
+func (z *Zoomable) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
+	z.update(gtx)
+	size := gtx.Constraints.Max
+	area := clip.Rect(image.Rectangle{Max: size}).Push(gtx.Ops)
+	z.Rotate.Add(gtx.Ops)
+	area.Pop()
+	defer op.Affine(z.Transform()).Push(gtx.Ops).Pop()
+	w(gtx)
+	return layout.Dimensions{Size: size}
+}
+
+func (z *Zoomable) update(gtx layout.Context) {
+	for _, e := range z.Rotate.Events(gtx) {
+		switch e.Type {
+		case gesture.RotateMove:
+			// Scale and rotate around the previous center, and move
+			// to the new center.
+			c := e.Center.Sub(e.Offset)
+			t := z.Transform().Scale(c, f32.Pt(e.Scale, e.Scale)).Rotate(c, e.Angle).Offset(e.Offset)
+			z.set(t)
+		case gesture.RotateEnd:
+			z.snap(e.Center)
+		}
+	}
+}
+
+// snap the rotation around center to the closest multiple of 90°, if it
+// is within SnapAngle.
+func (z *Zoomable) snap(center f32.Point) {
+	snap := z.SnapAngle
+	if snap == 0 {
+		snap = defaultSnapAngle
+	}
+	const right = math.Pi / 2
+	target := float32(math.Round(float64(z.angle/right))) * right
+	d := target - z.angle
+	if d == 0 || snap < 0 || d > snap || d < -snap {
+		return
+	}
+	z.set(z.Transform().Rotate(center, d))
+	// Avoid rounding errors in the snapped angle.
+	z.angle = target
+	if z.angle <= -math.Pi {
+		z.angle += 2 * math.Pi
+	}
+}
+
+// set the transformation from t, which must be a similarity
+// transformation.
+func (z *Zoomable) set(t f32.Affine2D) {
+	sx, _, ox, hy, _, oy := t.Elems()
+	z.scale = float32(math.Hypot(float64(sx), float64(hy)))
+	z.angle = float32(math.Atan2(float64(hy), float64(sx)))
+	z.offset = f32.Pt(ox, oy)
+}
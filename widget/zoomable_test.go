@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/layout"
+	"gioui.org/op"
+)
+
+func TestZoomableSnap(t *testing.T) {
+	for _, tc := range []struct {
+		angle, want float64
+	}{
+		{angle: 95, want: 90},
+		{angle: 55, want: 45},
+	} {
+		var (
+			z   Zoomable
+			ops op.Ops
+			r   router.Router
+		)
+		gtx := layout.Context{
+			Ops:         &ops,
+			Queue:       &r,
+			Constraints: layout.Exact(image.Pt(200, 200)),
+		}
+		frame := func() {
+			ops.Reset()
+			z.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Dimensions{}
+			})
+			r.Frame(&ops)
+		}
+		frame()
+		orbit := func(deg float64) f32.Point {
+			a := deg * math.Pi / 180
+			return f32.Pt(100+50*float32(math.Cos(a)), 100+50*float32(math.Sin(a)))
+		}
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 0, Position: f32.Pt(100, 100)},
+			pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 1, Position: orbit(0)},
+		)
+		frame()
+		// Rotate in small steps, the first of which crosses the
+		// gesture threshold.
+		for deg := 10.0; deg <= tc.angle; deg += 5 {
+			r.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Touch, PointerID: 1, Position: orbit(deg)})
+			frame()
+		}
+		r.Queue(pointer.Event{Type: pointer.Release, Source: pointer.Touch, PointerID: 1, Position: orbit(tc.angle)})
+		frame()
+		// The first 10° are lost to the threshold.
+		want := tc.want * math.Pi / 180
+		if got := float64(z.Angle()); math.Abs(got-want) > 1e-3 {
+			t.Errorf("rotation to %v°: got angle %v, expected %v", tc.angle, got, want)
+		}
+		if got := z.Scale(); math.Abs(float64(got)-1) > 1e-3 {
+			t.Errorf("rotation to %v°: got scale %v, expected 1", tc.angle, got)
+		}
+	}
+}
+
+func TestZoomablePinch(t *testing.T) {
+	var (
+		z   Zoomable
+		ops op.Ops
+		r   router.Router
+	)
+	gtx := layout.Context{
+		Ops:         &ops,
+		Queue:       &r,
+		Constraints: layout.Exact(image.Pt(200, 200)),
+	}
+	frame := func() {
+		ops.Reset()
+		z.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{}
+		})
+		r.Frame(&ops)
+	}
+	frame()
+	r.Queue(
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 0, Position: f32.Pt(50, 100)},
+		pointer.Event{Type: pointer.Press, Source: pointer.Touch, PointerID: 1, Position: f32.Pt(100, 100)},
+	)
+	frame()
+	// Spread the fingers apart.
+	r.Queue(pointer.Event{Type: pointer.Move, Source: pointer.Touch, PointerID: 1, Position: f32.Pt(150, 100)})
+	frame()
+	if got := z.Scale(); math.Abs(float64(got)-2) > 1e-3 {
+		t.Errorf("got scale %v, expected 2", got)
+	}
+	// The content under the fingers follows the fingers.
+	tr := z.Transform()
+	for _, p := range [][2]f32.Point{
+		{f32.Pt(50, 100), f32.Pt(50, 100)},
+		{f32.Pt(100, 100), f32.Pt(150, 100)},
+	} {
+		got := tr.Transform(p[0])
+		if d := got.Sub(p[1]); d.X*d.X+d.Y*d.Y > 1e-3 {
+			t.Errorf("%v transformed to %v, expected %v", p[0], got, p[1])
+		}
+	}
+}